@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -11,24 +16,59 @@ import (
 	"myapi/config"
 	"myapi/internal/db"
 	"myapi/internal/models"
+	"myapi/internal/pkg/backup"
+	"myapi/internal/pkg/transfer"
+	"myapi/internal/pkg/userdata"
+	"myapi/pkg/logger"
 )
 
 func main() {
 	if len(os.Args) < 2 {
 		fmt.Println("Usage: cli <command> [arguments...]")
 		fmt.Println("Available commands:")
+		fmt.Println("  config check")
 		fmt.Println("  update_pwd <email> <new_password>")
+		fmt.Println("  process_deletions")
+		fmt.Println("  migrate status|up [N]|down N|goto V|force V|create NAME")
+		fmt.Println("  backup [-dir DIR] [-keep N] [-gzip=false]")
+		fmt.Println("  restore <backup_file>")
+		fmt.Println("  transfer -to-type postgres|mysql|sqlite [-to-host H -to-port P -to-user U -to-password PW -to-name DB | -to-path FILE] [-chunk N] [-truncate]")
 		os.Exit(1)
 	}
 
+	// 检查配置时要列出所有问题，不能在加载时就退出
+	if os.Args[1] == "config" {
+		if len(os.Args) != 3 || os.Args[2] != "check" {
+			fmt.Println("Usage: cli config check")
+			os.Exit(1)
+		}
+		config_check()
+		return
+	}
+
 	// Load configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// 迁移用单独的连接，数据库是 dirty 状态时也能执行
+	if os.Args[1] == "migrate" {
+		migrate_cmd(cfg, os.Args[2:])
+		return
+	}
+	// 恢复会替换数据库文件，不能先连上数据库
+	if os.Args[1] == "restore" {
+		if len(os.Args) != 3 {
+			fmt.Println("Usage: cli restore <backup_file>")
+			os.Exit(1)
+		}
+		restore(cfg, os.Args[2])
+		return
+	}
+
 	// Connect to database
-	database, err := db.NewDatabase(cfg)
+	database, err := db.NewDatabase(cfg, logger.New(cfg.LoggerOptions()))
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
@@ -44,12 +84,35 @@ func main() {
 		email := os.Args[2]
 		newPassword := os.Args[3]
 		update_pwd(database, email, newPassword)
+	case "process_deletions":
+		process_deletions(database, cfg.DefaultAvatarURL)
+	case "backup":
+		backup_cmd(cfg, database, os.Args[2:])
+	case "transfer":
+		transfer_cmd(cfg, database, os.Args[2:])
 	default:
 		fmt.Printf("Unknown command: %s\n", command)
 		os.Exit(1)
 	}
 }
 
+// config_check prints the effective configuration with secrets hidden and validates it
+func config_check() {
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	for _, s := range config.Settings() {
+		fmt.Printf("%s=%s\n", s.Key, s.Value)
+	}
+	fmt.Println()
+	if err := cfg.Validate(); err != nil {
+		fmt.Printf("Config for %s is invalid:\n%v\n", cfg.Environment, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Config for %s is valid\n", cfg.Environment)
+}
+
 // update_pwd updates a user's password by email
 func update_pwd(db *gorm.DB, email, new_pwd string) {
 	var account models.CoachAccount
@@ -83,3 +146,180 @@ func update_pwd(db *gorm.DB, email, new_pwd string) {
 
 	fmt.Printf("Password updated successfully for email: %s\n", email)
 }
+
+// process_deletions purges accounts whose deletion grace period has passed
+func process_deletions(db *gorm.DB, default_avatar_url string) {
+	count, err := userdata.ProcessDeletionRequests(db, time.Now(), default_avatar_url)
+	if err != nil {
+		log.Fatalf("Failed to process deletion requests: %v", err)
+	}
+	fmt.Printf("Processed %d deletion request(s)\n", count)
+}
+
+// backup_cmd writes a snapshot of the database, flags override the BACKUP_* config
+func backup_cmd(cfg *config.Config, database *gorm.DB, args []string) {
+	opts := cfg.BackupOptions()
+	flags := flag.NewFlagSet("backup", flag.ExitOnError)
+	flags.StringVar(&opts.Dir, "dir", opts.Dir, "directory to write the backup to")
+	flags.IntVar(&opts.Keep, "keep", opts.Keep, "number of most recent backups to keep, 0 keeps all")
+	flags.BoolVar(&opts.Gzip, "gzip", opts.Gzip, "compress the backup with gzip")
+	flags.Parse(args)
+
+	path, err := backup.Backup(context.Background(), database, opts)
+	if err != nil {
+		log.Fatalf("Backup failed: %v", err)
+	}
+	fmt.Printf("Backup written to %s\n", path)
+}
+
+// restore replaces the SQLite database with a backup, the server must be stopped first
+func restore(cfg *config.Config, file string) {
+	if cfg.DBType != "sqlite" {
+		log.Fatalf("Restore only supports sqlite, use the native tools for %s", cfg.DBType)
+	}
+	previous, err := backup.Restore(file, cfg.DBPath)
+	if err != nil {
+		log.Fatalf("Restore failed: %v", err)
+	}
+	fmt.Printf("Database restored from %s\n", file)
+	if previous != "" {
+		fmt.Printf("Previous database kept at %s\n", previous)
+	}
+}
+
+// transfer_cmd copies every table of the configured database into another database
+func transfer_cmd(cfg *config.Config, database *gorm.DB, args []string) {
+	target := *cfg
+	target.DBType = ""
+	chunk := 500
+	truncate := false
+	flags := flag.NewFlagSet("transfer", flag.ExitOnError)
+	flags.StringVar(&target.DBType, "to-type", "", "target database type: postgres, mysql or sqlite")
+	flags.StringVar(&target.DBHost, "to-host", cfg.DBHost, "target database host")
+	flags.StringVar(&target.DBPort, "to-port", cfg.DBPort, "target database port")
+	flags.StringVar(&target.DBUser, "to-user", cfg.DBUser, "target database user")
+	flags.StringVar(&target.DBPassword, "to-password", cfg.DBPassword, "target database password")
+	flags.StringVar(&target.DBName, "to-name", cfg.DBName, "target database name")
+	flags.StringVar(&target.DBPath, "to-path", "", "target sqlite file")
+	flags.IntVar(&chunk, "chunk", chunk, "rows per batch")
+	flags.BoolVar(&truncate, "truncate", false, "delete existing rows in the target tables first")
+	flags.Parse(args)
+	if target.DBType == "" {
+		flags.Usage()
+		os.Exit(1)
+	}
+	if target.DBType == cfg.DBType && target.DBType == "sqlite" && target.DBPath == cfg.DBPath {
+		log.Fatalf("Source and target are the same database")
+	}
+
+	l := logger.New(cfg.LoggerOptions())
+	target_db, err := db.NewDatabase(&target, l)
+	if err != nil {
+		log.Fatalf("Failed to connect to target database: %v", err)
+	}
+	results, err := transfer.Transfer(context.Background(), database, target_db, transfer.Options{
+		ChunkSize: chunk,
+		Truncate:  truncate,
+		Logger:    l,
+	})
+	for _, r := range results {
+		fmt.Printf("%-40s %10d rows  checksum %s\n", r.Table, r.Rows, r.Checksum)
+	}
+	if err != nil {
+		log.Fatalf("Transfer failed: %v", err)
+	}
+	fmt.Printf("Transferred %d table(s), row counts and checksums match\n", len(results))
+}
+
+const migrateUsage = `Usage: cli migrate <command>
+  status        show current version, dirty flag and pending migrations
+  up [N]        apply all pending migrations, or the next N
+  down N        roll back N migrations
+  goto V        migrate up or down to version V
+  force V       set version V and clear the dirty flag without running SQL (-1 means no version)
+  create NAME   create empty up/down files for the next version`
+
+// migrate_cmd runs migration subcommands against the configured database
+func migrate_cmd(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		fmt.Println(migrateUsage)
+		os.Exit(1)
+	}
+	migrator := db.NewMigrator(cfg, logger.New(cfg.LoggerOptions()))
+	// 需要一个数字参数的子命令
+	arg := func() int {
+		if len(args) != 2 {
+			fmt.Println(migrateUsage)
+			os.Exit(1)
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil {
+			log.Fatalf("Invalid number %q: %v", args[1], err)
+		}
+		return n
+	}
+
+	var err error
+	switch args[0] {
+	case "status":
+		var status *db.MigrationStatus
+		status, err = migrator.Status()
+		if err != nil {
+			break
+		}
+		fmt.Printf("Current version: %d\n", status.Version)
+		fmt.Printf("Latest version:  %d\n", status.Latest)
+		if status.Dirty {
+			fmt.Printf("Dirty: yes, fix the schema by hand then run `cli migrate force %d` (or the version it now matches)\n", status.Version)
+		}
+		if len(status.Pending) == 0 {
+			fmt.Println("No pending migrations")
+			break
+		}
+		fmt.Println("Pending migrations:")
+		for _, f := range status.Pending {
+			fmt.Printf("  %06d %s\n", f.Version, f.Name)
+		}
+	case "up":
+		if len(args) == 1 {
+			err = migrator.MigrateUp()
+		} else {
+			err = migrator.MigrateSteps(arg())
+		}
+	case "down":
+		n := arg()
+		if n <= 0 {
+			log.Fatalf("N must be greater than 0")
+		}
+		err = migrator.MigrateSteps(-n)
+	case "goto":
+		v := arg()
+		if v < 0 {
+			log.Fatalf("Version must not be negative")
+		}
+		err = migrator.MigrateTo(uint(v))
+	case "force":
+		err = migrator.Force(arg())
+	case "create":
+		if len(args) != 2 {
+			fmt.Println(migrateUsage)
+			os.Exit(1)
+		}
+		// 新文件写到迁移目录，MIGRATIONS_PATH 没有指向目录时写到 ./migrations
+		dir := "migrations"
+		if path, ok := strings.CutPrefix(cfg.MigrationsPath, "file://"); ok {
+			dir = path
+		}
+		var paths []string
+		paths, err = db.CreateMigration(dir, args[1])
+		for _, path := range paths {
+			fmt.Printf("Created %s\n", path)
+		}
+	default:
+		fmt.Println(migrateUsage)
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+}
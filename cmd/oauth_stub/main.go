@@ -0,0 +1,24 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"myapi/pkg/oauth"
+)
+
+// 本地开发用的假 OAuth 服务，配合下面的配置使用
+// OAUTH_AUTHORIZE_URL=http://localhost:9999/authorize
+// OAUTH_TOKEN_URL=http://localhost:9999/token
+// OAUTH_USERINFO_URL=http://localhost:9999/userinfo
+func main() {
+	addr := ":9999"
+	if len(os.Args) > 1 {
+		addr = os.Args[1]
+	}
+	log.Printf("OAuth stub server listening on %s", addr)
+	if err := http.ListenAndServe(addr, oauth.NewStubServer()); err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"myapi/config"
+	"myapi/internal/api/openapi"
+	"myapi/internal/api/routes"
+	"myapi/internal/pkg/errcode"
+	"myapi/pkg/logger"
+)
+
+// 根据路由表生成 pkg/client 里的类型和接口方法
+// go run ./cmd/sdkgen -o pkg/client
+func main() {
+	out := flag.String("o", "pkg/client", "output directory")
+	flag.Parse()
+
+	gin.SetMode(gin.ReleaseMode)
+	r := routes.SetupRouter(nil, logger.NewLogger("error"), &config.Config{})
+
+	var list []endpoint
+	for _, route := range r.Routes() {
+		// 健康检查之类的给部署用，不是 {code, msg, data} 的格式，不生成
+		if !strings.HasPrefix(route.Path, "/api/") {
+			continue
+		}
+		op, ok := routes.Operations[openapi.Key(route.Method, route.Path)]
+		if !ok {
+			log.Fatalf("route %s %s has no operation", route.Method, route.Path)
+		}
+		// 没有 Response 的是 system 分组里的接口，比如 openapi.json，也不是 {code, msg, data} 的格式
+		if op.Response == nil {
+			continue
+		}
+		list = append(list, endpoint{Method: route.Method, Path: route.Path, Operation: op})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Tag != list[j].Tag {
+			return list[i].Tag < list[j].Tag
+		}
+		return list[i].Path < list[j].Path
+	})
+
+	g := newGenerator()
+	files := map[string][]byte{
+		"api_gen.go":    g.services(list),
+		"errors_gen.go": g.errors(),
+	}
+	// 类型定义是生成接口方法时收集的，要放在最后
+	files["types_gen.go"] = g.types()
+	for name, src := range files {
+		formatted, err := format.Source(src)
+		if err != nil {
+			log.Fatalf("format %s: %v\n%s", name, err, src)
+		}
+		if err := os.WriteFile(filepath.Join(*out, name), formatted, 0644); err != nil {
+			log.Fatalf("write %s: %v", name, err)
+		}
+	}
+}
+
+type endpoint struct {
+	Method string
+	Path   string
+	openapi.Operation
+}
+
+const header = "// Code generated by cmd/sdkgen. DO NOT EDIT.\n\npackage client\n\n"
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+type generator struct {
+	names map[reflect.Type]string
+	taken map[string]bool
+	defs  map[string]string
+}
+
+func newGenerator() *generator {
+	return &generator{
+		names: map[reflect.Type]string{},
+		taken: map[string]bool{},
+		defs:  map[string]string{},
+	}
+}
+
+// goType 请求、响应里的类型在 client 包里的写法，具名结构体会生成对应的定义
+func (g *generator) goType(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		return "*" + g.goType(t.Elem())
+	}
+	if t == timeType {
+		return "time.Time"
+	}
+	// 自定义了反序列化的类型（比如 LocalTime、gorm.DeletedAt）都是按字符串传的
+	if t.Kind() == reflect.Struct && reflect.PtrTo(t).Implements(unmarshalerType) {
+		return "string"
+	}
+	switch t.Kind() {
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "[]byte"
+		}
+		return "[]" + g.goType(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), g.goType(t.Elem()))
+	case reflect.Map:
+		return "map[" + g.goType(t.Key()) + "]" + g.goType(t.Elem())
+	case reflect.Interface:
+		return "interface{}"
+	case reflect.Struct:
+		if t.Name() == "" {
+			return "struct {\n" + g.fields(t) + "}"
+		}
+		return g.named(t)
+	}
+	return t.Kind().String()
+}
+
+func (g *generator) named(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := openapi.TypeName(t)
+	if g.taken[name] {
+		pkg := t.PkgPath()
+		pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	g.names[t] = name
+	g.taken[name] = true
+	g.defs[name] = "type " + name + " struct {\n" + g.fields(t) + "}\n"
+	return name
+}
+
+func (g *generator) fields(t reflect.Type) string {
+	var b strings.Builder
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		typ := g.goType(f.Type)
+		if f.Anonymous && tag == "" {
+			// 嵌入的结构体保持嵌入，JSON 字段一样是展开的
+			if strings.TrimPrefix(typ, "*") != "string" {
+				fmt.Fprintf(&b, "\t%s\n", typ)
+				continue
+			}
+		}
+		if tag != "" {
+			fmt.Fprintf(&b, "\t%s %s `json:\"%s\"`\n", f.Name, typ, tag)
+		} else {
+			fmt.Fprintf(&b, "\t%s %s\n", f.Name, typ)
+		}
+	}
+	return b.String()
+}
+
+func (g *generator) types() []byte {
+	var b bytes.Buffer
+	b.WriteString(header)
+	names := make([]string, 0, len(g.defs))
+	uses_time := false
+	for name, def := range g.defs {
+		names = append(names, name)
+		uses_time = uses_time || strings.Contains(def, "time.Time")
+	}
+	sort.Strings(names)
+	if uses_time {
+		b.WriteString("import \"time\"\n\n")
+	}
+	for _, name := range names {
+		b.WriteString(g.defs[name])
+		b.WriteString("\n")
+	}
+	return b.Bytes()
+}
+
+func (g *generator) services(list []endpoint) []byte {
+	var b bytes.Buffer
+	b.WriteString(header)
+	b.WriteString("import \"context\"\n\n")
+	g.idempotent(&b, list)
+
+	var tags []string
+	groups := map[string][]endpoint{}
+	for _, e := range list {
+		if _, ok := groups[e.Tag]; !ok {
+			tags = append(tags, e.Tag)
+		}
+		groups[e.Tag] = append(groups[e.Tag], e)
+	}
+
+	b.WriteString("// services 按路由分组的接口，嵌入到 Client 里\ntype services struct {\n")
+	for _, tag := range tags {
+		fmt.Fprintf(&b, "\t%s *%sService\n", camel(tag), camel(tag))
+	}
+	b.WriteString("}\n\nfunc (s *services) init(c *Client) {\n")
+	for _, tag := range tags {
+		fmt.Fprintf(&b, "\ts.%s = &%sService{c: c}\n", camel(tag), camel(tag))
+	}
+	b.WriteString("}\n\n")
+
+	for _, tag := range tags {
+		service := camel(tag) + "Service"
+		fmt.Fprintf(&b, "// %s %s 相关的接口\ntype %s struct {\n\tc *Client\n}\n\n", service, tag, service)
+		seen := map[string]string{}
+		for _, e := range groups[tag] {
+			method := methodName(tag, e.Path)
+			if prev, ok := seen[method]; ok {
+				log.Fatalf("method %s.%s is used by both %s and %s", service, method, prev, e.Path)
+			}
+			seen[method] = e.Path
+			g.method(&b, service, method, e)
+		}
+	}
+	return b.Bytes()
+}
+
+func (g *generator) method(b *bytes.Buffer, service, method string, e endpoint) {
+	params := "ctx context.Context"
+	body := "nil"
+	if e.Request != nil && !openapi.IsNone(e.Request) {
+		params += ", req " + g.goType(reflect.TypeOf(e.Request))
+		body = "req"
+	}
+	summary := e.Summary
+	if summary == "" {
+		summary = e.Method + " " + e.Path
+	}
+	fmt.Fprintf(b, "// %s %s\n", method, summary)
+	call := fmt.Sprintf("s.c.do(ctx, %q, %q, %s, &out)", e.Method, e.Path, body)
+	if openapi.IsNone(e.Response) {
+		fmt.Fprintf(b, "func (s *%s) %s(%s) error {\n", service, method, params)
+		fmt.Fprintf(b, "\treturn s.c.do(ctx, %q, %q, %s, nil)\n}\n\n", e.Method, e.Path, body)
+		return
+	}
+	if _, ok := e.Response.(openapi.File); ok {
+		fmt.Fprintf(b, "func (s *%s) %s(%s) ([]byte, error) {\n", service, method, params)
+		fmt.Fprintf(b, "\tvar out download\n\tif err := %s; err != nil {\n\t\treturn nil, err\n\t}\n\treturn out, nil\n}\n\n", call)
+		return
+	}
+	// 结构体返回指针，data 为 null 时是 nil；切片、map 直接返回
+	t := reflect.TypeOf(e.Response)
+	result := g.goType(t)
+	if t.Kind() == reflect.Struct {
+		result = "*" + result
+	}
+	fmt.Fprintf(b, "func (s *%s) %s(%s) (%s, error) {\n", service, method, params, result)
+	fmt.Fprintf(b, "\tvar out %s\n\tif err := %s; err != nil {\n\t\treturn nil, err\n\t}\n\treturn out, nil\n}\n\n", result, call)
+}
+
+// idempotent 标记了 Idempotent 的接口，客户端遇到网关错误时只重试这些
+func (g *generator) idempotent(b *bytes.Buffer, list []endpoint) {
+	b.WriteString("// idempotent 重复请求没有副作用的接口，遇到 502、503、504 时可以重试\nvar idempotent = map[string]bool{\n")
+	for _, e := range list {
+		if e.Idempotent {
+			fmt.Fprintf(b, "\t%q: true,\n", e.Path)
+		}
+	}
+	b.WriteString("}\n\n")
+}
+
+func (g *generator) errors() []byte {
+	var b bytes.Buffer
+	b.WriteString(header)
+	b.WriteString("// 服务端定义的错误码，可以用 errors.Is(err, client.ErrNotFound) 判断\nvar (\n")
+	for _, e := range errcode.List() {
+		fmt.Fprintf(&b, "\t// %s\n\tErr%s = &Error{Status: %d, Code: %d}\n", strings.ReplaceAll(e.Message(errcode.DefaultLang), "%d", "N"), camel(e.Key), e.Status, e.Code)
+	}
+	b.WriteString(")\n")
+	return b.Bytes()
+}
+
+var initialisms = map[string]string{"id": "ID", "ids": "IDs", "url": "URL", "mfa": "MFA", "totp": "TOTP", "oauth": "OAuth", "sms": "SMS", "2fa": "TwoFA", "json": "JSON"}
+
+// camel workout_day 转成 WorkoutDay
+func camel(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '_' || r == '/' || r == '-' || r == '.'
+	})
+	for i, w := range words {
+		if v, ok := initialisms[w]; ok {
+			words[i] = v
+			continue
+		}
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, "")
+}
+
+// methodName 去掉路径里分组的前缀，/api/workout_day/update_steps 在 workout_day 分组里是 UpdateSteps
+func methodName(tag, path string) string {
+	path = strings.TrimPrefix(path, "/api")
+	rest := strings.TrimPrefix(path, "/"+tag+"/")
+	if rest == path || rest == "" {
+		rest = path
+	}
+	return camel(rest)
+}
@@ -1,11 +1,19 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"myapi/config"
 	"myapi/internal/api/routes"
 	"myapi/internal/db"
+	"myapi/internal/pkg/backup"
+	"myapi/internal/pkg/lifecycle"
+	"myapi/internal/pkg/metrics"
+	"myapi/internal/pkg/userdata"
 	"myapi/pkg/logger"
+	"net/http"
+	"time"
 )
 
 func main() {
@@ -16,17 +24,29 @@ func main() {
 	}
 
 	// 初始化日志
-	logger := logger.NewLogger(cfg.LogLevel)
+	logger := logger.New(cfg.LoggerOptions())
 	defer logger.Sync()
 
 	// 初始化数据库连接
-	database, err := db.NewDatabase(cfg)
+	database, err := db.NewDatabase(cfg, logger)
 	if err != nil {
 		logger.Fatal("Failed to connect to database", err)
 	}
 
-	// 运行数据库迁移
+	// 导出连接池状态
+	sql_db, err := database.DB()
+	if err != nil {
+		logger.Fatal("Failed to get database connection pool", err)
+	}
+	if err := metrics.RegisterDB(sql_db, cfg.DBType); err != nil {
+		logger.Fatal("Failed to register database metrics", err)
+	}
+
+	// 运行数据库迁移，上次迁移失败留下 dirty 状态时不启动，需要先用 cli migrate force 处理
 	migrator := db.NewMigrator(cfg, logger)
+	if err := migrator.CheckClean(); err != nil {
+		logger.Fatal("Refusing to start", err)
+	}
 	if err := migrator.MigrateUp(); err != nil {
 		logger.Fatal("Failed to run migrations", err)
 	}
@@ -34,9 +54,42 @@ func main() {
 	// 设置路由
 	r := routes.SetupRouter(database, logger, cfg)
 
-	// 启动服务器
-	logger.Info("Starting server on " + cfg.ServerAddress)
-	if err := r.Run(cfg.ServerAddress); err != nil {
+	server := &http.Server{
+		Addr:         cfg.ServerAddress,
+		Handler:      r,
+		ReadTimeout:  time.Duration(cfg.ServerReadTimeout) * time.Second,
+		WriteTimeout: time.Duration(cfg.ServerWriteTimeout) * time.Second,
+		IdleTimeout:  time.Duration(cfg.ServerIdleTimeout) * time.Second,
+	}
+	app := lifecycle.New(logger, time.Duration(cfg.ShutdownTimeout)*time.Second)
+
+	// 后台任务
+	if cfg.DeletionCheckInterval > 0 {
+		app.Every("process_deletions", time.Duration(cfg.DeletionCheckInterval)*time.Minute, func(ctx context.Context) error {
+			count, err := userdata.ProcessDeletionRequests(database.WithContext(ctx), time.Now(), cfg.DefaultAvatarURL)
+			if count > 0 {
+				logger.Info(fmt.Sprintf("Processed %d deletion request(s)", count))
+			}
+			return err
+		})
+	}
+	if cfg.BackupInterval > 0 && cfg.DBType == "sqlite" {
+		app.Every("backup", time.Duration(cfg.BackupInterval)*time.Hour, func(ctx context.Context) error {
+			path, err := backup.Backup(ctx, database, cfg.BackupOptions())
+			if err == nil {
+				logger.Infow("Database backed up", "path", path)
+			}
+			return err
+		})
+	}
+
+	// 请求都处理完之后再关闭数据库连接
+	app.OnStop("database", func(ctx context.Context) error {
+		return sql_db.Close()
+	})
+
+	// 启动服务器，收到退出信号后等待处理中的请求完成
+	if err := app.Run(server); err != nil {
 		logger.Fatal("Failed to start server", err)
 	}
 }
@@ -2,17 +2,57 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"sort"
 	"strings"
 
 	"github.com/spf13/viper"
+
+	"myapi/internal/pkg/backup"
+	"myapi/pkg/logger"
+)
+
+// 运行环境
+const (
+	EnvDevelopment = "development"
+	EnvTest        = "test"
+	EnvProduction  = "production"
 )
 
+// devTokenSecretKey 开发环境默认的凭证密钥，生产环境不能使用
+const devTokenSecretKey = "fithub"
+
+// secretKeys 这些配置可以设置 <KEY>_FILE 从文件读取，比如 Docker、Kubernetes 挂载的 secret，cli config check 输出时会隐藏
+var secretKeys = []string{
+	"DB_PASSWORD",
+	"QINIU_ACCESS_KEY",
+	"QINIU_SECRET_KEY",
+	"TOKEN_SECRET_KEY",
+	"SMTP_PASSWORD",
+	"OAUTH_CLIENT_SECRET",
+}
+
 // Config 存储所有配置
 type Config struct {
 	// 服务器配置
 	ServerAddress string
 	Environment   string
 	LogLevel      string
+	// 日志格式 json 或 console，为空时生产环境用 json，其他用 console
+	LogFormat string
+	// 日志文件，为空时输出到标准输出；文件按 LogMaxSize（MB）切分，保留 LogMaxBackups 个、LogMaxAge 天
+	LogFile       string
+	LogMaxSize    int
+	LogMaxBackups int
+	LogMaxAge     int
+	// 相同的日志每秒超过 100 条后采样记录
+	LogSampling bool
+	// 读取请求、写入响应、空闲连接的超时，单位秒
+	ServerReadTimeout  int
+	ServerWriteTimeout int
+	ServerIdleTimeout  int
+	// 停止服务时等待处理中的请求完成的最长时间，单位秒
+	ShutdownTimeout int
 
 	// 数据库配置
 	DBType     string // mysql, postgres, sqlite
@@ -22,8 +62,10 @@ type Config struct {
 	DBPassword string
 	DBName     string
 	DBPath     string // 用于SQLite
+	// 超过这个时间的 SQL 记为慢查询，单位毫秒
+	DBSlowThreshold int
 
-	// 迁移配置
+	// 迁移配置，为空时用打包进程序的迁移文件，开发时可以设成 file://migrations 直接读目录
 	MigrationsPath string
 
 	// 七牛云
@@ -33,10 +75,79 @@ type Config struct {
 
 	// 用户凭证
 	TokenSecretKey string
+
+	// 上传文件的访问地址前缀，以 / 结尾
+	MediaURLPrefix string
+	// 默认头像，为空时用 MediaURLPrefix 下的 avatars/default1.jpeg
+	DefaultAvatarURL string
+	// H5 站点地址，邀请和代登录的链接指向这里
+	MobileSiteURL string
+
+	// 动态缓存时长，单位秒，0表示不缓存
+	FeedCacheTTL int
+
+	// 邮件，MailDriver 为 smtp 时真正发送，否则写到日志或 MailLogPath 文件
+	MailDriver   string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	MailFrom     string
+	MailLogPath  string
+	// 注册时是否必须填写邮箱验证码
+	RequireEmailVerification bool
+	// 管理员访问管理接口时是否必须通过两步验证
+	AdminRequire2FA bool
+
+	// 注销帐号的冷静期，单位天
+	AccountDeletionGraceDays int
+	// 服务内清除到期注销帐号的间隔，单位分钟，0 表示不在服务内处理，由 cli process_deletions 执行
+	DeletionCheckInterval int
+
+	// 备份，只支持 SQLite。BackupInterval 单位小时，0 表示不在服务内备份，由 cli backup 执行
+	BackupDir      string
+	BackupInterval int
+	BackupKeep     int
+	BackupGzip     bool
+
+	// 短信，目前只有 log，写到日志
+	SMSDriver string
+
+	// 第三方登录，OAuthClientId 为空表示不启用
+	OAuthName         string
+	OAuthClientId     string
+	OAuthClientSecret string
+	OAuthAuthorizeURL string
+	OAuthTokenURL     string
+	OAuthUserInfoURL  string
+	OAuthRedirectURL  string
+	OAuthScope        string
+
+	// 是否按接口文档校验请求体
+	OpenAPIValidate bool
+
+	// 是否限制登录、注册等接口的请求频率，具体规则在 routes.SetupRouter 里
+	RateLimitEnabled bool
+	// 可信的反向代理，只有来自这些地址的请求才使用 X-Forwarded-For 里的客户端 IP
+	TrustedProxies []string
 }
 
-// LoadConfig 从环境变量或配置文件加载配置
+// LoadConfig 从环境变量或配置文件加载配置，配置不正确时返回错误
 func LoadConfig() (*Config, error) {
+	config, err := ReadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+	return config, nil
+}
+
+// ReadConfig 读取配置但不校验，cli config check 用来列出所有问题
+//
+// 先读 .env，再读 .env.<ENVIRONMENT> 覆盖，环境变量的优先级最高
+func ReadConfig() (*Config, error) {
 	viper.SetConfigName(".env")
 	viper.SetConfigType("env")
 	viper.AddConfigPath(".")
@@ -48,11 +159,41 @@ func LoadConfig() (*Config, error) {
 			return nil, fmt.Errorf("error reading config file: %w", err)
 		}
 	}
+	if env := viper.GetString("ENVIRONMENT"); env != "" {
+		path := ".env." + env
+		if _, err := os.Stat(path); err == nil {
+			viper.SetConfigFile(path)
+			if err := viper.MergeInConfig(); err != nil {
+				return nil, fmt.Errorf("error reading config file %s: %w", path, err)
+			}
+		}
+	}
+	for _, key := range secretKeys {
+		path := viper.GetString(key + "_FILE")
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s_FILE: %w", key, err)
+		}
+		viper.Set(key, strings.TrimRight(string(data), "\r\n"))
+	}
 
 	// 设置默认值
 	viper.SetDefault("SERVER_ADDRESS", ":8080")
-	viper.SetDefault("ENVIRONMENT", "development")
+	viper.SetDefault("ENVIRONMENT", EnvDevelopment)
 	viper.SetDefault("LOG_LEVEL", "info")
+	viper.SetDefault("LOG_FORMAT", "")
+	viper.SetDefault("LOG_FILE", "")
+	viper.SetDefault("LOG_MAX_SIZE", 100)
+	viper.SetDefault("LOG_MAX_BACKUPS", 7)
+	viper.SetDefault("LOG_MAX_AGE", 30)
+	viper.SetDefault("LOG_SAMPLING", true)
+	viper.SetDefault("SERVER_READ_TIMEOUT", 15)
+	viper.SetDefault("SERVER_WRITE_TIMEOUT", 60)
+	viper.SetDefault("SERVER_IDLE_TIMEOUT", 120)
+	viper.SetDefault("SHUTDOWN_TIMEOUT", 30)
 	viper.SetDefault("DB_TYPE", "sqlite")
 	viper.SetDefault("DB_HOST", "localhost")
 	viper.SetDefault("DB_PORT", "5432")
@@ -60,11 +201,43 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("DB_PASSWORD", "postgres")
 	viper.SetDefault("DB_NAME", "myapi")
 	viper.SetDefault("DB_PATH", "./myapi.db")
-	viper.SetDefault("MIGRATIONS_PATH", "file:///migrations")
+	viper.SetDefault("DB_SLOW_THRESHOLD", 200)
+	viper.SetDefault("MIGRATIONS_PATH", "")
 	viper.SetDefault("QINIU_ACCESS_KEY", "")
 	viper.SetDefault("QINIU_SECRET_KEY", "")
 	viper.SetDefault("QINIU_BUCKET", "")
-	viper.SetDefault("TOKEN_SECRET_KEY", "fithub")
+	viper.SetDefault("TOKEN_SECRET_KEY", devTokenSecretKey)
+	viper.SetDefault("MEDIA_URL_PREFIX", "//static.fithub.top/")
+	viper.SetDefault("DEFAULT_AVATAR_URL", "")
+	viper.SetDefault("MOBILE_SITE_URL", "https://h5.fithub.top")
+	viper.SetDefault("FEED_CACHE_TTL", 0)
+	viper.SetDefault("MAIL_DRIVER", "log")
+	viper.SetDefault("SMTP_HOST", "")
+	viper.SetDefault("SMTP_PORT", "587")
+	viper.SetDefault("SMTP_USERNAME", "")
+	viper.SetDefault("SMTP_PASSWORD", "")
+	viper.SetDefault("MAIL_FROM", "")
+	viper.SetDefault("MAIL_LOG_PATH", "")
+	viper.SetDefault("REQUIRE_EMAIL_VERIFICATION", false)
+	viper.SetDefault("ADMIN_REQUIRE_2FA", true)
+	viper.SetDefault("ACCOUNT_DELETION_GRACE_DAYS", 14)
+	viper.SetDefault("DELETION_CHECK_INTERVAL", 60)
+	viper.SetDefault("BACKUP_DIR", "./backups")
+	viper.SetDefault("BACKUP_INTERVAL", 0)
+	viper.SetDefault("BACKUP_KEEP", 7)
+	viper.SetDefault("BACKUP_GZIP", true)
+	viper.SetDefault("SMS_DRIVER", "log")
+	viper.SetDefault("OAUTH_NAME", "oauth")
+	viper.SetDefault("OAUTH_CLIENT_ID", "")
+	viper.SetDefault("OAUTH_CLIENT_SECRET", "")
+	viper.SetDefault("OAUTH_AUTHORIZE_URL", "")
+	viper.SetDefault("OAUTH_TOKEN_URL", "")
+	viper.SetDefault("OAUTH_USERINFO_URL", "")
+	viper.SetDefault("OAUTH_REDIRECT_URL", "")
+	viper.SetDefault("OAUTH_SCOPE", "")
+	viper.SetDefault("OPENAPI_VALIDATE", false)
+	viper.SetDefault("RATE_LIMIT_ENABLED", true)
+	viper.SetDefault("TRUSTED_PROXIES", "127.0.0.1,::1")
 
 	config := &Config{
 		ServerAddress:  viper.GetString("SERVER_ADDRESS"),
@@ -82,7 +255,143 @@ func LoadConfig() (*Config, error) {
 		QiniuSecretKey: viper.GetString("QINIU_SECRET_KEY"),
 		QiniuBucket:    viper.GetString("QINIU_BUCKET"),
 		TokenSecretKey: viper.GetString("TOKEN_SECRET_KEY"),
+		MediaURLPrefix: viper.GetString("MEDIA_URL_PREFIX"),
+		MobileSiteURL:  viper.GetString("MOBILE_SITE_URL"),
+		FeedCacheTTL:   viper.GetInt("FEED_CACHE_TTL"),
+		MailDriver:     viper.GetString("MAIL_DRIVER"),
+		SMTPHost:       viper.GetString("SMTP_HOST"),
+		SMTPPort:       viper.GetString("SMTP_PORT"),
+		SMTPUsername:   viper.GetString("SMTP_USERNAME"),
+		SMTPPassword:   viper.GetString("SMTP_PASSWORD"),
+		MailFrom:       viper.GetString("MAIL_FROM"),
+		MailLogPath:    viper.GetString("MAIL_LOG_PATH"),
+
+		RequireEmailVerification: viper.GetBool("REQUIRE_EMAIL_VERIFICATION"),
+		AdminRequire2FA:          viper.GetBool("ADMIN_REQUIRE_2FA"),
+		AccountDeletionGraceDays: viper.GetInt("ACCOUNT_DELETION_GRACE_DAYS"),
+
+		SMSDriver:         viper.GetString("SMS_DRIVER"),
+		OAuthName:         viper.GetString("OAUTH_NAME"),
+		OAuthClientId:     viper.GetString("OAUTH_CLIENT_ID"),
+		OAuthClientSecret: viper.GetString("OAUTH_CLIENT_SECRET"),
+		OAuthAuthorizeURL: viper.GetString("OAUTH_AUTHORIZE_URL"),
+		OAuthTokenURL:     viper.GetString("OAUTH_TOKEN_URL"),
+		OAuthUserInfoURL:  viper.GetString("OAUTH_USERINFO_URL"),
+		OAuthRedirectURL:  viper.GetString("OAUTH_REDIRECT_URL"),
+		OAuthScope:        viper.GetString("OAUTH_SCOPE"),
+
+		OpenAPIValidate:  viper.GetBool("OPENAPI_VALIDATE"),
+		RateLimitEnabled: viper.GetBool("RATE_LIMIT_ENABLED"),
+		TrustedProxies:   splitList(viper.GetString("TRUSTED_PROXIES")),
+
+		LogFormat:       viper.GetString("LOG_FORMAT"),
+		LogFile:         viper.GetString("LOG_FILE"),
+		LogMaxSize:      viper.GetInt("LOG_MAX_SIZE"),
+		LogMaxBackups:   viper.GetInt("LOG_MAX_BACKUPS"),
+		LogMaxAge:       viper.GetInt("LOG_MAX_AGE"),
+		LogSampling:     viper.GetBool("LOG_SAMPLING"),
+		DBSlowThreshold: viper.GetInt("DB_SLOW_THRESHOLD"),
+
+		ServerReadTimeout:     viper.GetInt("SERVER_READ_TIMEOUT"),
+		ServerWriteTimeout:    viper.GetInt("SERVER_WRITE_TIMEOUT"),
+		ServerIdleTimeout:     viper.GetInt("SERVER_IDLE_TIMEOUT"),
+		ShutdownTimeout:       viper.GetInt("SHUTDOWN_TIMEOUT"),
+		DeletionCheckInterval: viper.GetInt("DELETION_CHECK_INTERVAL"),
+
+		BackupDir:      viper.GetString("BACKUP_DIR"),
+		BackupInterval: viper.GetInt("BACKUP_INTERVAL"),
+		BackupKeep:     viper.GetInt("BACKUP_KEEP"),
+		BackupGzip:     viper.GetBool("BACKUP_GZIP"),
+
+		DefaultAvatarURL: viper.GetString("DEFAULT_AVATAR_URL"),
+	}
+	if config.DefaultAvatarURL == "" {
+		config.DefaultAvatarURL = config.AvatarURL("default1.jpeg")
 	}
 
 	return config, nil
 }
+
+// splitList 逗号分隔的列表，忽略空项
+func splitList(v string) []string {
+	var list []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// Setting 一项配置和生效的值
+type Setting struct {
+	Key   string
+	Value string
+}
+
+// Settings 按名称列出读取到的所有配置，密钥只显示有没有设置
+func Settings() []Setting {
+	keys := viper.AllKeys()
+	sort.Strings(keys)
+	var settings []Setting
+	for _, key := range keys {
+		name := strings.ToUpper(key)
+		value := viper.GetString(key)
+		for _, secret := range secretKeys {
+			if name == secret && value != "" {
+				value = "******"
+			}
+		}
+		settings = append(settings, Setting{Key: name, Value: value})
+	}
+	return settings
+}
+
+// IsProduction 是否生产环境
+func (c *Config) IsProduction() bool {
+	return c.Environment == EnvProduction
+}
+
+// MediaURL 上传文件的访问地址
+func (c *Config) MediaURL(key string) string {
+	return c.MediaURLPrefix + key
+}
+
+// AvatarURL 头像的访问地址，头像都放在 avatars/ 下
+func (c *Config) AvatarURL(name string) string {
+	return c.MediaURL("avatars/" + name)
+}
+
+// MobileURL H5 站点上的页面地址
+func (c *Config) MobileURL(path string) string {
+	return c.MobileSiteURL + path
+}
+
+// BackupOptions 备份配置
+func (c *Config) BackupOptions() backup.Options {
+	return backup.Options{
+		Dir:  c.BackupDir,
+		Gzip: c.BackupGzip,
+		Keep: c.BackupKeep,
+	}
+}
+
+// LoggerOptions 日志配置
+func (c *Config) LoggerOptions() logger.Options {
+	format := c.LogFormat
+	if format == "" {
+		format = "console"
+		if c.IsProduction() {
+			format = "json"
+		}
+	}
+	return logger.Options{
+		Level:      c.LogLevel,
+		Format:     format,
+		File:       c.LogFile,
+		MaxSize:    c.LogMaxSize,
+		MaxBackups: c.LogMaxBackups,
+		MaxAge:     c.LogMaxAge,
+		Sampling:   c.LogSampling,
+	}
+}
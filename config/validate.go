@@ -0,0 +1,104 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// Validate 检查配置，生产环境还会拒绝不安全的设置，所有问题一起返回
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(lo.Contains([]string{EnvDevelopment, EnvTest, EnvProduction}, c.Environment),
+		"ENVIRONMENT must be one of development, test, production, got %q", c.Environment)
+	check(c.ServerAddress != "", "SERVER_ADDRESS is required")
+	check(lo.Contains([]string{"debug", "info", "warn", "error"}, c.LogLevel),
+		"LOG_LEVEL must be one of debug, info, warn, error, got %q", c.LogLevel)
+	check(lo.Contains([]string{"", "json", "console"}, c.LogFormat),
+		"LOG_FORMAT must be json or console, got %q", c.LogFormat)
+	for _, v := range []struct {
+		name  string
+		value int
+	}{
+		{"LOG_MAX_SIZE", c.LogMaxSize},
+		{"LOG_MAX_BACKUPS", c.LogMaxBackups},
+		{"LOG_MAX_AGE", c.LogMaxAge},
+		{"SERVER_READ_TIMEOUT", c.ServerReadTimeout},
+		{"SERVER_WRITE_TIMEOUT", c.ServerWriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", c.ServerIdleTimeout},
+		{"SHUTDOWN_TIMEOUT", c.ShutdownTimeout},
+		{"DB_SLOW_THRESHOLD", c.DBSlowThreshold},
+		{"FEED_CACHE_TTL", c.FeedCacheTTL},
+		{"ACCOUNT_DELETION_GRACE_DAYS", c.AccountDeletionGraceDays},
+		{"DELETION_CHECK_INTERVAL", c.DeletionCheckInterval},
+		{"BACKUP_INTERVAL", c.BackupInterval},
+		{"BACKUP_KEEP", c.BackupKeep},
+	} {
+		check(v.value >= 0, "%s must not be negative, got %d", v.name, v.value)
+	}
+
+	switch c.DBType {
+	case "sqlite":
+		check(c.DBPath != "", "DB_PATH is required for sqlite")
+	case "mysql", "postgres":
+		check(c.DBHost != "", "DB_HOST is required for %s", c.DBType)
+		check(c.DBName != "", "DB_NAME is required for %s", c.DBType)
+	default:
+		check(false, "DB_TYPE must be one of sqlite, mysql, postgres, got %q", c.DBType)
+	}
+
+	check(c.TokenSecretKey != "", "TOKEN_SECRET_KEY is required")
+	check(strings.HasSuffix(c.MediaURLPrefix, "/"), "MEDIA_URL_PREFIX must end with /, got %q", c.MediaURLPrefix)
+	mobile, err := url.Parse(c.MobileSiteURL)
+	check(err == nil && mobile.Host != "" && (mobile.Scheme == "http" || mobile.Scheme == "https"),
+		"MOBILE_SITE_URL must be an absolute http(s) URL, got %q", c.MobileSiteURL)
+	check(!strings.HasSuffix(c.MobileSiteURL, "/"), "MOBILE_SITE_URL must not end with /, got %q", c.MobileSiteURL)
+
+	for _, proxy := range c.TrustedProxies {
+		_, _, err := net.ParseCIDR(proxy)
+		check(err == nil || net.ParseIP(proxy) != nil, "TRUSTED_PROXIES must be IPs or CIDRs, got %q", proxy)
+	}
+
+	switch c.MailDriver {
+	case "log":
+	case "smtp":
+		check(c.SMTPHost != "", "SMTP_HOST is required when MAIL_DRIVER is smtp")
+		check(c.MailFrom != "", "MAIL_FROM is required when MAIL_DRIVER is smtp")
+	default:
+		check(false, "MAIL_DRIVER must be log or smtp, got %q", c.MailDriver)
+	}
+	check(c.SMSDriver == "log", "SMS_DRIVER must be log, got %q", c.SMSDriver)
+	if c.OAuthClientId != "" {
+		check(c.OAuthClientSecret != "", "OAUTH_CLIENT_SECRET is required when OAUTH_CLIENT_ID is set")
+		check(c.OAuthAuthorizeURL != "", "OAUTH_AUTHORIZE_URL is required when OAUTH_CLIENT_ID is set")
+		check(c.OAuthTokenURL != "", "OAUTH_TOKEN_URL is required when OAUTH_CLIENT_ID is set")
+		check(c.OAuthUserInfoURL != "", "OAUTH_USERINFO_URL is required when OAUTH_CLIENT_ID is set")
+	}
+
+	// 生产环境不允许使用开发用的默认值
+	if c.IsProduction() {
+		check(c.TokenSecretKey != devTokenSecretKey, "TOKEN_SECRET_KEY must be changed from the development default in production")
+		check(len(c.TokenSecretKey) >= 32, "TOKEN_SECRET_KEY must be at least 32 characters in production")
+		if c.DBType != "sqlite" {
+			check(c.DBPassword != "" && c.DBPassword != "postgres", "DB_PASSWORD must be set to a non-default value in production")
+		}
+		check(c.AdminRequire2FA, "ADMIN_REQUIRE_2FA must be enabled in production")
+		check(c.RateLimitEnabled, "RATE_LIMIT_ENABLED must be enabled in production")
+		check(mobile == nil || mobile.Scheme == "https", "MOBILE_SITE_URL must use https in production")
+		check(!c.RequireEmailVerification || c.MailDriver == "smtp",
+			"MAIL_DRIVER must be smtp in production when REQUIRE_EMAIL_VERIFICATION is enabled")
+		check(c.LogLevel != "debug", "LOG_LEVEL must not be debug in production")
+	}
+
+	return errors.Join(errs...)
+}
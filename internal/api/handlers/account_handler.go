@@ -0,0 +1,440 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"myapi/config"
+	"myapi/internal/models"
+	"myapi/internal/pkg/errcode"
+	"myapi/internal/pkg/response"
+	"myapi/pkg/logger"
+	"myapi/pkg/oauth"
+	"myapi/pkg/sms"
+)
+
+var phoneRegexp = regexp.MustCompile(`^\+?[0-9]{6,15}$`)
+
+// AccountHandler 手机号、第三方登录，以及一个用户绑定多种登录方式
+type AccountHandler struct {
+	db     *gorm.DB
+	logger *logger.Logger
+	config *config.Config
+	sms    sms.Sender
+	oauth  oauth.Provider
+}
+
+func NewAccountHandler(db *gorm.DB, logger *logger.Logger, cfg *config.Config) *AccountHandler {
+	h := &AccountHandler{
+		db:     db,
+		logger: logger,
+		config: cfg,
+		sms:    sms.NewLogSender(logger),
+	}
+	if cfg.OAuthClientId != "" {
+		h.oauth = oauth.NewOAuth2Provider(oauth.Config{
+			Name:         cfg.OAuthName,
+			ClientId:     cfg.OAuthClientId,
+			ClientSecret: cfg.OAuthClientSecret,
+			AuthorizeURL: cfg.OAuthAuthorizeURL,
+			TokenURL:     cfg.OAuthTokenURL,
+			UserInfoURL:  cfg.OAuthUserInfoURL,
+			RedirectURL:  cfg.OAuthRedirectURL,
+			Scope:        cfg.OAuthScope,
+		})
+	}
+	return h
+}
+
+type SendSMSCodeRequest struct {
+	Phone string `json:"phone"`
+}
+
+// 发送手机号登录验证码
+func (h *AccountHandler) SendSMSCode(c *gin.Context) {
+	var body SendSMSCodeRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if !phoneRegexp.MatchString(body.Phone) {
+		response.Fail(c, errcode.ErrInvalidPhone)
+		return
+	}
+	code, err := models.CreateVerificationCode(h.db.WithContext(c), body.Phone, models.VerificationPurposePhoneLogin)
+	if err != nil {
+		if err == models.ErrVerificationCodeTooFrequent {
+			response.Fail(c, verificationError(err))
+			return
+		}
+		response.Fail(c, err)
+		return
+	}
+	content := fmt.Sprintf("【FitHub】你的验证码是 %s，%d 分钟内有效。", code, int(models.VerificationCodeTTL.Minutes()))
+	if err := h.sms.Send(body.Phone, content); err != nil {
+		h.logger.WithContext(c).Error("Failed to send sms", err)
+		response.Fail(c, errcode.ErrSendFailed)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "验证码已发送", "data": nil})
+}
+
+type LoginWithSMSRequest struct {
+	Phone string `json:"phone"`
+	Code  string `json:"code"`
+}
+
+// 手机号验证码登录，没有注册过的手机号直接注册
+func (h *AccountHandler) LoginWithSMS(c *gin.Context) {
+	var body LoginWithSMSRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if err := models.CheckVerificationCode(h.db.WithContext(c), body.Phone, models.VerificationPurposePhoneLogin, body.Code); err != nil {
+		response.Fail(c, verificationError(err))
+		return
+	}
+	now := time.Now()
+	account := models.CoachAccount{
+		ProviderType: models.AccountProviderTypePhone,
+		ProviderId:   body.Phone,
+		VerifiedAt:   &now,
+		CreatedAt:    now,
+	}
+	h.loginWithAccount(c, account, "", "")
+}
+
+type BindPhoneRequest struct {
+	Phone string `json:"phone"`
+	Code  string `json:"code"`
+}
+
+// 绑定手机号
+func (h *AccountHandler) BindPhone(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body BindPhoneRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if err := models.CheckVerificationCode(h.db.WithContext(c), body.Phone, models.VerificationPurposePhoneLogin, body.Code); err != nil {
+		response.Fail(c, verificationError(err))
+		return
+	}
+	now := time.Now()
+	account := models.CoachAccount{
+		ProviderType: models.AccountProviderTypePhone,
+		ProviderId:   body.Phone,
+		VerifiedAt:   &now,
+		CreatedAt:    now,
+		CoachId:      uid,
+	}
+	h.bindAccount(c, account)
+}
+
+// 第三方登录的授权地址
+func (h *AccountHandler) BuildOAuthURL(c *gin.Context) {
+	h.buildOAuthURL(c, 0)
+}
+
+// 绑定第三方帐号的授权地址，state 里带着当前用户
+func (h *AccountHandler) BuildOAuthBindURL(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	h.buildOAuthURL(c, uid)
+}
+
+type OAuthURLResponse struct {
+	Provider string `json:"provider"`
+	URL      string `json:"url"`
+	State    string `json:"state"`
+}
+
+func (h *AccountHandler) buildOAuthURL(c *gin.Context, uid int) {
+	if h.oauth == nil {
+		response.Fail(c, errcode.ErrOAuthDisabled)
+		return
+	}
+	state, err := oauth.NewState(h.config.TokenSecretKey, uid, 10*time.Minute)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": OAuthURLResponse{
+		Provider: h.oauth.Name(),
+		URL:      h.oauth.AuthCodeURL(state),
+		State:    state,
+	}})
+}
+
+// 第三方登录回调后，用授权码登录，没有绑定过的帐号直接注册
+func (h *AccountHandler) LoginWithOAuth(c *gin.Context) {
+	info, ok := h.exchangeOAuthCode(c, 0)
+	if !ok {
+		return
+	}
+	account := models.CoachAccount{
+		ProviderType: models.AccountProviderTypeOAuth,
+		ProviderId:   info.Id,
+		ProviderArg1: h.oauth.Name(),
+		ProviderArg2: info.Nickname,
+		CreatedAt:    time.Now(),
+	}
+	h.loginWithAccount(c, account, info.Nickname, info.AvatarURL)
+}
+
+// 绑定第三方帐号
+func (h *AccountHandler) BindOAuth(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	info, ok := h.exchangeOAuthCode(c, uid)
+	if !ok {
+		return
+	}
+	account := models.CoachAccount{
+		ProviderType: models.AccountProviderTypeOAuth,
+		ProviderId:   info.Id,
+		ProviderArg1: h.oauth.Name(),
+		ProviderArg2: info.Nickname,
+		CreatedAt:    time.Now(),
+		CoachId:      uid,
+	}
+	h.bindAccount(c, account)
+}
+
+type OAuthCodeRequest struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
+
+func (h *AccountHandler) exchangeOAuthCode(c *gin.Context, uid int) (*oauth.UserInfo, bool) {
+	var body OAuthCodeRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return nil, false
+	}
+	if h.oauth == nil {
+		response.Fail(c, errcode.ErrOAuthDisabled)
+		return nil, false
+	}
+	state_uid, err := oauth.ParseState(h.config.TokenSecretKey, body.State)
+	if err != nil || state_uid != uid {
+		response.Fail(c, errcode.ErrOAuthExpired)
+		return nil, false
+	}
+	info, err := h.oauth.Exchange(c.Request.Context(), body.Code)
+	if err != nil {
+		h.logger.WithContext(c).Error("Failed to exchange oauth code", err)
+		response.Fail(c, errcode.ErrOAuthFailed)
+		return nil, false
+	}
+	return info, true
+}
+
+// loginWithAccount 已经绑定的帐号直接登录，否则注册新用户
+func (h *AccountHandler) loginWithAccount(c *gin.Context, account models.CoachAccount, nickname string, avatar_url string) {
+	tx := h.db.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			h.logger.WithContext(c).Errorw("Recovered from panic", "panic", r, "stack", string(debug.Stack()))
+			tx.Rollback()
+			response.Fail(c, errcode.ErrInternal)
+		}
+	}()
+	var existing models.CoachAccount
+	if err := tx.Where("provider_type = ? AND provider_id = ?", account.ProviderType, account.ProviderId).First(&existing).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			tx.Rollback()
+			response.Fail(c, err)
+			return
+		}
+	}
+	coach_id := existing.CoachId
+	if coach_id == 0 {
+		if avatar_url == "" {
+			avatar_url = h.config.DefaultAvatarURL
+		}
+		coach, err := createCoach(tx, nickname, avatar_url)
+		if err != nil {
+			tx.Rollback()
+			h.logger.WithContext(c).Error("Failed to create coach", err)
+			response.Fail(c, err)
+			return
+		}
+		account.CoachId = coach.Id
+		if err := tx.Create(&account).Error; err != nil {
+			tx.Rollback()
+			h.logger.WithContext(c).Error("Failed to create coach account", err)
+			response.Fail(c, err)
+			return
+		}
+		coach_id = coach.Id
+	}
+	auth_resp, err := createLoginSession(c, tx, coach_id, h.config.TokenSecretKey)
+	if err != nil {
+		tx.Rollback()
+		h.logger.WithContext(c).Error("Failed to generate JWT", err)
+		response.Fail(c, err)
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "登录成功", "data": auth_resp})
+}
+
+// bindAccount 每种登录方式只能绑定一个，已经被其他用户绑定的不能再绑定
+func (h *AccountHandler) bindAccount(c *gin.Context, account models.CoachAccount) {
+	// 代登录时不能替用户绑定
+	if c.GetFloat64("actor_id") != 0 {
+		response.Fail(c, errcode.ErrForbidden)
+		return
+	}
+	var existing models.CoachAccount
+	if err := h.db.WithContext(c).Where("provider_type = ? AND provider_id = ?", account.ProviderType, account.ProviderId).First(&existing).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+	}
+	if existing.CoachId == account.CoachId {
+		c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "绑定成功", "data": nil})
+		return
+	}
+	if existing.CoachId != 0 {
+		response.Fail(c, errcode.ErrAccountBoundOther)
+		return
+	}
+	var count int64
+	if err := h.db.WithContext(c).Model(&models.CoachAccount{}).Where("coach_id = ? AND provider_type = ?", account.CoachId, account.ProviderType).Count(&count).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if count != 0 {
+		response.Fail(c, errcode.ErrProviderBound)
+		return
+	}
+	if err := h.db.WithContext(c).Create(&account).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "绑定成功", "data": nil})
+}
+
+type AccountItem struct {
+	ProviderType int       `json:"provider_type"`
+	ProviderName string    `json:"provider_name"`
+	Name         string    `json:"name"`
+	Verified     bool      `json:"verified"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type FetchAccountListResponse struct {
+	List []AccountItem `json:"list"`
+}
+
+// 已绑定的登录方式
+func (h *AccountHandler) FetchAccountList(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var list1 []models.CoachAccount
+	if err := h.db.WithContext(c).Where("coach_id = ?", uid).Order("created_at ASC").Find(&list1).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	list := make([]AccountItem, 0, len(list1))
+	for _, v := range list1 {
+		// 邮箱帐号的 provider_arg1 是密码哈希，不能返回
+		name := v.ProviderId
+		provider_name := ""
+		if v.ProviderType == models.AccountProviderTypePhone && len(name) > 7 {
+			name = name[:3] + strings.Repeat("*", len(name)-7) + name[len(name)-4:]
+		}
+		if v.ProviderType == models.AccountProviderTypeOAuth {
+			name = v.ProviderArg2
+			provider_name = v.ProviderArg1
+		}
+		list = append(list, AccountItem{
+			ProviderType: v.ProviderType,
+			ProviderName: provider_name,
+			Name:         name,
+			Verified:     v.VerifiedAt != nil,
+			CreatedAt:    v.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": FetchAccountListResponse{List: list}})
+}
+
+type UnlinkAccountRequest struct {
+	ProviderType int `json:"provider_type"`
+}
+
+// 解绑登录方式，至少保留一种
+func (h *AccountHandler) UnlinkAccount(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body UnlinkAccountRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if c.GetFloat64("actor_id") != 0 {
+		response.Fail(c, errcode.ErrForbidden)
+		return
+	}
+	var count int64
+	if err := h.db.WithContext(c).Model(&models.CoachAccount{}).Where("coach_id = ?", uid).Count(&count).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if count <= 1 {
+		response.Fail(c, errcode.ErrLastAccount)
+		return
+	}
+	r := h.db.WithContext(c).Where("coach_id = ? AND provider_type = ?", uid, body.ProviderType).Delete(&models.CoachAccount{})
+	if r.Error != nil {
+		response.Fail(c, r.Error)
+		return
+	}
+	if r.RowsAffected == 0 {
+		response.Fail(c, errcode.ErrAccountNotBound)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "解绑成功", "data": nil})
+}
+
+// createCoach 手机号、第三方登录时没有帐号就直接注册
+func createCoach(tx *gorm.DB, nickname string, avatar_url string) (*models.Coach, error) {
+	uid := strings.ReplaceAll(uuid.New().String(), "-", "")[:6]
+	if nickname == "" {
+		nickname = uid
+	}
+	coach := models.Coach{
+		Nickname:  uid,
+		Config:    "{}",
+		CreatedAt: time.Now(),
+	}
+	if err := tx.Create(&coach).Error; err != nil {
+		return nil, err
+	}
+	profile1 := models.CoachProfile1{
+		CoachId:   coach.Id,
+		Nickname:  nickname,
+		AvatarURL: avatar_url,
+	}
+	if err := tx.Create(&profile1).Error; err != nil {
+		return nil, err
+	}
+	coach.Profile1Id = profile1.Id
+	if err := tx.Save(&coach).Error; err != nil {
+		return nil, err
+	}
+	return &coach, nil
+}
@@ -2,73 +2,132 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/mail"
+	"runtime/debug"
 	"sort"
 	"strings"
 	"time"
 
 	"myapi/config"
 	"myapi/internal/models"
+	"myapi/internal/pkg/dialect"
+	"myapi/internal/pkg/errcode"
+	"myapi/internal/pkg/loginguard"
 	"myapi/internal/pkg/pagination"
+	"myapi/internal/pkg/response"
 	"myapi/internal/pkg/sensitive"
 	"myapi/pkg/logger"
+	"myapi/pkg/mailer"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/samber/lo"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CoachHandler struct {
 	db     *gorm.DB
 	logger *logger.Logger
 	config *config.Config
+	mailer mailer.Mailer
+	guard  *loginguard.Guard
 }
 
 // NewCoachHandler creates a new coach handler
-func NewCoachHandler(db *gorm.DB, logger *logger.Logger, config *config.Config) *CoachHandler {
+//
+// guard 和 MFAHandler 共用，管理员解锁时两边的计数一起清除
+func NewCoachHandler(db *gorm.DB, logger *logger.Logger, config *config.Config, guard *loginguard.Guard) *CoachHandler {
 	return &CoachHandler{
 		db:     db,
 		logger: logger,
 		config: config,
+		mailer: NewMailer(logger, config),
+		guard:  guard,
 	}
 }
 
+// NewMailer 根据配置选择发送方式，本地开发默认写日志
+func NewMailer(logger *logger.Logger, cfg *config.Config) mailer.Mailer {
+	if cfg.MailDriver == "smtp" {
+		return mailer.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	}
+	return mailer.NewLogMailer(logger, cfg.MailLogPath)
+}
+
+// CoachBrief 列表、详情里附带的用户信息
+type CoachBrief struct {
+	Id        int    `json:"id"`
+	Nickname  string `json:"nickname"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+func newCoachBrief(id int, profile models.CoachProfile1) CoachBrief {
+	return CoachBrief{Id: id, Nickname: profile.Nickname, AvatarURL: profile.AvatarURL}
+}
+
+// IdResponse 创建、编辑后只返回记录 id
+type IdResponse struct {
+	Id int `json:"id"`
+}
+
+// APIVersion 接口版本，/ping 和接口文档里返回
+const APIVersion = "250707151622"
+
+type FetchVersionResponse struct {
+	Version string `json:"version"`
+}
+
 func (h *CoachHandler) FetchVersion(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": gin.H{
-		"version": "250707151622",
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": FetchVersionResponse{
+		Version: APIVersion,
 	}})
 }
 
-var AvatarPrefix = "//static.fithub.top/avatars/"
-var DefaultAvatarURL = AvatarPrefix + "default1.jpeg"
-var MobileSiteHostname = "https://h5.fithub.top"
+type RegisterCoachRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
 
 func (h *CoachHandler) RegisterCoach(c *gin.Context) {
-	var body struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
-	}
+	var body RegisterCoachRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	if body.Email == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "请输入邮箱", "data": nil})
+		response.Fail(c, errcode.ErrEmailRequired)
 		return
 	}
 	if body.Password == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "请输入密码", "data": nil})
+		response.Fail(c, errcode.ErrPasswordRequired)
+		return
+	}
+	if h.config.RequireEmailVerification && body.Code == "" {
+		response.Fail(c, errcode.ErrCodeRequired)
 		return
 	}
-	tx := h.db.Begin()
+	// 不强制验证时，填了验证码也会校验，通过后直接标记为已验证
+	var verified_at *time.Time
+	if body.Code != "" {
+		if err := models.CheckVerificationCode(h.db.WithContext(c), body.Email, models.VerificationPurposeVerifyEmail, body.Code); err != nil {
+			response.Fail(c, verificationError(err))
+			return
+		}
+		now := time.Now()
+		verified_at = &now
+	}
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Println("Recovered from panic:", r)
+			h.logger.WithContext(c).Errorw("Recovered from panic", "panic", r, "stack", string(debug.Stack()))
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 
@@ -76,12 +135,12 @@ func (h *CoachHandler) RegisterCoach(c *gin.Context) {
 	query := tx.Where("provider_type = ? AND provider_id = ?", models.AccountProviderTypeEmailWithPwd, body.Email)
 	if err := query.First(&existing).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
 	}
 	if existing.CoachId != 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "该邮箱已被使用", "data": nil})
+		response.Fail(c, errcode.ErrEmailUsed)
 		return
 	}
 
@@ -102,122 +161,151 @@ func (h *CoachHandler) RegisterCoach(c *gin.Context) {
 
 	if err := tx.Create(&the_coach).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to create coach", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		h.logger.WithContext(c).Error("Failed to create coach", err)
+		response.Fail(c, err)
 		return
 	}
 
 	hashed_pwd, err := bcrypt.GenerateFromPassword([]byte(body.Password), bcrypt.DefaultCost)
 	if err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 501, "msg": "注册失败", "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	the_coach_account := models.CoachAccount{
 		ProviderType: models.AccountProviderTypeEmailWithPwd,
 		ProviderId:   body.Email,
 		ProviderArg1: string(hashed_pwd),
+		VerifiedAt:   verified_at,
 		CreatedAt:    now,
 		CoachId:      the_coach.Id,
 	}
 	if err := tx.Create(&the_coach_account).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to create coach account", err)
-		c.JSON(http.StatusOK, gin.H{"code": 502, "msg": "注册失败", "data": nil})
+		h.logger.WithContext(c).Error("Failed to create coach account", err)
+		response.Fail(c, err)
 		return
 	}
 	profile1 := models.CoachProfile1{
 		CoachId:   the_coach.Id,
 		Nickname:  nickname,
-		AvatarURL: DefaultAvatarURL,
+		AvatarURL: h.config.DefaultAvatarURL,
 	}
 	if err := tx.Create(&profile1).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to create profile1", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to create coach account", "data": nil})
+		h.logger.WithContext(c).Error("Failed to create profile1", err)
+		response.Fail(c, err)
 		return
 	}
 	the_coach.Profile1Id = profile1.Id
 	if err := tx.Save(&the_coach).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to create coach account", "data": nil})
+		response.Fail(c, err)
 		return
 	}
-	token, expires_at, err := models.GenerateJWT(the_coach.Id, h.config.TokenSecretKey)
+	auth_resp, err := models.CreateCoachSession(tx, the_coach.Id, c.GetHeader("User-Agent"), c.ClientIP(), h.config.TokenSecretKey)
 	if err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to generate JWT", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to generate token", "data": nil})
+		h.logger.WithContext(c).Error("Failed to generate JWT", err)
+		response.Fail(c, err)
 		return
 	}
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
-	response := models.AuthResponse{
-		Token:     "Bearer " + token,
-		ExpiresAt: expires_at.Unix(),
-		Status:    "success",
-	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "注册成功", "data": response})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "注册成功", "data": auth_resp})
 }
 
 // LoginCoach handles coach login
+var dummyPasswordHash = func() string {
+	v, _ := bcrypt.GenerateFromPassword([]byte("fithub"), bcrypt.DefaultCost)
+	return string(v)
+}()
+
+type LoginCoachRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password,omitempty"`
+}
+
 func (h *CoachHandler) LoginCoach(c *gin.Context) {
-	var body struct {
-		Email    string `json:"email"`
-		Password string `json:"password,omitempty"`
-	}
+	var body LoginCoachRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	if body.Email == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "请输入邮箱", "data": nil})
+		response.Fail(c, errcode.ErrEmailRequired)
 		return
 	}
 	if body.Password == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "请输入密码", "data": nil})
+		response.Fail(c, errcode.ErrPasswordRequired)
+		return
+	}
+	// 帐号不存在和密码错误返回一样的提示，失败次数按帐号和 IP 分别计数
+	ip := c.ClientIP()
+	if wait := h.guard.Check(body.Email, ip); wait > 0 {
+		response.Fail(c, errcode.ErrTooManyAttempts.WithArgs(int(wait.Seconds())+1))
 		return
 	}
 	var account models.CoachAccount
-	if err := h.db.Where("provider_type = ? AND provider_id = ?", models.AccountProviderTypeEmailWithPwd, body.Email).First(&account).Error; err != nil {
+	if err := h.db.WithContext(c).Where("provider_type = ? AND provider_id = ?", models.AccountProviderTypeEmailWithPwd, body.Email).First(&account).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "帐号不存在", "data": nil})
-		return
 	}
-	err := bcrypt.CompareHashAndPassword([]byte(account.ProviderArg1), []byte(body.Password))
-	if err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "邮箱或密码错误", "data": nil})
+	hashed_pwd := account.ProviderArg1
+	if hashed_pwd == "" {
+		// 帐号不存在时也比较一次，避免从响应时间判断出帐号是否存在
+		hashed_pwd = dummyPasswordHash
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hashed_pwd), []byte(body.Password)); err != nil || account.CoachId == 0 {
+		h.guard.Fail(body.Email, ip)
+		response.Fail(c, errcode.ErrLoginFailed)
 		return
 	}
+	h.guard.Succeed(body.Email)
 	// Generate JWT token
-	token, expires_at, err := models.GenerateJWT(account.CoachId, h.config.TokenSecretKey)
+	auth_resp, err := createLoginSession(c, h.db.WithContext(c), account.CoachId, h.config.TokenSecretKey)
 	if err != nil {
-		h.logger.Error("Failed to generate JWT", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "登录失败", "data": nil})
+		h.logger.WithContext(c).Error("Failed to generate JWT", err)
+		response.Fail(c, err)
 		return
 	}
-	response := models.AuthResponse{
-		Token:     "Bearer " + token,
-		ExpiresAt: expires_at.Unix(),
-		Status:    "success",
-	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "登录成功", "data": response})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "登录成功", "data": auth_resp})
+}
+
+// CoachSubscriptionBrief 当前的会员订阅，没有订阅时 expired_at 为 null
+type CoachSubscriptionBrief struct {
+	Name      string     `json:"name"`
+	Status    int        `json:"status"`
+	ExpiredAt *time.Time `json:"expired_at"`
+	Count     int        `json:"count"`
+}
+
+type FetchCoachProfileResponse struct {
+	Id             int                    `json:"id"`
+	UID            string                 `json:"uid"`
+	Nickname       string                 `json:"nickname"`
+	AvatarURL      string                 `json:"avatar_url"`
+	Timezone       string                 `json:"timezone"`
+	Subscription   CoachSubscriptionBrief `json:"subscription"`
+	NoAccount      bool                   `json:"no_account"`
+	EmailVerified  bool                   `json:"email_verified"`
+	FollowerCount  int64                  `json:"follower_count"`
+	FollowingCount int64                  `json:"following_count"`
 }
 
 func (h *CoachHandler) FetchCoachProfile(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	var coach models.Coach
@@ -226,16 +314,16 @@ func (h *CoachHandler) FetchCoachProfile(c *gin.Context) {
 		Preload("Profile1").
 		First(&coach).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "没有找到记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 	var account models.CoachAccount
-	if err := tx.Where("coach_id = ?", coach.Id).First(&account).Error; err != nil {
+	if err := tx.Where("coach_id = ?", coach.Id).Order("provider_type ASC").First(&account).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
 	}
@@ -243,7 +331,7 @@ func (h *CoachHandler) FetchCoachProfile(c *gin.Context) {
 	var active_subscription models.Subscription
 	if err := tx.Where("coach_id = ? AND step = 2", uid).Order("created_at DESC").Preload("SubscriptionPlan").First(&active_subscription).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
 	}
@@ -254,7 +342,7 @@ func (h *CoachHandler) FetchCoachProfile(c *gin.Context) {
 			// Update expired subscription status
 			if err := tx.Model(&active_subscription).Update("step", 3).Error; err != nil {
 				tx.Rollback()
-				c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+				response.Fail(c, err)
 				return
 			}
 
@@ -262,7 +350,7 @@ func (h *CoachHandler) FetchCoachProfile(c *gin.Context) {
 			var next_subscription models.Subscription
 			if err := tx.Where("coach_id = ? AND step = 1", uid).Order("created_at ASC").First(&next_subscription).Error; err != nil {
 				if err != gorm.ErrRecordNotFound {
-					c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+					response.Fail(c, err)
 					return
 				}
 			}
@@ -270,7 +358,7 @@ func (h *CoachHandler) FetchCoachProfile(c *gin.Context) {
 				// Update next subscription to active
 				if err := tx.Model(&next_subscription).Update("step", 2).Error; err != nil {
 					tx.Rollback()
-					c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+					response.Fail(c, err)
 					return
 				}
 				// Update active_subscription to the newly activated one
@@ -280,94 +368,109 @@ func (h *CoachHandler) FetchCoachProfile(c *gin.Context) {
 	}
 	// Then find the latest subscription regardless of status
 	var latest_subscription models.Subscription
-	if err := h.db.Where("coach_id = ?", uid).Order("created_at DESC").Preload("SubscriptionPlan").First(&latest_subscription).Error; err != nil {
+	if err := h.db.WithContext(c).Where("coach_id = ?", uid).Order("created_at DESC").Preload("SubscriptionPlan").First(&latest_subscription).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
 	}
-	subscription_resp := gin.H{
-		"name":       "",
-		"status":     0,
-		"expired_at": "",
-		"count":      0,
-	}
+	subscription_resp := CoachSubscriptionBrief{}
 
 	// If we have a latest subscription
 	if latest_subscription.Id != 0 {
 		// If the latest is pending and we have an active one, use the active one
 		if latest_subscription.Step != 2 && active_subscription.Id != 0 {
-			subscription_resp = gin.H{
-				"name":       active_subscription.SubscriptionPlan.Name,
-				"status":     active_subscription.Step,
-				"expired_at": active_subscription.ExpectExpiredAt,
-				"count":      active_subscription.Count,
+			subscription_resp = CoachSubscriptionBrief{
+				Name:      active_subscription.SubscriptionPlan.Name,
+				Status:    active_subscription.Step,
+				ExpiredAt: active_subscription.ExpectExpiredAt,
+				Count:     active_subscription.Count,
 			}
 		} else {
 			// Otherwise use the latest one
-			subscription_resp = gin.H{
-				"name":       latest_subscription.SubscriptionPlan.Name,
-				"status":     latest_subscription.Step,
-				"expired_at": latest_subscription.ExpectExpiredAt,
-				"count":      latest_subscription.Count,
+			subscription_resp = CoachSubscriptionBrief{
+				Name:      latest_subscription.SubscriptionPlan.Name,
+				Status:    latest_subscription.Step,
+				ExpiredAt: latest_subscription.ExpectExpiredAt,
+				Count:     latest_subscription.Count,
 			}
 		}
 		// 生效中
-		if subscription_resp["status"] == 2 && subscription_resp["count"] == 9999 {
-			subscription_resp["name"] = "终身VIP"
+		if subscription_resp.Status == 2 && subscription_resp.Count == 9999 {
+			subscription_resp.Name = "终身VIP"
 		}
 	}
-	if err := tx.Commit().Error; err != nil {
+	follower_count, following_count, err := models.FetchFollowCount(tx, coach.Id)
+	if err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "获取成功", "data": gin.H{
-		"id":           coach.Id,
-		"uid":          coach.Nickname,
-		"nickname":     coach.Profile1.Nickname,
-		"avatar_url":   coach.Profile1.AvatarURL,
-		"subscription": subscription_resp,
-		"no_account":   account.ProviderType == 0,
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "获取成功", "data": FetchCoachProfileResponse{
+		Id:             coach.Id,
+		UID:            coach.Nickname,
+		Nickname:       coach.Profile1.Nickname,
+		AvatarURL:      coach.Profile1.AvatarURL,
+		Timezone:       coach.Location().String(),
+		Subscription:   subscription_resp,
+		NoAccount:      account.ProviderType == 0,
+		EmailVerified:  account.ProviderType == models.AccountProviderTypeEmailWithPwd && account.VerifiedAt != nil,
+		FollowerCount:  follower_count,
+		FollowingCount: following_count,
 	}})
 }
 
+type UpdateCoachProfileRequest struct {
+	Nickname  string `json:"nickname,omitempty" binding:"omitempty,min=1,max=18" label:"昵称"`
+	AvatarURL string `json:"avatar_url" label:"头像"`
+	Config    string `json:"config"`
+	// IANA 时区名，比如 Asia/Shanghai、America/New_York
+	Timezone string `json:"timezone" label:"时区"`
+}
+
 func (h *CoachHandler) UpdateCoachProfile(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		Nickname  string `json:"nickname,omitempty" binding:"omitempty,min=1,max=18" label:"昵称"`
-		AvatarURL string `json:"avatar_url" label:"头像"`
-		Config    string `json:"config"`
-	}
+	var body UpdateCoachProfileRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
-	if body.AvatarURL == "" && body.Nickname == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少修改参数", "data": nil})
+	if body.AvatarURL == "" && body.Nickname == "" && body.Timezone == "" {
+		response.Fail(c, errcode.ErrMissingParam)
 		return
 	}
+	if body.Timezone != "" {
+		if _, err := models.ParseTimezone(body.Timezone); err != nil {
+			response.Fail(c, errcode.ErrInvalidTimezone)
+			return
+		}
+	}
 
 	// Check for sensitive words in nickname
 	if body.Nickname != "" && sensitive.ContainsSensitiveWord(body.Nickname) {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "昵称包含敏感词", "data": nil})
+		response.Fail(c, errcode.ErrSensitiveNick)
 		return
 	}
 
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Println("Recovered from panic:", r)
+			h.logger.WithContext(c).Errorw("Recovered from panic", "panic", r, "stack", string(debug.Stack()))
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	var existing models.Coach
 	if err := tx.Where("id = ?", uid).Preload("Profile1").First(&existing).Error; err != nil {
-		h.logger.Error("Failed to find models.Coach", err)
+		h.logger.WithContext(c).Error("Failed to find models.Coach", err)
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
 	}
@@ -375,14 +478,14 @@ func (h *CoachHandler) UpdateCoachProfile(c *gin.Context) {
 		var existing_profile1 models.CoachProfile1
 		if err := tx.Where("coach_id = ?", uid).First(&existing_profile1).Error; err != nil {
 			if err != gorm.ErrRecordNotFound {
-				c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+				response.Fail(c, err)
 				return
 			}
 			// 处理历史遗留问题 不存在 CoachProfile1
 			existing_profile1.CoachId = existing.Id
 			if err := tx.Create(&existing_profile1).Error; err != nil {
 				tx.Rollback()
-				c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+				response.Fail(c, err)
 				return
 			}
 
@@ -392,7 +495,7 @@ func (h *CoachHandler) UpdateCoachProfile(c *gin.Context) {
 			"profile1_id": existing_profile1.Id,
 		}).Error; err != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
 		existing.Profile1 = existing_profile1
@@ -402,50 +505,283 @@ func (h *CoachHandler) UpdateCoachProfile(c *gin.Context) {
 		updates["nickname"] = body.Nickname
 	}
 	if body.AvatarURL != "" {
-		updates["avatar_url"] = AvatarPrefix + body.AvatarURL
+		updates["avatar_url"] = h.config.AvatarURL(body.AvatarURL)
 	}
-	if err := tx.Model(&existing.Profile1).Updates(updates).Error; err != nil {
-		tx.Rollback()
-		h.logger.Error("Failed to update the record", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
-		return
+	if len(updates) != 0 {
+		if err := tx.Model(&existing.Profile1).Updates(updates).Error; err != nil {
+			tx.Rollback()
+			h.logger.WithContext(c).Error("Failed to update the record", err)
+			response.Fail(c, err)
+			return
+		}
+	}
+	if body.Timezone != "" {
+		if err := tx.Model(&existing).Update("timezone", body.Timezone).Error; err != nil {
+			tx.Rollback()
+			h.logger.WithContext(c).Error("Failed to update the record", err)
+			response.Fail(c, err)
+			return
+		}
 	}
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "更新成功", "data": nil})
 }
 
+type SendVerificationCodeRequest struct {
+	Email string `json:"email"`
+}
+
+// isValidEmail 只接受单独的邮箱地址，不能带名称、换行等，避免拼进邮件头
+func isValidEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
+}
+
 // SendVerificationCode sends a verification code to the specified email
 func (h *CoachHandler) SendVerificationCode(c *gin.Context) {
-	var req struct {
-		Email string `json:"email"`
+	var body SendVerificationCodeRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if body.Email == "" {
+		response.Fail(c, errcode.ErrEmailRequired)
+		return
+	}
+	if !isValidEmail(body.Email) {
+		response.Fail(c, errcode.ErrInvalidEmail)
+		return
+	}
+	if err := h.sendVerificationCode(c, body.Email, models.VerificationPurposeVerifyEmail); err != nil {
+		if err == models.ErrVerificationCodeTooFrequent {
+			response.Fail(c, verificationError(err))
+			return
+		}
+		h.logger.WithContext(c).Error("Failed to send verification code", err)
+		response.Fail(c, errcode.ErrSendFailed)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "验证码已发送", "data": nil})
+}
+
+type VerifyEmailRequest struct {
+	Code string `json:"code"`
+}
+
+// 验证当前账号的邮箱
+func (h *CoachHandler) VerifyEmail(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body VerifyEmailRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	var account models.CoachAccount
+	if err := h.db.WithContext(c).Where("coach_id = ? AND provider_type = ?", uid, models.AccountProviderTypeEmailWithPwd).First(&account).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+		response.Fail(c, errcode.ErrEmailNotBound)
+		return
+	}
+	if account.VerifiedAt != nil {
+		c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "邮箱已验证", "data": nil})
+		return
+	}
+	if err := models.CheckVerificationCode(h.db.WithContext(c), account.ProviderId, models.VerificationPurposeVerifyEmail, body.Code); err != nil {
+		response.Fail(c, verificationError(err))
+		return
+	}
+	if err := h.db.WithContext(c).Model(&models.CoachAccount{}).
+		Where("provider_type = ? AND provider_id = ?", account.ProviderType, account.ProviderId).
+		Update("verified_at", time.Now()).Error; err != nil {
+		response.Fail(c, err)
+		return
 	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "验证成功", "data": nil})
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+// 忘记密码，发送重置密码的验证码
+// 邮箱没有注册时也返回成功，避免被用来探测邮箱是否注册过
+func (h *CoachHandler) ForgotPassword(c *gin.Context) {
+	var body ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if body.Email == "" {
+		response.Fail(c, errcode.ErrEmailRequired)
 		return
 	}
+	if !isValidEmail(body.Email) {
+		response.Fail(c, errcode.ErrInvalidEmail)
+		return
+	}
+	var count int64
+	if err := h.db.WithContext(c).Model(&models.CoachAccount{}).
+		Where("provider_type = ? AND provider_id = ?", models.AccountProviderTypeEmailWithPwd, body.Email).
+		Count(&count).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if count != 0 {
+		if err := h.sendVerificationCode(c, body.Email, models.VerificationPurposeResetPassword); err != nil {
+			if err == models.ErrVerificationCodeTooFrequent {
+				response.Fail(c, verificationError(err))
+				return
+			}
+			h.logger.WithContext(c).Error("Failed to send reset password code", err)
+			response.Fail(c, errcode.ErrSendFailed)
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "验证码已发送", "data": nil})
+}
+
+type ResetPasswordRequest struct {
+	Email    string `json:"email"`
+	Code     string `json:"code"`
+	Password string `json:"password"`
+}
 
-	if req.Email == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Email is required", "data": nil})
+// 用验证码重置密码，成功后所有设备都需要重新登录
+func (h *CoachHandler) ResetPassword(c *gin.Context) {
+	var body ResetPasswordRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if body.Email == "" {
+		response.Fail(c, errcode.ErrEmailRequired)
+		return
+	}
+	if body.Password == "" {
+		response.Fail(c, errcode.ErrPasswordRequired)
+		return
+	}
+	var account models.CoachAccount
+	if err := h.db.WithContext(c).Where("provider_type = ? AND provider_id = ?", models.AccountProviderTypeEmailWithPwd, body.Email).First(&account).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+		response.Fail(c, errcode.ErrVerificationCodeExpired)
+		return
+	}
+	if err := models.CheckVerificationCode(h.db.WithContext(c), body.Email, models.VerificationPurposeResetPassword, body.Code); err != nil {
+		response.Fail(c, verificationError(err))
+		return
+	}
+	hashed_pwd, err := bcrypt.GenerateFromPassword([]byte(body.Password), bcrypt.DefaultCost)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	tx := h.db.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			h.logger.WithContext(c).Errorw("Recovered from panic", "panic", r, "stack", string(debug.Stack()))
+			tx.Rollback()
+			response.Fail(c, errcode.ErrInternal)
+		}
+	}()
+	// 能收到验证码说明邮箱是自己的，顺便标记为已验证
+	updates := map[string]interface{}{
+		"provider_arg1": string(hashed_pwd),
+	}
+	if account.VerifiedAt == nil {
+		updates["verified_at"] = time.Now()
+	}
+	if err := tx.Model(&models.CoachAccount{}).
+		Where("provider_type = ? AND provider_id = ?", account.ProviderType, account.ProviderId).
+		Updates(updates).Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	if _, err := models.RevokeCoachSessions(tx, account.CoachId, ""); err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
 		return
 	}
+	h.guard.Unlock(body.Email)
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "密码已重置，请重新登录", "data": nil})
+}
+
+type UnlockLoginInAdminRequest struct {
+	Email string `json:"email"`
+	IP    string `json:"ip"`
+}
 
-	// In a real implementation, you would generate and send a verification code
-	// For now, we'll just return a success response
+// 管理员解除登录锁定，可以按邮箱或者 IP
+func (h *CoachHandler) UnlockLoginInAdmin(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	if uid != 1 {
+		response.Fail(c, errcode.ErrForbidden)
+		return
+	}
+	var body UnlockLoginInAdminRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if body.Email == "" && body.IP == "" {
+		response.Fail(c, errcode.MissingParam("email/ip"))
+		return
+	}
+	if body.Email != "" {
+		h.guard.Unlock(body.Email)
+		// 两步验证按用户计数
+		var account models.CoachAccount
+		if err := h.db.WithContext(c).Where("provider_type = ? AND provider_id = ?", models.AccountProviderTypeEmailWithPwd, body.Email).First(&account).Error; err == nil {
+			h.guard.Unlock(mfaGuardKey(account.CoachId))
+		}
+	}
+	if body.IP != "" {
+		h.guard.UnlockIP(body.IP)
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "解锁成功", "data": nil})
+}
 
-	response := struct {
-		Status  string `json:"status"`
-		Message string `json:"message"`
-	}{
-		Status:  "success",
-		Message: "Verification code sent",
+func (h *CoachHandler) sendVerificationCode(c *gin.Context, email string, purpose int) error {
+	code, err := models.CreateVerificationCode(h.db.WithContext(c), email, purpose)
+	if err != nil {
+		return err
 	}
+	minutes := int(models.VerificationCodeTTL.Minutes())
+	subject := "FitHub 邮箱验证码"
+	content := fmt.Sprintf("你的验证码是 %s，%d 分钟内有效。如果不是你本人操作，请忽略这封邮件。", code, minutes)
+	if purpose == models.VerificationPurposeResetPassword {
+		subject = "FitHub 重置密码"
+		content = fmt.Sprintf("你正在重置密码，验证码是 %s，%d 分钟内有效。如果不是你本人操作，请忽略这封邮件，你的密码不会改变。", code, minutes)
+	}
+	return h.mailer.Send(email, subject, content)
+}
 
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "Verification code sent", "data": response})
+func verificationError(err error) error {
+	switch err {
+	case models.ErrVerificationCodeTooFrequent:
+		return errcode.ErrCodeTooFrequent
+	case models.ErrVerificationCodeInvalid:
+		return errcode.ErrVerificationCodeInvalid
+	case models.ErrVerificationCodeExpired:
+		return errcode.ErrVerificationCodeExpired
+	}
+	return err
 }
 
 type LocalTime struct {
@@ -458,50 +794,145 @@ func (t *LocalTime) UnmarshalJSON(b []byte) error {
 	if s == "null" || s == "" {
 		return nil
 	}
-	// 先按 RFC3339 解析
+	// 按 RFC3339 解析，用到时再转成用户的时区
 	tt, err := time.Parse(time.RFC3339, s)
 	if err != nil {
 		return err
 	}
-	// 转为本地时区
-	loc, _ := time.LoadLocation("Asia/Shanghai")
-	t.Time = tt.In(loc)
+	t.Time = tt
 	return nil
 }
 
+type CoachWorkoutStats struct {
+	Version           string    `json:"v"`
+	TotalWorkoutDays  int       `json:"total_workout_days"`
+	TotalWorkoutTimes int64     `json:"total_workout_times"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type ActionStatRecord struct {
+	Reps       int       `json:"reps"`
+	RepsUnit   string    `json:"reps_unit"`
+	Weight     float64   `json:"weight"`
+	WeightUnit string    `json:"weight_unit"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type ActionStat struct {
+	Action  string             `json:"action"`
+	Records []ActionStatRecord `json:"records"`
+}
+
+type StatsDateRange struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+type StatsWorkoutPlan struct {
+	Id       int    `json:"id"`
+	Title    string `json:"title"`
+	Overview string `json:"overview"`
+	Type     string `json:"type"`
+}
+
+// StatsWorkoutDay 统计里时长最长、最早开始这些单次训练
+type StatsWorkoutDay struct {
+	Id          int              `json:"id"`
+	StartedAt   *time.Time       `json:"started_at"`
+	FinishedAt  *time.Time       `json:"finished_at"`
+	Duration    int              `json:"duration"`
+	TotalVolume float64          `json:"total_volume"`
+	WorkoutPlan StatsWorkoutPlan `json:"workout_plan"`
+}
+
+func newStatsWorkoutDay(v models.WorkoutDay) StatsWorkoutDay {
+	return StatsWorkoutDay{
+		Id:          v.Id,
+		StartedAt:   v.StartedAt,
+		FinishedAt:  v.FinishedAt,
+		Duration:    v.Duration,
+		TotalVolume: v.TotalVolume,
+		WorkoutPlan: StatsWorkoutPlan{
+			Id:       v.WorkoutPlan.Id,
+			Title:    v.WorkoutPlan.Title,
+			Overview: v.WorkoutPlan.Overview,
+			Type:     v.WorkoutPlan.Type,
+		},
+	}
+}
+
+type StatsWorkoutPlanTitle struct {
+	Id    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+type StatsWorkoutDayOfType struct {
+	WorkoutDayId int                   `json:"workout_day_id"`
+	WorkoutPlan  StatsWorkoutPlanTitle `json:"workout_plan"`
+}
+
+// RefreshCoachStatsResponse 一段时间内的训练统计，type_plan_map 按训练计划的类型分组
+type RefreshCoachStatsResponse struct {
+	Stats            CoachWorkoutStats                  `json:"stats"`
+	MaxStreak        int                                `json:"max_streak"`
+	MaxStreakRange   StatsDateRange                     `json:"max_streak_range"`
+	MaxDurationDay   StatsWorkoutDay                    `json:"max_duration_day"`
+	EarliestStartDay StatsWorkoutDay                    `json:"earliest_start_day"`
+	LatestFinishDay  StatsWorkoutDay                    `json:"latest_finish_day"`
+	TypePlanMap      map[string][]StatsWorkoutDayOfType `json:"type_plan_map"`
+	MaxVolumeDay     StatsWorkoutDay                    `json:"max_volume_day"`
+	ActionStats      []ActionStat                       `json:"action_stats"`
+}
+
+type RefreshCoachStatsRequest struct {
+	RangeOfStart *LocalTime `json:"range_of_start"`
+	RangeOfEnd   *LocalTime `json:"range_of_end"`
+}
+
 func (h *CoachHandler) RefreshCoachStats(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 	if uid == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 401, "msg": "请先登录", "data": nil})
+		response.Fail(c, errcode.ErrUnauthorized)
 		return
 	}
-	var body struct {
-		RangeOfStart *LocalTime `json:"range_of_start"`
-		RangeOfEnd   *LocalTime `json:"range_of_end"`
-	}
+	var body RefreshCoachStatsRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.RangeOfStart == nil || body.RangeOfEnd == nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "缺少参数", "data": nil})
+		response.Fail(c, errcode.ErrMissingParam)
 		return
 	}
 	var existing models.Coach
-	if err := h.db.Where("id = ?", uid).First(&existing).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "异常操作", "data": nil})
+	if err := h.db.WithContext(c).Where("id = ?", uid).First(&existing).Error; err != nil {
+		response.Fail(c, errcode.ErrIllegalOperation)
 		return
 	}
 
 	now := time.Now()
 
+	// 统计按用户时区的日期计算，日期参数按同样的格式传，各数据库都按字符串比较
+	// 时差按范围开始时算，范围内切换夏令时的话会差一个小时
+	sql := dialect.For(h.db)
+	loc := existing.Location()
+	_, offset_seconds := body.RangeOfStart.In(loc).Zone()
+	offset := time.Duration(offset_seconds) * time.Second
+	start_date := body.RangeOfStart.In(loc).Format("2006-01-02")
+	end_date := body.RangeOfEnd.In(loc).Format("2006-01-02")
+	finished_date := sql.LocalDate("finished_at", offset)
+	finished_in_range := func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("student_id = ? AND status = 2 AND "+finished_date+" BETWEEN ? AND ?", uid, start_date, end_date)
+	}
+
 	// 1.1 获取所有训练日期（用于最长连续天数）
 	var dateList []string
-	h.db.Raw(`SELECT DISTINCT finished_at as date_str FROM WORKOUT_DAY WHERE student_id = ? AND status = 2 AND DATE(finished_at, '+8 hours') BETWEEN ? AND ? ORDER BY date_str ASC`, uid, body.RangeOfStart.Time, body.RangeOfEnd.Time).Scan(&dateList)
+	h.db.WithContext(c).Model(&models.WorkoutDay{}).Scopes(finished_in_range).
+		Select("DISTINCT " + finished_date + " AS date_str").Order("date_str ASC").Scan(&dateList)
 
 	// fmt.Println("dateList:", dateList)
 	if len(dateList) == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "本月没有任何训练记录", "data": nil})
+		response.Fail(c, errcode.ErrNoWorkoutThisMonth)
 		return
 	}
 
@@ -532,14 +963,14 @@ func (h *CoachHandler) RefreshCoachStats(c *gin.Context) {
 
 	// 2. 训练时长最长、容量最大的、最早开始、最晚完成的 WorkoutDay
 	var max_duration_day, max_volume_day, earliest_start_day, latest_finish_day models.WorkoutDay
-	h.db.Where("student_id = ? AND status = 2 AND DATE(finished_at, '+8 hours') BETWEEN ? AND ?", uid, body.RangeOfStart.Time, body.RangeOfEnd.Time).
+	h.db.WithContext(c).Scopes(finished_in_range).
 		Order("duration DESC").Preload("WorkoutPlan").First(&max_duration_day)
-	h.db.Where("student_id = ? AND status = 2 AND DATE(finished_at, '+8 hours') BETWEEN ? AND ?", uid, body.RangeOfStart.Time, body.RangeOfEnd.Time).
+	h.db.WithContext(c).Scopes(finished_in_range).
 		Order("total_volume DESC").Preload("WorkoutPlan").First(&max_volume_day)
-	h.db.Where("student_id = ? AND status = 2 AND DATE(finished_at, '+8 hours') BETWEEN ? AND ?", uid, body.RangeOfStart.Time, body.RangeOfEnd.Time).
-		Order("strftime('%H', started_at), started_at ASC").Preload("WorkoutPlan").First(&earliest_start_day)
-	h.db.Where("student_id = ? AND status = 2 AND DATE(finished_at, '+8 hours') BETWEEN ? AND ?", uid, body.RangeOfStart.Time, body.RangeOfEnd.Time).
-		Order("strftime('%H', finished_at) DESC, finished_at DESC").Preload("WorkoutPlan").First(&latest_finish_day)
+	h.db.WithContext(c).Scopes(finished_in_range).
+		Order(sql.LocalHour("started_at", offset) + ", started_at ASC").Preload("WorkoutPlan").First(&earliest_start_day)
+	h.db.WithContext(c).Scopes(finished_in_range).
+		Order(sql.LocalHour("finished_at", offset) + " DESC, finished_at DESC").Preload("WorkoutPlan").First(&latest_finish_day)
 
 	// 3. 聚合 WorkoutPlan.type 下的所有 workout_day 及其 workout_plan
 	type WorkoutDayWithPlan struct {
@@ -550,26 +981,27 @@ func (h *CoachHandler) RefreshCoachStats(c *gin.Context) {
 		// 你可以根据需要加更多字段
 	}
 	var workout_days_with_plan []WorkoutDayWithPlan
-	h.db.Raw(`
+	// 表名用 clause.Table 传入，Postgres 下才会加引号保留大写
+	h.db.WithContext(c).Raw(`
 		SELECT 
 			wd.id as workout_day_id, 
 			wp.id as plan_id, 
 			wp.title as plan_title, 
 			wp.type as plan_type
-		FROM WORKOUT_DAY wd 
-		JOIN WORKOUT_PLAN wp ON wd.workout_plan_id = wp.id 
-		WHERE wd.student_id = ? AND wd.status = 2 AND DATE(wd.finished_at, '+8 hours') BETWEEN ? AND ?
+		FROM ? wd 
+		JOIN ? wp ON wd.workout_plan_id = wp.id 
+		WHERE wd.student_id = ? AND wd.status = 2 AND `+sql.LocalDate("wd.finished_at", offset)+` BETWEEN ? AND ?
 		ORDER BY wp.type, wd.id
-	`, uid, body.RangeOfStart.Time, body.RangeOfEnd.Time).Scan(&workout_days_with_plan)
+	`, clause.Table{Name: models.WorkoutDay{}.TableName()}, clause.Table{Name: models.WorkoutPlan{}.TableName()}, uid, start_date, end_date).Scan(&workout_days_with_plan)
 
 	// 按 type 分组
-	workout_day_group_with_type := map[string][]map[string]interface{}{}
+	workout_day_group_with_type := map[string][]StatsWorkoutDayOfType{}
 	for _, v := range workout_days_with_plan {
-		workout_day_group_with_type[v.PlanType] = append(workout_day_group_with_type[v.PlanType], map[string]interface{}{
-			"workout_day_id": v.WorkoutDayId,
-			"workout_plan": map[string]interface{}{
-				"id":    v.PlanId,
-				"title": v.PlanTitle,
+		workout_day_group_with_type[v.PlanType] = append(workout_day_group_with_type[v.PlanType], StatsWorkoutDayOfType{
+			WorkoutDayId: v.WorkoutDayId,
+			WorkoutPlan: StatsWorkoutPlanTitle{
+				Id:    v.PlanId,
+				Title: v.PlanTitle,
 				// 你可以加更多字段
 			},
 		})
@@ -584,7 +1016,7 @@ func (h *CoachHandler) RefreshCoachStats(c *gin.Context) {
 	// 	Progress    float64 `json:"progress"`
 	// }
 	// var plan_stats []PlanStat
-	// h.db.Raw(`
+	// h.db.WithContext(c).Raw(`
 	// SELECT
 	//   wd1.workout_plan_id as plan_id,
 	//   wp.title as plan_name,
@@ -605,22 +1037,15 @@ func (h *CoachHandler) RefreshCoachStats(c *gin.Context) {
 
 	// 总训练次数
 	var totalWorkoutTimes int64
-	h.db.Model(&models.WorkoutDay{}).
-		Where("student_id = ? AND status = 2 AND DATE(finished_at, '+8 hours') BETWEEN ? AND ?", uid, body.RangeOfStart.Time, body.RangeOfEnd.Time).
+	h.db.WithContext(c).Model(&models.WorkoutDay{}).Scopes(finished_in_range).
 		Count(&totalWorkoutTimes)
 
-	type WorkoutStats struct {
-		Version           string    `json:"v"`
-		TotalWorkoutDays  int       `json:"total_workout_days"`
-		TotalWorkoutTimes int64     `json:"total_workout_times"`
-		CreatedAt         time.Time `json:"created_at"`
-	}
-
 	// 1. 统计不重复的训练天数
 	var total_workout_days int
-	h.db.Raw(`SELECT COUNT(DISTINCT DATE(finished_at, '+8 hours')) FROM WORKOUT_DAY WHERE student_id = ? AND status = 2 AND DATE(finished_at, '+8 hours') BETWEEN ? AND ?`, uid, body.RangeOfStart.Time, body.RangeOfEnd.Time).Scan(&total_workout_days)
+	h.db.WithContext(c).Model(&models.WorkoutDay{}).Scopes(finished_in_range).
+		Select("COUNT(DISTINCT " + finished_date + ")").Scan(&total_workout_days)
 
-	stats := WorkoutStats{
+	stats := CoachWorkoutStats{
 		Version:           "250608",
 		TotalWorkoutDays:  total_workout_days,
 		TotalWorkoutTimes: totalWorkoutTimes,
@@ -629,26 +1054,19 @@ func (h *CoachHandler) RefreshCoachStats(c *gin.Context) {
 
 	// 动作统计
 	var action_histories []models.WorkoutActionHistory
-	if err := h.db.
-		Where("student_id = ? AND DATE(created_at, '+8 hours') BETWEEN ? AND ?", uid, body.RangeOfStart.Time, body.RangeOfEnd.Time).
+	if err := h.db.WithContext(c).
+		Where("student_id = ? AND "+sql.LocalDate("created_at", offset)+" BETWEEN ? AND ?", uid, start_date, end_date).
 		Preload("WorkoutAction").
 		Find(&action_histories).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 
 	// 1. 按动作分组
-	type Record struct {
-		Reps       int       `json:"reps"`
-		RepsUnit   string    `json:"reps_unit"`
-		Weight     float64   `json:"weight"`
-		WeightUnit string    `json:"weight_unit"`
-		CreatedAt  time.Time `json:"created_at"`
-	}
-	action_map := make(map[string][]Record)
+	action_map := make(map[string][]ActionStatRecord)
 	for _, history := range action_histories {
 		action_name := history.WorkoutAction.ZhName // 或英文名
-		rec := Record{
+		rec := ActionStatRecord{
 			Reps:       history.Reps,
 			RepsUnit:   history.RepsUnit,
 			Weight:     history.Weight,
@@ -659,10 +1077,6 @@ func (h *CoachHandler) RefreshCoachStats(c *gin.Context) {
 	}
 
 	// 2. 转为目标结构
-	type ActionStat struct {
-		Action  string   `json:"action"`
-		Records []Record `json:"records"`
-	}
 	var action_stats []ActionStat
 	for action, records := range action_map {
 		action_stats = append(action_stats, ActionStat{
@@ -683,30 +1097,30 @@ func (h *CoachHandler) RefreshCoachStats(c *gin.Context) {
 
 	stats_json, err := json.Marshal(stats)
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to marshal stats", "data": nil})
+		response.Fail(c, err)
 		return
 	}
 
-	if err := h.db.Model(&existing).Update("workout_stats", string(stats_json)).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to update stats", "data": nil})
+	if err := h.db.WithContext(c).Model(&existing).Update("workout_stats", string(stats_json)).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "获取成功",
-		"data": gin.H{
-			"stats":      stats,
-			"max_streak": max_streak,
-			"max_streak_range": gin.H{
-				"start": func() string {
+		"data": RefreshCoachStatsResponse{
+			Stats:     stats,
+			MaxStreak: max_streak,
+			MaxStreakRange: StatsDateRange{
+				Start: func() string {
 					if max_streak > 0 {
 						return max_streak_start.Format("2006-01-02")
 					} else {
 						return ""
 					}
 				}(),
-				"end": func() string {
+				End: func() string {
 					if max_streak > 0 {
 						return max_streak_end.Format("2006-01-02")
 					} else {
@@ -714,93 +1128,62 @@ func (h *CoachHandler) RefreshCoachStats(c *gin.Context) {
 					}
 				}(),
 			},
-			"max_duration_day": gin.H{
-				"id":           max_duration_day.Id,
-				"started_at":   max_duration_day.StartedAt,
-				"finished_at":  max_duration_day.FinishedAt,
-				"duration":     max_duration_day.Duration,
-				"total_volume": max_duration_day.TotalVolume,
-				"workout_plan": gin.H{
-					"id":       max_duration_day.WorkoutPlan.Id,
-					"title":    max_duration_day.WorkoutPlan.Title,
-					"overview": max_duration_day.WorkoutPlan.Overview,
-					"type":     max_duration_day.WorkoutPlan.Type,
-				},
-			},
-			"earliest_start_day": gin.H{
-				"id":           earliest_start_day.Id,
-				"started_at":   earliest_start_day.StartedAt,
-				"finished_at":  earliest_start_day.FinishedAt,
-				"duration":     earliest_start_day.Duration,
-				"total_volume": earliest_start_day.TotalVolume,
-				"workout_plan": gin.H{
-					"id":       earliest_start_day.WorkoutPlan.Id,
-					"title":    earliest_start_day.WorkoutPlan.Title,
-					"overview": earliest_start_day.WorkoutPlan.Overview,
-					"type":     earliest_start_day.WorkoutPlan.Type,
-				},
-			},
-			"latest_finish_day": gin.H{
-				"id":           latest_finish_day.Id,
-				"started_at":   latest_finish_day.StartedAt,
-				"finished_at":  latest_finish_day.FinishedAt,
-				"duration":     latest_finish_day.Duration,
-				"total_volume": latest_finish_day.TotalVolume,
-				"workout_plan": gin.H{
-					"id":       latest_finish_day.WorkoutPlan.Id,
-					"title":    latest_finish_day.WorkoutPlan.Title,
-					"overview": latest_finish_day.WorkoutPlan.Overview,
-					"type":     latest_finish_day.WorkoutPlan.Type,
-				},
-			},
-			"type_plan_map": workout_day_group_with_type,
-			"max_volume_day": gin.H{
-				"id":           max_volume_day.Id,
-				"started_at":   max_volume_day.StartedAt,
-				"finished_at":  max_volume_day.FinishedAt,
-				"duration":     max_volume_day.Duration,
-				"total_volume": max_volume_day.TotalVolume,
-				"workout_plan": gin.H{
-					"id":       max_volume_day.WorkoutPlan.Id,
-					"title":    max_volume_day.WorkoutPlan.Title,
-					"overview": max_volume_day.WorkoutPlan.Overview,
-					"type":     max_volume_day.WorkoutPlan.Type,
-				},
-			},
+			MaxDurationDay:   newStatsWorkoutDay(max_duration_day),
+			EarliestStartDay: newStatsWorkoutDay(earliest_start_day),
+			LatestFinishDay:  newStatsWorkoutDay(latest_finish_day),
+			TypePlanMap:      workout_day_group_with_type,
+			MaxVolumeDay:     newStatsWorkoutDay(max_volume_day),
 			// "plan_stats": plan_stats,
-			"action_stats": action_stats,
+			ActionStats: action_stats,
 		},
 	})
 }
 
+type RefreshTodayWorkoutStatsResponse struct {
+	WorkoutSteps  []models.TodayWorkoutActionGroup `json:"workout_steps"`
+	Times         int                              `json:"times"`
+	SetCount      int                              `json:"set_count"`
+	DurationCount int                              `json:"duration_count"`
+	VolumeCount   float64                          `json:"volume_count"`
+	Tags          []string                         `json:"tags"`
+}
+
+type RefreshTodayWorkoutStatsRequest struct {
+	// 都不传时取用户时区下的今天
+	RangeOfStart *time.Time `json:"range_of_start"`
+	RangeOfEnd   *time.Time `json:"range_of_end"`
+}
+
 // 刷新当日的训练总结
 func (h *CoachHandler) RefreshTodayWorkoutStats(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 	if uid == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 401, "msg": "请先登录", "data": nil})
+		response.Fail(c, errcode.ErrUnauthorized)
 		return
 	}
-	var body struct {
-		RangeOfStart *time.Time `json:"range_of_start"`
-		RangeOfEnd   *time.Time `json:"range_of_end"`
-	}
+	var body RefreshTodayWorkoutStatsRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
-	if body.RangeOfStart == nil || body.RangeOfEnd == nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "缺少参数", "data": nil})
+	if (body.RangeOfStart == nil) != (body.RangeOfEnd == nil) {
+		response.Fail(c, errcode.ErrMissingParam)
 		return
 	}
 	var existing_coach models.Coach
-	if err := h.db.Where("id = ?", uid).First(&existing_coach).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "异常操作", "data": nil})
+	if err := h.db.WithContext(c).Where("id = ?", uid).First(&existing_coach).Error; err != nil {
+		response.Fail(c, errcode.ErrIllegalOperation)
 		return
 	}
+	if body.RangeOfStart == nil {
+		today := models.StartOfDay(time.Now(), existing_coach.Location())
+		tomorrow := today.AddDate(0, 0, 1).Add(-time.Second)
+		body.RangeOfStart, body.RangeOfEnd = &today, &tomorrow
+	}
 
 	var existing_workout_days []models.WorkoutDay
-	if err := h.db.Where("student_id = ? AND status = 2 AND finished_at BETWEEN ? AND ?", uid, body.RangeOfStart, body.RangeOfEnd).Find(&existing_workout_days).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Where("student_id = ? AND status = 2 AND finished_at BETWEEN ? AND ?", uid, body.RangeOfStart, body.RangeOfEnd).Find(&existing_workout_days).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
 
@@ -814,7 +1197,7 @@ func (h *CoachHandler) RefreshTodayWorkoutStats(c *gin.Context) {
 		if v.Status != 2 {
 			continue
 		}
-		result, err := models.BuildResultFromWorkoutDay(v, h.db)
+		result, err := models.BuildResultFromWorkoutDay(v, h.db.WithContext(c))
 		if err != nil {
 			error_msg = append(error_msg, err.Error())
 		}
@@ -829,47 +1212,58 @@ func (h *CoachHandler) RefreshTodayWorkoutStats(c *gin.Context) {
 		return x != ""
 	})
 	if len(error_msg) != 0 {
-		c.JSON(http.StatusOK, gin.H{
-			"code": 500,
-			"msg":  strings.Join(error_msg, "\n"),
-			"data": nil,
-		})
+		response.Fail(c, errors.New(strings.Join(error_msg, "\n")))
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "获取成功",
-		"data": gin.H{
-			"workout_steps":  list,
-			"times":          len(existing_workout_days),
-			"set_count":      set_count,
-			"duration_count": duration_count,
-			"volume_count":   total_volume,
-			"tags":           tags,
+		"data": RefreshTodayWorkoutStatsResponse{
+			WorkoutSteps:  list,
+			Times:         len(existing_workout_days),
+			SetCount:      set_count,
+			DurationCount: duration_count,
+			VolumeCount:   total_volume,
+			Tags:          tags,
 		},
 	})
 }
 
+type BodyPartStat struct {
+	BodyPart string `json:"body_part"`
+	Sets     int    `json:"sets"`
+}
+
+type RefreshWorkoutActionStatsResponse struct {
+	BodyPartStats []BodyPartStat `json:"body_part_stats"`
+	TotalSets     int            `json:"total_sets"`
+	TotalActions  int            `json:"total_actions"`
+	RangeStart    *time.Time     `json:"range_start"`
+	RangeEnd      *time.Time     `json:"range_end"`
+}
+
+type RefreshWorkoutActionStatsRequest struct {
+	RangeOfStart *time.Time `json:"range_of_start"`
+	RangeOfEnd   *time.Time `json:"range_of_end"`
+}
+
 func (h *CoachHandler) RefreshWorkoutActionStats(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		RangeOfStart *time.Time `json:"range_of_start"`
-		RangeOfEnd   *time.Time `json:"range_of_end"`
-	}
+	var body RefreshWorkoutActionStatsRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.RangeOfStart == nil || body.RangeOfEnd == nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "缺少参数", "data": nil})
+		response.Fail(c, errcode.ErrMissingParam)
 		return
 	}
 
-	query := h.db.Where("student_id = ? AND created_at BETWEEN ? AND ?", uid, body.RangeOfStart, body.RangeOfEnd)
+	query := h.db.WithContext(c).Where("student_id = ? AND created_at BETWEEN ? AND ?", uid, body.RangeOfStart, body.RangeOfEnd)
 	var workout_action_histories []models.WorkoutActionHistory
 	if err := query.Preload("WorkoutAction").Find(&workout_action_histories).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 
@@ -892,11 +1286,6 @@ func (h *CoachHandler) RefreshWorkoutActionStats(c *gin.Context) {
 	}
 
 	// 将统计结果转换为排序后的切片
-	type BodyPartStat struct {
-		BodyPart string `json:"body_part"`
-		Sets     int    `json:"sets"`
-	}
-
 	var body_part_stats_list []BodyPartStat
 	for body_part, sets := range body_part_stats {
 		body_part_stats_list = append(body_part_stats_list, BodyPartStat{
@@ -917,46 +1306,48 @@ func (h *CoachHandler) RefreshWorkoutActionStats(c *gin.Context) {
 	}
 
 	// 构建响应数据
-	response := gin.H{
-		"body_part_stats": body_part_stats_list,
-		"total_sets":      total_sets,
-		"total_actions":   len(workout_action_histories),
-		"range_start":     body.RangeOfStart,
-		"range_end":       body.RangeOfEnd,
+	stats := RefreshWorkoutActionStatsResponse{
+		BodyPartStats: body_part_stats_list,
+		TotalSets:     total_sets,
+		TotalActions:  len(workout_action_histories),
+		RangeStart:    body.RangeOfStart,
+		RangeEnd:      body.RangeOfEnd,
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "统计数据获取成功",
-		"data": response,
+		"data": stats,
 	})
 }
 
+type CreateStudentRequest struct {
+	Name   string `json:"name" binding:"required,min=1,max=10"`
+	Gender int    `json:"gender"`
+	Age    int    `json:"age"`
+}
+
 func (h *CoachHandler) CreateStudent(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Name   string `json:"name" binding:"required,min=1,max=10"`
-		Gender int    `json:"gender"`
-		Age    int    `json:"age"`
-	}
+	var body CreateStudentRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	if body.Name == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Nickname are required", "data": nil})
+		response.Fail(c, errcode.ErrNicknameRequired)
 		return
 	}
 	if body.Name != "" && sensitive.ContainsSensitiveWord(body.Name) {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "名称包含敏感词", "data": nil})
+		response.Fail(c, errcode.ErrSensitiveName)
 		return
 	}
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			fmt.Println("Recovered from panic:", r)
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			h.logger.WithContext(c).Errorw("Recovered from panic", "panic", r, "stack", string(debug.Stack()))
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	now := time.Now().UTC()
@@ -973,23 +1364,23 @@ func (h *CoachHandler) CreateStudent(c *gin.Context) {
 	}
 	if err := tx.Create(&student).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	profile1 := models.CoachProfile1{
 		CoachId:   student.Id,
 		Nickname:  body.Name,
-		AvatarURL: DefaultAvatarURL,
+		AvatarURL: h.config.DefaultAvatarURL,
 		Age:       body.Age,
 		Gender:    body.Gender,
 	}
 	if err := tx.Create(&profile1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	student.Profile1Id = profile1.Id
 	if err := tx.Save(&student).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	relationship := models.CoachRelationship{
@@ -1001,72 +1392,74 @@ func (h *CoachHandler) CreateStudent(c *gin.Context) {
 	}
 	if err := tx.Create(&relationship).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": gin.H{"id": student.Id}})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": IdResponse{Id: student.Id}})
+}
+
+type UpdateStudentProfileRequest struct {
+	Id        int    `json:"id"`
+	Nickname  string `json:"nickname" binding:"min=3,max=10"`
+	AvatarURL string `json:"avatar_url"`
+	Age       int    `json:"age"`
+	Gender    int    `json:"gender"`
 }
 
 func (h *CoachHandler) UpdateStudentProfile(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Id        int    `json:"id"`
-		Nickname  string `json:"nickname" binding:"min=3,max=10"`
-		AvatarURL string `json:"avatar_url"`
-		Age       int    `json:"age"`
-		Gender    int    `json:"gender"`
-	}
+	var body UpdateStudentProfileRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少id参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
 	if body.Nickname != "" && sensitive.ContainsSensitiveWord(body.Nickname) {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "昵称包含敏感词", "data": nil})
+		response.Fail(c, errcode.ErrSensitiveNick)
 		return
 	}
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	var relation models.CoachRelationship
 	if err := tx.Where("coach_id = ? AND student_id = ?", uid, body.Id).First(&relation).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "异常操作", "data": nil})
+		response.Fail(c, errcode.ErrIllegalOperation)
 		return
 	}
 	var existing models.Coach
 	if err := tx.Where("id = ?", body.Id).Preload("Profile1").First(&existing).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "记录不存在", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 	if existing.Profile1Id == 0 {
 		var existing_profile1 models.CoachProfile1
 		if err := tx.Where("coach_id = ?", body.Id).First(&existing_profile1).Error; err != nil {
 			if err != gorm.ErrRecordNotFound {
-				c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+				response.Fail(c, err)
 				return
 			}
 			existing_profile1.CoachId = existing.Id
 			// 处理历史遗留问题 不存在 CoachProfile1
 			if err := tx.Create(&existing_profile1).Error; err != nil {
 				tx.Rollback()
-				c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+				response.Fail(c, err)
 				return
 			}
 		}
@@ -1075,7 +1468,7 @@ func (h *CoachHandler) UpdateStudentProfile(c *gin.Context) {
 			"profile1_id": existing_profile1.Id,
 		}).Error; err != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
 		existing.Profile1 = existing_profile1
@@ -1085,7 +1478,7 @@ func (h *CoachHandler) UpdateStudentProfile(c *gin.Context) {
 		updates["nickname"] = body.Nickname
 	}
 	if body.AvatarURL != "" {
-		updates["avatar_url"] = AvatarPrefix + body.AvatarURL
+		updates["avatar_url"] = h.config.AvatarURL(body.AvatarURL)
 	}
 	if body.Age != 0 {
 		updates["age"] = body.Age
@@ -1094,155 +1487,260 @@ func (h *CoachHandler) UpdateStudentProfile(c *gin.Context) {
 		updates["gender"] = body.Gender
 	}
 	if err := tx.Model(&existing.Profile1).Updates(&updates).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "更新成功", "data": nil})
 }
 
+type DeleteStudentRequest struct {
+	Id int `json:"id"`
+}
+
 func (h *CoachHandler) DeleteStudent(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body DeleteStudentRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少id参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
-	query := h.db.Where("d IS NULL OR d = 0")
+	query := h.db.WithContext(c).Where("d IS NULL OR d = 0")
 	query = query.Where("coach_id = ? AND student_id = ?", uid, body.Id)
 	var existing models.CoachRelationship
 	if err := query.First(&existing).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "没有找到记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
-	if err := h.db.Model(&existing).Update("d", 1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Model(&existing).Update("d", 1).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "删除成功", "data": nil})
 }
 
+type BuildStudentAuthURLRequest struct {
+	Id int `json:"id"`
+}
+
+// AuthURLResponse 免密登录的链接
+type AuthURLResponse struct {
+	URL string `json:"url"`
+}
+
 func (h *CoachHandler) BuildStudentAuthURL(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body BuildStudentAuthURLRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少id参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
-	query := h.db.Where("d IS NULL OR d = 0")
+	query := h.db.WithContext(c).Where("d IS NULL OR d = 0")
 	query = query.Where("coach_id = ? AND student_id = ?", uid, body.Id)
 	var existing models.CoachRelationship
 	if err := query.First(&existing).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "没有找到记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
-	token, _, err := models.GenerateJWT(existing.StudentId, h.config.TokenSecretKey)
+	// 学员通过链接登录后只能记录训练
+	code, err := models.CreateMagicLink(h.db.WithContext(c), existing.StudentId, models.ScopeWorkout, 0)
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
-	data := gin.H{
-		"url": "/home/index?token=" + token,
+	data := AuthURLResponse{
+		URL: "/home/index?code=" + code,
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": data})
 }
 
+type BuildCoachAuthURLInAdminRequest struct {
+	Id int `json:"id"`
+}
+
 func (h *CoachHandler) BuildCoachAuthURLInAdmin(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 	if uid != 1 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "没有权限", "data": nil})
+		response.Fail(c, errcode.ErrForbidden)
 		return
 	}
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body BuildCoachAuthURLInAdminRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少id参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
 	var existing models.Coach
-	if err := h.db.Where("id = ?", body.Id).First(&existing).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Where("id = ?", body.Id).First(&existing).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
-	token, _, err := models.GenerateJWT(existing.Id, h.config.TokenSecretKey)
+	// 代登录的会话里记录管理员，期间的操作都会记下来
+	code, err := models.CreateMagicLink(h.db.WithContext(c), existing.Id, "", uid)
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
-	data := gin.H{
-		"url": MobileSiteHostname + "/home/index?token=" + token,
+	data := AuthURLResponse{
+		URL: h.config.MobileURL("/home/index?code=" + code),
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": data})
 }
 
-func (h *CoachHandler) CreateCoach(c *gin.Context) {
-	uid := int(c.GetFloat64("id"))
-	if uid != 1 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "没有权限", "data": nil})
-	}
-	var body struct {
-		Nickname  string `json:"nickname"`
-		AvatarURL string `json:"avatar_url"`
-		Bio       string `json:"bio"`
-	}
+type ExchangeMagicLinkRequest struct {
+	Code string `json:"code"`
+}
+
+// 用登录链接里的 code 换会话，每个链接只能用一次
+func (h *CoachHandler) ExchangeMagicLink(c *gin.Context) {
+	var body ExchangeMagicLinkRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
-	if body.Nickname == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "缺少昵称", "data": nil})
-		return
-	}
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
+			h.logger.WithContext(c).Errorw("Recovered from panic", "panic", r, "stack", string(debug.Stack()))
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
-
-	now := time.Now()
-	the_coach := models.Coach{
-		Nickname:  body.Nickname,
-		Config:    "{}",
-		Bio:       body.Bio,
-		CreatedAt: now,
-	}
-
-	if err := tx.Create(&the_coach).Error; err != nil {
+	link, err := models.ConsumeMagicLink(tx, body.Code)
+	if err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to create coach", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		if err == models.ErrInvalidMagicLink {
+			response.Fail(c, errcode.ErrLinkExpired)
+			return
+		}
+		response.Fail(c, err)
+		return
+	}
+	auth_resp, err := models.CreateAuthURLSession(tx, link, c.ClientIP(), h.config.TokenSecretKey)
+	if err != nil {
+		tx.Rollback()
+		h.logger.WithContext(c).Error("Failed to generate JWT", err)
+		response.Fail(c, err)
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	if link.ActorId != 0 {
+		h.logger.WithContext(c).Infow("Impersonation started", "actor_id", link.ActorId, "coach_id", link.CoachId, "ip", c.ClientIP())
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "登录成功", "data": auth_resp})
+}
+
+type FetchImpersonationLogListRequest struct {
+	models.Pagination
+	ActorId int `json:"actor_id"`
+	CoachId int `json:"coach_id"`
+}
+
+// 管理员代登录的操作记录
+func (h *CoachHandler) FetchImpersonationLogList(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	if uid != 1 {
+		response.Fail(c, errcode.ErrForbidden)
+		return
+	}
+	var body FetchImpersonationLogListRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	query := h.db.WithContext(c)
+	if body.ActorId != 0 {
+		query = query.Where("actor_id = ?", body.ActorId)
+	}
+	if body.CoachId != 0 {
+		query = query.Where("coach_id = ?", body.CoachId)
+	}
+	pb := pagination.NewPaginationBuilder[models.CoachImpersonationLog](query).
+		SetLimit(body.PageSize).
+		SetPage(body.Page).
+		SetOrderBy("created_at DESC, id DESC")
+	var list1 []models.CoachImpersonationLog
+	if err := pb.Build().Find(&list1).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	list, has_more, next_marker := pb.ProcessResults(list1)
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "",
+		"data": pagination.NewList(pb, list, has_more, next_marker),
+	})
+}
+
+type CreateCoachRequest struct {
+	Nickname  string `json:"nickname"`
+	AvatarURL string `json:"avatar_url"`
+	Bio       string `json:"bio"`
+}
+
+func (h *CoachHandler) CreateCoach(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	if uid != 1 {
+		response.Fail(c, errcode.ErrForbidden)
+		return
+	}
+	var body CreateCoachRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if body.Nickname == "" {
+		response.Fail(c, errcode.ErrNicknameRequired)
+		return
+	}
+	tx := h.db.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			response.Fail(c, errcode.ErrInternal)
+		}
+	}()
+
+	now := time.Now()
+	the_coach := models.Coach{
+		Nickname:  body.Nickname,
+		Config:    "{}",
+		Bio:       body.Bio,
+		CreatedAt: now,
+	}
+
+	if err := tx.Create(&the_coach).Error; err != nil {
+		tx.Rollback()
+		h.logger.WithContext(c).Error("Failed to create coach", err)
+		response.Fail(c, err)
 		return
 	}
 	profile1 := models.CoachProfile1{
@@ -1252,38 +1750,50 @@ func (h *CoachHandler) CreateCoach(c *gin.Context) {
 	}
 	if err := tx.Create(&profile1).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to create profile1", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to create coach account", "data": nil})
+		h.logger.WithContext(c).Error("Failed to create profile1", err)
+		response.Fail(c, err)
 		return
 	}
 	the_coach.Profile1Id = profile1.Id
 	if err := tx.Save(&the_coach).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to create coach account", "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": nil})
 }
 
+type FetchCoachListRequest struct {
+	models.Pagination
+	Keyword string `json:"keyword"`
+}
+
+type CoachItem struct {
+	Id        int       `json:"id"`
+	Nickname  string    `json:"nickname"`
+	AvatarURL string    `json:"avatar_url"`
+	Age       int       `json:"age"`
+	Gender    int       `json:"gender"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 func (h *CoachHandler) FetchCoachList(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 	if uid != 1 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "没有权限", "data": nil})
-	}
-	var body struct {
-		models.Pagination
-		Keyword string `json:"keyword"`
+		response.Fail(c, errcode.ErrForbidden)
+		return
 	}
+	var body FetchCoachListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
-	query := h.db.Where("d IS NULL OR d = 0")
+	query := h.db.WithContext(c).Where("d IS NULL OR d = 0")
 	if body.Keyword != "" {
 		query = query.Where("coach.profile1.nickname LIKE ?", "%"+body.Keyword+"%")
 	}
@@ -1294,45 +1804,71 @@ func (h *CoachHandler) FetchCoachList(c *gin.Context) {
 
 	var list1 []models.Coach
 	if err := pb.Build().Preload("Profile1").Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
-	list := make([]map[string]interface{}, 0, len(list2))
+	list := make([]CoachItem, 0, len(list2))
 	for _, v := range list2 {
-		list = append(list, map[string]interface{}{
-			"id":         v.Id,
-			"nickname":   v.Profile1.Nickname,
-			"avatar_url": v.Profile1.AvatarURL,
-			"age":        v.Profile1.Age,
-			"gender":     v.Profile1.Gender,
-			"created_at": v.CreatedAt,
+		list = append(list, CoachItem{
+			Id:        v.Id,
+			Nickname:  v.Profile1.Nickname,
+			AvatarURL: v.Profile1.AvatarURL,
+			Age:       v.Profile1.Age,
+			Gender:    v.Profile1.Gender,
+			CreatedAt: v.CreatedAt,
 		})
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "Success",
-		"data": gin.H{
-			"list":        list,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list, has_more, next_marker),
 	})
 }
 
+// StudentItem 学员、教练、好友列表里的对方，role 是从自己的角度看的关系
+type StudentItem struct {
+	Id        int       `json:"id"`
+	Nickname  string    `json:"nickname"`
+	AvatarURL string    `json:"avatar_url"`
+	Age       int       `json:"age"`
+	Gender    int       `json:"gender"`
+	Role      int       `json:"role"`
+	RoleText  string    `json:"role_text"`
+	Status    int       `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newStudentItem(id int, profile models.CoachProfile1, relation models.CoachRelationship, role_text string) StudentItem {
+	return StudentItem{
+		Id:        id,
+		Nickname:  profile.Nickname,
+		AvatarURL: profile.AvatarURL,
+		Age:       profile.Age,
+		Gender:    profile.Gender,
+		Role:      relation.Role,
+		RoleText:  role_text,
+		Status:    relation.Status,
+		CreatedAt: relation.CreatedAt,
+	}
+}
+
+type FetchStudentListRequest struct {
+	models.Pagination
+	Keyword string `json:"keyword"`
+}
+
 func (h *CoachHandler) FetchStudentList(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		models.Pagination
-		Keyword string `json:"keyword"`
-	}
+	var body FetchStudentListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
-	query := h.db.Where("d IS NULL OR d = 0")
+	query := h.db.WithContext(c).Where("d IS NULL OR d = 0")
 	query = query.Where("coach_relationship.coach_id = ? OR coach_relationship.student_id = ?", uid, uid)
+	// 已拒绝、已解除的关系不再展示
+	query = query.Where("coach_relationship.status IN (?)", []int{models.RelationPending, models.RelationConfirmed})
 	if body.Keyword != "" {
 		query = query.Joins("JOIN coach_profile1 ON coach_relationship.student_id = coach_profile1.coach_id").
 			Where("coach_profile1.nickname LIKE ?", "%"+body.Keyword+"%")
@@ -1344,73 +1880,33 @@ func (h *CoachHandler) FetchStudentList(c *gin.Context) {
 
 	var list1 []models.CoachRelationship
 	if err := pb.Build().Preload("Coach").Preload("Coach.Profile1").Preload("Student").Preload("Student.Profile1").Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
-	list := make([]map[string]interface{}, 0, len(list2))
+	list := make([]StudentItem, 0, len(list2))
 	for _, v := range list2 {
-		if v.Role == models.RoleCoachStudent {
+		if v.Role == models.RoleCoachStudent || v.Role == models.RoleCoachAndStudentHasAccount {
 			if uid == v.StudentId {
-				data := map[string]interface{}{
-					"id":         v.CoachId,
-					"nickname":   v.Coach.Profile1.Nickname,
-					"avatar_url": v.Coach.Profile1.AvatarURL,
-					"age":        v.Coach.Profile1.Age,
-					"gender":     v.Coach.Profile1.Gender,
-					"role":       v.Role,
-					"role_text":  "教练",
-					"status":     v.Status,
-					"created_at": v.CreatedAt,
-				}
-				list = append(list, data)
+				data := newStudentItem(v.CoachId, v.Coach.Profile1, v, "教练")
 				if v.Role == int(models.RoleCoachStudent) {
-					data["role"] = int(models.RoleStudentCoach)
+					data.Role = int(models.RoleStudentCoach)
 				}
 				if v.Role == int(models.RoleCoachAndStudentHasAccount) {
-					data["role"] = int(models.RoleStudentHasAccountAndCoach)
+					data.Role = int(models.RoleStudentHasAccountAndCoach)
 				}
+				list = append(list, data)
 			}
 			if uid == v.CoachId {
-				list = append(list, map[string]interface{}{
-					"id":         v.StudentId,
-					"nickname":   v.Student.Profile1.Nickname,
-					"avatar_url": v.Student.Profile1.AvatarURL,
-					"age":        v.Student.Profile1.Age,
-					"gender":     v.Student.Profile1.Gender,
-					"role":       v.Role,
-					"role_text":  "学员",
-					"status":     v.Status,
-					"created_at": v.CreatedAt,
-				})
+				list = append(list, newStudentItem(v.StudentId, v.Student.Profile1, v, "学员"))
 			}
 		}
 		if v.Role == models.RoleFriendAndFriend {
 			if uid == v.StudentId {
-				list = append(list, map[string]interface{}{
-					"id":         v.CoachId,
-					"nickname":   v.Coach.Profile1.Nickname,
-					"avatar_url": v.Coach.Profile1.AvatarURL,
-					"age":        v.Coach.Profile1.Age,
-					"gender":     v.Coach.Profile1.Gender,
-					"role":       v.Role,
-					"role_text":  "好友",
-					"status":     v.Status,
-					"created_at": v.CreatedAt,
-				})
+				list = append(list, newStudentItem(v.CoachId, v.Coach.Profile1, v, "好友"))
 			}
 			if uid == v.CoachId {
-				list = append(list, map[string]interface{}{
-					"id":         v.StudentId,
-					"nickname":   v.Student.Profile1.Nickname,
-					"avatar_url": v.Student.Profile1.AvatarURL,
-					"age":        v.Student.Profile1.Age,
-					"gender":     v.Student.Profile1.Gender,
-					"role":       v.Role,
-					"role_text":  "好友",
-					"status":     v.Status,
-					"created_at": v.CreatedAt,
-				})
+				list = append(list, newStudentItem(v.StudentId, v.Student.Profile1, v, "好友"))
 			}
 
 		}
@@ -1419,148 +1915,301 @@ func (h *CoachHandler) FetchStudentList(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "Success",
-		"data": gin.H{
-			"list":        list,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list, has_more, next_marker),
 	})
 }
 
+type StudentProfile struct {
+	Id        int    `json:"id"`
+	Nickname  string `json:"nickname"`
+	AvatarURL string `json:"avatar_url"`
+	Gender    int    `json:"gender"`
+	Age       int    `json:"age"`
+	Status    int    `json:"status"`
+	Role      int    `json:"role"`
+}
+
+type FetchStudentProfileRequest struct {
+	Id int `json:"id"`
+}
+
 func (h *CoachHandler) FetchStudentProfile(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body FetchStudentProfileRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少id参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
-	query := h.db.Where("d IS NULL OR d = 0")
+	query := h.db.WithContext(c).Where("d IS NULL OR d = 0")
 	query = query.Where("id = ?", body.Id)
 	var profile models.Coach
 	if err := query.Preload("Profile1").First(&profile).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "没有找到该记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 	var relation models.CoachRelationship
-	if err := h.db.Where("d IS NULL OR d = 0").Where("coach_id = ? AND student_id = ? OR coach_id = ? AND student_id = ?", uid, body.Id, body.Id, uid).First(&relation).Error; err != nil {
+	if err := h.db.WithContext(c).Where("d IS NULL OR d = 0").Where("coach_id = ? AND student_id = ? OR coach_id = ? AND student_id = ?", uid, body.Id, body.Id, uid).First(&relation).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 501, "msg": "没有找到该记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
-	data := gin.H{
-		"id":         profile.Id,
-		"nickname":   profile.Profile1.Nickname,
-		"avatar_url": profile.Profile1.AvatarURL,
-		"gender":     profile.Profile1.Gender,
-		"age":        profile.Profile1.Age,
-		"status":     relation.Status,
-		"role":       relation.Role,
+	data := StudentProfile{
+		Id:        profile.Id,
+		Nickname:  profile.Profile1.Nickname,
+		AvatarURL: profile.Profile1.AvatarURL,
+		Gender:    profile.Profile1.Gender,
+		Age:       profile.Profile1.Age,
+		Status:    relation.Status,
+		Role:      relation.Role,
 	}
 	if relation.StudentId == uid {
 		if relation.Role == int(models.RoleCoachStudent) {
-			data["role"] = int(models.RoleStudentCoach)
+			data.Role = int(models.RoleStudentCoach)
 		}
 		if relation.Role == int(models.RoleCoachAndStudentHasAccount) {
-			data["role"] = int(models.RoleStudentHasAccountAndCoach)
+			data.Role = int(models.RoleStudentHasAccountAndCoach)
 		}
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "请求成功", "data": data})
 }
 
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// 用 refresh token 换新的 access token，同时轮换 refresh token
+// 已经被换掉的 refresh token 再次出现，说明可能泄露了，直接注销整个会话
 func (h *CoachHandler) RefreshToken(c *gin.Context) {
+	var body RefreshTokenRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	session_id, err := models.ParseRefreshToken(body.RefreshToken)
+	if err != nil {
+		response.Fail(c, errcode.ErrTokenInvalid)
+		return
+	}
+	now := time.Now()
+	var session models.CoachSession
+	if err := h.db.WithContext(c).Where("session_id = ?", session_id).First(&session).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+		response.Fail(c, errcode.ErrTokenInvalid)
+		return
+	}
+	if session.Status != models.SessionStatusActive || session.Device == models.SessionDeviceAuthURL || !now.Before(session.ExpiredAt) {
+		response.Fail(c, errcode.ErrTokenExpired)
+		return
+	}
+	hash := models.HashToken(body.RefreshToken)
+	refresh_token, err := models.NewRefreshToken(session_id)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	// 只有当前的 refresh token 能换成功，并发刷新时只有一个会成功
+	r := h.db.WithContext(c).Model(&models.CoachSession{}).
+		Where("id = ? AND status = ? AND refresh_token_hash = ?", session.Id, models.SessionStatusActive, hash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash": models.HashToken(refresh_token),
+			"last_used_at":       now,
+		})
+	if r.Error != nil {
+		response.Fail(c, r.Error)
+		return
+	}
+	if r.RowsAffected == 0 {
+		h.logger.WithContext(c).Warn(fmt.Sprintf("Refresh token reused, revoke session %d of coach %d", session.Id, session.CoachId))
+		if _, err := models.RevokeCoachSessions(h.db.WithContext(c), session.CoachId, session.SessionId); err != nil {
+			response.Fail(c, err)
+			return
+		}
+		response.Fail(c, errcode.ErrTokenInvalid)
+		return
+	}
+	token, expires_at, err := models.GenerateJWT(session.CoachId, session.SessionId, models.DefaultJWTConfig.TokenDuration, h.config.TokenSecretKey)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	auth_resp := models.AuthResponse{
+		Token:            "Bearer " + token,
+		ExpiresAt:        expires_at.Unix(),
+		RefreshToken:     refresh_token,
+		RefreshExpiresAt: session.ExpiredAt.Unix(),
+		Status:           "success",
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "请求成功", "data": auth_resp})
+}
+
+type SessionItem struct {
+	Id         int        `json:"id"`
+	Device     string     `json:"device"`
+	IP         string     `json:"ip"`
+	IsCurrent  bool       `json:"is_current"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiredAt  time.Time  `json:"expired_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type FetchSessionListResponse struct {
+	List []SessionItem `json:"list"`
+}
+
+// 我的登录设备
+func (h *CoachHandler) FetchSessionList(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
+	current := c.GetString("session_id")
+	var list1 []models.CoachSession
+	if err := h.db.WithContext(c).Where("coach_id = ? AND status = ? AND expired_at > ?", uid, models.SessionStatusActive, time.Now()).
+		Order("created_at DESC").
+		Find(&list1).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	list := make([]SessionItem, 0, len(list1))
+	for _, v := range list1 {
+		list = append(list, SessionItem{
+			Id:         v.Id,
+			Device:     v.Device,
+			IP:         v.IP,
+			IsCurrent:  v.SessionId == current,
+			LastUsedAt: v.LastUsedAt,
+			ExpiredAt:  v.ExpiredAt,
+			CreatedAt:  v.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": FetchSessionListResponse{List: list}})
+}
 
-	// Generate new JWT token
-	token, expires_at, err := models.GenerateJWT(uid, h.config.TokenSecretKey)
-	if err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to generate token", "data": nil})
+type LogoutRequest struct {
+	Id int `json:"id"`
+}
+
+// 退出登录，传 id 时注销指定设备，否则注销当前设备
+func (h *CoachHandler) Logout(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body LogoutRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	session_id := c.GetString("session_id")
+	if body.Id != 0 {
+		var session models.CoachSession
+		if err := h.db.WithContext(c).Where("id = ? AND coach_id = ?", body.Id, uid).First(&session).Error; err != nil {
+			if err != gorm.ErrRecordNotFound {
+				response.Fail(c, err)
+				return
+			}
+			response.Fail(c, errcode.ErrNotFound)
+			return
+		}
+		session_id = session.SessionId
+	}
+	if _, err := models.RevokeCoachSessions(h.db.WithContext(c), uid, session_id); err != nil {
+		response.Fail(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "退出成功", "data": nil})
+}
+
+type LogoutAllResponse struct {
+	Count int64 `json:"count"`
+}
 
-	// Return response
-	response := models.AuthResponse{
-		Token:     "Bearer " + token,
-		ExpiresAt: expires_at.Unix(),
-		Status:    "success",
+// 退出所有设备，包括当前设备
+func (h *CoachHandler) LogoutAll(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	count, err := models.RevokeCoachSessions(h.db.WithContext(c), uid, "")
+	if err != nil {
+		response.Fail(c, err)
+		return
 	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "退出成功", "data": LogoutAllResponse{Count: count}})
+}
 
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "请求成功", "data": response})
+type CreateAccountRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
 }
 
 // 通过授权链接访问，并且补全登录信息
 func (h *CoachHandler) CreateAccount(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
-	}
+	var body CreateAccountRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	if body.Email == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "请输入邮箱", "data": nil})
+		response.Fail(c, errcode.ErrEmailRequired)
 		return
 	}
 	if body.Password == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "请输入密码", "data": nil})
+		response.Fail(c, errcode.ErrPasswordRequired)
 		return
 	}
-	tx := h.db.Begin()
+	// 代登录时不能替用户设置账号密码
+	if c.GetFloat64("actor_id") != 0 {
+		response.Fail(c, errcode.ErrForbidden)
+		return
+	}
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Println("Recovered from panic:", r)
+			h.logger.WithContext(c).Errorw("Recovered from panic", "panic", r, "stack", string(debug.Stack()))
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 
 	var coach models.Coach
 	if err := tx.Where("id = ?", uid).First(&coach).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "异常操作", "data": nil})
+		response.Fail(c, errcode.ErrIllegalOperation)
 		return
 	}
 	var relation models.CoachRelationship
 	// 只有学员才需要补全帐号
 	if err := tx.Where("student_id = ?", uid).First(&relation).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "异常操作", "data": nil})
+		response.Fail(c, errcode.ErrIllegalOperation)
 		return
 	}
 	var existing models.CoachAccount
 	query := tx.Where("provider_type = ? AND provider_id = ?", models.AccountProviderTypeEmailWithPwd, body.Email)
 	if err := query.First(&existing).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "已经补全过帐号了", "data": nil})
+		response.Fail(c, errcode.ErrAccountCompleted)
 		return
 	}
 	if existing.CoachId != 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "该邮箱已被使用", "data": nil})
+		response.Fail(c, errcode.ErrEmailUsed)
 		return
 	}
 
@@ -1569,7 +2218,7 @@ func (h *CoachHandler) CreateAccount(c *gin.Context) {
 	hashed_pwd, err := bcrypt.GenerateFromPassword([]byte(body.Password), bcrypt.DefaultCost)
 	if err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 501, "msg": "注册失败", "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	the_coach_account := models.CoachAccount{
@@ -1581,35 +2230,36 @@ func (h *CoachHandler) CreateAccount(c *gin.Context) {
 	}
 	if err := tx.Create(&the_coach_account).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to create coach account", err)
-		c.JSON(http.StatusOK, gin.H{"code": 502, "msg": "操作失败", "data": nil})
+		h.logger.WithContext(c).Error("Failed to create coach account", err)
+		response.Fail(c, err)
 		return
 	}
 	// 补全后，更新教练和学员的关系，让教练不能再管理学员了
 	if err := tx.Model(&relation).Update("role", int(models.RoleCoachAndStudentHasAccount)).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 502, "msg": "操作失败", "data": nil})
+		response.Fail(c, err)
 		return
 	}
 
-	token, expires_at, err := models.GenerateJWT(coach.Id, h.config.TokenSecretKey)
+	// 授权链接的会话用完就注销，之后用正常登录的会话
+	if _, err := models.RevokeCoachSessions(tx, coach.Id, c.GetString("session_id")); err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	auth_resp, err := models.CreateCoachSession(tx, coach.Id, c.GetHeader("User-Agent"), c.ClientIP(), h.config.TokenSecretKey)
 	if err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to generate JWT", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "操作失败", "data": nil})
+		h.logger.WithContext(c).Error("Failed to generate JWT", err)
+		response.Fail(c, err)
 		return
 	}
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
-	response := models.AuthResponse{
-		Token:     "Bearer " + token,
-		ExpiresAt: expires_at.Unix(),
-		Status:    "success",
-	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "操作成功", "data": response})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "操作成功", "data": auth_resp})
 }
 
 type PersonAvatar struct {
@@ -1625,20 +2275,58 @@ type AvatarGroup struct {
 	Persons []PersonWithAvatars
 }
 
+// ArticleReviewState 审核状态只有作者能看到，其他人看不到这几个字段
+type ArticleReviewState struct {
+	Status       int    `json:"status"`
+	RejectReason string `json:"reject_reason"`
+	IsPublished  bool   `json:"is_published"`
+}
+
+func newArticleReviewState(v models.CoachContent, uid int, now time.Time) *ArticleReviewState {
+	if v.CoachId != uid {
+		return nil
+	}
+	return &ArticleReviewState{
+		Status:       v.Status,
+		RejectReason: v.RejectReason,
+		IsPublished:  v.IsPublished(now),
+	}
+}
+
+type ArticleItem struct {
+	Id           int        `json:"id"`
+	Title        string     `json:"title"`
+	Overview     string     `json:"overview"`
+	Type         int        `json:"type"`
+	VideoURL     string     `json:"video_url"`
+	Creator      CoachBrief `json:"creator"`
+	LikeCount    int        `json:"like_count"`
+	CommentCount int        `json:"comment_count"`
+	IsLiked      bool       `json:"is_liked"`
+	IsFavorited  bool       `json:"is_favorited"`
+	PublishedAt  *time.Time `json:"published_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	*ArticleReviewState
+}
+
+type FetchArticleListRequest struct {
+	models.Pagination
+}
+
 func (h *CoachHandler) FetchArticleList(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		models.Pagination
-	}
+	var body FetchArticleListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
-	query := h.db.Where("d IS NULL OR d = 0")
+	now := time.Now()
+	query := h.db.WithContext(c).Where("d IS NULL OR d = 0")
 	if uid != 0 {
-		query = query.Where("(publish = 1) OR (publish = 2 AND coach_id = ?)", uid)
+		query = query.Where("(publish = 1 AND status = ? AND published_at <= ?) OR coach_id = ?", models.CoachContentStatusApproved, now, uid)
+		query = query.Where("coach_id NOT IN (?)", models.BlockedCoachIds(h.db.WithContext(c), uid))
 	} else {
-		query = query.Where("publish = 1")
+		query = query.Where("publish = 1 AND status = ? AND published_at <= ?", models.CoachContentStatusApproved, now)
 	}
 	pb := pagination.NewPaginationBuilder[models.CoachContent](query).
 		SetLimit(body.PageSize).
@@ -1646,137 +2334,214 @@ func (h *CoachHandler) FetchArticleList(c *gin.Context) {
 		SetOrderBy("created_at DESC")
 	var list1 []models.CoachContent
 	if err := pb.Build().Preload("Coach.Profile1").Find(&list1).Error; err != nil {
-		h.logger.Error("Failed to fetch coach content list", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		h.logger.WithContext(c).Error("Failed to fetch coach content list", err)
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
-	fmt.Println("the", len(list2))
-	list := make([]map[string]interface{}, 0, len(list2))
+	ids := make([]int, 0, len(list2))
 	for _, v := range list2 {
-		list = append(list, gin.H{
-			"id":        v.Id,
-			"title":     v.Title,
-			"overview":  v.Description,
-			"type":      v.ContentType,
-			"video_url": v.VideoKey,
-			"creator": gin.H{
-				"nickname":   v.Coach.Profile1.Nickname,
-				"avatar_url": v.Coach.Profile1.AvatarURL,
-			},
-			"created_at": v.CreatedAt,
+		ids = append(ids, v.Id)
+	}
+	liked, err := models.FetchLikedContentIds(h.db.WithContext(c), uid, ids)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	favorited, err := models.FetchFavoriteContentIds(h.db.WithContext(c), uid, models.FavoriteContentTypeCoachContent, ids)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	list := make([]ArticleItem, 0, len(list2))
+	for _, v := range list2 {
+		list = append(list, ArticleItem{
+			Id:                 v.Id,
+			Title:              v.Title,
+			Overview:           v.Description,
+			Type:               v.ContentType,
+			VideoURL:           v.VideoKey,
+			Creator:            newCoachBrief(v.CoachId, v.Coach.Profile1),
+			LikeCount:          v.LikeCount,
+			CommentCount:       v.CommentCount,
+			IsLiked:            liked[v.Id],
+			IsFavorited:        favorited[v.Id],
+			PublishedAt:        v.PublishedAt,
+			CreatedAt:          v.CreatedAt,
+			ArticleReviewState: newArticleReviewState(v, uid, now),
 		})
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "",
-		"data": gin.H{
-			"list":        list,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list, has_more, next_marker),
 	})
 }
 
+type ArticleTimePointAction struct {
+	Id     int    `json:"id"`
+	ZhName string `json:"zh_name"`
+	Score  int    `json:"score"`
+}
+
+// ArticleTimePoint 视频里的时间点，没有关联动作时 workout_action 为 null
+type ArticleTimePoint struct {
+	Id            int                     `json:"id"`
+	Text          string                  `json:"text"`
+	Time          int                     `json:"time"`
+	WorkoutAction *ArticleTimePointAction `json:"workout_action"`
+}
+
+type ArticleProfile struct {
+	Id           int                `json:"id"`
+	Title        string             `json:"title"`
+	Overview     string             `json:"overview"`
+	Type         int                `json:"type"`
+	VideoURL     string             `json:"video_url"`
+	CreatedAt    time.Time          `json:"created_at"`
+	TimePoints   []ArticleTimePoint `json:"time_points"`
+	IsAuthor     bool               `json:"is_author"`
+	Creator      CoachBrief         `json:"creator"`
+	LikeCount    int                `json:"like_count"`
+	CommentCount int                `json:"comment_count"`
+	IsLiked      bool               `json:"is_liked"`
+	IsFavorited  bool               `json:"is_favorited"`
+	PublishedAt  *time.Time         `json:"published_at"`
+	*ArticleReviewState
+}
+
+type FetchArticleProfileRequest struct {
+	Id int `json:"id"`
+}
+
 func (h *CoachHandler) FetchArticleProfile(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body FetchArticleProfileRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少参数", "data": nil})
+		response.Fail(c, errcode.ErrMissingParam)
 		return
 	}
-	query := h.db.Where("d IS NULL OR d == 0")
+	now := time.Now()
+	query := h.db.WithContext(c).Where("d IS NULL OR d == 0")
 	if uid != 0 {
-		query = query.Where("(publish = 1) OR (publish = 2 AND coach_id = ?)", uid)
+		query = query.Where("(publish = 1 AND status = ? AND published_at <= ?) OR coach_id = ?", models.CoachContentStatusApproved, now, uid)
 	} else {
-		query = query.Where("publish = 1")
+		query = query.Where("publish = 1 AND status = ? AND published_at <= ?", models.CoachContentStatusApproved, now)
 	}
 	query = query.Where("id = ?", body.Id)
 	var existing models.CoachContent
 	if err := query.Preload("Coach.Profile1").First(&existing).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "没有找到记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 	var the_points1 []models.CoachContentWithWorkoutAction
-	query2 := h.db.Where("d IS NULL OR d = 0")
+	query2 := h.db.WithContext(c).Where("d IS NULL OR d = 0")
 	query2 = query2.Where("coach_content_id = ?", existing.Id)
 	if err := query2.Preload("WorkoutAction").Find(&the_points1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
-	points2 := make([]map[string]interface{}, 0, len(the_points1))
+	points2 := make([]ArticleTimePoint, 0, len(the_points1))
 	for _, v := range the_points1 {
-		var act map[string]interface{}
+		var act *ArticleTimePointAction
 		if v.WorkoutActionId != 0 {
-			act = make(map[string]interface{})
-			act["id"] = v.WorkoutAction.Id
-			act["zh_name"] = v.WorkoutAction.ZhName
-			act["score"] = v.WorkoutAction.Score
-		}
-		points2 = append(points2, gin.H{
-			"id":             v.Id,
-			"text":           v.Text,
-			"time":           v.StartPoint,
-			"workout_action": act,
+			act = &ArticleTimePointAction{
+				Id:     v.WorkoutAction.Id,
+				ZhName: v.WorkoutAction.ZhName,
+				Score:  v.WorkoutAction.Score,
+			}
+		}
+		points2 = append(points2, ArticleTimePoint{
+			Id:            v.Id,
+			Text:          v.Text,
+			Time:          v.StartPoint,
+			WorkoutAction: act,
 		})
 	}
-	data := gin.H{
-		"id":          existing.Id,
-		"title":       existing.Title,
-		"overview":    existing.Description,
-		"type":        existing.ContentType,
-		"video_url":   existing.VideoKey,
-		"created_at":  existing.CreatedAt,
-		"time_points": points2,
-		"is_author":   existing.CoachId == uid,
-		"creator": gin.H{
-			"nickname":   existing.Coach.Profile1.Nickname,
-			"avatar_url": existing.Coach.Profile1.AvatarURL,
-		},
+	liked, err := models.FetchLikedContentIds(h.db.WithContext(c), uid, []int{existing.Id})
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	favorited, err := models.FetchFavoriteContentIds(h.db.WithContext(c), uid, models.FavoriteContentTypeCoachContent, []int{existing.Id})
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	data := ArticleProfile{
+		Id:                 existing.Id,
+		Title:              existing.Title,
+		Overview:           existing.Description,
+		Type:               existing.ContentType,
+		VideoURL:           existing.VideoKey,
+		CreatedAt:          existing.CreatedAt,
+		TimePoints:         points2,
+		IsAuthor:           existing.CoachId == uid,
+		Creator:            newCoachBrief(existing.CoachId, existing.Coach.Profile1),
+		LikeCount:          existing.LikeCount,
+		CommentCount:       existing.CommentCount,
+		IsLiked:            liked[existing.Id],
+		IsFavorited:        favorited[existing.Id],
+		PublishedAt:        existing.PublishedAt,
+		ArticleReviewState: newArticleReviewState(existing, uid, now),
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": data})
 }
 
+// ArticleStatusResponse 创建、提交审核后的审核状态
+type ArticleStatusResponse struct {
+	Id           int    `json:"id"`
+	Status       int    `json:"status"`
+	RejectReason string `json:"reject_reason"`
+}
+
+type CreateArticleRequest struct {
+	Title    string `json:"title"`
+	Overview string `json:"overview"`
+	Type     int    `json:"type"`
+	Status   int    `json:"status"`
+	// CoverImageURL string `json:"cover_image_url"`
+	// ContentURL    string `json:"content_url"`
+	VideoURL   string `json:"video_url"`
+	TimePoints []struct {
+		Time            int    `json:"time"`
+		Text            string `json:"text"`
+		WorkoutActionId int    `json:"workout_action_id"`
+	} `json:"time_points"`
+	// 定时发布，为空表示审核通过后立即发布
+	PublishedAt *time.Time `json:"published_at"`
+	// 创建后直接提交审核
+	Submit bool `json:"submit"`
+}
+
 func (h *CoachHandler) CreateArticle(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Title    string `json:"title"`
-		Overview string `json:"overview"`
-		Type     int    `json:"type"`
-		Status   int    `json:"status"`
-		// CoverImageURL string `json:"cover_image_url"`
-		// ContentURL    string `json:"content_url"`
-		VideoURL   string `json:"video_url"`
-		TimePoints []struct {
-			Time            int    `json:"time"`
-			Text            string `json:"text"`
-			WorkoutActionId int    `json:"workout_action_id"`
-		} `json:"time_points"`
-	}
+	var body CreateArticleRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	if body.Title == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "缺少标题", "data": nil})
+		response.Fail(c, errcode.ErrTitleRequired)
+		return
+	}
+	if body.PublishedAt != nil && body.PublishedAt.Before(time.Now()) {
+		response.Fail(c, errcode.ErrPublishTimeInvalid)
 		return
 	}
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	// var existing models.CoachContent
@@ -1792,19 +2557,22 @@ func (h *CoachHandler) CreateArticle(c *gin.Context) {
 		ContentURL:    "",
 		CoverImageURL: "",
 		VideoKey:      body.VideoURL,
-		Status:        1,
+		Status:        models.CoachContentStatusDraft,
 		Publish:       body.Status,
+		PublishedAt:   body.PublishedAt,
 		LikeCount:     0,
 		CreatedAt:     now,
 		CoachId:       uid,
 	}
 	if err := tx.Create(&the_content).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to create content", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		h.logger.WithContext(c).Error("Failed to create content", err)
+		response.Fail(c, err)
 		return
 	}
+	texts := []string{body.Title, body.Overview}
 	for _, point := range body.TimePoints {
+		texts = append(texts, point.Text)
 		the_content_with_action := models.CoachContentWithWorkoutAction{
 			WorkoutActionId: point.WorkoutActionId,
 			CoachContentId:  the_content.Id,
@@ -1815,63 +2583,79 @@ func (h *CoachHandler) CreateArticle(c *gin.Context) {
 		}
 		if err := tx.Create(&the_content_with_action).Error; err != nil {
 			tx.Rollback()
-			h.logger.Error("Failed to create point", err)
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			h.logger.WithContext(c).Error("Failed to create point", err)
+			response.Fail(c, err)
 			return
 		}
 
 	}
+	if body.Submit {
+		if err := submitCoachContent(tx, &the_content, texts, now); err != nil {
+			tx.Rollback()
+			response.Fail(c, err)
+			return
+		}
+	}
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": gin.H{
-		"id": the_content.Id,
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": ArticleStatusResponse{
+		Id:           the_content.Id,
+		Status:       the_content.Status,
+		RejectReason: the_content.RejectReason,
 	}})
 }
 
+type UpdateArticleRequest struct {
+	Id         int    `json:"id"`
+	Title      string `json:"title"`
+	Overview   string `json:"overview"`
+	Type       int    `json:"type"`
+	VideoURL   string `json:"video_url"`
+	TimePoints []struct {
+		Id              int    `json:"id"`
+		Time            int    `json:"time"`
+		Text            string `json:"text"`
+		WorkoutActionId int    `json:"workout_action_id"`
+	} `json:"time_points"`
+	PublishedAt *time.Time `json:"published_at"`
+}
+
 func (h *CoachHandler) UpdateArticle(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Id         int    `json:"id"`
-		Title      string `json:"title"`
-		Overview   string `json:"overview"`
-		Type       int    `json:"type"`
-		VideoURL   string `json:"video_url"`
-		TimePoints []struct {
-			Id              int    `json:"id"`
-			Time            int    `json:"time"`
-			Text            string `json:"text"`
-			WorkoutActionId int    `json:"workout_action_id"`
-		} `json:"time_points"`
-	}
+	var body UpdateArticleRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "缺少 id", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
+		return
+	}
+	if body.PublishedAt != nil && body.PublishedAt.Before(time.Now()) {
+		response.Fail(c, errcode.ErrPublishTimeInvalid)
 		return
 	}
 	if body.Title == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "缺少标题", "data": nil})
+		response.Fail(c, errcode.ErrTitleRequired)
 		return
 	}
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	var existing models.CoachContent
 	if err := tx.Where("id = ? AND coach_id = ?", body.Id, uid).First(&existing).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "没有找到记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 	now := time.Now()
@@ -1888,10 +2672,18 @@ func (h *CoachHandler) UpdateArticle(c *gin.Context) {
 	if body.VideoURL != "" {
 		updates["video_key"] = body.VideoURL
 	}
+	if body.PublishedAt != nil {
+		updates["published_at"] = body.PublishedAt
+	}
+	// 修改过的内容需要重新提交审核
+	if existing.Status != models.CoachContentStatusDraft {
+		updates["status"] = models.CoachContentStatusDraft
+	}
+	updates["updated_at"] = now
 	if err := tx.Model(&existing).Updates(&updates).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to create content", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		h.logger.WithContext(c).Error("Failed to create content", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -1899,7 +2691,7 @@ func (h *CoachHandler) UpdateArticle(c *gin.Context) {
 	var existing_points []models.CoachContentWithWorkoutAction
 	if err := tx.Where("d IS NULL OR d = 0").Where("coach_content_id = ?", existing.Id).Find(&existing_points).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	existing_point_map := make(map[int]models.CoachContentWithWorkoutAction)
@@ -1920,8 +2712,8 @@ func (h *CoachHandler) UpdateArticle(c *gin.Context) {
 				}
 				if err := tx.Model(&models.CoachContentWithWorkoutAction{}).Where("id = ?", point.Id).Updates(update_fields).Error; err != nil {
 					tx.Rollback()
-					h.logger.Error("Failed to update point", err)
-					c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+					h.logger.WithContext(c).Error("Failed to update point", err)
+					response.Fail(c, err)
 					return
 				}
 				new_point_id_set[point.Id] = true
@@ -1938,8 +2730,8 @@ func (h *CoachHandler) UpdateArticle(c *gin.Context) {
 			}
 			if err := tx.Create(&newPoint).Error; err != nil {
 				tx.Rollback()
-				h.logger.Error("Failed to create point", err)
-				c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+				h.logger.WithContext(c).Error("Failed to create point", err)
+				response.Fail(c, err)
 				return
 			}
 			new_point_id_set[newPoint.Id] = true
@@ -1951,8 +2743,8 @@ func (h *CoachHandler) UpdateArticle(c *gin.Context) {
 		if !new_point_id_set[id] {
 			if err := tx.Model(&models.CoachContentWithWorkoutAction{}).Where("id = ?", id).Update("d", 1).Error; err != nil {
 				tx.Rollback()
-				h.logger.Error("Failed to delete point", err)
-				c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+				h.logger.WithContext(c).Error("Failed to delete point", err)
+				response.Fail(c, err)
 				return
 			}
 		}
@@ -1960,101 +2752,465 @@ func (h *CoachHandler) UpdateArticle(c *gin.Context) {
 
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "更新成功", "data": gin.H{
-		"id": existing.Id,
-	}})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "更新成功", "data": IdResponse{Id: existing.Id}})
 }
 
-func (h *CoachHandler) CreateCoachContent(c *gin.Context) {
-	// uid := int(c.GetFloat64("id"))
-	var body struct {
-		Title         string `json:"title"`
-		Description   string `json:"description"`
-		ContentType   int    `json:"content_type"`
-		CoverImageURL string `json:"cover_image_url"`
-		ContentURL    string `json:"content_url"`
-		VideoKey      string `json:"video_key"`
-		CoachId       int    `json:"coach_id"`
+// submitCoachContent 提交审核，先做敏感词检测，命中的直接审核不通过
+func submitCoachContent(tx *gorm.DB, content *models.CoachContent, texts []string, now time.Time) error {
+	updates := map[string]interface{}{
+		"submitted_at": now,
+		"updated_at":   now,
+	}
+	words := sensitive.FindSensitiveWords(strings.Join(texts, "\n"))
+	if len(words) != 0 {
+		reason := "内容包含敏感词：" + strings.Join(words, "、")
+		updates["status"] = models.CoachContentStatusRejected
+		updates["reject_reason"] = reason
+		updates["reviewer_id"] = 0
+		updates["reviewed_at"] = now
+		review := models.CoachContentReview{
+			Status:         models.CoachContentStatusRejected,
+			Reason:         reason,
+			ReviewerId:     0,
+			CoachContentId: content.Id,
+			CreatedAt:      now,
+		}
+		if err := tx.Create(&review).Error; err != nil {
+			return err
+		}
+	} else {
+		updates["status"] = models.CoachContentStatusSubmitted
+		updates["reject_reason"] = ""
+	}
+	if err := tx.Model(content).Updates(updates).Error; err != nil {
+		return err
 	}
+	return nil
+}
+
+type SubmitArticleRequest struct {
+	Id          int        `json:"id"`
+	PublishedAt *time.Time `json:"published_at"`
+}
+
+// 作者提交审核
+func (h *CoachHandler) SubmitArticle(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body SubmitArticleRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
-	if body.Title == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "缺少标题", "data": nil})
+	if body.Id == 0 {
+		response.Fail(c, errcode.MissingParam("id"))
+		return
+	}
+	now := time.Now()
+	if body.PublishedAt != nil && body.PublishedAt.Before(now) {
+		response.Fail(c, errcode.ErrPublishTimeInvalid)
 		return
 	}
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	var existing models.CoachContent
-	if err := tx.Where("content_url = ?", body.ContentURL).First(&existing).Error; err == nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "该记录已存在", "data": nil})
+	if err := tx.Where("d IS NULL OR d = 0").Where("id = ? AND coach_id = ?", body.Id, uid).First(&existing).Error; err != nil {
+		tx.Rollback()
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
-	now := time.Now()
-	the_content := models.CoachContent{
-		Title:         body.Title,
-		ContentType:   body.ContentType,
-		Description:   body.Description,
-		ContentURL:    body.ContentURL,
-		CoverImageURL: body.ContentURL,
-		VideoKey:      body.VideoKey,
-		Status:        1,
-		Publish:       1,
-		LikeCount:     0,
-		CreatedAt:     now,
-		CoachId:       body.CoachId,
+	if existing.Status == models.CoachContentStatusSubmitted {
+		tx.Rollback()
+		response.Fail(c, errcode.ErrContentInReview)
+		return
 	}
-	if err := tx.Create(&the_content).Error; err != nil {
+	if existing.Status == models.CoachContentStatusApproved {
 		tx.Rollback()
-		h.logger.Error("Failed to create coach", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrContentApproved)
 		return
 	}
-	if err := tx.Commit().Error; err != nil {
+	if body.PublishedAt != nil {
+		if err := tx.Model(&existing).Update("published_at", body.PublishedAt).Error; err != nil {
+			tx.Rollback()
+			response.Fail(c, err)
+			return
+		}
+	}
+	var points []models.CoachContentWithWorkoutAction
+	if err := tx.Where("d IS NULL OR d = 0").Where("coach_content_id = ?", existing.Id).Find(&points).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": nil})
+	texts := []string{existing.Title, existing.Description}
+	for _, p := range points {
+		texts = append(texts, p.Text)
+	}
+	if err := submitCoachContent(tx, &existing, texts, now); err != nil {
+		tx.Rollback()
+		h.logger.WithContext(c).Error("Failed to submit content", err)
+		response.Fail(c, err)
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	msg := "提交成功，请等待审核"
+	if existing.Status == models.CoachContentStatusRejected {
+		msg = existing.RejectReason
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": msg, "data": ArticleStatusResponse{
+		Id:           existing.Id,
+		Status:       existing.Status,
+		RejectReason: existing.RejectReason,
+	}})
+}
+
+type ArticleReviewItem struct {
+	Id        int       `json:"id"`
+	Status    int       `json:"status"`
+	Reason    string    `json:"reason"`
+	IsAuto    bool      `json:"is_auto"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type FetchArticleReviewListResponse struct {
+	Status       int                 `json:"status"`
+	RejectReason string              `json:"reject_reason"`
+	SubmittedAt  *time.Time          `json:"submitted_at"`
+	ReviewedAt   *time.Time          `json:"reviewed_at"`
+	List         []ArticleReviewItem `json:"list"`
+}
+
+type FetchArticleReviewListRequest struct {
+	Id int `json:"id"`
+}
+
+// 作者查看内容的审核记录
+func (h *CoachHandler) FetchArticleReviewList(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body FetchArticleReviewListRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if body.Id == 0 {
+		response.Fail(c, errcode.MissingParam("id"))
+		return
+	}
+	var existing models.CoachContent
+	if err := h.db.WithContext(c).Where("id = ?", body.Id).First(&existing).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+		response.Fail(c, errcode.ErrNotFound)
+		return
+	}
+	if existing.CoachId != uid && uid != 1 {
+		response.Fail(c, errcode.ErrForbidden)
+		return
+	}
+	var reviews []models.CoachContentReview
+	if err := h.db.WithContext(c).Where("coach_content_id = ?", existing.Id).Order("created_at DESC").Find(&reviews).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	list := make([]ArticleReviewItem, 0, len(reviews))
+	for _, v := range reviews {
+		list = append(list, ArticleReviewItem{
+			Id:        v.Id,
+			Status:    v.Status,
+			Reason:    v.Reason,
+			IsAuto:    v.ReviewerId == 0,
+			CreatedAt: v.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": FetchArticleReviewListResponse{
+		Status:       existing.Status,
+		RejectReason: existing.RejectReason,
+		SubmittedAt:  existing.SubmittedAt,
+		ReviewedAt:   existing.ReviewedAt,
+		List:         list,
+	}})
+}
+
+type PendingArticleItem struct {
+	Id          int        `json:"id"`
+	Title       string     `json:"title"`
+	Overview    string     `json:"overview"`
+	Type        int        `json:"type"`
+	VideoURL    string     `json:"video_url"`
+	Publish     int        `json:"publish"`
+	PublishedAt *time.Time `json:"published_at"`
+	SubmittedAt *time.Time `json:"submitted_at"`
+	Creator     CoachBrief `json:"creator"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type FetchPendingArticleListRequest struct {
+	models.Pagination
+}
+
+// 管理后台 待审核的内容列表
+func (h *CoachHandler) FetchPendingArticleList(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	if uid != 1 {
+		response.Fail(c, errcode.ErrForbidden)
+		return
+	}
+	var body FetchPendingArticleListRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	query := h.db.WithContext(c).Where("d IS NULL OR d = 0")
+	query = query.Where("status = ?", models.CoachContentStatusSubmitted)
+	pb := pagination.NewPaginationBuilder[models.CoachContent](query).
+		SetLimit(body.PageSize).
+		SetPage(body.Page).
+		SetOrderBy("submitted_at ASC")
+	var list1 []models.CoachContent
+	if err := pb.Build().Preload("Coach.Profile1").Find(&list1).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	list2, has_more, next_marker := pb.ProcessResults(list1)
+	list := make([]PendingArticleItem, 0, len(list2))
+	for _, v := range list2 {
+		list = append(list, PendingArticleItem{
+			Id:          v.Id,
+			Title:       v.Title,
+			Overview:    v.Description,
+			Type:        v.ContentType,
+			VideoURL:    v.VideoKey,
+			Publish:     v.Publish,
+			PublishedAt: v.PublishedAt,
+			SubmittedAt: v.SubmittedAt,
+			Creator:     newCoachBrief(v.CoachId, v.Coach.Profile1),
+			CreatedAt:   v.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "",
+		"data": pagination.NewList(pb, list, has_more, next_marker),
+	})
+}
+
+type ReviewArticleResponse struct {
+	Id          int        `json:"id"`
+	Status      int        `json:"status"`
+	PublishedAt *time.Time `json:"published_at"`
+}
+
+type ReviewArticleRequest struct {
+	Id       int    `json:"id"`
+	Approved bool   `json:"approved"`
+	Reason   string `json:"reason"`
+}
+
+// 管理后台 审核内容
+func (h *CoachHandler) ReviewArticle(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	if uid != 1 {
+		response.Fail(c, errcode.ErrForbidden)
+		return
+	}
+	var body ReviewArticleRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if body.Id == 0 {
+		response.Fail(c, errcode.MissingParam("id"))
+		return
+	}
+	if !body.Approved && body.Reason == "" {
+		response.Fail(c, errcode.ErrRejectReasonMissing)
+		return
+	}
+	tx := h.db.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			response.Fail(c, errcode.ErrInternal)
+		}
+	}()
+	var existing models.CoachContent
+	if err := tx.Where("d IS NULL OR d = 0").Where("id = ?", body.Id).First(&existing).Error; err != nil {
+		tx.Rollback()
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+		response.Fail(c, errcode.ErrNotFound)
+		return
+	}
+	if existing.Status != models.CoachContentStatusSubmitted {
+		tx.Rollback()
+		response.Fail(c, errcode.ErrContentNotPending)
+		return
+	}
+	now := time.Now()
+	review := models.CoachContentReview{
+		Status:         models.CoachContentStatusApproved,
+		ReviewerId:     uid,
+		CoachContentId: existing.Id,
+		CreatedAt:      now,
+	}
+	updates := map[string]interface{}{
+		"status":        models.CoachContentStatusApproved,
+		"reject_reason": "",
+		"reviewer_id":   uid,
+		"reviewed_at":   now,
+	}
+	if body.Approved {
+		// 没有设置定时发布，或者定时已经过了，审核通过后立即发布
+		if existing.PublishedAt == nil || existing.PublishedAt.Before(now) {
+			updates["published_at"] = now
+		}
+	} else {
+		review.Status = models.CoachContentStatusRejected
+		review.Reason = body.Reason
+		updates["status"] = models.CoachContentStatusRejected
+		updates["reject_reason"] = body.Reason
+	}
+	if err := tx.Create(&review).Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	if err := tx.Model(&existing).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "操作成功", "data": ReviewArticleResponse{
+		Id:          existing.Id,
+		Status:      existing.Status,
+		PublishedAt: existing.PublishedAt,
+	}})
+}
+
+type CreateCoachContentRequest struct {
+	Title         string `json:"title"`
+	Description   string `json:"description"`
+	ContentType   int    `json:"content_type"`
+	CoverImageURL string `json:"cover_image_url"`
+	ContentURL    string `json:"content_url"`
+	VideoKey      string `json:"video_key"`
+	CoachId       int    `json:"coach_id"`
+}
+
+// 管理后台 代教练发布内容，直接审核通过
+func (h *CoachHandler) CreateCoachContent(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	if uid != 1 {
+		response.Fail(c, errcode.ErrForbidden)
+		return
+	}
+	var body CreateCoachContentRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if body.Title == "" {
+		response.Fail(c, errcode.ErrTitleRequired)
+		return
+	}
+	tx := h.db.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			response.Fail(c, errcode.ErrInternal)
+		}
+	}()
+	var existing models.CoachContent
+	if err := tx.Where("content_url = ?", body.ContentURL).First(&existing).Error; err == nil {
+		tx.Rollback()
+		response.Fail(c, errcode.ErrAlreadyExists)
+		return
+	}
+	now := time.Now()
+	the_content := models.CoachContent{
+		Title:         body.Title,
+		ContentType:   body.ContentType,
+		Description:   body.Description,
+		ContentURL:    body.ContentURL,
+		CoverImageURL: body.ContentURL,
+		VideoKey:      body.VideoKey,
+		Status:        models.CoachContentStatusApproved,
+		Publish:       1,
+		PublishedAt:   &now,
+		ReviewedAt:    &now,
+		ReviewerId:    uid,
+		LikeCount:     0,
+		CreatedAt:     now,
+		CoachId:       body.CoachId,
+	}
+	if err := tx.Create(&the_content).Error; err != nil {
+		tx.Rollback()
+		h.logger.WithContext(c).Error("Failed to create coach", err)
+		response.Fail(c, err)
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": nil})
+}
+
+type AddFriendRequest struct {
+	// 添加好友只能通过 uid
+	UID string `json:"uid"`
 }
 
 // 添加好友
 func (h *CoachHandler) AddFriend(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		// 添加好友只能通过 uid
-		UID string `json:"uid"`
-	}
+	var body AddFriendRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.UID == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "缺少参数", "data": nil})
+		response.Fail(c, errcode.ErrMissingParam)
 		return
 	}
 	var friend models.Coach
-	if err := h.db.Where("nickname = ?", body.UID).First(&friend).Error; err != nil {
+	if err := h.db.WithContext(c).Where("nickname = ?", body.UID).First(&friend).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "UID 错误", "data": nil})
+		response.Fail(c, errcode.ErrInvalidParam)
 		return
 	}
 	var existing models.CoachRelationship
-	if err := h.db.Where("coach_id = ? AND student_id = ? OR coach_id = ? AND student_id = ?", uid, friend.Id, friend.Id, uid).First(&existing).Error; err != nil {
+	if err := h.db.WithContext(c).Where("coach_id = ? AND student_id = ? OR coach_id = ? AND student_id = ?", uid, friend.Id, friend.Id, uid).First(&existing).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
 		created := models.CoachRelationship{
@@ -2062,335 +3218,657 @@ func (h *CoachHandler) AddFriend(c *gin.Context) {
 			CoachId:   uid,
 			StudentId: friend.Id,
 		}
-		if err := h.db.Create(&created).Error; err != nil {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		if err := h.db.WithContext(c).Create(&created).Error; err != nil {
+			response.Fail(c, err)
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "添加成功", "data": nil})
 		return
 	}
 	if existing.Role == 1 {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "无法添加为好友", "data": nil})
+		response.Fail(c, errcode.ErrCannotAddFriend)
 		return
 	}
 	if existing.Role == 2 {
-		c.JSON(http.StatusOK, gin.H{"code": 201, "msg": "无法添加为好友", "data": nil})
+		response.Fail(c, errcode.ErrCannotAddFriend)
 		return
 	}
 	if existing.Role == 3 {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "已经添加过了", "data": nil})
+		response.Fail(c, errcode.ErrAlreadyExists)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "异常操作", "data": nil})
+	response.Fail(c, errcode.ErrIllegalOperation)
+}
+
+type StudentToFriendRequest struct {
+	Id int `json:"id"`
 }
 
 func (h *CoachHandler) StudentToFriend(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body StudentToFriendRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "缺少参数", "data": nil})
+		response.Fail(c, errcode.ErrMissingParam)
 		return
 	}
 	var friend models.Coach
-	if err := h.db.Where("id = ?", body.Id).First(&friend).Error; err != nil {
+	if err := h.db.WithContext(c).Where("id = ?", body.Id).First(&friend).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "用户不存在", "data": nil})
+		response.Fail(c, errcode.ErrUserNotFound)
 		return
 	}
 	var existing models.CoachRelationship
-	if err := h.db.Where("coach_id = ? AND student_id = ?", uid, friend.Id).First(&existing).Error; err != nil {
+	if err := h.db.WithContext(c).Where("coach_id = ? AND student_id = ?", uid, friend.Id).First(&existing).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "记录不存在", "data": nil})
 		return
 	}
 	if existing.Role == int(models.RoleCoachStudent) {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "无法添加为好友", "data": nil})
+		response.Fail(c, errcode.ErrCannotAddFriend)
 		return
 	}
 	if existing.Role == int(models.RoleFriendAndFriend) {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "已经是好友了", "data": nil})
+		response.Fail(c, errcode.ErrAlreadyFriend)
 		return
 	}
 	if existing.Role == int(models.RoleCoachAndStudentHasAccount) {
-		if err := h.db.Model(&existing).Update("role", int(models.RoleFriendAndFriend)).Error; err != nil {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		if err := h.db.WithContext(c).Model(&existing).Update("role", int(models.RoleFriendAndFriend)).Error; err != nil {
+			response.Fail(c, err)
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "操作成功", "data": nil})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "异常操作", "data": nil})
+	response.Fail(c, errcode.ErrIllegalOperation)
+}
+
+type FollowCoachRequest struct {
+	FollowingId int `json:"following_id"`
 }
 
 // 我关注别人
 func (h *CoachHandler) FollowCoach(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		FollowingId int `json:"following_id"`
-	}
+	var body FollowCoachRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.FollowingId == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "缺少关注人信息", "data": nil})
+		response.Fail(c, errcode.MissingParam("following_id"))
+		return
+	}
+	if body.FollowingId == uid {
+		response.Fail(c, errcode.ErrFollowSelf)
+		return
+	}
+	blocked, err := models.IsBlocked(h.db.WithContext(c), uid, body.FollowingId)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if blocked {
+		response.Fail(c, errcode.ErrCannotFollow)
 		return
 	}
 	var existing models.CoachFollow
-	if err := h.db.Where("following_id = ? AND follower_id = ?", body.FollowingId, uid).First(&existing).Error; err != nil {
+	if err := h.db.WithContext(c).Where("following_id = ? AND follower_id = ?", body.FollowingId, uid).First(&existing).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
 		the_created := models.CoachFollow{
-			Status:      1,
+			Status:      models.FollowStatusFollowing,
 			FollowingId: body.FollowingId,
 			FollowerId:  uid,
 		}
-		if err := h.db.Create(&the_created).Error; err != nil {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		if err := h.db.WithContext(c).Create(&the_created).Error; err != nil {
+			response.Fail(c, err)
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "关注成功", "data": nil})
 		return
 	}
-	if existing.Status == 1 {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "已经关注了", "data": nil})
+	if existing.Status == models.FollowStatusFollowing {
+		response.Fail(c, errcode.ErrAlreadyFollowed)
 		return
 	}
-	if err := h.db.Model(&existing).Update("status", 1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Model(&existing).Update("status", models.FollowStatusFollowing).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "关注成功", "data": nil})
 }
 
+type UnFollowCoachRequest struct {
+	FollowingId int `json:"following_id"`
+}
+
 // 我取消关注别人
 func (h *CoachHandler) UnFollowCoach(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		FollowingId int `json:"following_id"`
-	}
+	var body UnFollowCoachRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.FollowingId == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "缺少关注人信息", "data": nil})
+		response.Fail(c, errcode.MissingParam("following_id"))
 		return
 	}
 	var existing models.CoachFollow
-	if err := h.db.Where("following_id = ? AND follower_id = ?", body.FollowingId, uid).First(&existing).Error; err != nil {
+	if err := h.db.WithContext(c).Where("following_id = ? AND follower_id = ?", body.FollowingId, uid).First(&existing).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "没有关注记录", "data": nil})
 		return
 	}
-	if existing.Status == 2 {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "已经取消关注了", "data": nil})
+	if existing.Status == models.FollowStatusUnfollowed {
+		response.Fail(c, errcode.ErrNotFollowed)
 		return
 	}
-	if err := h.db.Model(&existing).Update("status", 2).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Model(&existing).Update("status", models.FollowStatusUnfollowed).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "取消关注成功", "data": nil})
 }
 
+// FollowItem 关注、粉丝列表里的人
+type FollowItem struct {
+	CoachBrief
+	IsMutual bool `json:"is_mutual"`
+}
+
+type FetchMyFollowerListRequest struct {
+	models.Pagination
+}
+
 // 获取我的关注者列表
 func (h *CoachHandler) FetchMyFollowerList(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		models.Pagination
-	}
+	var body FetchMyFollowerListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
-	query := h.db
-	query = query.Where("following_id = ?", uid)
+	query := h.db.WithContext(c)
+	query = query.Where("following_id = ? AND status = ?", uid, models.FollowStatusFollowing)
 	pb := pagination.NewPaginationBuilder[models.CoachFollow](query).
 		SetLimit(body.PageSize).
 		SetPage(body.Page).
 		SetOrderBy("created_at DESC")
 	var list1 []models.CoachFollow
 	if err := pb.Build().Preload("Follower.Profile1").Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch discount policies", "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
-	list := make([]map[string]interface{}, 0, len(list2))
+	ids := make([]int, 0, len(list2))
+	for _, v := range list2 {
+		ids = append(ids, v.FollowerId)
+	}
+	// 我也关注了对方就是互相关注
+	mutual, err := models.FetchFollowingIds(h.db.WithContext(c), uid, ids)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	list := make([]FollowItem, 0, len(list2))
 	for _, v := range list2 {
-		list = append(list, map[string]interface{}{
-			"id":         v.FollowerId,
-			"nickname":   v.Follower.Profile1.Nickname,
-			"avatar_url": v.Follower.Profile1.AvatarURL,
+		list = append(list, FollowItem{
+			CoachBrief: newCoachBrief(v.FollowerId, v.Follower.Profile1),
+			IsMutual:   mutual[v.FollowerId],
 		})
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "",
-		"data": gin.H{
-			"list":        list,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list, has_more, next_marker),
 	})
 }
 
+type FetchMyFollowingListRequest struct {
+	models.Pagination
+}
+
 // 获取我关注的人列表
 func (h *CoachHandler) FetchMyFollowingList(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		models.Pagination
-	}
+	var body FetchMyFollowingListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
-	query := h.db
-	query = query.Where("follower_id = ?", uid)
+	query := h.db.WithContext(c)
+	query = query.Where("follower_id = ? AND status = ?", uid, models.FollowStatusFollowing)
 	pb := pagination.NewPaginationBuilder[models.CoachFollow](query).
 		SetLimit(body.PageSize).
 		SetPage(body.Page).
 		SetOrderBy("created_at DESC")
 	var list1 []models.CoachFollow
 	if err := pb.Build().Preload("Following.Profile1").Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch discount policies", "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
-	list := make([]map[string]interface{}, 0, len(list2))
+	ids := make([]int, 0, len(list2))
 	for _, v := range list2 {
-		list = append(list, map[string]interface{}{
-			"id":         v.FollowingId,
-			"nickname":   v.Following.Profile1.Nickname,
-			"avatar_url": v.Following.Profile1.AvatarURL,
+		ids = append(ids, v.FollowingId)
+	}
+	// 对方也关注了我就是互相关注
+	mutual, err := models.FetchFollowerIds(h.db.WithContext(c), uid, ids)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	list := make([]FollowItem, 0, len(list2))
+	for _, v := range list2 {
+		list = append(list, FollowItem{
+			CoachBrief: newCoachBrief(v.FollowingId, v.Following.Profile1),
+			IsMutual:   mutual[v.FollowingId],
 		})
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "",
-		"data": gin.H{
-			"list":        list,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list, has_more, next_marker),
 	})
 }
 
+type BlockCoachRequest struct {
+	BlockedId int `json:"blocked_id"`
+}
+
+// 拉黑，同时解除双方的关注
+func (h *CoachHandler) BlockCoach(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body BlockCoachRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
+		return
+	}
+	if body.BlockedId == 0 || body.BlockedId == uid {
+		response.Fail(c, errcode.MissingParam("blocked_id"))
+		return
+	}
+	tx := h.db.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			response.Fail(c, errcode.ErrInternal)
+		}
+	}()
+	record := models.CoachBlock{
+		BlockerId: uid,
+		BlockedId: body.BlockedId,
+		CreatedAt: time.Now(),
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&record).Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	if err := tx.Model(&models.CoachFollow{}).
+		Where("(follower_id = ? AND following_id = ?) OR (follower_id = ? AND following_id = ?)", uid, body.BlockedId, body.BlockedId, uid).
+		Where("status = ?", models.FollowStatusFollowing).
+		Updates(map[string]interface{}{"status": models.FollowStatusUnfollowed, "updated_at": time.Now()}).Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "拉黑成功", "data": nil})
+}
+
+type UnblockCoachRequest struct {
+	BlockedId int `json:"blocked_id"`
+}
+
+// 取消拉黑，之前的关注不会恢复
+func (h *CoachHandler) UnblockCoach(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body UnblockCoachRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
+		return
+	}
+	if body.BlockedId == 0 {
+		response.Fail(c, errcode.MissingParam("blocked_id"))
+		return
+	}
+	if err := h.db.WithContext(c).Where("blocker_id = ? AND blocked_id = ?", uid, body.BlockedId).Delete(&models.CoachBlock{}).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "取消拉黑成功", "data": nil})
+}
+
+type BlockItem struct {
+	CoachBrief
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type FetchMyBlockListRequest struct {
+	models.Pagination
+}
+
+// 获取我拉黑的人列表
+func (h *CoachHandler) FetchMyBlockList(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body FetchMyBlockListRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	query := h.db.WithContext(c).Where("blocker_id = ?", uid)
+	pb := pagination.NewPaginationBuilder[models.CoachBlock](query).
+		SetLimit(body.PageSize).
+		SetPage(body.Page).
+		SetOrderBy("created_at DESC")
+	var list1 []models.CoachBlock
+	if err := pb.Build().Preload("Blocked.Profile1").Find(&list1).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	list2, has_more, next_marker := pb.ProcessResults(list1)
+	list := make([]BlockItem, 0, len(list2))
+	for _, v := range list2 {
+		list = append(list, BlockItem{
+			CoachBrief: newCoachBrief(v.BlockedId, v.Blocked.Profile1),
+			CreatedAt:  v.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "",
+		"data": pagination.NewList(pb, list, has_more, next_marker),
+	})
+}
+
+// FollowSuggestion 推荐关注的人，reason 是推荐的原因
+type FollowSuggestion struct {
+	CoachBrief
+	Reason string `json:"reason"`
+}
+
+type FetchFollowSuggestionListResponse struct {
+	List []FollowSuggestion `json:"list"`
+}
+
+type FetchFollowSuggestionListRequest struct {
+	PageSize int `json:"page_size"`
+}
+
+// 推荐关注，有共同学员的教练，以及练过或者收藏过的训练计划的作者
+func (h *CoachHandler) FetchFollowSuggestionList(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body FetchFollowSuggestionListRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	limit := 20
+	if body.PageSize > 0 && body.PageSize < limit {
+		limit = body.PageSize
+	}
+	type candidate struct {
+		CoachId int
+		Count   int
+	}
+	scores := make(map[int]int)
+	reasons := make(map[int]string)
+	roles := []int{models.RoleCoachStudent, models.RoleCoachAndStudentHasAccount}
+	statuses := []int{models.RelationPending, models.RelationConfirmed}
+	my_students := h.db.WithContext(c).Model(&models.CoachRelationship{}).Select("student_id").
+		Where("coach_id = ? AND (d IS NULL OR d = 0) AND role IN ? AND status IN ?", uid, roles, statuses)
+	var shared_students []candidate
+	if err := h.db.WithContext(c).Model(&models.CoachRelationship{}).
+		Select("coach_id, COUNT(DISTINCT student_id) AS count").
+		Where("student_id IN (?) AND coach_id != ?", my_students, uid).
+		Where("(d IS NULL OR d = 0) AND role IN ? AND status IN ?", roles, statuses).
+		Group("coach_id").
+		Scan(&shared_students).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	for _, v := range shared_students {
+		// 共同学员比计划兴趣更能说明关系
+		scores[v.CoachId] += v.Count * 2
+		reasons[v.CoachId] = "shared_students"
+	}
+	// 练过的和收藏过的计划
+	played_ids := h.db.WithContext(c).Model(&models.WorkoutDay{}).Select("workout_plan_id").Where("student_id = ?", uid)
+	favorite_ids := h.db.WithContext(c).Model(&models.UserFavorite{}).Select("content_id").
+		Where("coach_id = ? AND content_type = ? AND d = 0", uid, models.FavoriteContentTypeWorkoutPlan)
+	var plan_owners []candidate
+	if err := h.db.WithContext(c).Model(&models.WorkoutPlan{}).
+		Select("owner_id AS coach_id, COUNT(DISTINCT id) AS count").
+		Where("status = ? AND (d IS NULL OR d = 0) AND owner_id != ?", int(models.WorkoutPublishStatusPublic), uid).
+		Where("id IN (?) OR id IN (?)", played_ids, favorite_ids).
+		Group("owner_id").
+		Scan(&plan_owners).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	for _, v := range plan_owners {
+		scores[v.CoachId] += v.Count
+		if _, ok := reasons[v.CoachId]; !ok {
+			reasons[v.CoachId] = "plan_interest"
+		}
+	}
+	ids := make([]int, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	// 排除已经关注的和任意一方拉黑的
+	following, err := models.FetchFollowingIds(h.db.WithContext(c), uid, ids)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	var blocked []int
+	if err := h.db.WithContext(c).Model(&models.CoachBlock{}).Where("blocker_id = ?", uid).Pluck("blocked_id", &blocked).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	var blocked_by []int
+	if err := h.db.WithContext(c).Model(&models.CoachBlock{}).Where("blocked_id = ?", uid).Pluck("blocker_id", &blocked_by).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	ids = lo.Filter(ids, func(id int, _ int) bool {
+		return !following[id] && !lo.Contains(blocked, id) && !lo.Contains(blocked_by, id)
+	})
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	var coaches []models.Coach
+	if len(ids) != 0 {
+		if err := h.db.WithContext(c).Where("id IN (?)", ids).Preload("Profile1").Find(&coaches).Error; err != nil {
+			response.Fail(c, err)
+			return
+		}
+	}
+	coach_map := lo.KeyBy(coaches, func(v models.Coach) int { return v.Id })
+	list := make([]FollowSuggestion, 0, len(ids))
+	for _, id := range ids {
+		coach, ok := coach_map[id]
+		if !ok {
+			continue
+		}
+		list = append(list, FollowSuggestion{
+			CoachBrief: newCoachBrief(coach.Id, coach.Profile1),
+			Reason:     reasons[id],
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": FetchFollowSuggestionListResponse{List: list}})
+}
+
+type FetchCoachProfileInWechatResponse struct {
+	Id             int                              `json:"id"`
+	UID            string                           `json:"uid"`
+	Nickname       string                           `json:"nickname"`
+	AvatarURL      string                           `json:"avatar_url"`
+	Accounts       []models.CoachMediaSocialAccount `json:"accounts"`
+	FollowerCount  int64                            `json:"follower_count"`
+	FollowingCount int64                            `json:"following_count"`
+	IsFollowing    bool                             `json:"is_following"`
+	IsFollowed     bool                             `json:"is_followed"`
+	IsMutual       bool                             `json:"is_mutual"`
+	IsBlocked      bool                             `json:"is_blocked"`
+}
+
+type FetchCoachProfileInWechatRequest struct {
+	UID string `json:"uid"`
+}
+
 func (h *CoachHandler) FetchCoachProfileInWechat(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 	if uid == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "请先登录", "data": nil})
+		response.Fail(c, errcode.ErrUnauthorized)
 		return
 	}
-	var body struct {
-		UID string `json:"uid"`
-	}
+	var body FetchCoachProfileInWechatRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.UID == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "缺少参数", "data": nil})
+		response.Fail(c, errcode.ErrMissingParam)
 		return
 	}
 	var coach models.Coach
-	if err := h.db.
+	if err := h.db.WithContext(c).
 		Where("nickname = ?", body.UID).
 		Preload("Profile1").
 		First(&coach).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "没有找到记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 	var platform_accounts []models.CoachMediaSocialAccount
-	if err := h.db.Where("coach_id = ?", coach.Id).Find(&platform_accounts).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Where("coach_id = ?", coach.Id).Find(&platform_accounts).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	follower_count, following_count, err := models.FetchFollowCount(h.db.WithContext(c), coach.Id)
+	if err != nil {
+		response.Fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "获取成功", "data": gin.H{
-		"id":         coach.Id,
-		"uid":        coach.Nickname,
-		"nickname":   coach.Profile1.Nickname,
-		"avatar_url": coach.Profile1.AvatarURL,
-		"accounts":   platform_accounts,
+	is_following, err := models.FetchFollowingIds(h.db.WithContext(c), uid, []int{coach.Id})
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	is_followed, err := models.FetchFollowerIds(h.db.WithContext(c), uid, []int{coach.Id})
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	var blocked_count int64
+	if err := models.BlockedCoachIds(h.db.WithContext(c), uid).Where("blocked_id = ?", coach.Id).Count(&blocked_count).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "获取成功", "data": FetchCoachProfileInWechatResponse{
+		Id:             coach.Id,
+		UID:            coach.Nickname,
+		Nickname:       coach.Profile1.Nickname,
+		AvatarURL:      coach.Profile1.AvatarURL,
+		Accounts:       platform_accounts,
+		FollowerCount:  follower_count,
+		FollowingCount: following_count,
+		IsFollowing:    is_following[coach.Id],
+		IsFollowed:     is_followed[coach.Id],
+		IsMutual:       is_following[coach.Id] && is_followed[coach.Id],
+		IsBlocked:      blocked_count > 0,
 	}})
 }
 
+type FetchCoachProfileInAdminRequest struct {
+	Id int `json:"id"`
+}
+
 func (h *CoachHandler) FetchCoachProfileInAdmin(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 	if uid != 1 {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "没有权限", "data": nil})
+		response.Fail(c, errcode.ErrForbidden)
 		return
 	}
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body FetchCoachProfileInAdminRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "缺少 id 参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
 	var coach models.Coach
-	if err := h.db.
+	if err := h.db.WithContext(c).
 		Where("id = ?", body.Id).
 		Preload("Profile1").
 		First(&coach).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "没有找到记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "获取成功", "data": gin.H{
-		"id":         coach.Id,
-		"nickname":   coach.Profile1.Nickname,
-		"avatar_url": coach.Profile1.AvatarURL,
-	}})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "获取成功", "data": newCoachBrief(coach.Id, coach.Profile1)})
+}
+
+type FetchCoachContentListRequest struct {
+	models.Pagination
 }
 
 func (h *CoachHandler) FetchCoachContentList(c *gin.Context) {
-	// uid := int(c.GetFloat64("id"))
-	var body struct {
-		models.Pagination
+	uid := int(c.GetFloat64("id"))
+	if uid != 1 {
+		response.Fail(c, errcode.ErrForbidden)
+		return
 	}
+	var body FetchCoachContentListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
-	query := h.db
+	query := h.db.WithContext(c)
 	pb := pagination.NewPaginationBuilder[models.CoachContent](query).
 		SetLimit(body.PageSize).
 		SetPage(body.Page).
 		SetOrderBy("created_at DESC")
 	var list1 []models.CoachContent
 	if err := pb.Build().Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch discount policies", "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
@@ -2405,11 +3883,6 @@ func (h *CoachHandler) FetchCoachContentList(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "",
-		"data": gin.H{
-			"list":        list2,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list2, has_more, next_marker),
 	})
 }
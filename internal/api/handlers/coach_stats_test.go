@@ -0,0 +1,236 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"myapi/config"
+	"myapi/internal/api/handlers"
+	"myapi/internal/db"
+	"myapi/internal/models"
+	"myapi/internal/pkg/loginguard"
+	"myapi/pkg/logger"
+)
+
+// 统计接口在每种数据库上都要得到同样的结果，日期按用户的时区划分
+// SQLite 总是会跑，Postgres 和 MySQL 设置了 TEST_POSTGRES_DSN / TEST_MYSQL_DSN 时才跑，例如
+//
+//	docker run -d -p 5432:5432 -e POSTGRES_PASSWORD=test postgres:16
+//	TEST_POSTGRES_DSN="host=127.0.0.1 user=postgres password=test dbname=postgres sslmode=disable TimeZone=UTC" go test ./internal/api/handlers/
+//
+//	docker run -d -p 3306:3306 -e MYSQL_ROOT_PASSWORD=test -e MYSQL_DATABASE=test mysql:8
+//	TEST_MYSQL_DSN="root:test@tcp(127.0.0.1:3306)/test?charset=utf8mb4&parseTime=True&loc=UTC" go test ./internal/api/handlers/
+func TestRefreshCoachStatsAcrossDatabases(t *testing.T) {
+	databases := []struct {
+		name string
+		open func(t *testing.T) *gorm.DB
+	}{
+		{"sqlite", openSQLite},
+		{"postgres", openFromEnv("TEST_POSTGRES_DSN", postgres.Open)},
+		{"mysql", openFromEnv("TEST_MYSQL_DSN", mysql.Open)},
+	}
+	for _, d := range databases {
+		t.Run(d.name, func(t *testing.T) {
+			database := d.open(t)
+			uid, ids := seedWorkoutDays(t, database)
+			data := refreshCoachStats(t, database, uid)
+
+			if got := data.Stats.TotalWorkoutTimes; got != 5 {
+				t.Errorf("total_workout_times = %d, want 5", got)
+			}
+			// 默认是东八区，3 月 1 日 23:30 开始的那次在 3 月 2 日完成，算在 3 月 2 日
+			if got := data.Stats.TotalWorkoutDays; got != 4 {
+				t.Errorf("total_workout_days = %d, want 4", got)
+			}
+			if data.MaxStreak != 3 || data.MaxStreakRange.Start != "2025-03-01" || data.MaxStreakRange.End != "2025-03-03" {
+				t.Errorf("max_streak = %d %+v, want 3 2025-03-01~2025-03-03", data.MaxStreak, data.MaxStreakRange)
+			}
+			if got := data.MaxDurationDay.Id; got != ids["longest"] {
+				t.Errorf("max_duration_day = %d, want %d", got, ids["longest"])
+			}
+			// 按东八区的小时比较，按 UTC 比较的话结果会不一样
+			if got := data.EarliestStartDay.Id; got != ids["morning"] {
+				t.Errorf("earliest_start_day = %d, want %d", got, ids["morning"])
+			}
+			if got := data.LatestFinishDay.Id; got != ids["evening"] {
+				t.Errorf("latest_finish_day = %d, want %d", got, ids["evening"])
+			}
+			if got := len(data.TypePlanMap["strength"]); got != 5 {
+				t.Errorf("workout days of type strength = %d, want 5", got)
+			}
+
+			// 换成 UTC 之后，同样的范围是 2-28 到 3-31，4 月 1 日凌晨那次算在 3-31
+			if err := database.Model(&models.Coach{}).Where("id = ?", uid).Update("timezone", "UTC").Error; err != nil {
+				t.Fatal(err)
+			}
+			data = refreshCoachStats(t, database, uid)
+			if got := data.Stats.TotalWorkoutTimes; got != 6 {
+				t.Errorf("utc total_workout_times = %d, want 6", got)
+			}
+			if got := data.Stats.TotalWorkoutDays; got != 4 {
+				t.Errorf("utc total_workout_days = %d, want 4", got)
+			}
+			if got := data.EarliestStartDay.Id; got != ids["day1"] {
+				t.Errorf("utc earliest_start_day = %d, want %d", got, ids["day1"])
+			}
+		})
+	}
+}
+
+func openSQLite(t *testing.T) *gorm.DB {
+	cfg := &config.Config{
+		DBType:         "sqlite",
+		DBPath:         filepath.Join(t.TempDir(), "test.db"),
+		MigrationsPath: "file://../../../migrations",
+		Environment:    "production",
+	}
+	l := logger.NewLogger("error")
+	if err := db.NewMigrator(cfg, l).MigrateUp(); err != nil {
+		t.Fatal(err)
+	}
+	database, err := db.NewDatabase(cfg, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return database
+}
+
+// 迁移文件是 SQLite 的语法，其他数据库按模型建表，每次先清掉旧表
+func openFromEnv(key string, open func(dsn string) gorm.Dialector) func(t *testing.T) *gorm.DB {
+	return func(t *testing.T) *gorm.DB {
+		dsn := os.Getenv(key)
+		if dsn == "" {
+			t.Skipf("%s is not set", key)
+		}
+		database, err := gorm.Open(open(dsn), &gorm.Config{
+			Logger:                                   gormlogger.Default.LogMode(gormlogger.Silent),
+			DisableForeignKeyConstraintWhenMigrating: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tables := []interface{}{&models.Coach{}, &models.WorkoutPlan{}, &models.WorkoutDay{}, &models.WorkoutAction{}, &models.WorkoutActionHistory{}}
+		if err := database.Migrator().DropTable(tables...); err != nil {
+			t.Fatal(err)
+		}
+		if err := database.AutoMigrate(tables...); err != nil {
+			t.Fatal(err)
+		}
+		return database
+	}
+}
+
+func seedWorkoutDays(t *testing.T, database *gorm.DB) (int, map[string]int) {
+	t.Helper()
+	coach := models.Coach{Nickname: "stats", Config: "{}"}
+	if err := database.Create(&coach).Error; err != nil {
+		t.Fatal(err)
+	}
+	plan := models.WorkoutPlan{Title: "plan", Type: "strength", OwnerId: coach.Id}
+	if err := database.Create(&plan).Error; err != nil {
+		t.Fatal(err)
+	}
+	// 时间都用 UTC 写入，注释里是东八区的时间
+	days := []struct {
+		title    string
+		started  time.Time
+		duration int
+	}{
+		// 3-01 10:00
+		{"day1", time.Date(2025, 3, 1, 2, 0, 0, 0, time.UTC), 60},
+		// 3-01 23:30 开始，3-02 00:30 完成，UTC 都在 3-01
+		{"midnight", time.Date(2025, 3, 1, 15, 30, 0, 0, time.UTC), 60},
+		// 3-02 06:00，UTC 是 3-01 22:00
+		{"morning", time.Date(2025, 3, 1, 22, 0, 0, 0, time.UTC), 45},
+		// 3-03 12:00
+		{"longest", time.Date(2025, 3, 3, 4, 0, 0, 0, time.UTC), 120},
+		// 3-10 20:00
+		{"evening", time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC), 30},
+		// 3-31 23:00 之后完成，按东八区算在 4 月，不在统计范围内
+		{"april", time.Date(2025, 3, 31, 16, 30, 0, 0, time.UTC), 20},
+	}
+	ids := map[string]int{}
+	for _, d := range days {
+		started := d.started
+		finished := d.started.Add(time.Duration(d.duration) * time.Minute)
+		day := models.WorkoutDay{
+			Title:         d.title,
+			Status:        2,
+			Duration:      d.duration,
+			StartedAt:     &started,
+			FinishedAt:    &finished,
+			CreatedAt:     started,
+			WorkoutPlanId: plan.Id,
+			StudentId:     coach.Id,
+		}
+		if err := database.Create(&day).Error; err != nil {
+			t.Fatal(err)
+		}
+		ids[d.title] = day.Id
+	}
+	return coach.Id, ids
+}
+
+type statsDay struct {
+	Id int `json:"id"`
+}
+
+type statsData struct {
+	Stats struct {
+		TotalWorkoutDays  int   `json:"total_workout_days"`
+		TotalWorkoutTimes int64 `json:"total_workout_times"`
+	} `json:"stats"`
+	MaxStreak      int `json:"max_streak"`
+	MaxStreakRange struct {
+		Start string `json:"start"`
+		End   string `json:"end"`
+	} `json:"max_streak_range"`
+	MaxDurationDay   statsDay `json:"max_duration_day"`
+	EarliestStartDay statsDay `json:"earliest_start_day"`
+	LatestFinishDay  statsDay `json:"latest_finish_day"`
+	TypePlanMap      map[string][]struct {
+		WorkoutDayId int `json:"workout_day_id"`
+	} `json:"type_plan_map"`
+}
+
+func refreshCoachStats(t *testing.T, database *gorm.DB, uid int) statsData {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	handler := handlers.NewCoachHandler(database, logger.NewLogger("error"), &config.Config{}, loginguard.NewGuard(loginguard.NewMemoryStore(), loginguard.DefaultAccountPolicy, loginguard.DefaultIPPolicy))
+	router := gin.New()
+	router.POST("/stats", func(c *gin.Context) {
+		c.Set("id", float64(uid))
+		handler.RefreshCoachStats(c)
+	})
+
+	body, _ := json.Marshal(gin.H{
+		"range_of_start": "2025-03-01T00:00:00+08:00",
+		"range_of_end":   "2025-03-31T23:59:59+08:00",
+	})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/stats", bytes.NewReader(body)))
+	var resp struct {
+		Code int       `json:"code"`
+		Msg  string    `json:"msg"`
+		Data statsData `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode %s: %v", w.Body.String(), err)
+	}
+	if resp.Code != 200 {
+		t.Fatalf("code = %d, msg = %s", resp.Code, resp.Msg)
+	}
+	return resp.Data
+}
@@ -7,7 +7,9 @@ import (
 	"gorm.io/gorm"
 
 	"myapi/internal/models"
+	"myapi/internal/pkg/errcode"
 	"myapi/internal/pkg/pagination"
+	"myapi/internal/pkg/response"
 	"myapi/pkg/logger"
 )
 
@@ -27,16 +29,18 @@ func NewEquipmentHandler(db *gorm.DB, logger *logger.Logger) *EquipmentHandler {
 	}
 }
 
+type FetchEquipmentListRequest struct {
+	models.Pagination
+	Ids []int `json:"ids"`
+}
+
 func (h *EquipmentHandler) FetchEquipmentList(c *gin.Context) {
-	var body struct {
-		models.Pagination
-		Ids []int `json:"ids"`
-	}
+	var body FetchEquipmentListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
-	query := h.db
+	query := h.db.WithContext(c)
 	if len(body.Ids) != 0 {
 		query = query.Where("id IN (?)", body.Ids)
 	}
@@ -46,50 +50,49 @@ func (h *EquipmentHandler) FetchEquipmentList(c *gin.Context) {
 		SetOrderBy("sort_idx DESC")
 	var list1 []models.Equipment
 	if err := pb.Build().Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch equipments" + err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "Success",
-		"data": gin.H{
-			"list":        list2,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list2, has_more, next_marker),
 	})
 }
 
+type FetchEquipmentRequest struct {
+	Id int `json:"id"`
+}
+
 func (h *EquipmentHandler) FetchEquipment(c *gin.Context) {
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body FetchEquipmentRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	var equipment models.Equipment
-	if err := h.db.First(&equipment, body.Id).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "没有找到记录", "data": nil})
+	if err := h.db.WithContext(c).First(&equipment, body.Id).Error; err != nil {
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "Success", "data": equipment})
 }
 
+type CreateEquipmentRequest struct {
+	ZhName   string `json:"zh_name"`
+	Name     string `json:"name"`
+	Alias    string `json:"alias"`
+	Overview string `json:"overview"`
+	Tags     string `json:"tags"`
+	SortIdx  int    `json:"sort_idx"`
+	Medias   string `json:"medias"`
+}
+
 func (h *EquipmentHandler) CreateEquipment(c *gin.Context) {
-	var body struct {
-		ZhName   string `json:"zh_name"`
-		Name     string `json:"name"`
-		Alias    string `json:"alias"`
-		Overview string `json:"overview"`
-		Tags     string `json:"tags"`
-		SortIdx  int    `json:"sort_idx"`
-		Medias   string `json:"medias"`
-	}
+	var body CreateEquipmentRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	record := models.Equipment{
@@ -101,31 +104,33 @@ func (h *EquipmentHandler) CreateEquipment(c *gin.Context) {
 		SortIdx:  body.SortIdx,
 		Medias:   body.Medias,
 	}
-	if err := h.db.Create(&record).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Create(&record).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": nil})
 }
 
+type UpdateEquipmentRequest struct {
+	Id       int    `json:"id"`
+	ZhName   string `json:"zh_name"`
+	Name     string `json:"name"`
+	Alias    string `json:"alias"`
+	Overview string `json:"overview"`
+	Tags     string `json:"tags"`
+	SortIdx  int    `json:"sort_idx"`
+	Medias   string `json:"medias"`
+}
+
 func (h *EquipmentHandler) UpdateEquipment(c *gin.Context) {
-	var body struct {
-		Id       int    `json:"id"`
-		ZhName   string `json:"zh_name"`
-		Name     string `json:"name"`
-		Alias    string `json:"alias"`
-		Overview string `json:"overview"`
-		Tags     string `json:"tags"`
-		SortIdx  int    `json:"sort_idx"`
-		Medias   string `json:"medias"`
-	}
+	var body UpdateEquipmentRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	var existing models.Equipment
-	if err := h.db.First(&existing, body.Id).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "没有找到记录", "data": nil})
+	if err := h.db.WithContext(c).First(&existing, body.Id).Error; err != nil {
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 	updates := map[string]interface{}{}
@@ -147,28 +152,30 @@ func (h *EquipmentHandler) UpdateEquipment(c *gin.Context) {
 	if body.Medias != "" {
 		updates["medias"] = body.Medias
 	}
-	if err := h.db.Model(&existing).Updates(&updates).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Model(&existing).Updates(&updates).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "更新成功", "data": nil})
 }
 
+type DeleteEquipmentRequest struct {
+	Id int `json:"id"`
+}
+
 func (h *EquipmentHandler) DeleteEquipment(c *gin.Context) {
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body DeleteEquipmentRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	var existing models.Equipment
-	if err := h.db.First(&existing, body.Id).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "没有找到记录", "data": nil})
+	if err := h.db.WithContext(c).First(&existing, body.Id).Error; err != nil {
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
-	if err := h.db.Model(&existing).Update("d", 1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Model(&existing).Update("d", 1).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "删除成功", "data": nil})
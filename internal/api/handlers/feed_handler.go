@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"myapi/config"
+	"myapi/internal/models"
+	"myapi/internal/pkg/cache"
+	"myapi/internal/pkg/errcode"
+	"myapi/internal/pkg/pagination"
+	"myapi/internal/pkg/response"
+	"myapi/pkg/logger"
+)
+
+// 动态来源，时间相同时按这个顺序排
+const (
+	FeedSourceCoachContent = 1
+	FeedSourceWorkoutPlan  = 2
+	FeedSourceWorkoutDay   = 3
+)
+
+// FeedHandler 关注的人的动态，读取时从各个来源合并，可选缓存
+type FeedHandler struct {
+	db     *gorm.DB
+	logger *logger.Logger
+	cache  cache.Cache
+	ttl    time.Duration
+}
+
+func NewFeedHandler(db *gorm.DB, logger *logger.Logger, cfg *config.Config) *FeedHandler {
+	h := &FeedHandler{
+		db:     db,
+		logger: logger,
+	}
+	if cfg.FeedCacheTTL > 0 {
+		h.cache = cache.NewMemoryCache()
+		h.ttl = time.Duration(cfg.FeedCacheTTL) * time.Second
+	}
+	return h
+}
+
+type feedItem struct {
+	cursor pagination.FeedCursor
+	data   FeedItem
+}
+
+// FeedItem 动态，按 type 只有对应的一个字段有值
+type FeedItem struct {
+	Type         string            `json:"type"`
+	Time         time.Time         `json:"time"`
+	Creator      CoachBrief        `json:"creator"`
+	CoachContent *FeedCoachContent `json:"coach_content,omitempty"`
+	WorkoutPlan  *FeedWorkoutPlan  `json:"workout_plan,omitempty"`
+	WorkoutDay   *FeedWorkoutDay   `json:"workout_day,omitempty"`
+}
+
+type FeedCoachContent struct {
+	Id           int    `json:"id"`
+	Title        string `json:"title"`
+	Overview     string `json:"overview"`
+	Type         int    `json:"type"`
+	VideoURL     string `json:"video_url"`
+	LikeCount    int    `json:"like_count"`
+	CommentCount int    `json:"comment_count"`
+}
+
+type FeedWorkoutPlan struct {
+	Id                int    `json:"id"`
+	Title             string `json:"title"`
+	Overview          string `json:"overview"`
+	Level             int    `json:"level"`
+	Tags              string `json:"tags"`
+	EstimatedDuration int    `json:"estimated_duration"`
+}
+
+type FeedWorkoutDay struct {
+	Id          int        `json:"id"`
+	Title       string     `json:"title"`
+	Type        string     `json:"type"`
+	Duration    int        `json:"duration"`
+	TotalVolume float64    `json:"total_volume"`
+	FinishedAt  *time.Time `json:"finished_at"`
+}
+
+type FetchFeedListRequest struct {
+	PageSize   int    `json:"page_size"`
+	NextMarker string `json:"next_marker"`
+}
+
+func (h *FeedHandler) FetchFeedList(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body FetchFeedListRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	cursor, err := pagination.ParseFeedCursor(body.NextMarker)
+	if err != nil {
+		response.Fail(c, errcode.ErrInvalidParam.Wrap(err))
+		return
+	}
+	key := fmt.Sprintf("feed:%d:%d:%s", uid, body.PageSize, body.NextMarker)
+	if h.cache != nil {
+		if v, ok := h.cache.Get(key); ok {
+			var data pagination.List[FeedItem]
+			if err := json.Unmarshal(v, &data); err == nil {
+				c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": data})
+				return
+			}
+		}
+	}
+	data, err := h.buildFeed(c, uid, body.PageSize, cursor)
+	if err != nil {
+		h.logger.WithContext(c).Error("Failed to fetch feed", err)
+		response.Fail(c, err)
+		return
+	}
+	if h.cache != nil {
+		if v, err := json.Marshal(data); err == nil {
+			h.cache.Set(key, v, h.ttl)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": data})
+}
+
+// buildFeed 每个来源各取一页，合并后再截取一页
+func (h *FeedHandler) buildFeed(c *gin.Context, uid int, page_size int, cursor *pagination.FeedCursor) (*pagination.List[FeedItem], error) {
+	now := time.Now()
+	following := h.db.WithContext(c).Model(&models.CoachFollow{}).Select("following_id").
+		Where("follower_id = ? AND status = ?", uid, models.FollowStatusFollowing).
+		Where("following_id NOT IN (?)", models.BlockedCoachIds(h.db.WithContext(c), uid))
+
+	query1 := h.db.WithContext(c).Where("d IS NULL OR d = 0").
+		Where("publish = 1 AND status = ? AND published_at <= ?", models.CoachContentStatusApproved, now).
+		Where("coach_id IN (?)", following)
+	query1 = cursor.Apply(query1, "published_at", FeedSourceCoachContent)
+	pb1 := pagination.NewPaginationBuilder[models.CoachContent](query1).
+		SetLimit(page_size).
+		SetOrderBy("published_at DESC, id DESC")
+	var contents []models.CoachContent
+	if err := pb1.Build().Preload("Coach.Profile1").Find(&contents).Error; err != nil {
+		return nil, err
+	}
+	contents, has_more1, _ := pb1.ProcessResults(contents)
+
+	query2 := h.db.WithContext(c).Where("d IS NULL OR d = 0").
+		Where("status = ?", int(models.WorkoutPublishStatusPublic)).
+		Where("owner_id IN (?)", following)
+	query2 = cursor.Apply(query2, "created_at", FeedSourceWorkoutPlan)
+	pb2 := pagination.NewPaginationBuilder[models.WorkoutPlan](query2).
+		SetLimit(page_size).
+		SetOrderBy("created_at DESC, id DESC")
+	var plans []models.WorkoutPlan
+	if err := pb2.Build().Preload("Creator.Profile1").Find(&plans).Error; err != nil {
+		return nil, err
+	}
+	plans, has_more2, _ := pb2.ProcessResults(plans)
+
+	query3 := h.db.WithContext(c).Where("d IS NULL OR d = 0").
+		Where("status = ? AND shared = 1", int(models.WorkoutDayStatusFinished)).
+		Where("student_id IN (?)", following)
+	query3 = cursor.Apply(query3, "shared_at", FeedSourceWorkoutDay)
+	pb3 := pagination.NewPaginationBuilder[models.WorkoutDay](query3).
+		SetLimit(page_size).
+		SetOrderBy("shared_at DESC, id DESC")
+	var days []models.WorkoutDay
+	if err := pb3.Build().Preload("Student.Profile1").Find(&days).Error; err != nil {
+		return nil, err
+	}
+	days, has_more3, _ := pb3.ProcessResults(days)
+
+	items := make([]feedItem, 0, len(contents)+len(plans)+len(days))
+	for _, v := range contents {
+		items = append(items, feedItem{
+			cursor: pagination.FeedCursor{Time: *v.PublishedAt, Source: FeedSourceCoachContent, Id: v.Id},
+			data: FeedItem{
+				Type:    "coach_content",
+				Time:    *v.PublishedAt,
+				Creator: newCoachBrief(v.CoachId, v.Coach.Profile1),
+				CoachContent: &FeedCoachContent{
+					Id:           v.Id,
+					Title:        v.Title,
+					Overview:     v.Description,
+					Type:         v.ContentType,
+					VideoURL:     v.VideoKey,
+					LikeCount:    v.LikeCount,
+					CommentCount: v.CommentCount,
+				},
+			},
+		})
+	}
+	for _, v := range plans {
+		items = append(items, feedItem{
+			cursor: pagination.FeedCursor{Time: v.CreatedAt, Source: FeedSourceWorkoutPlan, Id: v.Id},
+			data: FeedItem{
+				Type:    "workout_plan",
+				Time:    v.CreatedAt,
+				Creator: newCoachBrief(v.OwnerId, v.Creator.Profile1),
+				WorkoutPlan: &FeedWorkoutPlan{
+					Id:                v.Id,
+					Title:             v.Title,
+					Overview:          v.Overview,
+					Level:             v.Level,
+					Tags:              v.Tags,
+					EstimatedDuration: v.EstimatedDuration,
+				},
+			},
+		})
+	}
+	for _, v := range days {
+		items = append(items, feedItem{
+			cursor: pagination.FeedCursor{Time: *v.SharedAt, Source: FeedSourceWorkoutDay, Id: v.Id},
+			data: FeedItem{
+				Type:    "workout_day",
+				Time:    *v.SharedAt,
+				Creator: newCoachBrief(v.StudentId, v.Student.Profile1),
+				WorkoutDay: &FeedWorkoutDay{
+					Id:          v.Id,
+					Title:       v.Title,
+					Type:        v.Type,
+					Duration:    v.Duration,
+					TotalVolume: v.TotalVolume,
+					FinishedAt:  v.FinishedAt,
+				},
+			},
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].cursor.Before(items[j].cursor)
+	})
+
+	limit := pb1.GetLimit()
+	has_more := has_more1 || has_more2 || has_more3 || len(items) > limit
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	next_marker := ""
+	if has_more && len(items) != 0 {
+		next_marker = items[len(items)-1].cursor.String()
+	}
+	list := make([]FeedItem, 0, len(items))
+	for _, v := range items {
+		list = append(list, v.data)
+	}
+	return &pagination.List[FeedItem]{
+		List:       list,
+		PageSize:   limit,
+		HasMore:    has_more,
+		NextMarker: next_marker,
+	}, nil
+}
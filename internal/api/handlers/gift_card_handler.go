@@ -10,7 +10,10 @@ import (
 	"gorm.io/gorm"
 
 	"myapi/internal/models"
+	"myapi/internal/pkg/errcode"
+	"myapi/internal/pkg/metrics"
 	"myapi/internal/pkg/pagination"
+	"myapi/internal/pkg/response"
 	"myapi/pkg/logger"
 )
 
@@ -41,48 +44,47 @@ func NewGiftCardHandler(db *gorm.DB, logger *logger.Logger) *GiftCardHandler {
 	}
 }
 
+type FetchGiftCardListRequest struct {
+	models.Pagination
+}
+
 func (h *GiftCardHandler) FetchGiftCardList(c *gin.Context) {
-	var body struct {
-		models.Pagination
-	}
+	var body FetchGiftCardListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
-	query := h.db
+	query := h.db.WithContext(c)
 	pb := pagination.NewPaginationBuilder[models.GiftCard](query).
 		SetLimit(body.PageSize).
 		SetPage(body.Page).
 		SetOrderBy("created_at DESC")
 	var list1 []models.GiftCard
 	if err := pb.Build().Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch discount policies", "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "",
-		"data": gin.H{
-			"list":        list2,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list2, has_more, next_marker),
 	})
 }
 
+type FetchGiftCardRewardListRequest struct {
+	models.Pagination
+}
+
 func (h *GiftCardHandler) FetchGiftCardRewardList(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		models.Pagination
-	}
+	var body FetchGiftCardRewardListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
-	query := h.db
+	query := h.db.WithContext(c)
 	query = query.Where("d != 1 AND creator_id = ?", uid)
 	pb := pagination.NewPaginationBuilder[models.GiftCardReward](query).
 		SetLimit(body.PageSize).
@@ -90,53 +92,50 @@ func (h *GiftCardHandler) FetchGiftCardRewardList(c *gin.Context) {
 		SetOrderBy("created_at DESC")
 	var list1 []models.GiftCardReward
 	if err := pb.Build().Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "",
-		"data": gin.H{
-			"list":        list2,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list2, has_more, next_marker),
 	})
 }
 
+type CreateGiftCardRequest struct {
+	Num              int `json:"num"`
+	GiftCardRewardId int `json:"gift_card_reward_id"`
+}
+
 func (h *GiftCardHandler) CreateGiftCard(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		Num              int `json:"num"`
-		GiftCardRewardId int `json:"gift_card_reward_id"`
-	}
+	var body CreateGiftCardRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	// 验证参数
 	if body.GiftCardRewardId == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少参数", "data": nil})
+		response.Fail(c, errcode.ErrMissingParam)
 		return
 	}
 	if body.Num <= 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "数量必须大于0", "data": nil})
+		response.Fail(c, errcode.ErrInvalidAmount)
 		return
 	}
 	// 开始事务
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	if tx.Error != nil {
-		h.logger.Error("Failed to start transaction", tx.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+		h.logger.WithContext(c).Error("Failed to start transaction", tx.Error)
+		response.Fail(c, tx.Error)
 		return
 	}
 
@@ -155,16 +154,16 @@ func (h *GiftCardHandler) CreateGiftCard(c *gin.Context) {
 
 	if err := tx.Create(&records).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to create records", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to create records", "data": nil})
+		h.logger.WithContext(c).Error("Failed to create records", err)
+		response.Fail(c, err)
 		return
 	}
 
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to commit transaction", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -175,39 +174,41 @@ func (h *GiftCardHandler) CreateGiftCard(c *gin.Context) {
 	})
 }
 
+type CreateGiftCardRewardRequest struct {
+	Name     string `json:"name"`
+	Overview string `json:"overview"`
+	Details  string `json:"details"`
+}
+
 func (h *GiftCardHandler) CreateGiftCardReward(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		Name     string `json:"name"`
-		Overview string `json:"overview"`
-		Details  string `json:"details"`
-	}
+	var body CreateGiftCardRewardRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	// 验证参数
 	if body.Name == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少参数", "data": nil})
+		response.Fail(c, errcode.ErrMissingParam)
 		return
 	}
 	if body.Details == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少参数", "data": nil})
+		response.Fail(c, errcode.ErrMissingParam)
 		return
 	}
 	// @todo 验证 Details 的有效性
 	// 开始事务
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	if tx.Error != nil {
-		h.logger.Error("Failed to start transaction", tx.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+		h.logger.WithContext(c).Error("Failed to start transaction", tx.Error)
+		response.Fail(c, tx.Error)
 		return
 	}
 	now := time.Now()
@@ -221,16 +222,16 @@ func (h *GiftCardHandler) CreateGiftCardReward(c *gin.Context) {
 	}
 	if err := tx.Create(&record).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to create records", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to create records", "data": nil})
+		h.logger.WithContext(c).Error("Failed to create records", err)
+		response.Fail(c, err)
 		return
 	}
 
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to commit transaction", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -246,95 +247,104 @@ type GiftCardRewardDetailsJSON250607 struct {
 	DayCount           int `json:"day_count"`
 }
 
+type FetchGiftCardProfileRequest struct {
+	Code string `json:"code"`
+}
+
+type FetchGiftCardProfileResponse struct {
+	Name   string `json:"name"`
+	Status int    `json:"status"`
+}
+
 func (h *GiftCardHandler) FetchGiftCardProfile(c *gin.Context) {
-	var body struct {
-		Code string `json:"code"`
-	}
+	var body FetchGiftCardProfileRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 
 	var record models.GiftCard
-	if r := h.db.Where("code = ? AND d != 1", body.Code).Preload("GiftCardReward").First(&record); r.Error != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": r.Error.Error(), "data": nil})
+	if r := h.db.WithContext(c).Where("code = ? AND d != 1", body.Code).Preload("GiftCardReward").First(&record); r.Error != nil {
+		response.Fail(c, r.Error)
 		return
 	}
 	if record.GiftCardReward.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "异常数据", "data": nil})
+		response.Fail(c, errcode.ErrDataCorrupted)
 		return
 	}
 	var details GiftCardRewardDetailsJSON250607
 	if err := json.Unmarshal([]byte(record.GiftCardReward.Details), &details); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 600, "msg": "异常数据", "data": nil})
+		response.Fail(c, errcode.ErrDataCorrupted)
 		return
 	}
 	if details.SubscriptionPlanId == 0 || details.DayCount == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 600, "msg": "异常数据", "data": nil})
+		response.Fail(c, errcode.ErrDataCorrupted)
 		return
 	}
 	var subscription_plan models.SubscriptionPlan
-	if err := h.db.Where("id = ?", details.SubscriptionPlanId).First(&subscription_plan).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 600, "msg": "异常数据", "data": nil})
+	if err := h.db.WithContext(c).Where("id = ?", details.SubscriptionPlanId).First(&subscription_plan).Error; err != nil {
+		response.Fail(c, errcode.ErrDataCorrupted)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "Success",
-		"data": gin.H{
-			"name":   record.GiftCardReward.Name,
-			"status": record.Status,
+		"data": FetchGiftCardProfileResponse{
+			Name:   record.GiftCardReward.Name,
+			Status: record.Status,
 		},
 	})
 }
 
+type UsingGiftCardRequest struct {
+	Code string `json:"code"`
+}
+
 func (h *GiftCardHandler) UsingGiftCard(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Code string `json:"code"`
-	}
+	var body UsingGiftCardRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	if tx.Error != nil {
-		h.logger.Error("Failed to start transaction", tx.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+		h.logger.WithContext(c).Error("Failed to start transaction", tx.Error)
+		response.Fail(c, tx.Error)
 		return
 	}
 	var card models.GiftCard
 	if r := tx.Where("code = ? AND d != 1", body.Code).Preload("GiftCardReward").First(&card); r.Error != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": r.Error.Error(), "data": nil})
+		response.Fail(c, r.Error)
 		return
 	}
 	if card.Status == int(models.GiftCardStatusUsed) {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "兑换码已被使用", "data": nil})
+		response.Fail(c, errcode.ErrGiftCardUsed)
 		return
 	}
 	if card.GiftCardReward.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 600, "msg": "异常数据", "data": nil})
+		response.Fail(c, errcode.ErrDataCorrupted)
 		return
 	}
 	var details GiftCardRewardDetailsJSON250607
 	if err := json.Unmarshal([]byte(card.GiftCardReward.Details), &details); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 601, "msg": "异常数据", "data": nil})
+		response.Fail(c, errcode.ErrDataCorrupted)
 		return
 	}
 	if details.SubscriptionPlanId == 0 || details.DayCount == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 602, "msg": "异常数据", "data": nil})
+		response.Fail(c, errcode.ErrDataCorrupted)
 		return
 	}
 	var subscription_plan models.SubscriptionPlan
 	if err := tx.Where("id = ?", details.SubscriptionPlanId).First(&subscription_plan).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 603, "msg": "异常数据", "data": nil})
+		response.Fail(c, errcode.ErrDataCorrupted)
 		return
 	}
 	now := time.Now()
@@ -363,8 +373,8 @@ func (h *GiftCardHandler) UsingGiftCard(c *gin.Context) {
 
 	if err := tx.Create(&subscription).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("create subscription failed", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "兑换失败", "data": nil})
+		h.logger.WithContext(c).Error("create subscription failed", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -375,18 +385,19 @@ func (h *GiftCardHandler) UsingGiftCard(c *gin.Context) {
 	}
 	if err := tx.Model(&card).Updates(updates).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to update gift card", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		h.logger.WithContext(c).Error("Failed to update gift card", err)
+		response.Fail(c, err)
 		return
 	}
 
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to commit transaction", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
+	metrics.GiftCardsRedeemed.Inc()
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "兑换成功",
@@ -394,67 +405,69 @@ func (h *GiftCardHandler) UsingGiftCard(c *gin.Context) {
 	})
 }
 
+type SendGiftCardRequest struct {
+	Code      string `json:"code"`
+	ToCoachId int    `json:"to_coach_id"`
+}
+
 // 赠送礼品卡
 func (h *GiftCardHandler) SendGiftCard(c *gin.Context) {
 	// uid := int(c.GetFloat64("id"))
-	var body struct {
-		Code      string `json:"code"`
-		ToCoachId int    `json:"to_coach_id"`
-	}
+	var body SendGiftCardRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Code == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少 code 参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("code"))
 		return
 	}
 	if body.ToCoachId == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少 to_coach_id 参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("to_coach_id"))
 		return
 	}
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	if tx.Error != nil {
-		h.logger.Error("Failed to start transaction", tx.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+		h.logger.WithContext(c).Error("Failed to start transaction", tx.Error)
+		response.Fail(c, tx.Error)
 		return
 	}
 	var existing_card models.GiftCard
 	if r := tx.Where("d IS NULL OR d = 0").Where("code = ?", body.Code).Preload("GiftCardReward").First(&existing_card); r.Error != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": r.Error.Error(), "data": nil})
+		response.Fail(c, r.Error)
 		return
 	}
 	var existing_coach models.Coach
 	if r := tx.Where("d IS NULL OR d = 0").Where("id = ?", body.ToCoachId).First(&existing_coach); r.Error != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": r.Error.Error(), "data": nil})
+		response.Fail(c, r.Error)
 		return
 	}
 	if existing_card.Status == int(models.GiftCardStatusUsed) {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "兑换码已被使用", "data": nil})
+		response.Fail(c, errcode.ErrGiftCardUsed)
 		return
 	}
 	if existing_card.GiftCardReward.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 600, "msg": "异常数据", "data": nil})
+		response.Fail(c, errcode.ErrDataCorrupted)
 		return
 	}
 	var details GiftCardRewardDetailsJSON250607
 	if err := json.Unmarshal([]byte(existing_card.GiftCardReward.Details), &details); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 601, "msg": "异常数据", "data": nil})
+		response.Fail(c, errcode.ErrDataCorrupted)
 		return
 	}
 	if details.SubscriptionPlanId == 0 || details.DayCount == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 602, "msg": "异常数据", "data": nil})
+		response.Fail(c, errcode.ErrDataCorrupted)
 		return
 	}
 	var subscription_plan models.SubscriptionPlan
 	if err := tx.Where("id = ?", details.SubscriptionPlanId).First(&subscription_plan).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 603, "msg": "异常数据", "data": nil})
+		response.Fail(c, errcode.ErrDataCorrupted)
 		return
 	}
 	now := time.Now()
@@ -483,8 +496,8 @@ func (h *GiftCardHandler) SendGiftCard(c *gin.Context) {
 
 	if err := tx.Create(&subscription).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("create subscription failed", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "兑换失败", "data": nil})
+		h.logger.WithContext(c).Error("create subscription failed", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -495,16 +508,16 @@ func (h *GiftCardHandler) SendGiftCard(c *gin.Context) {
 	}
 	if err := tx.Model(&existing_card).Updates(updates).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to update gift card", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		h.logger.WithContext(c).Error("Failed to update gift card", err)
+		response.Fail(c, err)
 		return
 	}
 
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to commit transaction", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"myapi/config"
+	migration "myapi/internal/db"
+	"myapi/pkg/logger"
+)
+
+type HealthHandler struct {
+	db     *gorm.DB
+	logger *logger.Logger
+	config *config.Config
+
+	// 迁移文件运行期间不会变，只读一次
+	latest_once    sync.Once
+	latest_version uint
+	latest_err     error
+}
+
+func NewHealthHandler(db *gorm.DB, logger *logger.Logger, config *config.Config) *HealthHandler {
+	return &HealthHandler{
+		db:     db,
+		logger: logger,
+		config: config,
+	}
+}
+
+// 存活检查，进程能响应就返回成功，不检查依赖，避免数据库故障时服务被反复重启
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+	})
+}
+
+// 就绪检查，数据库能连上并且迁移到了最新版本才接收流量
+func (h *HealthHandler) Ready(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+	defer cancel()
+
+	checks := gin.H{}
+	ready := true
+	if err := h.checkDatabase(ctx); err != nil {
+		checks["database"] = err.Error()
+		ready = false
+	} else {
+		checks["database"] = "ok"
+	}
+	if err := h.checkMigrations(ctx); err != nil {
+		checks["migrations"] = err.Error()
+		ready = false
+	} else {
+		checks["migrations"] = "ok"
+	}
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": "unavailable",
+			"checks": checks,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+		"checks": checks,
+	})
+}
+
+func (h *HealthHandler) checkDatabase(ctx context.Context) error {
+	if h.db == nil {
+		return fmt.Errorf("database is not configured")
+	}
+	sql_db, err := h.db.DB()
+	if err != nil {
+		return err
+	}
+	return sql_db.PingContext(ctx)
+}
+
+func (h *HealthHandler) checkMigrations(ctx context.Context) error {
+	if h.db == nil {
+		return fmt.Errorf("database is not configured")
+	}
+	h.latest_once.Do(func() {
+		h.latest_version, h.latest_err = migration.LatestVersion(h.config.MigrationsPath)
+	})
+	if h.latest_err != nil {
+		return h.latest_err
+	}
+	version, dirty, err := migration.SchemaVersion(h.db.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", version)
+	}
+	if version != h.latest_version {
+		return fmt.Errorf("migration version is %d, want %d", version, h.latest_version)
+	}
+	return nil
+}
@@ -0,0 +1,837 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"myapi/internal/models"
+	"myapi/internal/pkg/errcode"
+	"myapi/internal/pkg/moderation"
+	"myapi/internal/pkg/pagination"
+	"myapi/internal/pkg/response"
+	"myapi/pkg/logger"
+)
+
+// InteractionHandler 点赞、评论、收藏
+type InteractionHandler struct {
+	db        *gorm.DB
+	logger    *logger.Logger
+	moderator moderation.Moderator
+}
+
+func NewInteractionHandler(db *gorm.DB, logger *logger.Logger) *InteractionHandler {
+	return &InteractionHandler{
+		db:        db,
+		logger:    logger,
+		moderator: moderation.NewSensitiveWordModerator(),
+	}
+}
+
+// findVisibleContent 查找 uid 能看到的内容，已发布的公开内容或者自己的内容
+func findVisibleContent(db *gorm.DB, id int, uid int) (*models.CoachContent, error) {
+	var existing models.CoachContent
+	if err := db.Where("d IS NULL OR d = 0").Where("id = ?", id).First(&existing).Error; err != nil {
+		return nil, err
+	}
+	if existing.CoachId != uid && (existing.Publish != 1 || !existing.IsPublished(time.Now())) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &existing, nil
+}
+
+// LikeContentResponse 点赞和取消点赞之后的状态
+type LikeContentResponse struct {
+	LikeCount int  `json:"like_count"`
+	IsLiked   bool `json:"is_liked"`
+}
+
+type LikeContentRequest struct {
+	Id int `json:"id"`
+}
+
+func (h *InteractionHandler) LikeContent(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body LikeContentRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if body.Id == 0 {
+		response.Fail(c, errcode.MissingParam("id"))
+		return
+	}
+	tx := h.db.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			response.Fail(c, errcode.ErrInternal)
+		}
+	}()
+	existing, err := findVisibleContent(tx, body.Id, uid)
+	if err != nil {
+		tx.Rollback()
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+		response.Fail(c, errcode.ErrNotFound)
+		return
+	}
+	like := models.CoachContentLike{
+		CoachContentId: existing.Id,
+		CoachId:        uid,
+		CreatedAt:      time.Now(),
+	}
+	// 已经点过赞的不会重复插入，计数也就不会重复增加
+	r := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&like)
+	if r.Error != nil {
+		tx.Rollback()
+		response.Fail(c, r.Error)
+		return
+	}
+	if r.RowsAffected == 1 {
+		if err := tx.Model(&models.CoachContent{}).Where("id = ?", existing.Id).
+			Update("like_count", gorm.Expr("like_count + 1")).Error; err != nil {
+			tx.Rollback()
+			response.Fail(c, err)
+			return
+		}
+	}
+	var like_count int
+	if err := tx.Model(&models.CoachContent{}).Where("id = ?", existing.Id).Pluck("like_count", &like_count).Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "点赞成功", "data": LikeContentResponse{
+		LikeCount: like_count,
+		IsLiked:   true,
+	}})
+}
+
+type UnlikeContentRequest struct {
+	Id int `json:"id"`
+}
+
+func (h *InteractionHandler) UnlikeContent(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body UnlikeContentRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if body.Id == 0 {
+		response.Fail(c, errcode.MissingParam("id"))
+		return
+	}
+	tx := h.db.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			response.Fail(c, errcode.ErrInternal)
+		}
+	}()
+	r := tx.Where("coach_content_id = ? AND coach_id = ?", body.Id, uid).Delete(&models.CoachContentLike{})
+	if r.Error != nil {
+		tx.Rollback()
+		response.Fail(c, r.Error)
+		return
+	}
+	if r.RowsAffected == 1 {
+		if err := tx.Model(&models.CoachContent{}).Where("id = ? AND like_count > 0", body.Id).
+			Update("like_count", gorm.Expr("like_count - 1")).Error; err != nil {
+			tx.Rollback()
+			response.Fail(c, err)
+			return
+		}
+	}
+	var like_count int
+	if err := tx.Model(&models.CoachContent{}).Where("id = ?", body.Id).Pluck("like_count", &like_count).Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "取消点赞成功", "data": LikeContentResponse{
+		LikeCount: like_count,
+		IsLiked:   false,
+	}})
+}
+
+type CreateCommentRequest struct {
+	ContentId int    `json:"content_id"`
+	Content   string `json:"content" binding:"max=500"`
+	ParentId  int    `json:"parent_id"`
+}
+
+type CreateCommentResponse struct {
+	Id     int `json:"id"`
+	Status int `json:"status"`
+}
+
+func (h *InteractionHandler) CreateComment(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body CreateCommentRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
+		return
+	}
+	if body.ContentId == 0 {
+		response.Fail(c, errcode.MissingParam("content_id"))
+		return
+	}
+	if body.Content == "" {
+		response.Fail(c, errcode.ErrCommentRequired)
+		return
+	}
+	tx := h.db.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			response.Fail(c, errcode.ErrInternal)
+		}
+	}()
+	existing, err := findVisibleContent(tx, body.ContentId, uid)
+	if err != nil {
+		tx.Rollback()
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+		response.Fail(c, errcode.ErrNotFound)
+		return
+	}
+	now := time.Now()
+	comment := models.CoachContentComment{
+		Status:         models.CommentStatusNormal,
+		Content:        body.Content,
+		CoachContentId: existing.Id,
+		CoachId:        uid,
+		CreatedAt:      now,
+	}
+	if body.ParentId != 0 {
+		var parent models.CoachContentComment
+		if err := tx.Where("d = 0 AND status = ? AND coach_content_id = ? AND id = ?", models.CommentStatusNormal, existing.Id, body.ParentId).First(&parent).Error; err != nil {
+			tx.Rollback()
+			if err != gorm.ErrRecordNotFound {
+				response.Fail(c, err)
+				return
+			}
+			response.Fail(c, errcode.ErrCommentNotFound)
+			return
+		}
+		comment.ParentId = parent.Id
+		comment.ReplyToId = parent.CoachId
+		comment.RootId = parent.RootId
+		if parent.RootId == 0 {
+			comment.RootId = parent.Id
+		}
+	}
+	result := h.moderator.Moderate(body.Content)
+	switch result.Verdict {
+	case moderation.VerdictBlock:
+		tx.Rollback()
+		response.Fail(c, errcode.ErrSensitiveContent)
+		return
+	case moderation.VerdictReview:
+		comment.Status = models.CommentStatusPending
+	}
+	if err := tx.Create(&comment).Error; err != nil {
+		tx.Rollback()
+		h.logger.WithContext(c).Error("Failed to create comment", err)
+		response.Fail(c, err)
+		return
+	}
+	if comment.Status == models.CommentStatusNormal {
+		if err := applyCommentCount(tx, comment, 1); err != nil {
+			tx.Rollback()
+			response.Fail(c, err)
+			return
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	msg := "评论成功"
+	if comment.Status == models.CommentStatusPending {
+		msg = "评论已提交，审核通过后展示"
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": msg, "data": CreateCommentResponse{
+		Id:     comment.Id,
+		Status: comment.Status,
+	}})
+}
+
+// applyCommentCount 评论变为可见或不可见时，同步内容的评论数和一级评论的回复数
+func applyCommentCount(tx *gorm.DB, comment models.CoachContentComment, delta int) error {
+	expr := gorm.Expr("comment_count + ?", delta)
+	query := tx.Model(&models.CoachContent{}).Where("id = ?", comment.CoachContentId)
+	if delta < 0 {
+		expr = gorm.Expr("comment_count - ?", -delta)
+		query = query.Where("comment_count >= ?", -delta)
+	}
+	if err := query.Update("comment_count", expr).Error; err != nil {
+		return err
+	}
+	if comment.RootId == 0 {
+		return nil
+	}
+	expr = gorm.Expr("reply_count + ?", delta)
+	query = tx.Model(&models.CoachContentComment{}).Where("id = ?", comment.RootId)
+	if delta < 0 {
+		expr = gorm.Expr("reply_count - ?", -delta)
+		query = query.Where("reply_count >= ?", -delta)
+	}
+	return query.Update("reply_count", expr).Error
+}
+
+type FetchCommentListRequest struct {
+	models.Pagination
+	ContentId int `json:"content_id"`
+	RootId    int `json:"root_id"`
+}
+
+type CommentItem struct {
+	Id         int        `json:"id"`
+	Content    string     `json:"content"`
+	Status     int        `json:"status"`
+	ReplyCount int        `json:"reply_count"`
+	RootId     int        `json:"root_id"`
+	ParentId   int        `json:"parent_id"`
+	Creator    CoachBrief `json:"creator"`
+	IsAuthor   bool       `json:"is_author"`
+	CreatedAt  time.Time  `json:"created_at"`
+	// 回复别人的评论时才有
+	ReplyTo *CommentReplyTo `json:"reply_to,omitempty"`
+}
+
+type CommentReplyTo struct {
+	Id       int    `json:"id"`
+	Nickname string `json:"nickname"`
+}
+
+func (h *InteractionHandler) FetchCommentList(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body FetchCommentListRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if body.ContentId == 0 {
+		response.Fail(c, errcode.MissingParam("content_id"))
+		return
+	}
+	existing, err := findVisibleContent(h.db.WithContext(c), body.ContentId, uid)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+		response.Fail(c, errcode.ErrNotFound)
+		return
+	}
+	query := h.db.WithContext(c).Where("d = 0 AND coach_content_id = ? AND root_id = ?", existing.Id, body.RootId)
+	// 待审核的评论只有自己能看到
+	query = query.Where("status = ? OR (status = ? AND coach_id = ?)", models.CommentStatusNormal, models.CommentStatusPending, uid)
+	query = query.Where("coach_id NOT IN (?)", models.BlockedCoachIds(h.db.WithContext(c), uid))
+	order := "created_at DESC"
+	if body.RootId != 0 {
+		order = "created_at ASC"
+	}
+	pb := pagination.NewPaginationBuilder[models.CoachContentComment](query).
+		SetLimit(body.PageSize).
+		SetPage(body.Page).
+		SetOrderBy(order)
+	var list1 []models.CoachContentComment
+	if err := pb.Build().Preload("Coach.Profile1").Preload("ReplyTo.Profile1").Find(&list1).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	list2, has_more, next_marker := pb.ProcessResults(list1)
+	list := make([]CommentItem, 0, len(list2))
+	for _, v := range list2 {
+		data := CommentItem{
+			Id:         v.Id,
+			Content:    v.Content,
+			Status:     v.Status,
+			ReplyCount: v.ReplyCount,
+			RootId:     v.RootId,
+			ParentId:   v.ParentId,
+			Creator:    newCoachBrief(v.CoachId, v.Coach.Profile1),
+			IsAuthor:   v.CoachId == uid,
+			CreatedAt:  v.CreatedAt,
+		}
+		if v.ReplyToId != 0 {
+			data.ReplyTo = &CommentReplyTo{
+				Id:       v.ReplyToId,
+				Nickname: v.ReplyTo.Profile1.Nickname,
+			}
+		}
+		list = append(list, data)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "",
+		"data": pagination.NewList(pb, list, has_more, next_marker),
+	})
+}
+
+type DeleteCommentRequest struct {
+	Id int `json:"id"`
+}
+
+// 评论人和内容作者都可以删除评论
+func (h *InteractionHandler) DeleteComment(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body DeleteCommentRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if body.Id == 0 {
+		response.Fail(c, errcode.MissingParam("id"))
+		return
+	}
+	tx := h.db.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			response.Fail(c, errcode.ErrInternal)
+		}
+	}()
+	var existing models.CoachContentComment
+	if err := tx.Where("d = 0 AND id = ?", body.Id).First(&existing).Error; err != nil {
+		tx.Rollback()
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+		response.Fail(c, errcode.ErrNotFound)
+		return
+	}
+	if existing.CoachId != uid {
+		var content models.CoachContent
+		if err := tx.Where("id = ?", existing.CoachContentId).First(&content).Error; err != nil || content.CoachId != uid {
+			tx.Rollback()
+			response.Fail(c, errcode.ErrForbidden)
+			return
+		}
+	}
+	if err := tx.Model(&existing).Update("d", 1).Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	// 一级评论删除后，下面的回复一起删除，之后再删除或审核回复时不会重复扣减计数
+	delta := -existing.ReplyCount
+	if existing.Status == models.CommentStatusNormal {
+		delta -= 1
+	}
+	if existing.RootId == 0 {
+		if err := tx.Model(&models.CoachContentComment{}).Where("d = 0 AND root_id = ?", existing.Id).Update("d", 1).Error; err != nil {
+			tx.Rollback()
+			response.Fail(c, err)
+			return
+		}
+	}
+	if delta != 0 {
+		if err := applyCommentCount(tx, existing, delta); err != nil {
+			tx.Rollback()
+			response.Fail(c, err)
+			return
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "删除成功", "data": nil})
+}
+
+type FetchPendingCommentListRequest struct {
+	models.Pagination
+}
+
+// 管理后台 待审核的评论
+type PendingCommentItem struct {
+	Id        int        `json:"id"`
+	Content   string     `json:"content"`
+	ContentId int        `json:"content_id"`
+	Reason    string     `json:"reason"`
+	Creator   CoachBrief `json:"creator"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (h *InteractionHandler) FetchPendingCommentList(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	if uid != 1 {
+		response.Fail(c, errcode.ErrForbidden)
+		return
+	}
+	var body FetchPendingCommentListRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	query := h.db.WithContext(c).Where("d = 0 AND status = ?", models.CommentStatusPending)
+	pb := pagination.NewPaginationBuilder[models.CoachContentComment](query).
+		SetLimit(body.PageSize).
+		SetPage(body.Page).
+		SetOrderBy("created_at ASC")
+	var list1 []models.CoachContentComment
+	if err := pb.Build().Preload("Coach.Profile1").Find(&list1).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	list2, has_more, next_marker := pb.ProcessResults(list1)
+	list := make([]PendingCommentItem, 0, len(list2))
+	for _, v := range list2 {
+		list = append(list, PendingCommentItem{
+			Id:        v.Id,
+			Content:   v.Content,
+			ContentId: v.CoachContentId,
+			Reason:    h.moderator.Moderate(v.Content).Reason,
+			Creator:   newCoachBrief(v.CoachId, v.Coach.Profile1),
+			CreatedAt: v.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "",
+		"data": pagination.NewList(pb, list, has_more, next_marker),
+	})
+}
+
+type ReviewCommentRequest struct {
+	Id       int  `json:"id"`
+	Approved bool `json:"approved"`
+}
+
+// 管理后台 审核评论，通过后展示，不通过则屏蔽。已经展示的评论也可以屏蔽
+func (h *InteractionHandler) ReviewComment(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	if uid != 1 {
+		response.Fail(c, errcode.ErrForbidden)
+		return
+	}
+	var body ReviewCommentRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if body.Id == 0 {
+		response.Fail(c, errcode.MissingParam("id"))
+		return
+	}
+	tx := h.db.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			response.Fail(c, errcode.ErrInternal)
+		}
+	}()
+	var existing models.CoachContentComment
+	if err := tx.Where("d = 0 AND id = ?", body.Id).First(&existing).Error; err != nil {
+		tx.Rollback()
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+		response.Fail(c, errcode.ErrNotFound)
+		return
+	}
+	status := models.CommentStatusBlocked
+	if body.Approved {
+		status = models.CommentStatusNormal
+	}
+	if status != existing.Status {
+		updates := map[string]interface{}{"status": status}
+		delta := 0
+		if status == models.CommentStatusNormal {
+			delta = 1
+		} else if existing.Status == models.CommentStatusNormal {
+			delta = -1
+		}
+		// 一级评论屏蔽后，下面的回复一起屏蔽，计数里一起扣掉。之后重新通过时回复不恢复，需要单独审核
+		if status == models.CommentStatusBlocked && existing.RootId == 0 {
+			if err := tx.Model(&models.CoachContentComment{}).
+				Where("d = 0 AND root_id = ? AND status != ?", existing.Id, models.CommentStatusBlocked).
+				Update("status", models.CommentStatusBlocked).Error; err != nil {
+				tx.Rollback()
+				response.Fail(c, err)
+				return
+			}
+			delta -= existing.ReplyCount
+			updates["reply_count"] = 0
+		}
+		if err := tx.Model(&existing).Updates(updates).Error; err != nil {
+			tx.Rollback()
+			response.Fail(c, err)
+			return
+		}
+		if delta != 0 {
+			if err := applyCommentCount(tx, existing, delta); err != nil {
+				tx.Rollback()
+				response.Fail(c, err)
+				return
+			}
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "操作成功", "data": nil})
+}
+
+// checkFavoriteTarget 只能收藏自己能看到的内容或训练计划
+func checkFavoriteTarget(db *gorm.DB, content_type string, content_id int, uid int) error {
+	switch content_type {
+	case models.FavoriteContentTypeCoachContent:
+		_, err := findVisibleContent(db, content_id, uid)
+		return err
+	case models.FavoriteContentTypeWorkoutPlan:
+		var plan models.WorkoutPlan
+		if err := db.Where("d IS NULL OR d = 0").Where("id = ?", content_id).First(&plan).Error; err != nil {
+			return err
+		}
+		if plan.Status != int(models.WorkoutPublishStatusPublic) && plan.OwnerId != uid {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	default:
+		return gorm.ErrRecordNotFound
+	}
+}
+
+type FavoriteResponse struct {
+	IsFavorited bool `json:"is_favorited"`
+}
+
+type CreateFavoriteRequest struct {
+	ContentType string `json:"content_type"`
+	ContentId   int    `json:"content_id"`
+	FolderId    int    `json:"folder_id"`
+}
+
+func (h *InteractionHandler) CreateFavorite(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body CreateFavoriteRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if body.ContentType == "" || body.ContentId == 0 {
+		response.Fail(c, errcode.ErrMissingParam)
+		return
+	}
+	if err := checkFavoriteTarget(h.db.WithContext(c), body.ContentType, body.ContentId, uid); err != nil {
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+		response.Fail(c, errcode.ErrNotFound)
+		return
+	}
+	if body.FolderId != 0 {
+		var count int64
+		if err := h.db.WithContext(c).Table("USER_FAVORITE_FOLDER").Where("d = 0 AND id = ? AND coach_id = ?", body.FolderId, uid).Count(&count).Error; err != nil {
+			response.Fail(c, err)
+			return
+		}
+		if count == 0 {
+			response.Fail(c, errcode.ErrFolderNotFound)
+			return
+		}
+	}
+	now := time.Now()
+	record := models.UserFavorite{
+		ContentType: body.ContentType,
+		ContentId:   body.ContentId,
+		FolderId:    body.FolderId,
+		CoachId:     uid,
+		CreatedAt:   now,
+	}
+	// 重复收藏只会更新收藏夹，取消过的会恢复
+	if err := h.db.WithContext(c).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "coach_id"}, {Name: "content_type"}, {Name: "content_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"d":          0,
+			"folder_id":  body.FolderId,
+			"created_at": now,
+		}),
+	}).Create(&record).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "收藏成功", "data": FavoriteResponse{IsFavorited: true}})
+}
+
+type DeleteFavoriteRequest struct {
+	ContentType string `json:"content_type"`
+	ContentId   int    `json:"content_id"`
+}
+
+func (h *InteractionHandler) DeleteFavorite(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body DeleteFavoriteRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if body.ContentType == "" || body.ContentId == 0 {
+		response.Fail(c, errcode.ErrMissingParam)
+		return
+	}
+	if err := h.db.WithContext(c).Model(&models.UserFavorite{}).
+		Where("coach_id = ? AND content_type = ? AND content_id = ?", uid, body.ContentType, body.ContentId).
+		Update("d", 1).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "取消收藏成功", "data": FavoriteResponse{IsFavorited: false}})
+}
+
+type FetchFavoriteListRequest struct {
+	models.Pagination
+	ContentType string `json:"content_type"`
+	FolderId    int    `json:"folder_id"`
+}
+
+type FavoriteItem struct {
+	Id          int            `json:"id"`
+	ContentType string         `json:"content_type"`
+	FolderId    int            `json:"folder_id"`
+	Content     FavoriteTarget `json:"content"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// FavoriteTarget 收藏的内容或训练计划，按 content_type 只有对应的字段有值，找不到的只有 id 和 invalid
+type FavoriteTarget struct {
+	Id       int    `json:"id"`
+	Title    string `json:"title"`
+	Overview string `json:"overview"`
+	// 内容
+	Type         int    `json:"type"`
+	VideoURL     string `json:"video_url"`
+	LikeCount    int    `json:"like_count"`
+	CommentCount int    `json:"comment_count"`
+	// 训练计划
+	Level             int    `json:"level"`
+	Tags              string `json:"tags"`
+	EstimatedDuration int    `json:"estimated_duration"`
+
+	Creator *CoachBrief `json:"creator,omitempty"`
+	Invalid bool        `json:"invalid"`
+}
+
+func (h *InteractionHandler) FetchFavoriteList(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body FetchFavoriteListRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if body.ContentType == "" {
+		response.Fail(c, errcode.ErrMissingParam)
+		return
+	}
+	query := h.db.WithContext(c).Where("d = 0 AND coach_id = ? AND content_type = ?", uid, body.ContentType)
+	if body.FolderId != 0 {
+		query = query.Where("folder_id = ?", body.FolderId)
+	}
+	pb := pagination.NewPaginationBuilder[models.UserFavorite](query).
+		SetLimit(body.PageSize).
+		SetPage(body.Page).
+		SetOrderBy("created_at DESC")
+	var list1 []models.UserFavorite
+	if err := pb.Build().Find(&list1).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	list2, has_more, next_marker := pb.ProcessResults(list1)
+	ids := make([]int, 0, len(list2))
+	for _, v := range list2 {
+		ids = append(ids, v.ContentId)
+	}
+	// 被删除或者不再公开的也保留在收藏里，只是标记为失效
+	targets := make(map[int]FavoriteTarget)
+	switch body.ContentType {
+	case models.FavoriteContentTypeCoachContent:
+		var contents []models.CoachContent
+		if err := h.db.WithContext(c).Where("id IN (?)", ids).Preload("Coach.Profile1").Find(&contents).Error; err != nil {
+			response.Fail(c, err)
+			return
+		}
+		now := time.Now()
+		for _, v := range contents {
+			creator := newCoachBrief(v.CoachId, v.Coach.Profile1)
+			targets[v.Id] = FavoriteTarget{
+				Id:           v.Id,
+				Title:        v.Title,
+				Overview:     v.Description,
+				Type:         v.ContentType,
+				VideoURL:     v.VideoKey,
+				LikeCount:    v.LikeCount,
+				CommentCount: v.CommentCount,
+				Creator:      &creator,
+				Invalid:      v.D == 1 || (v.CoachId != uid && (v.Publish != 1 || !v.IsPublished(now))),
+			}
+		}
+	case models.FavoriteContentTypeWorkoutPlan:
+		var plans []models.WorkoutPlan
+		if err := h.db.WithContext(c).Where("id IN (?)", ids).Preload("Creator.Profile1").Find(&plans).Error; err != nil {
+			response.Fail(c, err)
+			return
+		}
+		for _, v := range plans {
+			creator := newCoachBrief(v.OwnerId, v.Creator.Profile1)
+			targets[v.Id] = FavoriteTarget{
+				Id:                v.Id,
+				Title:             v.Title,
+				Overview:          v.Overview,
+				Level:             v.Level,
+				Tags:              v.Tags,
+				EstimatedDuration: v.EstimatedDuration,
+				Creator:           &creator,
+				Invalid:           v.D == 1 || (v.OwnerId != uid && v.Status != int(models.WorkoutPublishStatusPublic)),
+			}
+		}
+	}
+	list := make([]FavoriteItem, 0, len(list2))
+	for _, v := range list2 {
+		target, ok := targets[v.ContentId]
+		if !ok {
+			target = FavoriteTarget{Id: v.ContentId, Invalid: true}
+		}
+		list = append(list, FavoriteItem{
+			Id:          v.Id,
+			ContentType: v.ContentType,
+			FolderId:    v.FolderId,
+			Content:     target,
+			CreatedAt:   v.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "",
+		"data": pagination.NewList(pb, list, has_more, next_marker),
+	})
+}
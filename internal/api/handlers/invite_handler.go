@@ -0,0 +1,570 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"myapi/config"
+	"myapi/internal/models"
+	"myapi/internal/pkg/errcode"
+	"myapi/internal/pkg/pagination"
+	"myapi/internal/pkg/response"
+	"myapi/pkg/logger"
+)
+
+// InviteHandler 邀请学员以及师生关系的确认、拒绝和解除
+type InviteHandler struct {
+	db     *gorm.DB
+	logger *logger.Logger
+	config *config.Config
+}
+
+func NewInviteHandler(db *gorm.DB, logger *logger.Logger, cfg *config.Config) *InviteHandler {
+	return &InviteHandler{
+		db:     db,
+		logger: logger,
+		config: cfg,
+	}
+}
+
+// 邀请默认 3 天有效，最长 30 天
+const (
+	DefaultInviteExpiresIn = 72
+	MaxInviteExpiresIn     = 24 * 30
+)
+
+func (h *InviteHandler) buildInviteURL(code string) string {
+	return h.config.MobileURL("/invite?code=" + code)
+}
+
+type CreateInviteRequest struct {
+	StudentId int `json:"student_id"`
+	ExpiresIn int `json:"expires_in"` // 单位小时
+}
+
+type CreateInviteResponse struct {
+	Id        int       `json:"id"`
+	Code      string    `json:"code"`
+	URL       string    `json:"url"`
+	QRCode    string    `json:"qrcode"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+func (h *InviteHandler) CreateInvite(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body CreateInviteRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	expires_in := body.ExpiresIn
+	if expires_in <= 0 {
+		expires_in = DefaultInviteExpiresIn
+	}
+	if expires_in > MaxInviteExpiresIn {
+		expires_in = MaxInviteExpiresIn
+	}
+	// 只有自己创建的、还没有关联账号的学员才能邀请关联
+	if body.StudentId != 0 {
+		var relation models.CoachRelationship
+		if err := h.db.WithContext(c).Where("d IS NULL OR d = 0").
+			Where("coach_id = ? AND student_id = ? AND role = ?", uid, body.StudentId, models.RoleCoachStudent).
+			Where("status IN (?)", []int{models.RelationPending, models.RelationConfirmed}).
+			First(&relation).Error; err != nil {
+			if err != gorm.ErrRecordNotFound {
+				response.Fail(c, err)
+				return
+			}
+			response.Fail(c, errcode.ErrStudentNotFound)
+			return
+		}
+	}
+	now := time.Now()
+	record := models.CoachInvite{
+		Code:      strings.ToUpper(strings.ReplaceAll(uuid.New().String(), "-", "")[:8]),
+		Status:    models.InviteStatusPending,
+		CoachId:   uid,
+		StudentId: body.StudentId,
+		ExpiredAt: now.Add(time.Duration(expires_in) * time.Hour),
+		CreatedAt: now,
+	}
+	if err := h.db.WithContext(c).Create(&record).Error; err != nil {
+		h.logger.WithContext(c).Error("Failed to create invite", err)
+		response.Fail(c, err)
+		return
+	}
+	url := h.buildInviteURL(record.Code)
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": CreateInviteResponse{
+		Id:        record.Id,
+		Code:      record.Code,
+		URL:       url,
+		QRCode:    url,
+		ExpiredAt: record.ExpiredAt,
+	}})
+}
+
+type FetchInviteListRequest struct {
+	models.Pagination
+	StudentId int `json:"student_id"`
+}
+
+type InviteItem struct {
+	Id        int        `json:"id"`
+	Code      string     `json:"code"`
+	URL       string     `json:"url"`
+	Status    int        `json:"status"`
+	Expired   bool       `json:"expired"`
+	InviteeId int        `json:"invitee_id"`
+	ExpiredAt time.Time  `json:"expired_at"`
+	HandledAt *time.Time `json:"handled_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	// 邀请关联已有学员时才有
+	Student *CoachBrief `json:"student,omitempty"`
+}
+
+func (h *InviteHandler) FetchInviteList(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body FetchInviteListRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	query := h.db.WithContext(c).Where("coach_id = ?", uid)
+	if body.StudentId != 0 {
+		query = query.Where("student_id = ?", body.StudentId)
+	}
+	pb := pagination.NewPaginationBuilder[models.CoachInvite](query).
+		SetLimit(body.PageSize).
+		SetPage(body.Page).
+		SetOrderBy("created_at DESC")
+	var list1 []models.CoachInvite
+	if err := pb.Build().Preload("Student.Profile1").Find(&list1).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	list2, has_more, next_marker := pb.ProcessResults(list1)
+	now := time.Now()
+	list := make([]InviteItem, 0, len(list2))
+	for _, v := range list2 {
+		data := InviteItem{
+			Id:        v.Id,
+			Code:      v.Code,
+			URL:       h.buildInviteURL(v.Code),
+			Status:    v.Status,
+			Expired:   v.Status == models.InviteStatusPending && !now.Before(v.ExpiredAt),
+			InviteeId: v.InviteeId,
+			ExpiredAt: v.ExpiredAt,
+			HandledAt: v.HandledAt,
+			CreatedAt: v.CreatedAt,
+		}
+		if v.StudentId != 0 {
+			student := newCoachBrief(v.StudentId, v.Student.Profile1)
+			data.Student = &student
+		}
+		list = append(list, data)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"msg":  "",
+		"data": pagination.NewList(pb, list, has_more, next_marker),
+	})
+}
+
+type RevokeInviteRequest struct {
+	Id int `json:"id"`
+}
+
+func (h *InviteHandler) RevokeInvite(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body RevokeInviteRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	r := h.db.WithContext(c).Model(&models.CoachInvite{}).
+		Where("id = ? AND coach_id = ? AND status = ?", body.Id, uid, models.InviteStatusPending).
+		Update("status", models.InviteStatusRevoked)
+	if r.Error != nil {
+		response.Fail(c, r.Error)
+		return
+	}
+	if r.RowsAffected == 0 {
+		response.Fail(c, errcode.ErrNotFound)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "撤销成功", "data": nil})
+}
+
+type FetchInviteProfileRequest struct {
+	Code string `json:"code"`
+}
+
+type FetchInviteProfileResponse struct {
+	Code      string     `json:"code"`
+	Status    int        `json:"status"`
+	Available bool       `json:"available"`
+	Coach     CoachBrief `json:"coach"`
+	ExpiredAt time.Time  `json:"expired_at"`
+}
+
+// 受邀人打开邀请时查看
+func (h *InviteHandler) FetchInviteProfile(c *gin.Context) {
+	var body FetchInviteProfileRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	var existing models.CoachInvite
+	if err := h.db.WithContext(c).Where("code = ?", strings.ToUpper(body.Code)).Preload("Coach.Profile1").First(&existing).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+		response.Fail(c, errcode.ErrInviteNotFound)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": FetchInviteProfileResponse{
+		Code:      existing.Code,
+		Status:    existing.Status,
+		Available: existing.IsAvailable(time.Now()),
+		Coach:     newCoachBrief(existing.CoachId, existing.Coach.Profile1),
+		ExpiredAt: existing.ExpiredAt,
+	}})
+}
+
+// linkStudentHistory 把教练创建的学员的训练记录、考试记录转到受邀人账号下
+func linkStudentHistory(tx *gorm.DB, from int, to int) error {
+	if err := tx.Model(&models.WorkoutDay{}).Where("student_id = ?", from).Update("student_id", to).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.WorkoutDay{}).Where("coach_id = ?", from).Update("coach_id", to).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.WorkoutActionHistory{}).Where("student_id = ?", from).Update("student_id", to).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Exam{}).Where("student_id = ?", from).Update("student_id", to).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.QuizAnswer{}).Where("student_id = ?", from).Update("student_id", to).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+type AcceptInviteResponse struct {
+	CoachId int `json:"coach_id"`
+}
+
+type AcceptInviteRequest struct {
+	Code string `json:"code"`
+}
+
+func (h *InviteHandler) AcceptInvite(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body AcceptInviteRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	tx := h.db.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			response.Fail(c, errcode.ErrInternal)
+		}
+	}()
+	now := time.Now()
+	var invite models.CoachInvite
+	if err := tx.Where("code = ?", strings.ToUpper(body.Code)).First(&invite).Error; err != nil {
+		tx.Rollback()
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+		response.Fail(c, errcode.ErrInviteNotFound)
+		return
+	}
+	if !invite.IsAvailable(now) {
+		tx.Rollback()
+		response.Fail(c, errcode.ErrInviteInvalid)
+		return
+	}
+	if invite.CoachId == uid || invite.StudentId == uid {
+		tx.Rollback()
+		response.Fail(c, errcode.ErrInviteSelf)
+		return
+	}
+	blocked, err := models.IsBlocked(tx, invite.CoachId, uid)
+	if err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	if blocked {
+		tx.Rollback()
+		response.Fail(c, errcode.ErrInviteUnacceptable)
+		return
+	}
+	var existing models.CoachRelationship
+	has_existing := true
+	if err := tx.Where("d IS NULL OR d = 0").
+		Where("coach_id = ? AND student_id = ? OR coach_id = ? AND student_id = ?", invite.CoachId, uid, uid, invite.CoachId).
+		First(&existing).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			tx.Rollback()
+			response.Fail(c, err)
+			return
+		}
+		has_existing = false
+	}
+	active := has_existing && (existing.Status == models.RelationPending || existing.Status == models.RelationConfirmed)
+	if active && (existing.Role != models.RoleCoachAndStudentHasAccount || existing.CoachId != invite.CoachId || existing.Status == models.RelationConfirmed || invite.StudentId != 0) {
+		tx.Rollback()
+		response.Fail(c, errcode.ErrRelationshipExists)
+		return
+	}
+	if invite.StudentId != 0 {
+		var placeholder models.CoachRelationship
+		if err := tx.Where("d IS NULL OR d = 0").
+			Where("coach_id = ? AND student_id = ? AND role = ?", invite.CoachId, invite.StudentId, models.RoleCoachStudent).
+			First(&placeholder).Error; err != nil {
+			tx.Rollback()
+			if err != gorm.ErrRecordNotFound {
+				response.Fail(c, err)
+				return
+			}
+			response.Fail(c, errcode.ErrInviteInvalid)
+			return
+		}
+		if err := linkStudentHistory(tx, invite.StudentId, uid); err != nil {
+			tx.Rollback()
+			response.Fail(c, err)
+			return
+		}
+		// 之前拒绝或解除的关系不再保留，统一用教练创建学员时的那条
+		if has_existing {
+			if err := tx.Model(&existing).Update("d", 1).Error; err != nil {
+				tx.Rollback()
+				response.Fail(c, err)
+				return
+			}
+		}
+		if err := tx.Model(&placeholder).Updates(map[string]interface{}{
+			"student_id": uid,
+			"role":       models.RoleCoachAndStudentHasAccount,
+			"status":     models.RelationConfirmed,
+			"updated_at": now,
+		}).Error; err != nil {
+			tx.Rollback()
+			response.Fail(c, err)
+			return
+		}
+		if err := tx.Model(&models.Coach{}).Where("id = ?", invite.StudentId).Update("d", 1).Error; err != nil {
+			tx.Rollback()
+			response.Fail(c, err)
+			return
+		}
+	} else if has_existing {
+		if err := tx.Model(&existing).Updates(map[string]interface{}{
+			"coach_id":   invite.CoachId,
+			"student_id": uid,
+			"role":       models.RoleCoachAndStudentHasAccount,
+			"status":     models.RelationConfirmed,
+			"updated_at": now,
+		}).Error; err != nil {
+			tx.Rollback()
+			response.Fail(c, err)
+			return
+		}
+	} else {
+		created := models.CoachRelationship{
+			CoachId:   invite.CoachId,
+			StudentId: uid,
+			Role:      models.RoleCoachAndStudentHasAccount,
+			Status:    models.RelationConfirmed,
+			CreatedAt: now,
+		}
+		if err := tx.Create(&created).Error; err != nil {
+			tx.Rollback()
+			response.Fail(c, err)
+			return
+		}
+	}
+	// 同时接受同一个邀请时只有一个能成功，另一个回滚之前的修改
+	r := tx.Model(&invite).Where("status = ?", models.InviteStatusPending).Updates(map[string]interface{}{
+		"status":     models.InviteStatusAccepted,
+		"invitee_id": uid,
+		"handled_at": now,
+	})
+	if r.Error != nil {
+		tx.Rollback()
+		response.Fail(c, r.Error)
+		return
+	}
+	if r.RowsAffected == 0 {
+		tx.Rollback()
+		response.Fail(c, errcode.ErrInviteInvalid)
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "已接受邀请", "data": AcceptInviteResponse{CoachId: invite.CoachId}})
+}
+
+type RejectInviteRequest struct {
+	Code string `json:"code"`
+}
+
+func (h *InviteHandler) RejectInvite(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body RejectInviteRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	now := time.Now()
+	var invite models.CoachInvite
+	if err := h.db.WithContext(c).Where("code = ?", strings.ToUpper(body.Code)).First(&invite).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+		response.Fail(c, errcode.ErrInviteNotFound)
+		return
+	}
+	if !invite.IsAvailable(now) {
+		response.Fail(c, errcode.ErrInviteInvalid)
+		return
+	}
+	if invite.CoachId == uid {
+		response.Fail(c, errcode.ErrInviteSelf)
+		return
+	}
+	// 和接受邀请同时发生时，只有还在等待处理的邀请才能拒绝
+	r := h.db.WithContext(c).Model(&invite).Where("status = ?", models.InviteStatusPending).Updates(map[string]interface{}{
+		"status":     models.InviteStatusRejected,
+		"invitee_id": uid,
+		"handled_at": now,
+	})
+	if r.Error != nil {
+		response.Fail(c, r.Error)
+		return
+	}
+	if r.RowsAffected == 0 {
+		response.Fail(c, errcode.ErrInviteInvalid)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "已拒绝邀请", "data": nil})
+}
+
+// findPendingRelation 找到对方发给我、还没有处理的关系
+func findPendingRelation(db *gorm.DB, from int, uid int) (*models.CoachRelationship, error) {
+	var existing models.CoachRelationship
+	if err := db.Where("d IS NULL OR d = 0").
+		Where("coach_id = ? AND student_id = ? AND status = ?", from, uid, models.RelationPending).
+		First(&existing).Error; err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+type AcceptRelationshipRequest struct {
+	Id int `json:"id"`
+}
+
+// 确认对方发起的关系，比如好友申请
+func (h *InviteHandler) AcceptRelationship(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body AcceptRelationshipRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	existing, err := findPendingRelation(h.db.WithContext(c), body.Id, uid)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+		response.Fail(c, errcode.ErrNotFound)
+		return
+	}
+	if err := h.db.WithContext(c).Model(existing).Updates(map[string]interface{}{
+		"status":     models.RelationConfirmed,
+		"updated_at": time.Now(),
+	}).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "操作成功", "data": nil})
+}
+
+type RejectRelationshipRequest struct {
+	Id int `json:"id"`
+}
+
+func (h *InviteHandler) RejectRelationship(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body RejectRelationshipRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	existing, err := findPendingRelation(h.db.WithContext(c), body.Id, uid)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+		response.Fail(c, errcode.ErrNotFound)
+		return
+	}
+	if err := h.db.WithContext(c).Model(existing).Updates(map[string]interface{}{
+		"status":     models.RelationRejected,
+		"updated_at": time.Now(),
+	}).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "操作成功", "data": nil})
+}
+
+type DismissRelationshipRequest struct {
+	Id int `json:"id"`
+}
+
+// 任意一方都可以解除关系，训练记录保留
+func (h *InviteHandler) DismissRelationship(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body DismissRelationshipRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	r := h.db.WithContext(c).Model(&models.CoachRelationship{}).
+		Where("d IS NULL OR d = 0").
+		Where("coach_id = ? AND student_id = ? OR coach_id = ? AND student_id = ?", uid, body.Id, body.Id, uid).
+		Where("status IN (?)", []int{models.RelationPending, models.RelationConfirmed}).
+		Updates(map[string]interface{}{
+			"status":     models.RelationDismissed,
+			"updated_at": time.Now(),
+		})
+	if r.Error != nil {
+		response.Fail(c, r.Error)
+		return
+	}
+	if r.RowsAffected == 0 {
+		response.Fail(c, errcode.ErrNotFound)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "解除成功", "data": nil})
+}
@@ -12,7 +12,9 @@ import (
 
 	"myapi/config"
 	"myapi/internal/models"
+	"myapi/internal/pkg/errcode"
 	"myapi/internal/pkg/pagination"
+	"myapi/internal/pkg/response"
 	"myapi/pkg/logger"
 )
 
@@ -30,6 +32,10 @@ func NewMediaResourceHandler(db *gorm.DB, logger *logger.Logger, config *config.
 	}
 }
 
+type QiniuTokenResponse struct {
+	Token string `json:"token"`
+}
+
 // qiniu.region.z0: 代表华东区域
 // qiniu.region.z1: 代表华北区域
 // qiniu.region.z2: 代表华南区域
@@ -42,75 +48,74 @@ func (h *MediaResourceHandler) BuildQiniuToken(c *gin.Context) {
 	mac := credentials.NewCredentials(access_key, secret_key)
 	putPolicy, err := uptoken.NewPutPolicy(bucket, time.Now().Add(1*time.Hour))
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	token, err := uptoken.NewSigner(putPolicy, mac).GetUpToken(context.Background())
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": gin.H{
-		"token": token,
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": QiniuTokenResponse{
+		Token: token,
 	}})
 }
 
+type FetchMediaResourceListRequest struct {
+	models.Pagination
+}
+
 func (h *MediaResourceHandler) FetchMediaResourceList(c *gin.Context) {
-	var body struct {
-		models.Pagination
-	}
+	var body FetchMediaResourceListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
-	query := h.db
+	query := h.db.WithContext(c)
 	pb := pagination.NewPaginationBuilder[models.MediaResource](query).
 		SetLimit(body.PageSize).
 		SetPage(body.Page).
 		SetOrderBy("created_at DESC")
 	var list1 []models.MediaResource
 	if err := pb.Build().Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch discount policies", "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "",
-		"data": gin.H{
-			"list":        list2,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list2, has_more, next_marker),
 	})
 }
 
+type CreateMediaResourceRequest struct {
+	Type int `json:"type"`
+	// 文件信息
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Duration int    `json:"duration"`
+	Size     int    `json:"size"`
+	Filetype string `json:"filetype"`
+	Filename string `json:"filename"`
+	Hash     string `json:"hash"`
+	Key      string `json:"key"`
+}
+
 func (h *MediaResourceHandler) CreateMediaResource(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		Type int `json:"type"`
-		// 文件信息
-		Width    int    `json:"width"`
-		Height   int    `json:"height"`
-		Duration int    `json:"duration"`
-		Size     int    `json:"size"`
-		Filetype string `json:"filetype"`
-		Filename string `json:"filename"`
-		Hash     string `json:"hash"`
-		Key      string `json:"key"`
-	}
+	var body CreateMediaResourceRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Type == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少 type", "data": nil})
+		response.Fail(c, errcode.MissingParam("type"))
 		return
 	}
 	record := models.MediaResource{
-		URL:       "//static.fithub.top/" + body.Key,
+		URL:       h.config.MediaURL(body.Key),
 		MediaType: body.Type,
 		Width:     body.Width,
 		Height:    body.Height,
@@ -128,28 +133,28 @@ func (h *MediaResourceHandler) CreateMediaResource(c *gin.Context) {
 		record.Duration = body.Duration
 	}
 	// 开始事务
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	if tx.Error != nil {
-		h.logger.Error("Failed to start transaction", tx.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+		h.logger.WithContext(c).Error("Failed to start transaction", tx.Error)
+		response.Fail(c, tx.Error)
 		return
 	}
 	if err := tx.Create(&record).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to create record", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to create record", "data": nil})
+		h.logger.WithContext(c).Error("Failed to create record", err)
+		response.Fail(c, err)
 		return
 	}
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to commit transaction", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -160,21 +165,23 @@ func (h *MediaResourceHandler) CreateMediaResource(c *gin.Context) {
 	})
 }
 
+type DeleteMediaResourceRequest struct {
+	Id int `json:"id"`
+}
+
 func (h *MediaResourceHandler) DeleteMediaResource(c *gin.Context) {
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body DeleteMediaResourceRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	var existing models.MediaResource
-	if err := h.db.Where("id = ?", body.Id).First(&existing).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Where("id = ?", body.Id).First(&existing).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
-	if err := h.db.Delete(&existing).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Delete(&existing).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "删除成功", "data": nil})
@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"myapi/config"
+	"myapi/internal/models"
+	"myapi/internal/pkg/errcode"
+	"myapi/internal/pkg/loginguard"
+	"myapi/internal/pkg/response"
+	"myapi/pkg/logger"
+	"myapi/pkg/totp"
+)
+
+const TOTPIssuer = "FitHub"
+
+// MFAHandler 两步验证
+type MFAHandler struct {
+	db     *gorm.DB
+	logger *logger.Logger
+	config *config.Config
+	guard  *loginguard.Guard
+}
+
+func NewMFAHandler(db *gorm.DB, logger *logger.Logger, cfg *config.Config, guard *loginguard.Guard) *MFAHandler {
+	return &MFAHandler{
+		db:     db,
+		logger: logger,
+		config: cfg,
+		guard:  guard,
+	}
+}
+
+// createLoginSession 开启了两步验证时只返回临时凭证，需要再调用 /auth/2fa/verify
+func createLoginSession(c *gin.Context, db *gorm.DB, coach_id int, secret_key string) (*models.AuthResponse, error) {
+	enabled, err := models.IsTOTPEnabled(db, coach_id)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return models.CreateCoachSession(db, coach_id, c.GetHeader("User-Agent"), c.ClientIP(), secret_key)
+	}
+	token, expires_at, err := models.GenerateMFAChallenge(coach_id, secret_key)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AuthResponse{
+		MFARequired:    true,
+		ChallengeToken: token,
+		ExpiresAt:      expires_at.Unix(),
+	}, nil
+}
+
+type FetchMFAStatusResponse struct {
+	Enabled            bool       `json:"enabled"`
+	EnabledAt          *time.Time `json:"enabled_at"`
+	RecoveryCodesLeft  int64      `json:"recovery_codes_left"`
+	SessionMFAVerified bool       `json:"session_mfa_verified"`
+}
+
+// 两步验证的状态
+func (h *MFAHandler) FetchMFAStatus(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var record models.CoachTOTP
+	if err := h.db.WithContext(c).Where("coach_id = ? AND status = ?", uid, models.TOTPStatusEnabled).First(&record).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+	}
+	var count int64
+	if err := h.db.WithContext(c).Model(&models.CoachRecoveryCode{}).Where("coach_id = ? AND used_at IS NULL", uid).Count(&count).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	var session models.CoachSession
+	if err := h.db.WithContext(c).Where("session_id = ?", c.GetString("session_id")).First(&session).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": FetchMFAStatusResponse{
+		Enabled:            record.Id != 0,
+		EnabledAt:          record.EnabledAt,
+		RecoveryCodesLeft:  count,
+		SessionMFAVerified: session.MfaVerified == 1,
+	}})
+}
+
+type EnrollTOTPResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// 开始开启两步验证，返回密钥和二维码内容，调用 enable 确认后才生效
+func (h *MFAHandler) EnrollTOTP(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	if c.GetFloat64("actor_id") != 0 {
+		response.Fail(c, errcode.ErrForbidden)
+		return
+	}
+	var existing models.CoachTOTP
+	if err := h.db.WithContext(c).Where("coach_id = ?", uid).First(&existing).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+	}
+	if existing.Status == models.TOTPStatusEnabled {
+		response.Fail(c, errcode.ErrTOTPEnabled)
+		return
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if existing.Id != 0 {
+		if err := h.db.WithContext(c).Model(&existing).Updates(map[string]interface{}{"secret": secret, "last_used_step": 0}).Error; err != nil {
+			response.Fail(c, err)
+			return
+		}
+	} else {
+		record := models.CoachTOTP{
+			Secret:    secret,
+			Status:    models.TOTPStatusPending,
+			CoachId:   uid,
+			CreatedAt: time.Now(),
+		}
+		if err := h.db.WithContext(c).Create(&record).Error; err != nil {
+			response.Fail(c, err)
+			return
+		}
+	}
+	// 验证器里显示邮箱，没有邮箱时显示 uid
+	name := fmt.Sprintf("%d", uid)
+	var account models.CoachAccount
+	if err := h.db.WithContext(c).Where("coach_id = ? AND provider_type = ?", uid, models.AccountProviderTypeEmailWithPwd).First(&account).Error; err == nil {
+		name = account.ProviderId
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": EnrollTOTPResponse{
+		Secret: secret,
+		URI:    totp.ProvisioningURI(TOTPIssuer, name, secret),
+	}})
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type EnableTOTPRequest struct {
+	Code string `json:"code"`
+}
+
+// 输入验证器里的验证码确认开启，返回恢复码，当前会话视为已通过两步验证
+func (h *MFAHandler) EnableTOTP(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body EnableTOTPRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if c.GetFloat64("actor_id") != 0 {
+		response.Fail(c, errcode.ErrForbidden)
+		return
+	}
+	var record models.CoachTOTP
+	if err := h.db.WithContext(c).Where("coach_id = ? AND status = ?", uid, models.TOTPStatusPending).First(&record).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+		response.Fail(c, errcode.ErrTOTPNotEnrolled)
+		return
+	}
+	ok, err := models.CheckTOTP(h.db.WithContext(c), &record, body.Code)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if !ok {
+		response.Fail(c, errcode.ErrVerificationCodeInvalid)
+		return
+	}
+	tx := h.db.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			h.logger.WithContext(c).Errorw("Recovered from panic", "panic", r, "stack", string(debug.Stack()))
+			tx.Rollback()
+			response.Fail(c, errcode.ErrInternal)
+		}
+	}()
+	if err := tx.Model(&record).Updates(map[string]interface{}{
+		"status":     models.TOTPStatusEnabled,
+		"enabled_at": time.Now(),
+	}).Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	codes, err := models.GenerateRecoveryCodes(tx, uid)
+	if err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	if err := tx.Model(&models.CoachSession{}).
+		Where("session_id = ? AND coach_id = ?", c.GetString("session_id"), uid).
+		Update("mfa_verified", 1).Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "开启成功", "data": RecoveryCodesResponse{RecoveryCodes: codes}})
+}
+
+type DisableTOTPRequest struct {
+	Code string `json:"code"`
+}
+
+// 关闭两步验证，需要验证码或者恢复码
+func (h *MFAHandler) DisableTOTP(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body DisableTOTPRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if c.GetFloat64("actor_id") != 0 {
+		response.Fail(c, errcode.ErrForbidden)
+		return
+	}
+	if !h.checkCode(c, uid, body.Code) {
+		return
+	}
+	tx := h.db.WithContext(c).Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			h.logger.WithContext(c).Errorw("Recovered from panic", "panic", r, "stack", string(debug.Stack()))
+			tx.Rollback()
+			response.Fail(c, errcode.ErrInternal)
+		}
+	}()
+	if err := tx.Where("coach_id = ?", uid).Delete(&models.CoachTOTP{}).Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	if err := tx.Where("coach_id = ?", uid).Delete(&models.CoachRecoveryCode{}).Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "已关闭两步验证", "data": nil})
+}
+
+type RegenerateRecoveryCodesRequest struct {
+	Code string `json:"code"`
+}
+
+// 重新生成恢复码
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body RegenerateRecoveryCodesRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if c.GetFloat64("actor_id") != 0 {
+		response.Fail(c, errcode.ErrForbidden)
+		return
+	}
+	if !h.checkCode(c, uid, body.Code) {
+		return
+	}
+	codes, err := models.GenerateRecoveryCodes(h.db.WithContext(c), uid)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": RecoveryCodesResponse{RecoveryCodes: codes}})
+}
+
+type VerifyMFARequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+// 登录的第二步，用临时凭证和验证码换会话
+func (h *MFAHandler) VerifyMFA(c *gin.Context) {
+	var body VerifyMFARequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	uid, err := models.ParseMFAChallenge(body.ChallengeToken, h.config.TokenSecretKey)
+	if err != nil {
+		response.Fail(c, errcode.ErrTokenExpired)
+		return
+	}
+	if !h.checkCode(c, uid, body.Code) {
+		return
+	}
+	auth_resp, err := models.CreateMFAVerifiedSession(h.db.WithContext(c), uid, c.GetHeader("User-Agent"), c.ClientIP(), h.config.TokenSecretKey)
+	if err != nil {
+		h.logger.WithContext(c).Error("Failed to generate JWT", err)
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "登录成功", "data": auth_resp})
+}
+
+// mfaGuardKey 两步验证在 loginguard 里按用户计数，和邮箱登录的计数区分开
+func mfaGuardKey(uid int) string {
+	return fmt.Sprintf("mfa:%d", uid)
+}
+
+// checkCode 校验验证码或恢复码，失败次数过多时暂时锁定
+func (h *MFAHandler) checkCode(c *gin.Context, uid int, code string) bool {
+	key := mfaGuardKey(uid)
+	ip := c.ClientIP()
+	if wait := h.guard.Check(key, ip); wait > 0 {
+		response.Fail(c, errcode.ErrTooManyAttempts.WithArgs(int(wait.Seconds())+1))
+		return false
+	}
+	ok, err := models.VerifyMFACode(h.db.WithContext(c), uid, code)
+	if err != nil {
+		response.Fail(c, err)
+		return false
+	}
+	if !ok {
+		h.guard.Fail(key, ip)
+		response.Fail(c, errcode.ErrVerificationCodeInvalid)
+		return false
+	}
+	h.guard.Succeed(key)
+	return true
+}
@@ -7,7 +7,9 @@ import (
 	"gorm.io/gorm"
 
 	"myapi/internal/models"
+	"myapi/internal/pkg/errcode"
 	"myapi/internal/pkg/pagination"
+	"myapi/internal/pkg/response"
 	"myapi/pkg/logger"
 )
 
@@ -23,16 +25,18 @@ func NewMuscleHandler(db *gorm.DB, logger *logger.Logger) *MuscleHandler {
 	}
 }
 
+type FetchMuscleListRequest struct {
+	models.Pagination
+	Ids []int `json:"ids"`
+}
+
 func (h *MuscleHandler) FetchMuscleList(c *gin.Context) {
-	var body struct {
-		models.Pagination
-		Ids []int `json:"ids"`
-	}
+	var body FetchMuscleListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
-	query := h.db
+	query := h.db.WithContext(c)
 	if len(body.Ids) != 0 {
 		query = query.Where("id IN (?)", body.Ids)
 	}
@@ -42,49 +46,48 @@ func (h *MuscleHandler) FetchMuscleList(c *gin.Context) {
 		SetOrderBy("sort_idx DESC")
 	var list1 []models.Muscle
 	if err := pb.Build().Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "Success",
-		"data": gin.H{
-			"list":        list2,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list2, has_more, next_marker),
 	})
 }
 
+type FetchMuscleProfileRequest struct {
+	Id int `json:"id"`
+}
+
 func (h *MuscleHandler) FetchMuscleProfile(c *gin.Context) {
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body FetchMuscleProfileRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	var equipment models.Muscle
-	if err := h.db.First(&equipment, body.Id).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "没有找到记录", "data": nil})
+	if err := h.db.WithContext(c).First(&equipment, body.Id).Error; err != nil {
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "Success", "data": equipment})
 }
 
+type CreateMuscleRequest struct {
+	ZhName   string `json:"zh_name"`
+	Name     string `json:"name"`
+	Overview string `json:"overview"`
+	Tags     string `json:"tags"`
+	SortIdx  int    `json:"sort_idx"`
+	Medias   string `json:"medias"`
+}
+
 func (h *MuscleHandler) CreateMuscle(c *gin.Context) {
-	var body struct {
-		ZhName   string `json:"zh_name"`
-		Name     string `json:"name"`
-		Overview string `json:"overview"`
-		Tags     string `json:"tags"`
-		SortIdx  int    `json:"sort_idx"`
-		Medias   string `json:"medias"`
-	}
+	var body CreateMuscleRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	record := models.Muscle{
@@ -95,32 +98,34 @@ func (h *MuscleHandler) CreateMuscle(c *gin.Context) {
 		SortIdx:  body.SortIdx,
 		Medias:   body.Medias,
 	}
-	if err := h.db.Create(&record).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Create(&record).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": nil})
 }
 
+type UpdateMuscleRequest struct {
+	Id       int    `json:"id"`
+	ZhName   string `json:"zh_name"`
+	Name     string `json:"name"`
+	Overview string `json:"overview"`
+	Tags     string `json:"tags"`
+	SortIdx  int    `json:"sort_idx"`
+	Medias   string `json:"medias"`
+}
+
 // UpdateAction updates an existing workout action
 func (h *MuscleHandler) UpdateMuscle(c *gin.Context) {
 
-	var body struct {
-		Id       int    `json:"id"`
-		ZhName   string `json:"zh_name"`
-		Name     string `json:"name"`
-		Overview string `json:"overview"`
-		Tags     string `json:"tags"`
-		SortIdx  int    `json:"sort_idx"`
-		Medias   string `json:"medias"`
-	}
+	var body UpdateMuscleRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	var existing models.Muscle
-	if err := h.db.First(&existing, body.Id).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "没有找到记录", "data": nil})
+	if err := h.db.WithContext(c).First(&existing, body.Id).Error; err != nil {
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 	updates := map[string]interface{}{}
@@ -139,29 +144,31 @@ func (h *MuscleHandler) UpdateMuscle(c *gin.Context) {
 	if body.Medias != "" {
 		updates["medias"] = body.Medias
 	}
-	if err := h.db.Model(&existing).Updates(&updates).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Model(&existing).Updates(&updates).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "更新成功", "data": nil})
 }
 
+type DeleteMuscleRequest struct {
+	Id int `json:"id"`
+}
+
 func (h *MuscleHandler) DeleteMuscle(c *gin.Context) {
 
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body DeleteMuscleRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	var existing models.Muscle
-	if err := h.db.First(&existing, body.Id).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "没有找到记录", "data": nil})
+	if err := h.db.WithContext(c).First(&existing, body.Id).Error; err != nil {
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
-	if err := h.db.Model(&existing).Update("d", 1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Model(&existing).Update("d", 1).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "删除成功", "data": nil})
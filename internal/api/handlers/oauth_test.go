@@ -0,0 +1,216 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"myapi/config"
+	"myapi/internal/api/handlers"
+	"myapi/internal/models"
+	"myapi/internal/pkg/errcode"
+	"myapi/pkg/logger"
+	"myapi/pkg/oauth"
+)
+
+// 第三方登录走一遍完整流程：取授权地址 → 假的 OAuth 服务跳回 → 用授权码登录或绑定
+func TestOAuthLoginAndBindWithStubServer(t *testing.T) {
+	stub := httptest.NewServer(oauth.NewStubServer())
+	defer stub.Close()
+	database := openSQLite(t)
+	app := newOAuthApp(t, database, stub.URL)
+
+	// 第一次登录注册新用户
+	code, state := app.authorize(app.authURL("/oauth/url", 0), "alice")
+	resp := app.post("/oauth/login", 0, handlers.OAuthCodeRequest{Code: code, State: state})
+	if resp.Code != 200 {
+		t.Fatalf("first login: code = %d, msg = %s", resp.Code, resp.Msg)
+	}
+	alice := findOAuthAccount(t, database, "alice")
+	if alice.CoachId == 0 {
+		t.Fatal("first login did not create a coach")
+	}
+	coaches := countCoaches(t, database)
+
+	// 再次登录匹配到已有的帐号，不会再注册
+	code, state = app.authorize(app.authURL("/oauth/url", 0), "alice")
+	resp = app.post("/oauth/login", 0, handlers.OAuthCodeRequest{Code: code, State: state})
+	if resp.Code != 200 {
+		t.Fatalf("second login: code = %d, msg = %s", resp.Code, resp.Msg)
+	}
+	if got := findOAuthAccount(t, database, "alice").CoachId; got != alice.CoachId {
+		t.Errorf("second login coach = %d, want %d", got, alice.CoachId)
+	}
+	if got := countCoaches(t, database); got != coaches {
+		t.Errorf("coaches after second login = %d, want %d", got, coaches)
+	}
+
+	// 已有用户绑定第三方帐号
+	bob := models.Coach{Nickname: "bob", Config: "{}"}
+	if err := database.Create(&bob).Error; err != nil {
+		t.Fatal(err)
+	}
+	code, state = app.authorize(app.authURL("/oauth/bind_url", bob.Id), "bob-oauth")
+	resp = app.post("/oauth/bind", bob.Id, handlers.OAuthCodeRequest{Code: code, State: state})
+	if resp.Code != 200 {
+		t.Fatalf("bind: code = %d, msg = %s", resp.Code, resp.Msg)
+	}
+	if got := findOAuthAccount(t, database, "bob-oauth").CoachId; got != bob.Id {
+		t.Errorf("bound coach = %d, want %d", got, bob.Id)
+	}
+
+	// 已经被其他用户绑定的帐号不能再绑定
+	code, state = app.authorize(app.authURL("/oauth/bind_url", bob.Id), "alice")
+	resp = app.post("/oauth/bind", bob.Id, handlers.OAuthCodeRequest{Code: code, State: state})
+	if resp.Code != errcode.ErrAccountBoundOther.Code {
+		t.Errorf("bind account of another coach: code = %d, want %d", resp.Code, errcode.ErrAccountBoundOther.Code)
+	}
+
+	// state 是别的用户发起的，或者是绑定用的 state 拿来登录，都要拒绝
+	code, state = app.authorize(app.authURL("/oauth/bind_url", alice.CoachId), "carol")
+	resp = app.post("/oauth/bind", bob.Id, handlers.OAuthCodeRequest{Code: code, State: state})
+	if resp.Code != errcode.ErrOAuthExpired.Code {
+		t.Errorf("bind with state of another coach: code = %d, want %d", resp.Code, errcode.ErrOAuthExpired.Code)
+	}
+	resp = app.post("/oauth/login", 0, handlers.OAuthCodeRequest{Code: code, State: state})
+	if resp.Code != errcode.ErrOAuthExpired.Code {
+		t.Errorf("login with bind state: code = %d, want %d", resp.Code, errcode.ErrOAuthExpired.Code)
+	}
+	resp = app.post("/oauth/login", 0, handlers.OAuthCodeRequest{Code: code, State: state + "x"})
+	if resp.Code != errcode.ErrOAuthExpired.Code {
+		t.Errorf("login with tampered state: code = %d, want %d", resp.Code, errcode.ErrOAuthExpired.Code)
+	}
+	var count int64
+	if err := database.Model(&models.CoachAccount{}).Where("provider_id = ?", "carol").Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("rejected state still created %d account(s)", count)
+	}
+}
+
+type oauthApp struct {
+	t      *testing.T
+	router *gin.Engine
+	client *http.Client
+}
+
+type envelope struct {
+	Code int             `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+func newOAuthApp(t *testing.T, database *gorm.DB, stub_url string) *oauthApp {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{
+		TokenSecretKey:    "test-secret",
+		DefaultAvatarURL:  "//static.test/avatars/default.jpeg",
+		OAuthName:         "stub",
+		OAuthClientId:     "client",
+		OAuthClientSecret: "secret",
+		OAuthAuthorizeURL: stub_url + "/authorize",
+		OAuthTokenURL:     stub_url + "/token",
+		OAuthUserInfoURL:  stub_url + "/userinfo",
+		OAuthRedirectURL:  "http://app.test/callback",
+	}
+	handler := handlers.NewAccountHandler(database, logger.NewLogger("error"), cfg)
+	router := gin.New()
+	// 测试里用请求头代替登录凭证
+	router.Use(func(c *gin.Context) {
+		if uid, _ := strconv.Atoi(c.GetHeader("X-Test-Uid")); uid != 0 {
+			c.Set("id", float64(uid))
+		}
+	})
+	router.POST("/oauth/url", handler.BuildOAuthURL)
+	router.POST("/oauth/bind_url", handler.BuildOAuthBindURL)
+	router.POST("/oauth/login", handler.LoginWithOAuth)
+	router.POST("/oauth/bind", handler.BindOAuth)
+	return &oauthApp{
+		t:      t,
+		router: router,
+		client: &http.Client{
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+func (a *oauthApp) post(path string, uid int, body interface{}) envelope {
+	a.t.Helper()
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+	if uid != 0 {
+		req.Header.Set("X-Test-Uid", strconv.Itoa(uid))
+	}
+	w := httptest.NewRecorder()
+	a.router.ServeHTTP(w, req)
+	var resp envelope
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		a.t.Fatalf("decode %s: %v", w.Body.String(), err)
+	}
+	return resp
+}
+
+// authURL 取授权地址
+func (a *oauthApp) authURL(path string, uid int) string {
+	a.t.Helper()
+	resp := a.post(path, uid, gin.H{})
+	if resp.Code != 200 {
+		a.t.Fatalf("%s: code = %d, msg = %s", path, resp.Code, resp.Msg)
+	}
+	var data struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		a.t.Fatal(err)
+	}
+	return data.URL
+}
+
+// authorize 打开授权页，以 login 的身份授权，返回回调地址里的 code 和 state
+func (a *oauthApp) authorize(auth_url string, login string) (string, string) {
+	a.t.Helper()
+	resp, err := a.client.Get(auth_url + "&login=" + url.QueryEscape(login))
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		a.t.Fatalf("authorize: status = %d", resp.StatusCode)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	if got := location.Scheme + "://" + location.Host + location.Path; got != "http://app.test/callback" {
+		a.t.Fatalf("redirected to %s", got)
+	}
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func findOAuthAccount(t *testing.T, database *gorm.DB, provider_id string) models.CoachAccount {
+	t.Helper()
+	var account models.CoachAccount
+	if err := database.Where("provider_type = ? AND provider_id = ?", models.AccountProviderTypeOAuth, provider_id).First(&account).Error; err != nil {
+		t.Fatalf("account %s: %v", provider_id, err)
+	}
+	return account
+}
+
+func countCoaches(t *testing.T, database *gorm.DB) int64 {
+	t.Helper()
+	var count int64
+	if err := database.Model(&models.Coach{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"myapi/config"
+	"myapi/internal/models"
+	"myapi/internal/pkg/errcode"
+	"myapi/internal/pkg/loginguard"
+	"myapi/internal/pkg/response"
+	"myapi/internal/pkg/userdata"
+	"myapi/pkg/logger"
+)
+
+// PrivacyHandler 个人数据导出、注销帐号
+type PrivacyHandler struct {
+	db     *gorm.DB
+	logger *logger.Logger
+	config *config.Config
+	guard  *loginguard.Guard
+}
+
+// NewPrivacyHandler guard 和登录、两步验证共用，注销时校验密码和验证码的失败次数一起计算
+func NewPrivacyHandler(db *gorm.DB, logger *logger.Logger, cfg *config.Config, guard *loginguard.Guard) *PrivacyHandler {
+	return &PrivacyHandler{
+		db:     db,
+		logger: logger,
+		config: cfg,
+		guard:  guard,
+	}
+}
+
+// 导出个人数据，返回 zip 文件
+func (h *PrivacyHandler) ExportPersonalData(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	if c.GetFloat64("actor_id") != 0 {
+		response.Fail(c, errcode.ErrForbidden)
+		return
+	}
+	var buf bytes.Buffer
+	if err := userdata.Export(h.db.WithContext(c), uid, &buf); err != nil {
+		response.Fail(c, err)
+		return
+	}
+	h.logger.WithContext(c).Infow("Personal data exported", "coach_id", uid, "ip", c.ClientIP())
+	filename := fmt.Sprintf("fithub-export-%d-%s.zip", uid, time.Now().Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+type RequestDeletionRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+	Reason   string `json:"reason"`
+}
+
+// 申请注销帐号，冷静期内可以撤销
+func (h *PrivacyHandler) RequestDeletion(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	var body RequestDeletionRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody)
+		return
+	}
+	if c.GetFloat64("actor_id") != 0 {
+		response.Fail(c, errcode.ErrForbidden)
+		return
+	}
+	if uid == 1 {
+		response.Fail(c, errcode.ErrAdminUndeletable)
+		return
+	}
+	existing, err := models.FetchPendingDeletionRequest(h.db.WithContext(c), uid)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if existing != nil {
+		c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "已经申请过注销", "data": existing})
+		return
+	}
+	// 有密码的帐号需要再次输入密码，开启了两步验证的还需要验证码
+	ip := c.ClientIP()
+	var account models.CoachAccount
+	if err := h.db.WithContext(c).Where("coach_id = ? AND provider_type = ?", uid, models.AccountProviderTypeEmailWithPwd).First(&account).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+	} else {
+		if body.Password == "" {
+			response.Fail(c, errcode.ErrPasswordRequired)
+			return
+		}
+		if wait := h.guard.Check(account.ProviderId, ip); wait > 0 {
+			response.Fail(c, errcode.ErrTooManyAttempts.WithArgs(int(wait.Seconds())+1))
+			return
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(account.ProviderArg1), []byte(body.Password)); err != nil {
+			h.guard.Fail(account.ProviderId, ip)
+			response.Fail(c, errcode.ErrPasswordIncorrect)
+			return
+		}
+		h.guard.Succeed(account.ProviderId)
+	}
+	enabled, err := models.IsTOTPEnabled(h.db.WithContext(c), uid)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if enabled {
+		if body.Code == "" {
+			response.Fail(c, errcode.ErrCodeRequired)
+			return
+		}
+		key := mfaGuardKey(uid)
+		if wait := h.guard.Check(key, ip); wait > 0 {
+			response.Fail(c, errcode.ErrTooManyAttempts.WithArgs(int(wait.Seconds())+1))
+			return
+		}
+		ok, err := models.VerifyMFACode(h.db.WithContext(c), uid, body.Code)
+		if err != nil {
+			response.Fail(c, err)
+			return
+		}
+		if !ok {
+			h.guard.Fail(key, ip)
+			response.Fail(c, errcode.ErrVerificationCodeInvalid)
+			return
+		}
+		h.guard.Succeed(key)
+	}
+	now := time.Now()
+	record := models.CoachDeletionRequest{
+		Status:      models.DeletionRequestStatusPending,
+		Reason:      body.Reason,
+		ScheduledAt: now.AddDate(0, 0, h.config.AccountDeletionGraceDays),
+		CreatedAt:   now,
+		CoachId:     uid,
+	}
+	if err := h.db.WithContext(c).Create(&record).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	h.logger.WithContext(c).Infow("Account deletion requested", "coach_id", uid, "scheduled_at", record.ScheduledAt)
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "已申请注销", "data": record})
+}
+
+// 撤销注销申请
+func (h *PrivacyHandler) CancelDeletion(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	if c.GetFloat64("actor_id") != 0 {
+		response.Fail(c, errcode.ErrForbidden)
+		return
+	}
+	now := time.Now()
+	r := h.db.WithContext(c).Model(&models.CoachDeletionRequest{}).
+		Where("coach_id = ? AND status = ?", uid, models.DeletionRequestStatusPending).
+		Updates(map[string]interface{}{
+			"status":       models.DeletionRequestStatusCancelled,
+			"cancelled_at": now,
+		})
+	if r.Error != nil {
+		response.Fail(c, r.Error)
+		return
+	}
+	if r.RowsAffected == 0 {
+		response.Fail(c, errcode.ErrDeletionNotFound)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "已撤销注销申请", "data": nil})
+}
+
+// 注销申请的状态，没有申请时 data 为 null
+func (h *PrivacyHandler) FetchDeletionStatus(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+	existing, err := models.FetchPendingDeletionRequest(h.db.WithContext(c), uid)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": existing})
+}
@@ -2,15 +2,18 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
 	"myapi/internal/models"
+	"myapi/internal/pkg/errcode"
+	"myapi/internal/pkg/metrics"
 	"myapi/internal/pkg/pagination"
+	"myapi/internal/pkg/response"
 	"myapi/pkg/logger"
 )
 
@@ -26,98 +29,94 @@ func NewQuizHandler(db *gorm.DB, logger *logger.Logger) *QuizHandler {
 	}
 }
 
+type FetchPaperListRequest struct {
+	models.Pagination
+}
+
 func (h *QuizHandler) FetchPaperList(c *gin.Context) {
-	var body struct {
-		models.Pagination
-	}
+	var body FetchPaperListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
-	query := h.db
+	query := h.db.WithContext(c)
 	pb := pagination.NewPaginationBuilder[models.Paper](query).
 		SetLimit(body.PageSize).
 		SetPage(body.Page).
 		SetOrderBy("created_at DESC")
 	var list1 []models.Paper
 	if err := pb.Build().Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch discount policies", "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "",
-		"data": gin.H{
-			"list":        list2,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list2, has_more, next_marker),
 	})
 }
 
+type FetchQuizListRequest struct {
+	models.Pagination
+}
+
 func (h *QuizHandler) FetchQuizList(c *gin.Context) {
-	var body struct {
-		models.Pagination
-	}
+	var body FetchQuizListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
-	query := h.db
+	query := h.db.WithContext(c)
 	pb := pagination.NewPaginationBuilder[models.Quiz](query).
 		SetLimit(body.PageSize).
 		SetPage(body.Page).
 		SetOrderBy("created_at DESC")
 	var list1 []models.Quiz
 	if err := pb.Build().Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch records", "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "Success",
-		"data": gin.H{
-			"list":        list2,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list2, has_more, next_marker),
 	})
 }
 
+type CreateQuizRequest struct {
+	Content    string `json:"content"`
+	Overview   string `json:"overview"`
+	Type       int    `json:"type"`
+	Tags       string `json:"tags"`
+	Difficulty int    `json:"difficulty"`
+	Choices    string `json:"choices"`
+	Answer     string `json:"answer"`
+	Analysis   string `json:"analysis"`
+	Medias     string `json:"medias"`
+}
+
 func (h *QuizHandler) CreateQuiz(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		Content    string `json:"content"`
-		Overview   string `json:"overview"`
-		Type       int    `json:"type"`
-		Tags       string `json:"tags"`
-		Difficulty int    `json:"difficulty"`
-		Choices    string `json:"choices"`
-		Answer     string `json:"answer"`
-		Analysis   string `json:"analysis"`
-		Medias     string `json:"medias"`
-	}
+	var body CreateQuizRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 
 	// 开始事务
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	if tx.Error != nil {
-		h.logger.Error("Failed to start transaction", tx.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+		h.logger.WithContext(c).Error("Failed to start transaction", tx.Error)
+		response.Fail(c, tx.Error)
 		return
 	}
 
@@ -138,16 +137,16 @@ func (h *QuizHandler) CreateQuiz(c *gin.Context) {
 
 	if err := tx.Create(&paper).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to create subscription plan", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to create subscription plan", "data": nil})
+		h.logger.WithContext(c).Error("Failed to create subscription plan", err)
+		response.Fail(c, err)
 		return
 	}
 
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to commit transaction", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -158,37 +157,39 @@ func (h *QuizHandler) CreateQuiz(c *gin.Context) {
 	})
 }
 
+type CreatePaperRequest struct {
+	Name      string `json:"name"`
+	Overview  string `json:"overview"`
+	Tags      string `json:"tags"`
+	PassScore int    `json:"pass_score"`
+	Duration  int    `json:"duration"`
+	QuizList  []struct {
+		Id      int `json:"id"`
+		Score   int `json:"score"`
+		SortIdx int `json:"sort_idx"`
+	} `json:"quiz_list"`
+}
+
 func (h *QuizHandler) CreatePaper(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		Name      string `json:"name"`
-		Overview  string `json:"overview"`
-		Tags      string `json:"tags"`
-		PassScore int    `json:"pass_score"`
-		Duration  int    `json:"duration"`
-		QuizList  []struct {
-			Id      int `json:"id"`
-			Score   int `json:"score"`
-			SortIdx int `json:"sort_idx"`
-		} `json:"quiz_list"`
-	}
+	var body CreatePaperRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 
 	// 开始事务
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	if tx.Error != nil {
-		h.logger.Error("Failed to start transaction", tx.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+		h.logger.WithContext(c).Error("Failed to start transaction", tx.Error)
+		response.Fail(c, tx.Error)
 		return
 	}
 
@@ -206,8 +207,8 @@ func (h *QuizHandler) CreatePaper(c *gin.Context) {
 
 	if err := tx.Create(&paper).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to create paper", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to create paper", "data": nil})
+		h.logger.WithContext(c).Error("Failed to create paper", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -222,8 +223,8 @@ func (h *QuizHandler) CreatePaper(c *gin.Context) {
 		}
 		if err := tx.Create(&paperQuiz).Error; err != nil {
 			tx.Rollback()
-			h.logger.Error("Failed to create paper quiz relation", err)
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to create paper quiz relation", "data": nil})
+			h.logger.WithContext(c).Error("Failed to create paper quiz relation", err)
+			response.Fail(c, err)
 			return
 		}
 	}
@@ -231,8 +232,8 @@ func (h *QuizHandler) CreatePaper(c *gin.Context) {
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to commit transaction", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -243,38 +244,40 @@ func (h *QuizHandler) CreatePaper(c *gin.Context) {
 	})
 }
 
+type UpdatePaperRequest struct {
+	Id        int    `json:"id"`
+	Name      string `json:"name"`
+	Tags      string `json:"tags"`
+	PassScore int    `json:"pass_score"`
+	Duration  int    `json:"duration"`
+	QuizList  []struct {
+		RelationId int `json:"relation_id"`
+		Id         int `json:"id"`
+		Score      int `json:"score"`
+		SortIdx    int `json:"sort_idx"`
+	} `json:"quiz_list"`
+}
+
 func (h *QuizHandler) UpdatePaper(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		Id        int    `json:"id"`
-		Name      string `json:"name"`
-		Tags      string `json:"tags"`
-		PassScore int    `json:"pass_score"`
-		Duration  int    `json:"duration"`
-		QuizList  []struct {
-			RelationId int `json:"relation_id"`
-			Id         int `json:"id"`
-			Score      int `json:"score"`
-			SortIdx    int `json:"sort_idx"`
-		} `json:"quiz_list"`
-	}
+	var body UpdatePaperRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 
 	// 开始事务
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	if tx.Error != nil {
-		h.logger.Error("Failed to start transaction", tx.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+		h.logger.WithContext(c).Error("Failed to start transaction", tx.Error)
+		response.Fail(c, tx.Error)
 		return
 	}
 
@@ -283,10 +286,10 @@ func (h *QuizHandler) UpdatePaper(c *gin.Context) {
 	if err := tx.First(&paper, body.Id).Error; err != nil {
 		tx.Rollback()
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "Paper not found", "data": nil})
+			response.Fail(c, errcode.ErrPaperNotFound)
 		} else {
-			h.logger.Error("Failed to find paper", err)
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to find paper", "data": nil})
+			h.logger.WithContext(c).Error("Failed to find paper", err)
+			response.Fail(c, errcode.ErrInternal)
 		}
 		return
 	}
@@ -294,7 +297,7 @@ func (h *QuizHandler) UpdatePaper(c *gin.Context) {
 	// 检查权限
 	if paper.CreatorId != uid {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 403, "msg": "Permission denied", "data": nil})
+		response.Fail(c, errcode.ErrForbidden)
 		return
 	}
 
@@ -307,8 +310,8 @@ func (h *QuizHandler) UpdatePaper(c *gin.Context) {
 	}
 	if err := tx.Model(&paper).Updates(updates).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to update paper", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to update paper", "data": nil})
+		h.logger.WithContext(c).Error("Failed to update paper", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -316,8 +319,8 @@ func (h *QuizHandler) UpdatePaper(c *gin.Context) {
 	var existing_relations []models.PaperQuiz
 	if err := tx.Where("paper_id = ?", body.Id).Find(&existing_relations).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to fetch existing paper quiz relations", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch existing relations", "data": nil})
+		h.logger.WithContext(c).Error("Failed to fetch existing paper quiz relations", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -346,8 +349,8 @@ func (h *QuizHandler) UpdatePaper(c *gin.Context) {
 				}
 				if err := tx.Model(&models.PaperQuiz{}).Where("id = ?", quiz.RelationId).Updates(updates).Error; err != nil {
 					tx.Rollback()
-					h.logger.Error("Failed to update paper quiz relation", err)
-					c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to update relation", "data": nil})
+					h.logger.WithContext(c).Error("Failed to update paper quiz relation", err)
+					response.Fail(c, err)
 					return
 				}
 			}
@@ -362,8 +365,8 @@ func (h *QuizHandler) UpdatePaper(c *gin.Context) {
 			}
 			if err := tx.Create(&paperQuiz).Error; err != nil {
 				tx.Rollback()
-				h.logger.Error("Failed to create paper quiz relation", err)
-				c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to create relation", "data": nil})
+				h.logger.WithContext(c).Error("Failed to create paper quiz relation", err)
+				response.Fail(c, err)
 				return
 			}
 		}
@@ -374,8 +377,8 @@ func (h *QuizHandler) UpdatePaper(c *gin.Context) {
 		if _, exists := new_relation_map[existingRel.Id]; !exists {
 			if err := tx.Delete(&models.PaperQuiz{}, existingRel.Id).Error; err != nil {
 				tx.Rollback()
-				h.logger.Error("Failed to delete paper quiz relation", err)
-				c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to delete relation", "data": nil})
+				h.logger.WithContext(c).Error("Failed to delete paper quiz relation", err)
+				response.Fail(c, err)
 				return
 			}
 		}
@@ -384,8 +387,8 @@ func (h *QuizHandler) UpdatePaper(c *gin.Context) {
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to commit transaction", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -396,67 +399,76 @@ func (h *QuizHandler) UpdatePaper(c *gin.Context) {
 	})
 }
 
+type FetchPaperProfileRequest struct {
+	Id int `json:"id"`
+}
+
+type FetchPaperProfileResponse struct {
+	Paper   models.Paper       `json:"paper"`
+	Quizzes []models.PaperQuiz `json:"quizzes"`
+}
+
 func (h *QuizHandler) FetchPaperProfile(c *gin.Context) {
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body FetchPaperProfileRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 
 	// 获取试卷信息
 	var paper models.Paper
-	if err := h.db.First(&paper, body.Id).Error; err != nil {
+	if err := h.db.WithContext(c).First(&paper, body.Id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "Paper not found", "data": nil})
+			response.Fail(c, errcode.ErrPaperNotFound)
 		} else {
-			h.logger.Error("Failed to fetch paper", err)
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch paper", "data": nil})
+			h.logger.WithContext(c).Error("Failed to fetch paper", err)
+			response.Fail(c, errcode.ErrInternal)
 		}
 		return
 	}
 
 	// 获取试卷关联的题目
 	var paper_quizzes []models.PaperQuiz
-	if err := h.db.Where("paper_id = ?", body.Id).Order("sort_idx asc").Preload("Quiz").Find(&paper_quizzes).Error; err != nil {
-		h.logger.Error("Failed to fetch paper quizzes", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch paper quizzes", "data": nil})
+	if err := h.db.WithContext(c).Where("paper_id = ?", body.Id).Order("sort_idx asc").Preload("Quiz").Find(&paper_quizzes).Error; err != nil {
+		h.logger.WithContext(c).Error("Failed to fetch paper quizzes", err)
+		response.Fail(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "",
-		"data": gin.H{
-			"paper":   paper,
-			"quizzes": paper_quizzes,
+		"data": FetchPaperProfileResponse{
+			Paper:   paper,
+			Quizzes: paper_quizzes,
 		},
 	})
 }
 
+type StartExamWithPaperRequest struct {
+	PaperId int `json:"paper_id"`
+}
+
 func (h *QuizHandler) StartExamWithPaper(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		PaperId int `json:"paper_id"`
-	}
+	var body StartExamWithPaperRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 
 	// 开始事务
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	if tx.Error != nil {
-		h.logger.Error("Failed to start transaction", tx.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+		h.logger.WithContext(c).Error("Failed to start transaction", tx.Error)
+		response.Fail(c, tx.Error)
 		return
 	}
 
@@ -465,10 +477,10 @@ func (h *QuizHandler) StartExamWithPaper(c *gin.Context) {
 	if err := tx.First(&paper, body.PaperId).Error; err != nil {
 		tx.Rollback()
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "Paper not found", "data": nil})
+			response.Fail(c, errcode.ErrPaperNotFound)
 		} else {
-			h.logger.Error("Failed to find paper", err)
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to find paper", "data": nil})
+			h.logger.WithContext(c).Error("Failed to find paper", err)
+			response.Fail(c, errcode.ErrInternal)
 		}
 		return
 	}
@@ -485,8 +497,8 @@ func (h *QuizHandler) StartExamWithPaper(c *gin.Context) {
 
 	if err := tx.Create(&exam).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to create exam", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to start exam", "data": nil})
+		h.logger.WithContext(c).Error("Failed to create exam", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -494,8 +506,8 @@ func (h *QuizHandler) StartExamWithPaper(c *gin.Context) {
 	var paper_quizzes []models.PaperQuiz
 	if err := tx.Where("paper_id = ?", body.PaperId).Order("sort_idx asc").Find(&paper_quizzes).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to fetch paper quizzes", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch paper quizzes", "data": nil})
+		h.logger.WithContext(c).Error("Failed to fetch paper quizzes", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -511,8 +523,8 @@ func (h *QuizHandler) StartExamWithPaper(c *gin.Context) {
 		}
 		if err := tx.Create(&quiz_answer).Error; err != nil {
 			tx.Rollback()
-			h.logger.Error("Failed to create quiz answer", err)
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to create quiz answer", "data": nil})
+			h.logger.WithContext(c).Error("Failed to create quiz answer", err)
+			response.Fail(c, err)
 			return
 		}
 	}
@@ -520,8 +532,8 @@ func (h *QuizHandler) StartExamWithPaper(c *gin.Context) {
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to commit transaction", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -532,33 +544,37 @@ func (h *QuizHandler) StartExamWithPaper(c *gin.Context) {
 	})
 }
 
+type FetchRunningExamResponse struct {
+	List []models.Exam `json:"list"`
+}
+
 func (h *QuizHandler) FetchRunningExam(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
 	// 获取用户正在进行的考试
 	var exams []models.Exam
-	if err := h.db.Where("student_id = ? AND status = ?", uid, 2).Find(&exams).Error; err != nil {
+	if err := h.db.WithContext(c).Where("student_id = ? AND status = ?", uid, 2).Find(&exams).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": nil})
 			return
 		}
-		h.logger.Error("Failed to fetch running exam", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch running exam", "data": nil})
+		h.logger.WithContext(c).Error("Failed to fetch running exam", err)
+		response.Fail(c, errcode.ErrInternal)
 		return
 	}
 
 	// // 获取试卷信息
 	// var paper models.Paper
-	// if err := h.db.First(&paper, exam.PaperId).Error; err != nil {
-	// 	h.logger.Error("Failed to fetch paper", err)
+	// if err := h.db.WithContext(c).First(&paper, exam.PaperId).Error; err != nil {
+	// 	h.logger.WithContext(c).Error("Failed to fetch paper", err)
 	// 	c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch paper", "data": nil})
 	// 	return
 	// }
 
 	// // 获取答题记录
 	// var quiz_answers []models.QuizAnswer
-	// if err := h.db.Where("exam_id = ?", exam.Id).Preload("Quiz").Order("id asc").Find(&quiz_answers).Error; err != nil {
-	// 	h.logger.Error("Failed to fetch quiz answers", err)
+	// if err := h.db.WithContext(c).Where("exam_id = ?", exam.Id).Preload("Quiz").Order("id asc").Find(&quiz_answers).Error; err != nil {
+	// 	h.logger.WithContext(c).Error("Failed to fetch quiz answers", err)
 	// 	c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch quiz answers", "data": nil})
 	// 	return
 	// }
@@ -566,126 +582,134 @@ func (h *QuizHandler) FetchRunningExam(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "",
-		"data": gin.H{
-			"list": exams,
+		"data": FetchRunningExamResponse{
+			List: exams,
 		},
 	})
 }
 
+type FetchExamListRequest struct {
+	models.Pagination
+}
+
 func (h *QuizHandler) FetchExamList(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		models.Pagination
-	}
+	var body FetchExamListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
-	query := h.db.Where("student_id = ?", uid)
+	query := h.db.WithContext(c).Where("student_id = ?", uid)
 	pb := pagination.NewPaginationBuilder[models.Exam](query).
 		SetLimit(body.PageSize).
 		SetPage(body.Page).
 		SetOrderBy("created_at DESC")
 	var list1 []models.Exam
 	if err := pb.Build().Preload("Paper").Find(&list1).Error; err != nil {
-		h.logger.Error("Failed to fetch exam list", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch exam", "data": nil})
+		h.logger.WithContext(c).Error("Failed to fetch exam list", err)
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "",
-		"data": gin.H{
-			"list":        list2,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list2, has_more, next_marker),
 	})
 }
 
+type FetchExamProfileRequest struct {
+	Id int `json:"id"`
+}
+
+// ExamDetailResponse 考试详情和考试结果
+type ExamDetailResponse struct {
+	Exam        models.Exam         `json:"exam"`
+	Paper       models.Paper        `json:"paper"`
+	QuizAnswers []models.QuizAnswer `json:"quiz_answers"`
+}
+
 func (h *QuizHandler) FetchExamProfile(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body FetchExamProfileRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 
 	// 获取考试信息
 	var exam models.Exam
-	if err := h.db.First(&exam, body.Id).Error; err != nil {
+	if err := h.db.WithContext(c).First(&exam, body.Id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "Exam not found", "data": nil})
+			response.Fail(c, errcode.ErrExamNotFound)
 		} else {
-			h.logger.Error("Failed to fetch exam", err)
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch exam", "data": nil})
+			h.logger.WithContext(c).Error("Failed to fetch exam", err)
+			response.Fail(c, errcode.ErrInternal)
 		}
 		return
 	}
 
 	// 检查权限
 	if exam.StudentId != uid {
-		c.JSON(http.StatusOK, gin.H{"code": 403, "msg": "Permission denied", "data": nil})
+		response.Fail(c, errcode.ErrForbidden)
 		return
 	}
 
 	// 获取试卷信息
 	var paper models.Paper
-	if err := h.db.First(&paper, exam.PaperId).Error; err != nil {
-		h.logger.Error("Failed to fetch paper", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch paper", "data": nil})
+	if err := h.db.WithContext(c).First(&paper, exam.PaperId).Error; err != nil {
+		h.logger.WithContext(c).Error("Failed to fetch paper", err)
+		response.Fail(c, err)
 		return
 	}
 
 	// 获取答题记录
 	var quiz_answers []models.QuizAnswer
-	if err := h.db.Where("exam_id = ?", exam.Id).Preload("Quiz").Order("id asc").Find(&quiz_answers).Error; err != nil {
-		h.logger.Error("Failed to fetch quiz answers", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch quiz answers", "data": nil})
+	if err := h.db.WithContext(c).Where("exam_id = ?", exam.Id).Preload("Quiz").Order("id asc").Find(&quiz_answers).Error; err != nil {
+		h.logger.WithContext(c).Error("Failed to fetch quiz answers", err)
+		response.Fail(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "",
-		"data": gin.H{
-			"exam":         exam,
-			"paper":        paper,
-			"quiz_answers": quiz_answers,
+		"data": ExamDetailResponse{
+			Exam:        exam,
+			Paper:       paper,
+			QuizAnswers: quiz_answers,
 		},
 	})
 }
 
+type UpdateQuizAnswerRequest struct {
+	Id      int    `json:"id"`
+	ExamId  int    `json:"exam_id"`
+	QuizId  int    `json:"quiz_id"`
+	Content string `json:"content"`
+}
+
 func (h *QuizHandler) UpdateQuizAnswer(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		Id      int    `json:"id"`
-		ExamId  int    `json:"exam_id"`
-		QuizId  int    `json:"quiz_id"`
-		Content string `json:"content"`
-	}
+	var body UpdateQuizAnswerRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 
 	// 开始事务
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	if tx.Error != nil {
-		h.logger.Error("Failed to start transaction", tx.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+		h.logger.WithContext(c).Error("Failed to start transaction", tx.Error)
+		response.Fail(c, tx.Error)
 		return
 	}
 
@@ -694,24 +718,24 @@ func (h *QuizHandler) UpdateQuizAnswer(c *gin.Context) {
 	if err := tx.First(&exam, body.ExamId).Error; err != nil {
 		tx.Rollback()
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "Exam not found", "data": nil})
+			response.Fail(c, errcode.ErrExamNotFound)
 		} else {
-			h.logger.Error("Failed to find exam", err)
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to find exam", "data": nil})
+			h.logger.WithContext(c).Error("Failed to find exam", err)
+			response.Fail(c, errcode.ErrInternal)
 		}
 		return
 	}
 
 	if exam.StudentId != uid {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 403, "msg": "Permission denied", "data": nil})
+		response.Fail(c, errcode.ErrForbidden)
 		return
 	}
 
 	// 检查考试状态
 	if exam.Status != 2 { // 2表示进行中
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Exam is not in progress", "data": nil})
+		response.Fail(c, errcode.ErrExamNotInProgress)
 		return
 	}
 	// 更新考试进度
@@ -720,8 +744,8 @@ func (h *QuizHandler) UpdateQuizAnswer(c *gin.Context) {
 	}
 	if err := tx.Model(&exam).Updates(exam_updates).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to update exam", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to update exam", "data": nil})
+		h.logger.WithContext(c).Error("Failed to update exam", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -730,10 +754,10 @@ func (h *QuizHandler) UpdateQuizAnswer(c *gin.Context) {
 	if err := tx.Where("exam_id = ? AND quiz_id = ?", body.ExamId, body.QuizId).First(&quiz_answer).Error; err != nil {
 		tx.Rollback()
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "Quiz answer not found", "data": nil})
+			response.Fail(c, errcode.ErrQuizAnswerNotFound)
 		} else {
-			h.logger.Error("Failed to find quiz answer", err)
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to find quiz answer", "data": nil})
+			h.logger.WithContext(c).Error("Failed to find quiz answer", err)
+			response.Fail(c, errcode.ErrInternal)
 		}
 		return
 	}
@@ -742,8 +766,8 @@ func (h *QuizHandler) UpdateQuizAnswer(c *gin.Context) {
 	var quiz models.Quiz
 	if err := tx.First(&quiz, body.QuizId).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to find quiz", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to find quiz", "data": nil})
+		h.logger.WithContext(c).Error("Failed to find quiz", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -751,8 +775,8 @@ func (h *QuizHandler) UpdateQuizAnswer(c *gin.Context) {
 	var paper_quiz models.PaperQuiz
 	if err := tx.Where("paper_id = ? AND quiz_id = ?", exam.PaperId, body.QuizId).First(&paper_quiz).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to find paper quiz relation", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to find paper quiz relation", "data": nil})
+		h.logger.WithContext(c).Error("Failed to find paper quiz relation", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -763,8 +787,8 @@ func (h *QuizHandler) UpdateQuizAnswer(c *gin.Context) {
 	}
 	if err := json.Unmarshal([]byte(body.Content), &user_answer); err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to parse user answer", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to parse user answer", "data": nil})
+		h.logger.WithContext(c).Error("Failed to parse user answer", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -774,8 +798,8 @@ func (h *QuizHandler) UpdateQuizAnswer(c *gin.Context) {
 	}
 	if err := json.Unmarshal([]byte(quiz.Answer), &correct_answer); err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to parse correct answer", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to parse correct answer", "data": nil})
+		h.logger.WithContext(c).Error("Failed to parse correct answer", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -785,10 +809,7 @@ func (h *QuizHandler) UpdateQuizAnswer(c *gin.Context) {
 
 	switch quiz.Type {
 	case 1: // 单选
-		fmt.Println("check the answer is correct1")
-		fmt.Println(user_answer.Choices)
-		fmt.Println(correct_answer.Value)
-		fmt.Println(quiz.Answer)
+		h.logger.WithContext(c).Debugw("Check single choice answer", "choices", user_answer.Choices, "answer", correct_answer.Value)
 		if len(user_answer.Choices) == len(correct_answer.Value) {
 			// 创建一个map来存储正确答案中的元素
 			correct_map := make(map[int]bool)
@@ -816,9 +837,7 @@ func (h *QuizHandler) UpdateQuizAnswer(c *gin.Context) {
 			score = 0
 		}
 	case 2: // 多选
-		fmt.Println("check the answer is correct1")
-		fmt.Println(user_answer.Choices)
-		fmt.Println(correct_answer.Value)
+		h.logger.WithContext(c).Debugw("Check multiple choice answer", "choices", user_answer.Choices, "answer", correct_answer.Value)
 		// 检查两个数组是否包含相同的元素（顺序不重要）
 		if len(user_answer.Choices) == len(correct_answer.Value) {
 			// 创建一个map来存储正确答案中的元素
@@ -875,16 +894,16 @@ func (h *QuizHandler) UpdateQuizAnswer(c *gin.Context) {
 
 	if err := tx.Model(&quiz_answer).Updates(updates).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to update quiz answer", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to update quiz answer", "data": nil})
+		h.logger.WithContext(c).Error("Failed to update quiz answer", err)
+		response.Fail(c, err)
 		return
 	}
 
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to commit transaction", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -895,28 +914,36 @@ func (h *QuizHandler) UpdateQuizAnswer(c *gin.Context) {
 	})
 }
 
+type CompleteExamResponse struct {
+	Exam       models.Exam `json:"exam"`
+	TotalScore int         `json:"total_score"`
+	IsPassed   int         `json:"is_passed"`
+}
+
+type CompleteExamRequest struct {
+	Id int `json:"id"`
+}
+
 func (h *QuizHandler) CompleteExam(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body CompleteExamRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 
 	// 开始事务
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	if tx.Error != nil {
-		h.logger.Error("Failed to start transaction", tx.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+		h.logger.WithContext(c).Error("Failed to start transaction", tx.Error)
+		response.Fail(c, tx.Error)
 		return
 	}
 
@@ -925,24 +952,24 @@ func (h *QuizHandler) CompleteExam(c *gin.Context) {
 	if err := tx.First(&exam, body.Id).Error; err != nil {
 		tx.Rollback()
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "Exam not found", "data": nil})
+			response.Fail(c, errcode.ErrExamNotFound)
 		} else {
-			h.logger.Error("Failed to find exam", err)
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to find exam", "data": nil})
+			h.logger.WithContext(c).Error("Failed to find exam", err)
+			response.Fail(c, errcode.ErrInternal)
 		}
 		return
 	}
 
 	if exam.StudentId != uid {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 403, "msg": "Permission denied", "data": nil})
+		response.Fail(c, errcode.ErrForbidden)
 		return
 	}
 
 	// 检查考试状态
 	if exam.Status != 2 { // 2表示进行中
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Exam is not in progress", "data": nil})
+		response.Fail(c, errcode.ErrExamNotInProgress)
 		return
 	}
 
@@ -950,8 +977,8 @@ func (h *QuizHandler) CompleteExam(c *gin.Context) {
 	var paper models.Paper
 	if err := tx.First(&paper, exam.PaperId).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to find paper", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to find paper", "data": nil})
+		h.logger.WithContext(c).Error("Failed to find paper", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -959,8 +986,8 @@ func (h *QuizHandler) CompleteExam(c *gin.Context) {
 	var quiz_answers []models.QuizAnswer
 	if err := tx.Where("exam_id = ?", exam.Id).Find(&quiz_answers).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to fetch quiz answers", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch quiz answers", "data": nil})
+		h.logger.WithContext(c).Error("Failed to fetch quiz answers", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -988,52 +1015,55 @@ func (h *QuizHandler) CompleteExam(c *gin.Context) {
 
 	if err := tx.Model(&exam).Updates(updates).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to update exam", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to update exam", "data": nil})
+		h.logger.WithContext(c).Error("Failed to update exam", err)
+		response.Fail(c, err)
 		return
 	}
 
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to commit transaction", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
+	metrics.ExamsCompleted.WithLabelValues(strconv.FormatBool(pass == 1)).Inc()
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "Success",
-		"data": gin.H{
-			"exam":        exam,
-			"total_score": total_score,
-			"is_passed":   pass,
+		"data": CompleteExamResponse{
+			Exam:       exam,
+			TotalScore: total_score,
+			IsPassed:   pass,
 		},
 	})
 }
 
+type GiveUpExamRequest struct {
+	Id int `json:"id"`
+}
+
 func (h *QuizHandler) GiveUpExam(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body GiveUpExamRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 
 	// 开始事务
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	if tx.Error != nil {
-		h.logger.Error("Failed to start transaction", tx.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+		h.logger.WithContext(c).Error("Failed to start transaction", tx.Error)
+		response.Fail(c, tx.Error)
 		return
 	}
 
@@ -1042,24 +1072,24 @@ func (h *QuizHandler) GiveUpExam(c *gin.Context) {
 	if err := tx.First(&exam, body.Id).Error; err != nil {
 		tx.Rollback()
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "Exam not found", "data": nil})
+			response.Fail(c, errcode.ErrExamNotFound)
 		} else {
-			h.logger.Error("Failed to find exam", err)
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to find exam", "data": nil})
+			h.logger.WithContext(c).Error("Failed to find exam", err)
+			response.Fail(c, errcode.ErrInternal)
 		}
 		return
 	}
 
 	if exam.StudentId != uid {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 403, "msg": "Permission denied", "data": nil})
+		response.Fail(c, errcode.ErrForbidden)
 		return
 	}
 
 	// 检查考试状态
 	if exam.Status != 2 { // 2表示进行中
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Exam is not in progress", "data": nil})
+		response.Fail(c, errcode.ErrExamNotInProgress)
 		return
 	}
 
@@ -1072,16 +1102,16 @@ func (h *QuizHandler) GiveUpExam(c *gin.Context) {
 
 	if err := tx.Model(&exam).Updates(updates).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to update exam", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to update exam", "data": nil})
+		h.logger.WithContext(c).Error("Failed to update exam", err)
+		response.Fail(c, err)
 		return
 	}
 
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to commit transaction", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -1092,58 +1122,60 @@ func (h *QuizHandler) GiveUpExam(c *gin.Context) {
 	})
 }
 
+type FetchExamResultRequest struct {
+	Id int `json:"id"`
+}
+
 func (h *QuizHandler) FetchExamResult(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body FetchExamResultRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 
 	// 获取考试信息
 	var exam models.Exam
-	if err := h.db.First(&exam, body.Id).Error; err != nil {
+	if err := h.db.WithContext(c).First(&exam, body.Id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "Exam not found", "data": nil})
+			response.Fail(c, errcode.ErrExamNotFound)
 		} else {
-			h.logger.Error("Failed to fetch exam", err)
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch exam", "data": nil})
+			h.logger.WithContext(c).Error("Failed to fetch exam", err)
+			response.Fail(c, errcode.ErrInternal)
 		}
 		return
 	}
 
 	// 检查权限
 	if exam.StudentId != uid {
-		c.JSON(http.StatusOK, gin.H{"code": 403, "msg": "Permission denied", "data": nil})
+		response.Fail(c, errcode.ErrForbidden)
 		return
 	}
 
 	// 获取试卷信息
 	var paper models.Paper
-	if err := h.db.First(&paper, exam.PaperId).Error; err != nil {
-		h.logger.Error("Failed to fetch paper", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch paper", "data": nil})
+	if err := h.db.WithContext(c).First(&paper, exam.PaperId).Error; err != nil {
+		h.logger.WithContext(c).Error("Failed to fetch paper", err)
+		response.Fail(c, err)
 		return
 	}
 
 	// 获取答题记录
 	var quiz_answers []models.QuizAnswer
-	if err := h.db.Where("exam_id = ?", exam.Id).Preload("Quiz").Order("id asc").Find(&quiz_answers).Error; err != nil {
-		h.logger.Error("Failed to fetch quiz answers", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch quiz answers", "data": nil})
+	if err := h.db.WithContext(c).Where("exam_id = ?", exam.Id).Preload("Quiz").Order("id asc").Find(&quiz_answers).Error; err != nil {
+		h.logger.WithContext(c).Error("Failed to fetch quiz answers", err)
+		response.Fail(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "",
-		"data": gin.H{
-			"exam":         exam,
-			"paper":        paper,
-			"quiz_answers": quiz_answers,
+		"data": ExamDetailResponse{
+			Exam:        exam,
+			Paper:       paper,
+			QuizAnswers: quiz_answers,
 		},
 	})
 }
@@ -8,7 +8,9 @@ import (
 	"gorm.io/gorm"
 
 	"myapi/internal/models"
+	"myapi/internal/pkg/errcode"
 	"myapi/internal/pkg/pagination"
+	"myapi/internal/pkg/response"
 	"myapi/pkg/logger"
 )
 
@@ -24,48 +26,47 @@ func NewReportHandler(db *gorm.DB, logger *logger.Logger) *ReportHandler {
 	}
 }
 
+type FetchReportListRequest struct {
+	models.Pagination
+}
+
 func (h *ReportHandler) FetchReportList(c *gin.Context) {
-	var body struct {
-		models.Pagination
-	}
+	var body FetchReportListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
-	query := h.db
+	query := h.db.WithContext(c)
 	pb := pagination.NewPaginationBuilder[models.CoachReport](query).
 		SetLimit(body.PageSize).
 		SetPage(body.Page).
 		SetOrderBy("created_at DESC")
 	var list1 []models.CoachReport
 	if err := pb.Build().Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch discount policies", "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "",
-		"data": gin.H{
-			"list":        list2,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list2, has_more, next_marker),
 	})
 }
 
+type FetchMineReportListRequest struct {
+	models.Pagination
+}
+
 func (h *ReportHandler) FetchMineReportList(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		models.Pagination
-	}
+	var body FetchMineReportListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
-	query := h.db
+	query := h.db.WithContext(c)
 	query = query.Where("d != 0 AND coach_id = ?", uid)
 	pb := pagination.NewPaginationBuilder[models.CoachReport](query).
 		SetLimit(body.PageSize).
@@ -73,52 +74,49 @@ func (h *ReportHandler) FetchMineReportList(c *gin.Context) {
 		SetOrderBy("created_at DESC")
 	var list1 []models.CoachReport
 	if err := pb.Build().Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch discount policies", "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "",
-		"data": gin.H{
-			"list":        list2,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list2, has_more, next_marker),
 	})
 }
 
+type CreateReportRequest struct {
+	Content    string  `json:"content"`
+	ReasonType *string `json:"reason_type"`
+	ReasonId   *int    `json:"reason_id"`
+}
+
 func (h *ReportHandler) CreateReport(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		Content    string  `json:"content"`
-		ReasonType *string `json:"reason_type"`
-		ReasonId   *int    `json:"reason_id"`
-	}
+	var body CreateReportRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 
 	// 验证 Content 字段
 	if body.Content == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Content cannot be empty", "data": nil})
+		response.Fail(c, errcode.ErrContentRequired)
 		return
 	}
 
 	// 开始事务
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	if tx.Error != nil {
-		h.logger.Error("Failed to start transaction", tx.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+		h.logger.WithContext(c).Error("Failed to start transaction", tx.Error)
+		response.Fail(c, tx.Error)
 		return
 	}
 
@@ -143,16 +141,16 @@ func (h *ReportHandler) CreateReport(c *gin.Context) {
 
 	if err := tx.Create(&record).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to create record", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to create record", "data": nil})
+		h.logger.WithContext(c).Error("Failed to create record", err)
+		response.Fail(c, err)
 		return
 	}
 
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to commit transaction", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -163,24 +161,26 @@ func (h *ReportHandler) CreateReport(c *gin.Context) {
 	})
 }
 
+type FetchReportProfileRequest struct {
+	Id int `json:"id"`
+}
+
 func (h *ReportHandler) FetchReportProfile(c *gin.Context) {
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body FetchReportProfileRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 
 	var record models.CoachReport
 
-	if r := h.db.Where("id = ?", body.Id).First(&record); r.Error != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": r.Error.Error(), "data": nil})
+	if r := h.db.WithContext(c).Where("id = ?", body.Id).First(&record); r.Error != nil {
+		response.Fail(c, r.Error)
 		return
 	}
 
 	if record.D == 1 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "记录不存在", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 
@@ -3,7 +3,9 @@ package handlers
 import (
 	"fmt"
 	"myapi/internal/models"
+	"myapi/internal/pkg/errcode"
 	"myapi/internal/pkg/pagination"
+	"myapi/internal/pkg/response"
 	"myapi/pkg/logger"
 	"net/http"
 	"sort"
@@ -25,57 +27,65 @@ func NewSubscriptionHandler(db *gorm.DB, logger *logger.Logger) *SubscriptionHan
 	}
 }
 
+type FetchSubscriptionPlanListRequest struct {
+	models.Pagination
+}
+
+type FetchSubscriptionPlanListResponse struct {
+	List []models.SubscriptionPlan `json:"list"`
+}
+
 func (h *SubscriptionHandler) FetchSubscriptionPlanList(c *gin.Context) {
-	var body struct {
-		models.Pagination
-	}
+	var body FetchSubscriptionPlanListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
-	query := h.db
+	query := h.db.WithContext(c)
 	var plans []models.SubscriptionPlan
 	if err := query.Find(&plans).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch discount policies", "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "",
-		"data": gin.H{
-			"list": plans,
+		"data": FetchSubscriptionPlanListResponse{
+			List: plans,
 		},
 	})
 }
 
+type CreateSubscriptionPlanRequest struct {
+	Name             string `json:"name"`
+	Details          string `json:"details"`
+	UnitPrice        int    `json:"unit_price"`
+	DiscountPolicies []struct {
+		Name         string `json:"name"`
+		Rate         int    `json:"rate"`
+		CountRequire int    `json:"count_require"`
+		Enabled      int    `json:"enabled"`
+	} `json:"discount_policies"`
+}
+
 func (h *SubscriptionHandler) CreateSubscriptionPlan(c *gin.Context) {
-	var body struct {
-		Name             string `json:"name"`
-		Details          string `json:"details"`
-		UnitPrice        int    `json:"unit_price"`
-		DiscountPolicies []struct {
-			Name         string `json:"name"`
-			Rate         int    `json:"rate"`
-			CountRequire int    `json:"count_require"`
-			Enabled      int    `json:"enabled"`
-		} `json:"discount_policies"`
-	}
+	var body CreateSubscriptionPlanRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 
 	// 开始事务
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	if tx.Error != nil {
-		h.logger.Error("Failed to start transaction", tx.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+		h.logger.WithContext(c).Error("Failed to start transaction", tx.Error)
+		response.Fail(c, tx.Error)
 		return
 	}
 
@@ -89,8 +99,8 @@ func (h *SubscriptionHandler) CreateSubscriptionPlan(c *gin.Context) {
 
 	if err := tx.Create(&subscription_plan).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to create subscription plan", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to create subscription plan", "data": nil})
+		h.logger.WithContext(c).Error("Failed to create subscription plan", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -106,8 +116,8 @@ func (h *SubscriptionHandler) CreateSubscriptionPlan(c *gin.Context) {
 
 		if err := tx.Create(&discount_policy).Error; err != nil {
 			tx.Rollback()
-			h.logger.Error("Failed to create discount policy", err)
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to create discount policy", "data": nil})
+			h.logger.WithContext(c).Error("Failed to create discount policy", err)
+			response.Fail(c, err)
 			return
 		}
 
@@ -120,8 +130,8 @@ func (h *SubscriptionHandler) CreateSubscriptionPlan(c *gin.Context) {
 
 		if err := tx.Create(&plan_policy).Error; err != nil {
 			tx.Rollback()
-			h.logger.Error("Failed to create subscription plan discount policy", err)
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to create subscription plan discount policy", "data": nil})
+			h.logger.WithContext(c).Error("Failed to create subscription plan discount policy", err)
+			response.Fail(c, err)
 			return
 		}
 	}
@@ -129,8 +139,8 @@ func (h *SubscriptionHandler) CreateSubscriptionPlan(c *gin.Context) {
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to commit transaction", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -141,36 +151,38 @@ func (h *SubscriptionHandler) CreateSubscriptionPlan(c *gin.Context) {
 	})
 }
 
+type UpdateSubscriptionPlanRequest struct {
+	Id               int    `json:"id"`
+	Name             string `json:"name"`
+	Details          string `json:"details"`
+	UnitPrice        int    `json:"unit_price"`
+	DiscountPolicies []struct {
+		Id           int    `json:"id"`
+		Name         string `json:"name"`
+		Rate         int    `json:"rate"`
+		CountRequire int    `json:"count_require"`
+		Enabled      int    `json:"enabled"`
+	} `json:"discount_policies"`
+}
+
 func (h *SubscriptionHandler) UpdateSubscriptionPlan(c *gin.Context) {
-	var body struct {
-		Id               int    `json:"id"`
-		Name             string `json:"name"`
-		Details          string `json:"details"`
-		UnitPrice        int    `json:"unit_price"`
-		DiscountPolicies []struct {
-			Id           int    `json:"id"`
-			Name         string `json:"name"`
-			Rate         int    `json:"rate"`
-			CountRequire int    `json:"count_require"`
-			Enabled      int    `json:"enabled"`
-		} `json:"discount_policies"`
-	}
+	var body UpdateSubscriptionPlanRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 
 	// 开始事务
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	if tx.Error != nil {
-		h.logger.Error("Failed to start transaction", tx.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+		h.logger.WithContext(c).Error("Failed to start transaction", tx.Error)
+		response.Fail(c, tx.Error)
 		return
 	}
 
@@ -184,16 +196,16 @@ func (h *SubscriptionHandler) UpdateSubscriptionPlan(c *gin.Context) {
 
 	if err := tx.Model(&models.SubscriptionPlan{}).Where("id = ?", body.Id).Updates(subscription_plan).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to update subscription plan", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to update subscription plan", "data": nil})
+		h.logger.WithContext(c).Error("Failed to update subscription plan", err)
+		response.Fail(c, err)
 		return
 	}
 
 	// 删除旧的折扣政策关联
 	if err := tx.Where("subscription_plan_id = ?", body.Id).Delete(&models.SubscriptionPlanDiscountPolicy{}).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to delete old discount policies", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to delete old discount policies", "data": nil})
+		h.logger.WithContext(c).Error("Failed to delete old discount policies", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -209,8 +221,8 @@ func (h *SubscriptionHandler) UpdateSubscriptionPlan(c *gin.Context) {
 
 		if err := tx.Save(&discount_policy).Error; err != nil {
 			tx.Rollback()
-			h.logger.Error("Failed to update discount policy", err)
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to update discount policy", "data": nil})
+			h.logger.WithContext(c).Error("Failed to update discount policy", err)
+			response.Fail(c, err)
 			return
 		}
 
@@ -223,8 +235,8 @@ func (h *SubscriptionHandler) UpdateSubscriptionPlan(c *gin.Context) {
 
 		if err := tx.Create(&plan_policy).Error; err != nil {
 			tx.Rollback()
-			h.logger.Error("Failed to create subscription plan discount policy", err)
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to create subscription plan discount policy", "data": nil})
+			h.logger.WithContext(c).Error("Failed to create subscription plan discount policy", err)
+			response.Fail(c, err)
 			return
 		}
 	}
@@ -232,8 +244,8 @@ func (h *SubscriptionHandler) UpdateSubscriptionPlan(c *gin.Context) {
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to commit transaction", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
 
@@ -244,13 +256,27 @@ func (h *SubscriptionHandler) UpdateSubscriptionPlan(c *gin.Context) {
 	})
 }
 
+type CalcSubscriptionOrderAmountRequest struct {
+	SubscriptionPlanId int    `json:"subscription_plan_id"`
+	Type               string `json:"type"`
+}
+
+type DiscountText struct {
+	Value int    `json:"value"`
+	Text  string `json:"text"`
+}
+
+type CalcSubscriptionOrderAmountResponse struct {
+	TotalAmount   int            `json:"total_amount"`
+	Amount        int            `json:"amount"`
+	DiscountTexts []DiscountText `json:"discount_texts"`
+	Text          string         `json:"text"`
+}
+
 func (h *SubscriptionHandler) CalcSubscriptionOrderAmount(c *gin.Context) {
-	var body struct {
-		SubscriptionPlanId int    `json:"subscription_plan_id"`
-		Type               string `json:"type"`
-	}
+	var body CalcSubscriptionOrderAmountRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	count := 30
@@ -262,17 +288,17 @@ func (h *SubscriptionHandler) CalcSubscriptionOrderAmount(c *gin.Context) {
 	}
 
 	var plan models.SubscriptionPlan
-	if err := h.db.First(&plan, body.SubscriptionPlanId).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch subscription plan", "data": nil})
+	if err := h.db.WithContext(c).First(&plan, body.SubscriptionPlanId).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
 
 	// Get discount policies
 	var discount_policies []models.SubscriptionPlanDiscountPolicy
-	if err := h.db.Where("subscription_plan_id = ? AND enabled = 1", body.SubscriptionPlanId).
+	if err := h.db.WithContext(c).Where("subscription_plan_id = ? AND enabled = 1", body.SubscriptionPlanId).
 		Preload("DiscountPolicy").
 		Find(&discount_policies).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch discount policies", "data": nil})
+		response.Fail(c, err)
 		return
 	}
 
@@ -304,30 +330,32 @@ func (h *SubscriptionHandler) CalcSubscriptionOrderAmount(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "",
-		"data": gin.H{
-			"total_amount": total_amount,
-			"amount":       amount,
-			"discount_texts": []gin.H{
+		"data": CalcSubscriptionOrderAmountResponse{
+			TotalAmount: total_amount,
+			Amount:      amount,
+			DiscountTexts: []DiscountText{
 				{
-					"value": discount,
-					"text":  fmt.Sprintf("满%.1f年打%.2f折", float64(count)/360, float64(rate)/100),
+					Value: discount,
+					Text:  fmt.Sprintf("满%.1f年打%.2f折", float64(count)/360, float64(rate)/100),
 				},
 			},
-			"text": text,
+			Text: text,
 		},
 	})
 }
 
+type FetchSubscriptionListRequest struct {
+	models.Pagination
+}
+
 func (h *SubscriptionHandler) FetchSubscriptionList(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		models.Pagination
-	}
+	var body FetchSubscriptionListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
-	query := h.db
+	query := h.db.WithContext(c)
 	query = query.Where("coach_id = ?", uid).Preload("SubscriptionPlan")
 	pb := pagination.NewPaginationBuilder[models.Subscription](query).
 		SetLimit(body.PageSize).
@@ -335,18 +363,13 @@ func (h *SubscriptionHandler) FetchSubscriptionList(c *gin.Context) {
 		SetOrderBy("created_at DESC")
 	var list1 []models.Subscription
 	if err := pb.Build().Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "",
-		"data": gin.H{
-			"list":        list2,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list2, has_more, next_marker),
 	})
 }
@@ -8,6 +8,8 @@ import (
 	"gorm.io/gorm"
 
 	"myapi/internal/models"
+	"myapi/internal/pkg/errcode"
+	"myapi/internal/pkg/response"
 	"myapi/pkg/logger"
 )
 
@@ -28,10 +30,10 @@ func NewUserHandler(db *gorm.DB, logger *logger.Logger) *UserHandler {
 // GetUsers 获取所有用户
 func (h *UserHandler) GetUsers(c *gin.Context) {
 	var users []models.User
-	result := h.db.Find(&users)
+	result := h.db.WithContext(c).Find(&users)
 	if result.Error != nil {
-		h.logger.Error("Failed to fetch users", result.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch users", "data": nil})
+		h.logger.WithContext(c).Error("Failed to fetch users", result.Error)
+		response.Fail(c, result.Error)
 		return
 	}
 
@@ -42,19 +44,19 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 func (h *UserHandler) GetUser(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid user ID", "data": nil})
+		response.Fail(c, errcode.ErrInvalidParam)
 		return
 	}
 
 	var user models.User
-	result := h.db.First(&user, id)
+	result := h.db.WithContext(c).First(&user, id)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "User not found", "data": nil})
+			response.Fail(c, errcode.ErrUserNotFound)
 			return
 		}
-		h.logger.Error("Failed to fetch user", result.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch user", "data": nil})
+		h.logger.WithContext(c).Error("Failed to fetch user", result.Error)
+		response.Fail(c, errcode.ErrInternal)
 		return
 	}
 
@@ -65,14 +67,14 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var user models.User
 	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 
-	result := h.db.Create(&user)
+	result := h.db.WithContext(c).Create(&user)
 	if result.Error != nil {
-		h.logger.Error("Failed to create user", result.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to create user", "data": nil})
+		h.logger.WithContext(c).Error("Failed to create user", result.Error)
+		response.Fail(c, result.Error)
 		return
 	}
 
@@ -83,30 +85,30 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid user ID", "data": nil})
+		response.Fail(c, errcode.ErrInvalidParam)
 		return
 	}
 
 	var user models.User
-	if result := h.db.First(&user, id); result.Error != nil {
+	if result := h.db.WithContext(c).First(&user, id); result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "User not found", "data": nil})
+			response.Fail(c, errcode.ErrUserNotFound)
 			return
 		}
-		h.logger.Error("Failed to fetch user for update", result.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch user", "data": nil})
+		h.logger.WithContext(c).Error("Failed to fetch user for update", result.Error)
+		response.Fail(c, errcode.ErrInternal)
 		return
 	}
 
 	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 
-	result := h.db.Save(&user)
+	result := h.db.WithContext(c).Save(&user)
 	if result.Error != nil {
-		h.logger.Error("Failed to update user", result.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to update user", "data": nil})
+		h.logger.WithContext(c).Error("Failed to update user", result.Error)
+		response.Fail(c, result.Error)
 		return
 	}
 
@@ -117,19 +119,19 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid user ID", "data": nil})
+		response.Fail(c, errcode.ErrInvalidParam)
 		return
 	}
 
-	result := h.db.Delete(&models.User{}, id)
+	result := h.db.WithContext(c).Delete(&models.User{}, id)
 	if result.Error != nil {
-		h.logger.Error("Failed to delete user", result.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to delete user", "data": nil})
+		h.logger.WithContext(c).Error("Failed to delete user", result.Error)
+		response.Fail(c, result.Error)
 		return
 	}
 
 	if result.RowsAffected == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "User not found", "data": nil})
+		response.Fail(c, errcode.ErrUserNotFound)
 		return
 	}
 
@@ -10,7 +10,9 @@ import (
 	"gorm.io/gorm"
 
 	"myapi/internal/models"
+	"myapi/internal/pkg/errcode"
 	"myapi/internal/pkg/pagination"
+	"myapi/internal/pkg/response"
 	"myapi/pkg/logger"
 )
 
@@ -28,21 +30,51 @@ func NewWorkoutActionHandler(db *gorm.DB, logger *logger.Logger) *WorkoutActionH
 	}
 }
 
-func (h *WorkoutActionHandler) FetchWorkoutActionList(c *gin.Context) {
-	var body struct {
-		models.Pagination
-		Type    string `json:"type"`
-		Keyword string `json:"keyword"`
-		Tag     string `json:"tag"`
-		Level   string `json:"level"`
-		Muscle  string `json:"muscle"`
+// WorkoutActionItem 动作列表里的一项
+type WorkoutActionItem struct {
+	Id           int       `json:"id"`
+	Name         string    `json:"name"`
+	ZhName       string    `json:"zh_name"`
+	MuscleIds    string    `json:"muscle_ids"`
+	EquipmentIds string    `json:"equipment_ids"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func newWorkoutActionItem(v models.WorkoutAction) WorkoutActionItem {
+	return WorkoutActionItem{
+		Id:           v.Id,
+		Name:         v.Name,
+		ZhName:       v.ZhName,
+		MuscleIds:    v.MuscleIds,
+		EquipmentIds: v.EquipmentIds,
+		CreatedAt:    v.CreatedAt,
 	}
+}
+
+// WorkoutActionListItem 分页列表里的动作，多了排序和标签
+type WorkoutActionListItem struct {
+	WorkoutActionItem
+	Idx  int    `json:"idx"`
+	Tags string `json:"tags"`
+}
+
+type FetchWorkoutActionListRequest struct {
+	models.Pagination
+	Type    string `json:"type"`
+	Keyword string `json:"keyword"`
+	Tag     string `json:"tag"`
+	Level   string `json:"level"`
+	Muscle  string `json:"muscle"`
+}
+
+func (h *WorkoutActionHandler) FetchWorkoutActionList(c *gin.Context) {
+	var body FetchWorkoutActionListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 
-	query := h.db.Where("d IS NULL OR d = 0")
+	query := h.db.WithContext(c).Where("d IS NULL OR d = 0")
 	if body.Type != "" {
 		query = query.Where("type = ?", body.Type)
 	}
@@ -68,47 +100,39 @@ func (h *WorkoutActionHandler) FetchWorkoutActionList(c *gin.Context) {
 		SetOrderBy("sort_idx DESC")
 	var list1 []models.WorkoutAction
 	if err := pb.Build().Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch workout actions", "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_cursor := pb.ProcessResults(list1)
-	list := make([]map[string]interface{}, 0, len(list2))
+	list := make([]WorkoutActionListItem, 0, len(list2))
 	for _, v := range list2 {
-		list = append(list, map[string]interface{}{
-			"id":            v.Id,
-			"name":          v.Name,
-			"zh_name":       v.ZhName,
-			"idx":           v.SortIdx,
-			"tags":          v.Tags1,
-			"muscle_ids":    v.MuscleIds,
-			"equipment_ids": v.EquipmentIds,
-			"created_at":    v.CreatedAt,
+		list = append(list, WorkoutActionListItem{
+			WorkoutActionItem: newWorkoutActionItem(v),
+			Idx:               v.SortIdx,
+			Tags:              v.Tags1,
 		})
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "Success",
-		"data": gin.H{
-			"list":        list,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_cursor,
-		},
+		"data": pagination.NewList(pb, list, has_more, next_cursor),
 	})
 }
 
+type FetchCardioWorkoutActionListRequest struct {
+	models.Pagination
+	Keyword string `json:"keyword"`
+	Tag     string `json:"tag"`
+}
+
 func (h *WorkoutActionHandler) FetchCardioWorkoutActionList(c *gin.Context) {
-	var body struct {
-		models.Pagination
-		Keyword string `json:"keyword"`
-		Tag     string `json:"tag"`
-	}
+	var body FetchCardioWorkoutActionListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 
-	query := h.db.Where("d IS NULL OR d = 0")
+	query := h.db.WithContext(c).Where("d IS NULL OR d = 0")
 	query = query.Where("type = ?", "cardio")
 	if body.Keyword != "" {
 		query = query.Where("zh_name LIKE ? OR alias LIKE ?", "%"+body.Keyword+"%", "%"+body.Keyword+"%")
@@ -123,90 +147,79 @@ func (h *WorkoutActionHandler) FetchCardioWorkoutActionList(c *gin.Context) {
 		SetOrderBy("sort_idx DESC")
 	var list1 []models.WorkoutAction
 	if err := pb.Build().Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch workout actions", "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_cursor := pb.ProcessResults(list1)
-	list := make([]map[string]interface{}, 0, len(list2))
+	list := make([]WorkoutActionListItem, 0, len(list2))
 	for _, v := range list2 {
-		list = append(list, map[string]interface{}{
-			"id":            v.Id,
-			"name":          v.Name,
-			"zh_name":       v.ZhName,
-			"idx":           v.SortIdx,
-			"tags":          v.Tags2,
-			"muscle_ids":    v.MuscleIds,
-			"equipment_ids": v.EquipmentIds,
-			"created_at":    v.CreatedAt,
+		list = append(list, WorkoutActionListItem{
+			WorkoutActionItem: newWorkoutActionItem(v),
+			Idx:               v.SortIdx,
+			Tags:              v.Tags2,
 		})
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "Success",
-		"data": gin.H{
-			"list":        list,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_cursor,
-		},
+		"data": pagination.NewList(pb, list, has_more, next_cursor),
 	})
 }
 
+type FetchWorkoutActionListByIdsRequest struct {
+	Ids []int `json:"ids"`
+}
+
+type FetchWorkoutActionListByIdsResponse struct {
+	List []WorkoutActionItem `json:"list"`
+}
+
 func (h *WorkoutActionHandler) FetchWorkoutActionListByIds(c *gin.Context) {
 
-	var body struct {
-		Ids []int `json:"ids"`
-	}
+	var body FetchWorkoutActionListByIdsRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	if len(body.Ids) == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "ids 不能为空", "data": nil})
+		response.Fail(c, errcode.MissingParam("ids"))
 		return
 	}
-	query := h.db.Where("d IS NULL OR d = 0")
+	query := h.db.WithContext(c).Where("d IS NULL OR d = 0")
 	query = query.Where("id IN (?)", body.Ids)
 	var list1 []models.WorkoutAction
 	if err := query.Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch workout actions", "data": nil})
+		response.Fail(c, err)
 		return
 	}
-	list := make([]map[string]interface{}, 0, len(list1))
+	list := make([]WorkoutActionItem, 0, len(list1))
 	for _, v := range list1 {
-		list = append(list, map[string]interface{}{
-			"id":            v.Id,
-			"name":          v.Name,
-			"zh_name":       v.ZhName,
-			"muscle_ids":    v.MuscleIds,
-			"equipment_ids": v.EquipmentIds,
-			"created_at":    v.CreatedAt,
-		})
+		list = append(list, newWorkoutActionItem(v))
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "Success",
-		"data": gin.H{
-			"list": list,
-		},
+		"data": FetchWorkoutActionListByIdsResponse{List: list},
 	})
 }
 
+type GetWorkoutActionRequest struct {
+	Id int `json:"id"`
+}
+
 // GetWorkoutAction retrieves a specific workout action by ID
 func (h *WorkoutActionHandler) GetWorkoutAction(c *gin.Context) {
 	// id := c.Param("id")
-	var request struct {
-		Id int `json:"id"`
-	}
+	var request GetWorkoutActionRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 
 	var action models.WorkoutAction
-	result := h.db.First(&action, request.Id)
+	result := h.db.WithContext(c).First(&action, request.Id)
 	if result.Error != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "Workout action not found", "data": nil})
+		response.Fail(c, errcode.ErrWorkoutActionMissing)
 		return
 	}
 
@@ -218,9 +231,9 @@ func (h *WorkoutActionHandler) GetActionsByMuscle(c *gin.Context) {
 	muscleId := c.Param("muscleId")
 
 	var actions []models.WorkoutAction
-	result := h.db.Where("target_muscle_ids LIKE ?", "%"+muscleId+"%").Find(&actions)
+	result := h.db.WithContext(c).Where("target_muscle_ids LIKE ?", "%"+muscleId+"%").Find(&actions)
 	if result.Error != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch workout actions", "data": nil})
+		response.Fail(c, result.Error)
 		return
 	}
 
@@ -231,53 +244,60 @@ func (h *WorkoutActionHandler) FetchWorkoutActionsByLevel(c *gin.Context) {
 	levelStr := c.Param("level")
 	level, err := strconv.Atoi(levelStr)
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid level parameter", "data": nil})
+		response.Fail(c, errcode.ErrInvalidParam)
 		return
 	}
 
 	var actions []models.WorkoutAction
-	result := h.db.Where("level = ?", level).Find(&actions)
+	result := h.db.WithContext(c).Where("level = ?", level).Find(&actions)
 	if result.Error != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch workout actions", "data": nil})
+		response.Fail(c, result.Error)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "Success", "data": actions})
 }
 
+type FetchRelatedWorkoutActionsRequest struct {
+	Id int `json:"id"`
+}
+
+type FetchRelatedWorkoutActionsResponse struct {
+	Advanced  []models.WorkoutAction `json:"advanced"`
+	Regressed []models.WorkoutAction `json:"regressed"`
+}
+
 // 获取指定动作的 进阶、退阶、替代动作
 func (h *WorkoutActionHandler) FetchRelatedWorkoutActions(c *gin.Context) {
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body FetchRelatedWorkoutActionsRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	var action models.WorkoutAction
-	r := h.db.First(&action, body.Id)
+	r := h.db.WithContext(c).First(&action, body.Id)
 	if r.Error != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "Workout action not found", "data": nil})
+		response.Fail(c, errcode.ErrWorkoutActionMissing)
 		return
 	}
 	// Get advanced actions
 	var advanced_workout_actions []models.WorkoutAction
 	if action.AdvancedActionIds != "" {
 		advanced_ids := strings.Split(action.AdvancedActionIds, ",")
-		h.db.Where("id IN ?", advanced_ids).Find(&advanced_workout_actions)
+		h.db.WithContext(c).Where("id IN ?", advanced_ids).Find(&advanced_workout_actions)
 	}
 	// Get regressed actions
 	var regressed_actions []models.WorkoutAction
 	if action.RegressedActionIds != "" {
 		regressed_ids := strings.Split(action.RegressedActionIds, ",")
-		h.db.Where("id IN ?", regressed_ids).Find(&regressed_actions)
+		h.db.WithContext(c).Where("id IN ?", regressed_ids).Find(&regressed_actions)
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "Success",
-		"data": gin.H{
-			"advanced":  advanced_workout_actions,
-			"regressed": regressed_actions,
+		"data": FetchRelatedWorkoutActionsResponse{
+			Advanced:  advanced_workout_actions,
+			Regressed: regressed_actions,
 		},
 	})
 }
@@ -311,24 +331,24 @@ type WorkoutActionBody struct {
 func (h *WorkoutActionHandler) CreateWorkoutAction(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 	if uid == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "请先登录", "data": nil})
+		response.Fail(c, errcode.ErrUnauthorized)
 		return
 	}
 	var body WorkoutActionBody
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	var existing models.WorkoutAction
-	if err := h.db.Where("zh_name = ?", body.ZhName).First(&existing).Error; err != nil {
+	if err := h.db.WithContext(c).Where("zh_name = ?", body.ZhName).First(&existing).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
 		if existing.Id != 0 {
-			c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "已存在同名动作", "data": gin.H{
+			response.FailWithData(c, errcode.ErrWorkoutActionExists, gin.H{
 				"id": existing.Id,
-			}})
+			})
 			return
 		}
 	}
@@ -360,34 +380,36 @@ func (h *WorkoutActionHandler) CreateWorkoutAction(c *gin.Context) {
 		OwnerId:              uid,
 		CreatedAt:            time.Now(),
 	}
-	if err := h.db.Create(&data).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to create", "data": nil})
+	if err := h.db.WithContext(c).Create(&data).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": body})
 }
 
+type UpdateWorkoutActionProfileRequest struct {
+	WorkoutActionBody
+	Id int `json:"id"`
+}
+
 func (h *WorkoutActionHandler) UpdateWorkoutActionProfile(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 	if uid == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "请先登录", "data": nil})
+		response.Fail(c, errcode.ErrUnauthorized)
 		return
 	}
-	var body struct {
-		WorkoutActionBody
-		Id int `json:"id"`
-	}
+	var body UpdateWorkoutActionProfileRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	var existing models.WorkoutAction
-	if err := h.db.First(&existing, body.Id).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "没有找到记录", "data": nil})
+	if err := h.db.WithContext(c).First(&existing, body.Id).Error; err != nil {
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 	now := time.Now().UTC()
-	if err := h.db.Model(&existing).Updates(map[string]interface{}{
+	if err := h.db.WithContext(c).Model(&existing).Updates(map[string]interface{}{
 		"name":                   body.Name,
 		"zh_name":                body.ZhName,
 		"alias":                  body.Alias,
@@ -414,87 +436,93 @@ func (h *WorkoutActionHandler) UpdateWorkoutActionProfile(c *gin.Context) {
 		"owner_id":               uid,
 		"updated_at":             now,
 	}).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "更新成功", "data": body})
 }
 
+type UpdateWorkoutActionIdxRequest struct {
+	Id  int `json:"id"`
+	Idx int `json:"idx"`
+}
+
 func (h *WorkoutActionHandler) UpdateWorkoutActionIdx(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 	if uid == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "请先登录", "data": nil})
+		response.Fail(c, errcode.ErrUnauthorized)
 		return
 	}
-	var body struct {
-		Id  int `json:"id"`
-		Idx int `json:"idx"`
-	}
+	var body UpdateWorkoutActionIdxRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	var existing models.WorkoutAction
-	if err := h.db.First(&existing, body.Id).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "没有找到记录", "data": nil})
+	if err := h.db.WithContext(c).First(&existing, body.Id).Error; err != nil {
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 	now := time.Now().UTC()
-	if err := h.db.Model(&existing).Updates(map[string]interface{}{
+	if err := h.db.WithContext(c).Model(&existing).Updates(map[string]interface{}{
 		"sort_idx":   body.Idx,
 		"updated_at": now,
 	}).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "更新成功", "data": body})
 }
 
+type DeleteWorkoutActionRequest struct {
+	Id int `json:"id"`
+}
+
 func (h *WorkoutActionHandler) DeleteWorkoutAction(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 	if uid == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "请先登录", "data": nil})
+		response.Fail(c, errcode.ErrUnauthorized)
 		return
 	}
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body DeleteWorkoutActionRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少id参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
 	var record models.WorkoutAction
-	if err := h.db.Where("id = ?", body.Id).First(&record).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "没有找到记录", "data": nil})
+	if err := h.db.WithContext(c).Where("id = ?", body.Id).First(&record).Error; err != nil {
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
-	if err := h.db.Model(&record).Update("d", 1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Model(&record).Update("d", 1).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "操作成功", "data": nil})
 }
 
+type CreateContentWithWorkoutActionRequest struct {
+	ContentId       int `json:"content_id"`
+	WorkoutActionId int `json:"workout_action_id"`
+	StartPoint      int `json:"start_point"`
+}
+
 func (h *WorkoutActionHandler) CreateContentWithWorkoutAction(c *gin.Context) {
-	var body struct {
-		ContentId       int `json:"content_id"`
-		WorkoutActionId int `json:"workout_action_id"`
-		StartPoint      int `json:"start_point"`
-	}
+	var body CreateContentWithWorkoutActionRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	if body.ContentId == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少 Content id 参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("content_id"))
 		return
 	}
 	if body.WorkoutActionId == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少 WorkoutAction id 参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("workout_action_id"))
 		return
 	}
 	content_with_act := models.CoachContentWithWorkoutAction{
@@ -503,30 +531,42 @@ func (h *WorkoutActionHandler) CreateContentWithWorkoutAction(c *gin.Context) {
 		CoachContentId:  body.ContentId,
 		CreatedAt:       time.Now(),
 	}
-	if err := h.db.Create(&content_with_act).Error; err != nil {
-		h.logger.Error("Failed to create CoachContentWithWorkoutAction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Create(&content_with_act).Error; err != nil {
+		h.logger.WithContext(c).Error("Failed to create CoachContentWithWorkoutAction", err)
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "关联成功", "data": nil})
 }
 
+type FetchContentListOfWorkoutActionRequest struct {
+	models.Pagination
+	WorkoutActionId int `json:"workout_action_id"`
+}
+
+// WorkoutActionContentItem 关联了动作的视频，time 是动作在视频里的开始时间
+type WorkoutActionContentItem struct {
+	Id       int        `json:"id"`
+	Title    string     `json:"title"`
+	Overview string     `json:"overview"`
+	VideoURL string     `json:"video_url"`
+	Time     int        `json:"time"`
+	Creator  CoachBrief `json:"creator"`
+}
+
 func (h *WorkoutActionHandler) FetchContentListOfWorkoutAction(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		models.Pagination
-		WorkoutActionId int `json:"workout_action_id"`
-	}
+	var body FetchContentListOfWorkoutActionRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.WorkoutActionId == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少 WorkoutActionId 参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("workout_action_id"))
 		return
 	}
-	query := h.db.Where("coach_content_with_workout_action.d IS NULL OR coach_content_with_workout_action.d = 0")
+	query := h.db.WithContext(c).Where("coach_content_with_workout_action.d IS NULL OR coach_content_with_workout_action.d = 0")
 	if uid != 0 {
 		query = query.Joins("JOIN coach_content ON coach_content.id = coach_content_with_workout_action.coach_content_id").Where("(coach_content_with_workout_action.status = 1) OR (coach_content_with_workout_action.status = 2 AND coach_content.coach_id = ?)", uid)
 	} else {
@@ -541,35 +581,26 @@ func (h *WorkoutActionHandler) FetchContentListOfWorkoutAction(c *gin.Context) {
 
 	var list1 []models.CoachContentWithWorkoutAction
 	if err := pb.Build().Preload("Content").Preload("Content.Coach").Preload("Content.Coach.Profile1").Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
 
-	list := make([]map[string]interface{}, 0, len(list2))
+	list := make([]WorkoutActionContentItem, 0, len(list2))
 	for _, v := range list2 {
-		list = append(list, map[string]interface{}{
-			"id":        v.Content.Id,
-			"title":     v.Content.Title,
-			"overview":  v.Content.Description,
-			"video_url": v.Content.VideoKey,
-			"time":      v.StartPoint,
-			"creator": map[string]interface{}{
-				"nickname":   v.Content.Coach.Profile1.Nickname,
-				"avatar_url": v.Content.Coach.Profile1.AvatarURL,
-			},
+		list = append(list, WorkoutActionContentItem{
+			Id:       v.Content.Id,
+			Title:    v.Content.Title,
+			Overview: v.Content.Description,
+			VideoURL: v.Content.VideoKey,
+			Time:     v.StartPoint,
+			Creator:  newCoachBrief(v.Content.CoachId, v.Content.Coach.Profile1),
 		})
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "Success",
-		"data": gin.H{
-			"list":        list,
-			"page":        pb.GetLimit(),
-			"page_size":   body.PageSize,
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list, has_more, next_marker),
 	})
 }
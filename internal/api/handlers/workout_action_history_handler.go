@@ -8,7 +8,9 @@ import (
 	"gorm.io/gorm"
 
 	"myapi/internal/models"
+	"myapi/internal/pkg/errcode"
 	"myapi/internal/pkg/pagination"
+	"myapi/internal/pkg/response"
 	"myapi/pkg/logger"
 )
 
@@ -26,26 +28,28 @@ func NewWorkoutActionHistoryHandler(db *gorm.DB, logger *logger.Logger) *Workout
 	}
 }
 
+type CreateWorkoutHistoryRequest struct {
+	WorkoutActionId int     `json:"workout_action_id"`
+	Reps            int     `json:"reps"`
+	RepsUnit        string  `json:"reps_unit"`
+	Weight          float64 `json:"weight"`
+	WeightUnit      string  `json:"weight_unit"`
+}
+
 func (h *WorkoutActionHistoryHandler) CreateWorkoutHistory(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		WorkoutActionId int     `json:"workout_action_id"`
-		Reps            int     `json:"reps"`
-		RepsUnit        string  `json:"reps_unit"`
-		Weight          float64 `json:"weight"`
-		WeightUnit      string  `json:"weight_unit"`
-	}
+	var body CreateWorkoutHistoryRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.WorkoutActionId == 0 || body.Reps == 0 || body.Weight == 0 || body.RepsUnit == "" || body.WeightUnit == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少动作参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("workout_action_id"))
 		return
 	}
 	if uid == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "参数错误", "data": nil})
+		response.Fail(c, errcode.ErrInvalidParam)
 		return
 	}
 
@@ -59,42 +63,44 @@ func (h *WorkoutActionHistoryHandler) CreateWorkoutHistory(c *gin.Context) {
 		CreatedAt:       time.Now(),
 	}
 
-	if err := h.db.Create(&history).Error; err != nil {
-		h.logger.Error("Failed to create workout action history", err)
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Create(&history).Error; err != nil {
+		h.logger.WithContext(c).Error("Failed to create workout action history", err)
+		response.Fail(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": nil})
 }
 
+type FetchWorkoutActionHistoryListOfWorkoutDayRequest struct {
+	models.Pagination
+	WorkoutDayId int    `json:"workout_day_id"`
+	OrderBy      string `json:"order_by"`
+}
+
 func (h *WorkoutActionHistoryHandler) FetchWorkoutActionHistoryListOfWorkoutDay(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		models.Pagination
-		WorkoutDayId int    `json:"workout_day_id"`
-		OrderBy      string `json:"order_by"`
-	}
+	var body FetchWorkoutActionHistoryListOfWorkoutDayRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.WorkoutDayId == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少参数", "data": nil})
+		response.Fail(c, errcode.ErrMissingParam)
 		return
 	}
 	var d models.WorkoutDay
-	if err := h.db.Where("id = ? AND student_id = ?", body.WorkoutDayId, uid).First(&d).Error; err != nil {
+	if err := h.db.WithContext(c).Where("id = ? AND student_id = ?", body.WorkoutDayId, uid).First(&d).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "没有找到记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 
-	query := h.db.Where("d IS NULL OR d = 0")
+	query := h.db.WithContext(c).Where("d IS NULL OR d = 0")
 	query = query.Where("workout_day_id = ?", body.WorkoutDayId)
 	pb := pagination.NewPaginationBuilder[models.WorkoutActionHistory](query).
 		SetLimit(body.PageSize).
@@ -103,38 +109,35 @@ func (h *WorkoutActionHistoryHandler) FetchWorkoutActionHistoryListOfWorkoutDay(
 
 	var list1 []models.WorkoutActionHistory
 	if err := pb.Build().Preload("WorkoutAction").Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch workout history: " + err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "",
-		"data": gin.H{
-			"list":        list2,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list2, has_more, next_marker),
 	})
 }
 
+type FetchWorkoutActionHistoryListOfWorkoutActionRequest struct {
+	models.Pagination
+	WorkoutActionId int    `json:"workout_action_id"`
+	StudentId       int    `json:"student_id"`
+	OrderBy         string `json:"order_by"`
+}
+
 // 获取健身动作历史记录
 func (h *WorkoutActionHistoryHandler) FetchWorkoutActionHistoryListOfWorkoutAction(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		models.Pagination
-		WorkoutActionId int    `json:"workout_action_id"`
-		StudentId       int    `json:"student_id"`
-		OrderBy         string `json:"order_by"`
-	}
+	var body FetchWorkoutActionHistoryListOfWorkoutActionRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.WorkoutActionId == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少参数", "data": nil})
+		response.Fail(c, errcode.ErrMissingParam)
 		return
 	}
 	if body.StudentId == 0 {
@@ -142,12 +145,12 @@ func (h *WorkoutActionHistoryHandler) FetchWorkoutActionHistoryListOfWorkoutActi
 	}
 	if uid != body.StudentId {
 		var relation models.CoachRelationship
-		if err := h.db.Where("coach_id = ? AND student_id = ?", uid, body.StudentId).First(&relation).Error; err != nil {
-			c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		if err := h.db.WithContext(c).Where("coach_id = ? AND student_id = ?", uid, body.StudentId).First(&relation).Error; err != nil {
+			response.Fail(c, err)
 			return
 		}
 	}
-	query := h.db.Where("d IS NULL OR d = 0")
+	query := h.db.WithContext(c).Where("d IS NULL OR d = 0")
 	query = query.Where("action_id = ? AND student_id = ?", body.WorkoutActionId, body.StudentId)
 	pb := pagination.NewPaginationBuilder[models.WorkoutActionHistory](query).
 		SetLimit(body.PageSize).
@@ -158,18 +161,13 @@ func (h *WorkoutActionHistoryHandler) FetchWorkoutActionHistoryListOfWorkoutActi
 	}
 	var list1 []models.WorkoutActionHistory
 	if err := pb.Build().Preload("WorkoutAction").Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch workout history: " + err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "Success",
-		"data": gin.H{
-			"list":        list2,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list2, has_more, next_marker),
 	})
 }
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"fmt"
 	"math"
 	"net/http"
 	"strconv"
@@ -11,7 +10,10 @@ import (
 	"gorm.io/gorm"
 
 	"myapi/internal/models"
+	"myapi/internal/pkg/errcode"
+	"myapi/internal/pkg/metrics"
 	"myapi/internal/pkg/pagination"
+	"myapi/internal/pkg/response"
 	"myapi/pkg/logger"
 )
 
@@ -29,43 +31,49 @@ func NewWorkoutDayHandler(db *gorm.DB, logger *logger.Logger) *WorkoutDayHandler
 	}
 }
 
+type CreateWorkoutDayRequest struct {
+	StudentIds      []int  `json:"student_ids"`
+	StartWhenCreate bool   `json:"start_when_create"`
+	Title           string `json:"title"`
+	Type            string `json:"type"`
+	Details         string `json:"details"`
+	WorkoutPlanId   int    `json:"workout_plan_id"`
+}
+
+type CreateWorkoutDayResponse struct {
+	Ids []int `json:"ids"`
+}
+
 func (h *WorkoutDayHandler) CreateWorkoutDay(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 	if uid == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "非法操作", "data": nil})
+		response.Fail(c, errcode.ErrIllegalOperation)
 		return
 	}
-	var body struct {
-		StudentIds      []int  `json:"student_ids"`
-		StartWhenCreate bool   `json:"start_when_create"`
-		Title           string `json:"title"`
-		Type            string `json:"type"`
-		Details         string `json:"details"`
-		WorkoutPlanId   int    `json:"workout_plan_id"`
-	}
+	var body CreateWorkoutDayRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.WorkoutPlanId == 0 && body.Details == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少训练内容", "data": nil})
+		response.Fail(c, errcode.ErrContentRequired)
 		return
 	}
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	var workout_plan models.WorkoutPlan
 	if body.WorkoutPlanId != 0 {
 		if err := tx.Where("id = ?", body.WorkoutPlanId).First(&workout_plan).Error; err != nil {
 			if err != gorm.ErrRecordNotFound {
-				c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+				response.Fail(c, err)
 				return
 			}
-			c.JSON(http.StatusOK, gin.H{"code": 101, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
 	}
@@ -78,10 +86,10 @@ func (h *WorkoutDayHandler) CreateWorkoutDay(c *gin.Context) {
 	var subscription models.Subscription
 	if err := tx.Where("coach_id = ? AND step = 2", uid).First(&subscription).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 101, "msg": "该功能需订阅后才能使用", "data": nil})
+		response.Fail(c, errcode.ErrSubscriptionRequired)
 		return
 	}
 	now := time.Now().UTC()
@@ -110,9 +118,9 @@ func (h *WorkoutDayHandler) CreateWorkoutDay(c *gin.Context) {
 					workout_day.Status = int(models.WorkoutDayStatusStarted)
 				}
 				if err := tx.Create(&workout_day).Error; err != nil {
-					h.logger.Error("Failed to create workout plan", err)
+					h.logger.WithContext(c).Error("Failed to create workout plan", err)
 					tx.Rollback()
-					c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+					response.Fail(c, err)
 					return
 				}
 				workout_day_ids = append(workout_day_ids, workout_day.Id)
@@ -121,10 +129,10 @@ func (h *WorkoutDayHandler) CreateWorkoutDay(c *gin.Context) {
 			var relation models.CoachRelationship
 			if err := tx.Where("coach_id = ? AND student_id = ? OR coach_id = ? AND student_id = ?", uid, student_id, student_id, uid).First(&relation).Error; err != nil {
 				if err != gorm.ErrRecordNotFound {
-					c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+					response.Fail(c, err)
 					return
 				}
-				c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "非法操作", "data": nil})
+				response.Fail(c, errcode.ErrIllegalOperation)
 				return
 			}
 			workout_day := models.WorkoutDay{
@@ -146,9 +154,9 @@ func (h *WorkoutDayHandler) CreateWorkoutDay(c *gin.Context) {
 				workout_day.Status = int(models.WorkoutDayStatusStarted)
 			}
 			if err := tx.Create(&workout_day).Error; err != nil {
-				h.logger.Error("Failed to create workout plan", err)
+				h.logger.WithContext(c).Error("Failed to create workout plan", err)
 				tx.Rollback()
-				c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+				response.Fail(c, err)
 				return
 			}
 			workout_day_ids = append(workout_day_ids, workout_day.Id)
@@ -171,9 +179,9 @@ func (h *WorkoutDayHandler) CreateWorkoutDay(c *gin.Context) {
 			workout_day.Status = int(models.WorkoutDayStatusStarted)
 		}
 		if err := tx.Create(&workout_day).Error; err != nil {
-			h.logger.Error("Failed to create workout plan", err)
+			h.logger.WithContext(c).Error("Failed to create workout plan", err)
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
 		workout_day_ids = append(workout_day_ids, workout_day.Id)
@@ -181,59 +189,61 @@ func (h *WorkoutDayHandler) CreateWorkoutDay(c *gin.Context) {
 
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to commit transaction", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": gin.H{"ids": workout_day_ids}})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": CreateWorkoutDayResponse{Ids: workout_day_ids}})
+}
+
+type CreateFreeWorkoutDayRequest struct {
+	Type              string     `json:"type"`
+	Title             string     `json:"title"`
+	PendingSteps      string     `json:"pending_steps"`
+	UpdatedDetails    string     `json:"updated_details"`
+	StartAt           *time.Time `json:"start_at"`
+	FinishedAt        *time.Time `json:"finished_at"`
+	Remark            string     `json:"remark"` /** 备注 **/
+	Medias            string     `json:"medias"`
+	StartWhenCreate   bool       `json:"start_when_create"`
+	FinishWhenCreated bool       `json:"finish_when_created"`
 }
 
 // 用于创建一个已经完成的训练，比如有氧
 func (h *WorkoutDayHandler) CreateFreeWorkoutDay(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 	if uid == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "非法操作", "data": nil})
+		response.Fail(c, errcode.ErrIllegalOperation)
 		return
 	}
-	var body struct {
-		Type              string     `json:"type"`
-		Title             string     `json:"title"`
-		PendingSteps      string     `json:"pending_steps"`
-		UpdatedDetails    string     `json:"updated_details"`
-		StartAt           *time.Time `json:"start_at"`
-		FinishedAt        *time.Time `json:"finished_at"`
-		Remark            string     `json:"remark"` /** 备注 **/
-		Medias            string     `json:"medias"`
-		StartWhenCreate   bool       `json:"start_when_create"`
-		FinishWhenCreated bool       `json:"finish_when_created"`
-	}
+	var body CreateFreeWorkoutDayRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Type == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少参数", "data": nil})
+		response.Fail(c, errcode.ErrMissingParam)
 		return
 	}
 	if body.PendingSteps == "" || body.UpdatedDetails == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少参数", "data": nil})
+		response.Fail(c, errcode.ErrMissingParam)
 		return
 	}
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	var subscription models.Subscription
 	if err := tx.Where("coach_id = ? AND step = 2", uid).First(&subscription).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 101, "msg": "该功能需订阅后才能使用", "data": nil})
+		response.Fail(c, errcode.ErrSubscriptionRequired)
 		return
 	}
 	now := time.Now().UTC()
@@ -267,15 +277,15 @@ func (h *WorkoutDayHandler) CreateFreeWorkoutDay(c *gin.Context) {
 		}
 	}
 	if err := tx.Create(&workout_day).Error; err != nil {
-		h.logger.Error("Failed to create workout plan", err)
+		h.logger.WithContext(c).Error("Failed to create workout plan", err)
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	progress, err := models.ParseWorkoutDayProgress(body.PendingSteps)
 	if err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	latest := models.ToWorkoutDayStepProgress(progress)
@@ -302,7 +312,7 @@ func (h *WorkoutDayHandler) CreateFreeWorkoutDay(c *gin.Context) {
 				}
 				if err := tx.Create(&history).Error; err != nil {
 					tx.Rollback()
-					h.logger.Error("Failed to create workout action history", err)
+					h.logger.WithContext(c).Error("Failed to create workout action history", err)
 				}
 			}
 		}
@@ -311,59 +321,59 @@ func (h *WorkoutDayHandler) CreateFreeWorkoutDay(c *gin.Context) {
 		workout_day.TotalVolume = toFixed(total_volume, 1)
 	}
 	if err := tx.Save(&workout_day).Error; err != nil {
-		h.logger.Error("Failed to save workout plan", err)
+		h.logger.WithContext(c).Error("Failed to save workout plan", err)
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to commit transaction", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": gin.H{
-		"id": workout_day.Id,
-	}})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": IdResponse{Id: workout_day.Id}})
+}
+
+type UpdateWorkoutDayRequest struct {
+	Id             int        `json:"id"`
+	Type           string     `json:"type"`
+	Title          string     `json:"title"`
+	PendingSteps   string     `json:"pending_steps"`
+	UpdatedDetails string     `json:"updated_details"`
+	StartAt        *time.Time `json:"start_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
 }
 
 func (h *WorkoutDayHandler) UpdateWorkoutDay(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 	if uid == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "非法操作", "data": nil})
+		response.Fail(c, errcode.ErrIllegalOperation)
 		return
 	}
-	var body struct {
-		Id             int        `json:"id"`
-		Type           string     `json:"type"`
-		Title          string     `json:"title"`
-		PendingSteps   string     `json:"pending_steps"`
-		UpdatedDetails string     `json:"updated_details"`
-		StartAt        *time.Time `json:"start_at"`
-		FinishedAt     *time.Time `json:"finished_at"`
-	}
+	var body UpdateWorkoutDayRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少参数", "data": nil})
+		response.Fail(c, errcode.ErrMissingParam)
 		return
 	}
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	var existing models.WorkoutDay
 	if err := tx.Where("id = ? AND student_id = ?", body.Id, uid).First(&existing).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "没有找到记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 	if body.Title != "" {
@@ -379,21 +389,20 @@ func (h *WorkoutDayHandler) UpdateWorkoutDay(c *gin.Context) {
 		existing.PendingSteps = body.PendingSteps
 		if err := tx.Model(&models.WorkoutActionHistory{}).Where("workout_day_id = ?", existing.Id).Update("d", 1).Error; err != nil {
 			tx.Rollback()
-			h.logger.Error("Failed to update WorkoutActionHistory d field", err)
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			h.logger.WithContext(c).Error("Failed to update WorkoutActionHistory d field", err)
+			response.Fail(c, err)
 			return
 		}
 		progress, err := models.ParseWorkoutDayProgress(body.PendingSteps)
 		if err != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
 		latest := models.ToWorkoutDayStepProgress(progress)
 		total_volume := float64(0)
 		for _, set := range latest.Sets {
 			for _, act := range set.Actions {
-				fmt.Println("the action", act.Completed)
 				if act.Completed {
 					history := models.WorkoutActionHistory{
 						WorkoutDayId:    existing.Id,
@@ -414,7 +423,7 @@ func (h *WorkoutDayHandler) UpdateWorkoutDay(c *gin.Context) {
 					}
 					if err := tx.Create(&history).Error; err != nil {
 						tx.Rollback()
-						h.logger.Error("Failed to create workout action history", err)
+						h.logger.WithContext(c).Error("Failed to create workout action history", err)
 					}
 				}
 			}
@@ -424,20 +433,31 @@ func (h *WorkoutDayHandler) UpdateWorkoutDay(c *gin.Context) {
 		}
 	}
 	if err := tx.Save(&existing).Error; err != nil {
-		h.logger.Error("Failed to save workout plan", err)
+		h.logger.WithContext(c).Error("Failed to save workout plan", err)
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to commit transaction", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "编辑成功", "data": gin.H{
-		"id": existing.Id,
-	}})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "编辑成功", "data": IdResponse{Id: existing.Id}})
+}
+
+type StartedWorkoutDayStatus struct {
+	Id        int        `json:"id"`
+	Status    int        `json:"status"`
+	CreatedAt time.Time  `json:"created_at"`
+	StartedAt *time.Time `json:"started_at"`
+	StudentId int        `json:"student_id"`
+	CoachId   int        `json:"coach_id"`
+}
+
+type CheckHasStartedWorkoutDayResponse struct {
+	List []StartedWorkoutDayStatus `json:"list"`
 }
 
 // 查看是否有进行中的训练，仅获取少量数据
@@ -445,36 +465,76 @@ func (h *WorkoutDayHandler) CheckHasStartedWorkoutDay(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
 	var list []models.WorkoutDay
-	if err := h.db.
+	if err := h.db.WithContext(c).
 		Where("status = ?", int(models.WorkoutDayStatusStarted)).
 		Where("coach_id = ?", uid).
 		Find(&list).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
-	data := []map[string]interface{}{}
+	data := []StartedWorkoutDayStatus{}
 
 	for _, v := range list {
-		data = append(data, map[string]interface{}{
-			"id":         v.Id,
-			"status":     v.Status,
-			"created_at": v.CreatedAt,
-			"started_at": v.StartedAt,
-			"student_id": v.StudentId,
-			"coach_id":   v.CoachId,
+		data = append(data, StartedWorkoutDayStatus{
+			Id:        v.Id,
+			Status:    v.Status,
+			CreatedAt: v.CreatedAt,
+			StartedAt: v.StartedAt,
+			StudentId: v.StudentId,
+			CoachId:   v.CoachId,
 		})
 	}
 
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "请求成功", "data": gin.H{
-		"list": data,
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "请求成功", "data": CheckHasStartedWorkoutDayResponse{
+		List: data,
 	}})
 }
 
+// WorkoutDayPlanBrief 训练记录关联的训练计划，details 和 creator 只在详情里有
+type WorkoutDayPlanBrief struct {
+	Id       int         `json:"id"`
+	Title    string      `json:"title"`
+	Overview string      `json:"overview"`
+	Tags     string      `json:"tags"`
+	Details  string      `json:"details,omitempty"`
+	Creator  *CoachBrief `json:"creator,omitempty"`
+}
+
+// 没有关联训练计划时返回 nil
+func newWorkoutDayPlanBrief(v models.WorkoutDay) *WorkoutDayPlanBrief {
+	if v.WorkoutPlanId == 0 {
+		return nil
+	}
+	return &WorkoutDayPlanBrief{
+		Id:       v.WorkoutPlan.Id,
+		Title:    v.WorkoutPlan.Title,
+		Overview: v.WorkoutPlan.Overview,
+		Tags:     v.WorkoutPlan.Tags,
+	}
+}
+
+type StartedWorkoutDayItem struct {
+	Id          int                  `json:"id"`
+	Status      int                  `json:"status"`
+	Title       string               `json:"title"`
+	Type        string               `json:"type"`
+	CreatedAt   time.Time            `json:"created_at"`
+	StartedAt   *time.Time           `json:"started_at"`
+	CoachId     int                  `json:"coach_id"`
+	GroupNo     string               `json:"group_no"`
+	Student     CoachBrief           `json:"student"`
+	WorkoutPlan *WorkoutDayPlanBrief `json:"workout_plan"`
+}
+
+type FetchStartedWorkoutDayResponse struct {
+	List []StartedWorkoutDayItem `json:"list"`
+}
+
 func (h *WorkoutDayHandler) FetchStartedWorkoutDay(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
 	var list []models.WorkoutDay
-	if err := h.db.
+	if err := h.db.WithContext(c).
 		Where("status = ?", int(models.WorkoutDayStatusStarted)).
 		Where("coach_id = ? OR student_id = ?", uid, uid).
 		Where("started_at IS NOT NULL").
@@ -482,218 +542,230 @@ func (h *WorkoutDayHandler) FetchStartedWorkoutDay(c *gin.Context) {
 		Preload("WorkoutPlan").
 		Preload("Student.Profile1").
 		Find(&list).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
-	data := []map[string]interface{}{}
+	data := []StartedWorkoutDayItem{}
 
 	for _, v := range list {
-		vv := map[string]interface{}{
-			"id":         v.Id,
-			"status":     v.Status,
-			"title":      v.Title,
-			"type":       v.Type,
-			"created_at": v.CreatedAt,
-			"started_at": v.StartedAt,
-			"coach_id":   v.CoachId,
-			"group_no":   v.GroupNo,
-			"student": map[string]interface{}{
-				"id":         v.StudentId,
-				"nickname":   v.Student.Profile1.Nickname,
-				"avatar_url": v.Student.Profile1.AvatarURL,
-			},
-			"workout_plan": nil,
-		}
-		if v.WorkoutPlanId != 0 {
-			vv["workout_plan"] = map[string]interface{}{
-				"id":       v.WorkoutPlan.Id,
-				"title":    v.WorkoutPlan.Title,
-				"overview": v.WorkoutPlan.Overview,
-			}
-		}
-		data = append(data, vv)
+		data = append(data, StartedWorkoutDayItem{
+			Id:          v.Id,
+			Status:      v.Status,
+			Title:       v.Title,
+			Type:        v.Type,
+			CreatedAt:   v.CreatedAt,
+			StartedAt:   v.StartedAt,
+			CoachId:     v.CoachId,
+			GroupNo:     v.GroupNo,
+			Student:     newCoachBrief(v.StudentId, v.Student.Profile1),
+			WorkoutPlan: newWorkoutDayPlanBrief(v),
+		})
 	}
 
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "请求成功", "data": gin.H{
-		"list": data,
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "请求成功", "data": FetchStartedWorkoutDayResponse{
+		List: data,
 	}})
 }
 
+// WorkoutDayProfile 训练记录详情，自己的和学员、好友的共用
+type WorkoutDayProfile struct {
+	Id          int     `json:"id"`
+	Title       string  `json:"title"`
+	Type        string  `json:"type"`
+	Status      int     `json:"status"`
+	Duration    int     `json:"duration"`
+	TotalVolume float64 `json:"total_volume"`
+	Remark      string  `json:"remark"`
+	Medias      string  `json:"medias"`
+	// 训练记录
+	PendingSteps string `json:"pending_steps"`
+	// 训练内容
+	UpdatedDetails string               `json:"updated_details"`
+	StudentId      int                  `json:"student_id"`
+	Student        CoachBrief           `json:"student"`
+	IsSelf         bool                 `json:"is_self"`
+	StartedAt      *time.Time           `json:"started_at"`
+	FinishedAt     *time.Time           `json:"finished_at"`
+	WorkoutPlan    *WorkoutDayPlanBrief `json:"workout_plan"`
+}
+
+func newWorkoutDayProfile(v models.WorkoutDay, uid int) WorkoutDayProfile {
+	plan := newWorkoutDayPlanBrief(v)
+	if plan != nil {
+		creator := newCoachBrief(v.WorkoutPlan.OwnerId, v.WorkoutPlan.Creator.Profile1)
+		plan.Details = v.WorkoutPlan.Details
+		plan.Creator = &creator
+	}
+	return WorkoutDayProfile{
+		Id:             v.Id,
+		Title:          v.Title,
+		Type:           v.Type,
+		Status:         v.Status,
+		Duration:       v.Duration,
+		TotalVolume:    v.TotalVolume,
+		Remark:         v.Remark,
+		Medias:         v.Medias,
+		PendingSteps:   v.PendingSteps,
+		UpdatedDetails: v.UpdatedDetails,
+		StudentId:      v.StudentId,
+		Student:        newCoachBrief(v.Student.Id, v.Student.Profile1),
+		IsSelf:         v.StudentId == uid,
+		StartedAt:      v.StartedAt,
+		FinishedAt:     v.FinishedAt,
+		WorkoutPlan:    plan,
+	}
+}
+
+type FetchWorkoutDayProfileRequest struct {
+	Id int `json:"id"`
+}
+
 // 获取训练计划记录 获取自己的和当时一起训练好友、学员的
 func (h *WorkoutDayHandler) FetchWorkoutDayProfile(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body FetchWorkoutDayProfileRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少 id 参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
 	var existing_workout_day models.WorkoutDay
-	if err := h.db.
+	if err := h.db.WithContext(c).
 		Where("id = ?", body.Id).
 		Preload("WorkoutPlan").
 		Preload("WorkoutPlan.Creator.Profile1").
 		Preload("Student.Profile1").
 		First(&existing_workout_day).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "没有找到该记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 	var existing_relation models.CoachRelationship
-	if err := h.db.
+	if err := h.db.WithContext(c).
 		Where("(coach_id = ? AND student_id = ?) OR (coach_id = ? AND student_id = ?)", uid, existing_workout_day.StudentId, existing_workout_day.StudentId, uid).
 		First(&existing_relation).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
 	}
 	if existing_workout_day.StudentId != uid && existing_relation.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "异常操作", "data": nil})
-		return
-	}
-	data := gin.H{
-		"id":           existing_workout_day.Id,
-		"title":        existing_workout_day.Title,
-		"type":         existing_workout_day.Type,
-		"status":       existing_workout_day.Status,
-		"duration":     existing_workout_day.Duration,
-		"total_volume": existing_workout_day.TotalVolume,
-		"remark":       existing_workout_day.Remark,
-		"medias":       existing_workout_day.Medias,
-		// 训练记录
-		"pending_steps": existing_workout_day.PendingSteps,
-		// 训练内容
-		"updated_details": existing_workout_day.UpdatedDetails,
-		"student_id":      existing_workout_day.StudentId,
-		"student": gin.H{
-			"id":         existing_workout_day.Student.Id,
-			"nickname":   existing_workout_day.Student.Profile1.Nickname,
-			"avatar_url": existing_workout_day.Student.Profile1.AvatarURL,
-		},
-		"is_self":      existing_workout_day.StudentId == uid,
-		"started_at":   existing_workout_day.StartedAt,
-		"finished_at":  existing_workout_day.FinishedAt,
-		"workout_plan": nil,
-	}
-	if existing_workout_day.WorkoutPlanId != 0 {
-		data["workout_plan"] = gin.H{
-			"id":       existing_workout_day.WorkoutPlan.Id,
-			"title":    existing_workout_day.WorkoutPlan.Title,
-			"overview": existing_workout_day.WorkoutPlan.Overview,
-			"tags":     existing_workout_day.WorkoutPlan.Tags,
-			"details":  existing_workout_day.WorkoutPlan.Details,
-			"creator": gin.H{
-				"nickname":   existing_workout_day.WorkoutPlan.Creator.Profile1.Nickname,
-				"avatar_url": existing_workout_day.WorkoutPlan.Creator.Profile1.AvatarURL,
-			},
-		}
+		response.Fail(c, errcode.ErrIllegalOperation)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": newWorkoutDayProfile(existing_workout_day, uid)})
+}
+
+// WorkoutDayResult 训练结果，由训练记录统计得到
+type WorkoutDayResult struct {
+	Id          int                              `json:"id"`
+	Status      int                              `json:"status"`
+	Steps       []models.TodayWorkoutActionGroup `json:"steps"`
+	SetCount    int                              `json:"set_count"`
+	Duration    int                              `json:"duration"`
+	TotalVolume float64                          `json:"total_volume"`
+	Tags        []string                         `json:"tags"`
+	StudentId   int                              `json:"student_id"`
+	IsSelf      bool                             `json:"is_self"`
+	WorkoutPlan *WorkoutDayPlanBrief             `json:"workout_plan"`
+	StartedAt   *time.Time                       `json:"started_at"`
+	FinishedAt  *time.Time                       `json:"finished_at"`
+}
+
+func newWorkoutDayResult(v models.WorkoutDay, result *models.BuildedWorkoutDayResult, uid int) WorkoutDayResult {
+	plan := newWorkoutDayPlanBrief(v)
+	if plan != nil {
+		creator := newCoachBrief(v.WorkoutPlan.OwnerId, v.WorkoutPlan.Creator.Profile1)
+		plan.Creator = &creator
+	}
+	return WorkoutDayResult{
+		Id:          v.Id,
+		Status:      v.Status,
+		Steps:       result.List,
+		SetCount:    result.SetCount,
+		Duration:    result.DurationCount,
+		TotalVolume: result.TotalVolume,
+		Tags:        result.Tags,
+		StudentId:   v.StudentId,
+		IsSelf:      v.StudentId == uid,
+		WorkoutPlan: plan,
+		StartedAt:   v.StartedAt,
+		FinishedAt:  v.FinishedAt,
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": data})
+}
+
+type FetchWorkoutDayResultRequest struct {
+	Id int `json:"id"`
 }
 
 // 获取训练计划记录结果 只能获取自己的
 func (h *WorkoutDayHandler) FetchWorkoutDayResult(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body FetchWorkoutDayResultRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少 id 参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
 	var workout_day models.WorkoutDay
-	if err := h.db.
+	if err := h.db.WithContext(c).
 		Where("id = ? AND student_id = ?", body.Id, uid).
 		Preload("WorkoutPlan").
 		Preload("WorkoutPlan.Creator.Profile1").
 		First(&workout_day).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "没有找到该记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 
 	// Calculate the day number based on unique dates
 	// var day_number int64
-	// h.db.Model(&models.WorkoutDay{}).
+	// h.db.WithContext(c).Model(&models.WorkoutDay{}).
 	// 	Select("COUNT(DISTINCT DATE(created_at))").
 	// 	Where("student_id = ? AND DATE(created_at) <= DATE(?)", uid, workout_day.CreatedAt).
 	// 	Count(&day_number)
-	result, err := models.BuildResultFromWorkoutDay(workout_day, h.db)
+	result, err := models.BuildResultFromWorkoutDay(workout_day, h.db.WithContext(c))
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
-		return
-	}
-	data := gin.H{
-		"id":     workout_day.Id,
-		"status": workout_day.Status,
-		// 训练记录
-		// "pending_steps": workout_day.PendingSteps,
-		// 训练内容
-		// "updated_details": workout_day.UpdatedDetails,
-		"steps":        result.List,
-		"set_count":    result.SetCount,
-		"duration":     result.DurationCount,
-		"total_volume": result.TotalVolume,
-		"tags":         result.Tags,
-		"student_id":   workout_day.StudentId,
-		"is_self":      workout_day.StudentId == uid,
-		"workout_plan": nil,
-		// "day_number":  day_number,
-		"started_at":  workout_day.StartedAt,
-		"finished_at": workout_day.FinishedAt,
-	}
-	if workout_day.WorkoutPlanId != 0 {
-		data["workout_plan"] = gin.H{
-			"id":       workout_day.WorkoutPlan.Id,
-			"title":    workout_day.WorkoutPlan.Title,
-			"overview": workout_day.WorkoutPlan.Overview,
-			"tags":     workout_day.WorkoutPlan.Tags,
-			// "details":  workout_day.WorkoutPlan.Details,
-			"creator": gin.H{
-				"nickname":   workout_day.WorkoutPlan.Creator.Profile1.Nickname,
-				"avatar_url": workout_day.WorkoutPlan.Creator.Profile1.AvatarURL,
-			},
-		}
+		response.Fail(c, err)
+		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": data})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": newWorkoutDayResult(workout_day, result, uid)})
+}
+
+type FetchStudentWorkoutDayProfileRequest struct {
+	Id        int `json:"id"`
+	StudentId int `json:"student_id"`
 }
 
 func (h *WorkoutDayHandler) FetchStudentWorkoutDayProfile(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Id        int `json:"id"`
-		StudentId int `json:"student_id"`
-	}
+	var body FetchStudentWorkoutDayProfileRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少 id 参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
 	var existing_relation models.CoachRelationship
-	if err := h.db.Where("(coach_id = ? AND student_id = ?) OR (coach_id = ? AND student_id = ?)", uid, body.StudentId, body.StudentId, uid).First(&existing_relation).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Where("(coach_id = ? AND student_id = ?) OR (coach_id = ? AND student_id = ?)", uid, body.StudentId, body.StudentId, uid).First(&existing_relation).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
-	query := h.db.Where("d IS NULL OR d = 0")
+	query := h.db.WithContext(c).Where("d IS NULL OR d = 0")
 	query = query.Where("id = ? AND (coach_id = ? OR student_id = ?)", body.Id, body.StudentId, body.StudentId)
 	var workout_day models.WorkoutDay
 	if err := query.
@@ -702,280 +774,225 @@ func (h *WorkoutDayHandler) FetchStudentWorkoutDayProfile(c *gin.Context) {
 		Preload("Student.Profile1").
 		First(&workout_day).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "没有找到该记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 
 	// Calculate the day number based on unique dates
 	// var day_number int64
-	// h.db.Model(&models.WorkoutDay{}).
+	// h.db.WithContext(c).Model(&models.WorkoutDay{}).
 	// 	Select("COUNT(DISTINCT DATE(created_at))").
 	// 	Where("student_id = ? AND DATE(created_at) <= DATE(?)", uid, workout_day.CreatedAt).
 	// 	Count(&day_number)
 
-	data := gin.H{
-		"id":           workout_day.Id,
-		"title":        workout_day.Title,
-		"type":         workout_day.Type,
-		"duration":     workout_day.Duration,
-		"total_volume": workout_day.TotalVolume,
-		"status":       workout_day.Status,
-		"remark":       workout_day.Remark,
-		"medias":       workout_day.Medias,
-		// 训练记录
-		"pending_steps": workout_day.PendingSteps,
-		// 训练内容
-		"updated_details": workout_day.UpdatedDetails,
-		"student_id":      workout_day.StudentId,
-		"student": gin.H{
-			"id":         workout_day.Student.Id,
-			"nickname":   workout_day.Student.Profile1.Nickname,
-			"avatar_url": workout_day.Student.Profile1.AvatarURL,
-		},
-		"is_self": workout_day.StudentId == uid,
-		// "day_number":  day_number,
-		"started_at":   workout_day.StartedAt,
-		"finished_at":  workout_day.FinishedAt,
-		"workout_plan": nil,
-	}
-	if workout_day.WorkoutPlanId != 0 {
-		data["workout_plan"] = gin.H{
-			"id":       workout_day.WorkoutPlan.Id,
-			"title":    workout_day.WorkoutPlan.Title,
-			"overview": workout_day.WorkoutPlan.Overview,
-			"tags":     workout_day.WorkoutPlan.Tags,
-			"details":  workout_day.WorkoutPlan.Details,
-			"creator": gin.H{
-				"nickname":   workout_day.WorkoutPlan.Creator.Profile1.Nickname,
-				"avatar_url": workout_day.WorkoutPlan.Creator.Profile1.AvatarURL,
-			},
-		}
-	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": data})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": newWorkoutDayProfile(workout_day, uid)})
+}
+
+type FetchStudentWorkoutDayResultRequest struct {
+	Id int `json:"id"`
+	// StudentId int `json:"student_id"`
 }
 
 // 获取学员/好友训练记录
 // 可以不传 student_id，但是会匹配好友关系
 func (h *WorkoutDayHandler) FetchStudentWorkoutDayResult(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Id int `json:"id"`
-		// StudentId int `json:"student_id"`
-	}
+	var body FetchStudentWorkoutDayResultRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少 id 参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
 	var workout_day models.WorkoutDay
-	if err := h.db.Where("d IS NULL OR d = 0").Where("id = ?", body.Id).
+	if err := h.db.WithContext(c).Where("d IS NULL OR d = 0").Where("id = ?", body.Id).
 		Preload("WorkoutPlan").
 		Preload("WorkoutPlan.Creator.Profile1").
 		Preload("Student.Profile1").
 		First(&workout_day).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "没有找到该记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 
-	fmt.Println(uid, workout_day.StudentId)
 	var existing_relation models.CoachRelationship
-	if err := h.db.Where("(coach_id = ? AND student_id = ?) OR (coach_id = ? AND student_id = ?)", uid, workout_day.StudentId, workout_day.StudentId, uid).First(&existing_relation).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "无法查看", "data": nil})
+	if err := h.db.WithContext(c).Where("(coach_id = ? AND student_id = ?) OR (coach_id = ? AND student_id = ?)", uid, workout_day.StudentId, workout_day.StudentId, uid).First(&existing_relation).Error; err != nil {
+		response.Fail(c, errcode.ErrForbidden)
 		return
 	}
-	result, err := models.BuildResultFromWorkoutDay(workout_day, h.db)
+	result, err := models.BuildResultFromWorkoutDay(workout_day, h.db.WithContext(c))
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
-		return
-	}
-	data := gin.H{
-		"id":           workout_day.Id,
-		"status":       workout_day.Status,
-		"steps":        result.List,
-		"set_count":    result.SetCount,
-		"duration":     result.DurationCount,
-		"total_volume": result.TotalVolume,
-		"tags":         result.Tags,
-		"student_id":   workout_day.StudentId,
-		"is_self":      workout_day.StudentId == uid,
-		"workout_plan": nil,
-		"started_at":   workout_day.StartedAt,
-		"finished_at":  workout_day.FinishedAt,
-	}
-	if workout_day.WorkoutPlanId != 0 {
-		data["workout_plan"] = gin.H{
-			"id":       workout_day.WorkoutPlan.Id,
-			"title":    workout_day.WorkoutPlan.Title,
-			"overview": workout_day.WorkoutPlan.Overview,
-			"tags":     workout_day.WorkoutPlan.Tags,
-			"creator": gin.H{
-				"nickname":   workout_day.WorkoutPlan.Creator.Profile1.Nickname,
-				"avatar_url": workout_day.WorkoutPlan.Creator.Profile1.AvatarURL,
-			},
-		}
+		response.Fail(c, err)
+		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": data})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": newWorkoutDayResult(workout_day, result, uid)})
+}
+
+type UpdateWorkoutDayPlanDetailsRequest struct {
+	Id   int    `json:"id"`
+	Data string `json:"data"`
 }
 
 func (h *WorkoutDayHandler) UpdateWorkoutDayPlanDetails(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Id   int    `json:"id"`
-		Data string `json:"data"`
-	}
+	var body UpdateWorkoutDayPlanDetailsRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少id参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
 	var existing models.WorkoutDay
-	if err := h.db.Where("id = ? AND (coach_id = ? OR student_id = ?)", body.Id, uid, uid).First(&existing).Error; err != nil {
+	if err := h.db.WithContext(c).Where("id = ? AND (coach_id = ? OR student_id = ?)", body.Id, uid, uid).First(&existing).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "没有找到记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 	// existing.UpdatedDetails = body.Data
 	// now := time.Now().UTC()
 	// existing.UpdatedAt = &now
-	if err := h.db.Model(&existing).Update("updated_details", body.Data).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Model(&existing).Update("updated_details", body.Data).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "更新成功", "data": gin.H{"id": existing.Id}})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "更新成功", "data": IdResponse{Id: existing.Id}})
+}
+
+type UpdateWorkoutDayStepProgressRequest struct {
+	Id   int    `json:"id"`
+	Data string `json:"data"`
 }
 
 // 暂存的训练内容记录
 func (h *WorkoutDayHandler) UpdateWorkoutDayStepProgress(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Id   int    `json:"id"`
-		Data string `json:"data"`
-	}
+	var body UpdateWorkoutDayStepProgressRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少id参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
 	if body.Data == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少更新内容", "data": nil})
+		response.Fail(c, errcode.ErrMissingParam)
 		return
 	}
 	var existing models.WorkoutDay
-	if result := h.db.Where("id = ? AND (coach_id = ? OR student_id = ?)", body.Id, uid, uid).First(&existing); result.Error != nil {
+	if result := h.db.WithContext(c).Where("id = ? AND (coach_id = ? OR student_id = ?)", body.Id, uid, uid).First(&existing); result.Error != nil {
 		if result.Error != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": result.Error.Error(), "data": nil})
+			response.Fail(c, result.Error)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "没有找到记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 
 	if err := tx.Model(&existing).Update("pending_steps", body.Data).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to update day", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		h.logger.WithContext(c).Error("Failed to update day", err)
+		response.Fail(c, err)
 		return
 	}
 
 	if err := tx.Commit().Error; err != nil {
-		h.logger.Error("Failed to commit transaction: ", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "更新失败", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction: ", err)
+		response.Fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "更新成功", "data": gin.H{"id": existing.Id}})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "更新成功", "data": IdResponse{Id: existing.Id}})
+}
+
+type StartWorkoutDayRequest struct {
+	Id int `json:"id"`
 }
 
 // 基本上用不上，都是用 createWorkoutDay
 func (h *WorkoutDayHandler) StartWorkoutDay(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body StartWorkoutDayRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少id参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
 	var day models.WorkoutDay
-	if err := h.db.Where("student_id = ?", uid).First(&day, body.Id).Error; err != nil {
+	if err := h.db.WithContext(c).Where("student_id = ?", uid).First(&day, body.Id).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 404, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "找不到记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 
 	day.Status = int(models.WorkoutDayStatusStarted)
 	now := time.Now().UTC()
 	day.StartedAt = &now
-	h.db.Save(&day)
+	h.db.WithContext(c).Save(&day)
 
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "操作成功", "data": gin.H{"id": day.Id}})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "操作成功", "data": IdResponse{Id: day.Id}})
+}
+
+type FinishWorkoutDayRequest struct {
+	Id             int    `json:"id"`
+	PendingSteps   string `json:"pending_steps"`
+	UpdatedDetails string `json:"updated_details"`
 }
 
 func (h *WorkoutDayHandler) FinishWorkoutDay(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Id             int    `json:"id"`
-		PendingSteps   string `json:"pending_steps"`
-		UpdatedDetails string `json:"updated_details"`
-	}
+	var body FinishWorkoutDayRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少id参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
 
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 
 	var existing models.WorkoutDay
 	if err := tx.Where("id = ? AND (coach_id = ? OR student_id = ?)", body.Id, uid, uid).First(&existing).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "没有找到记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 	if body.PendingSteps != "" {
@@ -988,7 +1005,7 @@ func (h *WorkoutDayHandler) FinishWorkoutDay(c *gin.Context) {
 	progress, err := models.ParseWorkoutDayProgress(existing.PendingSteps)
 	if err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	latest := models.ToWorkoutDayStepProgress(progress)
@@ -1018,7 +1035,7 @@ func (h *WorkoutDayHandler) FinishWorkoutDay(c *gin.Context) {
 				}
 				if err := tx.Create(&history).Error; err != nil {
 					tx.Rollback()
-					h.logger.Error("Failed to create workout action history", err)
+					h.logger.WithContext(c).Error("Failed to create workout action history", err)
 				}
 			}
 		}
@@ -1032,133 +1049,216 @@ func (h *WorkoutDayHandler) FinishWorkoutDay(c *gin.Context) {
 	existing.TotalVolume = toFixed(total_volume, 1)
 	if err := tx.Save(&existing).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to commit transaction", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "操作成功", "data": gin.H{"id": existing.Id}})
+	metrics.WorkoutsFinished.Inc()
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "操作成功", "data": IdResponse{Id: existing.Id}})
+}
+
+type GiveUpWorkoutDayRequest struct {
+	Id int `json:"id"`
 }
 
 func (h *WorkoutDayHandler) GiveUpWorkoutDay(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body GiveUpWorkoutDayRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少id参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
 	var existing models.WorkoutDay
-	if err := h.db.Where("id = ? AND (coach_id = ? OR student_id = ?)", body.Id, uid, uid).First(&existing).Error; err != nil {
+	if err := h.db.WithContext(c).Where("id = ? AND (coach_id = ? OR student_id = ?)", body.Id, uid, uid).First(&existing).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "没有找到记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 	existing.Status = int(models.WorkoutDayStatusGiveUp)
-	h.db.Save(&existing)
+	h.db.WithContext(c).Save(&existing)
+
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "更新成功", "data": IdResponse{Id: existing.Id}})
+}
 
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "更新成功", "data": gin.H{"id": existing.Id}})
+type ContinueWorkoutDayRequest struct {
+	Id int `json:"id"`
 }
 
 func (h *WorkoutDayHandler) ContinueWorkoutDay(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body ContinueWorkoutDayRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少id参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	var existing models.WorkoutDay
 	if err := tx.Where("id = ? AND coach_id = ?", body.Id, uid).First(&existing).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "没有找到记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 	existing.Status = int(models.WorkoutDayStatusStarted)
 	if err := tx.Save(&existing).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	// 将 WorkoutActionHistory 所有 workout_day_id = existing.id 的记录 d 设置为 1
 	if err := tx.Model(&models.WorkoutActionHistory{}).Where("workout_day_id = ?", existing.Id).Update("d", 1).Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to update WorkoutActionHistory d field", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		h.logger.WithContext(c).Error("Failed to update WorkoutActionHistory d field", err)
+		response.Fail(c, err)
 		return
 	}
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to commit transaction", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "更新成功", "data": gin.H{"id": existing.Id}})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "更新成功", "data": IdResponse{Id: existing.Id}})
+}
+
+type DeleteWorkoutDayRequest struct {
+	Id int `json:"id"`
 }
 
 func (h *WorkoutDayHandler) DeleteWorkoutDay(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body DeleteWorkoutDayRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	var existing models.WorkoutDay
-	if err := h.db.Where("id = ? AND student_id = ?", body.Id, uid).First(&existing).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Where("id = ? AND student_id = ?", body.Id, uid).First(&existing).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
-	if err := h.db.Where("id = ?", body.Id).Update("d", 1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Where("id = ?", body.Id).Update("d", 1).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "操作成功", "data": nil})
 }
 
-func (h *WorkoutDayHandler) FetchWorkoutDayList(c *gin.Context) {
+type ShareWorkoutDayRequest struct {
+	Id     int  `json:"id"`
+	Shared bool `json:"shared"`
+}
+
+type ShareWorkoutDayResponse struct {
+	Shared int `json:"shared"`
+}
+
+// 分享已完成的训练到动态，关注我的人能看到
+func (h *WorkoutDayHandler) ShareWorkoutDay(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
+	var body ShareWorkoutDayRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
+		return
+	}
+	if body.Id == 0 {
+		response.Fail(c, errcode.MissingParam("id"))
+		return
+	}
+	var existing models.WorkoutDay
+	if err := h.db.WithContext(c).Where("(d IS NULL OR d = 0) AND id = ? AND student_id = ?", body.Id, uid).First(&existing).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			response.Fail(c, err)
+			return
+		}
+		response.Fail(c, errcode.ErrNotFound)
+		return
+	}
+	if existing.Status != int(models.WorkoutDayStatusFinished) {
+		response.Fail(c, errcode.ErrShareUnfinished)
+		return
+	}
+	updates := map[string]interface{}{
+		"shared":    0,
+		"shared_at": nil,
+	}
+	if body.Shared {
+		updates["shared"] = 1
+		updates["shared_at"] = time.Now()
+	}
+	if err := h.db.WithContext(c).Model(&existing).Updates(updates).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "操作成功", "data": ShareWorkoutDayResponse{Shared: existing.Shared}})
+}
+
+// WorkoutDayItem 训练记录列表里的一项
+type WorkoutDayItem struct {
+	Id          int                  `json:"id"`
+	Status      int                  `json:"status"`
+	Title       string               `json:"title"`
+	Type        string               `json:"type"`
+	GroupNo     string               `json:"group_no"`
+	WorkoutPlan *WorkoutDayPlanBrief `json:"workout_plan"`
+	StartedAt   *time.Time           `json:"started_at"`
+	FinishedAt  *time.Time           `json:"finished_at"`
+}
 
-	var body struct {
-		models.Pagination
-		Status          int        `json:"status"`
-		FinishedAtStart *time.Time `json:"finished_at_start"`
-		FinishedAtEnd   *time.Time `json:"finished_at_end"`
+func newWorkoutDayItem(v models.WorkoutDay) WorkoutDayItem {
+	return WorkoutDayItem{
+		Id:          v.Id,
+		Status:      v.Status,
+		Title:       v.Title,
+		Type:        v.Type,
+		GroupNo:     v.GroupNo,
+		WorkoutPlan: newWorkoutDayPlanBrief(v),
+		StartedAt:   v.StartedAt,
+		FinishedAt:  v.FinishedAt,
 	}
+}
+
+type FetchWorkoutDayListRequest struct {
+	models.Pagination
+	Status          int        `json:"status"`
+	FinishedAtStart *time.Time `json:"finished_at_start"`
+	FinishedAtEnd   *time.Time `json:"finished_at_end"`
+}
+
+func (h *WorkoutDayHandler) FetchWorkoutDayList(c *gin.Context) {
+	uid := int(c.GetFloat64("id"))
+
+	var body FetchWorkoutDayListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 
-	query := h.db.Where("d IS NULL OR d = 0")
+	query := h.db.WithContext(c).Where("d IS NULL OR d = 0")
 	if body.Status != 0 {
 		query = query.Where("status = ?", body.Status)
 	}
@@ -1178,61 +1278,44 @@ func (h *WorkoutDayHandler) FetchWorkoutDayList(c *gin.Context) {
 		SetOrderBy("finished_at DESC")
 	var list1 []models.WorkoutDay
 	if err := pb.Build().Preload("WorkoutPlan").Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
-	list := make([]map[string]interface{}, 0, len(list2))
+	list := make([]WorkoutDayItem, 0, len(list2))
 	for _, v := range list2 {
-		d := map[string]interface{}{
-			"id":           v.Id,
-			"status":       v.Status,
-			"title":        v.Title,
-			"type":         v.Type,
-			"group_no":     v.GroupNo,
-			"workout_plan": nil,
-			"started_at":   v.StartedAt,
-			"finished_at":  v.FinishedAt,
-		}
-		if v.WorkoutPlanId != 0 {
-			d["workout_plan"] = map[string]interface{}{
-				"id":       v.WorkoutPlan.Id,
-				"title":    v.WorkoutPlan.Title,
-				"overview": v.WorkoutPlan.Overview,
-				"tags":     v.WorkoutPlan.Tags,
-			}
-		}
-		list = append(list, d)
+		list = append(list, newWorkoutDayItem(v))
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "Success",
-		"data": gin.H{
-			"list":        list,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list, has_more, next_marker),
 	})
 }
 
+type FetchFinishedWorkoutDayListRequest struct {
+	Id              int        `json:"id"`
+	FinishedAtStart *time.Time `json:"finished_at_start"`
+	FinishedAtEnd   *time.Time `json:"finished_at_end"`
+}
+
+type FetchFinishedWorkoutDayListResponse struct {
+	List []WorkoutDayItem `json:"list"`
+}
+
 // 似乎废弃了，使用 FetchWorkoutDayList 替代
 func (h *WorkoutDayHandler) FetchFinishedWorkoutDayList(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Id              int        `json:"id"`
-		FinishedAtStart *time.Time `json:"finished_at_start"`
-		FinishedAtEnd   *time.Time `json:"finished_at_end"`
-	}
+	var body FetchFinishedWorkoutDayListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 
 	var list []models.WorkoutDay
 
-	query := h.db.Where("d IS NULL OR d = 0")
+	query := h.db.WithContext(c).Where("d IS NULL OR d = 0")
 	// student_id 表示是自己训练的记录
 	query = query.Where("student_id = ?", uid)
 
@@ -1247,55 +1330,50 @@ func (h *WorkoutDayHandler) FetchFinishedWorkoutDayList(c *gin.Context) {
 	query = query.Where("status = ?", int(models.WorkoutDayStatusFinished))
 	query = query.Order("created_at desc")
 
-	if err := query.Find(&list).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := query.Preload("WorkoutPlan").Find(&list).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
 
-	data := []map[string]interface{}{}
+	data := []WorkoutDayItem{}
 
 	for _, v := range list {
-		data = append(data, map[string]interface{}{
-			"id":          v.Id,
-			"title":       v.Title,
-			"type":        v.Type,
-			"status":      v.Status,
-			"started_at":  v.StartedAt,
-			"finished_at": v.FinishedAt,
-		})
+		data = append(data, newWorkoutDayItem(v))
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "Success",
-		"data": gin.H{
-			"list": data,
+		"data": FetchFinishedWorkoutDayListResponse{
+			List: data,
 		},
 	})
 }
 
+type FetchMyStudentWorkoutDayListRequest struct {
+	models.Pagination
+	Id             int        `json:"id"`
+	StartedAtStart *time.Time `json:"started_at_start"`
+	StartedAtEnd   *time.Time `json:"started_at_end"`
+	Status         *int       `json:"status"`
+}
+
 func (h *WorkoutDayHandler) FetchMyStudentWorkoutDayList(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		models.Pagination
-		Id             int        `json:"id"`
-		StartedAtStart *time.Time `json:"started_at_start"`
-		StartedAtEnd   *time.Time `json:"started_at_end"`
-		Status         *int       `json:"status"`
-	}
+	var body FetchMyStudentWorkoutDayListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 
 	// 确保是自己的学员
 	var relation models.CoachRelationship
-	if err := h.db.Where("coach_id = ? AND student_id = ? OR coach_id = ? AND student_id = ?", uid, body.Id, body.Id, uid).First(&relation).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Where("coach_id = ? AND student_id = ? OR coach_id = ? AND student_id = ?", uid, body.Id, body.Id, uid).First(&relation).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
 
-	query := h.db
+	query := h.db.WithContext(c)
 	query = query.Where("student_id = ?", body.Id)
 	// 添加开始时间范围筛选
 	if body.StartedAtStart != nil {
@@ -1315,71 +1393,51 @@ func (h *WorkoutDayHandler) FetchMyStudentWorkoutDayList(c *gin.Context) {
 
 	var list []models.WorkoutDay
 	if err := pb.Build().Preload("WorkoutPlan").Find(&list).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list)
-	data := []map[string]interface{}{}
+	data := make([]WorkoutDayItem, 0, len(list2))
 	for _, v := range list2 {
-		d := map[string]interface{}{
-			"id":           v.Id,
-			"status":       v.Status,
-			"title":        v.Title,
-			"type":         v.Type,
-			"workout_plan": nil,
-			"started_at":   v.StartedAt,
-			"finished_at":  v.FinishedAt,
-		}
-		if v.WorkoutPlanId != 0 {
-			d["workout_plan"] = map[string]interface{}{
-				"id":       v.WorkoutPlan.Id,
-				"title":    v.WorkoutPlan.Title,
-				"overview": v.WorkoutPlan.Overview,
-				"tags":     v.WorkoutPlan.Tags,
-			}
-		}
-		data = append(data, d)
+		data = append(data, newWorkoutDayItem(v))
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "Success",
-		"data": gin.H{
-			"list":        data,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, data, has_more, next_marker),
 	})
 }
 
+type FetchStudentWorkoutActionHistoryListOfWorkoutDayRequest struct {
+	models.Pagination
+	WorkoutDayId int    `json:"workout_day_id"`
+	StudentId    int    `json:"student_id"`
+	OrderBy      string `json:"order_by"`
+}
+
 func (h *WorkoutActionHistoryHandler) FetchStudentWorkoutActionHistoryListOfWorkoutDay(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		models.Pagination
-		WorkoutDayId int    `json:"workout_day_id"`
-		StudentId    int    `json:"student_id"`
-		OrderBy      string `json:"order_by"`
-	}
+	var body FetchStudentWorkoutActionHistoryListOfWorkoutDayRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 
 	if body.WorkoutDayId == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少参数", "data": nil})
+		response.Fail(c, errcode.ErrMissingParam)
 		return
 	}
 	var d models.WorkoutDay
-	query1 := h.db
+	query1 := h.db.WithContext(c)
 	query1 = query1.Where("id = ? AND (coach_id = ? OR student_id = ?)", body.WorkoutDayId, uid, body.StudentId)
 	if err := query1.First(&d).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 
-	query := h.db.Where("d IS NULL OR d = 0")
+	query := h.db.WithContext(c).Where("d IS NULL OR d = 0")
 	query = query.Where("workout_day_id = ?", body.WorkoutDayId)
 	pb := pagination.NewPaginationBuilder[models.WorkoutActionHistory](query).
 		SetLimit(body.PageSize).
@@ -1388,35 +1446,35 @@ func (h *WorkoutActionHistoryHandler) FetchStudentWorkoutActionHistoryListOfWork
 
 	var list1 []models.WorkoutActionHistory
 	if err := pb.Build().Preload("WorkoutAction").Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch workout history: " + err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "",
-		"data": gin.H{
-			"list":        list2,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list2, has_more, next_marker),
 	})
 }
 
+type RefreshWorkoutDayRecordsResponse struct {
+	Updated int `json:"updated"`
+	Total   int `json:"total"`
+}
+
 func (h *WorkoutDayHandler) RefreshWorkoutDayRecords250630(c *gin.Context) {
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	// 查询所有 status=2（已完成）的 WorkoutDay 记录
 	var days []models.WorkoutDay
 	err := tx.Where("status = ?", int(models.WorkoutDayStatusFinished)).Preload("WorkoutPlan").Find(&days).Error
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "查询失败: " + err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	updated := 0
@@ -1424,14 +1482,14 @@ func (h *WorkoutDayHandler) RefreshWorkoutDayRecords250630(c *gin.Context) {
 		if day.Title == "" {
 			if err := tx.Model(&models.WorkoutDay{}).Where("id = ?", day.Id).Update("title", day.WorkoutPlan.Title).Error; err != nil {
 				tx.Rollback()
-				c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+				response.Fail(c, err)
 				return
 			}
 		}
 		if day.Type == "" {
 			if err := tx.Model(&models.WorkoutDay{}).Where("id = ?", day.Id).Update("type", day.WorkoutPlan.Type).Error; err != nil {
 				tx.Rollback()
-				c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+				response.Fail(c, err)
 				return
 			}
 		}
@@ -1442,7 +1500,7 @@ func (h *WorkoutDayHandler) RefreshWorkoutDayRecords250630(c *gin.Context) {
 			if day.Duration != dur_min {
 				if err := tx.Model(&models.WorkoutDay{}).Where("id = ?", day.Id).Update("duration", dur_min).Error; err != nil {
 					tx.Rollback()
-					c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+					response.Fail(c, err)
 					return
 				}
 				updated++
@@ -1468,7 +1526,7 @@ func (h *WorkoutDayHandler) RefreshWorkoutDayRecords250630(c *gin.Context) {
 			if day.TotalVolume != float64(total_volume) {
 				if err := tx.Model(&models.WorkoutDay{}).Where("id = ?", day.Id).Update("total_volume", total_volume).Error; err != nil {
 					tx.Rollback()
-					c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+					response.Fail(c, err)
 					return
 				}
 			}
@@ -1476,11 +1534,11 @@ func (h *WorkoutDayHandler) RefreshWorkoutDayRecords250630(c *gin.Context) {
 	}
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to commit transaction", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "刷新完成", "data": gin.H{"updated": updated, "total": len(days)}})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "刷新完成", "data": RefreshWorkoutDayRecordsResponse{Updated: updated, Total: len(days)}})
 }
 
 // 辅助函数
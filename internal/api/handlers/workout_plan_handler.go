@@ -10,7 +10,9 @@ import (
 	"gorm.io/gorm"
 
 	"myapi/internal/models"
+	"myapi/internal/pkg/errcode"
 	"myapi/internal/pkg/pagination"
+	"myapi/internal/pkg/response"
 	"myapi/pkg/logger"
 )
 
@@ -43,13 +45,15 @@ type WorkoutPlanBody struct {
 	EquipmentIds      string `json:"equipment_ids"`
 }
 
+type CreateWorkoutPlanRequest struct {
+	WorkoutPlanBody
+}
+
 func (h *WorkoutPlanHandler) CreateWorkoutPlan(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		WorkoutPlanBody
-	}
+	var body CreateWorkoutPlanRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	record := models.WorkoutPlan{
@@ -71,42 +75,44 @@ func (h *WorkoutPlanHandler) CreateWorkoutPlan(c *gin.Context) {
 	if record.Type == "" {
 		record.Type = "strength"
 	}
-	if err := h.db.Create(&record).Error; err != nil {
-		// h.logger.Error("Failed to create workout plan", "error", result.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Create(&record).Error; err != nil {
+		// h.logger.WithContext(c).Error("Failed to create workout plan", "error", result.Error)
+		response.Fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": gin.H{"id": record.Id}})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": IdResponse{Id: record.Id}})
+}
+
+type UpdateWorkoutPlanRequest struct {
+	WorkoutPlanBody
+	Id int `json:"id"`
 }
 
 func (h *WorkoutPlanHandler) UpdateWorkoutPlan(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		WorkoutPlanBody
-		Id int `json:"id"`
-	}
+	var body UpdateWorkoutPlanRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少id参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
 	var existing models.WorkoutPlan
-	if err := h.db.Where("id = ? AND owner_id = ?", body.Id, uid).First(&existing).Error; err != nil {
+	if err := h.db.WithContext(c).Where("id = ? AND owner_id = ?", body.Id, uid).First(&existing).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "没有找到记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 
@@ -126,114 +132,164 @@ func (h *WorkoutPlanHandler) UpdateWorkoutPlan(c *gin.Context) {
 
 	if err := tx.Save(&existing).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to update plan: " + err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "提交事务失败", "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "更新成功", "data": gin.H{"id": existing.Id}})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "更新成功", "data": IdResponse{Id: existing.Id}})
+}
+
+// CreatorBrief 详情里的作者，多了是否是自己
+type CreatorBrief struct {
+	CoachBrief
+	IsSelf bool `json:"is_self"`
+}
+
+func newCreatorBrief(creator models.Coach, uid int) CreatorBrief {
+	return CreatorBrief{
+		CoachBrief: newCoachBrief(creator.Id, creator.Profile1),
+		IsSelf:     creator.Id == uid,
+	}
+}
+
+type WorkoutPlanProfile struct {
+	Id                int          `json:"id"`
+	Title             string       `json:"title"`
+	Overview          string       `json:"overview"`
+	Level             int          `json:"level"`
+	EstimatedDuration int          `json:"estimated_duration"`
+	Suggestions       string       `json:"suggestions"`
+	Tags              string       `json:"tags"`
+	CoverURL          string       `json:"cover_url"`
+	EquipmentIds      string       `json:"equipment_ids"`
+	MuscleIds         string       `json:"muscle_ids"`
+	Details           string       `json:"details"`
+	Creator           CreatorBrief `json:"creator"`
+	IsFavorited       bool         `json:"is_favorited"`
+	CreatedAt         time.Time    `json:"created_at"`
+}
+
+type FetchWorkoutPlanProfileRequest struct {
+	Id int `json:"id"`
 }
 
 func (h *WorkoutPlanHandler) FetchWorkoutPlanProfile(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body FetchWorkoutPlanProfileRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少id参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
-	query := h.db.Where("d IS NULL OR d = 0")
+	query := h.db.WithContext(c).Where("d IS NULL OR d = 0")
 	query = query.Where("id = ?", body.Id)
 	var record models.WorkoutPlan
 	if err := query.
 		Preload("Creator.Profile1").
 		First(&record).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 404, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "没有找到记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 	if record.Status != int(models.WorkoutPublishStatusPublic) {
 		if record.OwnerId != uid {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "没有权限查看", "data": nil})
+			response.Fail(c, errcode.ErrForbidden)
 			return
 		}
 	}
-	data := map[string]interface{}{
-		"id":                 record.Id,
-		"title":              record.Title,
-		"overview":           record.Overview,
-		"level":              record.Level,
-		"estimated_duration": record.EstimatedDuration,
-		"suggestions":        record.Suggestions,
-		"tags":               record.Tags,
-		"cover_url":          record.CoverURL,
-		"equipment_ids":      record.EquipmentIds,
-		"muscle_ids":         record.MuscleIds,
-		"details":            record.Details,
-		"creator": map[string]interface{}{
-			"nickname":   record.Creator.Profile1.Nickname,
-			"avatar_url": record.Creator.Profile1.AvatarURL,
-			"is_self":    record.Creator.Id == uid,
-		},
-		"created_at": record.CreatedAt,
+	favorited, err := models.FetchFavoriteContentIds(h.db.WithContext(c), uid, models.FavoriteContentTypeWorkoutPlan, []int{record.Id})
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	data := WorkoutPlanProfile{
+		Id:                record.Id,
+		Title:             record.Title,
+		Overview:          record.Overview,
+		Level:             record.Level,
+		EstimatedDuration: record.EstimatedDuration,
+		Suggestions:       record.Suggestions,
+		Tags:              record.Tags,
+		CoverURL:          record.CoverURL,
+		EquipmentIds:      record.EquipmentIds,
+		MuscleIds:         record.MuscleIds,
+		Details:           record.Details,
+		Creator:           newCreatorBrief(record.Creator, uid),
+		IsFavorited:       favorited[record.Id],
+		CreatedAt:         record.CreatedAt,
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "请求成功", "data": data})
 }
 
+type DeleteWorkoutPlanRequest struct {
+	Id int `json:"id"`
+}
+
 func (h *WorkoutPlanHandler) DeleteWorkoutPlan(c *gin.Context) {
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body DeleteWorkoutPlanRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少 id 参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
 	var existing models.WorkoutPlan
-	if err := h.db.Where("id = ?", body.Id).First(&existing).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Where("id = ?", body.Id).First(&existing).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
-	if err := h.db.Model(&existing).Update("d", 1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Model(&existing).Update("d", 1).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "删除成功", "data": nil})
 }
 
+type WorkoutPlanItem struct {
+	Id                int        `json:"id"`
+	Title             string     `json:"title"`
+	Overview          string     `json:"overview"`
+	Level             int        `json:"level"`
+	Tags              string     `json:"tags"`
+	EstimatedDuration int        `json:"estimated_duration"`
+	Creator           CoachBrief `json:"creator"`
+	IsFavorited       bool       `json:"is_favorited"`
+}
+
+type FetchWorkoutPlanListRequest struct {
+	models.Pagination
+	Ids     []int  `json:"ids"`
+	Keyword string `json:"keyword"`
+	Level   int    `json:"level"`
+	Tag     string `json:"tag"`
+	Muscle  string `json:"muscle"`
+}
+
 func (h *WorkoutPlanHandler) FetchWorkoutPlanList(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		models.Pagination
-		Ids     []int  `json:"ids"`
-		Keyword string `json:"keyword"`
-		Level   int    `json:"level"`
-		Tag     string `json:"tag"`
-		Muscle  string `json:"muscle"`
-	}
+	var body FetchWorkoutPlanListRequest
 
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
-	query := h.db.Where("d IS NULL OR d = 0")
+	query := h.db.WithContext(c).Where("d IS NULL OR d = 0")
 	if uid != 0 {
 		query = query.Where("(status = 1) OR (status = 2 AND owner_id = ?)", uid)
+		query = query.Where("owner_id NOT IN (?)", models.BlockedCoachIds(h.db.WithContext(c), uid))
 	} else {
 		query = query.Where("status = 1")
 	}
@@ -259,34 +315,36 @@ func (h *WorkoutPlanHandler) FetchWorkoutPlanList(c *gin.Context) {
 
 	var list1 []models.WorkoutPlan
 	if err := pb.Build().Preload("Creator.Profile1").Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
-	list := make([]map[string]interface{}, 0, len(list2))
+	ids := make([]int, 0, len(list2))
 	for _, v := range list2 {
-		list = append(list, map[string]interface{}{
-			"id":                 v.Id,
-			"title":              v.Title,
-			"overview":           v.Overview,
-			"level":              v.Level,
-			"tags":               v.Tags,
-			"estimated_duration": v.EstimatedDuration,
-			"creator": map[string]interface{}{
-				"nickname":   v.Creator.Profile1.Nickname,
-				"avatar_url": v.Creator.Profile1.AvatarURL,
-			},
+		ids = append(ids, v.Id)
+	}
+	favorited, err := models.FetchFavoriteContentIds(h.db.WithContext(c), uid, models.FavoriteContentTypeWorkoutPlan, ids)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+	list := make([]WorkoutPlanItem, 0, len(list2))
+	for _, v := range list2 {
+		list = append(list, WorkoutPlanItem{
+			Id:                v.Id,
+			Title:             v.Title,
+			Overview:          v.Overview,
+			Level:             v.Level,
+			Tags:              v.Tags,
+			EstimatedDuration: v.EstimatedDuration,
+			Creator:           newCoachBrief(v.OwnerId, v.Creator.Profile1),
+			IsFavorited:       favorited[v.Id],
 		})
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "Success",
-		"data": gin.H{
-			"list":        list,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list, has_more, next_marker),
 	})
 }
 
@@ -312,7 +370,7 @@ func (h *WorkoutPlanHandler) FetchMyWorkoutPlanList(c *gin.Context) {
 	}
 
 	// Start with base query
-	query := h.db
+	query := h.db.WithContext(c)
 
 	query = query.Where("owner_id = ?", id)
 
@@ -346,7 +404,7 @@ func (h *WorkoutPlanHandler) FetchMyWorkoutPlanList(c *gin.Context) {
 	// Execute the query
 	result := query.Find(&plans)
 	if result.Error != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch workout plans", "data": nil})
+		response.Fail(c, result.Error)
 		return
 	}
 
@@ -363,33 +421,44 @@ func (h *WorkoutPlanHandler) FetchMyWorkoutPlanList(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "Success",
-		"data": gin.H{
-			"list":        plans,
-			"page_size":   limit,
-			"has_more":    hasMore,
-			"next_marker": nextCursor,
+		"data": pagination.List[models.WorkoutPlan]{
+			List:       plans,
+			PageSize:   limit,
+			HasMore:    hasMore,
+			NextMarker: nextCursor,
 		},
 	})
 }
 
+type FetchContentListOfWorkoutPlanRequest struct {
+	models.Pagination
+	WorkoutPlanId int    `json:"workout_plan_id"`
+	Level         int    `json:"level"`
+	Tag           string `json:"tag"`
+}
+
+type WorkoutPlanContentItem struct {
+	Id       int        `json:"id"`
+	Title    string     `json:"title"`
+	Overview string     `json:"overview"`
+	VideoKey string     `json:"video_key"`
+	Details  string     `json:"details"`
+	Creator  CoachBrief `json:"creator"`
+}
+
 func (h *WorkoutPlanHandler) FetchContentListOfWorkoutPlan(c *gin.Context) {
 	// uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		models.Pagination
-		WorkoutPlanId int    `json:"workout_plan_id"`
-		Level         int    `json:"level"`
-		Tag           string `json:"tag"`
-	}
+	var body FetchContentListOfWorkoutPlanRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.WorkoutPlanId == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "缺少 WorkoutPlanId 参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("workout_plan_id"))
 		return
 	}
-	query := h.db.Where("d IS NULL OR d = 0")
+	query := h.db.WithContext(c).Where("d IS NULL OR d = 0")
 	query = query.Where("workout_plan_id = ?", body.WorkoutPlanId)
 	if body.Level != 0 {
 		query = query.Where("level = ?", body.Level)
@@ -404,58 +473,52 @@ func (h *WorkoutPlanHandler) FetchContentListOfWorkoutPlan(c *gin.Context) {
 
 	var list1 []models.CoachContentWithWorkoutPlan
 	if err := pb.Build().Preload("Content").Preload("Content.Coach.Profile1").Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
-	list := make([]map[string]interface{}, 0, len(list2))
+	list := make([]WorkoutPlanContentItem, 0, len(list2))
 	for _, v := range list2 {
-		list = append(list, map[string]interface{}{
-			"id":        v.Id,
-			"title":     v.Content.Title,
-			"overview":  v.Content.Description,
-			"video_key": v.Content.VideoKey,
-			"details":   v.Details,
-			"creator": map[string]interface{}{
-				"nickname":   v.Content.Coach.Profile1.Nickname,
-				"avatar_url": v.Content.Coach.Profile1.AvatarURL,
-			},
+		list = append(list, WorkoutPlanContentItem{
+			Id:       v.Id,
+			Title:    v.Content.Title,
+			Overview: v.Content.Description,
+			VideoKey: v.Content.VideoKey,
+			Details:  v.Details,
+			Creator:  newCoachBrief(v.Content.CoachId, v.Content.Coach.Profile1),
 		})
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "Success",
-		"data": gin.H{
-			"list":        list,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list, has_more, next_marker),
 	})
 }
 
+type CreateContentWithWorkoutPlanRequest struct {
+	Details       string `json:"details"`
+	ContentId     int    `json:"content_id"`
+	WorkoutPlanId int    `json:"workout_plan_id"`
+	// Points        []struct {
+	// 	Time              int    `json:"time"`
+	// 	TimeText          string `json:"time_text"`
+	// 	WorkoutActionId   int    `json:"workout_action_id"`
+	// 	WorkoutActionName string `json:"workout_action_name"`
+	// } `json:"points"`
+}
+
 func (h *WorkoutPlanHandler) CreateContentWithWorkoutPlan(c *gin.Context) {
-	var body struct {
-		Details       string `json:"details"`
-		ContentId     int    `json:"content_id"`
-		WorkoutPlanId int    `json:"workout_plan_id"`
-		// Points        []struct {
-		// 	Time              int    `json:"time"`
-		// 	TimeText          string `json:"time_text"`
-		// 	WorkoutActionId   int    `json:"workout_action_id"`
-		// 	WorkoutActionName string `json:"workout_action_name"`
-		// } `json:"points"`
-	}
+	var body CreateContentWithWorkoutPlanRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "Invalid request body", "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody)
 		return
 	}
 	if body.ContentId == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少 Content id 参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("content_id"))
 		return
 	}
 	if body.WorkoutPlanId == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少 WorkoutPlan id 参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("workout_plan_id"))
 		return
 	}
 	content_with_plan := models.CoachContentWithWorkoutPlan{
@@ -465,78 +528,89 @@ func (h *WorkoutPlanHandler) CreateContentWithWorkoutPlan(c *gin.Context) {
 		CoachContentId: body.ContentId,
 		WorkoutPlanId:  body.WorkoutPlanId,
 	}
-	if err := h.db.Create(&content_with_plan).Error; err != nil {
-		h.logger.Error("Failed to create CoachContentWithWorkoutPlan", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Create(&content_with_plan).Error; err != nil {
+		h.logger.WithContext(c).Error("Failed to create CoachContentWithWorkoutPlan", err)
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "关联成功", "data": nil})
 }
 
+type FetchContentProfileOfWorkoutPlanRequest struct {
+	Id int `json:"id"`
+}
+
+type WorkoutPlanContentProfile struct {
+	Id          int        `json:"id"`
+	ContentType int        `json:"content_type"`
+	Title       string     `json:"title"`
+	Overview    string     `json:"overview"`
+	Details     string     `json:"details"`
+	VideoURL    string     `json:"video_url"`
+	Creator     CoachBrief `json:"creator"`
+}
+
 func (h *WorkoutPlanHandler) FetchContentProfileOfWorkoutPlan(c *gin.Context) {
 	// uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body FetchContentProfileOfWorkoutPlanRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "缺少 id 参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
 	var record models.CoachContentWithWorkoutPlan
-	if err := h.db.Where("id = ?", body.Id).Preload("Content").Preload("Content.Coach.Profile1").First(&record).Error; err != nil {
+	if err := h.db.WithContext(c).Where("id = ?", body.Id).Preload("Content").Preload("Content.Coach.Profile1").First(&record).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "没有找到记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "Success",
-		"data": gin.H{
-			"id":           record.Id,
-			"content_type": record.Content.ContentType,
-			"title":        record.Content.Title,
-			"overview":     record.Content.Description,
-			"details":      record.Details,
-			"video_url":    record.Content.VideoKey,
-			"creator": map[string]interface{}{
-				"nickname":   record.Content.Coach.Profile1.Nickname,
-				"avatar_url": record.Content.Coach.Profile1.AvatarURL,
-			},
+		"data": WorkoutPlanContentProfile{
+			Id:          record.Id,
+			ContentType: record.Content.ContentType,
+			Title:       record.Content.Title,
+			Overview:    record.Content.Description,
+			Details:     record.Details,
+			VideoURL:    record.Content.VideoKey,
+			Creator:     newCoachBrief(record.Content.CoachId, record.Content.Coach.Profile1),
 		},
 	})
 }
 
+type CreateWorkoutScheduleRequest struct {
+	Title    string `json:"title" binding:"required"`
+	Overview string `json:"overview"`
+	Status   int    `json:"status" binding:"required,oneof=1 2"`
+	Level    int    `json:"level"`
+	Type     int    `json:"type"`
+	// Details []struct {
+	// 	Weekday        int   `json:"weekday"`
+	// 	Day            int   `json:"day"`
+	// 	Idx            int   `json:"idx"`
+	// 	WorkoutPlanIds []int `json:"workout_plan_ids"`
+	// } `json:"schedules"`
+	Details string `json:"details"`
+}
+
 func (h *WorkoutPlanHandler) CreateWorkoutSchedule(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		Title    string `json:"title" binding:"required"`
-		Overview string `json:"overview"`
-		Status   int    `json:"status" binding:"required,oneof=1 2"`
-		Level    int    `json:"level"`
-		Type     int    `json:"type"`
-		// Details []struct {
-		// 	Weekday        int   `json:"weekday"`
-		// 	Day            int   `json:"day"`
-		// 	Idx            int   `json:"idx"`
-		// 	WorkoutPlanIds []int `json:"workout_plan_ids"`
-		// } `json:"schedules"`
-		Details string `json:"details"`
-	}
+	var body CreateWorkoutScheduleRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Title == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少标题", "data": nil})
+		response.Fail(c, errcode.ErrTitleRequired)
 		return
 	}
 	// if len(body.Schedules) == 0 {
@@ -544,11 +618,11 @@ func (h *WorkoutPlanHandler) CreateWorkoutSchedule(c *gin.Context) {
 	// 	return
 	// }
 	// 开始事务
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	// 创建训练计划集合
@@ -564,7 +638,7 @@ func (h *WorkoutPlanHandler) CreateWorkoutSchedule(c *gin.Context) {
 	}
 	if err := tx.Create(&record).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "创建训练计划集合失败: " + err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	// 创建训练计划集合中的训练计划
@@ -586,40 +660,42 @@ func (h *WorkoutPlanHandler) CreateWorkoutSchedule(c *gin.Context) {
 	// }
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		h.logger.Error("Failed to commit transaction", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		h.logger.WithContext(c).Error("Failed to commit transaction", err)
+		response.Fail(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": gin.H{"id": record.Id}})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": IdResponse{Id: record.Id}})
+}
+
+type UpdateWorkoutScheduleRequest struct {
+	Id           int    `json:"id"`
+	Title        string `json:"title"`
+	Overview     string `json:"overview"`
+	Level        int    `json:"level"`
+	Type         int    `json:"type"`
+	WorkoutPlans []struct {
+		Weekday        int   `json:"weekday"`
+		Day            int   `json:"day"`
+		WorkoutPlanIds []int `json:"workout_plan_ids"`
+	} `json:"workout_plans"`
 }
 
 func (h *WorkoutPlanHandler) UpdateWorkoutSchedule(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		Id           int    `json:"id"`
-		Title        string `json:"title"`
-		Overview     string `json:"overview"`
-		Level        int    `json:"level"`
-		Type         int    `json:"type"`
-		WorkoutPlans []struct {
-			Weekday        int   `json:"weekday"`
-			Day            int   `json:"day"`
-			WorkoutPlanIds []int `json:"workout_plan_ids"`
-		} `json:"workout_plans"`
-	}
+	var body UpdateWorkoutScheduleRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少 id 参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
 
 	var existing models.WorkoutSchedule
-	if err := h.db.Where("id = ? AND owner_id = ?", body.Id, uid).First(&existing).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Where("id = ? AND owner_id = ?", body.Id, uid).First(&existing).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
 
@@ -637,22 +713,22 @@ func (h *WorkoutPlanHandler) UpdateWorkoutSchedule(c *gin.Context) {
 		updates["type"] = body.Type
 	}
 
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 	if err := tx.Model(&existing).Updates(updates).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "更新周期计划失败，" + err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 
 	if err := tx.Where("workout_plan_collection_id = ?", body.Id).Delete(&models.WorkoutPlanInSchedule{}).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "删除现有训练计划关联失败，" + err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	// Create new workout plans in collection
@@ -666,35 +742,68 @@ func (h *WorkoutPlanHandler) UpdateWorkoutSchedule(c *gin.Context) {
 			}
 			if err := tx.Create(&plan_in_collection).Error; err != nil {
 				tx.Rollback()
-				c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+				response.Fail(c, err)
 				return
 			}
 		}
 	}
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "更新成功", "data": nil})
 }
 
+type WorkoutScheduleDayPlan struct {
+	Id                int    `json:"id"`
+	Title             string `json:"title"`
+	Overview          string `json:"overview"`
+	Tags              string `json:"tags"`
+	EstimatedDuration int    `json:"estimated_duration"`
+}
+
+// WorkoutScheduleDay 周期计划里某一天的训练计划
+type WorkoutScheduleDay struct {
+	Idx         int                    `json:"idx"`
+	Day         int                    `json:"day"`
+	Weekday     int                    `json:"weekday"`
+	WorkoutPlan WorkoutScheduleDayPlan `json:"workout_plan"`
+}
+
+// WorkoutScheduleProfile 周期计划详情，applied 和 applied_in_interval 只在自己应用过时返回
+type WorkoutScheduleProfile struct {
+	Id                int                  `json:"id"`
+	Title             string               `json:"title"`
+	Overview          string               `json:"overview"`
+	Level             int                  `json:"level"`
+	Type              int                  `json:"type"`
+	Details           string               `json:"details"`
+	Creator           CreatorBrief         `json:"creator"`
+	CreatedAt         time.Time            `json:"created_at"`
+	Schedules         []WorkoutScheduleDay `json:"schedules"`
+	Applied           *int                 `json:"applied,omitempty"`
+	AppliedInInterval *int                 `json:"applied_in_interval,omitempty"`
+}
+
+type FetchWorkoutScheduleProfileRequest struct {
+	Id int `json:"id"`
+}
+
 func (h *WorkoutPlanHandler) FetchWorkoutScheduleProfile(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body FetchWorkoutScheduleProfileRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少id参数", "data": nil})
+		response.Fail(c, errcode.MissingParam("id"))
 		return
 	}
 	var record models.WorkoutSchedule
-	query := h.db.Where("d IS NULL OR d = 0")
+	query := h.db.WithContext(c).Where("d IS NULL OR d = 0")
 	query = query.Where("id = ?", body.Id)
 	if err := query.
 		Preload("WorkoutPlans").
@@ -702,75 +811,82 @@ func (h *WorkoutPlanHandler) FetchWorkoutScheduleProfile(c *gin.Context) {
 		Preload("Creator.Profile1").
 		First(&record).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "找不到记录", "data": nil})
+		response.Fail(c, errcode.ErrNotFound)
 		return
 	}
 	if record.Status != int(models.WorkoutPublishStatusPublic) {
 		if record.OwnerId != uid {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "没有权限查看", "data": nil})
+			response.Fail(c, errcode.ErrForbidden)
 			return
 		}
 	}
-	data := map[string]interface{}{
-		"id":       record.Id,
-		"title":    record.Title,
-		"overview": record.Overview,
-		"level":    record.Level,
-		"type":     record.Type,
-		"details":  record.Details,
-		"creator": map[string]interface{}{
-			"nickname":   record.Creator.Profile1.Nickname,
-			"avatar_url": record.Creator.Profile1.AvatarURL,
-			"is_self":    record.Creator.Id == uid,
-		},
-		"created_at": record.CreatedAt,
+	data := WorkoutScheduleProfile{
+		Id:        record.Id,
+		Title:     record.Title,
+		Overview:  record.Overview,
+		Level:     record.Level,
+		Type:      record.Type,
+		Details:   record.Details,
+		Creator:   newCreatorBrief(record.Creator, uid),
+		CreatedAt: record.CreatedAt,
+		Schedules: []WorkoutScheduleDay{},
 	}
-	schedules := []interface{}{}
 	for _, schedule := range record.WorkoutPlans {
-		schedules = append(schedules, map[string]interface{}{
-			"idx":     schedule.Idx,
-			"day":     schedule.Day,
-			"weekday": schedule.Weekday,
-			"workout_plan": gin.H{
-				"id":                 schedule.WorkoutPlan.Id,
-				"title":              schedule.WorkoutPlan.Title,
-				"overview":           schedule.WorkoutPlan.Overview,
-				"tags":               schedule.WorkoutPlan.Tags,
-				"estimated_duration": schedule.WorkoutPlan.EstimatedDuration,
+		data.Schedules = append(data.Schedules, WorkoutScheduleDay{
+			Idx:     schedule.Idx,
+			Day:     schedule.Day,
+			Weekday: schedule.Weekday,
+			WorkoutPlan: WorkoutScheduleDayPlan{
+				Id:                schedule.WorkoutPlan.Id,
+				Title:             schedule.WorkoutPlan.Title,
+				Overview:          schedule.WorkoutPlan.Overview,
+				Tags:              schedule.WorkoutPlan.Tags,
+				EstimatedDuration: schedule.WorkoutPlan.EstimatedDuration,
 			},
 		})
 	}
-	data["schedules"] = schedules
 	if uid != 0 {
 		var record2 models.CoachWorkoutSchedule
-		h.db.Where("coach_id = ? AND workout_plan_collection_id = ?", uid, body.Id).First(&record2)
+		h.db.WithContext(c).Where("coach_id = ? AND workout_plan_collection_id = ?", uid, body.Id).First(&record2)
 		if record2.Id != 0 {
-			data["applied"] = record2.Status
-			data["applied_in_interval"] = record2.Interval
+			data.Applied = &record2.Status
+			data.AppliedInInterval = &record2.Interval
 		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "获取成功", "data": data})
 }
 
+type WorkoutScheduleItem struct {
+	Id       int        `json:"id"`
+	Type     int        `json:"type"`
+	Title    string     `json:"title"`
+	Overview string     `json:"overview"`
+	Level    int        `json:"level"`
+	Creator  CoachBrief `json:"creator"`
+}
+
+type FetchWorkoutScheduleListRequest struct {
+	models.Pagination
+	Keyword string `json:"keyword"`
+	Level   int    `json:"level"`
+	Tag     string `json:"tag"`
+}
+
 func (h *WorkoutPlanHandler) FetchWorkoutScheduleList(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
-	var body struct {
-		models.Pagination
-		Keyword string `json:"keyword"`
-		Level   int    `json:"level"`
-		Tag     string `json:"tag"`
-	}
+	var body FetchWorkoutScheduleListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
-	query := h.db.Where("d IS NULL OR d = 0")
+	query := h.db.WithContext(c).Where("d IS NULL OR d = 0")
 	if uid != 0 {
 		query = query.Where("(status = 1) OR (status = 2 AND owner_id = ?)", uid)
+		query = query.Where("owner_id NOT IN (?)", models.BlockedCoachIds(h.db.WithContext(c), uid))
 	} else {
 		query = query.Where("status = 1")
 	}
@@ -790,60 +906,63 @@ func (h *WorkoutPlanHandler) FetchWorkoutScheduleList(c *gin.Context) {
 
 	var list1 []models.WorkoutSchedule
 	if err := pb.Build().Preload("Creator.Profile1").Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
-	list := make([]map[string]interface{}, 0, len(list2))
+	list := make([]WorkoutScheduleItem, 0, len(list2))
 	for _, v := range list2 {
-		list = append(list, map[string]interface{}{
-			"id":       v.Id,
-			"type":     v.Type,
-			"title":    v.Title,
-			"overview": v.Overview,
-			"level":    v.Level,
-			"creator": map[string]interface{}{
-				"nickname":   v.Creator.Profile1.Nickname,
-				"avatar_url": v.Creator.Profile1.AvatarURL,
-			},
+		list = append(list, WorkoutScheduleItem{
+			Id:       v.Id,
+			Type:     v.Type,
+			Title:    v.Title,
+			Overview: v.Overview,
+			Level:    v.Level,
+			Creator:  newCoachBrief(v.OwnerId, v.Creator.Profile1),
 		})
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "Success",
-		"data": gin.H{
-			"list":        list,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list, has_more, next_marker),
 	})
 }
 
+type ApplyWorkoutScheduleRequest struct {
+	Id       int `json:"id"`
+	Interval int `json:"interval"`
+	// 只有 天循环 会需要？按用户时区取当天 0 点，不传时从今天开始
+	StartDate time.Time `json:"start_date"`
+}
+
 // 应用某个周期计划
 func (h *WorkoutPlanHandler) ApplyWorkoutSchedule(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		Id       int `json:"id"`
-		Interval int `json:"interval"`
-		// 只有 天循环 会需要？
-		StartDate time.Time `json:"start_date"`
-	}
+	var body ApplyWorkoutScheduleRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "缺少周期计划 id", "data": nil})
+		response.Fail(c, errcode.MissingParam("workout_schedule_id"))
 		return
 	}
+	var coach models.Coach
+	if err := h.db.WithContext(c).Where("id = ?", uid).First(&coach).Error; err != nil {
+		response.Fail(c, err)
+		return
+	}
+	if body.StartDate.IsZero() {
+		body.StartDate = time.Now()
+	}
+	body.StartDate = models.StartOfDay(body.StartDate, coach.Location())
 
 	var existing models.CoachWorkoutSchedule
-	if err := h.db.Where("coach_id = ? AND workout_plan_collection_id = ?", uid, body.Id).First(&existing).Error; err != nil {
+	if err := h.db.WithContext(c).Where("coach_id = ? AND workout_plan_collection_id = ?", uid, body.Id).First(&existing).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
 		// 创建
@@ -854,9 +973,9 @@ func (h *WorkoutPlanHandler) ApplyWorkoutSchedule(c *gin.Context) {
 			Status:                  1,
 			AppliedAt:               time.Now(),
 		}
-		if err := h.db.Create(&record).Error; err != nil {
-			h.logger.Error("Failed to create record", err)
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to create record", "data": nil})
+		if err := h.db.WithContext(c).Create(&record).Error; err != nil {
+			h.logger.WithContext(c).Error("Failed to create record", err)
+			response.Fail(c, err)
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "应用周期计划成功", "data": nil})
@@ -864,7 +983,7 @@ func (h *WorkoutPlanHandler) ApplyWorkoutSchedule(c *gin.Context) {
 	}
 
 	if existing.Status == 1 {
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "该周期计划已应用", "data": nil})
+		response.Fail(c, errcode.ErrScheduleApplied)
 		return
 	}
 	// 更新
@@ -873,42 +992,44 @@ func (h *WorkoutPlanHandler) ApplyWorkoutSchedule(c *gin.Context) {
 		"start_date": body.StartDate,
 		"applied_at": time.Now(),
 	}
-	if err := h.db.Model(&existing).Updates(updates).Error; err != nil {
-		h.logger.Error("Failed to update", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "应用失败", "data": nil})
+	if err := h.db.WithContext(c).Model(&existing).Updates(updates).Error; err != nil {
+		h.logger.WithContext(c).Error("Failed to update", err)
+		response.Fail(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "应用周期计划成功", "data": nil})
 
 }
 
+type CancelWorkoutScheduleRequest struct {
+	Id int `json:"id"`
+}
+
 // 取消应用某个周期计划
 func (h *WorkoutPlanHandler) CancelWorkoutSchedule(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 
-	var body struct {
-		Id int `json:"id"`
-	}
+	var body CancelWorkoutScheduleRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Id == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "缺少周期计划 id", "data": nil})
+		response.Fail(c, errcode.MissingParam("workout_schedule_id"))
 		return
 	}
 
 	var existing models.CoachWorkoutSchedule
-	if err := h.db.Where("coach_id = ? AND workout_plan_collection_id = ?", uid, body.Id).First(&existing).Error; err != nil {
+	if err := h.db.WithContext(c).Where("coach_id = ? AND workout_plan_collection_id = ?", uid, body.Id).First(&existing).Error; err != nil {
 		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "周期计划不存在", "data": nil})
+		response.Fail(c, errcode.ErrScheduleNotFound)
 		return
 	}
 	if existing.CoachId != uid {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Workout plan not found", "data": nil})
+		response.Fail(c, errcode.ErrWorkoutPlanNotFound)
 		return
 	}
 
@@ -917,179 +1038,212 @@ func (h *WorkoutPlanHandler) CancelWorkoutSchedule(c *gin.Context) {
 		"status":       2,
 		"cancelled_at": time.Now(),
 	}
-	if err := h.db.Model(&existing).Updates(updates).Error; err != nil {
-		h.logger.Error("Failed to update", err)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "取消失败", "data": nil})
+	if err := h.db.WithContext(c).Model(&existing).Updates(updates).Error; err != nil {
+		h.logger.WithContext(c).Error("Failed to update", err)
+		response.Fail(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "取消周期计划成功", "data": nil})
 }
 
+type AppliedWorkoutScheduleDay struct {
+	Day           int    `json:"day"`
+	Weekday       int    `json:"weekday"`
+	WorkoutPlanId int    `json:"workout_plan_id"`
+	Title         string `json:"title"`
+	Overview      string `json:"overview"`
+	Tags          string `json:"tags"`
+}
+
+type AppliedWorkoutSchedule struct {
+	Id                int                         `json:"id"`
+	Status            int                         `json:"status"`
+	WorkoutScheduleId int                         `json:"workout_schedule_id"`
+	Title             string                      `json:"title"`
+	Overview          string                      `json:"overview"`
+	Type              int                         `json:"type"`
+	Level             int                         `json:"level"`
+	Details           string                      `json:"details"`
+	Schedules         []AppliedWorkoutScheduleDay `json:"schedules"`
+	AppliedAt         time.Time                   `json:"applied_at"`
+	StartDate         *time.Time                  `json:"start_date"`
+}
+
+type FetchAppliedWorkoutScheduleListResponse struct {
+	List []AppliedWorkoutSchedule `json:"list"`
+}
+
 // 获取当前应用中的周期计划
 func (h *WorkoutPlanHandler) FetchAppliedWorkoutScheduleList(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 	var list []models.CoachWorkoutSchedule
 	// @todo 周期计划，历史数据处理完了后，这里就改成 Preload("WorkoutPlanCollection")
-	if err := h.db.Where("status = 1 AND coach_id = ?", uid).Preload("WorkoutPlanCollection.WorkoutPlans.WorkoutPlan").Find(&list).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Where("status = 1 AND coach_id = ?", uid).Preload("WorkoutPlanCollection.WorkoutPlans.WorkoutPlan").Find(&list).Error; err != nil {
+		response.Fail(c, err)
 		return
 	}
-	data := []interface{}{}
+	data := []AppliedWorkoutSchedule{}
 	for _, relation := range list {
-		schedules := []interface{}{}
+		schedules := []AppliedWorkoutScheduleDay{}
 		for _, schedule := range relation.WorkoutPlanCollection.WorkoutPlans {
-			schedules = append(schedules, map[string]interface{}{
-				"day":             schedule.Day,
-				"weekday":         schedule.Weekday,
-				"workout_plan_id": schedule.WorkoutPlanId,
-				"title":           schedule.WorkoutPlan.Title,
-				"overview":        schedule.WorkoutPlan.Overview,
-				"tags":            schedule.WorkoutPlan.Tags,
+			schedules = append(schedules, AppliedWorkoutScheduleDay{
+				Day:           schedule.Day,
+				Weekday:       schedule.Weekday,
+				WorkoutPlanId: schedule.WorkoutPlanId,
+				Title:         schedule.WorkoutPlan.Title,
+				Overview:      schedule.WorkoutPlan.Overview,
+				Tags:          schedule.WorkoutPlan.Tags,
 			})
 		}
-		d := map[string]interface{}{
-			"id":                  relation.Id,
-			"status":              relation.Status,
-			"workout_schedule_id": relation.WorkoutPlanCollection.Id,
-			"title":               relation.WorkoutPlanCollection.Title,
-			"overview":            relation.WorkoutPlanCollection.Overview,
-			"type":                relation.WorkoutPlanCollection.Type,
-			"level":               relation.WorkoutPlanCollection.Level,
-			"details":             relation.WorkoutPlanCollection.Details,
-			"schedules":           schedules,
-			"applied_at":          relation.AppliedAt,
-			"start_date":          nil,
-		}
-		if relation.StartDate != nil {
-			d["start_date"] = relation.StartDate
-		}
-		data = append(data, d)
+		data = append(data, AppliedWorkoutSchedule{
+			Id:                relation.Id,
+			Status:            relation.Status,
+			WorkoutScheduleId: relation.WorkoutPlanCollection.Id,
+			Title:             relation.WorkoutPlanCollection.Title,
+			Overview:          relation.WorkoutPlanCollection.Overview,
+			Type:              relation.WorkoutPlanCollection.Type,
+			Level:             relation.WorkoutPlanCollection.Level,
+			Details:           relation.WorkoutPlanCollection.Details,
+			Schedules:         schedules,
+			AppliedAt:         relation.AppliedAt,
+			StartDate:         relation.StartDate,
+		})
 	}
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": gin.H{
-		"list": data,
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "", "data": FetchAppliedWorkoutScheduleListResponse{
+		List: data,
 	}})
 }
 
+// WorkoutPlanSetDetail 训练计划集合里的一项，type 1训练计划 2周期计划，以 JSON 存在 details 字段里
+type WorkoutPlanSetDetail struct {
+	Type     int                   `json:"type"`
+	Id       int                   `json:"id"`
+	Title    string                `json:"title"`
+	Overview string                `json:"overview"`
+	Tags     string                `json:"tags"`
+	Creator  WorkoutPlanSetCreator `json:"creator"`
+}
+
+type WorkoutPlanSetCreator struct {
+	Nickname  string `json:"nickname"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+type WorkoutPlanSetItem struct {
+	Id      int                    `json:"id"`
+	Title   string                 `json:"title"`
+	Idx     int                    `json:"idx"`
+	Details []WorkoutPlanSetDetail `json:"details"`
+}
+
+type FetchWorkoutPlanSetListRequest struct {
+	models.Pagination
+}
+
 func (h *WorkoutPlanHandler) FetchWorkoutPlanSetList(c *gin.Context) {
-	var body struct {
-		models.Pagination
-	}
+	var body FetchWorkoutPlanSetListRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
-	query := h.db.Where("d IS NULL OR d = 0")
+	query := h.db.WithContext(c).Where("d IS NULL OR d = 0")
 	pb := pagination.NewPaginationBuilder[models.WorkoutPlanSet](query).
 		SetLimit(body.PageSize).
 		SetPage(body.Page).
 		SetOrderBy("idx DESC")
 	var list1 []models.WorkoutPlanSet
 	if err := pb.Build().Find(&list1).Error; err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to fetch workout plans", "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	list2, has_more, next_marker := pb.ProcessResults(list1)
-	list := make([]map[string]interface{}, 0, len(list2))
-	type WorkoutPlanSetJSON250607 struct {
-		Type     int    `json:"type"`
-		Id       int    `json:"id"`
-		Title    string `json:"title"`
-		Overview string `json:"overview"`
-		Tags     string `json:"tags"`
-		Creator  struct {
-			Nickname  string `json:"nickname"`
-			AvatarURL string `json:"avatar_url"`
-		} `json:"creator"`
-	}
+	list := make([]WorkoutPlanSetItem, 0, len(list2))
 	for _, v := range list2 {
-		var details []WorkoutPlanSetJSON250607
+		var details []WorkoutPlanSetDetail
 		if err := json.Unmarshal([]byte(v.Details), &details); err != nil {
-			c.JSON(http.StatusOK, gin.H{"code": 600, "msg": err.Error(), "data": nil})
+			response.Fail(c, err)
 			return
 		}
-		list = append(list, map[string]interface{}{
-			"id":      v.Id,
-			"title":   v.Title,
-			"idx":     v.Idx,
-			"details": details,
+		list = append(list, WorkoutPlanSetItem{
+			Id:      v.Id,
+			Title:   v.Title,
+			Idx:     v.Idx,
+			Details: details,
 		})
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"msg":  "Success",
-		"data": gin.H{
-			"list":        list,
-			"page_size":   pb.GetLimit(),
-			"has_more":    has_more,
-			"next_marker": next_marker,
-		},
+		"data": pagination.NewList(pb, list, has_more, next_marker),
 	})
 }
 
+type CreateWorkoutPlanSetRequest struct {
+	Title    string `json:"title"`
+	Overview string `json:"overview"`
+	IconURL  string `json:"icon_url"`
+	Idx      int    `json:"idx"`
+	Details  []struct {
+		Type int `json:"type"`
+		Id   int `json:"id"`
+	} `json:"details"`
+}
+
 func (h *WorkoutPlanHandler) CreateWorkoutPlanSet(c *gin.Context) {
 	uid := int(c.GetFloat64("id"))
 	if uid == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "请先登录", "data": nil})
+		response.Fail(c, errcode.ErrUnauthorized)
 		return
 	}
-	var body struct {
-		Title    string `json:"title"`
-		Overview string `json:"overview"`
-		IconURL  string `json:"icon_url"`
-		Idx      int    `json:"idx"`
-		Details  []struct {
-			Type int `json:"type"`
-			Id   int `json:"id"`
-		} `json:"details"`
-	}
+	var body CreateWorkoutPlanSetRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 	if body.Title == "" {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少标题", "data": nil})
+		response.Fail(c, errcode.ErrTitleRequired)
 		return
 	}
 	if len(body.Details) == 0 {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "缺少内容", "data": nil})
+		response.Fail(c, errcode.ErrContentRequired)
 		return
 	}
-	var details []map[string]interface{}
+	var details []WorkoutPlanSetDetail
 	for _, v := range body.Details {
 		if v.Type == 1 {
 			var existing models.WorkoutPlan
-			if err := h.db.Where("id = ?", v.Id).Preload("Creator.Profile1").First(&existing).Error; err != nil {
-				c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "数据异常", "data": nil})
+			if err := h.db.WithContext(c).Where("id = ?", v.Id).Preload("Creator.Profile1").First(&existing).Error; err != nil {
+				response.Fail(c, errcode.ErrDataCorrupted)
 				return
 			}
-			details = append(details, map[string]interface{}{
-				"type":     v.Type,
-				"id":       v.Id,
-				"title":    existing.Title,
-				"overview": existing.Overview,
-				"tags":     existing.Tags,
-				"creator": map[string]interface{}{
-					"nickname":   existing.Creator.Profile1.Nickname,
-					"avatar_url": existing.Creator.Profile1.AvatarURL,
+			details = append(details, WorkoutPlanSetDetail{
+				Type:     v.Type,
+				Id:       v.Id,
+				Title:    existing.Title,
+				Overview: existing.Overview,
+				Tags:     existing.Tags,
+				Creator: WorkoutPlanSetCreator{
+					Nickname:  existing.Creator.Profile1.Nickname,
+					AvatarURL: existing.Creator.Profile1.AvatarURL,
 				},
 			})
 		}
 		if v.Type == 2 {
 			var existing models.WorkoutSchedule
-			if err := h.db.Where("id = ?", v.Id).Preload("Creator.Profile1").First(&existing).Error; err != nil {
-				c.JSON(http.StatusOK, gin.H{"code": 400, "msg": "数据异常", "data": nil})
+			if err := h.db.WithContext(c).Where("id = ?", v.Id).Preload("Creator.Profile1").First(&existing).Error; err != nil {
+				response.Fail(c, errcode.ErrDataCorrupted)
 				return
 			}
-			details = append(details, map[string]interface{}{
-				"type":     v.Type,
-				"id":       v.Id,
-				"title":    existing.Title,
-				"overview": existing.Overview,
-				"tags":     "",
-				"creator": map[string]interface{}{
-					"nickname":   existing.Creator.Profile1.Nickname,
-					"avatar_url": existing.Creator.Profile1.AvatarURL,
+			details = append(details, WorkoutPlanSetDetail{
+				Type:     v.Type,
+				Id:       v.Id,
+				Title:    existing.Title,
+				Overview: existing.Overview,
+				Tags:     "",
+				Creator: WorkoutPlanSetCreator{
+					Nickname:  existing.Creator.Profile1.Nickname,
+					AvatarURL: existing.Creator.Profile1.AvatarURL,
 				},
 			})
 		}
@@ -1097,7 +1251,7 @@ func (h *WorkoutPlanHandler) CreateWorkoutPlanSet(c *gin.Context) {
 
 	detailsJSON, err := json.Marshal(details)
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to serialize details: " + err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	record := models.WorkoutPlanSet{
@@ -1109,39 +1263,39 @@ func (h *WorkoutPlanHandler) CreateWorkoutPlanSet(c *gin.Context) {
 		CreatedAt: time.Now().UTC(),
 	}
 
-	if err := h.db.Create(&record).Error; err != nil {
-		// h.logger.Error("Failed to create workout plan", "error", result.Error)
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+	if err := h.db.WithContext(c).Create(&record).Error; err != nil {
+		// h.logger.WithContext(c).Error("Failed to create workout plan", "error", result.Error)
+		response.Fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": gin.H{"id": record.Id}})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "创建成功", "data": IdResponse{Id: record.Id}})
 }
 
 func (h *WorkoutPlanHandler) UpdateWorkoutPlanSet(c *gin.Context) {
 	// 绑定更新的数据
 	var body models.WorkoutPlanSet
 	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusOK, gin.H{"code": 400, "msg": err.Error(), "data": nil})
+		response.Fail(c, errcode.ErrInvalidBody.Wrap(err))
 		return
 	}
 
 	// 先获取现有的计划
 	var existing_plan models.WorkoutPlanSet
-	if result := h.db.First(&existing_plan, body.Id); result.Error != nil {
+	if result := h.db.WithContext(c).First(&existing_plan, body.Id); result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusOK, gin.H{"code": 404, "msg": "Workout plan not found", "data": nil})
+			response.Fail(c, errcode.ErrWorkoutPlanNotFound)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": result.Error.Error(), "data": nil})
+		response.Fail(c, result.Error)
 		return
 	}
 
-	tx := h.db.Begin()
+	tx := h.db.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
-			c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Internal server error", "data": nil})
+			response.Fail(c, errcode.ErrInternal)
 		}
 	}()
 
@@ -1154,14 +1308,14 @@ func (h *WorkoutPlanHandler) UpdateWorkoutPlanSet(c *gin.Context) {
 	// 保存主记录
 	if err := tx.Save(&existing_plan).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": "Failed to update plan: " + err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusOK, gin.H{"code": 500, "msg": err.Error(), "data": nil})
+		response.Fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "更新成功", "data": gin.H{"id": existing_plan.Id}})
+	c.JSON(http.StatusOK, gin.H{"code": 200, "msg": "更新成功", "data": IdResponse{Id: existing_plan.Id}})
 }
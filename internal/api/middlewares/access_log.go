@@ -0,0 +1,36 @@
+package middlewares
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"myapi/pkg/logger"
+)
+
+// AccessLogMiddleware 记录每个请求的结果，代替 gin.Logger()，带上请求 ID、当前用户和路由
+func AccessLogMiddleware(logger *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		fields := []interface{}{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", status,
+			"latency", time.Since(start),
+			"client_ip", c.ClientIP(),
+		}
+		log := logger.WithContext(c)
+		switch {
+		case status >= http.StatusInternalServerError:
+			log.Errorw("Request", fields...)
+		case status >= http.StatusBadRequest:
+			log.Warnw("Request", fields...)
+		default:
+			log.Infow("Request", fields...)
+		}
+	}
+}
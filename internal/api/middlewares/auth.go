@@ -1,24 +1,26 @@
 package middlewares
 
 import (
-	"net/http"
 	"strings"
 	"time"
 
 	"myapi/config"
 	"myapi/internal/models"
+	"myapi/internal/pkg/errcode"
+	"myapi/internal/pkg/response"
 	"myapi/pkg/logger"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // AuthMiddleware checks for a valid JWT token in the Authorization header
-func AuthMiddleware(logger *logger.Logger, config *config.Config) gin.HandlerFunc {
+// and that the session it belongs to has not been revoked
+func AuthMiddleware(db *gorm.DB, logger *logger.Logger, config *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		auth_header := c.GetHeader("Authorization")
 		if auth_header == "" {
-			c.JSON(http.StatusOK, gin.H{"code": 401, "msg": "缺少登录凭证", "data": nil})
-			c.Abort()
+			response.Abort(c, errcode.ErrTokenMissing)
 			return
 		}
 		// fmt.Println("process in auth middleware")
@@ -27,30 +29,81 @@ func AuthMiddleware(logger *logger.Logger, config *config.Config) gin.HandlerFun
 		// Format: "Bearer <token>"
 		parts := strings.Split(auth_header, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusOK, gin.H{"code": 401, "msg": "非法凭证请重新登录", "data": nil})
-			c.Abort()
+			response.Abort(c, errcode.ErrTokenInvalid)
 			return
 		}
 
 		token_str := strings.TrimPrefix(auth_header, "Bearer ")
 		claims, err := models.ParseJWT(token_str, config.TokenSecretKey)
 		if err != nil {
-			c.JSON(http.StatusOK, gin.H{"code": 401, "msg": "凭证失效请重新登录", "data": nil})
-			c.Abort()
+			response.Abort(c, errcode.ErrTokenInvalid)
 			return
 		}
 		// 检查过期时间
 		if claims.ExpiresAt < float64(time.Now().Unix()) {
-			c.JSON(http.StatusOK, gin.H{"code": 401, "msg": "凭证过期请重新登录", "data": nil})
-			c.Abort()
+			response.Abort(c, errcode.ErrTokenExpired)
 			return
 		}
 		if claims.Id == 0 {
-			c.JSON(http.StatusOK, gin.H{"code": 401, "msg": "请先登录", "data": nil})
-			c.Abort()
+			response.Abort(c, errcode.ErrUnauthorized)
+			return
+		}
+		if claims.SessionId == "" {
+			response.Abort(c, errcode.ErrTokenInvalid)
+			return
+		}
+		// 会话被注销后 access token 立即失效
+		var session models.CoachSession
+		if err := db.WithContext(c).Where("session_id = ? AND coach_id = ? AND status = ? AND expired_at > ?", claims.SessionId, int(claims.Id), models.SessionStatusActive, time.Now()).
+			First(&session).Error; err != nil {
+			if err != gorm.ErrRecordNotFound {
+				response.Abort(c, err)
+				return
+			}
+			response.Abort(c, errcode.ErrTokenInvalid)
+			return
+		}
+		path := strings.TrimPrefix(c.FullPath(), "/api")
+		// 限制了权限范围的会话只能访问部分接口
+		if !models.ScopeAllows(claims.Scope, path) {
+			response.Abort(c, errcode.ErrForbidden)
 			return
 		}
 		c.Set("id", claims.Id)
+		c.Set("session_id", claims.SessionId)
+		c.Set("scope", claims.Scope)
+		c.Set("actor_id", claims.ActorId)
+		c.Set("mfa_verified", session.MfaVerified == 1)
+		c.Next()
+		if claims.ActorId != 0 {
+			// 管理员代登录期间的操作都记下来
+			record := models.CoachImpersonationLog{
+				ActorId:   int(claims.ActorId),
+				SessionId: claims.SessionId,
+				Method:    c.Request.Method,
+				Path:      c.Request.URL.Path,
+				Status:    c.Writer.Status(),
+				IP:        c.ClientIP(),
+				CoachId:   int(claims.Id),
+				CreatedAt: time.Now(),
+			}
+			logger.WithContext(c).Infow("Impersonated request", "actor_id", record.ActorId, "coach_id", record.CoachId, "path", record.Path)
+			if err := db.WithContext(c).Create(&record).Error; err != nil {
+				logger.WithContext(c).Error("Failed to save impersonation log", err)
+			}
+		}
+	}
+}
+
+// AdminMFAMiddleware 开启了 ADMIN_REQUIRE_2FA 时，管理员接口要求会话通过了两步验证
+//
+// 挂在 SetupRouter 里的管理员路由组上，要放在 AuthMiddleware 之后
+func AdminMFAMiddleware(config *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if config.AdminRequire2FA && int(c.GetFloat64("id")) == 1 && !c.GetBool("mfa_verified") {
+			response.Abort(c, errcode.ErrMFARequired)
+			return
+		}
 		c.Next()
 	}
 }
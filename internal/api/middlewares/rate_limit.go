@@ -0,0 +1,50 @@
+package middlewares
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"myapi/internal/pkg/errcode"
+	"myapi/internal/pkg/ratelimit"
+	"myapi/internal/pkg/response"
+)
+
+// RateLimitMiddleware 按令牌桶限制请求频率，超出时返回 429
+//
+// 响应头里带上 X-RateLimit-Limit、X-RateLimit-Remaining、X-RateLimit-Reset（桶装满的秒数），
+// 被拒绝时还有 Retry-After。按用户限制的路由要放在 AuthMiddleware 之后
+func RateLimitMiddleware(store ratelimit.Store, policy ratelimit.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		now := time.Now()
+		ip_key := policy.Name + ":ip:" + c.ClientIP()
+		var keys []string
+		uid := int(c.GetFloat64("id"))
+		switch {
+		case policy.Key == ratelimit.KeyIP || uid == 0:
+			keys = []string{ip_key}
+		case policy.Key == ratelimit.KeyUser:
+			keys = []string{policy.Name + ":user:" + strconv.Itoa(uid)}
+		default:
+			keys = []string{policy.Name + ":user:" + strconv.Itoa(uid), ip_key}
+		}
+
+		result := store.Take(keys, policy, now)
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+		if !result.Allowed {
+			retry_after := ceilSeconds(result.RetryAfter)
+			c.Header("Retry-After", strconv.Itoa(retry_after))
+			response.Abort(c, errcode.ErrRateLimited.WithArgs(retry_after))
+			return
+		}
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+
+	"myapi/internal/pkg/errcode"
+	"myapi/internal/pkg/response"
+	"myapi/pkg/logger"
+)
+
+// RecoveryMiddleware 把 panic 和处理函数记录的错误统一转换成错误码响应
+//
+// panic(gorm.ErrRecordNotFound) 和 c.Error(gorm.ErrRecordNotFound) 都会返回 404，其他未知错误返回 500
+func RecoveryMiddleware(logger *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				err, ok := r.(error)
+				if !ok {
+					err = fmt.Errorf("%v", r)
+				}
+				e := errcode.From(err)
+				if e.Status >= http.StatusInternalServerError {
+					logger.WithContext(c).Errorw("Recovered from panic", "path", c.Request.URL.Path, "error", err.Error(), "stack", string(debug.Stack()))
+				}
+				if !c.Writer.Written() {
+					response.Abort(c, e)
+				}
+				c.Abort()
+			}
+		}()
+		c.Next()
+		if len(c.Errors) == 0 {
+			return
+		}
+		for _, v := range c.Errors {
+			if e := errcode.From(v.Err); e.Status >= http.StatusInternalServerError {
+				logger.WithContext(c).Errorw("Request failed", "path", c.Request.URL.Path, "code", e.Code, "error", v.Err.Error())
+			}
+		}
+		if !c.Writer.Written() {
+			response.Fail(c, c.Errors.Last().Err)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"myapi/pkg/logger"
+)
+
+// RequestIdHeader 请求 ID 的请求头和响应头
+const RequestIdHeader = "X-Request-Id"
+
+// RequestIdMiddleware 给每个请求分配 ID，上游已经带了 X-Request-Id 时沿用
+//
+// ID 和路由记在 gin.Context 里，请求 ID 也放到请求的 context 里，logger.WithContext(c) 和 db.WithContext(c) 的日志都会带上
+func RequestIdMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		request_id := c.GetHeader(RequestIdHeader)
+		if request_id == "" || len(request_id) > 64 {
+			request_id = uuid.New().String()
+		}
+		c.Set(logger.RequestIdKey, request_id)
+		c.Set(logger.RouteKey, c.FullPath())
+		c.Request = c.Request.WithContext(logger.WithRequestId(c.Request.Context(), request_id))
+		c.Header(RequestIdHeader, request_id)
+		c.Next()
+	}
+}
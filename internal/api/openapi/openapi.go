@@ -0,0 +1,212 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"myapi/internal/pkg/errcode"
+)
+
+// Operation 一个接口的说明，Request、Response 传对应类型的零值
+// 没有请求体、成功时 data 为 null 的传 None{}，nil 只用于 system 分组里不是 {code, msg, data} 格式的接口
+type Operation struct {
+	Summary string
+	Tag     string
+	Public  bool
+	// Idempotent 重复请求没有副作用，客户端遇到网关错误时可以重试
+	Idempotent bool
+	Request    interface{}
+	Response   interface{}
+}
+
+// None 用在 Request 上表示没有请求体，用在 Response 上表示成功时 data 为 null
+type None struct{}
+
+// IsNone 判断 Request、Response 是不是 None
+func IsNone(v interface{}) bool {
+	_, ok := v.(None)
+	return ok
+}
+
+// File 用在 Response 上表示成功时直接返回文件内容，不包 {code, msg, data}
+type File struct {
+	ContentType string
+}
+
+// Document OpenAPI 3 文档
+type Document struct {
+	OpenAPI    string                        `json:"openapi"`
+	Info       Info                          `json:"info"`
+	Paths      map[string]map[string]*PathOp `json:"paths"`
+	Components Components                    `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+type PathOp struct {
+	OperationId string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+
+	// 请求体校验用，不输出
+	request *Schema
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Key 用于 Build 的 operations 参数，和 gin 的路由对应
+func Key(method, path string) string {
+	return method + " " + path
+}
+
+// Build 根据 gin 注册的路由生成文档，没有说明的路由返回在 missing 里
+func Build(title, version string, routes gin.RoutesInfo, operations map[string]Operation) (doc *Document, missing []string) {
+	b := newSchemaBuilder()
+	doc = &Document{
+		OpenAPI: "3.0.3",
+		Info:    Info{Title: title, Version: version},
+		Paths:   map[string]map[string]*PathOp{},
+		Components: Components{
+			Schemas: b.schemas,
+			SecuritySchemes: map[string]*SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+	b.schemas["Error"] = errorSchema()
+	for _, route := range routes {
+		op, ok := operations[Key(route.Method, route.Path)]
+		if !ok {
+			missing = append(missing, Key(route.Method, route.Path))
+			continue
+		}
+		success := &Response{Description: "成功", Content: jsonContent(envelope(b, op.Response))}
+		if f, ok := op.Response.(File); ok {
+			success.Content = map[string]*MediaType{f.ContentType: {Schema: &Schema{Type: "string", Format: "binary"}}}
+		}
+		item := &PathOp{
+			OperationId: operationId(route.Path),
+			Summary:     op.Summary,
+			Responses: map[string]*Response{
+				"200": success,
+				"default": {
+					Description: "错误，code 见 Error 里的枚举",
+					Content:     jsonContent(&Schema{Ref: "#/components/schemas/Error"}),
+				},
+			},
+		}
+		if op.Tag != "" {
+			item.Tags = []string{op.Tag}
+		}
+		if !op.Public {
+			item.Security = []map[string][]string{{"bearerAuth": {}}}
+		}
+		if op.Request != nil && !IsNone(op.Request) {
+			item.request = b.build(reflect.TypeOf(op.Request))
+			item.RequestBody = &RequestBody{Required: true, Content: jsonContent(item.request)}
+		}
+		if doc.Paths[route.Path] == nil {
+			doc.Paths[route.Path] = map[string]*PathOp{}
+		}
+		doc.Paths[route.Path][strings.ToLower(route.Method)] = item
+	}
+	return doc, missing
+}
+
+// Find 查找路由对应的接口说明
+func (d *Document) Find(method, path string) *PathOp {
+	return d.Paths[path][strings.ToLower(method)]
+}
+
+// Resolve 展开 $ref
+func (d *Document) Resolve(s *Schema) *Schema {
+	for s != nil && s.Ref != "" {
+		s = d.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+func jsonContent(s *Schema) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: s}}
+}
+
+// envelope 所有接口都用 {code, msg, data} 包一层
+func envelope(b *schemaBuilder, data interface{}) *Schema {
+	s := &Schema{}
+	if data != nil && !IsNone(data) {
+		s = b.build(reflect.TypeOf(data))
+	}
+	s.Nullable = s.Ref == "" && s.Type == ""
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code": {Type: "integer", Enum: []interface{}{200}},
+			"msg":  {Type: "string"},
+			"data": s,
+		},
+		Required: []string{"code", "msg", "data"},
+	}
+}
+
+func errorSchema() *Schema {
+	codes := []interface{}{}
+	lines := []string{}
+	for _, e := range errcode.List() {
+		codes = append(codes, e.Code)
+		lines = append(lines, fmt.Sprintf("- %d %s", e.Code, e.Message(errcode.DefaultLang)))
+	}
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code": {Type: "integer", Enum: codes, Description: strings.Join(lines, "\n")},
+			"msg":  {Type: "string"},
+			"data": {Nullable: true},
+		},
+		Required: []string{"code", "msg", "data"},
+	}
+}
+
+// operationId 由路径生成，比如 /api/workout_day/list 得到 workoutDayList，保证每个接口唯一
+func operationId(path string) string {
+	words := strings.FieldsFunc(strings.TrimPrefix(path, "/api"), func(r rune) bool {
+		return r == '/' || r == '_' || r == '-' || r == ':'
+	})
+	for i, w := range words {
+		if i > 0 {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, "")
+}
@@ -0,0 +1,219 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema OpenAPI 3 的 Schema Object，只包含用到的字段
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+}
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textType        = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// schemaBuilder 把 Go 类型转换成 Schema，具名结构体放到 components 里用 $ref 引用
+type schemaBuilder struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		schemas: map[string]*Schema{},
+		names:   map[reflect.Type]string{},
+	}
+}
+
+func (b *schemaBuilder) build(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	// 自定义了反序列化的类型（比如 LocalTime）没法从字段推断格式，按字符串处理
+	if t.Kind() == reflect.Struct && (reflect.PtrTo(t).Implements(unmarshalerType) || reflect.PtrTo(t).Implements(textType)) {
+		return &Schema{Type: "string"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.build(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.build(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + b.named(t)}
+	}
+	// interface{} 之类的，任意值
+	return &Schema{}
+}
+
+// named 具名结构体只生成一次，不同包下同名的类型加上包名区分
+func (b *schemaBuilder) named(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+	name := TypeName(t)
+	if _, ok := b.schemas[name]; ok {
+		pkg := t.PkgPath()
+		name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+	}
+	b.names[t] = name
+	// 先占位，结构体引用自己时不会死循环
+	b.schemas[name] = &Schema{Type: "object"}
+	*b.schemas[name] = *b.object(t)
+	return name
+}
+
+func (b *schemaBuilder) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	b.fields(t, s)
+	return s
+}
+
+func (b *schemaBuilder) fields(t reflect.Type, s *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				b.fields(ft, s)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		prop := b.build(f.Type)
+		if prop.Ref == "" {
+			if applyBinding(prop, f.Tag.Get("binding")) {
+				s.Required = append(s.Required, name)
+			}
+		} else if required(f.Tag.Get("binding")) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = prop
+	}
+}
+
+func required(binding string) bool {
+	for _, rule := range strings.Split(binding, ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// applyBinding 把 gin 的 binding 规则里能表达的部分写到 Schema 上，返回是否必填
+func applyBinding(s *Schema, binding string) bool {
+	is_required := false
+	for _, rule := range strings.Split(binding, ",") {
+		kv := strings.SplitN(rule, "=", 2)
+		switch kv[0] {
+		case "required":
+			is_required = true
+		case "min", "max":
+			if len(kv) != 2 {
+				continue
+			}
+			n, err := strconv.ParseFloat(kv[1], 64)
+			if err != nil {
+				continue
+			}
+			if s.Type == "string" {
+				v := int(n)
+				if kv[0] == "min" {
+					s.MinLength = &v
+				} else {
+					s.MaxLength = &v
+				}
+				continue
+			}
+			if kv[0] == "min" {
+				s.Minimum = &n
+			} else {
+				s.Maximum = &n
+			}
+		case "oneof":
+			if len(kv) != 2 {
+				continue
+			}
+			for _, v := range strings.Fields(kv[1]) {
+				if s.Type == "integer" {
+					if n, err := strconv.Atoi(v); err == nil {
+						s.Enum = append(s.Enum, n)
+						continue
+					}
+				}
+				s.Enum = append(s.Enum, v)
+			}
+		}
+	}
+	return is_required
+}
+
+// TypeName 类型的名字，泛型的类型参数拼在前面，比如 pagination.List[models.Equipment] 得到 EquipmentList
+func TypeName(t reflect.Type) string {
+	name := t.Name()
+	i := strings.Index(name, "[")
+	if i < 0 {
+		return name
+	}
+	prefix := ""
+	for _, arg := range strings.Split(name[i+1:len(name)-1], ",") {
+		arg = strings.TrimLeft(arg, "*[]")
+		prefix += arg[strings.LastIndex(arg, ".")+1:]
+	}
+	return prefix + name[:i]
+}
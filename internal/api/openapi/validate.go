@@ -0,0 +1,120 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"myapi/internal/pkg/errcode"
+	"myapi/internal/pkg/response"
+)
+
+// ValidateMiddleware 按文档校验请求体，只检查字段类型、必填和长度，业务规则还是由处理函数负责
+func ValidateMiddleware(doc *Document) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		op := doc.Find(c.Request.Method, c.FullPath())
+		if op == nil || op.request == nil || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+		raw, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response.Abort(c, errcode.ErrInvalidBody.Wrap(err))
+			return
+		}
+		// 读完之后放回去，处理函数里还要再解析一次
+		c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+		if len(bytes.TrimSpace(raw)) == 0 {
+			c.Next()
+			return
+		}
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			response.Abort(c, errcode.ErrInvalidBody.Wrap(err))
+			return
+		}
+		if err := doc.validate(op.request, value, ""); err != nil {
+			response.Abort(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+func (d *Document) validate(s *Schema, value interface{}, field string) error {
+	s = d.Resolve(s)
+	if s == nil || value == nil {
+		return nil
+	}
+	switch s.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return invalidField(field)
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				return errcode.MissingParam(join(field, name))
+			}
+		}
+		for name, v := range obj {
+			prop, ok := s.Properties[name]
+			if !ok {
+				prop = s.AdditionalProperties
+			}
+			if err := d.validate(prop, v, join(field, name)); err != nil {
+				return err
+			}
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			return invalidField(field)
+		}
+		for i, v := range arr {
+			if err := d.validate(s.Items, v, fmt.Sprintf("%s[%d]", field, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return invalidField(field)
+		}
+		n := utf8.RuneCountInString(str)
+		if (s.MinLength != nil && n < *s.MinLength) || (s.MaxLength != nil && n > *s.MaxLength) {
+			return invalidField(field)
+		}
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok || (s.Type == "integer" && n != float64(int64(n))) {
+			return invalidField(field)
+		}
+		if (s.Minimum != nil && n < *s.Minimum) || (s.Maximum != nil && n > *s.Maximum) {
+			return invalidField(field)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return invalidField(field)
+		}
+	}
+	return nil
+}
+
+func invalidField(field string) error {
+	if field == "" {
+		return errcode.ErrInvalidBody
+	}
+	return errcode.InvalidParam(field)
+}
+
+func join(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
@@ -0,0 +1,246 @@
+package routes
+
+import (
+	"myapi/internal/api/handlers"
+	"myapi/internal/api/openapi"
+	"myapi/internal/models"
+	"myapi/internal/pkg/pagination"
+)
+
+// Operations 每个路由的请求、响应类型，新增路由时要在这里补上，routes_test 会检查
+var Operations = map[string]openapi.Operation{
+	"GET /health":           {Summary: "存活检查，和 /health/live 一样", Tag: "system", Public: true},
+	"GET /health/live":      {Summary: "存活检查，进程能响应就返回成功", Tag: "system", Public: true},
+	"GET /health/ready":     {Summary: "就绪检查，数据库能连上并且迁移到了最新版本才返回成功，否则返回 503", Tag: "system", Public: true},
+	"GET /metrics":          {Summary: "Prometheus 指标", Tag: "system", Public: true},
+	"GET /api/openapi.json": {Summary: "接口文档", Tag: "system", Public: true},
+
+	"POST /api/auth/web_register":  {Summary: "邮箱密码注册", Tag: "auth", Public: true, Request: handlers.RegisterCoachRequest{}, Response: models.AuthResponse{}},
+	"POST /api/auth/web_login":     {Summary: "邮箱密码登录", Tag: "auth", Public: true, Request: handlers.LoginCoachRequest{}, Response: models.AuthResponse{}},
+	"POST /api/auth/refresh_token": {Summary: "用 refresh token 换新的 access token，同时轮换 refresh token", Tag: "auth", Public: true, Request: handlers.RefreshTokenRequest{}, Response: models.AuthResponse{}},
+	"POST /api/auth/magic_link":    {Summary: "用登录链接里的 code 换会话，每个链接只能用一次", Tag: "auth", Public: true, Request: handlers.ExchangeMagicLinkRequest{}, Response: models.AuthResponse{}},
+
+	"GET /api/ping": {Summary: "服务版本", Tag: "system", Public: true, Idempotent: true, Request: openapi.None{}, Response: handlers.FetchVersionResponse{}},
+
+	"POST /api/auth/send_verification_code": {Summary: "发送邮箱验证码", Tag: "auth", Public: true, Request: handlers.SendVerificationCodeRequest{}, Response: openapi.None{}},
+	"POST /api/auth/forgot_password":        {Summary: "忘记密码，发送重置密码的验证码", Tag: "auth", Public: true, Request: handlers.ForgotPasswordRequest{}, Response: openapi.None{}},
+	"POST /api/auth/reset_password":         {Summary: "用验证码重置密码，成功后所有设备都需要重新登录", Tag: "auth", Public: true, Request: handlers.ResetPasswordRequest{}, Response: openapi.None{}},
+	"POST /api/auth/profile":                {Summary: "当前用户的资料", Tag: "auth", Idempotent: true, Request: openapi.None{}, Response: handlers.FetchCoachProfileResponse{}},
+	"POST /api/auth/update_profile":         {Summary: "更新当前用户的资料", Tag: "auth", Request: handlers.UpdateCoachProfileRequest{}, Response: openapi.None{}},
+	"POST /api/auth/session/list":           {Summary: "我的登录设备", Tag: "auth", Idempotent: true, Request: openapi.None{}, Response: handlers.FetchSessionListResponse{}},
+	"POST /api/auth/logout":                 {Summary: "退出登录，传 id 时注销指定设备，否则注销当前设备", Tag: "auth", Request: handlers.LogoutRequest{}, Response: openapi.None{}},
+	"POST /api/auth/logout_all":             {Summary: "退出所有设备，包括当前设备", Tag: "auth", Request: openapi.None{}, Response: handlers.LogoutAllResponse{}},
+	"POST /api/auth/verify_email":           {Summary: "验证当前账号的邮箱", Tag: "auth", Request: handlers.VerifyEmailRequest{}, Response: openapi.None{}},
+	"POST /api/auth/create_account":         {Summary: "通过授权链接访问，并且补全登录信息", Tag: "auth", Request: handlers.CreateAccountRequest{}, Response: models.AuthResponse{}},
+	"POST /api/auth/qiniu_token":            {Summary: "七牛上传凭证", Tag: "auth", Idempotent: true, Request: openapi.None{}, Response: handlers.QiniuTokenResponse{}},
+
+	"POST /api/today_workout":         {Summary: "刷新当日的训练总结", Tag: "stats", Request: handlers.RefreshTodayWorkoutStatsRequest{}, Response: handlers.RefreshTodayWorkoutStatsResponse{}},
+	"POST /api/refresh_workout_stats": {Summary: "刷新训练统计", Tag: "stats", Request: handlers.RefreshCoachStatsRequest{}, Response: handlers.RefreshCoachStatsResponse{}},
+
+	"POST /api/student/create":         {Summary: "创建学员", Tag: "student", Request: handlers.CreateStudentRequest{}, Response: handlers.IdResponse{}},
+	"POST /api/student/list":           {Summary: "学员列表", Tag: "student", Idempotent: true, Request: handlers.FetchStudentListRequest{}, Response: pagination.List[handlers.StudentItem]{}},
+	"POST /api/student/profile":        {Summary: "学员详情", Tag: "student", Idempotent: true, Request: handlers.FetchStudentProfileRequest{}, Response: handlers.StudentProfile{}},
+	"POST /api/student/update":         {Summary: "更新学员资料", Tag: "student", Request: handlers.UpdateStudentProfileRequest{}, Response: openapi.None{}},
+	"POST /api/student/delete":         {Summary: "删除学员", Tag: "student", Request: handlers.DeleteStudentRequest{}, Response: openapi.None{}},
+	"POST /api/student/auth_url":       {Summary: "学员的登录链接", Tag: "student", Request: handlers.BuildStudentAuthURLRequest{}, Response: handlers.AuthURLResponse{}},
+	"POST /api/student/url_with_token": {Summary: "删除学员（旧接口）", Tag: "student", Request: handlers.DeleteStudentRequest{}, Response: openapi.None{}},
+	"POST /api/student/to_friend":      {Summary: "学员转为好友", Tag: "student", Request: handlers.StudentToFriendRequest{}, Response: openapi.None{}},
+
+	"POST /api/friend/add": {Summary: "添加好友", Tag: "friend", Request: handlers.AddFriendRequest{}, Response: openapi.None{}},
+
+	"POST /api/coach/profile": {Summary: "教练主页", Tag: "coach", Idempotent: true, Request: handlers.FetchCoachProfileInWechatRequest{}, Response: handlers.FetchCoachProfileInWechatResponse{}},
+
+	"POST /api/content/create":      {Summary: "创建内容", Tag: "content", Request: handlers.CreateArticleRequest{}, Response: handlers.ArticleStatusResponse{}},
+	"POST /api/content/update":      {Summary: "更新内容", Tag: "content", Request: handlers.UpdateArticleRequest{}, Response: handlers.IdResponse{}},
+	"POST /api/content/list":        {Summary: "我的内容列表", Tag: "content", Idempotent: true, Request: handlers.FetchArticleListRequest{}, Response: pagination.List[handlers.ArticleItem]{}},
+	"POST /api/content/profile":     {Summary: "内容详情", Tag: "content", Idempotent: true, Request: handlers.FetchArticleProfileRequest{}, Response: handlers.ArticleProfile{}},
+	"POST /api/content/submit":      {Summary: "作者提交审核", Tag: "content", Request: handlers.SubmitArticleRequest{}, Response: handlers.ArticleStatusResponse{}},
+	"POST /api/content/review/list": {Summary: "作者查看内容的审核记录", Tag: "content", Idempotent: true, Request: handlers.FetchArticleReviewListRequest{}, Response: handlers.FetchArticleReviewListResponse{}},
+
+	"POST /api/follow":                 {Summary: "我关注别人", Tag: "follow", Request: handlers.FollowCoachRequest{}, Response: openapi.None{}},
+	"POST /api/unfollow":               {Summary: "我取消关注别人", Tag: "follow", Request: handlers.UnFollowCoachRequest{}, Response: openapi.None{}},
+	"POST /api/block":                  {Summary: "拉黑，同时解除双方的关注", Tag: "follow", Request: handlers.BlockCoachRequest{}, Response: openapi.None{}},
+	"POST /api/unblock":                {Summary: "取消拉黑，之前的关注不会恢复", Tag: "follow", Request: handlers.UnblockCoachRequest{}, Response: openapi.None{}},
+	"POST /api/my/block/list":          {Summary: "获取我拉黑的人列表", Tag: "follow", Idempotent: true, Request: handlers.FetchMyBlockListRequest{}, Response: pagination.List[handlers.BlockItem]{}},
+	"POST /api/follow/suggestion_list": {Summary: "推荐关注，有共同学员的教练，以及练过或者收藏过的训练计划的作者", Tag: "follow", Idempotent: true, Request: handlers.FetchFollowSuggestionListRequest{}, Response: handlers.FetchFollowSuggestionListResponse{}},
+	"POST /api/my/follower/list":       {Summary: "获取我的关注者列表", Tag: "follow", Idempotent: true, Request: handlers.FetchMyFollowerListRequest{}, Response: pagination.List[handlers.FollowItem]{}},
+	"POST /api/my/following/list":      {Summary: "获取我关注的人列表", Tag: "follow", Idempotent: true, Request: handlers.FetchMyFollowingListRequest{}, Response: pagination.List[handlers.FollowItem]{}},
+
+	"POST /api/coach/list":           {Summary: "教练列表", Tag: "coach", Idempotent: true, Request: handlers.FetchCoachListRequest{}, Response: pagination.List[handlers.CoachItem]{}},
+	"POST /api/coach/create":         {Summary: "创建教练", Tag: "coach", Request: handlers.CreateCoachRequest{}, Response: openapi.None{}},
+	"POST /api/coach/content/list":   {Summary: "教练的内容列表", Tag: "coach", Idempotent: true, Request: handlers.FetchCoachContentListRequest{}, Response: pagination.List[models.CoachContent]{}},
+	"POST /api/coach/content/create": {Summary: "给教练创建内容", Tag: "coach", Request: handlers.CreateCoachContentRequest{}, Response: openapi.None{}},
+
+	"POST /api/admin/content/pending_list":   {Summary: "管理后台 待审核的内容列表", Tag: "admin", Idempotent: true, Request: handlers.FetchPendingArticleListRequest{}, Response: pagination.List[handlers.PendingArticleItem]{}},
+	"POST /api/admin/content/review":         {Summary: "管理后台 审核内容", Tag: "admin", Request: handlers.ReviewArticleRequest{}, Response: handlers.ReviewArticleResponse{}},
+	"POST /api/admin/coach/auth_url":         {Summary: "管理员代登录链接", Tag: "admin", Request: handlers.BuildCoachAuthURLInAdminRequest{}, Response: handlers.AuthURLResponse{}},
+	"POST /api/admin/impersonation_log/list": {Summary: "管理员代登录的操作记录", Tag: "admin", Idempotent: true, Request: handlers.FetchImpersonationLogListRequest{}, Response: pagination.List[models.CoachImpersonationLog]{}},
+	"POST /api/admin/login/unlock":           {Summary: "管理员解除登录锁定，可以按邮箱或者 IP", Tag: "admin", Request: handlers.UnlockLoginInAdminRequest{}, Response: openapi.None{}},
+	"POST /api/admin/coach/profile":          {Summary: "管理后台的教练详情", Tag: "admin", Idempotent: true, Request: handlers.FetchCoachProfileInAdminRequest{}, Response: handlers.CoachBrief{}},
+
+	"POST /api/auth/2fa/verify":         {Summary: "登录的第二步，用临时凭证和验证码换会话", Tag: "auth", Public: true, Request: handlers.VerifyMFARequest{}, Response: models.AuthResponse{}},
+	"POST /api/auth/2fa/status":         {Summary: "两步验证的状态", Tag: "auth", Idempotent: true, Request: openapi.None{}, Response: handlers.FetchMFAStatusResponse{}},
+	"POST /api/auth/2fa/enroll":         {Summary: "开始开启两步验证，返回密钥和二维码内容，调用 enable 确认后才生效", Tag: "auth", Request: openapi.None{}, Response: handlers.EnrollTOTPResponse{}},
+	"POST /api/auth/2fa/enable":         {Summary: "输入验证器里的验证码确认开启，返回恢复码，当前会话视为已通过两步验证", Tag: "auth", Request: handlers.EnableTOTPRequest{}, Response: handlers.RecoveryCodesResponse{}},
+	"POST /api/auth/2fa/disable":        {Summary: "关闭两步验证，需要验证码或者恢复码", Tag: "auth", Request: handlers.DisableTOTPRequest{}, Response: openapi.None{}},
+	"POST /api/auth/2fa/recovery_codes": {Summary: "重新生成恢复码", Tag: "auth", Request: handlers.RegenerateRecoveryCodesRequest{}, Response: handlers.RecoveryCodesResponse{}},
+	"POST /api/auth/sms/send_code":      {Summary: "发送手机号登录验证码", Tag: "auth", Public: true, Request: handlers.SendSMSCodeRequest{}, Response: openapi.None{}},
+	"POST /api/auth/sms/login":          {Summary: "手机号验证码登录，没有注册过的手机号直接注册", Tag: "auth", Public: true, Request: handlers.LoginWithSMSRequest{}, Response: models.AuthResponse{}},
+	"POST /api/auth/oauth/url":          {Summary: "第三方登录的授权地址", Tag: "auth", Public: true, Idempotent: true, Request: openapi.None{}, Response: handlers.OAuthURLResponse{}},
+	"POST /api/auth/oauth/login":        {Summary: "第三方登录回调后，用授权码登录，没有绑定过的帐号直接注册", Tag: "auth", Public: true, Request: handlers.OAuthCodeRequest{}, Response: models.AuthResponse{}},
+	"POST /api/auth/sms/bind":           {Summary: "绑定手机号", Tag: "auth", Request: handlers.BindPhoneRequest{}, Response: openapi.None{}},
+	"POST /api/auth/oauth/bind_url":     {Summary: "绑定第三方帐号的授权地址，state 里带着当前用户", Tag: "auth", Idempotent: true, Request: openapi.None{}, Response: handlers.OAuthURLResponse{}},
+	"POST /api/auth/oauth/bind":         {Summary: "绑定第三方帐号", Tag: "auth", Request: handlers.OAuthCodeRequest{}, Response: openapi.None{}},
+	"POST /api/auth/account/list":       {Summary: "已绑定的登录方式", Tag: "auth", Idempotent: true, Request: openapi.None{}, Response: handlers.FetchAccountListResponse{}},
+	"POST /api/auth/account/unlink":     {Summary: "解绑登录方式，至少保留一种", Tag: "auth", Request: handlers.UnlinkAccountRequest{}, Response: openapi.None{}},
+	"POST /api/auth/export":             {Summary: "导出个人数据，返回 zip 文件", Tag: "auth", Idempotent: true, Request: openapi.None{}, Response: openapi.File{ContentType: "application/zip"}},
+	"POST /api/auth/deletion/request":   {Summary: "申请注销帐号，冷静期内可以撤销", Tag: "auth", Request: handlers.RequestDeletionRequest{}, Response: models.CoachDeletionRequest{}},
+	"POST /api/auth/deletion/cancel":    {Summary: "撤销注销申请", Tag: "auth", Request: openapi.None{}, Response: openapi.None{}},
+	"POST /api/auth/deletion/status":    {Summary: "注销申请的状态，没有申请时 data 为 null", Tag: "auth", Idempotent: true, Request: openapi.None{}, Response: models.CoachDeletionRequest{}},
+
+	"POST /api/workout_plan/profile":         {Summary: "训练计划详情", Tag: "workout_plan", Idempotent: true, Request: handlers.FetchWorkoutPlanProfileRequest{}, Response: handlers.WorkoutPlanProfile{}},
+	"POST /api/workout_plan/list":            {Summary: "训练计划列表", Tag: "workout_plan", Idempotent: true, Request: handlers.FetchWorkoutPlanListRequest{}, Response: pagination.List[handlers.WorkoutPlanItem]{}},
+	"POST /api/workout_plan/update":          {Summary: "更新训练计划", Tag: "workout_plan", Request: handlers.UpdateWorkoutPlanRequest{}, Response: handlers.IdResponse{}},
+	"POST /api/workout_plan/delete":          {Summary: "删除训练计划", Tag: "workout_plan", Request: handlers.DeleteWorkoutPlanRequest{}, Response: openapi.None{}},
+	"POST /api/workout_plan/create":          {Summary: "创建训练计划", Tag: "workout_plan", Request: handlers.CreateWorkoutPlanRequest{}, Response: handlers.IdResponse{}},
+	"POST /api/workout_plan/mine":            {Summary: "我的训练计划", Tag: "workout_plan", Idempotent: true, Request: openapi.None{}, Response: pagination.List[models.WorkoutPlan]{}},
+	"POST /api/workout_plan/content/list":    {Summary: "训练计划的内容列表", Tag: "workout_plan", Idempotent: true, Request: handlers.FetchContentListOfWorkoutPlanRequest{}, Response: pagination.List[handlers.WorkoutPlanContentItem]{}},
+	"POST /api/workout_plan/content/profile": {Summary: "训练计划的内容详情", Tag: "workout_plan", Idempotent: true, Request: handlers.FetchContentProfileOfWorkoutPlanRequest{}, Response: handlers.WorkoutPlanContentProfile{}},
+	"POST /api/workout_plan/content/create":  {Summary: "给训练计划创建内容", Tag: "workout_plan", Request: handlers.CreateContentWithWorkoutPlanRequest{}, Response: openapi.None{}},
+
+	"POST /api/workout_schedule/list":    {Summary: "周期计划列表", Tag: "workout_schedule", Idempotent: true, Request: handlers.FetchWorkoutScheduleListRequest{}, Response: pagination.List[handlers.WorkoutScheduleItem]{}},
+	"POST /api/workout_schedule/create":  {Summary: "创建周期计划", Tag: "workout_schedule", Request: handlers.CreateWorkoutScheduleRequest{}, Response: handlers.IdResponse{}},
+	"POST /api/workout_schedule/update":  {Summary: "更新周期计划", Tag: "workout_schedule", Request: handlers.UpdateWorkoutScheduleRequest{}, Response: openapi.None{}},
+	"POST /api/workout_schedule/profile": {Summary: "周期计划详情", Tag: "workout_schedule", Idempotent: true, Request: handlers.FetchWorkoutScheduleProfileRequest{}, Response: handlers.WorkoutScheduleProfile{}},
+	"POST /api/workout_schedule/apply":   {Summary: "应用某个周期计划", Tag: "workout_schedule", Request: handlers.ApplyWorkoutScheduleRequest{}, Response: openapi.None{}},
+	"POST /api/workout_schedule/cancel":  {Summary: "取消应用某个周期计划", Tag: "workout_schedule", Request: handlers.CancelWorkoutScheduleRequest{}, Response: openapi.None{}},
+	"POST /api/workout_schedule/enabled": {Summary: "获取当前应用中的周期计划", Tag: "workout_schedule", Idempotent: true, Request: openapi.None{}, Response: handlers.FetchAppliedWorkoutScheduleListResponse{}},
+
+	"POST /api/workout_plan_set/list":   {Summary: "计划合集列表", Tag: "workout_plan_set", Idempotent: true, Request: handlers.FetchWorkoutPlanSetListRequest{}, Response: pagination.List[handlers.WorkoutPlanSetItem]{}},
+	"POST /api/workout_plan_set/create": {Summary: "创建计划合集", Tag: "workout_plan_set", Request: handlers.CreateWorkoutPlanSetRequest{}, Response: handlers.IdResponse{}},
+	"POST /api/workout_plan_set/update": {Summary: "更新计划合集", Tag: "workout_plan_set", Request: models.WorkoutPlanSet{}, Response: handlers.IdResponse{}},
+
+	"POST /api/workout_day/list":           {Summary: "训练日列表", Tag: "workout_day", Idempotent: true, Request: handlers.FetchWorkoutDayListRequest{}, Response: pagination.List[handlers.WorkoutDayItem]{}},
+	"POST /api/workout_day/create":         {Summary: "创建训练日", Tag: "workout_day", Request: handlers.CreateWorkoutDayRequest{}, Response: handlers.CreateWorkoutDayResponse{}},
+	"POST /api/workout_day/create_free":    {Summary: "用于创建一个已经完成的训练，比如有氧", Tag: "workout_day", Request: handlers.CreateFreeWorkoutDayRequest{}, Response: handlers.IdResponse{}},
+	"POST /api/workout_day/update":         {Summary: "更新训练日", Tag: "workout_day", Request: handlers.UpdateWorkoutDayRequest{}, Response: handlers.IdResponse{}},
+	"POST /api/workout_day/profile":        {Summary: "获取训练计划记录 获取自己的和当时一起训练好友、学员的", Tag: "workout_day", Idempotent: true, Request: handlers.FetchWorkoutDayProfileRequest{}, Response: handlers.WorkoutDayProfile{}},
+	"POST /api/workout_day/has_started":    {Summary: "查看是否有进行中的训练，仅获取少量数据", Tag: "workout_day", Idempotent: true, Request: openapi.None{}, Response: handlers.CheckHasStartedWorkoutDayResponse{}},
+	"POST /api/workout_day/started_list":   {Summary: "进行中的训练日", Tag: "workout_day", Idempotent: true, Request: openapi.None{}, Response: handlers.FetchStartedWorkoutDayResponse{}},
+	"POST /api/workout_day/finished_list":  {Summary: "似乎废弃了，使用 FetchWorkoutDayList 替代", Tag: "workout_day", Idempotent: true, Request: handlers.FetchFinishedWorkoutDayListRequest{}, Response: handlers.FetchFinishedWorkoutDayListResponse{}},
+	"POST /api/workout_day/start":          {Summary: "基本上用不上，都是用 createWorkoutDay", Tag: "workout_day", Request: handlers.StartWorkoutDayRequest{}, Response: handlers.IdResponse{}},
+	"POST /api/workout_day/give_up":        {Summary: "放弃训练", Tag: "workout_day", Request: handlers.GiveUpWorkoutDayRequest{}, Response: handlers.IdResponse{}},
+	"POST /api/workout_day/finish":         {Summary: "完成训练", Tag: "workout_day", Request: handlers.FinishWorkoutDayRequest{}, Response: handlers.IdResponse{}},
+	"POST /api/workout_day/result":         {Summary: "获取训练计划记录结果 只能获取自己的", Tag: "workout_day", Idempotent: true, Request: handlers.FetchWorkoutDayResultRequest{}, Response: handlers.WorkoutDayResult{}},
+	"POST /api/workout_day/continue":       {Summary: "继续训练", Tag: "workout_day", Request: handlers.ContinueWorkoutDayRequest{}, Response: handlers.IdResponse{}},
+	"POST /api/workout_day/update_steps":   {Summary: "暂存的训练内容记录", Tag: "workout_day", Request: handlers.UpdateWorkoutDayStepProgressRequest{}, Response: handlers.IdResponse{}},
+	"POST /api/workout_day/update_details": {Summary: "更新训练日的计划内容", Tag: "workout_day", Request: handlers.UpdateWorkoutDayPlanDetailsRequest{}, Response: handlers.IdResponse{}},
+	"POST /api/workout_day/delete":         {Summary: "删除训练日", Tag: "workout_day", Request: handlers.DeleteWorkoutDayRequest{}, Response: openapi.None{}},
+	"POST /api/workout_day/share":          {Summary: "分享已完成的训练到动态，关注我的人能看到", Tag: "workout_day", Request: handlers.ShareWorkoutDayRequest{}, Response: handlers.ShareWorkoutDayResponse{}},
+
+	"POST /api/student/workout_day/list":    {Summary: "学员的训练日列表", Tag: "student", Idempotent: true, Request: handlers.FetchMyStudentWorkoutDayListRequest{}, Response: pagination.List[handlers.WorkoutDayItem]{}},
+	"POST /api/student/workout_day/profile": {Summary: "学员的训练日详情", Tag: "student", Idempotent: true, Request: handlers.FetchStudentWorkoutDayProfileRequest{}, Response: handlers.WorkoutDayProfile{}},
+	"POST /api/student/workout_day/result":  {Summary: "获取学员/好友训练记录", Tag: "student", Idempotent: true, Request: handlers.FetchStudentWorkoutDayResultRequest{}, Response: handlers.WorkoutDayResult{}},
+
+	"POST /api/admin/workout_day/refresh_250630": {Summary: "修复训练日数据（250630）", Tag: "admin", Request: openapi.None{}, Response: handlers.RefreshWorkoutDayRecordsResponse{}},
+
+	"POST /api/workout_action_history/create":                 {Summary: "记录动作", Tag: "workout_action_history", Request: handlers.CreateWorkoutHistoryRequest{}, Response: openapi.None{}},
+	"POST /api/workout_action_history/list_of_workout_day":    {Summary: "训练日的动作记录", Tag: "workout_action_history", Idempotent: true, Request: handlers.FetchWorkoutActionHistoryListOfWorkoutDayRequest{}, Response: pagination.List[models.WorkoutActionHistory]{}},
+	"POST /api/workout_action_history/list_of_workout_action": {Summary: "获取健身动作历史记录", Tag: "workout_action_history", Idempotent: true, Request: handlers.FetchWorkoutActionHistoryListOfWorkoutActionRequest{}, Response: pagination.List[models.WorkoutActionHistory]{}},
+
+	"POST /api/student/workout_action_history/list": {Summary: "学员训练日的动作记录", Tag: "student", Idempotent: true, Request: handlers.FetchStudentWorkoutActionHistoryListOfWorkoutDayRequest{}, Response: pagination.List[models.WorkoutActionHistory]{}},
+
+	"POST /api/workout_action/list":           {Summary: "动作列表", Tag: "workout_action", Idempotent: true, Request: handlers.FetchWorkoutActionListRequest{}, Response: pagination.List[handlers.WorkoutActionListItem]{}},
+	"POST /api/workout_action/list_by_ids":    {Summary: "根据 id 获取动作", Tag: "workout_action", Idempotent: true, Request: handlers.FetchWorkoutActionListByIdsRequest{}, Response: handlers.FetchWorkoutActionListByIdsResponse{}},
+	"POST /api/workout_action/list/by_muscle": {Summary: "锻炼指定肌肉的动作", Tag: "workout_action", Idempotent: true, Request: openapi.None{}, Response: []models.WorkoutAction{}},
+	"POST /api/workout_action/list/by_level":  {Summary: "指定难度的动作", Tag: "workout_action", Idempotent: true, Request: openapi.None{}, Response: []models.WorkoutAction{}},
+	"POST /api/workout_action/list/cardio":    {Summary: "有氧动作列表", Tag: "workout_action", Idempotent: true, Request: handlers.FetchCardioWorkoutActionListRequest{}, Response: pagination.List[handlers.WorkoutActionListItem]{}},
+	"POST /api/workout_action/list/related":   {Summary: "获取指定动作的 进阶、退阶、替代动作", Tag: "workout_action", Idempotent: true, Request: handlers.FetchRelatedWorkoutActionsRequest{}, Response: handlers.FetchRelatedWorkoutActionsResponse{}},
+	"POST /api/workout_action/profile":        {Summary: "动作详情", Tag: "workout_action", Idempotent: true, Request: handlers.GetWorkoutActionRequest{}, Response: models.WorkoutAction{}},
+	"POST /api/workout_action/update_idx":     {Summary: "更新动作排序", Tag: "workout_action", Request: handlers.UpdateWorkoutActionIdxRequest{}, Response: handlers.UpdateWorkoutActionIdxRequest{}},
+	"POST /api/workout_action/create":         {Summary: "创建动作", Tag: "workout_action", Request: handlers.WorkoutActionBody{}, Response: handlers.WorkoutActionBody{}},
+	"POST /api/workout_action/update":         {Summary: "更新动作", Tag: "workout_action", Request: handlers.UpdateWorkoutActionProfileRequest{}, Response: handlers.UpdateWorkoutActionProfileRequest{}},
+	"POST /api/workout_action/delete":         {Summary: "删除动作", Tag: "workout_action", Request: handlers.DeleteWorkoutActionRequest{}, Response: openapi.None{}},
+	"POST /api/workout_action/content/create": {Summary: "给动作创建内容", Tag: "workout_action", Request: handlers.CreateContentWithWorkoutActionRequest{}, Response: openapi.None{}},
+	"POST /api/workout_action/content/list":   {Summary: "动作的内容列表", Tag: "workout_action", Idempotent: true, Request: handlers.FetchContentListOfWorkoutActionRequest{}, Response: pagination.List[handlers.WorkoutActionContentItem]{}},
+
+	"POST /api/muscle/list":    {Summary: "肌肉列表", Tag: "muscle", Idempotent: true, Request: handlers.FetchMuscleListRequest{}, Response: pagination.List[models.Muscle]{}},
+	"POST /api/muscle/profile": {Summary: "肌肉详情", Tag: "muscle", Idempotent: true, Request: handlers.FetchMuscleProfileRequest{}, Response: models.Muscle{}},
+	"POST /api/muscle/create":  {Summary: "创建肌肉", Tag: "muscle", Request: handlers.CreateMuscleRequest{}, Response: openapi.None{}},
+	"POST /api/muscle/update":  {Summary: "更新肌肉", Tag: "muscle", Request: handlers.UpdateMuscleRequest{}, Response: openapi.None{}},
+	"POST /api/muscle/delete":  {Summary: "删除肌肉", Tag: "muscle", Request: handlers.DeleteMuscleRequest{}, Response: openapi.None{}},
+
+	"POST /api/equipment/list":    {Summary: "器械列表", Tag: "equipment", Idempotent: true, Request: handlers.FetchEquipmentListRequest{}, Response: pagination.List[models.Equipment]{}},
+	"POST /api/equipment/profile": {Summary: "器械详情", Tag: "equipment", Idempotent: true, Request: handlers.FetchEquipmentRequest{}, Response: models.Equipment{}},
+	"POST /api/equipment/create":  {Summary: "创建器械", Tag: "equipment", Request: handlers.CreateEquipmentRequest{}, Response: openapi.None{}},
+	"POST /api/equipment/update":  {Summary: "更新器械", Tag: "equipment", Request: handlers.UpdateEquipmentRequest{}, Response: openapi.None{}},
+	"POST /api/equipment/delete":  {Summary: "删除器械", Tag: "equipment", Request: handlers.DeleteEquipmentRequest{}, Response: openapi.None{}},
+
+	"POST /api/subscription_plan/list":   {Summary: "订阅方案列表", Tag: "subscription_plan", Idempotent: true, Request: handlers.FetchSubscriptionPlanListRequest{}, Response: handlers.FetchSubscriptionPlanListResponse{}},
+	"POST /api/subscription_plan/create": {Summary: "创建订阅方案", Tag: "subscription_plan", Request: handlers.CreateSubscriptionPlanRequest{}, Response: models.SubscriptionPlan{}},
+
+	"POST /api/subscription_order/calc": {Summary: "计算订阅金额", Tag: "subscription_order", Idempotent: true, Request: handlers.CalcSubscriptionOrderAmountRequest{}, Response: handlers.CalcSubscriptionOrderAmountResponse{}},
+
+	"POST /api/subscription/list": {Summary: "我的订阅", Tag: "subscription", Idempotent: true, Request: handlers.FetchSubscriptionListRequest{}, Response: pagination.List[models.Subscription]{}},
+
+	"POST /api/quiz/list":   {Summary: "题目列表", Tag: "quiz", Idempotent: true, Request: handlers.FetchQuizListRequest{}, Response: pagination.List[models.Quiz]{}},
+	"POST /api/quiz/create": {Summary: "创建题目", Tag: "quiz", Request: handlers.CreateQuizRequest{}, Response: models.Quiz{}},
+
+	"POST /api/paper/list":    {Summary: "试卷列表", Tag: "paper", Idempotent: true, Request: handlers.FetchPaperListRequest{}, Response: pagination.List[models.Paper]{}},
+	"POST /api/paper/profile": {Summary: "试卷详情", Tag: "paper", Idempotent: true, Request: handlers.FetchPaperProfileRequest{}, Response: handlers.FetchPaperProfileResponse{}},
+	"POST /api/paper/create":  {Summary: "创建试卷", Tag: "paper", Request: handlers.CreatePaperRequest{}, Response: models.Paper{}},
+	"POST /api/paper/update":  {Summary: "更新试卷", Tag: "paper", Request: handlers.UpdatePaperRequest{}, Response: models.Paper{}},
+
+	"POST /api/exam/running":  {Summary: "进行中的考试", Tag: "exam", Idempotent: true, Request: openapi.None{}, Response: handlers.FetchRunningExamResponse{}},
+	"POST /api/exam/list":     {Summary: "考试记录", Tag: "exam", Idempotent: true, Request: handlers.FetchExamListRequest{}, Response: pagination.List[models.Exam]{}},
+	"POST /api/exam/start":    {Summary: "开始考试", Tag: "exam", Request: handlers.StartExamWithPaperRequest{}, Response: models.Exam{}},
+	"POST /api/exam/profile":  {Summary: "考试详情", Tag: "exam", Idempotent: true, Request: handlers.FetchExamProfileRequest{}, Response: handlers.ExamDetailResponse{}},
+	"POST /api/exam/answer":   {Summary: "答题", Tag: "exam", Request: handlers.UpdateQuizAnswerRequest{}, Response: models.QuizAnswer{}},
+	"POST /api/exam/complete": {Summary: "交卷", Tag: "exam", Request: handlers.CompleteExamRequest{}, Response: handlers.CompleteExamResponse{}},
+	"POST /api/exam/give_up":  {Summary: "放弃考试", Tag: "exam", Request: handlers.GiveUpExamRequest{}, Response: models.Exam{}},
+	"POST /api/exam/result":   {Summary: "考试结果", Tag: "exam", Idempotent: true, Request: handlers.FetchExamResultRequest{}, Response: handlers.ExamDetailResponse{}},
+
+	"POST /api/invite/create":  {Summary: "创建邀请", Tag: "invite", Request: handlers.CreateInviteRequest{}, Response: handlers.CreateInviteResponse{}},
+	"POST /api/invite/list":    {Summary: "我的邀请", Tag: "invite", Idempotent: true, Request: handlers.FetchInviteListRequest{}, Response: pagination.List[handlers.InviteItem]{}},
+	"POST /api/invite/revoke":  {Summary: "撤回邀请", Tag: "invite", Request: handlers.RevokeInviteRequest{}, Response: openapi.None{}},
+	"POST /api/invite/profile": {Summary: "受邀人打开邀请时查看", Tag: "invite", Idempotent: true, Request: handlers.FetchInviteProfileRequest{}, Response: handlers.FetchInviteProfileResponse{}},
+	"POST /api/invite/accept":  {Summary: "接受邀请", Tag: "invite", Request: handlers.AcceptInviteRequest{}, Response: handlers.AcceptInviteResponse{}},
+	"POST /api/invite/reject":  {Summary: "拒绝邀请", Tag: "invite", Request: handlers.RejectInviteRequest{}, Response: openapi.None{}},
+
+	"POST /api/relationship/accept":  {Summary: "确认对方发起的关系，比如好友申请", Tag: "relationship", Request: handlers.AcceptRelationshipRequest{}, Response: openapi.None{}},
+	"POST /api/relationship/reject":  {Summary: "拒绝建立关系", Tag: "relationship", Request: handlers.RejectRelationshipRequest{}, Response: openapi.None{}},
+	"POST /api/relationship/dismiss": {Summary: "任意一方都可以解除关系，训练记录保留", Tag: "relationship", Request: handlers.DismissRelationshipRequest{}, Response: openapi.None{}},
+
+	"POST /api/feed/list": {Summary: "关注的教练的动态", Tag: "feed", Idempotent: true, Request: handlers.FetchFeedListRequest{}, Response: pagination.List[handlers.FeedItem]{}},
+
+	"POST /api/content/like":           {Summary: "点赞", Tag: "content", Request: handlers.LikeContentRequest{}, Response: handlers.LikeContentResponse{}},
+	"POST /api/content/unlike":         {Summary: "取消点赞", Tag: "content", Request: handlers.UnlikeContentRequest{}, Response: handlers.LikeContentResponse{}},
+	"POST /api/content/comment/create": {Summary: "发表评论", Tag: "content", Request: handlers.CreateCommentRequest{}, Response: handlers.CreateCommentResponse{}},
+	"POST /api/content/comment/list":   {Summary: "评论列表", Tag: "content", Idempotent: true, Request: handlers.FetchCommentListRequest{}, Response: pagination.List[handlers.CommentItem]{}},
+	"POST /api/content/comment/delete": {Summary: "评论人和内容作者都可以删除评论", Tag: "content", Request: handlers.DeleteCommentRequest{}, Response: openapi.None{}},
+
+	"POST /api/favorite/create": {Summary: "收藏", Tag: "favorite", Request: handlers.CreateFavoriteRequest{}, Response: handlers.FavoriteResponse{}},
+	"POST /api/favorite/delete": {Summary: "取消收藏", Tag: "favorite", Request: handlers.DeleteFavoriteRequest{}, Response: handlers.FavoriteResponse{}},
+	"POST /api/favorite/list":   {Summary: "收藏列表", Tag: "favorite", Idempotent: true, Request: handlers.FetchFavoriteListRequest{}, Response: pagination.List[handlers.FavoriteItem]{}},
+
+	"POST /api/admin/comment/pending_list": {Summary: "管理后台 待审核的评论", Tag: "admin", Idempotent: true, Request: handlers.FetchPendingCommentListRequest{}, Response: pagination.List[handlers.PendingCommentItem]{}},
+	"POST /api/admin/comment/review":       {Summary: "管理后台 审核评论，通过后展示，不通过则屏蔽。已经展示的评论也可以屏蔽", Tag: "admin", Request: handlers.ReviewCommentRequest{}, Response: openapi.None{}},
+
+	"POST /api/report/create":       {Summary: "举报", Tag: "report", Request: handlers.CreateReportRequest{}, Response: models.CoachReport{}},
+	"POST /api/report/profile":      {Summary: "举报详情", Tag: "report", Idempotent: true, Request: handlers.FetchReportProfileRequest{}, Response: models.CoachReport{}},
+	"POST /api/report/list":         {Summary: "举报列表", Tag: "report", Idempotent: true, Request: handlers.FetchReportListRequest{}, Response: pagination.List[models.CoachReport]{}},
+	"POST /api/report/list_of_mine": {Summary: "我的举报", Tag: "report", Idempotent: true, Request: handlers.FetchMineReportListRequest{}, Response: pagination.List[models.CoachReport]{}},
+
+	"POST /api/gift_card/create":        {Summary: "创建兑换码", Tag: "gift_card", Request: handlers.CreateGiftCardRequest{}, Response: openapi.None{}},
+	"POST /api/gift_card/create_reward": {Summary: "创建兑换奖励", Tag: "gift_card", Request: handlers.CreateGiftCardRewardRequest{}, Response: openapi.None{}},
+	"POST /api/gift_card/list":          {Summary: "兑换码列表", Tag: "gift_card", Idempotent: true, Request: handlers.FetchGiftCardListRequest{}, Response: pagination.List[models.GiftCard]{}},
+	"POST /api/gift_card/reward_list":   {Summary: "兑换奖励列表", Tag: "gift_card", Idempotent: true, Request: handlers.FetchGiftCardRewardListRequest{}, Response: pagination.List[models.GiftCardReward]{}},
+	"POST /api/gift_card/profile":       {Summary: "兑换码详情", Tag: "gift_card", Idempotent: true, Request: handlers.FetchGiftCardProfileRequest{}, Response: handlers.FetchGiftCardProfileResponse{}},
+	"POST /api/gift_card/using":         {Summary: "使用兑换码", Tag: "gift_card", Request: handlers.UsingGiftCardRequest{}, Response: openapi.None{}},
+	"POST /api/gift_card/send":          {Summary: "赠送礼品卡", Tag: "gift_card", Request: handlers.SendGiftCardRequest{}, Response: openapi.None{}},
+
+	"POST /api/media/qiniu_token": {Summary: "七牛上传凭证", Tag: "media", Idempotent: true, Request: openapi.None{}, Response: handlers.QiniuTokenResponse{}},
+	"POST /api/media/create":      {Summary: "上传媒体资源后保存记录", Tag: "media", Request: handlers.CreateMediaResourceRequest{}, Response: models.MediaResource{}},
+	"POST /api/media/list":        {Summary: "媒体资源列表", Tag: "media", Idempotent: true, Request: handlers.FetchMediaResourceListRequest{}, Response: pagination.List[models.MediaResource]{}},
+	"POST /api/media/delete":      {Summary: "删除媒体资源", Tag: "media", Request: handlers.DeleteMediaResourceRequest{}, Response: openapi.None{}},
+}
@@ -1,39 +1,82 @@
 package routes
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
 	"myapi/config"
 	"myapi/internal/api/handlers"
 	"myapi/internal/api/middlewares"
+	"myapi/internal/api/openapi"
+	"myapi/internal/pkg/loginguard"
+	"myapi/internal/pkg/metrics"
+	"myapi/internal/pkg/ratelimit"
 	"myapi/pkg/logger"
 )
 
 // SetupRouter 配置API路由
 func SetupRouter(db *gorm.DB, logger *logger.Logger, cfg *config.Config) *gin.Engine {
 	// 设置Gin模式
-	if cfg.Environment == "production" {
+	if cfg.IsProduction() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	r := gin.New()
+	// 只信任配置的代理转发的客户端 IP，否则频率限制可以用 X-Forwarded-For 绕过
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		logger.Warnw("Invalid trusted proxies", "error", err)
+	}
 
 	// 使用中间件
-	r.Use(gin.Recovery())
-	r.Use(gin.Logger())
-
-	// 健康检查
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status": "ok",
-		})
-	})
+	r.Use(middlewares.RequestIdMiddleware())
+	r.Use(metrics.Middleware())
+	r.Use(middlewares.AccessLogMiddleware(logger))
+	r.Use(middlewares.RecoveryMiddleware(logger))
+
+	// Prometheus 指标
+	r.GET("/metrics", metrics.Handler())
+
+	// 健康检查，/health 保留给旧的探针，和 /health/live 一样
+	health := handlers.NewHealthHandler(db, logger, cfg)
+	r.GET("/health", health.Live)
+	r.GET("/health/live", health.Live)
+	r.GET("/health/ready", health.Ready)
+
+	// 接口文档在所有路由注册完之后才生成，这里先占位
+	spec := &openapi.Document{}
 
 	// API路由组
 	api := r.Group("/api")
+	if cfg.OpenAPIValidate {
+		api.Use(openapi.ValidateMiddleware(spec))
+	}
+	api.GET("/openapi.json", func(c *gin.Context) {
+		c.JSON(200, spec)
+	})
+	// 频率限制，Name 相同的路由共用一个桶；多实例部署时把 MemoryStore 换成共享存储
+	limits := ratelimit.NewMemoryStore()
+	limit := func(policy ratelimit.Policy) gin.HandlerFunc {
+		if !cfg.RateLimitEnabled {
+			return func(c *gin.Context) { c.Next() }
+		}
+		return middlewares.RateLimitMiddleware(limits, policy)
+	}
+	login_limit := limit(ratelimit.Policy{Name: "login", Limit: 10, Per: time.Minute, Key: ratelimit.KeyIP})
+	register_limit := limit(ratelimit.Policy{Name: "register", Limit: 5, Per: time.Hour, Key: ratelimit.KeyIP})
+	send_code_limit := limit(ratelimit.Policy{Name: "send_code", Limit: 5, Per: 10 * time.Minute, Key: ratelimit.KeyIP})
+	gift_card_limit := limit(ratelimit.Policy{Name: "gift_card", Limit: 5, Per: time.Minute, Key: ratelimit.KeyUserAndIP})
+	report_limit := limit(ratelimit.Policy{Name: "report", Limit: 10, Per: time.Hour, Burst: 3, Key: ratelimit.KeyUser})
+
+	// 登录、两步验证的失败计数，几个处理器共用，多实例部署时把 MemoryStore 换成共享存储
+	guard := loginguard.NewGuard(loginguard.NewMemoryStore(), loginguard.DefaultAccountPolicy, loginguard.DefaultIPPolicy)
+
 	authorized := api.Group("/")
-	authorized.Use(middlewares.AuthMiddleware(logger, cfg))
+	authorized.Use(middlewares.AuthMiddleware(db, logger, cfg))
+	// 管理员接口，开启了强制两步验证时会话必须通过两步验证
+	admin := authorized.Group("")
+	admin.Use(middlewares.AdminMFAMiddleware(cfg))
 	{
 		// 用户处理器
 		// userHandler := handlers.NewUserHandler(db, logger)
@@ -43,16 +86,23 @@ func SetupRouter(db *gorm.DB, logger *logger.Logger, cfg *config.Config) *gin.En
 		// api.POST("/user/profile", userHandler.GetUser)
 
 		{
-			handler := handlers.NewCoachHandler(db, logger, cfg)
+			handler := handlers.NewCoachHandler(db, logger, cfg, guard)
 			handler2 := handlers.NewMediaResourceHandler(db, logger, cfg)
-			api.POST("/auth/web_register", handler.RegisterCoach)
-			api.POST("/auth/web_login", handler.LoginCoach)
+			api.POST("/auth/web_register", register_limit, handler.RegisterCoach)
+			api.POST("/auth/web_login", login_limit, handler.LoginCoach)
+			api.POST("/auth/refresh_token", handler.RefreshToken)
+			api.POST("/auth/magic_link", handler.ExchangeMagicLink)
 			api.GET("/ping", handler.FetchVersion)
-			// api.POST("/coach/send-verification-code", handler.SendVerificationCode)
+			api.POST("/auth/send_verification_code", send_code_limit, handler.SendVerificationCode)
+			api.POST("/auth/forgot_password", send_code_limit, handler.ForgotPassword)
+			api.POST("/auth/reset_password", handler.ResetPassword)
 
 			authorized.POST("/auth/profile", handler.FetchCoachProfile)
 			authorized.POST("/auth/update_profile", handler.UpdateCoachProfile)
-			authorized.POST("/auth/refresh_token", handler.RefreshToken)
+			authorized.POST("/auth/session/list", handler.FetchSessionList)
+			authorized.POST("/auth/logout", handler.Logout)
+			authorized.POST("/auth/logout_all", handler.LogoutAll)
+			authorized.POST("/auth/verify_email", handler.VerifyEmail)
 			authorized.POST("/auth/create_account", handler.CreateAccount)
 			authorized.POST("/auth/qiniu_token", handler2.BuildQiniuToken)
 			authorized.POST("/today_workout", handler.RefreshTodayWorkoutStats)
@@ -72,17 +122,56 @@ func SetupRouter(db *gorm.DB, logger *logger.Logger, cfg *config.Config) *gin.En
 			authorized.POST("/content/update", handler.UpdateArticle)
 			authorized.POST("/content/list", handler.FetchArticleList)
 			authorized.POST("/content/profile", handler.FetchArticleProfile)
+			authorized.POST("/content/submit", handler.SubmitArticle)
+			authorized.POST("/content/review/list", handler.FetchArticleReviewList)
 			authorized.POST("/follow", handler.FollowCoach)
+			authorized.POST("/unfollow", handler.UnFollowCoach)
+			authorized.POST("/block", handler.BlockCoach)
+			authorized.POST("/unblock", handler.UnblockCoach)
+			authorized.POST("/my/block/list", handler.FetchMyBlockList)
+			authorized.POST("/follow/suggestion_list", handler.FetchFollowSuggestionList)
 			authorized.POST("/my/follower/list", handler.FetchMyFollowerList)
 			authorized.POST("/my/following/list", handler.FetchMyFollowingList)
 
 			// 管理后台
-			authorized.POST("/coach/list", handler.FetchCoachList)
-			authorized.POST("/coach/create", handler.CreateCoach)
-			authorized.POST("/coach/content/list", handler.FetchCoachContentList)
-			authorized.POST("/coach/content/create", handler.CreateCoachContent)
-			authorized.POST("/admin/coach/auth_url", handler.BuildCoachAuthURLInAdmin)
-			authorized.POST("/admin/coach/profile", handler.FetchCoachProfileInAdmin)
+			admin.POST("/coach/list", handler.FetchCoachList)
+			admin.POST("/coach/create", handler.CreateCoach)
+			admin.POST("/coach/content/list", handler.FetchCoachContentList)
+			admin.POST("/coach/content/create", handler.CreateCoachContent)
+			admin.POST("/admin/content/pending_list", handler.FetchPendingArticleList)
+			admin.POST("/admin/content/review", handler.ReviewArticle)
+			admin.POST("/admin/coach/auth_url", handler.BuildCoachAuthURLInAdmin)
+			admin.POST("/admin/impersonation_log/list", handler.FetchImpersonationLogList)
+			admin.POST("/admin/login/unlock", handler.UnlockLoginInAdmin)
+			admin.POST("/admin/coach/profile", handler.FetchCoachProfileInAdmin)
+		}
+		{
+			handler := handlers.NewMFAHandler(db, logger, cfg, guard)
+			api.POST("/auth/2fa/verify", login_limit, handler.VerifyMFA)
+			authorized.POST("/auth/2fa/status", handler.FetchMFAStatus)
+			authorized.POST("/auth/2fa/enroll", handler.EnrollTOTP)
+			authorized.POST("/auth/2fa/enable", handler.EnableTOTP)
+			authorized.POST("/auth/2fa/disable", handler.DisableTOTP)
+			authorized.POST("/auth/2fa/recovery_codes", handler.RegenerateRecoveryCodes)
+		}
+		{
+			handler := handlers.NewAccountHandler(db, logger, cfg)
+			api.POST("/auth/sms/send_code", send_code_limit, handler.SendSMSCode)
+			api.POST("/auth/sms/login", login_limit, handler.LoginWithSMS)
+			api.POST("/auth/oauth/url", handler.BuildOAuthURL)
+			api.POST("/auth/oauth/login", handler.LoginWithOAuth)
+			authorized.POST("/auth/sms/bind", handler.BindPhone)
+			authorized.POST("/auth/oauth/bind_url", handler.BuildOAuthBindURL)
+			authorized.POST("/auth/oauth/bind", handler.BindOAuth)
+			authorized.POST("/auth/account/list", handler.FetchAccountList)
+			authorized.POST("/auth/account/unlink", handler.UnlinkAccount)
+		}
+		{
+			handler := handlers.NewPrivacyHandler(db, logger, cfg, guard)
+			authorized.POST("/auth/export", handler.ExportPersonalData)
+			authorized.POST("/auth/deletion/request", handler.RequestDeletion)
+			authorized.POST("/auth/deletion/cancel", handler.CancelDeletion)
+			authorized.POST("/auth/deletion/status", handler.FetchDeletionStatus)
 		}
 		{
 
@@ -128,10 +217,11 @@ func SetupRouter(db *gorm.DB, logger *logger.Logger, cfg *config.Config) *gin.En
 			authorized.POST("/workout_day/update_steps", handler.UpdateWorkoutDayStepProgress)
 			authorized.POST("/workout_day/update_details", handler.UpdateWorkoutDayPlanDetails)
 			authorized.POST("/workout_day/delete", handler.DeleteWorkoutDay)
+			authorized.POST("/workout_day/share", handler.ShareWorkoutDay)
 			authorized.POST("/student/workout_day/list", handler.FetchMyStudentWorkoutDayList)
 			authorized.POST("/student/workout_day/profile", handler.FetchStudentWorkoutDayProfile)
 			authorized.POST("/student/workout_day/result", handler.FetchStudentWorkoutDayResult)
-			authorized.POST("/admin/workout_day/refresh_250630", handler.RefreshWorkoutDayRecords250630)
+			admin.POST("/admin/workout_day/refresh_250630", handler.RefreshWorkoutDayRecords250630)
 		}
 		{
 			handler := handlers.NewWorkoutActionHistoryHandler(db, logger)
@@ -196,9 +286,38 @@ func SetupRouter(db *gorm.DB, logger *logger.Logger, cfg *config.Config) *gin.En
 			authorized.POST("/exam/give_up", handler.GiveUpExam)
 			authorized.POST("/exam/result", handler.FetchExamResult)
 		}
+		{
+			handler := handlers.NewInviteHandler(db, logger, cfg)
+			authorized.POST("/invite/create", handler.CreateInvite)
+			authorized.POST("/invite/list", handler.FetchInviteList)
+			authorized.POST("/invite/revoke", handler.RevokeInvite)
+			authorized.POST("/invite/profile", handler.FetchInviteProfile)
+			authorized.POST("/invite/accept", handler.AcceptInvite)
+			authorized.POST("/invite/reject", handler.RejectInvite)
+			authorized.POST("/relationship/accept", handler.AcceptRelationship)
+			authorized.POST("/relationship/reject", handler.RejectRelationship)
+			authorized.POST("/relationship/dismiss", handler.DismissRelationship)
+		}
+		{
+			handler := handlers.NewFeedHandler(db, logger, cfg)
+			authorized.POST("/feed/list", handler.FetchFeedList)
+		}
+		{
+			handler := handlers.NewInteractionHandler(db, logger)
+			authorized.POST("/content/like", handler.LikeContent)
+			authorized.POST("/content/unlike", handler.UnlikeContent)
+			authorized.POST("/content/comment/create", handler.CreateComment)
+			authorized.POST("/content/comment/list", handler.FetchCommentList)
+			authorized.POST("/content/comment/delete", handler.DeleteComment)
+			authorized.POST("/favorite/create", handler.CreateFavorite)
+			authorized.POST("/favorite/delete", handler.DeleteFavorite)
+			authorized.POST("/favorite/list", handler.FetchFavoriteList)
+			admin.POST("/admin/comment/pending_list", handler.FetchPendingCommentList)
+			admin.POST("/admin/comment/review", handler.ReviewComment)
+		}
 		{
 			handler := handlers.NewReportHandler(db, logger)
-			authorized.POST("/report/create", handler.CreateReport)
+			authorized.POST("/report/create", report_limit, handler.CreateReport)
 			authorized.POST("/report/profile", handler.FetchReportProfile)
 			authorized.POST("/report/list", handler.FetchReportList)
 			authorized.POST("/report/list_of_mine", handler.FetchMineReportList)
@@ -210,7 +329,7 @@ func SetupRouter(db *gorm.DB, logger *logger.Logger, cfg *config.Config) *gin.En
 			authorized.POST("/gift_card/list", handler.FetchGiftCardList)
 			authorized.POST("/gift_card/reward_list", handler.FetchGiftCardRewardList)
 			authorized.POST("/gift_card/profile", handler.FetchGiftCardProfile)
-			authorized.POST("/gift_card/using", handler.UsingGiftCard)
+			authorized.POST("/gift_card/using", gift_card_limit, handler.UsingGiftCard)
 			authorized.POST("/gift_card/send", handler.SendGiftCard)
 		}
 		{
@@ -222,5 +341,11 @@ func SetupRouter(db *gorm.DB, logger *logger.Logger, cfg *config.Config) *gin.En
 		}
 	}
 
+	doc, missing := openapi.Build("FitHub API", handlers.APIVersion, r.Routes(), Operations)
+	for _, key := range missing {
+		logger.Warnw("Route has no openapi operation", "route", key)
+	}
+	*spec = *doc
+
 	return r
 }
@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"myapi/config"
+	"myapi/internal/api/openapi"
+	"myapi/pkg/logger"
+)
+
+func TestEveryRouteHasOperation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := SetupRouter(nil, logger.NewLogger("error"), &config.Config{})
+	routes := r.Routes()
+
+	_, missing := openapi.Build("test", "test", routes, Operations)
+	for _, key := range missing {
+		t.Errorf("route %s has no entry in Operations", key)
+	}
+
+	registered := map[string]bool{}
+	for _, route := range routes {
+		key := openapi.Key(route.Method, route.Path)
+		registered[key] = true
+		op, ok := Operations[key]
+		if !ok || op.Tag == "system" {
+			continue
+		}
+		// 没有请求体或者没有返回数据的也要写明 openapi.None{}，生成的客户端才不会退回到 json.RawMessage
+		if op.Request == nil {
+			t.Errorf("operation %s has no Request, use openapi.None{} if it takes no body", key)
+		}
+		if op.Response == nil {
+			t.Errorf("operation %s has no Response, use openapi.None{} if data is always null", key)
+		}
+	}
+	for key := range Operations {
+		if !registered[key] {
+			t.Errorf("operation %s has no route", key)
+		}
+	}
+}
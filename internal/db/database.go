@@ -3,6 +3,8 @@ package db
 import (
 	"fmt"
 	"myapi/config"
+	mylogger "myapi/pkg/logger"
+	"time"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
@@ -11,13 +13,14 @@ import (
 	"gorm.io/gorm/logger"
 )
 
-// NewDatabase 创建数据库连接
-func NewDatabase(cfg *config.Config) (*gorm.DB, error) {
+// NewDatabase 创建数据库连接，SQL 日志写到 log 里
+func NewDatabase(cfg *config.Config, log *mylogger.Logger) (*gorm.DB, error) {
 	var dialector gorm.Dialector
 
 	switch cfg.DBType {
 	case "mysql":
-		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		// 和 Postgres 一样按 UTC 保存时间，按用户时区统计时只需要加上用户的偏移
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=UTC",
 			cfg.DBUser, cfg.DBPassword, cfg.DBHost, cfg.DBPort, cfg.DBName)
 		dialector = mysql.Open(dsn)
 	case "postgres":
@@ -30,13 +33,14 @@ func NewDatabase(cfg *config.Config) (*gorm.DB, error) {
 		return nil, fmt.Errorf("unsupported database type: %s", cfg.DBType)
 	}
 
-	// 配置GORM
+	// 配置GORM，生产环境只记录出错和慢查询的 SQL
+	slow := time.Duration(cfg.DBSlowThreshold) * time.Millisecond
 	gormConfig := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger: newGormLogger(log, logger.Info, slow),
 	}
 
-	if cfg.Environment == "production" {
-		gormConfig.Logger = logger.Default.LogMode(logger.Error)
+	if cfg.IsProduction() {
+		gormConfig.Logger = newGormLogger(log, logger.Warn, slow)
 	}
 
 	// 连接数据库
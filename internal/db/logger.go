@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+
+	"myapi/pkg/logger"
+)
+
+// gormLogger 把 GORM 的日志写到 zap，source 是执行 SQL 的代码位置，查询时用了 db.WithContext(c) 的会带上请求 ID
+type gormLogger struct {
+	logger        *logger.Logger
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+}
+
+// slow_threshold 为 0 时不记录慢查询
+func newGormLogger(logger *logger.Logger, level gormlogger.LogLevel, slow_threshold time.Duration) gormlogger.Interface {
+	return &gormLogger{
+		logger:        logger,
+		level:         level,
+		slowThreshold: slow_threshold,
+	}
+}
+
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	next := *l
+	next.level = level
+	return &next
+}
+
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		l.logger.WithContext(ctx).Infow(fmt.Sprintf(msg, args...), "source", utils.FileWithLineNum())
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		l.logger.WithContext(ctx).Warnw(fmt.Sprintf(msg, args...), "source", utils.FileWithLineNum())
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		l.logger.WithContext(ctx).Errorw(fmt.Sprintf(msg, args...), "source", utils.FileWithLineNum())
+	}
+}
+
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	log := l.logger.WithContext(ctx)
+	switch {
+	// 记录不存在由调用方处理，不算错误
+	case err != nil && l.level >= gormlogger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		log.Errorw("SQL failed", "sql", sql, "rows", rows, "elapsed", elapsed, "source", utils.FileWithLineNum(), "error", err.Error())
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		log.Warnw("Slow SQL", "sql", sql, "rows", rows, "elapsed", elapsed, "source", utils.FileWithLineNum())
+	case l.level >= gormlogger.Info:
+		sql, rows := fc()
+		log.Infow("SQL", "sql", sql, "rows", rows, "elapsed", elapsed, "source", utils.FileWithLineNum())
+	}
+}
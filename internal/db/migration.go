@@ -2,16 +2,26 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"myapi/config"
+	"myapi/migrations"
 	"myapi/pkg/logger"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/mysql"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"gorm.io/gorm"
 )
 
 // Migrator 处理数据库迁移
@@ -30,54 +40,120 @@ func NewMigrator(cfg *config.Config, logger *logger.Logger) *Migrator {
 
 // MigrateUp 运行所有向上迁移
 func (m *Migrator) MigrateUp() error {
-	migrator, err := m.createMigrator()
-	if err != nil {
-		return err
-	}
-	defer func() {
-		sourceErr, dbErr := migrator.Close()
-		if sourceErr != nil {
-			m.logger.Error("Error closing migration source", sourceErr)
+	return m.run(func(migrator *migrate.Migrate) error {
+		if err := migrator.Up(); err != nil && err != migrate.ErrNoChange {
+			return fmt.Errorf("failed to run migrations: %w", err)
 		}
-		if dbErr != nil {
-			m.logger.Error("Error closing migration database", dbErr)
+		m.logger.Info("Database migrations completed successfully")
+		return nil
+	})
+}
+
+// MigrateDown 回滚所有迁移
+func (m *Migrator) MigrateDown() error {
+	return m.run(func(migrator *migrate.Migrate) error {
+		if err := migrator.Down(); err != nil && err != migrate.ErrNoChange {
+			return fmt.Errorf("failed to rollback migrations: %w", err)
 		}
-	}()
+		m.logger.Info("Database rollback completed successfully")
+		return nil
+	})
+}
 
-	if err := migrator.Up(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("failed to run migrations: %w", err)
-	}
+// MigrateTo 迁移到特定版本
+func (m *Migrator) MigrateTo(version uint) error {
+	return m.run(func(migrator *migrate.Migrate) error {
+		if err := migrator.Migrate(version); err != nil && err != migrate.ErrNoChange {
+			return fmt.Errorf("failed to migrate to version %d: %w", version, err)
+		}
+		m.logger.Info(fmt.Sprintf("Database migrated to version %d successfully", version))
+		return nil
+	})
+}
 
-	m.logger.Info("Database migrations completed successfully")
-	return nil
+// MigrateSteps 向上（n > 0）或向下（n < 0）迁移 n 个版本
+func (m *Migrator) MigrateSteps(n int) error {
+	return m.run(func(migrator *migrate.Migrate) error {
+		if err := migrator.Steps(n); err != nil && err != migrate.ErrNoChange {
+			return fmt.Errorf("failed to migrate %d step(s): %w", n, err)
+		}
+		m.logger.Info(fmt.Sprintf("Database migrated %d step(s) successfully", n))
+		return nil
+	})
 }
 
-// MigrateDown 回滚所有迁移
-func (m *Migrator) MigrateDown() error {
-	migrator, err := m.createMigrator()
+// Force 把版本记录改成 version 并清除 dirty 标记，不执行任何 SQL
+//
+// 迁移中途失败后，先手动把数据库改成某个版本的状态，再用这个方法标记，version 为 -1 表示还没有迁移过
+func (m *Migrator) Force(version int) error {
+	return m.run(func(migrator *migrate.Migrate) error {
+		if err := migrator.Force(version); err != nil {
+			return fmt.Errorf("failed to force version %d: %w", version, err)
+		}
+		m.logger.Info(fmt.Sprintf("Database version forced to %d", version))
+		return nil
+	})
+}
+
+// MigrationFile 一个迁移版本
+type MigrationFile struct {
+	Version uint
+	Name    string
+}
+
+// MigrationStatus 数据库的迁移状态
+type MigrationStatus struct {
+	// 当前版本，还没有迁移过时为 0
+	Version uint
+	// 上次迁移中途失败了，需要处理后 force
+	Dirty bool
+	// 迁移文件里最新的版本
+	Latest uint
+	// 还没有执行的迁移
+	Pending []MigrationFile
+}
+
+// Status 查询当前的迁移状态
+func (m *Migrator) Status() (*MigrationStatus, error) {
+	status := &MigrationStatus{}
+	err := m.run(func(migrator *migrate.Migrate) error {
+		version, dirty, err := migrator.Version()
+		if err != nil && err != migrate.ErrNilVersion {
+			return fmt.Errorf("failed to read migration version: %w", err)
+		}
+		status.Version, status.Dirty = version, dirty
+		return nil
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer func() {
-		sourceErr, dbErr := migrator.Close()
-		if sourceErr != nil {
-			m.logger.Error("Error closing migration source", sourceErr)
-		}
-		if dbErr != nil {
-			m.logger.Error("Error closing migration database", dbErr)
+	files, err := ListMigrations(m.config.MigrationsPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		status.Latest = f.Version
+		if f.Version > status.Version {
+			status.Pending = append(status.Pending, f)
 		}
-	}()
-
-	if err := migrator.Down(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("failed to rollback migrations: %w", err)
 	}
+	return status, nil
+}
 
-	m.logger.Info("Database rollback completed successfully")
+// CheckClean 数据库处于 dirty 状态时返回错误，说明怎么修复
+func (m *Migrator) CheckClean() error {
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	if status.Dirty {
+		return fmt.Errorf("database is dirty at migration version %d: a previous migration failed halfway. "+
+			"Fix the schema by hand, then run `cli migrate force <version>` with the version it now matches", status.Version)
+	}
 	return nil
 }
 
-// MigrateTo 迁移到特定版本
-func (m *Migrator) MigrateTo(version uint) error {
+func (m *Migrator) run(fn func(migrator *migrate.Migrate) error) error {
 	migrator, err := m.createMigrator()
 	if err != nil {
 		return err
@@ -91,13 +167,7 @@ func (m *Migrator) MigrateTo(version uint) error {
 			m.logger.Error("Error closing migration database", dbErr)
 		}
 	}()
-
-	if err := migrator.Migrate(version); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("failed to migrate to version %d: %w", version, err)
-	}
-
-	m.logger.Info(fmt.Sprintf("Database migrated to version %d successfully", version))
-	return nil
+	return fn(migrator)
 }
 
 // createMigrator 创建迁移实例
@@ -148,14 +218,117 @@ func (m *Migrator) createMigrator() (*migrate.Migrate, error) {
 	}
 
 	// 创建迁移实例
-	migrator, err := migrate.NewWithDatabaseInstance(
-		m.config.MigrationsPath,
-		driver,
-		instance,
-	)
+	src, err := openSource(m.config.MigrationsPath)
+	if err != nil {
+		return nil, err
+	}
+	migrator, err := migrate.NewWithInstance("migrations", src, driver, instance)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create migrator: %w", err)
 	}
 
 	return migrator, nil
 }
+
+// openSource 打开迁移文件，migrations_path 为空时用打包进程序的迁移文件
+func openSource(migrations_path string) (source.Driver, error) {
+	var driver source.Driver
+	var err error
+	if migrations_path == "" {
+		driver, err = iofs.New(migrations.FS, ".")
+	} else {
+		driver, err = source.Open(migrations_path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migration source: %w", err)
+	}
+	return driver, nil
+}
+
+// ListMigrations 按版本顺序列出所有迁移，不需要连接数据库
+func ListMigrations(migrations_path string) ([]MigrationFile, error) {
+	driver, err := openSource(migrations_path)
+	if err != nil {
+		return nil, err
+	}
+	defer driver.Close()
+
+	var files []MigrationFile
+	version, err := driver.First()
+	for err == nil {
+		r, identifier, read_err := driver.ReadUp(version)
+		if read_err != nil {
+			return nil, fmt.Errorf("failed to read migration %d: %w", version, read_err)
+		}
+		r.Close()
+		files = append(files, MigrationFile{Version: version, Name: identifier})
+		version, err = driver.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read migration source: %w", err)
+	}
+	return files, nil
+}
+
+// LatestVersion 迁移文件里最新的版本，不需要连接数据库
+func LatestVersion(migrations_path string) (uint, error) {
+	files, err := ListMigrations(migrations_path)
+	if err != nil {
+		return 0, err
+	}
+	if len(files) == 0 {
+		return 0, fmt.Errorf("no migrations found")
+	}
+	return files[len(files)-1].Version, nil
+}
+
+// CreateMigration 在 dir 下新建下一个版本的 up、down 两个空文件，返回文件路径
+func CreateMigration(dir, name string) ([]string, error) {
+	name = strings.Trim(migrationNameRegexp.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, fmt.Errorf("migration name is empty")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	next := uint64(1)
+	for _, e := range entries {
+		prefix, _, ok := strings.Cut(e.Name(), "_")
+		if !ok {
+			continue
+		}
+		if v, err := strconv.ParseUint(prefix, 10, 64); err == nil && v >= next {
+			next = v + 1
+		}
+	}
+	var paths []string
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, fmt.Sprintf("%06d_%s.%s.sql", next, name, direction))
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return paths, err
+		}
+		f.Close()
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+var migrationNameRegexp = regexp.MustCompile(`[^a-z0-9]+`)
+
+// SchemaVersion 数据库当前的迁移版本，dirty 表示上次迁移中途失败了
+func SchemaVersion(db *gorm.DB) (version uint, dirty bool, err error) {
+	var row struct {
+		Version int64
+		Dirty   bool
+	}
+	r := db.Table("schema_migrations").Select("version, dirty").Limit(1).Scan(&row)
+	if r.Error != nil {
+		return 0, false, r.Error
+	}
+	if r.RowsAffected == 0 {
+		return 0, false, nil
+	}
+	return uint(row.Version), row.Dirty, nil
+}
@@ -19,6 +19,7 @@ type Coach struct {
 	Status       int        `json:"status" gorm:"default:1"`     // 状态
 	Config       string     `json:"config" gorm:"default:'{}'"`
 	WorkoutStats string     `json:"workout_stats"`
+	Timezone     string     `json:"timezone" gorm:"default:'Asia/Shanghai'"` // IANA 时区，按这个时区划分每一天
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    *time.Time `json:"updated_at"`
 
@@ -36,12 +37,13 @@ func (Coach) TableName() string {
 
 // CoachAccount 教练账号模型
 type CoachAccount struct {
-	ProviderType int       `json:"provider_type"`
-	ProviderId   string    `json:"provider_id"`
-	ProviderArg1 string    `json:"provider_arg1"`
-	ProviderArg2 string    `json:"provider_arg2"`
-	ProviderArg3 string    `json:"provider_arg3"`
-	CreatedAt    time.Time `json:"created_at"`
+	ProviderType int        `json:"provider_type"`
+	ProviderId   string     `json:"provider_id"`
+	ProviderArg1 string     `json:"provider_arg1"`
+	ProviderArg2 string     `json:"provider_arg2"`
+	ProviderArg3 string     `json:"provider_arg3"`
+	VerifiedAt   *time.Time `json:"verified_at"` // 邮箱验证时间
+	CreatedAt    time.Time  `json:"created_at"`
 
 	CoachId int   `json:"coach_id"`
 	Coach   Coach `json:"coach"`
@@ -114,11 +116,17 @@ type CoachContent struct {
 	VideoKey      string     `json:"video_key"`
 	ImageKeys     string     `json:"image_keys"`
 	LikeCount     int        `json:"like_count"`
-	Status        int        `json:"status"`  // 1审核通过
+	CommentCount  int        `json:"comment_count"`
+	Status        int        `json:"status"`  // 0草稿 1审核通过 2审核不通过 3待审核
 	Publish       int        `json:"publish"` // 1公开 2私有
 	PublishedAt   *time.Time `json:"published_at"`
+	RejectReason  string     `json:"reject_reason"`
+	SubmittedAt   *time.Time `json:"submitted_at"`
+	ReviewedAt    *time.Time `json:"reviewed_at"`
+	ReviewerId    int        `json:"reviewer_id"` // 0表示系统自动审核
 	D             int        `json:"d"`
 	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     *time.Time `json:"updated_at"`
 
 	CoachId int   `json:"coach_id"`
 	Coach   Coach `json:"coach"  gorm:"foreignKey:CoachId"`
@@ -128,6 +136,26 @@ func (CoachContent) TableName() string {
 	return "COACH_CONTENT"
 }
 
+// IsPublished 审核通过并且到达发布时间
+func (c CoachContent) IsPublished(now time.Time) bool {
+	return c.Status == CoachContentStatusApproved && c.PublishedAt != nil && !c.PublishedAt.After(now)
+}
+
+// CoachContentReview 内容审核记录
+type CoachContentReview struct {
+	Id         int       `json:"id" gorm:"primaryKey"`
+	Status     int       `json:"status"` // 1审核通过 2审核不通过
+	Reason     string    `json:"reason"`
+	ReviewerId int       `json:"reviewer_id"` // 0表示系统自动审核
+	CreatedAt  time.Time `json:"created_at"`
+
+	CoachContentId int `json:"coach_content_id"`
+}
+
+func (CoachContentReview) TableName() string {
+	return "COACH_CONTENT_REVIEW"
+}
+
 type CoachContentWithWorkoutAction struct {
 	Id         int       `json:"id" gorm:"primaryKey"`
 	SortIdx    int       `json:"sort_idx"`
@@ -175,7 +203,7 @@ type CoachFollow struct {
 	FollowingId int   `json:"following_id"`
 	Following   Coach `json:"following" gorm:"foreignKey:FollowingId"`
 	FollowerId  int   `json:"follower_id"`
-	Follower    Coach `json:"follower" gorm:"foreignKey:FollowingId"`
+	Follower    Coach `json:"follower" gorm:"foreignKey:FollowerId"`
 }
 
 func (CoachFollow) TableName() string {
@@ -221,7 +249,10 @@ func (CoachMediaSocialAccount) TableName() string {
 
 // 常量定义
 const (
-	AccountProviderTypeEmailWithPwd = 1
+	// AccountProviderType 帐号授权方式
+	AccountProviderTypeEmailWithPwd = 1 // 邮箱密码
+	AccountProviderTypePhone        = 2 // 手机号验证码
+	AccountProviderTypeOAuth        = 3 // 第三方登录，provider_arg1 是第三方平台名称
 
 	// CoachType 教练类型
 	CoachTypePersonal = 1 // 私教
@@ -233,6 +264,12 @@ const (
 	CoachStatusPaused = 2 // 暂停服务
 	CoachStatusBanned = 3 // 封禁
 
+	// CoachContentStatus 内容审核状态
+	CoachContentStatusDraft     = 0 // 草稿
+	CoachContentStatusApproved  = 1 // 审核通过
+	CoachContentStatusRejected  = 2 // 审核不通过
+	CoachContentStatusSubmitted = 3 // 待审核
+
 	// RelationshipStatus 关系状态
 	RelationPending   = 1 // 待确认
 	RelationConfirmed = 2 // 已确认
@@ -248,40 +285,72 @@ const (
 )
 
 // AuthResponse represents the response for authentication operations
+// 开启了两步验证时只有 mfa_required、challenge_token 和 expires_at，需要再调用 /auth/2fa/verify
 type AuthResponse struct {
-	Token     string `json:"token"`
-	ExpiresAt int64  `json:"expires_at"`
-	Status    string `json:"status"`
+	Token            string `json:"token,omitempty"`
+	ExpiresAt        int64  `json:"expires_at"`
+	RefreshToken     string `json:"refresh_token,omitempty"`
+	RefreshExpiresAt int64  `json:"refresh_expires_at,omitempty"`
+	Status           string `json:"status,omitempty"`
+	MFARequired      bool   `json:"mfa_required,omitempty"`
+	ChallengeToken   string `json:"challenge_token,omitempty"`
 }
 
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
-	SecretKey     []byte
-	TokenDuration time.Duration
+	SecretKey            []byte
+	TokenDuration        time.Duration
+	RefreshTokenDuration time.Duration
+	AuthURLTokenDuration time.Duration
+	MagicLinkDuration    time.Duration
+	MFAChallengeDuration time.Duration
 }
 
 // Claims represents the JWT claims
 type Claims struct {
 	Id        float64 `json:"id"`
+	SessionId string  `json:"sid"`
+	Scope     string  `json:"scope"`
+	ActorId   float64 `json:"act"`
+	Purpose   string  `json:"purpose"`
 	ExpiresAt float64 `json:"expires_at"`
 	Issuer    string  `json:"issuer"`
 }
 
 // Default JWT configuration
 var DefaultJWTConfig = JWTConfig{
-	TokenDuration: 48 * time.Hour, // 24 hours
+	TokenDuration: 30 * time.Minute,
 	// TokenDuration: 5 * time.Minute, // 5分钟，测试用
+	RefreshTokenDuration: 30 * 24 * time.Hour,
+	// 授权链接不能刷新，过期后需要重新生成
+	AuthURLTokenDuration: 24 * time.Hour,
+	// 一次性登录链接要尽快使用
+	MagicLinkDuration: 15 * time.Minute,
+	// 两步验证要在这个时间内完成
+	MFAChallengeDuration: 5 * time.Minute,
 }
 
 // Helper function to generate JWT token
-func GenerateJWT(coach_id int, secret_key string) (string, time.Time, error) {
-	expiration_time := time.Now().Add(DefaultJWTConfig.TokenDuration)
+func GenerateJWT(coach_id int, session_id string, duration time.Duration, secret_key string) (string, time.Time, error) {
+	return GenerateScopedJWT(coach_id, session_id, "", 0, duration, secret_key)
+}
+
+// GenerateScopedJWT 生成限制权限范围的 token，actor_id 不为 0 表示是管理员代登录，写在 act 里
+func GenerateScopedJWT(coach_id int, session_id string, scope string, actor_id int, duration time.Duration, secret_key string) (string, time.Time, error) {
+	expiration_time := time.Now().Add(duration)
 
 	claims := jwt.MapClaims{
 		"id":         coach_id,
+		"sid":        session_id,
 		"expires_at": jwt.NewNumericDate(expiration_time),
 		"issuer":     "top.fithub",
 	}
+	if scope != "" {
+		claims["scope"] = scope
+	}
+	if actor_id != 0 {
+		claims["act"] = map[string]interface{}{"id": actor_id}
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	str, err := token.SignedString([]byte(secret_key))
@@ -321,5 +390,19 @@ func ParseJWT(str string, secret_key string) (*Claims, error) {
 		ExpiresAt: v["expires_at"].(float64),
 		Issuer:    v["issuer"].(string),
 	}
+	if sid, ok := v["sid"].(string); ok {
+		claims.SessionId = sid
+	}
+	if purpose, ok := v["purpose"].(string); ok {
+		claims.Purpose = purpose
+	}
+	if scope, ok := v["scope"].(string); ok {
+		claims.Scope = scope
+	}
+	if act, ok := v["act"].(map[string]interface{}); ok {
+		if id, ok := act["id"].(float64); ok {
+			claims.ActorId = id
+		}
+	}
 	return claims, nil
 }
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CoachDeletionRequest 注销帐号申请
+type CoachDeletionRequest struct {
+	Id          int        `json:"id" gorm:"primaryKey"`
+	Status      int        `json:"status"` // 1等待执行 2已撤销 3已完成
+	Reason      string     `json:"reason"`
+	ScheduledAt time.Time  `json:"scheduled_at"`
+	CancelledAt *time.Time `json:"cancelled_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	CoachId int `json:"coach_id"`
+}
+
+func (CoachDeletionRequest) TableName() string {
+	return "COACH_DELETION_REQUEST"
+}
+
+const (
+	// DeletionRequestStatus 注销申请状态
+	DeletionRequestStatusPending   = 1 // 等待执行
+	DeletionRequestStatusCancelled = 2 // 已撤销
+	DeletionRequestStatusCompleted = 3 // 已完成
+)
+
+// FetchPendingDeletionRequest 没有等待执行的申请时返回 nil
+func FetchPendingDeletionRequest(db *gorm.DB, coach_id int) (*CoachDeletionRequest, error) {
+	var record CoachDeletionRequest
+	err := db.Where("coach_id = ? AND status = ?", coach_id, DeletionRequestStatusPending).First(&record).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
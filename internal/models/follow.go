@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CoachBlock 拉黑，被拉黑的人不能关注我，我也看不到对方的内容
+type CoachBlock struct {
+	Id        int       `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+
+	BlockerId int   `json:"blocker_id"`
+	BlockedId int   `json:"blocked_id"`
+	Blocked   Coach `json:"blocked" gorm:"foreignKey:BlockedId"`
+}
+
+func (CoachBlock) TableName() string {
+	return "COACH_BLOCK"
+}
+
+const (
+	// FollowStatus 关注状态
+	FollowStatusFollowing  = 1 // 关注中
+	FollowStatusUnfollowed = 2 // 取消关注
+)
+
+// BlockedCoachIds 返回 coach_id 拉黑的人，用作子查询
+func BlockedCoachIds(db *gorm.DB, coach_id int) *gorm.DB {
+	return db.Model(&CoachBlock{}).Select("blocked_id").Where("blocker_id = ?", coach_id)
+}
+
+// IsBlocked 两个人之间任意一方拉黑了另一方
+func IsBlocked(db *gorm.DB, a int, b int) (bool, error) {
+	var count int64
+	if err := db.Model(&CoachBlock{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", a, b, b, a).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// FetchFollowCount 返回关注者数量和关注的人数量
+func FetchFollowCount(db *gorm.DB, coach_id int) (int64, int64, error) {
+	var follower_count int64
+	if err := db.Model(&CoachFollow{}).Where("following_id = ? AND status = ?", coach_id, FollowStatusFollowing).Count(&follower_count).Error; err != nil {
+		return 0, 0, err
+	}
+	var following_count int64
+	if err := db.Model(&CoachFollow{}).Where("follower_id = ? AND status = ?", coach_id, FollowStatusFollowing).Count(&following_count).Error; err != nil {
+		return 0, 0, err
+	}
+	return follower_count, following_count, nil
+}
+
+// FetchFollowingIds 返回 ids 中被 follower_id 关注的人
+func FetchFollowingIds(db *gorm.DB, follower_id int, ids []int) (map[int]bool, error) {
+	result := make(map[int]bool)
+	if follower_id == 0 || len(ids) == 0 {
+		return result, nil
+	}
+	var following []int
+	if err := db.Model(&CoachFollow{}).
+		Where("follower_id = ? AND status = ? AND following_id IN (?)", follower_id, FollowStatusFollowing, ids).
+		Pluck("following_id", &following).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range following {
+		result[id] = true
+	}
+	return result, nil
+}
+
+// FetchFollowerIds 返回 ids 中关注了 following_id 的人
+func FetchFollowerIds(db *gorm.DB, following_id int, ids []int) (map[int]bool, error) {
+	result := make(map[int]bool)
+	if following_id == 0 || len(ids) == 0 {
+		return result, nil
+	}
+	var followers []int
+	if err := db.Model(&CoachFollow{}).
+		Where("following_id = ? AND status = ? AND follower_id IN (?)", following_id, FollowStatusFollowing, ids).
+		Pluck("follower_id", &followers).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range followers {
+		result[id] = true
+	}
+	return result, nil
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CoachContentLike 内容点赞
+type CoachContentLike struct {
+	Id        int       `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+
+	CoachContentId int `json:"coach_content_id"`
+	CoachId        int `json:"coach_id"`
+}
+
+func (CoachContentLike) TableName() string {
+	return "COACH_CONTENT_LIKE"
+}
+
+// CoachContentComment 内容评论，只有两级，回复都挂在一级评论下面
+type CoachContentComment struct {
+	Id         int       `json:"id" gorm:"primaryKey"`
+	D          int       `json:"d"`
+	Status     int       `json:"status"` // 1正常 2待审核 3已屏蔽
+	Content    string    `json:"content"`
+	ReplyCount int       `json:"reply_count"`
+	RootId     int       `json:"root_id"`     // 0表示一级评论
+	ParentId   int       `json:"parent_id"`   // 回复的评论
+	ReplyToId  int       `json:"reply_to_id"` // 回复的人
+	CreatedAt  time.Time `json:"created_at"`
+
+	CoachContentId int   `json:"coach_content_id"`
+	CoachId        int   `json:"coach_id"`
+	Coach          Coach `json:"coach" gorm:"foreignKey:CoachId"`
+	ReplyTo        Coach `json:"reply_to" gorm:"foreignKey:ReplyToId"`
+}
+
+func (CoachContentComment) TableName() string {
+	return "COACH_CONTENT_COMMENT"
+}
+
+// UserFavorite 收藏
+type UserFavorite struct {
+	Id          int       `json:"id" gorm:"primaryKey"`
+	D           int       `json:"d"`
+	SortIdx     int       `json:"sort_idx"`
+	ContentType string    `json:"content_type"`
+	ContentId   int       `json:"content_id"`
+	FolderId    int       `json:"folder_id"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	CoachId int `json:"coach_id"`
+}
+
+func (UserFavorite) TableName() string {
+	return "USER_FAVORITE"
+}
+
+const (
+	// CommentStatus 评论状态
+	CommentStatusNormal  = 1 // 正常
+	CommentStatusPending = 2 // 待审核
+	CommentStatusBlocked = 3 // 已屏蔽
+
+	// FavoriteContentType 收藏内容类型
+	FavoriteContentTypeCoachContent = "coach_content"
+	FavoriteContentTypeWorkoutPlan  = "workout_plan"
+)
+
+// FetchLikedContentIds 返回 ids 中被 coach_id 点赞过的内容
+func FetchLikedContentIds(db *gorm.DB, coach_id int, ids []int) (map[int]bool, error) {
+	result := make(map[int]bool)
+	if coach_id == 0 || len(ids) == 0 {
+		return result, nil
+	}
+	var liked []int
+	if err := db.Model(&CoachContentLike{}).
+		Where("coach_id = ? AND coach_content_id IN (?)", coach_id, ids).
+		Pluck("coach_content_id", &liked).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range liked {
+		result[id] = true
+	}
+	return result, nil
+}
+
+// FetchFavoriteContentIds 返回 ids 中被 coach_id 收藏过的内容
+func FetchFavoriteContentIds(db *gorm.DB, coach_id int, content_type string, ids []int) (map[int]bool, error) {
+	result := make(map[int]bool)
+	if coach_id == 0 || len(ids) == 0 {
+		return result, nil
+	}
+	var favorited []int
+	if err := db.Model(&UserFavorite{}).
+		Where("d = 0 AND coach_id = ? AND content_type = ? AND content_id IN (?)", coach_id, content_type, ids).
+		Pluck("content_id", &favorited).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range favorited {
+		result[id] = true
+	}
+	return result, nil
+}
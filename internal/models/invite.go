@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// CoachInvite 教练邀请学员，受邀人接受后建立关系
+type CoachInvite struct {
+	Id        int        `json:"id" gorm:"primaryKey"`
+	Code      string     `json:"code"`
+	Status    int        `json:"status"` // 1待接受 2已接受 3已拒绝 4已撤销
+	ExpiredAt time.Time  `json:"expired_at"`
+	HandledAt *time.Time `json:"handled_at"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	CoachId   int   `json:"coach_id"`
+	Coach     Coach `json:"coach" gorm:"foreignKey:CoachId"`
+	StudentId int   `json:"student_id"` // 教练创建的学员
+	Student   Coach `json:"student" gorm:"foreignKey:StudentId"`
+	InviteeId int   `json:"invitee_id"`
+}
+
+func (CoachInvite) TableName() string {
+	return "COACH_INVITE"
+}
+
+const (
+	// InviteStatus 邀请状态
+	InviteStatusPending  = 1 // 待接受
+	InviteStatusAccepted = 2 // 已接受
+	InviteStatusRejected = 3 // 已拒绝
+	InviteStatusRevoked  = 4 // 已撤销
+)
+
+// IsAvailable 邀请还能被接受或拒绝
+func (v CoachInvite) IsAvailable(now time.Time) bool {
+	return v.Status == InviteStatusPending && now.Before(v.ExpiredAt)
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CoachMagicLink 一次性登录链接，链接里只有随机 code，服务端换成会话
+type CoachMagicLink struct {
+	Id        int        `json:"id" gorm:"primaryKey"`
+	TokenHash string     `json:"-"`
+	Scope     string     `json:"scope"`
+	ActorId   int        `json:"actor_id"`
+	ExpiredAt time.Time  `json:"expired_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CoachId   int        `json:"coach_id"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (CoachMagicLink) TableName() string {
+	return "COACH_MAGIC_LINK"
+}
+
+// CoachImpersonationLog 管理员代登录期间的操作记录
+type CoachImpersonationLog struct {
+	Id        int       `json:"id" gorm:"primaryKey"`
+	ActorId   int       `json:"actor_id"`
+	SessionId string    `json:"session_id"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	IP        string    `json:"ip" gorm:"column:ip"`
+	CoachId   int       `json:"coach_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (CoachImpersonationLog) TableName() string {
+	return "COACH_IMPERSONATION_LOG"
+}
+
+const (
+	// 权限范围，空表示不限制
+	ScopeWorkout = "workout" // 学员只能记录训练
+)
+
+var ErrInvalidMagicLink = errors.New("invalid magic link")
+
+// ScopePaths 各权限范围允许访问的接口，以 / 结尾的表示前缀
+var ScopePaths = map[string][]string{
+	ScopeWorkout: {
+		"/auth/profile",
+		"/auth/create_account",
+		"/auth/logout",
+		"/today_workout",
+		"/workout_day/",
+		"/workout_action_history/",
+		"/workout_action/list",
+		"/workout_action/profile",
+		"/workout_plan/profile",
+	},
+}
+
+// ScopeAllows 判断权限范围内能否访问 path，path 不带 /api 前缀
+func ScopeAllows(scope string, path string) bool {
+	if scope == "" {
+		return true
+	}
+	for _, p := range ScopePaths[scope] {
+		if p == path || (strings.HasSuffix(p, "/") && strings.HasPrefix(path, p)) || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateMagicLink 生成一次性登录链接，返回放在链接里的 code
+func CreateMagicLink(db *gorm.DB, coach_id int, scope string, actor_id int) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	code := hex.EncodeToString(buf)
+	now := time.Now()
+	link := CoachMagicLink{
+		TokenHash: HashToken(code),
+		Scope:     scope,
+		ActorId:   actor_id,
+		ExpiredAt: now.Add(DefaultJWTConfig.MagicLinkDuration),
+		CoachId:   coach_id,
+		CreatedAt: now,
+	}
+	if err := db.Create(&link).Error; err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// ConsumeMagicLink 使用登录链接，每个链接只能成功使用一次
+func ConsumeMagicLink(db *gorm.DB, code string) (*CoachMagicLink, error) {
+	if code == "" {
+		return nil, ErrInvalidMagicLink
+	}
+	var link CoachMagicLink
+	if err := db.Where("token_hash = ?", HashToken(code)).First(&link).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrInvalidMagicLink
+		}
+		return nil, err
+	}
+	now := time.Now()
+	r := db.Model(&CoachMagicLink{}).
+		Where("id = ? AND used_at IS NULL AND expired_at > ?", link.Id, now).
+		Update("used_at", now)
+	if r.Error != nil {
+		return nil, r.Error
+	}
+	if r.RowsAffected == 0 {
+		return nil, ErrInvalidMagicLink
+	}
+	link.UsedAt = &now
+	return &link, nil
+}
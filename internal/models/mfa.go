@@ -0,0 +1,157 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"gorm.io/gorm"
+
+	"myapi/pkg/totp"
+)
+
+// CoachTOTP 两步验证
+type CoachTOTP struct {
+	Id           int        `json:"id" gorm:"primaryKey"`
+	Secret       string     `json:"-"`
+	Status       int        `json:"status"` // 1待确认 2已开启
+	LastUsedStep int64      `json:"-"`
+	EnabledAt    *time.Time `json:"enabled_at"`
+	CoachId      int        `json:"coach_id"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func (CoachTOTP) TableName() string {
+	return "COACH_TOTP"
+}
+
+// CoachRecoveryCode 两步验证的恢复码，手机丢了的时候用
+type CoachRecoveryCode struct {
+	Id        int        `json:"id" gorm:"primaryKey"`
+	CodeHash  string     `json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CoachId   int        `json:"coach_id"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (CoachRecoveryCode) TableName() string {
+	return "COACH_RECOVERY_CODE"
+}
+
+const (
+	// TOTPStatus 两步验证状态
+	TOTPStatusPending = 1 // 已生成密钥，待确认
+	TOTPStatusEnabled = 2 // 已开启
+
+	RecoveryCodeCount = 10
+
+	MFAChallengePurpose = "mfa"
+)
+
+var ErrInvalidMFAChallenge = errors.New("invalid mfa challenge")
+
+// IsTOTPEnabled 是否开启了两步验证
+func IsTOTPEnabled(db *gorm.DB, coach_id int) (bool, error) {
+	var count int64
+	if err := db.Model(&CoachTOTP{}).Where("coach_id = ? AND status = ?", coach_id, TOTPStatusEnabled).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count != 0, nil
+}
+
+// CheckTOTP 校验验证码，同一个周期的验证码只能用一次
+func CheckTOTP(db *gorm.DB, record *CoachTOTP, code string) (bool, error) {
+	step, ok := totp.Validate(record.Secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	r := db.Model(&CoachTOTP{}).
+		Where("id = ? AND last_used_step < ?", record.Id, step).
+		Update("last_used_step", step)
+	if r.Error != nil {
+		return false, r.Error
+	}
+	return r.RowsAffected != 0, nil
+}
+
+// VerifyMFACode 用验证码或者恢复码完成两步验证
+func VerifyMFACode(db *gorm.DB, coach_id int, code string) (bool, error) {
+	var record CoachTOTP
+	if err := db.Where("coach_id = ? AND status = ?", coach_id, TOTPStatusEnabled).First(&record).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		return CheckTOTP(db, &record, code)
+	}
+	r := db.Model(&CoachRecoveryCode{}).
+		Where("coach_id = ? AND code_hash = ? AND used_at IS NULL", coach_id, HashToken(strings.ToLower(code))).
+		Update("used_at", time.Now())
+	if r.Error != nil {
+		return false, r.Error
+	}
+	return r.RowsAffected != 0, nil
+}
+
+// GenerateRecoveryCodes 重新生成恢复码，之前的全部作废，明文只在这里返回一次
+func GenerateRecoveryCodes(db *gorm.DB, coach_id int) ([]string, error) {
+	if err := db.Where("coach_id = ?", coach_id).Delete(&CoachRecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	codes := make([]string, 0, RecoveryCodeCount)
+	records := make([]CoachRecoveryCode, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		v := hex.EncodeToString(buf)
+		code := v[:5] + "-" + v[5:]
+		codes = append(codes, code)
+		records = append(records, CoachRecoveryCode{
+			CodeHash:  HashToken(code),
+			CoachId:   coach_id,
+			CreatedAt: now,
+		})
+	}
+	if err := db.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// GenerateMFAChallenge 密码正确但还需要两步验证时返回的临时凭证，不能用来访问其他接口
+func GenerateMFAChallenge(coach_id int, secret_key string) (string, time.Time, error) {
+	expiration_time := time.Now().Add(DefaultJWTConfig.MFAChallengeDuration)
+	claims := jwt.MapClaims{
+		"id":         coach_id,
+		"purpose":    MFAChallengePurpose,
+		"expires_at": jwt.NewNumericDate(expiration_time),
+		"issuer":     "top.fithub",
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	str, err := token.SignedString([]byte(secret_key))
+	if err != nil {
+		return "", time.Now(), err
+	}
+	return str, expiration_time, nil
+}
+
+// ParseMFAChallenge 返回临时凭证所属的用户
+func ParseMFAChallenge(str string, secret_key string) (int, error) {
+	claims, err := ParseJWT(str, secret_key)
+	if err != nil {
+		return 0, ErrInvalidMFAChallenge
+	}
+	if claims.Purpose != MFAChallengePurpose || claims.ExpiresAt < float64(time.Now().Unix()) || claims.Id == 0 {
+		return 0, ErrInvalidMFAChallenge
+	}
+	return int(claims.Id), nil
+}
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CoachSession 登录会话，access token 里带着 session_id，注销后立即失效
+type CoachSession struct {
+	Id               int        `json:"id" gorm:"primaryKey"`
+	SessionId        string     `json:"session_id"`
+	Status           int        `json:"status"` // 1有效 2已注销
+	Device           string     `json:"device"`
+	IP               string     `json:"ip" gorm:"column:ip"`
+	RefreshTokenHash string     `json:"-"`
+	Scope            string     `json:"scope"`        // 权限范围，空表示不限制
+	ActorId          int        `json:"actor_id"`     // 管理员代登录时的管理员id
+	MfaVerified      int        `json:"mfa_verified"` // 1通过了两步验证
+	ExpiredAt        time.Time  `json:"expired_at"`
+	LastUsedAt       *time.Time `json:"last_used_at"`
+	RevokedAt        *time.Time `json:"revoked_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+
+	CoachId int `json:"coach_id"`
+}
+
+func (CoachSession) TableName() string {
+	return "COACH_SESSION"
+}
+
+const (
+	// SessionStatus 会话状态
+	SessionStatusActive  = 1 // 有效
+	SessionStatusRevoked = 2 // 已注销
+
+	// 授权链接生成的会话，不能刷新
+	SessionDeviceAuthURL = "auth_url"
+)
+
+var ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+// HashToken 数据库里只保存 refresh token、登录链接 code 的哈希
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewRefreshToken 生成 refresh token，格式是 <session_id>.<随机串>，方便找到所属会话判断是否被重复使用
+func NewRefreshToken(session_id string) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return session_id + "." + hex.EncodeToString(buf), nil
+}
+
+// ParseRefreshToken 返回 refresh token 所属的会话
+func ParseRefreshToken(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", ErrInvalidRefreshToken
+	}
+	return parts[0], nil
+}
+
+// CreateCoachSession 创建会话并签发 access token 和 refresh token
+func CreateCoachSession(db *gorm.DB, coach_id int, device string, ip string, secret_key string) (*AuthResponse, error) {
+	return createCoachSession(db, coach_id, device, ip, 0, secret_key)
+}
+
+// CreateMFAVerifiedSession 通过两步验证后创建的会话
+func CreateMFAVerifiedSession(db *gorm.DB, coach_id int, device string, ip string, secret_key string) (*AuthResponse, error) {
+	return createCoachSession(db, coach_id, device, ip, 1, secret_key)
+}
+
+func createCoachSession(db *gorm.DB, coach_id int, device string, ip string, mfa_verified int, secret_key string) (*AuthResponse, error) {
+	now := time.Now()
+	session_id := strings.ReplaceAll(uuid.New().String(), "-", "")
+	refresh_token, err := NewRefreshToken(session_id)
+	if err != nil {
+		return nil, err
+	}
+	if len(device) > 200 {
+		device = device[:200]
+	}
+	session := CoachSession{
+		SessionId:        session_id,
+		Status:           SessionStatusActive,
+		Device:           device,
+		IP:               ip,
+		RefreshTokenHash: HashToken(refresh_token),
+		MfaVerified:      mfa_verified,
+		ExpiredAt:        now.Add(DefaultJWTConfig.RefreshTokenDuration),
+		CreatedAt:        now,
+		CoachId:          coach_id,
+	}
+	if err := db.Create(&session).Error; err != nil {
+		return nil, err
+	}
+	token, expires_at, err := GenerateJWT(coach_id, session_id, DefaultJWTConfig.TokenDuration, secret_key)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthResponse{
+		Token:            "Bearer " + token,
+		ExpiresAt:        expires_at.Unix(),
+		RefreshToken:     refresh_token,
+		RefreshExpiresAt: session.ExpiredAt.Unix(),
+		Status:           "success",
+	}, nil
+}
+
+// CreateAuthURLSession 登录链接换来的会话，只签发 access token，不能刷新，可以被注销
+func CreateAuthURLSession(db *gorm.DB, link *CoachMagicLink, ip string, secret_key string) (*AuthResponse, error) {
+	now := time.Now()
+	session := CoachSession{
+		SessionId: strings.ReplaceAll(uuid.New().String(), "-", ""),
+		Status:    SessionStatusActive,
+		Device:    SessionDeviceAuthURL,
+		IP:        ip,
+		Scope:     link.Scope,
+		ActorId:   link.ActorId,
+		ExpiredAt: now.Add(DefaultJWTConfig.AuthURLTokenDuration),
+		CreatedAt: now,
+		CoachId:   link.CoachId,
+	}
+	if err := db.Create(&session).Error; err != nil {
+		return nil, err
+	}
+	token, expires_at, err := GenerateScopedJWT(link.CoachId, session.SessionId, link.Scope, link.ActorId, DefaultJWTConfig.AuthURLTokenDuration, secret_key)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthResponse{
+		Token:     "Bearer " + token,
+		ExpiresAt: expires_at.Unix(),
+		Status:    "success",
+	}, nil
+}
+
+// RevokeCoachSessions 注销会话，session_id 为空时注销该用户所有会话
+func RevokeCoachSessions(db *gorm.DB, coach_id int, session_id string) (int64, error) {
+	query := db.Model(&CoachSession{}).Where("coach_id = ? AND status = ?", coach_id, SessionStatusActive)
+	if session_id != "" {
+		query = query.Where("session_id = ?", session_id)
+	}
+	r := query.Updates(map[string]interface{}{
+		"status":     SessionStatusRevoked,
+		"revoked_at": time.Now(),
+	})
+	return r.RowsAffected, r.Error
+}
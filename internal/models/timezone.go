@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+	// 部署的镜像里不一定有时区数据，打包进程序里
+	_ "time/tzdata"
+)
+
+// DefaultTimezone 没有设置时区的用户按东八区处理，和之前写死的 +8 小时一致
+const DefaultTimezone = "Asia/Shanghai"
+
+// ParseTimezone 解析 IANA 时区名，空字符串表示默认时区
+func ParseTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		name = DefaultTimezone
+	}
+	return time.LoadLocation(name)
+}
+
+// Location 用户所在的时区，时区无效时退回默认时区
+func (c Coach) Location() *time.Location {
+	loc, err := ParseTimezone(c.Timezone)
+	if err != nil {
+		loc, _ = time.LoadLocation(DefaultTimezone)
+	}
+	return loc
+}
+
+// StartOfDay 时间 t 在时区 loc 下当天的 0 点
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// VerificationCode 邮箱验证码
+type VerificationCode struct {
+	Id        int        `json:"id" gorm:"primaryKey"`
+	Target    string     `json:"target"`
+	Purpose   int        `json:"purpose"`
+	CodeHash  string     `json:"-"`
+	Attempts  int        `json:"attempts"`
+	ExpiredAt time.Time  `json:"expired_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (VerificationCode) TableName() string {
+	return "VERIFICATION_CODE"
+}
+
+const (
+	// VerificationPurpose 验证码用途
+	VerificationPurposeVerifyEmail   = 1 // 验证邮箱
+	VerificationPurposeResetPassword = 2 // 重置密码
+	VerificationPurposePhoneLogin    = 3 // 手机号登录、绑定
+
+	VerificationCodeTTL         = 10 * time.Minute // 有效期
+	VerificationCodeInterval    = time.Minute      // 两次发送的最小间隔
+	VerificationCodeMaxAttempts = 5                // 每个验证码最多尝试次数
+)
+
+var (
+	ErrVerificationCodeTooFrequent = errors.New("verification code requested too frequently")
+	ErrVerificationCodeInvalid     = errors.New("invalid verification code")
+	ErrVerificationCodeExpired     = errors.New("verification code expired")
+)
+
+// CreateVerificationCode 生成 6 位数字验证码，之前没用掉的验证码一起作废
+func CreateVerificationCode(db *gorm.DB, target string, purpose int) (string, error) {
+	now := time.Now()
+	var count int64
+	if err := db.Model(&VerificationCode{}).
+		Where("target = ? AND purpose = ? AND created_at > ?", target, purpose, now.Add(-VerificationCodeInterval)).
+		Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count != 0 {
+		return "", ErrVerificationCodeTooFrequent
+	}
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+	if err := db.Model(&VerificationCode{}).
+		Where("target = ? AND purpose = ? AND used_at IS NULL", target, purpose).
+		Update("expired_at", now).Error; err != nil {
+		return "", err
+	}
+	record := VerificationCode{
+		Target:    target,
+		Purpose:   purpose,
+		CodeHash:  HashToken(target + ":" + code),
+		ExpiredAt: now.Add(VerificationCodeTTL),
+		CreatedAt: now,
+	}
+	if err := db.Create(&record).Error; err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// CheckVerificationCode 校验验证码，成功后验证码作废，错误次数超过限制也作废
+func CheckVerificationCode(db *gorm.DB, target string, purpose int, code string) error {
+	now := time.Now()
+	var record VerificationCode
+	if err := db.Where("target = ? AND purpose = ? AND used_at IS NULL AND expired_at > ?", target, purpose, now).
+		Order("created_at DESC").
+		First(&record).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return ErrVerificationCodeExpired
+		}
+		return err
+	}
+	matched := code != "" && HashToken(target+":"+code) == record.CodeHash
+	// 先占用一次尝试机会再返回结果，并发的请求也不会超过次数限制，猜对时同一条语句里作废验证码
+	updates := map[string]interface{}{"attempts": gorm.Expr("attempts + 1")}
+	if matched {
+		updates["used_at"] = now
+	}
+	r := db.Model(&VerificationCode{}).
+		Where("id = ? AND used_at IS NULL AND attempts < ?", record.Id, VerificationCodeMaxAttempts).
+		Updates(updates)
+	if r.Error != nil {
+		return r.Error
+	}
+	if r.RowsAffected == 0 {
+		return ErrVerificationCodeExpired
+	}
+	if !matched {
+		return ErrVerificationCodeInvalid
+	}
+	return nil
+}
@@ -16,7 +16,7 @@ type WorkoutDay struct {
 	Id                int        `json:"id" db:"id"`                           // Primary key
 	Title             string     `json:"title"`                                // 标题
 	Type              string     `json:"type"`                                 // 类型
-	Time              string     `json:"time" db:"time"`                       // Training time (YYYY-MM-DD HH:MM:SS)
+	Time              *time.Time `json:"time,omitempty" db:"time"`             // Training time
 	Status            int        `json:"status" db:"status"`                   // 0等待进行 1进行中 2已完成 3已过期 4手动作废
 	GroupNo           string     `json:"group_no"`                             // 一起训练的标记
 	PendingSteps      string     `json:"pending_steps" db:"pending_steps"`     // Execution records in JSON array
@@ -26,11 +26,13 @@ type WorkoutDay struct {
 	Medias            string     `json:"medias"`
 	EstimatedDuration int        `json:"estimated_duration" db:"estimated_duration"` // 训练预计时长
 	Duration          int        `json:"duration"`                                   // 本次训练实际时长 单位 分
+	Shared            int        `json:"shared"`                                     // 1分享到动态
 	TotalVolume       float64    `json:"total_volume"`                               // 总容量 单位 公斤
 	CreatedAt         time.Time  `json:"created_at" db:"created_at"`                 // Creation time
 	StartedAt         *time.Time `json:"started_at,omitempty" db:"started_at"`       // Start time
 	UpdatedAt         *time.Time `json:"updated_at,omitempty" db:"updated_at"`       // Update time
 	FinishedAt        *time.Time `json:"finished_at,omitempty" db:"finished_at"`     // Finish time
+	SharedAt          *time.Time `json:"shared_at,omitempty" db:"shared_at"`         // 分享时间
 	CoachId           int        `json:"coach_id" db:"coach_id" `                    // Coach ID
 
 	WorkoutPlanId int         `json:"workout_plan_id" db:"workout_plan_id"` // Associated workout plan ID
@@ -0,0 +1,257 @@
+// Package backup SQLite 数据库的在线备份和恢复
+//
+// 备份用 VACUUM INTO 生成一致的快照，服务运行中（WAL 模式）也能执行；恢复前会检查备份文件的完整性，恢复时服务必须停止
+package backup
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// 备份文件名是 backup-时间.db，压缩的再加上 .gz，按文件名排序就是时间顺序
+const (
+	filePrefix = "backup-"
+	fileExt    = ".db"
+	gzipExt    = ".gz"
+	timeLayout = "20060102-150405"
+)
+
+// Options 备份配置
+type Options struct {
+	// 备份文件所在的目录，不存在时会创建
+	Dir string
+	// 是否用 gzip 压缩
+	Gzip bool
+	// 保留最近的几个备份，0 表示都保留
+	Keep int
+}
+
+// Backup 给 db 做一次快照，写到 opts.Dir 下，返回备份文件的路径
+func Backup(ctx context.Context, db *gorm.DB, opts Options) (string, error) {
+	if name := db.Dialector.Name(); name != "sqlite" {
+		return "", fmt.Errorf("backup only supports sqlite, use the native tools (pg_dump, mysqldump) for %s", name)
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	name := filePrefix + time.Now().UTC().Format(timeLayout) + fileExt
+	path := filepath.Join(opts.Dir, name)
+	// 先写到临时文件，完成后再改名，中途失败不会留下不完整的备份
+	tmp := path + ".tmp"
+	os.Remove(tmp)
+	if err := db.WithContext(ctx).Exec("VACUUM INTO ?", tmp).Error; err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to snapshot database: %w", err)
+	}
+	if opts.Gzip {
+		path += gzipExt
+		if err := compress(tmp, path+".tmp"); err != nil {
+			os.Remove(tmp)
+			os.Remove(path + ".tmp")
+			return "", err
+		}
+		os.Remove(tmp)
+		tmp = path + ".tmp"
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to save backup: %w", err)
+	}
+	if _, err := Rotate(opts.Dir, opts.Keep); err != nil {
+		return path, err
+	}
+	return path, nil
+}
+
+// List 按时间从旧到新列出 dir 下的备份文件
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, filePrefix) {
+			continue
+		}
+		if strings.HasSuffix(name, fileExt) || strings.HasSuffix(name, fileExt+gzipExt) {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Rotate 只保留最近的 keep 个备份，返回删除的文件
+func Rotate(dir string, keep int) ([]string, error) {
+	if keep <= 0 {
+		return nil, nil
+	}
+	files, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) <= keep {
+		return nil, nil
+	}
+	removed := files[:len(files)-keep]
+	for _, f := range removed {
+		if err := os.Remove(f); err != nil {
+			return nil, fmt.Errorf("failed to remove old backup: %w", err)
+		}
+	}
+	return removed, nil
+}
+
+// Restore 用备份文件 src 覆盖数据库文件 db_path，执行前服务必须已经停止
+//
+// 备份文件先解压到 db_path 同目录下并做完整性检查，通过后才替换；原来的数据库连同 -wal、-shm 文件改名为 db_path.before-restore-时间 保留
+func Restore(src, db_path string) (string, error) {
+	tmp := db_path + ".restore"
+	os.Remove(tmp)
+	if strings.HasSuffix(src, gzipExt) {
+		if err := decompress(src, tmp); err != nil {
+			os.Remove(tmp)
+			return "", err
+		}
+	} else if err := copyFile(src, tmp); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := Check(tmp); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	var previous string
+	if _, err := os.Stat(db_path); err == nil {
+		previous = db_path + ".before-restore-" + time.Now().UTC().Format(timeLayout)
+		if err := os.Rename(db_path, previous); err != nil {
+			os.Remove(tmp)
+			return "", fmt.Errorf("failed to move current database aside: %w", err)
+		}
+	}
+	// WAL 和共享内存文件属于旧的数据库，留着会被当成新数据库的日志，跟旧数据库一起改名，WAL 里可能还有没写回的数据
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if _, err := os.Stat(db_path + suffix); err != nil {
+			continue
+		}
+		if previous == "" {
+			os.Remove(db_path + suffix)
+			continue
+		}
+		if err := os.Rename(db_path+suffix, previous+suffix); err != nil {
+			os.Remove(tmp)
+			return previous, fmt.Errorf("failed to move current database aside: %w", err)
+		}
+	}
+	if err := os.Rename(tmp, db_path); err != nil {
+		return previous, fmt.Errorf("failed to restore database: %w", err)
+	}
+	return previous, nil
+}
+
+// Check 检查 SQLite 文件的完整性，并且迁移不能处于 dirty 状态
+func Check(path string) error {
+	db, err := gorm.Open(sqlite.Open("file:"+path+"?mode=ro"), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	sql_db, err := db.DB()
+	if err != nil {
+		return err
+	}
+	defer sql_db.Close()
+
+	var result string
+	if err := db.Raw("PRAGMA integrity_check").Row().Scan(&result); err != nil {
+		return fmt.Errorf("failed to check backup: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("backup failed integrity check: %s", result)
+	}
+	var row struct {
+		Version int64
+		Dirty   bool
+	}
+	if err := db.Table("schema_migrations").Select("version, dirty").Limit(1).Scan(&row).Error; err != nil {
+		return fmt.Errorf("backup has no migration version: %w", err)
+	}
+	if row.Dirty {
+		return fmt.Errorf("backup is dirty at migration version %d", row.Version)
+	}
+	return nil
+}
+
+func compress(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to compress backup: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to compress backup: %w", err)
+	}
+	return out.Close()
+}
+
+func decompress(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	zr, err := gzip.NewReader(in)
+	if err != nil {
+		return fmt.Errorf("failed to decompress backup: %w", err)
+	}
+	defer zr.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, zr); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to decompress backup: %w", err)
+	}
+	return out.Close()
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
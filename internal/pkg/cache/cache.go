@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache is a key/value store with expiration
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+	Delete(key string)
+}
+
+// cleanInterval 清理过期数据的间隔
+const cleanInterval = time.Minute
+
+type entry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryCache keeps values in process memory, expired values are removed lazily
+type MemoryCache struct {
+	mu        sync.Mutex
+	items     map[string]entry
+	cleanedAt time.Time
+}
+
+// NewMemoryCache creates a new in-memory cache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		items: make(map[string]entry),
+	}
+}
+
+// Get returns the value if it exists and has not expired
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.expiresAt) {
+		delete(m.items, key)
+		return nil, false
+	}
+	return e.value, true
+}
+
+// Set stores the value for ttl
+func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	// 顺便清理过期的，避免一直增长，每隔 cleanInterval 才清理一次
+	if now.Sub(m.cleanedAt) > cleanInterval {
+		for k, e := range m.items {
+			if now.After(e.expiresAt) {
+				delete(m.items, k)
+			}
+		}
+		m.cleanedAt = now
+	}
+	m.items[key] = entry{value: value, expiresAt: now.Add(ttl)}
+}
+
+// Delete removes the value
+func (m *MemoryCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.items, key)
+}
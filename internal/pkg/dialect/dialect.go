@@ -0,0 +1,72 @@
+// Package dialect 生成各数据库写法不一样的 SQL 片段，统计里按本地日期、小时分组都通过这里拼 SQL
+package dialect
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Dialect 时间相关的 SQL 表达式，column 原样拼进 SQL，只能传代码里写死的列名
+type Dialect interface {
+	Name() string
+	// LocalDate 时间列换算到 UTC 偏移 offset 之后的日期，结果是 YYYY-MM-DD 格式的字符串，可以直接和同格式的参数比较
+	LocalDate(column string, offset time.Duration) string
+	// LocalHour 时间列换算到 UTC 偏移 offset 之后的小时，0-23 的整数
+	LocalHour(column string, offset time.Duration) string
+}
+
+// For 按连接的数据库类型返回对应的写法，不认识的类型按 SQLite 处理
+func For(db *gorm.DB) Dialect {
+	if db == nil || db.Dialector == nil {
+		return sqlite{}
+	}
+	switch db.Dialector.Name() {
+	case "postgres":
+		return postgres{}
+	case "mysql":
+		return mysql{}
+	default:
+		return sqlite{}
+	}
+}
+
+// SQLite 的时间按带时区的文本保存，日期函数会先换算成 UTC
+type sqlite struct{}
+
+func (sqlite) Name() string { return "sqlite" }
+
+func (sqlite) LocalDate(column string, offset time.Duration) string {
+	return fmt.Sprintf("DATE(%s, '%+d seconds')", column, int(offset.Seconds()))
+}
+
+func (sqlite) LocalHour(column string, offset time.Duration) string {
+	return fmt.Sprintf("CAST(strftime('%%H', %s, '%+d seconds') AS INTEGER)", column, int(offset.Seconds()))
+}
+
+// Postgres 的时间列是 timestamptz，连接的时区是 UTC
+type postgres struct{}
+
+func (postgres) Name() string { return "postgres" }
+
+func (postgres) LocalDate(column string, offset time.Duration) string {
+	return fmt.Sprintf("TO_CHAR(%s + INTERVAL '%d seconds', 'YYYY-MM-DD')", column, int(offset.Seconds()))
+}
+
+func (postgres) LocalHour(column string, offset time.Duration) string {
+	return fmt.Sprintf("CAST(EXTRACT(HOUR FROM %s + INTERVAL '%d seconds') AS INTEGER)", column, int(offset.Seconds()))
+}
+
+// MySQL 的 DATETIME 不带时区，连接参数 loc=UTC，驱动按 UTC 保存和读取
+type mysql struct{}
+
+func (mysql) Name() string { return "mysql" }
+
+func (mysql) LocalDate(column string, offset time.Duration) string {
+	return fmt.Sprintf("DATE_FORMAT(DATE_ADD(%s, INTERVAL %d SECOND), '%%Y-%%m-%%d')", column, int(offset.Seconds()))
+}
+
+func (mysql) LocalHour(column string, offset time.Duration) string {
+	return fmt.Sprintf("HOUR(DATE_ADD(%s, INTERVAL %d SECOND))", column, int(offset.Seconds()))
+}
@@ -0,0 +1,127 @@
+package errcode
+
+import "net/http"
+
+// 错误码由 HTTP 状态码 + 两位序号组成，新增时在对应分组后面追加，不要复用已经删除的错误码
+
+// 通用参数错误
+var (
+	ErrBadRequest       = New(40000, http.StatusBadRequest, "bad_request")
+	ErrInvalidBody      = New(40001, http.StatusBadRequest, "invalid_body")
+	ErrMissingParam     = New(40002, http.StatusBadRequest, "missing_param")
+	ErrInvalidParam     = New(40003, http.StatusBadRequest, "invalid_param")
+	ErrIllegalOperation = New(40004, http.StatusBadRequest, "illegal_operation")
+	ErrSensitiveName    = New(40005, http.StatusBadRequest, "sensitive_name")
+	ErrSensitiveNick    = New(40006, http.StatusBadRequest, "sensitive_nickname")
+	ErrEmailRequired    = New(40007, http.StatusBadRequest, "email_required")
+	ErrPasswordRequired = New(40008, http.StatusBadRequest, "password_required")
+	ErrCodeRequired     = New(40009, http.StatusBadRequest, "code_required")
+	ErrTitleRequired    = New(40010, http.StatusBadRequest, "title_required")
+	ErrNicknameRequired = New(40011, http.StatusBadRequest, "nickname_required")
+	ErrContentRequired  = New(40012, http.StatusBadRequest, "content_required")
+	ErrCommentRequired  = New(40013, http.StatusBadRequest, "comment_required")
+	ErrInvalidPhone     = New(40014, http.StatusBadRequest, "invalid_phone")
+	ErrInvalidAmount    = New(40015, http.StatusBadRequest, "invalid_amount")
+	ErrSensitiveContent = New(40016, http.StatusBadRequest, "sensitive_content")
+	ErrInvalidTimezone  = New(40017, http.StatusBadRequest, "invalid_timezone")
+	ErrInvalidEmail     = New(40018, http.StatusBadRequest, "invalid_email")
+)
+
+// 帐号、登录相关
+var (
+	ErrVerificationCodeInvalid = New(40020, http.StatusBadRequest, "verification_code_invalid")
+	ErrVerificationCodeExpired = New(40021, http.StatusBadRequest, "verification_code_expired")
+	ErrPasswordIncorrect       = New(40022, http.StatusBadRequest, "password_incorrect")
+	ErrLoginFailed             = New(40023, http.StatusBadRequest, "login_failed")
+	ErrOAuthDisabled           = New(40024, http.StatusBadRequest, "oauth_disabled")
+	ErrOAuthExpired            = New(40025, http.StatusBadRequest, "oauth_expired")
+	ErrOAuthFailed             = New(40026, http.StatusBadRequest, "oauth_failed")
+	ErrLastAccount             = New(40027, http.StatusBadRequest, "last_account")
+	ErrAdminUndeletable        = New(40028, http.StatusBadRequest, "admin_undeletable")
+)
+
+// 业务规则
+var (
+	ErrInviteInvalid       = New(40040, http.StatusBadRequest, "invite_invalid")
+	ErrInviteSelf          = New(40041, http.StatusBadRequest, "invite_self")
+	ErrInviteUnacceptable  = New(40042, http.StatusBadRequest, "invite_unacceptable")
+	ErrFollowSelf          = New(40043, http.StatusBadRequest, "follow_self")
+	ErrCannotFollow        = New(40044, http.StatusBadRequest, "cannot_follow")
+	ErrCannotAddFriend     = New(40045, http.StatusBadRequest, "cannot_add_friend")
+	ErrPublishTimeInvalid  = New(40046, http.StatusBadRequest, "publish_time_invalid")
+	ErrRejectReasonMissing = New(40047, http.StatusBadRequest, "reject_reason_missing")
+	ErrShareUnfinished     = New(40048, http.StatusBadRequest, "share_unfinished")
+	ErrScheduleEmpty       = New(40049, http.StatusBadRequest, "schedule_empty")
+	ErrExamNotInProgress   = New(40050, http.StatusBadRequest, "exam_not_in_progress")
+)
+
+// 登录凭证
+var (
+	ErrUnauthorized = New(40100, http.StatusUnauthorized, "unauthorized")
+	ErrTokenMissing = New(40101, http.StatusUnauthorized, "token_missing")
+	ErrTokenInvalid = New(40102, http.StatusUnauthorized, "token_invalid")
+	ErrTokenExpired = New(40103, http.StatusUnauthorized, "token_expired")
+	ErrLinkExpired  = New(40104, http.StatusUnauthorized, "link_expired")
+)
+
+// 权限
+var (
+	ErrForbidden            = New(40300, http.StatusForbidden, "forbidden")
+	ErrMFARequired          = New(40301, http.StatusForbidden, "mfa_required")
+	ErrSubscriptionRequired = New(40302, http.StatusForbidden, "subscription_required")
+)
+
+// 记录不存在
+var (
+	ErrNotFound             = New(40400, http.StatusNotFound, "not_found")
+	ErrUserNotFound         = New(40401, http.StatusNotFound, "user_not_found")
+	ErrStudentNotFound      = New(40402, http.StatusNotFound, "student_not_found")
+	ErrInviteNotFound       = New(40403, http.StatusNotFound, "invite_not_found")
+	ErrExamNotFound         = New(40404, http.StatusNotFound, "exam_not_found")
+	ErrPaperNotFound        = New(40405, http.StatusNotFound, "paper_not_found")
+	ErrQuizAnswerNotFound   = New(40406, http.StatusNotFound, "quiz_answer_not_found")
+	ErrWorkoutActionMissing = New(40407, http.StatusNotFound, "workout_action_not_found")
+	ErrWorkoutPlanNotFound  = New(40408, http.StatusNotFound, "workout_plan_not_found")
+	ErrScheduleNotFound     = New(40409, http.StatusNotFound, "workout_schedule_not_found")
+	ErrCommentNotFound      = New(40410, http.StatusNotFound, "comment_not_found")
+	ErrFolderNotFound       = New(40411, http.StatusNotFound, "favorite_folder_not_found")
+	ErrAccountNotBound      = New(40412, http.StatusNotFound, "account_not_bound")
+	ErrEmailNotBound        = New(40413, http.StatusNotFound, "email_not_bound")
+	ErrTOTPNotEnrolled      = New(40414, http.StatusNotFound, "totp_not_enrolled")
+	ErrDeletionNotFound     = New(40415, http.StatusNotFound, "deletion_not_found")
+	ErrNoWorkoutThisMonth   = New(40416, http.StatusNotFound, "no_workout_this_month")
+)
+
+// 状态冲突
+var (
+	ErrAlreadyExists       = New(40900, http.StatusConflict, "already_exists")
+	ErrEmailUsed           = New(40901, http.StatusConflict, "email_used")
+	ErrAlreadyFollowed     = New(40902, http.StatusConflict, "already_followed")
+	ErrNotFollowed         = New(40903, http.StatusConflict, "not_followed")
+	ErrAlreadyFriend       = New(40904, http.StatusConflict, "already_friend")
+	ErrRelationshipExists  = New(40905, http.StatusConflict, "relationship_exists")
+	ErrAccountCompleted    = New(40906, http.StatusConflict, "account_completed")
+	ErrAccountBoundOther   = New(40907, http.StatusConflict, "account_bound_other")
+	ErrProviderBound       = New(40908, http.StatusConflict, "provider_bound")
+	ErrTOTPEnabled         = New(40909, http.StatusConflict, "totp_enabled")
+	ErrGiftCardUsed        = New(40910, http.StatusConflict, "gift_card_used")
+	ErrScheduleApplied     = New(40911, http.StatusConflict, "workout_schedule_applied")
+	ErrContentApproved     = New(40912, http.StatusConflict, "content_approved")
+	ErrContentInReview     = New(40913, http.StatusConflict, "content_in_review")
+	ErrContentNotPending   = New(40914, http.StatusConflict, "content_not_pending")
+	ErrWorkoutActionExists = New(40915, http.StatusConflict, "workout_action_exists")
+)
+
+// 频率限制
+var (
+	ErrTooManyAttempts = New(42900, http.StatusTooManyRequests, "too_many_attempts")
+	ErrCodeTooFrequent = New(42901, http.StatusTooManyRequests, "verification_code_too_frequent")
+	ErrRateLimited     = New(42902, http.StatusTooManyRequests, "rate_limited")
+)
+
+// 服务端错误
+var (
+	ErrInternal      = New(50000, http.StatusInternalServerError, "internal")
+	ErrDataCorrupted = New(50001, http.StatusInternalServerError, "data_corrupted")
+	ErrSendFailed    = New(50002, http.StatusInternalServerError, "send_failed")
+)
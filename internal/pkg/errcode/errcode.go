@@ -0,0 +1,106 @@
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// Error 带稳定错误码的业务错误
+//
+// Code 是给客户端判断错误类型用的，前三位和 HTTP 状态码一致，发布后不能再修改含义；
+// Key 是文案的 i18n key，返回时根据 Accept-Language 取对应语言的文案
+type Error struct {
+	Code   int
+	Status int
+	Key    string
+	Args   []interface{}
+	// 原始错误，只写到日志，不返回给客户端
+	Err error
+}
+
+var catalogue = map[int]*Error{}
+
+// New 定义一个错误码，重复定义会 panic
+func New(code int, status int, key string) *Error {
+	if _, ok := catalogue[code]; ok {
+		panic(fmt.Sprintf("errcode: duplicate code %d", code))
+	}
+	e := &Error{Code: code, Status: status, Key: key}
+	catalogue[code] = e
+	return e
+}
+
+// List 所有定义过的错误码，按 Code 排序
+func List() []*Error {
+	list := make([]*Error, 0, len(catalogue))
+	for _, e := range catalogue {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%d %s: %v", e.Code, e.Key, e.Err)
+	}
+	return fmt.Sprintf("%d %s", e.Code, e.Key)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is 同一个错误码就认为是同一个错误，Wrap、WithArgs 之后仍然能用 errors.Is 判断
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Wrap 附带原始错误，返回新的副本
+func (e *Error) Wrap(err error) *Error {
+	v := *e
+	v.Err = err
+	return &v
+}
+
+// WithArgs 文案里的参数，返回新的副本
+func (e *Error) WithArgs(args ...interface{}) *Error {
+	v := *e
+	v.Args = args
+	return &v
+}
+
+// From 把任意错误转换成 *Error，记录不存在对应 ErrNotFound，其他未知错误都是 ErrInternal
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound.Wrap(err)
+	}
+	return ErrInternal.Wrap(err)
+}
+
+// MissingParam 缺少某个参数
+func MissingParam(name string) *Error {
+	v := *ErrMissingParam
+	v.Key = "missing_param_named"
+	v.Args = []interface{}{name}
+	return &v
+}
+
+// InvalidParam 某个参数格式不对
+func InvalidParam(name string) *Error {
+	v := *ErrInvalidParam
+	v.Key = "invalid_param_named"
+	v.Args = []interface{}{name}
+	return &v
+}
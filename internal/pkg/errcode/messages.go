@@ -0,0 +1,240 @@
+package errcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	LangZh = "zh"
+	LangEn = "en"
+)
+
+// DefaultLang 没有 Accept-Language 或者不支持时使用的语言
+const DefaultLang = LangZh
+
+var messages = map[string]map[string]string{
+	LangZh: {
+		"bad_request":         "请求参数错误",
+		"invalid_body":        "请求参数格式错误",
+		"missing_param":       "缺少参数",
+		"missing_param_named": "缺少 %s 参数",
+		"invalid_param":       "参数错误",
+		"invalid_param_named": "参数 %s 格式错误",
+		"illegal_operation":   "非法操作",
+		"sensitive_name":      "名称包含敏感词",
+		"sensitive_nickname":  "昵称包含敏感词",
+		"email_required":      "请输入邮箱",
+		"password_required":   "请输入密码",
+		"code_required":       "请输入验证码",
+		"title_required":      "缺少标题",
+		"nickname_required":   "缺少昵称",
+		"content_required":    "缺少内容",
+		"comment_required":    "请输入评论内容",
+		"invalid_phone":       "请输入正确的手机号",
+		"invalid_amount":      "数量必须大于0",
+		"sensitive_content":   "内容包含敏感词",
+		"invalid_timezone":    "时区不正确",
+		"invalid_email":       "请输入正确的邮箱",
+
+		"verification_code_invalid": "验证码错误",
+		"verification_code_expired": "验证码已失效，请重新获取",
+		"password_incorrect":        "密码错误",
+		"login_failed":              "邮箱或密码错误",
+		"oauth_disabled":            "未开启第三方登录",
+		"oauth_expired":             "授权已失效，请重新授权",
+		"oauth_failed":              "授权失败，请重新授权",
+		"last_account":              "至少保留一种登录方式",
+		"admin_undeletable":         "管理员帐号不能注销",
+
+		"invite_invalid":        "邀请已失效",
+		"invite_self":           "不能处理自己的邀请",
+		"invite_unacceptable":   "无法接受该邀请",
+		"follow_self":           "不能关注自己",
+		"cannot_follow":         "无法关注该用户",
+		"cannot_add_friend":     "无法添加为好友",
+		"publish_time_invalid":  "定时发布时间不能早于当前时间",
+		"reject_reason_missing": "请填写不通过的原因",
+		"share_unfinished":      "训练完成后才能分享",
+		"schedule_empty":        "至少选择一天配置训练计划",
+		"exam_not_in_progress":  "考试不在进行中",
+
+		"unauthorized":  "请先登录",
+		"token_missing": "缺少登录凭证",
+		"token_invalid": "凭证失效请重新登录",
+		"token_expired": "凭证过期请重新登录",
+		"link_expired":  "链接已失效",
+
+		"forbidden":             "没有权限",
+		"mfa_required":          "请先开启并完成两步验证",
+		"subscription_required": "该功能需订阅后才能使用",
+
+		"not_found":                  "没有找到记录",
+		"user_not_found":             "用户不存在",
+		"student_not_found":          "没有找到该学员",
+		"invite_not_found":           "邀请不存在",
+		"exam_not_found":             "考试不存在",
+		"paper_not_found":            "试卷不存在",
+		"quiz_answer_not_found":      "答题记录不存在",
+		"workout_action_not_found":   "动作不存在",
+		"workout_plan_not_found":     "训练计划不存在",
+		"workout_schedule_not_found": "周期计划不存在",
+		"comment_not_found":          "评论不存在",
+		"favorite_folder_not_found":  "收藏夹不存在",
+		"account_not_bound":          "没有绑定该登录方式",
+		"email_not_bound":            "请先绑定邮箱",
+		"totp_not_enrolled":          "请先获取密钥",
+		"deletion_not_found":         "没有等待执行的注销申请",
+		"no_workout_this_month":      "本月没有任何训练记录",
+
+		"already_exists":           "该记录已存在",
+		"email_used":               "该邮箱已被使用",
+		"already_followed":         "已经关注了",
+		"not_followed":             "已经取消关注了",
+		"already_friend":           "已经是好友了",
+		"relationship_exists":      "已经建立过关系了",
+		"account_completed":        "已经补全过帐号了",
+		"account_bound_other":      "该帐号已绑定其他用户",
+		"provider_bound":           "已绑定过该登录方式，请先解绑",
+		"totp_enabled":             "已开启两步验证",
+		"gift_card_used":           "兑换码已被使用",
+		"workout_schedule_applied": "该周期计划已应用",
+		"content_approved":         "已经审核通过了",
+		"content_in_review":        "正在审核中",
+		"content_not_pending":      "该内容不在待审核状态",
+		"workout_action_exists":    "已存在同名动作",
+
+		"too_many_attempts":              "尝试次数过多，请 %d 秒后再试",
+		"verification_code_too_frequent": "验证码发送太频繁，请稍后再试",
+		"rate_limited":                   "请求太频繁，请 %d 秒后再试",
+
+		"internal":       "服务器内部错误",
+		"data_corrupted": "数据异常",
+		"send_failed":    "发送失败",
+	},
+	LangEn: {
+		"bad_request":         "Bad request",
+		"invalid_body":        "Invalid request body",
+		"missing_param":       "Missing parameter",
+		"missing_param_named": "Missing parameter: %s",
+		"invalid_param":       "Invalid parameter",
+		"invalid_param_named": "Invalid parameter: %s",
+		"illegal_operation":   "Illegal operation",
+		"sensitive_name":      "Name contains sensitive words",
+		"sensitive_nickname":  "Nickname contains sensitive words",
+		"email_required":      "Email is required",
+		"password_required":   "Password is required",
+		"code_required":       "Verification code is required",
+		"title_required":      "Title is required",
+		"nickname_required":   "Nickname is required",
+		"content_required":    "Content is required",
+		"comment_required":    "Comment cannot be empty",
+		"invalid_phone":       "Invalid phone number",
+		"invalid_amount":      "Amount must be greater than 0",
+		"sensitive_content":   "Content contains sensitive words",
+		"invalid_timezone":    "Invalid timezone",
+		"invalid_email":       "Invalid email address",
+
+		"verification_code_invalid": "Incorrect verification code",
+		"verification_code_expired": "Verification code has expired, please request a new one",
+		"password_incorrect":        "Incorrect password",
+		"login_failed":              "Incorrect email or password",
+		"oauth_disabled":            "Third-party login is not enabled",
+		"oauth_expired":             "Authorization has expired, please authorize again",
+		"oauth_failed":              "Authorization failed, please authorize again",
+		"last_account":              "At least one login method must be kept",
+		"admin_undeletable":         "The admin account cannot be deleted",
+
+		"invite_invalid":        "The invite is no longer valid",
+		"invite_self":           "You cannot respond to your own invite",
+		"invite_unacceptable":   "The invite cannot be accepted",
+		"follow_self":           "You cannot follow yourself",
+		"cannot_follow":         "You cannot follow this user",
+		"cannot_add_friend":     "Cannot add as friend",
+		"publish_time_invalid":  "Scheduled publish time cannot be in the past",
+		"reject_reason_missing": "Please provide a reason for rejection",
+		"share_unfinished":      "Only finished workouts can be shared",
+		"schedule_empty":        "Configure a workout plan for at least one day",
+		"exam_not_in_progress":  "Exam is not in progress",
+
+		"unauthorized":  "Please log in first",
+		"token_missing": "Missing credentials",
+		"token_invalid": "Invalid credentials, please log in again",
+		"token_expired": "Credentials expired, please log in again",
+		"link_expired":  "The link has expired",
+
+		"forbidden":             "Permission denied",
+		"mfa_required":          "Two-factor authentication is required",
+		"subscription_required": "A subscription is required for this feature",
+
+		"not_found":                  "Record not found",
+		"user_not_found":             "User not found",
+		"student_not_found":          "Student not found",
+		"invite_not_found":           "Invite not found",
+		"exam_not_found":             "Exam not found",
+		"paper_not_found":            "Paper not found",
+		"quiz_answer_not_found":      "Quiz answer not found",
+		"workout_action_not_found":   "Workout action not found",
+		"workout_plan_not_found":     "Workout plan not found",
+		"workout_schedule_not_found": "Workout schedule not found",
+		"comment_not_found":          "Comment not found",
+		"favorite_folder_not_found":  "Favorite folder not found",
+		"account_not_bound":          "This login method is not linked",
+		"email_not_bound":            "Please bind an email first",
+		"totp_not_enrolled":          "Please request a secret first",
+		"deletion_not_found":         "No pending deletion request",
+		"no_workout_this_month":      "No workouts this month",
+
+		"already_exists":           "Record already exists",
+		"email_used":               "Email is already in use",
+		"already_followed":         "Already followed",
+		"not_followed":             "Already unfollowed",
+		"already_friend":           "Already friends",
+		"relationship_exists":      "Relationship already exists",
+		"account_completed":        "Account has already been completed",
+		"account_bound_other":      "This account is linked to another user",
+		"provider_bound":           "This login method is already linked, unlink it first",
+		"totp_enabled":             "Two-factor authentication is already enabled",
+		"gift_card_used":           "The gift card has already been used",
+		"workout_schedule_applied": "The workout schedule has already been applied",
+		"content_approved":         "Already approved",
+		"content_in_review":        "Under review",
+		"content_not_pending":      "The content is not pending review",
+		"workout_action_exists":    "A workout action with the same name already exists",
+
+		"too_many_attempts":              "Too many attempts, please retry in %d seconds",
+		"verification_code_too_frequent": "Verification codes are requested too frequently, please try again later",
+		"rate_limited":                   "Too many requests, please retry in %d seconds",
+
+		"internal":       "Internal server error",
+		"data_corrupted": "Data corrupted",
+		"send_failed":    "Failed to send",
+	},
+}
+
+// Lang 根据 Accept-Language 选择文案语言，按客户端给出的顺序取第一个支持的语言
+func Lang(accept_language string) string {
+	for _, part := range strings.Split(accept_language, ",") {
+		tag := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		tag = strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+		if _, ok := messages[tag]; ok {
+			return tag
+		}
+	}
+	return DefaultLang
+}
+
+// Message 指定语言的文案，找不到时用默认语言，再找不到就返回 key
+func (e *Error) Message(lang string) string {
+	text, ok := messages[lang][e.Key]
+	if !ok {
+		text, ok = messages[DefaultLang][e.Key]
+	}
+	if !ok {
+		return e.Key
+	}
+	if len(e.Args) != 0 {
+		return fmt.Sprintf(text, e.Args...)
+	}
+	return text
+}
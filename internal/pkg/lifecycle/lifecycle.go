@@ -0,0 +1,135 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"myapi/pkg/logger"
+)
+
+// Worker 后台任务，ctx 取消后要尽快返回
+type Worker func(ctx context.Context) error
+
+type worker struct {
+	name string
+	run  Worker
+}
+
+type hook struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// Manager 管理 HTTP 服务和后台任务的启动、停止
+// 收到 SIGINT、SIGTERM 后先停止接收新请求，等处理中的请求完成，再停止后台任务，最后按注册的逆序执行 OnStop
+type Manager struct {
+	logger  *logger.Logger
+	timeout time.Duration
+	workers []worker
+	hooks   []hook
+}
+
+// New timeout 是停止时最多等待的时间，超时后还没完成的请求会被中断
+func New(logger *logger.Logger, timeout time.Duration) *Manager {
+	return &Manager{
+		logger:  logger,
+		timeout: timeout,
+	}
+}
+
+// Go 注册后台任务，Run 的时候启动
+func (m *Manager) Go(name string, run Worker) {
+	m.workers = append(m.workers, worker{name: name, run: run})
+}
+
+// Every 注册定时执行的后台任务，启动后先执行一次，出错只记录日志不退出
+func (m *Manager) Every(name string, interval time.Duration, fn func(ctx context.Context) error) {
+	m.Go(name, func(ctx context.Context) error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := fn(ctx); err != nil && ctx.Err() == nil {
+				m.logger.Error("Worker "+name+" failed", err)
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// OnStop 注册停止时要执行的清理，比如关闭数据库连接
+func (m *Manager) OnStop(name string, stop func(ctx context.Context) error) {
+	m.hooks = append(m.hooks, hook{name: name, stop: stop})
+}
+
+// Run 启动服务和后台任务，直到收到退出信号或者服务启动失败
+func (m *Manager) Run(server *http.Server) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, w := range m.workers {
+		wg.Add(1)
+		go func(w worker) {
+			defer wg.Done()
+			if err := w.run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				m.logger.Error("Worker "+w.name+" stopped", err)
+			}
+		}(w)
+	}
+
+	serve_err := make(chan error, 1)
+	go func() {
+		m.logger.Info("Starting server on " + server.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serve_err <- err
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	var err error
+	select {
+	case sig := <-quit:
+		m.logger.Info(fmt.Sprintf("Received %s, shutting down", sig))
+	case err = <-serve_err:
+	}
+
+	shutdown_ctx, done := context.WithTimeout(context.Background(), m.timeout)
+	defer done()
+	if serr := server.Shutdown(shutdown_ctx); serr != nil {
+		m.logger.Error("Failed to drain requests", serr)
+	}
+
+	cancel()
+	stopped := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-shutdown_ctx.Done():
+		m.logger.Warn("Workers did not stop before timeout")
+	}
+
+	for i := len(m.hooks) - 1; i >= 0; i-- {
+		if herr := m.hooks[i].stop(shutdown_ctx); herr != nil {
+			m.logger.Error("Failed to stop "+m.hooks[i].name, herr)
+		}
+	}
+	m.logger.Info("Server stopped")
+	return err
+}
@@ -0,0 +1,178 @@
+package loginguard
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"myapi/internal/pkg/cache"
+)
+
+// Record 某个 key 的登录失败情况
+type Record struct {
+	Failures     int
+	LockedUntil  time.Time
+	LastFailedAt time.Time
+}
+
+// Store 保存失败计数，默认用 cache 实现，多实例部署时换成共享存储
+type Store interface {
+	Get(key string) (Record, bool)
+	Set(key string, record Record, ttl time.Duration)
+	Delete(key string)
+}
+
+// Policy 失败多少次之后开始退避，以及什么时候锁定
+type Policy struct {
+	FreeAttempts int           // 不限制的失败次数
+	BaseDelay    time.Duration // 第一次退避的时长，之后每次翻倍
+	MaxDelay     time.Duration // 退避时长上限
+	LockAfter    int           // 失败这么多次后锁定
+	LockDuration time.Duration // 锁定时长
+	Window       time.Duration // 这么久没有失败就重新计数
+}
+
+var (
+	// DefaultAccountPolicy 按帐号计数
+	DefaultAccountPolicy = Policy{
+		FreeAttempts: 3,
+		BaseDelay:    time.Second,
+		MaxDelay:     time.Minute,
+		LockAfter:    10,
+		LockDuration: 15 * time.Minute,
+		Window:       time.Hour,
+	}
+	// DefaultIPPolicy 按 IP 计数，同一个 IP 可能有很多正常用户，放宽一些
+	DefaultIPPolicy = Policy{
+		FreeAttempts: 20,
+		BaseDelay:    time.Second,
+		MaxDelay:     time.Minute,
+		LockAfter:    100,
+		LockDuration: 15 * time.Minute,
+		Window:       time.Hour,
+	}
+)
+
+// Guard 登录失败计数，超过次数后指数退避，再多就临时锁定
+type Guard struct {
+	store   Store
+	account Policy
+	ip      Policy
+	mu      sync.Mutex
+}
+
+func NewGuard(store Store, account Policy, ip Policy) *Guard {
+	return &Guard{
+		store:   store,
+		account: account,
+		ip:      ip,
+	}
+}
+
+func accountKey(account string) string {
+	return "account:" + account
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Check 返回还需要等待多久才能再次尝试，0 表示可以尝试
+func (g *Guard) Check(account string, ip string) time.Duration {
+	now := time.Now()
+	var wait time.Duration
+	for _, key := range []string{accountKey(account), ipKey(ip)} {
+		if r, ok := g.store.Get(key); ok && now.Before(r.LockedUntil) {
+			if d := r.LockedUntil.Sub(now); d > wait {
+				wait = d
+			}
+		}
+	}
+	return wait
+}
+
+// Fail 记录一次失败
+func (g *Guard) Fail(account string, ip string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.fail(accountKey(account), g.account)
+	g.fail(ipKey(ip), g.ip)
+}
+
+func (g *Guard) fail(key string, policy Policy) {
+	now := time.Now()
+	r, ok := g.store.Get(key)
+	if !ok || now.Sub(r.LastFailedAt) > policy.Window {
+		r = Record{}
+	}
+	r.Failures += 1
+	r.LastFailedAt = now
+	if r.Failures >= policy.LockAfter {
+		r.LockedUntil = now.Add(policy.LockDuration)
+	} else if r.Failures > policy.FreeAttempts {
+		delay := policy.BaseDelay << (r.Failures - policy.FreeAttempts - 1)
+		if delay > policy.MaxDelay || delay <= 0 {
+			delay = policy.MaxDelay
+		}
+		r.LockedUntil = now.Add(delay)
+	}
+	ttl := policy.Window
+	if d := r.LockedUntil.Sub(now); d > ttl {
+		ttl = d
+	}
+	g.store.Set(key, r, ttl)
+}
+
+// Succeed 登录成功后清空帐号的计数，IP 的计数保留
+func (g *Guard) Succeed(account string) {
+	g.store.Delete(accountKey(account))
+}
+
+// Unlock 管理员手动解锁帐号
+func (g *Guard) Unlock(account string) {
+	g.store.Delete(accountKey(account))
+}
+
+// UnlockIP 管理员手动解锁 IP
+func (g *Guard) UnlockIP(ip string) {
+	g.store.Delete(ipKey(ip))
+}
+
+// cacheStore 把 Record 编码成 JSON 存在 cache 里，过期清理由 cache 负责
+type cacheStore struct {
+	cache cache.Cache
+}
+
+// NewCacheStore 基于 cache 保存失败计数，多实例部署时传入共享的 cache 实现
+func NewCacheStore(c cache.Cache) Store {
+	return &cacheStore{cache: c}
+}
+
+// NewMemoryStore 保存在进程内存里，重启后清空
+func NewMemoryStore() Store {
+	return NewCacheStore(cache.NewMemoryCache())
+}
+
+func (s *cacheStore) Get(key string) (Record, bool) {
+	value, ok := s.cache.Get(key)
+	if !ok {
+		return Record{}, false
+	}
+	var record Record
+	if err := json.Unmarshal(value, &record); err != nil {
+		return Record{}, false
+	}
+	return record, true
+}
+
+func (s *cacheStore) Set(key string, record Record, ttl time.Duration) {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	s.cache.Set(key, value, ttl)
+}
+
+func (s *cacheStore) Delete(key string) {
+	s.cache.Delete(key)
+}
@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"myapi/internal/pkg/response"
+)
+
+const namespace = "fithub"
+
+// Registry 只注册这里定义的指标，不用 prometheus 的全局注册表
+var Registry = prometheus.NewRegistry()
+
+var (
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route and status.",
+	}, []string{"method", "route", "status"})
+	duration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route.",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"method", "route"})
+	errorCodes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "api_errors_total",
+		Help:      "Error responses by route and error code in the response envelope.",
+	}, []string{"route", "code"})
+
+	// 业务指标
+	WorkoutsFinished = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "workouts_finished_total",
+		Help:      "Workout days finished.",
+	})
+	GiftCardsRedeemed = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gift_cards_redeemed_total",
+		Help:      "Gift cards redeemed.",
+	})
+	ExamsCompleted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "exams_completed_total",
+		Help:      "Exams completed, by whether the exam was passed.",
+	}, []string{"passed"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requests,
+		duration,
+		errorCodes,
+		WorkoutsFinished,
+		GiftCardsRedeemed,
+		ExamsCompleted,
+	)
+}
+
+// RegisterDB 导出连接池的状态，每个连接池只能注册一次
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler /metrics 接口
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+}
+
+// Middleware 按路由统计请求数、耗时和错误码，route 是注册时的路径，没有匹配的路由统一记为 unmatched
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		status := c.Writer.Status()
+		requests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+		duration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+		if code, ok := c.Get(response.CodeKey); ok {
+			errorCodes.WithLabelValues(route, strconv.Itoa(code.(int))).Inc()
+		} else if status >= http.StatusBadRequest {
+			// 没有经过 response.Fail 的错误，比如 404 路由
+			errorCodes.WithLabelValues(route, strconv.Itoa(status*100)).Inc()
+		}
+	}
+}
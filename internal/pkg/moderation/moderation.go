@@ -0,0 +1,50 @@
+package moderation
+
+import (
+	"strings"
+
+	"myapi/internal/pkg/sensitive"
+)
+
+// Verdict is the result of moderating a piece of user generated text
+type Verdict int
+
+const (
+	// VerdictPass can be shown directly
+	VerdictPass Verdict = iota
+	// VerdictReview needs to be reviewed by an admin before it is shown
+	VerdictReview
+	// VerdictBlock must never be shown
+	VerdictBlock
+)
+
+// Result holds the verdict and a human readable reason
+type Result struct {
+	Verdict Verdict
+	Reason  string
+}
+
+// Moderator is the hook used before user generated text is shown to others
+type Moderator interface {
+	Moderate(text string) Result
+}
+
+// SensitiveWordModerator holds text containing sensitive words for manual review
+type SensitiveWordModerator struct{}
+
+// NewSensitiveWordModerator creates the default moderator
+func NewSensitiveWordModerator() *SensitiveWordModerator {
+	return &SensitiveWordModerator{}
+}
+
+// Moderate implements Moderator
+func (m *SensitiveWordModerator) Moderate(text string) Result {
+	words := sensitive.FindSensitiveWords(text)
+	if len(words) == 0 {
+		return Result{Verdict: VerdictPass}
+	}
+	return Result{
+		Verdict: VerdictReview,
+		Reason:  "包含敏感词：" + strings.Join(words, "、"),
+	}
+}
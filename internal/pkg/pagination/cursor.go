@@ -0,0 +1,72 @@
+package pagination
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FeedCursor is the cursor used when merging several sources into one list.
+// Items are ordered by time DESC, then source ASC, then id DESC.
+type FeedCursor struct {
+	Time   time.Time
+	Source int
+	Id     int
+}
+
+// String encodes the cursor as next_marker
+func (c FeedCursor) String() string {
+	return fmt.Sprintf("%d_%d_%d", c.Time.UnixNano(), c.Source, c.Id)
+}
+
+// ParseFeedCursor decodes a next_marker, an empty marker returns nil
+func ParseFeedCursor(marker string) (*FeedCursor, error) {
+	if marker == "" {
+		return nil, nil
+	}
+	parts := strings.Split(marker, "_")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid marker: %s", marker)
+	}
+	var nums [3]int64
+	for i, p := range parts {
+		n, err := strconv.ParseInt(p, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid marker: %s", marker)
+		}
+		nums[i] = n
+	}
+	return &FeedCursor{
+		Time:   time.Unix(0, nums[0]),
+		Source: int(nums[1]),
+		Id:     int(nums[2]),
+	}, nil
+}
+
+// Apply keeps the records of source that come after the cursor, column is the time field the source is ordered by
+func (c *FeedCursor) Apply(query *gorm.DB, column string, source int) *gorm.DB {
+	if c == nil {
+		return query
+	}
+	if source < c.Source {
+		return query.Where(column+" < ?", c.Time)
+	}
+	if source > c.Source {
+		return query.Where(column+" <= ?", c.Time)
+	}
+	return query.Where(column+" < ? OR ("+column+" = ? AND id < ?)", c.Time, c.Time, c.Id)
+}
+
+// Before reports whether a sorts before b
+func (c FeedCursor) Before(b FeedCursor) bool {
+	if !c.Time.Equal(b.Time) {
+		return c.Time.After(b.Time)
+	}
+	if c.Source != b.Source {
+		return c.Source < b.Source
+	}
+	return c.Id > b.Id
+}
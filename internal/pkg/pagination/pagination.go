@@ -99,3 +99,21 @@ func (pb *PaginationBuilder[T]) ProcessResults(results []T) ([]T, bool, string)
 
 	return results, has_more, next_cursor
 }
+
+// List 分页列表接口返回的数据
+type List[T any] struct {
+	List       []T    `json:"list"`
+	PageSize   int    `json:"page_size"`
+	HasMore    bool   `json:"has_more"`
+	NextMarker string `json:"next_marker"`
+}
+
+// NewList 用 ProcessResults 之后的结果生成返回的数据，list 可以是转换过的类型
+func NewList[T any, R any](pb *PaginationBuilder[R], list []T, has_more bool, next_marker string) List[T] {
+	return List[T]{
+		List:       list,
+		PageSize:   pb.GetLimit(),
+		HasMore:    has_more,
+		NextMarker: next_marker,
+	}
+}
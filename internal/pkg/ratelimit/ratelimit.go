@@ -0,0 +1,142 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// KeyBy 按什么区分请求方
+type KeyBy int
+
+const (
+	// KeyIP 按客户端 IP
+	KeyIP KeyBy = iota
+	// KeyUser 按登录用户，没有登录时按 IP
+	KeyUser
+	// KeyUserAndIP 登录用户和 IP 分别计数，任意一个用完都拒绝
+	KeyUserAndIP
+)
+
+// Policy 令牌桶的规则，每 Per 时间补充 Limit 个令牌，最多攒 Burst 个
+//
+// Name 相同的路由共用一个桶，比如发送验证码的几个接口
+type Policy struct {
+	Name  string
+	Limit int
+	Per   time.Duration
+	Burst int // 为 0 时等于 Limit
+	Key   KeyBy
+}
+
+func (p Policy) capacity() float64 {
+	if p.Burst > 0 {
+		return float64(p.Burst)
+	}
+	return float64(p.Limit)
+}
+
+// rate 每秒补充的令牌数
+func (p Policy) rate() float64 {
+	return float64(p.Limit) / p.Per.Seconds()
+}
+
+// Result 取令牌的结果，用来设置响应头
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// 桶重新装满还要多久
+	Reset time.Duration
+	// 被拒绝时还要等多久才有令牌
+	RetryAfter time.Duration
+}
+
+// Store 保存令牌桶，取令牌要是原子的，目前只有内存实现，多实例部署时换成共享存储
+type Store interface {
+	// Take 从每个 key 的桶里各取一个令牌，任意一个桶不够时都不取，返回最严格的结果
+	Take(keys []string, policy Policy, now time.Time) Result
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	// 这个时间之后桶已经满了，可以清理
+	fullAt time.Time
+}
+
+// MemoryStore 保存在进程内存里，重启后清空
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	cleanedAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// cleanInterval 清理已经装满的桶的间隔，装满的桶和新建的没有区别
+const cleanInterval = time.Minute
+
+func (m *MemoryStore) Take(keys []string, policy Policy, now time.Time) Result {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if now.Sub(m.cleanedAt) > cleanInterval {
+		for k, b := range m.buckets {
+			if !now.Before(b.fullAt) {
+				delete(m.buckets, k)
+			}
+		}
+		m.cleanedAt = now
+	}
+
+	capacity := policy.capacity()
+	rate := policy.rate()
+	buckets := make([]*bucket, 0, len(keys))
+	allowed := true
+	for _, key := range keys {
+		b, ok := m.buckets[key]
+		if !ok {
+			b = &bucket{tokens: capacity, updatedAt: now}
+			m.buckets[key] = b
+		}
+		if elapsed := now.Sub(b.updatedAt).Seconds(); elapsed > 0 {
+			b.tokens = math.Min(capacity, b.tokens+elapsed*rate)
+			b.updatedAt = now
+		}
+		allowed = allowed && b.tokens >= 1
+		buckets = append(buckets, b)
+	}
+	// 被拒绝的请求不消耗令牌，不然一个桶用完后还会一直扣另一个桶
+	var result Result
+	for i, b := range buckets {
+		r := Result{Limit: int(capacity), Allowed: allowed}
+		if allowed {
+			b.tokens -= 1
+		} else if b.tokens < 1 {
+			r.RetryAfter = seconds((1 - b.tokens) / rate)
+		}
+		r.Remaining = int(b.tokens)
+		r.Reset = seconds((capacity - b.tokens) / rate)
+		b.fullAt = now.Add(r.Reset)
+		if i == 0 || stricter(r, result) {
+			result = r
+		}
+	}
+	return result
+}
+
+// stricter a 是否比 b 更严格：都拒绝时等得久的优先，都通过时剩余少的优先
+func stricter(a, b Result) bool {
+	if !a.Allowed {
+		return a.RetryAfter > b.RetryAfter
+	}
+	return a.Remaining < b.Remaining
+}
+
+func seconds(v float64) time.Duration {
+	return time.Duration(v * float64(time.Second))
+}
@@ -0,0 +1,45 @@
+package response
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"myapi/internal/pkg/errcode"
+)
+
+// SuccessCode 成功时 envelope 里的 code
+const SuccessCode = 200
+
+// CodeKey 错误响应的 code 记在 gin.Context 里，用于统计
+const CodeKey = "response_code"
+
+// OK 成功响应
+func OK(c *gin.Context, data interface{}) {
+	c.JSON(http.StatusOK, gin.H{"code": SuccessCode, "msg": "", "data": data})
+}
+
+// Fail 错误响应，HTTP 状态码和 code 都来自错误码，文案按 Accept-Language 选择语言
+//
+// 非 *errcode.Error 的错误统一按 errcode.From 转换，原始错误记到 c.Errors 里，由 RecoveryMiddleware 写日志
+func Fail(c *gin.Context, err error) {
+	e := errcode.From(err)
+	if e.Err != nil {
+		c.Error(e)
+	}
+	FailWithData(c, e, nil)
+}
+
+// FailWithData 错误响应，同时返回一些数据方便客户端处理，比如重复记录的 id
+func FailWithData(c *gin.Context, err error, data interface{}) {
+	e := errcode.From(err)
+	lang := errcode.Lang(c.GetHeader("Accept-Language"))
+	c.Set(CodeKey, e.Code)
+	c.JSON(e.Status, gin.H{"code": e.Code, "msg": e.Message(lang), "data": data})
+}
+
+// Abort 错误响应并中断后续的处理，给中间件用
+func Abort(c *gin.Context, err error) {
+	Fail(c, err)
+	c.Abort()
+}
@@ -49,3 +49,51 @@ func ContainsSensitiveWord(text string) bool {
 	}
 	return false
 }
+
+// FindSensitiveWords returns the sensitive words contained in the given text.
+// English words only match on word boundaries, so "class" does not hit "ass".
+func FindSensitiveWords(text string) []string {
+	text = strings.ToLower(text)
+	var matched []string
+	for _, word := range SensitiveWords {
+		w := strings.ToLower(word)
+		if isASCII(w) {
+			if containsWord(text, w) {
+				matched = append(matched, word)
+			}
+			continue
+		}
+		if strings.Contains(text, w) {
+			matched = append(matched, word)
+		}
+	}
+	return matched
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= '0' && b <= '9' || b == '_'
+}
+
+func containsWord(text, word string) bool {
+	for start := 0; ; {
+		idx := strings.Index(text[start:], word)
+		if idx < 0 {
+			return false
+		}
+		idx += start
+		end := idx + len(word)
+		if (idx == 0 || !isWordByte(text[idx-1])) && (end == len(text) || !isWordByte(text[end])) {
+			return true
+		}
+		start = idx + 1
+	}
+}
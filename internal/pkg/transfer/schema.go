@@ -0,0 +1,430 @@
+package transfer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+// 列的数据类型，只区分需要各数据库分别处理的几类
+type kind int
+
+const (
+	kindText kind = iota
+	kindInteger
+	kindReal
+	kindBoolean
+	kindDatetime
+	kindBlob
+)
+
+type column struct {
+	Name    string
+	Kind    kind
+	NotNull bool
+	// 原样的默认值，建表时按目标数据库转换，不认识的会丢掉
+	Default       string
+	AutoIncrement bool
+}
+
+type index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
+type table struct {
+	Name       string
+	Columns    []column
+	PrimaryKey []string
+	Indexes    []index
+	// 外键引用的表，需要先复制
+	DependsOn []string
+}
+
+func (t *table) column(name string) *column {
+	for i := range t.Columns {
+		if t.Columns[i].Name == name {
+			return &t.Columns[i]
+		}
+	}
+	return nil
+}
+
+// autoIncrement 自增主键的列名，没有时返回空字符串
+func (t *table) autoIncrement() string {
+	for _, c := range t.Columns {
+		if c.AutoIncrement {
+			return c.Name
+		}
+	}
+	return ""
+}
+
+// readSchema 读出源数据库所有表的结构
+func readSchema(db *gorm.DB) ([]*table, error) {
+	if db.Dialector.Name() == "sqlite" {
+		return readSQLiteSchema(db)
+	}
+	return readSchemaByMigrator(db)
+}
+
+// SQLite 的类型只是建议，INTEGER 列里可能存了文本，按实际数据确定类型
+func readSQLiteSchema(db *gorm.DB) ([]*table, error) {
+	var names []string
+	if err := db.Raw("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name").Scan(&names).Error; err != nil {
+		return nil, err
+	}
+	var tables []*table
+	for _, name := range names {
+		t := &table{Name: name}
+		var infos []struct {
+			Name      string
+			Type      string
+			NotNull   bool
+			DfltValue *string
+			Pk        int
+		}
+		if err := db.Raw("SELECT name, type, \"notnull\" AS not_null, dflt_value, pk FROM pragma_table_info(?) ORDER BY cid", name).Scan(&infos).Error; err != nil {
+			return nil, err
+		}
+		pk_count := 0
+		for _, info := range infos {
+			if info.Pk > 0 {
+				pk_count++
+			}
+		}
+		pks := make([]string, pk_count)
+		for _, info := range infos {
+			c := column{Name: info.Name, Kind: kindOf(info.Type), NotNull: info.NotNull}
+			if info.DfltValue != nil {
+				c.Default = *info.DfltValue
+			}
+			if info.Pk > 0 {
+				pks[info.Pk-1] = info.Name
+				// INTEGER PRIMARY KEY 是 rowid 的别名，会自动递增
+				c.AutoIncrement = pk_count == 1 && strings.EqualFold(info.Type, "INTEGER")
+			}
+			kind, err := sqliteActualKind(db, name, c)
+			if err != nil {
+				return nil, err
+			}
+			c.Kind = kind
+			t.Columns = append(t.Columns, c)
+		}
+		t.PrimaryKey = pks
+
+		var index_list []struct {
+			Name   string
+			Unique bool
+			Origin string
+		}
+		if err := db.Raw("SELECT name, \"unique\" AS \"unique\", origin FROM pragma_index_list(?) ORDER BY name", name).Scan(&index_list).Error; err != nil {
+			return nil, err
+		}
+		for _, idx := range index_list {
+			if idx.Origin == "pk" {
+				continue
+			}
+			var cols []string
+			if err := db.Raw("SELECT name FROM pragma_index_info(?) ORDER BY seqno", idx.Name).Scan(&cols).Error; err != nil {
+				return nil, err
+			}
+			// 表达式索引没有列名，复制不了
+			if len(cols) == 0 || lo.Contains(cols, "") {
+				continue
+			}
+			t.Indexes = append(t.Indexes, index{Name: idx.Name, Columns: cols, Unique: idx.Unique})
+		}
+
+		if err := db.Raw("SELECT DISTINCT \"table\" FROM pragma_foreign_key_list(?)", name).Scan(&t.DependsOn).Error; err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+	return tables, nil
+}
+
+// 声明的类型和实际存的数据对不上时按文本处理
+func sqliteActualKind(db *gorm.DB, table_name string, c column) (kind, error) {
+	var cond string
+	switch c.Kind {
+	case kindInteger, kindBoolean:
+		cond = "typeof(%s) != 'integer'"
+	case kindReal:
+		cond = "typeof(%s) NOT IN ('integer', 'real')"
+	case kindDatetime:
+		cond = "(typeof(%[1]s) NOT IN ('text', 'integer', 'real') OR datetime(%[1]s) IS NULL)"
+	default:
+		return c.Kind, nil
+	}
+	quoted := db.Statement.Quote(c.Name)
+	var count int64
+	err := db.Table(table_name).Where(quoted + " IS NOT NULL AND " + fmt.Sprintf(cond, quoted)).Count(&count).Error
+	if err != nil {
+		return c.Kind, err
+	}
+	if count > 0 {
+		return kindText, nil
+	}
+	return c.Kind, nil
+}
+
+// Postgres、MySQL 用 gorm 读出表结构
+func readSchemaByMigrator(db *gorm.DB) ([]*table, error) {
+	m := db.Migrator()
+	names, err := m.GetTables()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	var tables []*table
+	for _, name := range names {
+		t := &table{Name: name}
+		types, err := m.ColumnTypes(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ct := range types {
+			c := column{Name: ct.Name(), Kind: kindOf(ct.DatabaseTypeName())}
+			if nullable, ok := ct.Nullable(); ok {
+				c.NotNull = !nullable
+			}
+			if pk, ok := ct.PrimaryKey(); ok && pk {
+				t.PrimaryKey = append(t.PrimaryKey, c.Name)
+			}
+			if auto, ok := ct.AutoIncrement(); ok {
+				c.AutoIncrement = auto
+			}
+			if value, ok := ct.DefaultValue(); ok && !c.AutoIncrement {
+				c.Default = value
+			}
+			t.Columns = append(t.Columns, c)
+		}
+		indexes, err := m.GetIndexes(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, idx := range indexes {
+			if pk, ok := idx.PrimaryKey(); ok && pk {
+				continue
+			}
+			unique, _ := idx.Unique()
+			t.Indexes = append(t.Indexes, index{Name: idx.Name(), Columns: idx.Columns(), Unique: unique})
+		}
+		var refs string
+		switch db.Dialector.Name() {
+		case "postgres":
+			refs = `SELECT DISTINCT ccu.table_name FROM information_schema.table_constraints tc
+				JOIN information_schema.constraint_column_usage ccu ON tc.constraint_name = ccu.constraint_name AND tc.table_schema = ccu.table_schema
+				WHERE tc.constraint_type = 'FOREIGN KEY' AND tc.table_schema = CURRENT_SCHEMA() AND tc.table_name = ?`
+		case "mysql":
+			refs = `SELECT DISTINCT REFERENCED_TABLE_NAME FROM information_schema.KEY_COLUMN_USAGE
+				WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND REFERENCED_TABLE_NAME IS NOT NULL`
+		}
+		if refs != "" {
+			if err := db.Raw(refs, name).Scan(&t.DependsOn).Error; err != nil {
+				return nil, err
+			}
+		}
+		tables = append(tables, t)
+	}
+	return tables, nil
+}
+
+// kindOf 按 SQLite 的类型亲和规则，同时兼容 Postgres、MySQL 的类型名
+func kindOf(type_name string) kind {
+	t := strings.ToUpper(type_name)
+	switch {
+	case strings.Contains(t, "BOOL"), t == "TINYINT(1)":
+		return kindBoolean
+	case strings.Contains(t, "INT"), strings.Contains(t, "SERIAL"):
+		return kindInteger
+	case strings.Contains(t, "CHAR"), strings.Contains(t, "CLOB"), strings.Contains(t, "TEXT"), strings.Contains(t, "JSON"):
+		return kindText
+	case strings.Contains(t, "BLOB"), strings.Contains(t, "BYTEA"), strings.Contains(t, "BINARY"):
+		return kindBlob
+	case strings.Contains(t, "REAL"), strings.Contains(t, "FLOA"), strings.Contains(t, "DOUB"), strings.Contains(t, "NUMERIC"), strings.Contains(t, "DECIMAL"):
+		return kindReal
+	case strings.Contains(t, "DATE"), strings.Contains(t, "TIME"):
+		return kindDatetime
+	default:
+		return kindText
+	}
+}
+
+// sortByDependency 按外键依赖排序，被引用的表在前，其余按表名排序
+func sortByDependency(tables []*table) ([]*table, error) {
+	by_name := map[string]*table{}
+	for _, t := range tables {
+		by_name[t.Name] = t
+	}
+	var sorted []*table
+	state := map[string]int{} // 1 处理中 2 已完成
+	var visit func(t *table) error
+	visit = func(t *table) error {
+		switch state[t.Name] {
+		case 1:
+			return fmt.Errorf("circular foreign keys at table %s", t.Name)
+		case 2:
+			return nil
+		}
+		state[t.Name] = 1
+		for _, dep := range t.DependsOn {
+			if d, ok := by_name[dep]; ok && dep != t.Name {
+				if err := visit(d); err != nil {
+					return err
+				}
+			}
+		}
+		state[t.Name] = 2
+		sorted = append(sorted, t)
+		return nil
+	}
+	for _, t := range tables {
+		if err := visit(t); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+// createTable 在目标数据库按源表的结构建表和索引
+func createTable(db *gorm.DB, t *table) error {
+	dialect := db.Dialector.Name()
+	indexed := map[string]bool{}
+	for _, name := range t.PrimaryKey {
+		indexed[name] = true
+	}
+	for _, idx := range t.Indexes {
+		for _, name := range idx.Columns {
+			indexed[name] = true
+		}
+	}
+	var defs []string
+	for _, c := range t.Columns {
+		def := db.Statement.Quote(c.Name) + " "
+		if c.AutoIncrement {
+			switch dialect {
+			case "postgres":
+				def += "BIGSERIAL PRIMARY KEY"
+			case "mysql":
+				def += "BIGINT AUTO_INCREMENT PRIMARY KEY"
+			default:
+				def += "INTEGER PRIMARY KEY AUTOINCREMENT"
+			}
+			defs = append(defs, def)
+			continue
+		}
+		def += columnType(dialect, c.Kind, indexed[c.Name])
+		if c.NotNull {
+			def += " NOT NULL"
+		}
+		if value := defaultValue(dialect, c); value != "" {
+			def += " DEFAULT " + value
+		}
+		defs = append(defs, def)
+	}
+	if t.autoIncrement() == "" && len(t.PrimaryKey) > 0 {
+		var cols []string
+		for _, name := range t.PrimaryKey {
+			cols = append(cols, db.Statement.Quote(name))
+		}
+		defs = append(defs, "PRIMARY KEY ("+strings.Join(cols, ", ")+")")
+	}
+	if err := db.Exec("CREATE TABLE " + db.Statement.Quote(t.Name) + " (\n  " + strings.Join(defs, ",\n  ") + "\n)").Error; err != nil {
+		return fmt.Errorf("failed to create table %s: %w", t.Name, err)
+	}
+	for _, idx := range t.Indexes {
+		name := idx.Name
+		// SQLite 自动生成的索引名在其他数据库里没有意义，Postgres 的索引名还要全库唯一
+		if strings.HasPrefix(name, "sqlite_autoindex_") || dialect == "postgres" {
+			name = "idx_" + strings.ToLower(t.Name) + "_" + strings.Join(idx.Columns, "_")
+		}
+		var cols []string
+		for _, col := range idx.Columns {
+			cols = append(cols, db.Statement.Quote(col))
+		}
+		sql := "CREATE INDEX "
+		if idx.Unique {
+			sql = "CREATE UNIQUE INDEX "
+		}
+		sql += db.Statement.Quote(name) + " ON " + db.Statement.Quote(t.Name) + " (" + strings.Join(cols, ", ") + ")"
+		if err := db.Exec(sql).Error; err != nil {
+			return fmt.Errorf("failed to create index %s on %s: %w", name, t.Name, err)
+		}
+	}
+	return nil
+}
+
+func columnType(dialect string, k kind, indexed bool) string {
+	switch dialect {
+	case "postgres":
+		return map[kind]string{kindText: "TEXT", kindInteger: "BIGINT", kindReal: "DOUBLE PRECISION", kindBoolean: "BOOLEAN", kindDatetime: "TIMESTAMPTZ", kindBlob: "BYTEA"}[k]
+	case "mysql":
+		// MySQL 的 TEXT 不能直接建索引
+		if k == kindText && indexed {
+			return "VARCHAR(191)"
+		}
+		return map[kind]string{kindText: "LONGTEXT", kindInteger: "BIGINT", kindReal: "DOUBLE", kindBoolean: "BOOLEAN", kindDatetime: "DATETIME(6)", kindBlob: "LONGBLOB"}[k]
+	default:
+		return map[kind]string{kindText: "TEXT", kindInteger: "INTEGER", kindReal: "REAL", kindBoolean: "BOOLEAN", kindDatetime: "DATETIME", kindBlob: "BLOB"}[k]
+	}
+}
+
+var (
+	numberRegexp = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+	stringRegexp = regexp.MustCompile(`^'(?:[^']|'')*'$`)
+	// Postgres 的默认值会带上类型转换，比如 'abc'::text
+	castRegexp = regexp.MustCompile(`::[a-z ]+(\[\])?$`)
+)
+
+// defaultValue 把默认值转换成目标数据库的写法，转换不了的返回空字符串
+func defaultValue(dialect string, c column) string {
+	value := strings.TrimSpace(castRegexp.ReplaceAllString(c.Default, ""))
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "("), ")")
+	upper := strings.ToUpper(value)
+	switch {
+	case value == "" || upper == "NULL":
+		return ""
+	case upper == "CURRENT_TIMESTAMP" || upper == "NOW()" || strings.HasPrefix(upper, "CURRENT_TIMESTAMP("):
+		if c.Kind != kindDatetime {
+			return ""
+		}
+		if dialect == "mysql" {
+			return "CURRENT_TIMESTAMP(6)"
+		}
+		return "CURRENT_TIMESTAMP"
+	case c.Kind == kindBoolean && dialect == "postgres":
+		switch upper {
+		case "1", "TRUE", "'1'":
+			return "TRUE"
+		case "0", "FALSE", "'0'":
+			return "FALSE"
+		}
+		return ""
+	case numberRegexp.MatchString(value):
+		if c.Kind == kindText {
+			value = "'" + value + "'"
+		}
+	case stringRegexp.MatchString(value):
+		if c.Kind == kindInteger || c.Kind == kindReal || c.Kind == kindBoolean {
+			inner := strings.Trim(value, "'")
+			if !numberRegexp.MatchString(inner) {
+				return ""
+			}
+			value = inner
+		}
+	default:
+		return ""
+	}
+	// MySQL 的 TEXT 列只能用表达式作为默认值
+	if dialect == "mysql" && (c.Kind == kindText || c.Kind == kindBlob) {
+		return "(" + value + ")"
+	}
+	return value
+}
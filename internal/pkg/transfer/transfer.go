@@ -0,0 +1,360 @@
+// Package transfer 把一个数据库的所有表复制到另一个数据库，比如从 SQLite 迁到 Postgres、MySQL
+//
+// 目标库里没有的表按源表的结构新建；表按外键依赖的顺序、按主键分批复制，复制完重置自增序列，
+// 最后比较两边每张表的行数和校验和
+package transfer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"myapi/pkg/logger"
+)
+
+// Options 复制配置
+type Options struct {
+	// 每批复制的行数
+	ChunkSize int
+	// 目标表已经有数据时先清空，否则报错
+	Truncate bool
+	Logger   *logger.Logger
+}
+
+// Result 一张表的复制结果
+type Result struct {
+	Table    string
+	Rows     int64
+	Checksum string
+}
+
+// Transfer 把 src 的所有表复制到 dst，并校验两边的数据一致
+func Transfer(ctx context.Context, src, dst *gorm.DB, opts Options) ([]Result, error) {
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = 500
+	}
+	// 每一批的 SQL 都记日志太多了，只记录出错和慢查询
+	src = src.Session(&gorm.Session{Context: ctx, Logger: src.Logger.LogMode(gormlogger.Warn)})
+	dst = dst.Session(&gorm.Session{Context: ctx, Logger: dst.Logger.LogMode(gormlogger.Warn)})
+
+	tables, err := readSchema(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source schema: %w", err)
+	}
+	tables, err = sortByDependency(tables)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tables {
+		if err := prepareTable(dst, t, opts.Truncate); err != nil {
+			return nil, err
+		}
+	}
+	for _, t := range tables {
+		rows, err := copyTable(src, dst, t, opts.ChunkSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy table %s: %w", t.Name, err)
+		}
+		if err := resetSequence(dst, t); err != nil {
+			return nil, fmt.Errorf("failed to reset sequence of %s: %w", t.Name, err)
+		}
+		if opts.Logger != nil {
+			opts.Logger.Infow("Table copied", "table", t.Name, "rows", rows)
+		}
+	}
+	return Verify(src, dst, tables)
+}
+
+// prepareTable 目标表不存在时新建，存在时必须是空的
+func prepareTable(dst *gorm.DB, t *table, truncate bool) error {
+	if !dst.Migrator().HasTable(t.Name) {
+		return createTable(dst, t)
+	}
+	var count int64
+	if err := dst.Table(t.Name).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+	if !truncate {
+		return fmt.Errorf("table %s in the target database already has %d row(s), use truncate to replace them", t.Name, count)
+	}
+	return dst.Exec("DELETE FROM " + dst.Statement.Quote(t.Name)).Error
+}
+
+// copyTable 按主键分批读出再写入，每张表在一个事务里写入
+func copyTable(src, dst *gorm.DB, t *table, chunk_size int) (int64, error) {
+	var names []string
+	for _, c := range t.Columns {
+		names = append(names, c.Name)
+	}
+	var copied int64
+	err := dst.Transaction(func(tx *gorm.DB) error {
+		return eachChunk(src, t, chunk_size, func(rows [][]interface{}) error {
+			values := make([]string, 0, len(rows))
+			args := make([]interface{}, 0, len(rows)*len(names))
+			placeholder := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ") + ")"
+			for _, row := range rows {
+				values = append(values, placeholder)
+				for i, v := range row {
+					args = append(args, convert(v, t.Columns[i].Kind, tx.Dialector.Name()))
+				}
+			}
+			var cols []string
+			for _, name := range names {
+				cols = append(cols, tx.Statement.Quote(name))
+			}
+			sql := "INSERT INTO " + tx.Statement.Quote(t.Name) + " (" + strings.Join(cols, ", ") + ") VALUES " + strings.Join(values, ", ")
+			if err := tx.Exec(sql, args...).Error; err != nil {
+				return err
+			}
+			copied += int64(len(rows))
+			return nil
+		})
+	})
+	return copied, err
+}
+
+// eachChunk 分批读出源表的所有行，有单列主键时按主键翻页，否则按偏移量翻页
+func eachChunk(db *gorm.DB, t *table, chunk_size int, fn func(rows [][]interface{}) error) error {
+	var cols []string
+	for _, c := range t.Columns {
+		cols = append(cols, db.Statement.Quote(c.Name))
+	}
+	base := "SELECT " + strings.Join(cols, ", ") + " FROM " + db.Statement.Quote(t.Name)
+	keyset := len(t.PrimaryKey) == 1
+	key_idx := 0
+	order := strings.Join(cols, ", ")
+	if keyset {
+		for i, c := range t.Columns {
+			if c.Name == t.PrimaryKey[0] {
+				key_idx = i
+			}
+		}
+		order = db.Statement.Quote(t.PrimaryKey[0])
+	}
+
+	var last interface{}
+	offset := 0
+	for {
+		sql := base
+		args := []interface{}{}
+		if keyset && last != nil {
+			sql += " WHERE " + order + " > ?"
+			args = append(args, last)
+		}
+		sql += " ORDER BY " + order + " LIMIT " + strconv.Itoa(chunk_size)
+		if !keyset {
+			sql += " OFFSET " + strconv.Itoa(offset)
+		}
+		rows, err := readRows(db, sql, args, len(cols))
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		if err := fn(rows); err != nil {
+			return err
+		}
+		if len(rows) < chunk_size {
+			return nil
+		}
+		last = rows[len(rows)-1][key_idx]
+		offset += len(rows)
+	}
+}
+
+func readRows(db *gorm.DB, sql string, args []interface{}, width int) ([][]interface{}, error) {
+	rows, err := db.Raw(sql, args...).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result [][]interface{}
+	for rows.Next() {
+		row := make([]interface{}, width)
+		ptrs := make([]interface{}, width)
+		for i := range row {
+			ptrs[i] = &row[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// 时间精确到微秒，Postgres 和 MySQL DATETIME(6) 都只保存到微秒
+const timePrecision = time.Microsecond
+
+// 各数据库的驱动读出来的类型不一样，转换成目标列能接受的类型
+func convert(v interface{}, k kind, dialect string) interface{} {
+	if v == nil {
+		return nil
+	}
+	if b, ok := v.([]byte); ok && k != kindBlob {
+		v = string(b)
+	}
+	switch k {
+	case kindText:
+		if s, ok := v.(string); ok {
+			return s
+		}
+		if t, ok := v.(time.Time); ok {
+			return t.UTC().Truncate(timePrecision).Format(time.RFC3339Nano)
+		}
+		return fmt.Sprint(v)
+	case kindBoolean:
+		switch x := v.(type) {
+		case int64:
+			if dialect == "postgres" {
+				return x != 0
+			}
+		case bool:
+			if dialect != "postgres" {
+				if x {
+					return 1
+				}
+				return 0
+			}
+		}
+	case kindDatetime:
+		if t, ok := parseTime(v); ok {
+			return t
+		}
+	}
+	return v
+}
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseTime 统一成 UTC 并截断到微秒，SQLite 里没被驱动解析的文本按 UTC 解析
+func parseTime(v interface{}) (time.Time, bool) {
+	switch x := v.(type) {
+	case time.Time:
+		return x.UTC().Truncate(timePrecision), true
+	case string:
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, x); err == nil {
+				return t.UTC().Truncate(timePrecision), true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// resetSequence 把自增序列设到最大的主键之后，否则目标库新插入的行会主键冲突
+func resetSequence(db *gorm.DB, t *table) error {
+	col := t.autoIncrement()
+	if col == "" {
+		return nil
+	}
+	var max int64
+	if err := db.Table(t.Name).Select("COALESCE(MAX(" + db.Statement.Quote(col) + "), 0)").Scan(&max).Error; err != nil {
+		return err
+	}
+	switch db.Dialector.Name() {
+	case "postgres":
+		return db.Exec("SELECT setval(pg_get_serial_sequence(?, ?), ?, false)", db.Statement.Quote(t.Name), col, max+1).Error
+	case "mysql":
+		return db.Exec("ALTER TABLE " + db.Statement.Quote(t.Name) + " AUTO_INCREMENT = " + strconv.FormatInt(max+1, 10)).Error
+	default:
+		// SQLite 插入指定的主键时会自动更新 sqlite_sequence
+		return nil
+	}
+}
+
+// Verify 比较两边每张表的行数和校验和，校验和和行的顺序无关
+func Verify(src, dst *gorm.DB, tables []*table) ([]Result, error) {
+	var results []Result
+	var mismatched []string
+	for _, t := range tables {
+		src_rows, src_sum, err := checksum(src, t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to checksum source table %s: %w", t.Name, err)
+		}
+		dst_rows, dst_sum, err := checksum(dst, t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to checksum target table %s: %w", t.Name, err)
+		}
+		if src_rows != dst_rows || src_sum != dst_sum {
+			mismatched = append(mismatched, fmt.Sprintf("%s (rows %d/%d, checksum %s/%s)", t.Name, src_rows, dst_rows, src_sum, dst_sum))
+		}
+		results = append(results, Result{Table: t.Name, Rows: src_rows, Checksum: src_sum})
+	}
+	if len(mismatched) > 0 {
+		return results, fmt.Errorf("data mismatch after transfer: %s", strings.Join(mismatched, ", "))
+	}
+	return results, nil
+}
+
+// checksum 每行按列算出哈希再相加，值先统一成和数据库无关的字符串
+func checksum(db *gorm.DB, t *table) (int64, string, error) {
+	var count int64
+	var sum uint64
+	err := eachChunk(db, t, 1000, func(rows [][]interface{}) error {
+		for _, row := range rows {
+			h := sha256.New()
+			for i, v := range row {
+				h.Write([]byte(normalize(v, t.Columns[i].Kind)))
+				h.Write([]byte{0x1f})
+			}
+			sum += binary.BigEndian.Uint64(h.Sum(nil))
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, "", err
+	}
+	return count, fmt.Sprintf("%016x", sum), nil
+}
+
+func normalize(v interface{}, k kind) string {
+	if v == nil {
+		return "\x00"
+	}
+	if k == kindDatetime {
+		if t, ok := parseTime(v); ok {
+			return t.Format(time.RFC3339Nano)
+		}
+	}
+	switch x := v.(type) {
+	case []byte:
+		return string(x)
+	case string:
+		return x
+	case bool:
+		if x {
+			return "1"
+		}
+		return "0"
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(x), 'g', -1, 32)
+	case time.Time:
+		return x.UTC().Truncate(timePrecision).Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(x)
+	}
+}
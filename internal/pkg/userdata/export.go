@@ -0,0 +1,379 @@
+package userdata
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"myapi/internal/models"
+)
+
+// 导出的帐号信息，不包含密码哈希等凭证
+type exportAccount struct {
+	ProviderType int        `json:"provider_type"`
+	ProviderId   string     `json:"provider_id"`
+	VerifiedAt   *time.Time `json:"verified_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type exportCoach struct {
+	Id           int                  `json:"id"`
+	Nickname     string               `json:"nickname"`
+	Bio          string               `json:"bio"`
+	CoachType    int                  `json:"coach_type"`
+	Config       string               `json:"config"`
+	WorkoutStats string               `json:"workout_stats"`
+	CreatedAt    time.Time            `json:"created_at"`
+	Profile1     models.CoachProfile1 `json:"profile1"`
+	Profile2     models.CoachProfile2 `json:"profile2"`
+	Accounts     []exportAccount      `json:"accounts"`
+	ExportedAt   time.Time            `json:"exported_at"`
+}
+
+// 训练记录，进度和修改记录解析成最新版本的结构，解析失败时保留原始内容
+type exportWorkoutDay struct {
+	Id                int         `json:"id"`
+	Title             string      `json:"title"`
+	Type              string      `json:"type"`
+	Time              *time.Time  `json:"time"`
+	Status            int         `json:"status"`
+	Remark            string      `json:"remark"`
+	Medias            string      `json:"medias"`
+	Stats             string      `json:"stats"`
+	EstimatedDuration int         `json:"estimated_duration"`
+	Duration          int         `json:"duration"`
+	TotalVolume       float64     `json:"total_volume"`
+	WorkoutPlanId     int         `json:"workout_plan_id"`
+	CoachId           int         `json:"coach_id"`
+	Progress          interface{} `json:"progress"`
+	Details           interface{} `json:"details"`
+	CreatedAt         time.Time   `json:"created_at"`
+	StartedAt         *time.Time  `json:"started_at"`
+	FinishedAt        *time.Time  `json:"finished_at"`
+}
+
+type exportActionHistory struct {
+	Id           int       `json:"id"`
+	WorkoutDayId int       `json:"workout_day_id"`
+	ActionId     int       `json:"action_id"`
+	ActionName   string    `json:"action_name"`
+	Reps         int       `json:"reps"`
+	RepsUnit     string    `json:"reps_unit"`
+	Weight       float64   `json:"weight"`
+	WeightUnit   string    `json:"weight_unit"`
+	Remark       string    `json:"remark"`
+	ExtraMedias  string    `json:"extra_medias"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type exportExam struct {
+	Id          int        `json:"id"`
+	PaperId     int        `json:"paper_id"`
+	PaperName   string     `json:"paper_name"`
+	Status      int        `json:"status"`
+	Score       int        `json:"score"`
+	CorrectRate int        `json:"correct_rate"`
+	Pass        int        `json:"pass"`
+	StartedAt   *time.Time `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type exportSubscription struct {
+	Id                 int        `json:"id"`
+	SubscriptionPlanId int        `json:"subscription_plan_id"`
+	PlanName           string     `json:"plan_name"`
+	Step               int        `json:"step"`
+	Count              int        `json:"count"`
+	Reason             string     `json:"reason"`
+	ActiveAt           *time.Time `json:"active_at"`
+	ExpectExpiredAt    *time.Time `json:"expect_expired_at"`
+	ExpiredAt          *time.Time `json:"expired_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// Export 把用户的个人数据打包成 zip 写到 w，每类数据各有一份 json 和 csv
+func Export(db *gorm.DB, coach_id int, w io.Writer) error {
+	var coach models.Coach
+	if err := db.Where("id = ?", coach_id).Preload("Profile1").Preload("Profile2").First(&coach).Error; err != nil {
+		return err
+	}
+	var accounts []models.CoachAccount
+	if err := db.Where("coach_id = ?", coach_id).Order("provider_type ASC").Find(&accounts).Error; err != nil {
+		return err
+	}
+	var days []models.WorkoutDay
+	if err := db.Where("student_id = ?", coach_id).Order("created_at ASC").Find(&days).Error; err != nil {
+		return err
+	}
+	var histories []models.WorkoutActionHistory
+	if err := db.Where("student_id = ? AND d = 0", coach_id).Preload("WorkoutAction").Order("created_at ASC").Find(&histories).Error; err != nil {
+		return err
+	}
+	var exams []models.Exam
+	if err := db.Where("student_id = ?", coach_id).Preload("Paper").Order("created_at ASC").Find(&exams).Error; err != nil {
+		return err
+	}
+	var subscriptions []models.Subscription
+	if err := db.Where("coach_id = ?", coach_id).Preload("SubscriptionPlan").Order("created_at ASC").Find(&subscriptions).Error; err != nil {
+		return err
+	}
+	var medias []models.MediaResource
+	if err := db.Where("creator_id = ?", coach_id).Order("created_at ASC").Find(&medias).Error; err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+
+	profile := exportCoach{
+		Id:           coach.Id,
+		Nickname:     coach.Nickname,
+		Bio:          coach.Bio,
+		CoachType:    coach.CoachType,
+		Config:       coach.Config,
+		WorkoutStats: coach.WorkoutStats,
+		CreatedAt:    coach.CreatedAt,
+		Profile1:     coach.Profile1,
+		Profile2:     coach.Profile2,
+		Accounts:     []exportAccount{},
+		ExportedAt:   time.Now(),
+	}
+	for _, a := range accounts {
+		profile.Accounts = append(profile.Accounts, exportAccount{
+			ProviderType: a.ProviderType,
+			ProviderId:   a.ProviderId,
+			VerifiedAt:   a.VerifiedAt,
+			CreatedAt:    a.CreatedAt,
+		})
+	}
+	if err := writeJSON(zw, "coach.json", profile); err != nil {
+		return err
+	}
+
+	day_records := []exportWorkoutDay{}
+	day_rows := [][]string{}
+	for _, d := range days {
+		record := exportWorkoutDay{
+			Id:                d.Id,
+			Title:             d.Title,
+			Type:              d.Type,
+			Time:              d.Time,
+			Status:            d.Status,
+			Remark:            d.Remark,
+			Medias:            d.Medias,
+			Stats:             d.Stats,
+			EstimatedDuration: d.EstimatedDuration,
+			Duration:          d.Duration,
+			TotalVolume:       d.TotalVolume,
+			WorkoutPlanId:     d.WorkoutPlanId,
+			CoachId:           d.CoachId,
+			Progress:          d.PendingSteps,
+			Details:           d.UpdatedDetails,
+			CreatedAt:         d.CreatedAt,
+			StartedAt:         d.StartedAt,
+			FinishedAt:        d.FinishedAt,
+		}
+		if progress, err := models.ParseWorkoutDayProgress(d.PendingSteps); err == nil {
+			record.Progress = models.ToWorkoutDayStepProgress(progress)
+		}
+		if details, err := models.ParseWorkoutDayUpdatedDetails(d.UpdatedDetails); err == nil {
+			record.Details = models.ToWorkoutDayStepDetails(details)
+		}
+		day_records = append(day_records, record)
+		day_rows = append(day_rows, []string{
+			strconv.Itoa(d.Id),
+			d.Title,
+			d.Type,
+			formatTime(d.Time),
+			strconv.Itoa(d.Status),
+			strconv.Itoa(d.Duration),
+			strconv.FormatFloat(d.TotalVolume, 'f', -1, 64),
+			d.Remark,
+			formatTime(&d.CreatedAt),
+			formatTime(d.FinishedAt),
+		})
+	}
+	if err := writeJSON(zw, "workout_days.json", day_records); err != nil {
+		return err
+	}
+	if err := writeCSV(zw, "workout_days.csv", []string{"id", "title", "type", "time", "status", "duration", "total_volume", "remark", "created_at", "finished_at"}, day_rows); err != nil {
+		return err
+	}
+
+	history_records := []exportActionHistory{}
+	history_rows := [][]string{}
+	for _, h := range histories {
+		name := h.WorkoutAction.ZhName
+		history_records = append(history_records, exportActionHistory{
+			Id:           h.Id,
+			WorkoutDayId: h.WorkoutDayId,
+			ActionId:     h.WorkoutActionId,
+			ActionName:   name,
+			Reps:         h.Reps,
+			RepsUnit:     h.RepsUnit,
+			Weight:       h.Weight,
+			WeightUnit:   h.WeightUnit,
+			Remark:       h.Remark,
+			ExtraMedias:  h.ExtraMedias,
+			CreatedAt:    h.CreatedAt,
+		})
+		history_rows = append(history_rows, []string{
+			strconv.Itoa(h.Id),
+			strconv.Itoa(h.WorkoutDayId),
+			strconv.Itoa(h.WorkoutActionId),
+			name,
+			strconv.Itoa(h.Reps),
+			h.RepsUnit,
+			strconv.FormatFloat(h.Weight, 'f', -1, 64),
+			h.WeightUnit,
+			h.Remark,
+			formatTime(&h.CreatedAt),
+		})
+	}
+	if err := writeJSON(zw, "workout_action_histories.json", history_records); err != nil {
+		return err
+	}
+	if err := writeCSV(zw, "workout_action_histories.csv", []string{"id", "workout_day_id", "action_id", "action_name", "reps", "reps_unit", "weight", "weight_unit", "remark", "created_at"}, history_rows); err != nil {
+		return err
+	}
+
+	exam_records := []exportExam{}
+	exam_rows := [][]string{}
+	for _, e := range exams {
+		exam_records = append(exam_records, exportExam{
+			Id:          e.Id,
+			PaperId:     e.PaperId,
+			PaperName:   e.Paper.Name,
+			Status:      e.Status,
+			Score:       e.Score,
+			CorrectRate: e.CorrectRate,
+			Pass:        e.Pass,
+			StartedAt:   e.StartedAt,
+			CompletedAt: e.CompletedAt,
+			CreatedAt:   e.CreatedAt,
+		})
+		exam_rows = append(exam_rows, []string{
+			strconv.Itoa(e.Id),
+			strconv.Itoa(e.PaperId),
+			e.Paper.Name,
+			strconv.Itoa(e.Status),
+			strconv.Itoa(e.Score),
+			strconv.Itoa(e.CorrectRate),
+			strconv.Itoa(e.Pass),
+			formatTime(e.StartedAt),
+			formatTime(e.CompletedAt),
+		})
+	}
+	if err := writeJSON(zw, "exams.json", exam_records); err != nil {
+		return err
+	}
+	if err := writeCSV(zw, "exams.csv", []string{"id", "paper_id", "paper_name", "status", "score", "correct_rate", "pass", "started_at", "completed_at"}, exam_rows); err != nil {
+		return err
+	}
+
+	subscription_records := []exportSubscription{}
+	subscription_rows := [][]string{}
+	for _, s := range subscriptions {
+		subscription_records = append(subscription_records, exportSubscription{
+			Id:                 s.Id,
+			SubscriptionPlanId: s.SubscriptionPlanId,
+			PlanName:           s.SubscriptionPlan.Name,
+			Step:               s.Step,
+			Count:              s.Count,
+			Reason:             s.Reason,
+			ActiveAt:           s.ActiveAt,
+			ExpectExpiredAt:    s.ExpectExpiredAt,
+			ExpiredAt:          s.ExpiredAt,
+			CreatedAt:          s.CreatedAt,
+		})
+		subscription_rows = append(subscription_rows, []string{
+			strconv.Itoa(s.Id),
+			strconv.Itoa(s.SubscriptionPlanId),
+			s.SubscriptionPlan.Name,
+			strconv.Itoa(s.Step),
+			strconv.Itoa(s.Count),
+			s.Reason,
+			formatTime(s.ActiveAt),
+			formatTime(s.ExpectExpiredAt),
+			formatTime(s.ExpiredAt),
+		})
+	}
+	if err := writeJSON(zw, "subscriptions.json", subscription_records); err != nil {
+		return err
+	}
+	if err := writeCSV(zw, "subscriptions.csv", []string{"id", "subscription_plan_id", "plan_name", "step", "count", "reason", "active_at", "expect_expired_at", "expired_at"}, subscription_rows); err != nil {
+		return err
+	}
+
+	// 媒体文件只导出引用，文件本身通过 url 下载
+	media_rows := [][]string{}
+	for _, m := range medias {
+		media_rows = append(media_rows, []string{
+			strconv.Itoa(m.Id),
+			strconv.Itoa(m.MediaType),
+			m.Title,
+			m.Filename,
+			m.Filetype,
+			strconv.Itoa(m.Size),
+			m.Key,
+			m.URL,
+			formatTime(&m.CreatedAt),
+		})
+	}
+	if medias == nil {
+		medias = []models.MediaResource{}
+	}
+	if err := writeJSON(zw, "media.json", medias); err != nil {
+		return err
+	}
+	if err := writeCSV(zw, "media.csv", []string{"id", "media_type", "title", "filename", "filetype", "size", "key", "url", "created_at"}, media_rows); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func writeJSON(zw *zip.Writer, name string, v interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+func writeCSV(zw *zip.Writer, name string, header []string, rows [][]string) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	// 加上 BOM，Excel 打开中文不乱码
+	if _, err := f.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(f)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
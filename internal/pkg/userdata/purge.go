@@ -0,0 +1,181 @@
+package userdata
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"myapi/internal/models"
+)
+
+// AnonymousNickname 注销后展示的昵称
+const AnonymousNickname = "已注销用户"
+
+// 只属于用户本人的数据，注销时直接删除
+var coachOwnedTables = []string{
+	"COACH_ACCOUNT",
+	"COACH_SESSION",
+	"COACH_MAGIC_LINK",
+	"COACH_TOTP",
+	"COACH_RECOVERY_CODE",
+	"COACH_BODY_MEASUREMENT",
+	"COACH_PHYSICAL_TEST",
+	"MENSTRUAL_CYCLE",
+	"NOTIFICATION",
+	"COACH_REPORT",
+	"WORKOUT_OUT_WEEKLY_STATS",
+	"USER_FAVORITE",
+	"USER_FAVORITE_FOLDER",
+	"COACH_CONTENT_LIKE",
+	"COACH_WORKOUT_SCHEDULE",
+	"COACH_WORKOUT_PLAN_COLLECTION",
+	"SUBSCRIPTION",
+	"INFLUENCER",
+	"INFLUENCER_ACCOUNT_IN_PLATFORM",
+}
+
+// 作为学员产生的训练、答题记录
+var studentOwnedTables = []string{
+	"WORKOUT_ACTION_HISTORY",
+	"QUIZ_ANSWER",
+	"EXAM",
+}
+
+// Purge 清除用户的个人数据，调用方负责开启事务
+//
+// COACH 这一行保留下来并匿名化，其他用户的评论回复、训练记录里引用的 id 不会失效；
+// 公开发布的内容、创建的动作和计划做软删除；订单和发票需要留存，不做处理
+func Purge(db *gorm.DB, coach_id int, avatar_url string) error {
+	var targets []string
+	if err := db.Model(&models.CoachAccount{}).Where("coach_id = ?", coach_id).Pluck("provider_id", &targets).Error; err != nil {
+		return err
+	}
+	if len(targets) != 0 {
+		if err := db.Where("target IN ?", targets).Delete(&models.VerificationCode{}).Error; err != nil {
+			return err
+		}
+	}
+	// 点赞记录删掉之前，先把这些内容的点赞数减回去
+	liked_ids := db.Model(&models.CoachContentLike{}).Select("coach_content_id").Where("coach_id = ?", coach_id)
+	if err := db.Model(&models.CoachContent{}).Where("id IN (?) AND like_count > 0", liked_ids).
+		Update("like_count", gorm.Expr("like_count - 1")).Error; err != nil {
+		return err
+	}
+	// 表名用 Table 传入，由 GORM 加引号，Postgres 上大写的表名才能匹配
+	for _, table := range coachOwnedTables {
+		if err := db.Table(table).Where("coach_id = ?", coach_id).Delete(map[string]interface{}{}).Error; err != nil {
+			return err
+		}
+	}
+	day_ids := db.Model(&models.WorkoutDay{}).Select("id").Where("student_id = ?", coach_id)
+	for _, table := range []string{"WORKOUT_DAY_ACTION", "WORKOUT_DAY_STEP"} {
+		if err := db.Table(table).Where("workout_day_id IN (?)", day_ids).Delete(map[string]interface{}{}).Error; err != nil {
+			return err
+		}
+	}
+	for _, table := range studentOwnedTables {
+		if err := db.Table(table).Where("student_id = ?", coach_id).Delete(map[string]interface{}{}).Error; err != nil {
+			return err
+		}
+	}
+	if err := db.Where("student_id = ?", coach_id).Delete(&models.WorkoutDay{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("coach_id = ? OR student_id = ?", coach_id, coach_id).Delete(&models.CoachRelationship{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("coach_id = ? OR student_id = ? OR invitee_id = ?", coach_id, coach_id, coach_id).Delete(&models.CoachInvite{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("follower_id = ? OR following_id = ?", coach_id, coach_id).Delete(&models.CoachFollow{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("blocker_id = ? OR blocked_id = ?", coach_id, coach_id).Delete(&models.CoachBlock{}).Error; err != nil {
+		return err
+	}
+	if err := db.Table("INVITATION_CODE_COACH_RELATION").Where("inviter_id = ? OR invitee_id = ?", coach_id, coach_id).Delete(map[string]interface{}{}).Error; err != nil {
+		return err
+	}
+	// 媒体文件本身还在对象存储里，这里只删除引用
+	if err := db.Where("creator_id = ?", coach_id).Delete(&models.MediaResource{}).Error; err != nil {
+		return err
+	}
+	// 评论保留楼层，只清空内容
+	if err := db.Model(&models.CoachContentComment{}).Where("coach_id = ?", coach_id).Update("content", "").Error; err != nil {
+		return err
+	}
+	if err := db.Model(&models.CoachContent{}).Where("coach_id = ?", coach_id).Update("d", 1).Error; err != nil {
+		return err
+	}
+	for _, table := range []string{"WORKOUT_PLAN", "WORKOUT_ACTION", "WORKOUT_PLAN_COLLECTION"} {
+		if err := db.Table(table).Where("owner_id = ?", coach_id).Update("d", 1).Error; err != nil {
+			return err
+		}
+	}
+	if err := db.Table("INVITATION_CODE").Where("creator_id = ?", coach_id).Update("d", 1).Error; err != nil {
+		return err
+	}
+	if err := db.Model(&models.CoachProfile1{}).Where("coach_id = ?", coach_id).Updates(map[string]interface{}{
+		"nickname":             AnonymousNickname,
+		"avatar_url":           avatar_url,
+		"age":                  0,
+		"gender":               0,
+		"body_type":            0,
+		"height":               0,
+		"weight":               0,
+		"body_fat_percent":     0,
+		"risk_screenings":      "",
+		"training_goals":       "",
+		"training_frequency":   0,
+		"training_preferences": "",
+		"diet_preferences":     "",
+	}).Error; err != nil {
+		return err
+	}
+	if err := db.Model(&models.CoachProfile2{}).Where("coach_id = ?", coach_id).Updates(map[string]interface{}{
+		"specialties":      "",
+		"certification":    "",
+		"experience_years": "",
+	}).Error; err != nil {
+		return err
+	}
+	return db.Model(&models.Coach{}).Where("id = ?", coach_id).Updates(map[string]interface{}{
+		"d":             1,
+		"nickname":      AnonymousNickname,
+		"bio":           "",
+		"config":        "{}",
+		"workout_stats": "",
+	}).Error
+}
+
+// ProcessDeletionRequests 执行已经过了冷静期的注销申请，返回处理的数量
+func ProcessDeletionRequests(db *gorm.DB, now time.Time, avatar_url string) (int, error) {
+	var list []models.CoachDeletionRequest
+	if err := db.Where("status = ? AND scheduled_at <= ?", models.DeletionRequestStatusPending, now).Find(&list).Error; err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, v := range list {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// 条件更新，避免同时运行时重复执行，也避免和撤销操作冲突
+			r := tx.Model(&models.CoachDeletionRequest{}).
+				Where("id = ? AND status = ?", v.Id, models.DeletionRequestStatusPending).
+				Updates(map[string]interface{}{
+					"status":       models.DeletionRequestStatusCompleted,
+					"completed_at": now,
+				})
+			if r.Error != nil {
+				return r.Error
+			}
+			if r.RowsAffected == 0 {
+				return nil
+			}
+			return Purge(tx, v.CoachId, avatar_url)
+		})
+		if err != nil {
+			return count, err
+		}
+		count += 1
+	}
+	return count, nil
+}
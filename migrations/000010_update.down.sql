@@ -0,0 +1,7 @@
+ALTER TABLE COACH_CONTENT DROP COLUMN reject_reason;
+ALTER TABLE COACH_CONTENT DROP COLUMN submitted_at;
+ALTER TABLE COACH_CONTENT DROP COLUMN reviewed_at;
+ALTER TABLE COACH_CONTENT DROP COLUMN reviewer_id;
+ALTER TABLE COACH_CONTENT DROP COLUMN updated_at;
+
+DROP TABLE IF EXISTS COACH_CONTENT_REVIEW;
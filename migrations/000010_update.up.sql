@@ -0,0 +1,18 @@
+ALTER TABLE COACH_CONTENT ADD COLUMN reject_reason TEXT NOT NULL DEFAULT ''; --最近一次审核不通过的原因
+ALTER TABLE COACH_CONTENT ADD COLUMN submitted_at DATETIME; --提交审核时间
+ALTER TABLE COACH_CONTENT ADD COLUMN reviewed_at DATETIME; --审核时间
+ALTER TABLE COACH_CONTENT ADD COLUMN reviewer_id INTEGER NOT NULL DEFAULT 0; --审核人 0表示系统自动审核
+ALTER TABLE COACH_CONTENT ADD COLUMN updated_at DATETIME; --更新时间
+
+-- 历史内容都是直接发布的，补全发布时间
+UPDATE COACH_CONTENT SET published_at = created_at WHERE status = 1 AND published_at IS NULL;
+
+-- 内容审核记录
+CREATE TABLE IF NOT EXISTS COACH_CONTENT_REVIEW(
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  status INTEGER NOT NULL DEFAULT 0, --审核结果 1审核通过 2审核不通过
+  reason TEXT NOT NULL DEFAULT '', --不通过的原因
+  reviewer_id INTEGER NOT NULL DEFAULT 0, --审核人 0表示系统自动审核
+  coach_content_id INTEGER NOT NULL DEFAULT 0, --内容id
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP -- 创建时间
+);
@@ -0,0 +1,5 @@
+ALTER TABLE COACH_CONTENT DROP COLUMN comment_count;
+
+DROP INDEX IF EXISTS idx_user_favorite;
+DROP TABLE IF EXISTS COACH_CONTENT_LIKE;
+DROP TABLE IF EXISTS COACH_CONTENT_COMMENT;
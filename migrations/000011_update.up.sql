@@ -0,0 +1,29 @@
+ALTER TABLE COACH_CONTENT ADD COLUMN comment_count INTEGER NOT NULL DEFAULT 0; --评论数
+
+-- 点赞
+CREATE TABLE IF NOT EXISTS COACH_CONTENT_LIKE(
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  coach_content_id INTEGER NOT NULL DEFAULT 0, --内容id
+  coach_id INTEGER NOT NULL DEFAULT 0, --点赞人
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP -- 创建时间
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_coach_content_like ON COACH_CONTENT_LIKE(coach_content_id, coach_id);
+
+-- 评论
+CREATE TABLE IF NOT EXISTS COACH_CONTENT_COMMENT(
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  d INTEGER NOT NULL DEFAULT 0, --软删除
+  status INTEGER NOT NULL DEFAULT 0, --1正常 2待审核 3已屏蔽
+  content TEXT NOT NULL DEFAULT '', --评论内容
+  reply_count INTEGER NOT NULL DEFAULT 0, --回复数，只有一级评论才有
+  root_id INTEGER NOT NULL DEFAULT 0, --所属一级评论，0表示自己就是一级评论
+  parent_id INTEGER NOT NULL DEFAULT 0, --回复的评论
+  reply_to_id INTEGER NOT NULL DEFAULT 0, --回复的人
+  coach_content_id INTEGER NOT NULL DEFAULT 0, --内容id
+  coach_id INTEGER NOT NULL DEFAULT 0, --评论人
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP -- 创建时间
+);
+CREATE INDEX IF NOT EXISTS idx_coach_content_comment_content ON COACH_CONTENT_COMMENT(coach_content_id, root_id);
+
+-- 同一个内容只能收藏一次
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_favorite ON USER_FAVORITE(coach_id, content_type, content_id);
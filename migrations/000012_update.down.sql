@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_coach_follow_follower;
+
+ALTER TABLE WORKOUT_DAY DROP COLUMN shared_at;
+ALTER TABLE WORKOUT_DAY DROP COLUMN shared;
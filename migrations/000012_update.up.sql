@@ -0,0 +1,6 @@
+
+ALTER TABLE WORKOUT_DAY ADD COLUMN shared INTEGER NOT NULL DEFAULT 0; --是否分享到动态 1分享
+ALTER TABLE WORKOUT_DAY ADD COLUMN shared_at DATETIME; --分享时间
+
+-- 动态按关注人读取
+CREATE INDEX IF NOT EXISTS idx_coach_follow_follower ON COACH_FOLLOW(follower_id, status);
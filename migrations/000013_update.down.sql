@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_coach_follow_following;
+DROP TABLE IF EXISTS COACH_BLOCK;
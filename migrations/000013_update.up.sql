@@ -0,0 +1,10 @@
+
+-- 拉黑
+CREATE TABLE IF NOT EXISTS COACH_BLOCK(
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  blocker_id INTEGER NOT NULL DEFAULT 0, --谁拉黑
+  blocked_id INTEGER NOT NULL DEFAULT 0, --被拉黑的人
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP -- 创建时间
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_coach_block ON COACH_BLOCK(blocker_id, blocked_id);
+CREATE INDEX IF NOT EXISTS idx_coach_follow_following ON COACH_FOLLOW(following_id, status);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_coach_invite_code;
+DROP TABLE IF EXISTS COACH_INVITE;
@@ -0,0 +1,14 @@
+
+-- 学员邀请
+CREATE TABLE IF NOT EXISTS COACH_INVITE(
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  code TEXT NOT NULL DEFAULT '', --邀请码
+  status INTEGER NOT NULL DEFAULT 1, --1待接受 2已接受 3已拒绝 4已撤销
+  coach_id INTEGER NOT NULL DEFAULT 0, --发出邀请的教练
+  student_id INTEGER NOT NULL DEFAULT 0, --教练创建的学员，接受后关联到受邀人，0表示不关联
+  invitee_id INTEGER NOT NULL DEFAULT 0, --受邀人
+  expired_at DATETIME NOT NULL, --过期时间
+  handled_at DATETIME, --接受或拒绝的时间
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP -- 创建时间
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_coach_invite_code ON COACH_INVITE(code);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_coach_session_coach;
+DROP INDEX IF EXISTS idx_coach_session_sid;
+DROP TABLE IF EXISTS COACH_SESSION;
@@ -0,0 +1,17 @@
+
+-- 登录会话，每次登录一条，刷新凭证时轮换 refresh token
+CREATE TABLE IF NOT EXISTS COACH_SESSION(
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  session_id TEXT NOT NULL DEFAULT '', --写在 access token 里的会话id
+  status INTEGER NOT NULL DEFAULT 1, --1有效 2已注销
+  device TEXT NOT NULL DEFAULT '', --设备
+  ip TEXT NOT NULL DEFAULT '', --登录ip
+  refresh_token_hash TEXT NOT NULL DEFAULT '', --当前 refresh token 的哈希
+  expired_at DATETIME NOT NULL, --会话过期时间
+  last_used_at DATETIME, --最后一次刷新时间
+  revoked_at DATETIME, --注销时间
+  coach_id INTEGER NOT NULL DEFAULT 0,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP -- 创建时间
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_coach_session_sid ON COACH_SESSION(session_id);
+CREATE INDEX IF NOT EXISTS idx_coach_session_coach ON COACH_SESSION(coach_id, status);
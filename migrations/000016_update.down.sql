@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_coach_impersonation_log_actor;
+DROP TABLE IF EXISTS COACH_IMPERSONATION_LOG;
+
+ALTER TABLE COACH_SESSION DROP COLUMN actor_id;
+ALTER TABLE COACH_SESSION DROP COLUMN scope;
+
+DROP INDEX IF EXISTS idx_coach_magic_link_token;
+DROP TABLE IF EXISTS COACH_MAGIC_LINK;
@@ -0,0 +1,30 @@
+
+-- 一次性登录链接，换成会话后失效
+CREATE TABLE IF NOT EXISTS COACH_MAGIC_LINK(
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  token_hash TEXT NOT NULL DEFAULT '', --链接里 code 的哈希
+  scope TEXT NOT NULL DEFAULT '', --权限范围 空表示不限制 workout只能记录训练
+  actor_id INTEGER NOT NULL DEFAULT 0, --管理员代登录时记录管理员id
+  expired_at DATETIME NOT NULL, --过期时间
+  used_at DATETIME, --使用时间
+  coach_id INTEGER NOT NULL DEFAULT 0, --登录的用户
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP -- 创建时间
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_coach_magic_link_token ON COACH_MAGIC_LINK(token_hash);
+
+ALTER TABLE COACH_SESSION ADD COLUMN scope TEXT NOT NULL DEFAULT ''; --权限范围
+ALTER TABLE COACH_SESSION ADD COLUMN actor_id INTEGER NOT NULL DEFAULT 0; --代登录的管理员id
+
+-- 管理员代登录期间的操作记录
+CREATE TABLE IF NOT EXISTS COACH_IMPERSONATION_LOG(
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  actor_id INTEGER NOT NULL DEFAULT 0, --管理员id
+  session_id TEXT NOT NULL DEFAULT '', --会话id
+  method TEXT NOT NULL DEFAULT '',
+  path TEXT NOT NULL DEFAULT '', --请求的接口
+  status INTEGER NOT NULL DEFAULT 0, --HTTP 状态码
+  ip TEXT NOT NULL DEFAULT '',
+  coach_id INTEGER NOT NULL DEFAULT 0, --被代登录的用户
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP -- 创建时间
+);
+CREATE INDEX IF NOT EXISTS idx_coach_impersonation_log_actor ON COACH_IMPERSONATION_LOG(actor_id, created_at);
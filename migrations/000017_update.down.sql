@@ -0,0 +1,4 @@
+ALTER TABLE COACH_ACCOUNT DROP COLUMN verified_at;
+
+DROP INDEX IF EXISTS idx_verification_code_target;
+DROP TABLE IF EXISTS VERIFICATION_CODE;
@@ -0,0 +1,15 @@
+
+-- 邮箱验证码，注册验证和找回密码共用
+CREATE TABLE IF NOT EXISTS VERIFICATION_CODE(
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  target TEXT NOT NULL DEFAULT '', --接收验证码的邮箱
+  purpose INTEGER NOT NULL DEFAULT 0, --1验证邮箱 2重置密码
+  code_hash TEXT NOT NULL DEFAULT '', --验证码的哈希
+  attempts INTEGER NOT NULL DEFAULT 0, --已经尝试的次数
+  expired_at DATETIME NOT NULL, --过期时间
+  used_at DATETIME, --使用时间
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP -- 创建时间
+);
+CREATE INDEX IF NOT EXISTS idx_verification_code_target ON VERIFICATION_CODE(target, purpose, created_at);
+
+ALTER TABLE COACH_ACCOUNT ADD COLUMN verified_at DATETIME; --邮箱验证时间，为空表示未验证
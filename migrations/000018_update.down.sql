@@ -0,0 +1,7 @@
+ALTER TABLE COACH_SESSION DROP COLUMN mfa_verified;
+
+DROP INDEX IF EXISTS idx_coach_recovery_code_coach;
+DROP TABLE IF EXISTS COACH_RECOVERY_CODE;
+
+DROP INDEX IF EXISTS idx_coach_totp_coach;
+DROP TABLE IF EXISTS COACH_TOTP;
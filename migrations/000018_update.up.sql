@@ -0,0 +1,24 @@
+
+-- 两步验证，每个用户一条
+CREATE TABLE IF NOT EXISTS COACH_TOTP(
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  secret TEXT NOT NULL DEFAULT '', --base32 编码的密钥
+  status INTEGER NOT NULL DEFAULT 1, --1待确认 2已开启
+  last_used_step INTEGER NOT NULL DEFAULT 0, --最后一次使用的验证码周期，防止重复使用
+  enabled_at DATETIME, --开启时间
+  coach_id INTEGER NOT NULL DEFAULT 0,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP -- 创建时间
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_coach_totp_coach ON COACH_TOTP(coach_id);
+
+-- 两步验证的恢复码，每个只能用一次
+CREATE TABLE IF NOT EXISTS COACH_RECOVERY_CODE(
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  code_hash TEXT NOT NULL DEFAULT '', --恢复码的哈希
+  used_at DATETIME, --使用时间
+  coach_id INTEGER NOT NULL DEFAULT 0,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP -- 创建时间
+);
+CREATE INDEX IF NOT EXISTS idx_coach_recovery_code_coach ON COACH_RECOVERY_CODE(coach_id);
+
+ALTER TABLE COACH_SESSION ADD COLUMN mfa_verified INTEGER NOT NULL DEFAULT 0; --是否通过了两步验证
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_coach_deletion_request_status;
+DROP INDEX IF EXISTS idx_coach_deletion_request_coach;
+DROP TABLE IF EXISTS COACH_DELETION_REQUEST;
@@ -0,0 +1,13 @@
+-- 注销帐号申请，冷静期过后由 cli process_deletions 清除数据
+CREATE TABLE IF NOT EXISTS COACH_DELETION_REQUEST(
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  status INTEGER NOT NULL DEFAULT 1, --1等待执行 2已撤销 3已完成
+  reason TEXT NOT NULL DEFAULT '', --注销原因
+  scheduled_at DATETIME NOT NULL, --计划执行时间，在此之前可以撤销
+  cancelled_at DATETIME, --撤销时间
+  completed_at DATETIME, --执行时间
+  coach_id INTEGER NOT NULL DEFAULT 0,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP -- 创建时间
+);
+CREATE INDEX IF NOT EXISTS idx_coach_deletion_request_coach ON COACH_DELETION_REQUEST(coach_id);
+CREATE INDEX IF NOT EXISTS idx_coach_deletion_request_status ON COACH_DELETION_REQUEST(status, scheduled_at);
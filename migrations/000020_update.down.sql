@@ -0,0 +1,6 @@
+ALTER TABLE WORKOUT_DAY ADD COLUMN time_text TEXT NOT NULL DEFAULT '';
+UPDATE WORKOUT_DAY SET time_text = datetime(time, '+8 hours') WHERE time IS NOT NULL;
+ALTER TABLE WORKOUT_DAY DROP COLUMN time;
+ALTER TABLE WORKOUT_DAY RENAME COLUMN time_text TO time;
+
+ALTER TABLE COACH DROP COLUMN timezone;
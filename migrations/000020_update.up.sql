@@ -0,0 +1,8 @@
+-- 用户时区，统计、今日训练、周期计划按这个时区划分每一天
+ALTER TABLE COACH ADD COLUMN timezone TEXT NOT NULL DEFAULT 'Asia/Shanghai'; --IANA 时区名
+
+-- 训练时间从本地时间的文本改成时间，之前的文本都是东八区的 年-月-日 时:分:秒，格式不对的置空
+ALTER TABLE WORKOUT_DAY ADD COLUMN time_at DATETIME;
+UPDATE WORKOUT_DAY SET time_at = datetime(time, '-8 hours') WHERE time != '';
+ALTER TABLE WORKOUT_DAY DROP COLUMN time;
+ALTER TABLE WORKOUT_DAY RENAME COLUMN time_at TO time; --训练时间
@@ -0,0 +1,7 @@
+// Package migrations 把迁移文件打包进程序，部署时不需要再带上 SQL 文件
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
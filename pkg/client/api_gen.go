@@ -0,0 +1,1823 @@
+// Code generated by cmd/sdkgen. DO NOT EDIT.
+
+package client
+
+import "context"
+
+// idempotent 重复请求没有副作用的接口，遇到 502、503、504 时可以重试
+var idempotent = map[string]bool{
+	"/api/admin/coach/profile":                           true,
+	"/api/admin/comment/pending_list":                    true,
+	"/api/admin/content/pending_list":                    true,
+	"/api/admin/impersonation_log/list":                  true,
+	"/api/auth/2fa/status":                               true,
+	"/api/auth/account/list":                             true,
+	"/api/auth/deletion/status":                          true,
+	"/api/auth/export":                                   true,
+	"/api/auth/oauth/bind_url":                           true,
+	"/api/auth/oauth/url":                                true,
+	"/api/auth/profile":                                  true,
+	"/api/auth/qiniu_token":                              true,
+	"/api/auth/session/list":                             true,
+	"/api/coach/content/list":                            true,
+	"/api/coach/list":                                    true,
+	"/api/coach/profile":                                 true,
+	"/api/content/comment/list":                          true,
+	"/api/content/list":                                  true,
+	"/api/content/profile":                               true,
+	"/api/content/review/list":                           true,
+	"/api/equipment/list":                                true,
+	"/api/equipment/profile":                             true,
+	"/api/exam/list":                                     true,
+	"/api/exam/profile":                                  true,
+	"/api/exam/result":                                   true,
+	"/api/exam/running":                                  true,
+	"/api/favorite/list":                                 true,
+	"/api/feed/list":                                     true,
+	"/api/follow/suggestion_list":                        true,
+	"/api/my/block/list":                                 true,
+	"/api/my/follower/list":                              true,
+	"/api/my/following/list":                             true,
+	"/api/gift_card/list":                                true,
+	"/api/gift_card/profile":                             true,
+	"/api/gift_card/reward_list":                         true,
+	"/api/invite/list":                                   true,
+	"/api/invite/profile":                                true,
+	"/api/media/list":                                    true,
+	"/api/media/qiniu_token":                             true,
+	"/api/muscle/list":                                   true,
+	"/api/muscle/profile":                                true,
+	"/api/paper/list":                                    true,
+	"/api/paper/profile":                                 true,
+	"/api/quiz/list":                                     true,
+	"/api/report/list":                                   true,
+	"/api/report/list_of_mine":                           true,
+	"/api/report/profile":                                true,
+	"/api/student/list":                                  true,
+	"/api/student/profile":                               true,
+	"/api/student/workout_action_history/list":           true,
+	"/api/student/workout_day/list":                      true,
+	"/api/student/workout_day/profile":                   true,
+	"/api/student/workout_day/result":                    true,
+	"/api/subscription/list":                             true,
+	"/api/subscription_order/calc":                       true,
+	"/api/subscription_plan/list":                        true,
+	"/api/ping":                                          true,
+	"/api/workout_action/content/list":                   true,
+	"/api/workout_action/list":                           true,
+	"/api/workout_action/list/by_level":                  true,
+	"/api/workout_action/list/by_muscle":                 true,
+	"/api/workout_action/list/cardio":                    true,
+	"/api/workout_action/list/related":                   true,
+	"/api/workout_action/list_by_ids":                    true,
+	"/api/workout_action/profile":                        true,
+	"/api/workout_action_history/list_of_workout_action": true,
+	"/api/workout_action_history/list_of_workout_day":    true,
+	"/api/workout_day/finished_list":                     true,
+	"/api/workout_day/has_started":                       true,
+	"/api/workout_day/list":                              true,
+	"/api/workout_day/profile":                           true,
+	"/api/workout_day/result":                            true,
+	"/api/workout_day/started_list":                      true,
+	"/api/workout_plan/content/list":                     true,
+	"/api/workout_plan/content/profile":                  true,
+	"/api/workout_plan/list":                             true,
+	"/api/workout_plan/mine":                             true,
+	"/api/workout_plan/profile":                          true,
+	"/api/workout_plan_set/list":                         true,
+	"/api/workout_schedule/enabled":                      true,
+	"/api/workout_schedule/list":                         true,
+	"/api/workout_schedule/profile":                      true,
+}
+
+// services 按路由分组的接口，嵌入到 Client 里
+type services struct {
+	Admin                *AdminService
+	Auth                 *AuthService
+	Coach                *CoachService
+	Content              *ContentService
+	Equipment            *EquipmentService
+	Exam                 *ExamService
+	Favorite             *FavoriteService
+	Feed                 *FeedService
+	Follow               *FollowService
+	Friend               *FriendService
+	GiftCard             *GiftCardService
+	Invite               *InviteService
+	Media                *MediaService
+	Muscle               *MuscleService
+	Paper                *PaperService
+	Quiz                 *QuizService
+	Relationship         *RelationshipService
+	Report               *ReportService
+	Stats                *StatsService
+	Student              *StudentService
+	Subscription         *SubscriptionService
+	SubscriptionOrder    *SubscriptionOrderService
+	SubscriptionPlan     *SubscriptionPlanService
+	System               *SystemService
+	WorkoutAction        *WorkoutActionService
+	WorkoutActionHistory *WorkoutActionHistoryService
+	WorkoutDay           *WorkoutDayService
+	WorkoutPlan          *WorkoutPlanService
+	WorkoutPlanSet       *WorkoutPlanSetService
+	WorkoutSchedule      *WorkoutScheduleService
+}
+
+func (s *services) init(c *Client) {
+	s.Admin = &AdminService{c: c}
+	s.Auth = &AuthService{c: c}
+	s.Coach = &CoachService{c: c}
+	s.Content = &ContentService{c: c}
+	s.Equipment = &EquipmentService{c: c}
+	s.Exam = &ExamService{c: c}
+	s.Favorite = &FavoriteService{c: c}
+	s.Feed = &FeedService{c: c}
+	s.Follow = &FollowService{c: c}
+	s.Friend = &FriendService{c: c}
+	s.GiftCard = &GiftCardService{c: c}
+	s.Invite = &InviteService{c: c}
+	s.Media = &MediaService{c: c}
+	s.Muscle = &MuscleService{c: c}
+	s.Paper = &PaperService{c: c}
+	s.Quiz = &QuizService{c: c}
+	s.Relationship = &RelationshipService{c: c}
+	s.Report = &ReportService{c: c}
+	s.Stats = &StatsService{c: c}
+	s.Student = &StudentService{c: c}
+	s.Subscription = &SubscriptionService{c: c}
+	s.SubscriptionOrder = &SubscriptionOrderService{c: c}
+	s.SubscriptionPlan = &SubscriptionPlanService{c: c}
+	s.System = &SystemService{c: c}
+	s.WorkoutAction = &WorkoutActionService{c: c}
+	s.WorkoutActionHistory = &WorkoutActionHistoryService{c: c}
+	s.WorkoutDay = &WorkoutDayService{c: c}
+	s.WorkoutPlan = &WorkoutPlanService{c: c}
+	s.WorkoutPlanSet = &WorkoutPlanSetService{c: c}
+	s.WorkoutSchedule = &WorkoutScheduleService{c: c}
+}
+
+// AdminService admin 相关的接口
+type AdminService struct {
+	c *Client
+}
+
+// CoachAuthURL 管理员代登录链接
+func (s *AdminService) CoachAuthURL(ctx context.Context, req BuildCoachAuthURLInAdminRequest) (*AuthURLResponse, error) {
+	var out *AuthURLResponse
+	if err := s.c.do(ctx, "POST", "/api/admin/coach/auth_url", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CoachProfile 管理后台的教练详情
+func (s *AdminService) CoachProfile(ctx context.Context, req FetchCoachProfileInAdminRequest) (*CoachBrief, error) {
+	var out *CoachBrief
+	if err := s.c.do(ctx, "POST", "/api/admin/coach/profile", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CommentPendingList 管理后台 待审核的评论
+func (s *AdminService) CommentPendingList(ctx context.Context, req FetchPendingCommentListRequest) (*PendingCommentItemList, error) {
+	var out *PendingCommentItemList
+	if err := s.c.do(ctx, "POST", "/api/admin/comment/pending_list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CommentReview 管理后台 审核评论，通过后展示，不通过则屏蔽。已经展示的评论也可以屏蔽
+func (s *AdminService) CommentReview(ctx context.Context, req ReviewCommentRequest) error {
+	return s.c.do(ctx, "POST", "/api/admin/comment/review", req, nil)
+}
+
+// ContentPendingList 管理后台 待审核的内容列表
+func (s *AdminService) ContentPendingList(ctx context.Context, req FetchPendingArticleListRequest) (*PendingArticleItemList, error) {
+	var out *PendingArticleItemList
+	if err := s.c.do(ctx, "POST", "/api/admin/content/pending_list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ContentReview 管理后台 审核内容
+func (s *AdminService) ContentReview(ctx context.Context, req ReviewArticleRequest) (*ReviewArticleResponse, error) {
+	var out *ReviewArticleResponse
+	if err := s.c.do(ctx, "POST", "/api/admin/content/review", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ImpersonationLogList 管理员代登录的操作记录
+func (s *AdminService) ImpersonationLogList(ctx context.Context, req FetchImpersonationLogListRequest) (*CoachImpersonationLogList, error) {
+	var out *CoachImpersonationLogList
+	if err := s.c.do(ctx, "POST", "/api/admin/impersonation_log/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LoginUnlock 管理员解除登录锁定，可以按邮箱或者 IP
+func (s *AdminService) LoginUnlock(ctx context.Context, req UnlockLoginInAdminRequest) error {
+	return s.c.do(ctx, "POST", "/api/admin/login/unlock", req, nil)
+}
+
+// WorkoutDayRefresh250630 修复训练日数据（250630）
+func (s *AdminService) WorkoutDayRefresh250630(ctx context.Context) (*RefreshWorkoutDayRecordsResponse, error) {
+	var out *RefreshWorkoutDayRecordsResponse
+	if err := s.c.do(ctx, "POST", "/api/admin/workout_day/refresh_250630", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AuthService auth 相关的接口
+type AuthService struct {
+	c *Client
+}
+
+// TwoFADisable 关闭两步验证，需要验证码或者恢复码
+func (s *AuthService) TwoFADisable(ctx context.Context, req DisableTOTPRequest) error {
+	return s.c.do(ctx, "POST", "/api/auth/2fa/disable", req, nil)
+}
+
+// TwoFAEnable 输入验证器里的验证码确认开启，返回恢复码，当前会话视为已通过两步验证
+func (s *AuthService) TwoFAEnable(ctx context.Context, req EnableTOTPRequest) (*RecoveryCodesResponse, error) {
+	var out *RecoveryCodesResponse
+	if err := s.c.do(ctx, "POST", "/api/auth/2fa/enable", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TwoFAEnroll 开始开启两步验证，返回密钥和二维码内容，调用 enable 确认后才生效
+func (s *AuthService) TwoFAEnroll(ctx context.Context) (*EnrollTOTPResponse, error) {
+	var out *EnrollTOTPResponse
+	if err := s.c.do(ctx, "POST", "/api/auth/2fa/enroll", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TwoFARecoveryCodes 重新生成恢复码
+func (s *AuthService) TwoFARecoveryCodes(ctx context.Context, req RegenerateRecoveryCodesRequest) (*RecoveryCodesResponse, error) {
+	var out *RecoveryCodesResponse
+	if err := s.c.do(ctx, "POST", "/api/auth/2fa/recovery_codes", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TwoFAStatus 两步验证的状态
+func (s *AuthService) TwoFAStatus(ctx context.Context) (*FetchMFAStatusResponse, error) {
+	var out *FetchMFAStatusResponse
+	if err := s.c.do(ctx, "POST", "/api/auth/2fa/status", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TwoFAVerify 登录的第二步，用临时凭证和验证码换会话
+func (s *AuthService) TwoFAVerify(ctx context.Context, req VerifyMFARequest) (*AuthResponse, error) {
+	var out *AuthResponse
+	if err := s.c.do(ctx, "POST", "/api/auth/2fa/verify", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AccountList 已绑定的登录方式
+func (s *AuthService) AccountList(ctx context.Context) (*FetchAccountListResponse, error) {
+	var out *FetchAccountListResponse
+	if err := s.c.do(ctx, "POST", "/api/auth/account/list", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AccountUnlink 解绑登录方式，至少保留一种
+func (s *AuthService) AccountUnlink(ctx context.Context, req UnlinkAccountRequest) error {
+	return s.c.do(ctx, "POST", "/api/auth/account/unlink", req, nil)
+}
+
+// CreateAccount 通过授权链接访问，并且补全登录信息
+func (s *AuthService) CreateAccount(ctx context.Context, req CreateAccountRequest) (*AuthResponse, error) {
+	var out *AuthResponse
+	if err := s.c.do(ctx, "POST", "/api/auth/create_account", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeletionCancel 撤销注销申请
+func (s *AuthService) DeletionCancel(ctx context.Context) error {
+	return s.c.do(ctx, "POST", "/api/auth/deletion/cancel", nil, nil)
+}
+
+// DeletionRequest 申请注销帐号，冷静期内可以撤销
+func (s *AuthService) DeletionRequest(ctx context.Context, req RequestDeletionRequest) (*CoachDeletionRequest, error) {
+	var out *CoachDeletionRequest
+	if err := s.c.do(ctx, "POST", "/api/auth/deletion/request", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeletionStatus 注销申请的状态，没有申请时 data 为 null
+func (s *AuthService) DeletionStatus(ctx context.Context) (*CoachDeletionRequest, error) {
+	var out *CoachDeletionRequest
+	if err := s.c.do(ctx, "POST", "/api/auth/deletion/status", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Export 导出个人数据，返回 zip 文件
+func (s *AuthService) Export(ctx context.Context) ([]byte, error) {
+	var out download
+	if err := s.c.do(ctx, "POST", "/api/auth/export", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ForgotPassword 忘记密码，发送重置密码的验证码
+func (s *AuthService) ForgotPassword(ctx context.Context, req ForgotPasswordRequest) error {
+	return s.c.do(ctx, "POST", "/api/auth/forgot_password", req, nil)
+}
+
+// Logout 退出登录，传 id 时注销指定设备，否则注销当前设备
+func (s *AuthService) Logout(ctx context.Context, req LogoutRequest) error {
+	return s.c.do(ctx, "POST", "/api/auth/logout", req, nil)
+}
+
+// LogoutAll 退出所有设备，包括当前设备
+func (s *AuthService) LogoutAll(ctx context.Context) (*LogoutAllResponse, error) {
+	var out *LogoutAllResponse
+	if err := s.c.do(ctx, "POST", "/api/auth/logout_all", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MagicLink 用登录链接里的 code 换会话，每个链接只能用一次
+func (s *AuthService) MagicLink(ctx context.Context, req ExchangeMagicLinkRequest) (*AuthResponse, error) {
+	var out *AuthResponse
+	if err := s.c.do(ctx, "POST", "/api/auth/magic_link", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// OAuthBind 绑定第三方帐号
+func (s *AuthService) OAuthBind(ctx context.Context, req OAuthCodeRequest) error {
+	return s.c.do(ctx, "POST", "/api/auth/oauth/bind", req, nil)
+}
+
+// OAuthBindURL 绑定第三方帐号的授权地址，state 里带着当前用户
+func (s *AuthService) OAuthBindURL(ctx context.Context) (*OAuthURLResponse, error) {
+	var out *OAuthURLResponse
+	if err := s.c.do(ctx, "POST", "/api/auth/oauth/bind_url", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// OAuthLogin 第三方登录回调后，用授权码登录，没有绑定过的帐号直接注册
+func (s *AuthService) OAuthLogin(ctx context.Context, req OAuthCodeRequest) (*AuthResponse, error) {
+	var out *AuthResponse
+	if err := s.c.do(ctx, "POST", "/api/auth/oauth/login", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// OAuthURL 第三方登录的授权地址
+func (s *AuthService) OAuthURL(ctx context.Context) (*OAuthURLResponse, error) {
+	var out *OAuthURLResponse
+	if err := s.c.do(ctx, "POST", "/api/auth/oauth/url", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Profile 当前用户的资料
+func (s *AuthService) Profile(ctx context.Context) (*FetchCoachProfileResponse, error) {
+	var out *FetchCoachProfileResponse
+	if err := s.c.do(ctx, "POST", "/api/auth/profile", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// QiniuToken 七牛上传凭证
+func (s *AuthService) QiniuToken(ctx context.Context) (*QiniuTokenResponse, error) {
+	var out *QiniuTokenResponse
+	if err := s.c.do(ctx, "POST", "/api/auth/qiniu_token", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RefreshToken 用 refresh token 换新的 access token，同时轮换 refresh token
+func (s *AuthService) RefreshToken(ctx context.Context, req RefreshTokenRequest) (*AuthResponse, error) {
+	var out *AuthResponse
+	if err := s.c.do(ctx, "POST", "/api/auth/refresh_token", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ResetPassword 用验证码重置密码，成功后所有设备都需要重新登录
+func (s *AuthService) ResetPassword(ctx context.Context, req ResetPasswordRequest) error {
+	return s.c.do(ctx, "POST", "/api/auth/reset_password", req, nil)
+}
+
+// SendVerificationCode 发送邮箱验证码
+func (s *AuthService) SendVerificationCode(ctx context.Context, req SendVerificationCodeRequest) error {
+	return s.c.do(ctx, "POST", "/api/auth/send_verification_code", req, nil)
+}
+
+// SessionList 我的登录设备
+func (s *AuthService) SessionList(ctx context.Context) (*FetchSessionListResponse, error) {
+	var out *FetchSessionListResponse
+	if err := s.c.do(ctx, "POST", "/api/auth/session/list", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SMSBind 绑定手机号
+func (s *AuthService) SMSBind(ctx context.Context, req BindPhoneRequest) error {
+	return s.c.do(ctx, "POST", "/api/auth/sms/bind", req, nil)
+}
+
+// SMSLogin 手机号验证码登录，没有注册过的手机号直接注册
+func (s *AuthService) SMSLogin(ctx context.Context, req LoginWithSMSRequest) (*AuthResponse, error) {
+	var out *AuthResponse
+	if err := s.c.do(ctx, "POST", "/api/auth/sms/login", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SMSSendCode 发送手机号登录验证码
+func (s *AuthService) SMSSendCode(ctx context.Context, req SendSMSCodeRequest) error {
+	return s.c.do(ctx, "POST", "/api/auth/sms/send_code", req, nil)
+}
+
+// UpdateProfile 更新当前用户的资料
+func (s *AuthService) UpdateProfile(ctx context.Context, req UpdateCoachProfileRequest) error {
+	return s.c.do(ctx, "POST", "/api/auth/update_profile", req, nil)
+}
+
+// VerifyEmail 验证当前账号的邮箱
+func (s *AuthService) VerifyEmail(ctx context.Context, req VerifyEmailRequest) error {
+	return s.c.do(ctx, "POST", "/api/auth/verify_email", req, nil)
+}
+
+// WebLogin 邮箱密码登录
+func (s *AuthService) WebLogin(ctx context.Context, req LoginCoachRequest) (*AuthResponse, error) {
+	var out *AuthResponse
+	if err := s.c.do(ctx, "POST", "/api/auth/web_login", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WebRegister 邮箱密码注册
+func (s *AuthService) WebRegister(ctx context.Context, req RegisterCoachRequest) (*AuthResponse, error) {
+	var out *AuthResponse
+	if err := s.c.do(ctx, "POST", "/api/auth/web_register", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CoachService coach 相关的接口
+type CoachService struct {
+	c *Client
+}
+
+// ContentCreate 给教练创建内容
+func (s *CoachService) ContentCreate(ctx context.Context, req CreateCoachContentRequest) error {
+	return s.c.do(ctx, "POST", "/api/coach/content/create", req, nil)
+}
+
+// ContentList 教练的内容列表
+func (s *CoachService) ContentList(ctx context.Context, req FetchCoachContentListRequest) (*CoachContentList, error) {
+	var out *CoachContentList
+	if err := s.c.do(ctx, "POST", "/api/coach/content/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Create 创建教练
+func (s *CoachService) Create(ctx context.Context, req CreateCoachRequest) error {
+	return s.c.do(ctx, "POST", "/api/coach/create", req, nil)
+}
+
+// List 教练列表
+func (s *CoachService) List(ctx context.Context, req FetchCoachListRequest) (*CoachItemList, error) {
+	var out *CoachItemList
+	if err := s.c.do(ctx, "POST", "/api/coach/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Profile 教练主页
+func (s *CoachService) Profile(ctx context.Context, req FetchCoachProfileInWechatRequest) (*FetchCoachProfileInWechatResponse, error) {
+	var out *FetchCoachProfileInWechatResponse
+	if err := s.c.do(ctx, "POST", "/api/coach/profile", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ContentService content 相关的接口
+type ContentService struct {
+	c *Client
+}
+
+// CommentCreate 发表评论
+func (s *ContentService) CommentCreate(ctx context.Context, req CreateCommentRequest) (*CreateCommentResponse, error) {
+	var out *CreateCommentResponse
+	if err := s.c.do(ctx, "POST", "/api/content/comment/create", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CommentDelete 评论人和内容作者都可以删除评论
+func (s *ContentService) CommentDelete(ctx context.Context, req DeleteCommentRequest) error {
+	return s.c.do(ctx, "POST", "/api/content/comment/delete", req, nil)
+}
+
+// CommentList 评论列表
+func (s *ContentService) CommentList(ctx context.Context, req FetchCommentListRequest) (*CommentItemList, error) {
+	var out *CommentItemList
+	if err := s.c.do(ctx, "POST", "/api/content/comment/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Create 创建内容
+func (s *ContentService) Create(ctx context.Context, req CreateArticleRequest) (*ArticleStatusResponse, error) {
+	var out *ArticleStatusResponse
+	if err := s.c.do(ctx, "POST", "/api/content/create", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Like 点赞
+func (s *ContentService) Like(ctx context.Context, req LikeContentRequest) (*LikeContentResponse, error) {
+	var out *LikeContentResponse
+	if err := s.c.do(ctx, "POST", "/api/content/like", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// List 我的内容列表
+func (s *ContentService) List(ctx context.Context, req FetchArticleListRequest) (*ArticleItemList, error) {
+	var out *ArticleItemList
+	if err := s.c.do(ctx, "POST", "/api/content/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Profile 内容详情
+func (s *ContentService) Profile(ctx context.Context, req FetchArticleProfileRequest) (*ArticleProfile, error) {
+	var out *ArticleProfile
+	if err := s.c.do(ctx, "POST", "/api/content/profile", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReviewList 作者查看内容的审核记录
+func (s *ContentService) ReviewList(ctx context.Context, req FetchArticleReviewListRequest) (*FetchArticleReviewListResponse, error) {
+	var out *FetchArticleReviewListResponse
+	if err := s.c.do(ctx, "POST", "/api/content/review/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Submit 作者提交审核
+func (s *ContentService) Submit(ctx context.Context, req SubmitArticleRequest) (*ArticleStatusResponse, error) {
+	var out *ArticleStatusResponse
+	if err := s.c.do(ctx, "POST", "/api/content/submit", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Unlike 取消点赞
+func (s *ContentService) Unlike(ctx context.Context, req UnlikeContentRequest) (*LikeContentResponse, error) {
+	var out *LikeContentResponse
+	if err := s.c.do(ctx, "POST", "/api/content/unlike", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Update 更新内容
+func (s *ContentService) Update(ctx context.Context, req UpdateArticleRequest) (*IdResponse, error) {
+	var out *IdResponse
+	if err := s.c.do(ctx, "POST", "/api/content/update", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EquipmentService equipment 相关的接口
+type EquipmentService struct {
+	c *Client
+}
+
+// Create 创建器械
+func (s *EquipmentService) Create(ctx context.Context, req CreateEquipmentRequest) error {
+	return s.c.do(ctx, "POST", "/api/equipment/create", req, nil)
+}
+
+// Delete 删除器械
+func (s *EquipmentService) Delete(ctx context.Context, req DeleteEquipmentRequest) error {
+	return s.c.do(ctx, "POST", "/api/equipment/delete", req, nil)
+}
+
+// List 器械列表
+func (s *EquipmentService) List(ctx context.Context, req FetchEquipmentListRequest) (*EquipmentList, error) {
+	var out *EquipmentList
+	if err := s.c.do(ctx, "POST", "/api/equipment/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Profile 器械详情
+func (s *EquipmentService) Profile(ctx context.Context, req FetchEquipmentRequest) (*Equipment, error) {
+	var out *Equipment
+	if err := s.c.do(ctx, "POST", "/api/equipment/profile", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Update 更新器械
+func (s *EquipmentService) Update(ctx context.Context, req UpdateEquipmentRequest) error {
+	return s.c.do(ctx, "POST", "/api/equipment/update", req, nil)
+}
+
+// ExamService exam 相关的接口
+type ExamService struct {
+	c *Client
+}
+
+// Answer 答题
+func (s *ExamService) Answer(ctx context.Context, req UpdateQuizAnswerRequest) (*QuizAnswer, error) {
+	var out *QuizAnswer
+	if err := s.c.do(ctx, "POST", "/api/exam/answer", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Complete 交卷
+func (s *ExamService) Complete(ctx context.Context, req CompleteExamRequest) (*CompleteExamResponse, error) {
+	var out *CompleteExamResponse
+	if err := s.c.do(ctx, "POST", "/api/exam/complete", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GiveUp 放弃考试
+func (s *ExamService) GiveUp(ctx context.Context, req GiveUpExamRequest) (*Exam, error) {
+	var out *Exam
+	if err := s.c.do(ctx, "POST", "/api/exam/give_up", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// List 考试记录
+func (s *ExamService) List(ctx context.Context, req FetchExamListRequest) (*ExamList, error) {
+	var out *ExamList
+	if err := s.c.do(ctx, "POST", "/api/exam/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Profile 考试详情
+func (s *ExamService) Profile(ctx context.Context, req FetchExamProfileRequest) (*ExamDetailResponse, error) {
+	var out *ExamDetailResponse
+	if err := s.c.do(ctx, "POST", "/api/exam/profile", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Result 考试结果
+func (s *ExamService) Result(ctx context.Context, req FetchExamResultRequest) (*ExamDetailResponse, error) {
+	var out *ExamDetailResponse
+	if err := s.c.do(ctx, "POST", "/api/exam/result", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Running 进行中的考试
+func (s *ExamService) Running(ctx context.Context) (*FetchRunningExamResponse, error) {
+	var out *FetchRunningExamResponse
+	if err := s.c.do(ctx, "POST", "/api/exam/running", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Start 开始考试
+func (s *ExamService) Start(ctx context.Context, req StartExamWithPaperRequest) (*Exam, error) {
+	var out *Exam
+	if err := s.c.do(ctx, "POST", "/api/exam/start", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FavoriteService favorite 相关的接口
+type FavoriteService struct {
+	c *Client
+}
+
+// Create 收藏
+func (s *FavoriteService) Create(ctx context.Context, req CreateFavoriteRequest) (*FavoriteResponse, error) {
+	var out *FavoriteResponse
+	if err := s.c.do(ctx, "POST", "/api/favorite/create", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Delete 取消收藏
+func (s *FavoriteService) Delete(ctx context.Context, req DeleteFavoriteRequest) (*FavoriteResponse, error) {
+	var out *FavoriteResponse
+	if err := s.c.do(ctx, "POST", "/api/favorite/delete", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// List 收藏列表
+func (s *FavoriteService) List(ctx context.Context, req FetchFavoriteListRequest) (*FavoriteItemList, error) {
+	var out *FavoriteItemList
+	if err := s.c.do(ctx, "POST", "/api/favorite/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FeedService feed 相关的接口
+type FeedService struct {
+	c *Client
+}
+
+// List 关注的教练的动态
+func (s *FeedService) List(ctx context.Context, req FetchFeedListRequest) (*FeedItemList, error) {
+	var out *FeedItemList
+	if err := s.c.do(ctx, "POST", "/api/feed/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FollowService follow 相关的接口
+type FollowService struct {
+	c *Client
+}
+
+// Block 拉黑，同时解除双方的关注
+func (s *FollowService) Block(ctx context.Context, req BlockCoachRequest) error {
+	return s.c.do(ctx, "POST", "/api/block", req, nil)
+}
+
+// Follow 我关注别人
+func (s *FollowService) Follow(ctx context.Context, req FollowCoachRequest) error {
+	return s.c.do(ctx, "POST", "/api/follow", req, nil)
+}
+
+// SuggestionList 推荐关注，有共同学员的教练，以及练过或者收藏过的训练计划的作者
+func (s *FollowService) SuggestionList(ctx context.Context, req FetchFollowSuggestionListRequest) (*FetchFollowSuggestionListResponse, error) {
+	var out *FetchFollowSuggestionListResponse
+	if err := s.c.do(ctx, "POST", "/api/follow/suggestion_list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MyBlockList 获取我拉黑的人列表
+func (s *FollowService) MyBlockList(ctx context.Context, req FetchMyBlockListRequest) (*BlockItemList, error) {
+	var out *BlockItemList
+	if err := s.c.do(ctx, "POST", "/api/my/block/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MyFollowerList 获取我的关注者列表
+func (s *FollowService) MyFollowerList(ctx context.Context, req FetchMyFollowerListRequest) (*FollowItemList, error) {
+	var out *FollowItemList
+	if err := s.c.do(ctx, "POST", "/api/my/follower/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MyFollowingList 获取我关注的人列表
+func (s *FollowService) MyFollowingList(ctx context.Context, req FetchMyFollowingListRequest) (*FollowItemList, error) {
+	var out *FollowItemList
+	if err := s.c.do(ctx, "POST", "/api/my/following/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Unblock 取消拉黑，之前的关注不会恢复
+func (s *FollowService) Unblock(ctx context.Context, req UnblockCoachRequest) error {
+	return s.c.do(ctx, "POST", "/api/unblock", req, nil)
+}
+
+// Unfollow 我取消关注别人
+func (s *FollowService) Unfollow(ctx context.Context, req UnFollowCoachRequest) error {
+	return s.c.do(ctx, "POST", "/api/unfollow", req, nil)
+}
+
+// FriendService friend 相关的接口
+type FriendService struct {
+	c *Client
+}
+
+// Add 添加好友
+func (s *FriendService) Add(ctx context.Context, req AddFriendRequest) error {
+	return s.c.do(ctx, "POST", "/api/friend/add", req, nil)
+}
+
+// GiftCardService gift_card 相关的接口
+type GiftCardService struct {
+	c *Client
+}
+
+// Create 创建兑换码
+func (s *GiftCardService) Create(ctx context.Context, req CreateGiftCardRequest) error {
+	return s.c.do(ctx, "POST", "/api/gift_card/create", req, nil)
+}
+
+// CreateReward 创建兑换奖励
+func (s *GiftCardService) CreateReward(ctx context.Context, req CreateGiftCardRewardRequest) error {
+	return s.c.do(ctx, "POST", "/api/gift_card/create_reward", req, nil)
+}
+
+// List 兑换码列表
+func (s *GiftCardService) List(ctx context.Context, req FetchGiftCardListRequest) (*GiftCardList, error) {
+	var out *GiftCardList
+	if err := s.c.do(ctx, "POST", "/api/gift_card/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Profile 兑换码详情
+func (s *GiftCardService) Profile(ctx context.Context, req FetchGiftCardProfileRequest) (*FetchGiftCardProfileResponse, error) {
+	var out *FetchGiftCardProfileResponse
+	if err := s.c.do(ctx, "POST", "/api/gift_card/profile", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RewardList 兑换奖励列表
+func (s *GiftCardService) RewardList(ctx context.Context, req FetchGiftCardRewardListRequest) (*GiftCardRewardList, error) {
+	var out *GiftCardRewardList
+	if err := s.c.do(ctx, "POST", "/api/gift_card/reward_list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Send 赠送礼品卡
+func (s *GiftCardService) Send(ctx context.Context, req SendGiftCardRequest) error {
+	return s.c.do(ctx, "POST", "/api/gift_card/send", req, nil)
+}
+
+// Using 使用兑换码
+func (s *GiftCardService) Using(ctx context.Context, req UsingGiftCardRequest) error {
+	return s.c.do(ctx, "POST", "/api/gift_card/using", req, nil)
+}
+
+// InviteService invite 相关的接口
+type InviteService struct {
+	c *Client
+}
+
+// Accept 接受邀请
+func (s *InviteService) Accept(ctx context.Context, req AcceptInviteRequest) (*AcceptInviteResponse, error) {
+	var out *AcceptInviteResponse
+	if err := s.c.do(ctx, "POST", "/api/invite/accept", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Create 创建邀请
+func (s *InviteService) Create(ctx context.Context, req CreateInviteRequest) (*CreateInviteResponse, error) {
+	var out *CreateInviteResponse
+	if err := s.c.do(ctx, "POST", "/api/invite/create", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// List 我的邀请
+func (s *InviteService) List(ctx context.Context, req FetchInviteListRequest) (*InviteItemList, error) {
+	var out *InviteItemList
+	if err := s.c.do(ctx, "POST", "/api/invite/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Profile 受邀人打开邀请时查看
+func (s *InviteService) Profile(ctx context.Context, req FetchInviteProfileRequest) (*FetchInviteProfileResponse, error) {
+	var out *FetchInviteProfileResponse
+	if err := s.c.do(ctx, "POST", "/api/invite/profile", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Reject 拒绝邀请
+func (s *InviteService) Reject(ctx context.Context, req RejectInviteRequest) error {
+	return s.c.do(ctx, "POST", "/api/invite/reject", req, nil)
+}
+
+// Revoke 撤回邀请
+func (s *InviteService) Revoke(ctx context.Context, req RevokeInviteRequest) error {
+	return s.c.do(ctx, "POST", "/api/invite/revoke", req, nil)
+}
+
+// MediaService media 相关的接口
+type MediaService struct {
+	c *Client
+}
+
+// Create 上传媒体资源后保存记录
+func (s *MediaService) Create(ctx context.Context, req CreateMediaResourceRequest) (*MediaResource, error) {
+	var out *MediaResource
+	if err := s.c.do(ctx, "POST", "/api/media/create", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Delete 删除媒体资源
+func (s *MediaService) Delete(ctx context.Context, req DeleteMediaResourceRequest) error {
+	return s.c.do(ctx, "POST", "/api/media/delete", req, nil)
+}
+
+// List 媒体资源列表
+func (s *MediaService) List(ctx context.Context, req FetchMediaResourceListRequest) (*MediaResourceList, error) {
+	var out *MediaResourceList
+	if err := s.c.do(ctx, "POST", "/api/media/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// QiniuToken 七牛上传凭证
+func (s *MediaService) QiniuToken(ctx context.Context) (*QiniuTokenResponse, error) {
+	var out *QiniuTokenResponse
+	if err := s.c.do(ctx, "POST", "/api/media/qiniu_token", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MuscleService muscle 相关的接口
+type MuscleService struct {
+	c *Client
+}
+
+// Create 创建肌肉
+func (s *MuscleService) Create(ctx context.Context, req CreateMuscleRequest) error {
+	return s.c.do(ctx, "POST", "/api/muscle/create", req, nil)
+}
+
+// Delete 删除肌肉
+func (s *MuscleService) Delete(ctx context.Context, req DeleteMuscleRequest) error {
+	return s.c.do(ctx, "POST", "/api/muscle/delete", req, nil)
+}
+
+// List 肌肉列表
+func (s *MuscleService) List(ctx context.Context, req FetchMuscleListRequest) (*MuscleList, error) {
+	var out *MuscleList
+	if err := s.c.do(ctx, "POST", "/api/muscle/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Profile 肌肉详情
+func (s *MuscleService) Profile(ctx context.Context, req FetchMuscleProfileRequest) (*Muscle, error) {
+	var out *Muscle
+	if err := s.c.do(ctx, "POST", "/api/muscle/profile", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Update 更新肌肉
+func (s *MuscleService) Update(ctx context.Context, req UpdateMuscleRequest) error {
+	return s.c.do(ctx, "POST", "/api/muscle/update", req, nil)
+}
+
+// PaperService paper 相关的接口
+type PaperService struct {
+	c *Client
+}
+
+// Create 创建试卷
+func (s *PaperService) Create(ctx context.Context, req CreatePaperRequest) (*Paper, error) {
+	var out *Paper
+	if err := s.c.do(ctx, "POST", "/api/paper/create", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// List 试卷列表
+func (s *PaperService) List(ctx context.Context, req FetchPaperListRequest) (*PaperList, error) {
+	var out *PaperList
+	if err := s.c.do(ctx, "POST", "/api/paper/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Profile 试卷详情
+func (s *PaperService) Profile(ctx context.Context, req FetchPaperProfileRequest) (*FetchPaperProfileResponse, error) {
+	var out *FetchPaperProfileResponse
+	if err := s.c.do(ctx, "POST", "/api/paper/profile", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Update 更新试卷
+func (s *PaperService) Update(ctx context.Context, req UpdatePaperRequest) (*Paper, error) {
+	var out *Paper
+	if err := s.c.do(ctx, "POST", "/api/paper/update", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// QuizService quiz 相关的接口
+type QuizService struct {
+	c *Client
+}
+
+// Create 创建题目
+func (s *QuizService) Create(ctx context.Context, req CreateQuizRequest) (*Quiz, error) {
+	var out *Quiz
+	if err := s.c.do(ctx, "POST", "/api/quiz/create", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// List 题目列表
+func (s *QuizService) List(ctx context.Context, req FetchQuizListRequest) (*QuizList, error) {
+	var out *QuizList
+	if err := s.c.do(ctx, "POST", "/api/quiz/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RelationshipService relationship 相关的接口
+type RelationshipService struct {
+	c *Client
+}
+
+// Accept 确认对方发起的关系，比如好友申请
+func (s *RelationshipService) Accept(ctx context.Context, req AcceptRelationshipRequest) error {
+	return s.c.do(ctx, "POST", "/api/relationship/accept", req, nil)
+}
+
+// Dismiss 任意一方都可以解除关系，训练记录保留
+func (s *RelationshipService) Dismiss(ctx context.Context, req DismissRelationshipRequest) error {
+	return s.c.do(ctx, "POST", "/api/relationship/dismiss", req, nil)
+}
+
+// Reject 拒绝建立关系
+func (s *RelationshipService) Reject(ctx context.Context, req RejectRelationshipRequest) error {
+	return s.c.do(ctx, "POST", "/api/relationship/reject", req, nil)
+}
+
+// ReportService report 相关的接口
+type ReportService struct {
+	c *Client
+}
+
+// Create 举报
+func (s *ReportService) Create(ctx context.Context, req CreateReportRequest) (*CoachReport, error) {
+	var out *CoachReport
+	if err := s.c.do(ctx, "POST", "/api/report/create", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// List 举报列表
+func (s *ReportService) List(ctx context.Context, req FetchReportListRequest) (*CoachReportList, error) {
+	var out *CoachReportList
+	if err := s.c.do(ctx, "POST", "/api/report/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListOfMine 我的举报
+func (s *ReportService) ListOfMine(ctx context.Context, req FetchMineReportListRequest) (*CoachReportList, error) {
+	var out *CoachReportList
+	if err := s.c.do(ctx, "POST", "/api/report/list_of_mine", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Profile 举报详情
+func (s *ReportService) Profile(ctx context.Context, req FetchReportProfileRequest) (*CoachReport, error) {
+	var out *CoachReport
+	if err := s.c.do(ctx, "POST", "/api/report/profile", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StatsService stats 相关的接口
+type StatsService struct {
+	c *Client
+}
+
+// RefreshWorkoutStats 刷新训练统计
+func (s *StatsService) RefreshWorkoutStats(ctx context.Context, req RefreshCoachStatsRequest) (*RefreshCoachStatsResponse, error) {
+	var out *RefreshCoachStatsResponse
+	if err := s.c.do(ctx, "POST", "/api/refresh_workout_stats", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TodayWorkout 刷新当日的训练总结
+func (s *StatsService) TodayWorkout(ctx context.Context, req RefreshTodayWorkoutStatsRequest) (*RefreshTodayWorkoutStatsResponse, error) {
+	var out *RefreshTodayWorkoutStatsResponse
+	if err := s.c.do(ctx, "POST", "/api/today_workout", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StudentService student 相关的接口
+type StudentService struct {
+	c *Client
+}
+
+// AuthURL 学员的登录链接
+func (s *StudentService) AuthURL(ctx context.Context, req BuildStudentAuthURLRequest) (*AuthURLResponse, error) {
+	var out *AuthURLResponse
+	if err := s.c.do(ctx, "POST", "/api/student/auth_url", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Create 创建学员
+func (s *StudentService) Create(ctx context.Context, req CreateStudentRequest) (*IdResponse, error) {
+	var out *IdResponse
+	if err := s.c.do(ctx, "POST", "/api/student/create", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Delete 删除学员
+func (s *StudentService) Delete(ctx context.Context, req DeleteStudentRequest) error {
+	return s.c.do(ctx, "POST", "/api/student/delete", req, nil)
+}
+
+// List 学员列表
+func (s *StudentService) List(ctx context.Context, req FetchStudentListRequest) (*StudentItemList, error) {
+	var out *StudentItemList
+	if err := s.c.do(ctx, "POST", "/api/student/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Profile 学员详情
+func (s *StudentService) Profile(ctx context.Context, req FetchStudentProfileRequest) (*StudentProfile, error) {
+	var out *StudentProfile
+	if err := s.c.do(ctx, "POST", "/api/student/profile", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ToFriend 学员转为好友
+func (s *StudentService) ToFriend(ctx context.Context, req StudentToFriendRequest) error {
+	return s.c.do(ctx, "POST", "/api/student/to_friend", req, nil)
+}
+
+// Update 更新学员资料
+func (s *StudentService) Update(ctx context.Context, req UpdateStudentProfileRequest) error {
+	return s.c.do(ctx, "POST", "/api/student/update", req, nil)
+}
+
+// URLWithToken 删除学员（旧接口）
+func (s *StudentService) URLWithToken(ctx context.Context, req DeleteStudentRequest) error {
+	return s.c.do(ctx, "POST", "/api/student/url_with_token", req, nil)
+}
+
+// WorkoutActionHistoryList 学员训练日的动作记录
+func (s *StudentService) WorkoutActionHistoryList(ctx context.Context, req FetchStudentWorkoutActionHistoryListOfWorkoutDayRequest) (*WorkoutActionHistoryList, error) {
+	var out *WorkoutActionHistoryList
+	if err := s.c.do(ctx, "POST", "/api/student/workout_action_history/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WorkoutDayList 学员的训练日列表
+func (s *StudentService) WorkoutDayList(ctx context.Context, req FetchMyStudentWorkoutDayListRequest) (*WorkoutDayItemList, error) {
+	var out *WorkoutDayItemList
+	if err := s.c.do(ctx, "POST", "/api/student/workout_day/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WorkoutDayProfile 学员的训练日详情
+func (s *StudentService) WorkoutDayProfile(ctx context.Context, req FetchStudentWorkoutDayProfileRequest) (*WorkoutDayProfile, error) {
+	var out *WorkoutDayProfile
+	if err := s.c.do(ctx, "POST", "/api/student/workout_day/profile", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WorkoutDayResult 获取学员/好友训练记录
+func (s *StudentService) WorkoutDayResult(ctx context.Context, req FetchStudentWorkoutDayResultRequest) (*WorkoutDayResult, error) {
+	var out *WorkoutDayResult
+	if err := s.c.do(ctx, "POST", "/api/student/workout_day/result", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SubscriptionService subscription 相关的接口
+type SubscriptionService struct {
+	c *Client
+}
+
+// List 我的订阅
+func (s *SubscriptionService) List(ctx context.Context, req FetchSubscriptionListRequest) (*SubscriptionList, error) {
+	var out *SubscriptionList
+	if err := s.c.do(ctx, "POST", "/api/subscription/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SubscriptionOrderService subscription_order 相关的接口
+type SubscriptionOrderService struct {
+	c *Client
+}
+
+// Calc 计算订阅金额
+func (s *SubscriptionOrderService) Calc(ctx context.Context, req CalcSubscriptionOrderAmountRequest) (*CalcSubscriptionOrderAmountResponse, error) {
+	var out *CalcSubscriptionOrderAmountResponse
+	if err := s.c.do(ctx, "POST", "/api/subscription_order/calc", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SubscriptionPlanService subscription_plan 相关的接口
+type SubscriptionPlanService struct {
+	c *Client
+}
+
+// Create 创建订阅方案
+func (s *SubscriptionPlanService) Create(ctx context.Context, req CreateSubscriptionPlanRequest) (*SubscriptionPlan, error) {
+	var out *SubscriptionPlan
+	if err := s.c.do(ctx, "POST", "/api/subscription_plan/create", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// List 订阅方案列表
+func (s *SubscriptionPlanService) List(ctx context.Context, req FetchSubscriptionPlanListRequest) (*FetchSubscriptionPlanListResponse, error) {
+	var out *FetchSubscriptionPlanListResponse
+	if err := s.c.do(ctx, "POST", "/api/subscription_plan/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SystemService system 相关的接口
+type SystemService struct {
+	c *Client
+}
+
+// Ping 服务版本
+func (s *SystemService) Ping(ctx context.Context) (*FetchVersionResponse, error) {
+	var out *FetchVersionResponse
+	if err := s.c.do(ctx, "GET", "/api/ping", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WorkoutActionService workout_action 相关的接口
+type WorkoutActionService struct {
+	c *Client
+}
+
+// ContentCreate 给动作创建内容
+func (s *WorkoutActionService) ContentCreate(ctx context.Context, req CreateContentWithWorkoutActionRequest) error {
+	return s.c.do(ctx, "POST", "/api/workout_action/content/create", req, nil)
+}
+
+// ContentList 动作的内容列表
+func (s *WorkoutActionService) ContentList(ctx context.Context, req FetchContentListOfWorkoutActionRequest) (*WorkoutActionContentItemList, error) {
+	var out *WorkoutActionContentItemList
+	if err := s.c.do(ctx, "POST", "/api/workout_action/content/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Create 创建动作
+func (s *WorkoutActionService) Create(ctx context.Context, req WorkoutActionBody) (*WorkoutActionBody, error) {
+	var out *WorkoutActionBody
+	if err := s.c.do(ctx, "POST", "/api/workout_action/create", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Delete 删除动作
+func (s *WorkoutActionService) Delete(ctx context.Context, req DeleteWorkoutActionRequest) error {
+	return s.c.do(ctx, "POST", "/api/workout_action/delete", req, nil)
+}
+
+// List 动作列表
+func (s *WorkoutActionService) List(ctx context.Context, req FetchWorkoutActionListRequest) (*WorkoutActionListItemList, error) {
+	var out *WorkoutActionListItemList
+	if err := s.c.do(ctx, "POST", "/api/workout_action/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListByLevel 指定难度的动作
+func (s *WorkoutActionService) ListByLevel(ctx context.Context) ([]WorkoutAction, error) {
+	var out []WorkoutAction
+	if err := s.c.do(ctx, "POST", "/api/workout_action/list/by_level", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListByMuscle 锻炼指定肌肉的动作
+func (s *WorkoutActionService) ListByMuscle(ctx context.Context) ([]WorkoutAction, error) {
+	var out []WorkoutAction
+	if err := s.c.do(ctx, "POST", "/api/workout_action/list/by_muscle", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListCardio 有氧动作列表
+func (s *WorkoutActionService) ListCardio(ctx context.Context, req FetchCardioWorkoutActionListRequest) (*WorkoutActionListItemList, error) {
+	var out *WorkoutActionListItemList
+	if err := s.c.do(ctx, "POST", "/api/workout_action/list/cardio", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListRelated 获取指定动作的 进阶、退阶、替代动作
+func (s *WorkoutActionService) ListRelated(ctx context.Context, req FetchRelatedWorkoutActionsRequest) (*FetchRelatedWorkoutActionsResponse, error) {
+	var out *FetchRelatedWorkoutActionsResponse
+	if err := s.c.do(ctx, "POST", "/api/workout_action/list/related", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListByIDs 根据 id 获取动作
+func (s *WorkoutActionService) ListByIDs(ctx context.Context, req FetchWorkoutActionListByIdsRequest) (*FetchWorkoutActionListByIdsResponse, error) {
+	var out *FetchWorkoutActionListByIdsResponse
+	if err := s.c.do(ctx, "POST", "/api/workout_action/list_by_ids", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Profile 动作详情
+func (s *WorkoutActionService) Profile(ctx context.Context, req GetWorkoutActionRequest) (*WorkoutAction, error) {
+	var out *WorkoutAction
+	if err := s.c.do(ctx, "POST", "/api/workout_action/profile", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Update 更新动作
+func (s *WorkoutActionService) Update(ctx context.Context, req UpdateWorkoutActionProfileRequest) (*UpdateWorkoutActionProfileRequest, error) {
+	var out *UpdateWorkoutActionProfileRequest
+	if err := s.c.do(ctx, "POST", "/api/workout_action/update", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateIdx 更新动作排序
+func (s *WorkoutActionService) UpdateIdx(ctx context.Context, req UpdateWorkoutActionIdxRequest) (*UpdateWorkoutActionIdxRequest, error) {
+	var out *UpdateWorkoutActionIdxRequest
+	if err := s.c.do(ctx, "POST", "/api/workout_action/update_idx", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WorkoutActionHistoryService workout_action_history 相关的接口
+type WorkoutActionHistoryService struct {
+	c *Client
+}
+
+// Create 记录动作
+func (s *WorkoutActionHistoryService) Create(ctx context.Context, req CreateWorkoutHistoryRequest) error {
+	return s.c.do(ctx, "POST", "/api/workout_action_history/create", req, nil)
+}
+
+// ListOfWorkoutAction 获取健身动作历史记录
+func (s *WorkoutActionHistoryService) ListOfWorkoutAction(ctx context.Context, req FetchWorkoutActionHistoryListOfWorkoutActionRequest) (*WorkoutActionHistoryList, error) {
+	var out *WorkoutActionHistoryList
+	if err := s.c.do(ctx, "POST", "/api/workout_action_history/list_of_workout_action", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListOfWorkoutDay 训练日的动作记录
+func (s *WorkoutActionHistoryService) ListOfWorkoutDay(ctx context.Context, req FetchWorkoutActionHistoryListOfWorkoutDayRequest) (*WorkoutActionHistoryList, error) {
+	var out *WorkoutActionHistoryList
+	if err := s.c.do(ctx, "POST", "/api/workout_action_history/list_of_workout_day", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WorkoutDayService workout_day 相关的接口
+type WorkoutDayService struct {
+	c *Client
+}
+
+// Continue 继续训练
+func (s *WorkoutDayService) Continue(ctx context.Context, req ContinueWorkoutDayRequest) (*IdResponse, error) {
+	var out *IdResponse
+	if err := s.c.do(ctx, "POST", "/api/workout_day/continue", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Create 创建训练日
+func (s *WorkoutDayService) Create(ctx context.Context, req CreateWorkoutDayRequest) (*CreateWorkoutDayResponse, error) {
+	var out *CreateWorkoutDayResponse
+	if err := s.c.do(ctx, "POST", "/api/workout_day/create", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateFree 用于创建一个已经完成的训练，比如有氧
+func (s *WorkoutDayService) CreateFree(ctx context.Context, req CreateFreeWorkoutDayRequest) (*IdResponse, error) {
+	var out *IdResponse
+	if err := s.c.do(ctx, "POST", "/api/workout_day/create_free", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Delete 删除训练日
+func (s *WorkoutDayService) Delete(ctx context.Context, req DeleteWorkoutDayRequest) error {
+	return s.c.do(ctx, "POST", "/api/workout_day/delete", req, nil)
+}
+
+// Finish 完成训练
+func (s *WorkoutDayService) Finish(ctx context.Context, req FinishWorkoutDayRequest) (*IdResponse, error) {
+	var out *IdResponse
+	if err := s.c.do(ctx, "POST", "/api/workout_day/finish", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FinishedList 似乎废弃了，使用 FetchWorkoutDayList 替代
+func (s *WorkoutDayService) FinishedList(ctx context.Context, req FetchFinishedWorkoutDayListRequest) (*FetchFinishedWorkoutDayListResponse, error) {
+	var out *FetchFinishedWorkoutDayListResponse
+	if err := s.c.do(ctx, "POST", "/api/workout_day/finished_list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GiveUp 放弃训练
+func (s *WorkoutDayService) GiveUp(ctx context.Context, req GiveUpWorkoutDayRequest) (*IdResponse, error) {
+	var out *IdResponse
+	if err := s.c.do(ctx, "POST", "/api/workout_day/give_up", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HasStarted 查看是否有进行中的训练，仅获取少量数据
+func (s *WorkoutDayService) HasStarted(ctx context.Context) (*CheckHasStartedWorkoutDayResponse, error) {
+	var out *CheckHasStartedWorkoutDayResponse
+	if err := s.c.do(ctx, "POST", "/api/workout_day/has_started", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// List 训练日列表
+func (s *WorkoutDayService) List(ctx context.Context, req FetchWorkoutDayListRequest) (*WorkoutDayItemList, error) {
+	var out *WorkoutDayItemList
+	if err := s.c.do(ctx, "POST", "/api/workout_day/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Profile 获取训练计划记录 获取自己的和当时一起训练好友、学员的
+func (s *WorkoutDayService) Profile(ctx context.Context, req FetchWorkoutDayProfileRequest) (*WorkoutDayProfile, error) {
+	var out *WorkoutDayProfile
+	if err := s.c.do(ctx, "POST", "/api/workout_day/profile", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Result 获取训练计划记录结果 只能获取自己的
+func (s *WorkoutDayService) Result(ctx context.Context, req FetchWorkoutDayResultRequest) (*WorkoutDayResult, error) {
+	var out *WorkoutDayResult
+	if err := s.c.do(ctx, "POST", "/api/workout_day/result", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Share 分享已完成的训练到动态，关注我的人能看到
+func (s *WorkoutDayService) Share(ctx context.Context, req ShareWorkoutDayRequest) (*ShareWorkoutDayResponse, error) {
+	var out *ShareWorkoutDayResponse
+	if err := s.c.do(ctx, "POST", "/api/workout_day/share", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Start 基本上用不上，都是用 createWorkoutDay
+func (s *WorkoutDayService) Start(ctx context.Context, req StartWorkoutDayRequest) (*IdResponse, error) {
+	var out *IdResponse
+	if err := s.c.do(ctx, "POST", "/api/workout_day/start", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StartedList 进行中的训练日
+func (s *WorkoutDayService) StartedList(ctx context.Context) (*FetchStartedWorkoutDayResponse, error) {
+	var out *FetchStartedWorkoutDayResponse
+	if err := s.c.do(ctx, "POST", "/api/workout_day/started_list", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Update 更新训练日
+func (s *WorkoutDayService) Update(ctx context.Context, req UpdateWorkoutDayRequest) (*IdResponse, error) {
+	var out *IdResponse
+	if err := s.c.do(ctx, "POST", "/api/workout_day/update", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateDetails 更新训练日的计划内容
+func (s *WorkoutDayService) UpdateDetails(ctx context.Context, req UpdateWorkoutDayPlanDetailsRequest) (*IdResponse, error) {
+	var out *IdResponse
+	if err := s.c.do(ctx, "POST", "/api/workout_day/update_details", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpdateSteps 暂存的训练内容记录
+func (s *WorkoutDayService) UpdateSteps(ctx context.Context, req UpdateWorkoutDayStepProgressRequest) (*IdResponse, error) {
+	var out *IdResponse
+	if err := s.c.do(ctx, "POST", "/api/workout_day/update_steps", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WorkoutPlanService workout_plan 相关的接口
+type WorkoutPlanService struct {
+	c *Client
+}
+
+// ContentCreate 给训练计划创建内容
+func (s *WorkoutPlanService) ContentCreate(ctx context.Context, req CreateContentWithWorkoutPlanRequest) error {
+	return s.c.do(ctx, "POST", "/api/workout_plan/content/create", req, nil)
+}
+
+// ContentList 训练计划的内容列表
+func (s *WorkoutPlanService) ContentList(ctx context.Context, req FetchContentListOfWorkoutPlanRequest) (*WorkoutPlanContentItemList, error) {
+	var out *WorkoutPlanContentItemList
+	if err := s.c.do(ctx, "POST", "/api/workout_plan/content/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ContentProfile 训练计划的内容详情
+func (s *WorkoutPlanService) ContentProfile(ctx context.Context, req FetchContentProfileOfWorkoutPlanRequest) (*WorkoutPlanContentProfile, error) {
+	var out *WorkoutPlanContentProfile
+	if err := s.c.do(ctx, "POST", "/api/workout_plan/content/profile", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Create 创建训练计划
+func (s *WorkoutPlanService) Create(ctx context.Context, req CreateWorkoutPlanRequest) (*IdResponse, error) {
+	var out *IdResponse
+	if err := s.c.do(ctx, "POST", "/api/workout_plan/create", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Delete 删除训练计划
+func (s *WorkoutPlanService) Delete(ctx context.Context, req DeleteWorkoutPlanRequest) error {
+	return s.c.do(ctx, "POST", "/api/workout_plan/delete", req, nil)
+}
+
+// List 训练计划列表
+func (s *WorkoutPlanService) List(ctx context.Context, req FetchWorkoutPlanListRequest) (*WorkoutPlanItemList, error) {
+	var out *WorkoutPlanItemList
+	if err := s.c.do(ctx, "POST", "/api/workout_plan/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Mine 我的训练计划
+func (s *WorkoutPlanService) Mine(ctx context.Context) (*WorkoutPlanList, error) {
+	var out *WorkoutPlanList
+	if err := s.c.do(ctx, "POST", "/api/workout_plan/mine", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Profile 训练计划详情
+func (s *WorkoutPlanService) Profile(ctx context.Context, req FetchWorkoutPlanProfileRequest) (*WorkoutPlanProfile, error) {
+	var out *WorkoutPlanProfile
+	if err := s.c.do(ctx, "POST", "/api/workout_plan/profile", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Update 更新训练计划
+func (s *WorkoutPlanService) Update(ctx context.Context, req UpdateWorkoutPlanRequest) (*IdResponse, error) {
+	var out *IdResponse
+	if err := s.c.do(ctx, "POST", "/api/workout_plan/update", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WorkoutPlanSetService workout_plan_set 相关的接口
+type WorkoutPlanSetService struct {
+	c *Client
+}
+
+// Create 创建计划合集
+func (s *WorkoutPlanSetService) Create(ctx context.Context, req CreateWorkoutPlanSetRequest) (*IdResponse, error) {
+	var out *IdResponse
+	if err := s.c.do(ctx, "POST", "/api/workout_plan_set/create", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// List 计划合集列表
+func (s *WorkoutPlanSetService) List(ctx context.Context, req FetchWorkoutPlanSetListRequest) (*WorkoutPlanSetItemList, error) {
+	var out *WorkoutPlanSetItemList
+	if err := s.c.do(ctx, "POST", "/api/workout_plan_set/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Update 更新计划合集
+func (s *WorkoutPlanSetService) Update(ctx context.Context, req WorkoutPlanSet) (*IdResponse, error) {
+	var out *IdResponse
+	if err := s.c.do(ctx, "POST", "/api/workout_plan_set/update", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WorkoutScheduleService workout_schedule 相关的接口
+type WorkoutScheduleService struct {
+	c *Client
+}
+
+// Apply 应用某个周期计划
+func (s *WorkoutScheduleService) Apply(ctx context.Context, req ApplyWorkoutScheduleRequest) error {
+	return s.c.do(ctx, "POST", "/api/workout_schedule/apply", req, nil)
+}
+
+// Cancel 取消应用某个周期计划
+func (s *WorkoutScheduleService) Cancel(ctx context.Context, req CancelWorkoutScheduleRequest) error {
+	return s.c.do(ctx, "POST", "/api/workout_schedule/cancel", req, nil)
+}
+
+// Create 创建周期计划
+func (s *WorkoutScheduleService) Create(ctx context.Context, req CreateWorkoutScheduleRequest) (*IdResponse, error) {
+	var out *IdResponse
+	if err := s.c.do(ctx, "POST", "/api/workout_schedule/create", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Enabled 获取当前应用中的周期计划
+func (s *WorkoutScheduleService) Enabled(ctx context.Context) (*FetchAppliedWorkoutScheduleListResponse, error) {
+	var out *FetchAppliedWorkoutScheduleListResponse
+	if err := s.c.do(ctx, "POST", "/api/workout_schedule/enabled", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// List 周期计划列表
+func (s *WorkoutScheduleService) List(ctx context.Context, req FetchWorkoutScheduleListRequest) (*WorkoutScheduleItemList, error) {
+	var out *WorkoutScheduleItemList
+	if err := s.c.do(ctx, "POST", "/api/workout_schedule/list", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Profile 周期计划详情
+func (s *WorkoutScheduleService) Profile(ctx context.Context, req FetchWorkoutScheduleProfileRequest) (*WorkoutScheduleProfile, error) {
+	var out *WorkoutScheduleProfile
+	if err := s.c.do(ctx, "POST", "/api/workout_schedule/profile", req, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Update 更新周期计划
+func (s *WorkoutScheduleService) Update(ctx context.Context, req UpdateWorkoutScheduleRequest) error {
+	return s.c.do(ctx, "POST", "/api/workout_schedule/update", req, nil)
+}
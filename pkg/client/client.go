@@ -0,0 +1,283 @@
+// Package client 是 FitHub API 的 Go 客户端
+//
+// 接口方法、请求和响应的类型都是根据路由表生成的，修改路由之后在这个目录下执行 go generate 重新生成。
+package client
+
+//go:generate go run ../../cmd/sdkgen -o .
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	successCode      = 200
+	refreshTokenPath = "/api/auth/refresh_token"
+)
+
+// Error 服务端返回的错误，Code 对应服务端的错误码，可以和 ErrXxx 用 errors.Is 比较
+type Error struct {
+	Status int
+	Code   int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	if e.Msg == "" {
+		return fmt.Sprintf("client: code %d", e.Code)
+	}
+	return fmt.Sprintf("client: code %d, %s", e.Code, e.Msg)
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// MFAChallenge 开启了两步验证的帐号登录时返回，用 VerifyMFA 完成登录
+type MFAChallenge struct {
+	ChallengeToken string `json:"challenge_token"`
+	ExpiresAt      int64  `json:"expires_at"`
+}
+
+func (e *MFAChallenge) Error() string {
+	return "client: two-factor authentication required"
+}
+
+// Client 接口按路由分组，比如 c.WorkoutDay.List(ctx, req)
+// 登录、注册、刷新后返回的 token 会自动保存，access token 过期时用 refresh token 换新的再重试一次
+type Client struct {
+	services
+
+	base_url    string
+	http_client *http.Client
+	language    string
+	max_retries int
+	retry_wait  time.Duration
+
+	mu            sync.Mutex
+	access_token  string
+	refresh_token string
+	// 同一时间只刷新一次
+	refresh_mu sync.Mutex
+}
+
+type Option func(*Client)
+
+func WithHTTPClient(http_client *http.Client) Option {
+	return func(c *Client) {
+		c.http_client = http_client
+	}
+}
+
+// WithRetry 连不上服务时重试，只读之类的幂等接口遇到 502、503、504 也重试，每次等待的时间翻倍
+// 刷新 token 的接口不重试，refresh token 用过一次就失效了
+func WithRetry(max_retries int, wait time.Duration) Option {
+	return func(c *Client) {
+		c.max_retries = max_retries
+		c.retry_wait = wait
+	}
+}
+
+func WithTokens(access_token, refresh_token string) Option {
+	return func(c *Client) {
+		c.access_token = access_token
+		c.refresh_token = refresh_token
+	}
+}
+
+// WithLanguage 错误信息的语言，比如 en
+func WithLanguage(language string) Option {
+	return func(c *Client) {
+		c.language = language
+	}
+}
+
+// New 创建客户端，base_url 是服务的地址，比如 https://api.example.com
+func New(base_url string, opts ...Option) *Client {
+	c := &Client{
+		base_url:    strings.TrimRight(base_url, "/"),
+		http_client: http.DefaultClient,
+		max_retries: 2,
+		retry_wait:  200 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.services.init(c)
+	return c
+}
+
+// SetTokens 设置 token，access_token 要带上 Bearer 前缀，和接口返回的一样
+func (c *Client) SetTokens(access_token, refresh_token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.access_token = access_token
+	c.refresh_token = refresh_token
+}
+
+func (c *Client) Tokens() (access_token, refresh_token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.access_token, c.refresh_token
+}
+
+// Login 邮箱密码登录，开启了两步验证时返回 *MFAChallenge 错误
+func (c *Client) Login(ctx context.Context, email, password string) (*AuthResponse, error) {
+	auth, err := c.Auth.WebLogin(ctx, LoginCoachRequest{Email: email, Password: password})
+	if err != nil {
+		return nil, err
+	}
+	if auth.MFARequired {
+		return nil, &MFAChallenge{ChallengeToken: auth.ChallengeToken, ExpiresAt: auth.ExpiresAt}
+	}
+	return auth, nil
+}
+
+// VerifyMFA 登录的第二步
+func (c *Client) VerifyMFA(ctx context.Context, challenge *MFAChallenge, code string) (*AuthResponse, error) {
+	return c.Auth.TwoFAVerify(ctx, VerifyMFARequest{ChallengeToken: challenge.ChallengeToken, Code: code})
+}
+
+// download 直接返回文件内容的接口用，比如导出个人数据
+type download []byte
+
+type envelope struct {
+	Code int             `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+func (c *Client) do(ctx context.Context, method, path string, req, out interface{}) error {
+	token, _ := c.Tokens()
+	err := c.doWithRetry(ctx, method, path, req, out)
+	if path == refreshTokenPath || !(errors.Is(err, ErrTokenInvalid) || errors.Is(err, ErrTokenExpired)) {
+		return err
+	}
+	if refreshed, rerr := c.refresh(ctx, token); rerr != nil || !refreshed {
+		return err
+	}
+	return c.doWithRetry(ctx, method, path, req, out)
+}
+
+// refresh 用 refresh token 换新的 access token，其他请求已经换过了就直接用新的
+func (c *Client) refresh(ctx context.Context, stale string) (bool, error) {
+	c.refresh_mu.Lock()
+	defer c.refresh_mu.Unlock()
+	token, refresh_token := c.Tokens()
+	if token != stale {
+		return true, nil
+	}
+	if refresh_token == "" {
+		return false, nil
+	}
+	if _, err := c.Auth.RefreshToken(ctx, RefreshTokenRequest{RefreshToken: refresh_token}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *Client) doWithRetry(ctx context.Context, method, path string, req, out interface{}) error {
+	var body []byte
+	if req != nil {
+		var err error
+		if body, err = json.Marshal(req); err != nil {
+			return err
+		}
+	}
+	max_retries := c.max_retries
+	if path == refreshTokenPath {
+		max_retries = 0
+	}
+	wait := c.retry_wait
+	for attempt := 0; ; attempt++ {
+		err := c.send(ctx, method, path, body, out)
+		if attempt >= max_retries || !retryable(err, idempotent[path]) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// retryable 连接没建立起来时请求肯定没到服务端，都可以重试
+// 网关错误时请求可能已经执行过了，只有幂等的接口重试，业务错误和解析失败重试也没用
+func retryable(err error, idempotent bool) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var op *net.OpError
+	if errors.As(err, &op) && op.Op == "dial" {
+		return true
+	}
+	var e *Error
+	if idempotent && errors.As(err, &e) {
+		return e.Status == http.StatusBadGateway || e.Status == http.StatusServiceUnavailable || e.Status == http.StatusGatewayTimeout
+	}
+	return false
+}
+
+func (c *Client) send(ctx context.Context, method, path string, body []byte, out interface{}) error {
+	r, err := http.NewRequestWithContext(ctx, method, c.base_url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	if token, _ := c.Tokens(); token != "" {
+		r.Header.Set("Authorization", token)
+	}
+	if c.language != "" {
+		r.Header.Set("Accept-Language", c.language)
+	}
+	resp, err := c.http_client.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	// 下载文件的接口成功时直接返回文件内容，出错时和其他接口一样
+	if file, ok := out.(*download); ok && resp.StatusCode == http.StatusOK && !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		*file = raw
+		return nil
+	}
+	var result envelope
+	if err := json.Unmarshal(raw, &result); err != nil {
+		// 网关返回的错误页面之类的不是 JSON
+		if resp.StatusCode != http.StatusOK {
+			return &Error{Status: resp.StatusCode, Msg: http.StatusText(resp.StatusCode)}
+		}
+		return fmt.Errorf("client: decode %s: %w", path, err)
+	}
+	if result.Code != successCode {
+		return &Error{Status: resp.StatusCode, Code: result.Code, Msg: result.Msg}
+	}
+	if out == nil || len(result.Data) == 0 || string(result.Data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(result.Data, out); err != nil {
+		return fmt.Errorf("client: decode %s: %w", path, err)
+	}
+	// 注册、刷新、两步验证等接口返回新的会话，直接保存
+	if auth, ok := out.(**AuthResponse); ok && *auth != nil && (*auth).Token != "" {
+		c.SetTokens((*auth).Token, (*auth).RefreshToken)
+	}
+	return nil
+}
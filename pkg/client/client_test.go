@@ -0,0 +1,232 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"myapi/config"
+	"myapi/internal/api/routes"
+	"myapi/internal/db"
+	"myapi/pkg/client"
+	"myapi/pkg/logger"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{
+		DBType:         "sqlite",
+		DBPath:         filepath.Join(t.TempDir(), "test.db"),
+		MigrationsPath: "file://../../migrations",
+		TokenSecretKey: "test",
+		Environment:    "production",
+	}
+	l := logger.NewLogger("error")
+	if err := db.NewMigrator(cfg, l).MigrateUp(); err != nil {
+		t.Fatal(err)
+	}
+	database, err := db.NewDatabase(cfg, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(routes.SetupRouter(database, l, cfg))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newClient(t *testing.T, base_url string) *client.Client {
+	t.Helper()
+	c := client.New(base_url, client.WithRetry(2, time.Millisecond))
+	ctx := context.Background()
+	if _, err := c.Auth.WebRegister(ctx, client.RegisterCoachRequest{Email: "coach@example.com", Password: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestLoginAndTypedMethods(t *testing.T) {
+	server := newServer(t)
+	newClient(t, server.URL)
+	ctx := context.Background()
+
+	c := client.New(server.URL)
+	if _, err := c.Login(ctx, "coach@example.com", "wrong"); !errors.Is(err, client.ErrLoginFailed) {
+		t.Fatalf("want ErrLoginFailed, got %v", err)
+	}
+	if _, err := c.Login(ctx, "coach@example.com", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if token, refresh_token := c.Tokens(); token == "" || refresh_token == "" {
+		t.Fatal("tokens are not saved after login")
+	}
+
+	if err := c.Equipment.Create(ctx, client.CreateEquipmentRequest{Name: "sdk-barbell", ZhName: "杠铃", SortIdx: 10000}); err != nil {
+		t.Fatal(err)
+	}
+	list, err := c.Equipment.List(ctx, client.FetchEquipmentListRequest{Pagination: client.Pagination{Page: 1, PageSize: 10}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 迁移里有初始的器械数据，按 sort_idx 倒序排，新建的在第一个
+	if len(list.List) == 0 || list.List[0].Name != "sdk-barbell" {
+		t.Fatalf("created equipment is not in list: %+v", list.List)
+	}
+	equipment, err := c.Equipment.Profile(ctx, client.FetchEquipmentRequest{Id: int(list.List[0].Id)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if equipment.ZhName != "杠铃" {
+		t.Fatalf("want 杠铃, got %q", equipment.ZhName)
+	}
+
+	// 导出接口直接返回 zip 文件
+	data, err := c.Auth.Export(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("PK")) {
+		t.Fatalf("export is not a zip file: %q", data)
+	}
+
+	_, err = c.Equipment.Profile(ctx, client.FetchEquipmentRequest{Id: 9999})
+	var e *client.Error
+	if !errors.As(err, &e) || !errors.Is(err, client.ErrNotFound) || e.Status != http.StatusNotFound {
+		t.Fatalf("want ErrNotFound, got %v", err)
+	}
+}
+
+func TestRefreshExpiredToken(t *testing.T) {
+	server := newServer(t)
+	c := newClient(t, server.URL)
+	ctx := context.Background()
+
+	_, refresh_token := c.Tokens()
+	c.SetTokens("Bearer invalid", refresh_token)
+	if _, err := c.Auth.Profile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	token, next := c.Tokens()
+	if token == "Bearer invalid" || next == refresh_token {
+		t.Fatal("tokens are not refreshed")
+	}
+
+	// refresh token 也失效时返回原来的错误
+	c.SetTokens("Bearer invalid", "invalid")
+	if _, err := c.Auth.Profile(ctx); !errors.Is(err, client.ErrTokenInvalid) {
+		t.Fatalf("want ErrTokenInvalid, got %v", err)
+	}
+}
+
+// newProxy 转发到 server，fail 大于 0 时直接返回 503，calls 是收到的请求数
+func newProxy(t *testing.T, server *httptest.Server, fail, calls *int32) *httptest.Server {
+	t.Helper()
+	target, _ := url.Parse(server.URL)
+	forward := httputil.NewSingleHostReverseProxy(target)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		if atomic.AddInt32(fail, -1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		forward.ServeHTTP(w, r)
+	}))
+	t.Cleanup(proxy.Close)
+	return proxy
+}
+
+func TestRetryUnavailable(t *testing.T) {
+	server := newServer(t)
+	var fail, calls int32
+	proxy := newProxy(t, server, &fail, &calls)
+	c := client.New(proxy.URL, client.WithRetry(2, time.Millisecond), client.WithTokens(newClient(t, server.URL).Tokens()))
+	ctx := context.Background()
+
+	// 幂等的接口重试
+	atomic.StoreInt32(&fail, 1)
+	if _, err := c.Equipment.List(ctx, client.FetchEquipmentListRequest{Pagination: client.Pagination{Page: 1, PageSize: 10}}); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Fatalf("want 2 calls, got %d", atomic.LoadInt32(&calls))
+	}
+
+	// 写接口可能已经执行过了，不重试
+	atomic.StoreInt32(&fail, 1)
+	atomic.StoreInt32(&calls, 0)
+	err := c.Equipment.Create(ctx, client.CreateEquipmentRequest{Name: "sdk-barbell", ZhName: "杠铃"})
+	var e *client.Error
+	if !errors.As(err, &e) || e.Status != http.StatusServiceUnavailable {
+		t.Fatalf("want 503, got %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("want no retry, got %d calls", atomic.LoadInt32(&calls))
+	}
+
+	// refresh token 用过就失效，不重试
+	atomic.StoreInt32(&fail, 1)
+	atomic.StoreInt32(&calls, 0)
+	_, refresh_token := c.Tokens()
+	if _, err := c.Auth.RefreshToken(ctx, client.RefreshTokenRequest{RefreshToken: refresh_token}); !errors.As(err, &e) || e.Status != http.StatusServiceUnavailable {
+		t.Fatalf("want 503, got %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("want no retry, got %d calls", atomic.LoadInt32(&calls))
+	}
+
+	// 业务错误不重试
+	atomic.StoreInt32(&fail, 0)
+	atomic.StoreInt32(&calls, 0)
+	if _, err := c.Equipment.Profile(ctx, client.FetchEquipmentRequest{Id: 9999}); !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("want ErrNotFound, got %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("want no retry, got %d calls", atomic.LoadInt32(&calls))
+	}
+}
+
+func TestRetryDialError(t *testing.T) {
+	var dials int32
+	http_client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			atomic.AddInt32(&dials, 1)
+			return nil, &net.OpError{Op: "dial", Net: network, Err: errors.New("connection refused")}
+		},
+	}}
+	c := client.New("http://127.0.0.1:1", client.WithHTTPClient(http_client), client.WithRetry(2, time.Millisecond))
+	// 连接没建立起来，写接口也可以重试
+	if err := c.Auth.SendVerificationCode(context.Background(), client.SendVerificationCodeRequest{Email: "coach@example.com"}); err == nil {
+		t.Fatal("want dial error")
+	}
+	if atomic.LoadInt32(&dials) != 3 {
+		t.Fatalf("want 3 dials, got %d", atomic.LoadInt32(&dials))
+	}
+}
+
+func TestNoRetryOnDecodeError(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte("<html>ok</html>"))
+	}))
+	defer server.Close()
+
+	c := client.New(server.URL, client.WithRetry(2, time.Millisecond))
+	if _, err := c.Equipment.List(context.Background(), client.FetchEquipmentListRequest{}); err == nil {
+		t.Fatal("want decode error")
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Fatalf("want no retry, got %d calls", atomic.LoadInt32(&calls))
+	}
+}
@@ -0,0 +1,179 @@
+// Code generated by cmd/sdkgen. DO NOT EDIT.
+
+package client
+
+// 服务端定义的错误码，可以用 errors.Is(err, client.ErrNotFound) 判断
+var (
+	// 请求参数错误
+	ErrBadRequest = &Error{Status: 400, Code: 40000}
+	// 请求参数格式错误
+	ErrInvalidBody = &Error{Status: 400, Code: 40001}
+	// 缺少参数
+	ErrMissingParam = &Error{Status: 400, Code: 40002}
+	// 参数错误
+	ErrInvalidParam = &Error{Status: 400, Code: 40003}
+	// 非法操作
+	ErrIllegalOperation = &Error{Status: 400, Code: 40004}
+	// 名称包含敏感词
+	ErrSensitiveName = &Error{Status: 400, Code: 40005}
+	// 昵称包含敏感词
+	ErrSensitiveNickname = &Error{Status: 400, Code: 40006}
+	// 请输入邮箱
+	ErrEmailRequired = &Error{Status: 400, Code: 40007}
+	// 请输入密码
+	ErrPasswordRequired = &Error{Status: 400, Code: 40008}
+	// 请输入验证码
+	ErrCodeRequired = &Error{Status: 400, Code: 40009}
+	// 缺少标题
+	ErrTitleRequired = &Error{Status: 400, Code: 40010}
+	// 缺少昵称
+	ErrNicknameRequired = &Error{Status: 400, Code: 40011}
+	// 缺少内容
+	ErrContentRequired = &Error{Status: 400, Code: 40012}
+	// 请输入评论内容
+	ErrCommentRequired = &Error{Status: 400, Code: 40013}
+	// 请输入正确的手机号
+	ErrInvalidPhone = &Error{Status: 400, Code: 40014}
+	// 数量必须大于0
+	ErrInvalidAmount = &Error{Status: 400, Code: 40015}
+	// 内容包含敏感词
+	ErrSensitiveContent = &Error{Status: 400, Code: 40016}
+	// 时区不正确
+	ErrInvalidTimezone = &Error{Status: 400, Code: 40017}
+	// 请输入正确的邮箱
+	ErrInvalidEmail = &Error{Status: 400, Code: 40018}
+	// 验证码错误
+	ErrVerificationCodeInvalid = &Error{Status: 400, Code: 40020}
+	// 验证码已失效，请重新获取
+	ErrVerificationCodeExpired = &Error{Status: 400, Code: 40021}
+	// 密码错误
+	ErrPasswordIncorrect = &Error{Status: 400, Code: 40022}
+	// 邮箱或密码错误
+	ErrLoginFailed = &Error{Status: 400, Code: 40023}
+	// 未开启第三方登录
+	ErrOAuthDisabled = &Error{Status: 400, Code: 40024}
+	// 授权已失效，请重新授权
+	ErrOAuthExpired = &Error{Status: 400, Code: 40025}
+	// 授权失败，请重新授权
+	ErrOAuthFailed = &Error{Status: 400, Code: 40026}
+	// 至少保留一种登录方式
+	ErrLastAccount = &Error{Status: 400, Code: 40027}
+	// 管理员帐号不能注销
+	ErrAdminUndeletable = &Error{Status: 400, Code: 40028}
+	// 邀请已失效
+	ErrInviteInvalid = &Error{Status: 400, Code: 40040}
+	// 不能处理自己的邀请
+	ErrInviteSelf = &Error{Status: 400, Code: 40041}
+	// 无法接受该邀请
+	ErrInviteUnacceptable = &Error{Status: 400, Code: 40042}
+	// 不能关注自己
+	ErrFollowSelf = &Error{Status: 400, Code: 40043}
+	// 无法关注该用户
+	ErrCannotFollow = &Error{Status: 400, Code: 40044}
+	// 无法添加为好友
+	ErrCannotAddFriend = &Error{Status: 400, Code: 40045}
+	// 定时发布时间不能早于当前时间
+	ErrPublishTimeInvalid = &Error{Status: 400, Code: 40046}
+	// 请填写不通过的原因
+	ErrRejectReasonMissing = &Error{Status: 400, Code: 40047}
+	// 训练完成后才能分享
+	ErrShareUnfinished = &Error{Status: 400, Code: 40048}
+	// 至少选择一天配置训练计划
+	ErrScheduleEmpty = &Error{Status: 400, Code: 40049}
+	// 考试不在进行中
+	ErrExamNotInProgress = &Error{Status: 400, Code: 40050}
+	// 请先登录
+	ErrUnauthorized = &Error{Status: 401, Code: 40100}
+	// 缺少登录凭证
+	ErrTokenMissing = &Error{Status: 401, Code: 40101}
+	// 凭证失效请重新登录
+	ErrTokenInvalid = &Error{Status: 401, Code: 40102}
+	// 凭证过期请重新登录
+	ErrTokenExpired = &Error{Status: 401, Code: 40103}
+	// 链接已失效
+	ErrLinkExpired = &Error{Status: 401, Code: 40104}
+	// 没有权限
+	ErrForbidden = &Error{Status: 403, Code: 40300}
+	// 请先开启并完成两步验证
+	ErrMFARequired = &Error{Status: 403, Code: 40301}
+	// 该功能需订阅后才能使用
+	ErrSubscriptionRequired = &Error{Status: 403, Code: 40302}
+	// 没有找到记录
+	ErrNotFound = &Error{Status: 404, Code: 40400}
+	// 用户不存在
+	ErrUserNotFound = &Error{Status: 404, Code: 40401}
+	// 没有找到该学员
+	ErrStudentNotFound = &Error{Status: 404, Code: 40402}
+	// 邀请不存在
+	ErrInviteNotFound = &Error{Status: 404, Code: 40403}
+	// 考试不存在
+	ErrExamNotFound = &Error{Status: 404, Code: 40404}
+	// 试卷不存在
+	ErrPaperNotFound = &Error{Status: 404, Code: 40405}
+	// 答题记录不存在
+	ErrQuizAnswerNotFound = &Error{Status: 404, Code: 40406}
+	// 动作不存在
+	ErrWorkoutActionNotFound = &Error{Status: 404, Code: 40407}
+	// 训练计划不存在
+	ErrWorkoutPlanNotFound = &Error{Status: 404, Code: 40408}
+	// 周期计划不存在
+	ErrWorkoutScheduleNotFound = &Error{Status: 404, Code: 40409}
+	// 评论不存在
+	ErrCommentNotFound = &Error{Status: 404, Code: 40410}
+	// 收藏夹不存在
+	ErrFavoriteFolderNotFound = &Error{Status: 404, Code: 40411}
+	// 没有绑定该登录方式
+	ErrAccountNotBound = &Error{Status: 404, Code: 40412}
+	// 请先绑定邮箱
+	ErrEmailNotBound = &Error{Status: 404, Code: 40413}
+	// 请先获取密钥
+	ErrTOTPNotEnrolled = &Error{Status: 404, Code: 40414}
+	// 没有等待执行的注销申请
+	ErrDeletionNotFound = &Error{Status: 404, Code: 40415}
+	// 本月没有任何训练记录
+	ErrNoWorkoutThisMonth = &Error{Status: 404, Code: 40416}
+	// 该记录已存在
+	ErrAlreadyExists = &Error{Status: 409, Code: 40900}
+	// 该邮箱已被使用
+	ErrEmailUsed = &Error{Status: 409, Code: 40901}
+	// 已经关注了
+	ErrAlreadyFollowed = &Error{Status: 409, Code: 40902}
+	// 已经取消关注了
+	ErrNotFollowed = &Error{Status: 409, Code: 40903}
+	// 已经是好友了
+	ErrAlreadyFriend = &Error{Status: 409, Code: 40904}
+	// 已经建立过关系了
+	ErrRelationshipExists = &Error{Status: 409, Code: 40905}
+	// 已经补全过帐号了
+	ErrAccountCompleted = &Error{Status: 409, Code: 40906}
+	// 该帐号已绑定其他用户
+	ErrAccountBoundOther = &Error{Status: 409, Code: 40907}
+	// 已绑定过该登录方式，请先解绑
+	ErrProviderBound = &Error{Status: 409, Code: 40908}
+	// 已开启两步验证
+	ErrTOTPEnabled = &Error{Status: 409, Code: 40909}
+	// 兑换码已被使用
+	ErrGiftCardUsed = &Error{Status: 409, Code: 40910}
+	// 该周期计划已应用
+	ErrWorkoutScheduleApplied = &Error{Status: 409, Code: 40911}
+	// 已经审核通过了
+	ErrContentApproved = &Error{Status: 409, Code: 40912}
+	// 正在审核中
+	ErrContentInReview = &Error{Status: 409, Code: 40913}
+	// 该内容不在待审核状态
+	ErrContentNotPending = &Error{Status: 409, Code: 40914}
+	// 已存在同名动作
+	ErrWorkoutActionExists = &Error{Status: 409, Code: 40915}
+	// 尝试次数过多，请 N 秒后再试
+	ErrTooManyAttempts = &Error{Status: 429, Code: 42900}
+	// 验证码发送太频繁，请稍后再试
+	ErrVerificationCodeTooFrequent = &Error{Status: 429, Code: 42901}
+	// 请求太频繁，请 N 秒后再试
+	ErrRateLimited = &Error{Status: 429, Code: 42902}
+	// 服务器内部错误
+	ErrInternal = &Error{Status: 500, Code: 50000}
+	// 数据异常
+	ErrDataCorrupted = &Error{Status: 500, Code: 50001}
+	// 发送失败
+	ErrSendFailed = &Error{Status: 500, Code: 50002}
+)
@@ -0,0 +1,2385 @@
+// Code generated by cmd/sdkgen. DO NOT EDIT.
+
+package client
+
+import "time"
+
+type AcceptInviteRequest struct {
+	Code string `json:"code"`
+}
+
+type AcceptInviteResponse struct {
+	CoachId int `json:"coach_id"`
+}
+
+type AcceptRelationshipRequest struct {
+	Id int `json:"id"`
+}
+
+type AccountItem struct {
+	ProviderType int       `json:"provider_type"`
+	ProviderName string    `json:"provider_name"`
+	Name         string    `json:"name"`
+	Verified     bool      `json:"verified"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type ActionStat struct {
+	Action  string             `json:"action"`
+	Records []ActionStatRecord `json:"records"`
+}
+
+type ActionStatRecord struct {
+	Reps       int       `json:"reps"`
+	RepsUnit   string    `json:"reps_unit"`
+	Weight     float64   `json:"weight"`
+	WeightUnit string    `json:"weight_unit"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type AddFriendRequest struct {
+	UID string `json:"uid"`
+}
+
+type AppliedWorkoutSchedule struct {
+	Id                int                         `json:"id"`
+	Status            int                         `json:"status"`
+	WorkoutScheduleId int                         `json:"workout_schedule_id"`
+	Title             string                      `json:"title"`
+	Overview          string                      `json:"overview"`
+	Type              int                         `json:"type"`
+	Level             int                         `json:"level"`
+	Details           string                      `json:"details"`
+	Schedules         []AppliedWorkoutScheduleDay `json:"schedules"`
+	AppliedAt         time.Time                   `json:"applied_at"`
+	StartDate         *time.Time                  `json:"start_date"`
+}
+
+type AppliedWorkoutScheduleDay struct {
+	Day           int    `json:"day"`
+	Weekday       int    `json:"weekday"`
+	WorkoutPlanId int    `json:"workout_plan_id"`
+	Title         string `json:"title"`
+	Overview      string `json:"overview"`
+	Tags          string `json:"tags"`
+}
+
+type ApplyWorkoutScheduleRequest struct {
+	Id        int       `json:"id"`
+	Interval  int       `json:"interval"`
+	StartDate time.Time `json:"start_date"`
+}
+
+type ArticleItem struct {
+	Id           int        `json:"id"`
+	Title        string     `json:"title"`
+	Overview     string     `json:"overview"`
+	Type         int        `json:"type"`
+	VideoURL     string     `json:"video_url"`
+	Creator      CoachBrief `json:"creator"`
+	LikeCount    int        `json:"like_count"`
+	CommentCount int        `json:"comment_count"`
+	IsLiked      bool       `json:"is_liked"`
+	IsFavorited  bool       `json:"is_favorited"`
+	PublishedAt  *time.Time `json:"published_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	*ArticleReviewState
+}
+
+type ArticleItemList struct {
+	List       []ArticleItem `json:"list"`
+	PageSize   int           `json:"page_size"`
+	HasMore    bool          `json:"has_more"`
+	NextMarker string        `json:"next_marker"`
+}
+
+type ArticleProfile struct {
+	Id           int                `json:"id"`
+	Title        string             `json:"title"`
+	Overview     string             `json:"overview"`
+	Type         int                `json:"type"`
+	VideoURL     string             `json:"video_url"`
+	CreatedAt    time.Time          `json:"created_at"`
+	TimePoints   []ArticleTimePoint `json:"time_points"`
+	IsAuthor     bool               `json:"is_author"`
+	Creator      CoachBrief         `json:"creator"`
+	LikeCount    int                `json:"like_count"`
+	CommentCount int                `json:"comment_count"`
+	IsLiked      bool               `json:"is_liked"`
+	IsFavorited  bool               `json:"is_favorited"`
+	PublishedAt  *time.Time         `json:"published_at"`
+	*ArticleReviewState
+}
+
+type ArticleReviewItem struct {
+	Id        int       `json:"id"`
+	Status    int       `json:"status"`
+	Reason    string    `json:"reason"`
+	IsAuto    bool      `json:"is_auto"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ArticleReviewState struct {
+	Status       int    `json:"status"`
+	RejectReason string `json:"reject_reason"`
+	IsPublished  bool   `json:"is_published"`
+}
+
+type ArticleStatusResponse struct {
+	Id           int    `json:"id"`
+	Status       int    `json:"status"`
+	RejectReason string `json:"reject_reason"`
+}
+
+type ArticleTimePoint struct {
+	Id            int                     `json:"id"`
+	Text          string                  `json:"text"`
+	Time          int                     `json:"time"`
+	WorkoutAction *ArticleTimePointAction `json:"workout_action"`
+}
+
+type ArticleTimePointAction struct {
+	Id     int    `json:"id"`
+	ZhName string `json:"zh_name"`
+	Score  int    `json:"score"`
+}
+
+type AuthResponse struct {
+	Token            string `json:"token,omitempty"`
+	ExpiresAt        int64  `json:"expires_at"`
+	RefreshToken     string `json:"refresh_token,omitempty"`
+	RefreshExpiresAt int64  `json:"refresh_expires_at,omitempty"`
+	Status           string `json:"status,omitempty"`
+	MFARequired      bool   `json:"mfa_required,omitempty"`
+	ChallengeToken   string `json:"challenge_token,omitempty"`
+}
+
+type AuthURLResponse struct {
+	URL string `json:"url"`
+}
+
+type BindPhoneRequest struct {
+	Phone string `json:"phone"`
+	Code  string `json:"code"`
+}
+
+type BlockCoachRequest struct {
+	BlockedId int `json:"blocked_id"`
+}
+
+type BlockItem struct {
+	CoachBrief
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BlockItemList struct {
+	List       []BlockItem `json:"list"`
+	PageSize   int         `json:"page_size"`
+	HasMore    bool        `json:"has_more"`
+	NextMarker string      `json:"next_marker"`
+}
+
+type BuildCoachAuthURLInAdminRequest struct {
+	Id int `json:"id"`
+}
+
+type BuildStudentAuthURLRequest struct {
+	Id int `json:"id"`
+}
+
+type CalcSubscriptionOrderAmountRequest struct {
+	SubscriptionPlanId int    `json:"subscription_plan_id"`
+	Type               string `json:"type"`
+}
+
+type CalcSubscriptionOrderAmountResponse struct {
+	TotalAmount   int            `json:"total_amount"`
+	Amount        int            `json:"amount"`
+	DiscountTexts []DiscountText `json:"discount_texts"`
+	Text          string         `json:"text"`
+}
+
+type CancelWorkoutScheduleRequest struct {
+	Id int `json:"id"`
+}
+
+type CheckHasStartedWorkoutDayResponse struct {
+	List []StartedWorkoutDayStatus `json:"list"`
+}
+
+type Coach struct {
+	Id           int                 `json:"id"`
+	D            int                 `json:"d"`
+	Nickname     string              `json:"nickname"`
+	Bio          string              `json:"bio"`
+	CoachType    int                 `json:"coach_type"`
+	Status       int                 `json:"status"`
+	Config       string              `json:"config"`
+	WorkoutStats string              `json:"workout_stats"`
+	Timezone     string              `json:"timezone"`
+	CreatedAt    time.Time           `json:"created_at"`
+	UpdatedAt    *time.Time          `json:"updated_at"`
+	Profile1Id   int                 `json:"profile1_id"`
+	Profile1     CoachProfile1       `json:"profile1"`
+	Profile2Id   int                 `json:"profile2_id"`
+	Profile2     CoachProfile2       `json:"profile2"`
+	Students     []CoachRelationship `json:"students"`
+	Coaches      []CoachRelationship `json:"coaches"`
+}
+
+type CoachBrief struct {
+	Id        int    `json:"id"`
+	Nickname  string `json:"nickname"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+type CoachContent struct {
+	Id            int        `json:"id"`
+	ContentType   int        `json:"content_type"`
+	Title         string     `json:"title"`
+	Description   string     `json:"description"`
+	ContentURL    string     `json:"content_url"`
+	CoverImageURL string     `json:"cover_image_url"`
+	VideoKey      string     `json:"video_key"`
+	ImageKeys     string     `json:"image_keys"`
+	LikeCount     int        `json:"like_count"`
+	CommentCount  int        `json:"comment_count"`
+	Status        int        `json:"status"`
+	Publish       int        `json:"publish"`
+	PublishedAt   *time.Time `json:"published_at"`
+	RejectReason  string     `json:"reject_reason"`
+	SubmittedAt   *time.Time `json:"submitted_at"`
+	ReviewedAt    *time.Time `json:"reviewed_at"`
+	ReviewerId    int        `json:"reviewer_id"`
+	D             int        `json:"d"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     *time.Time `json:"updated_at"`
+	CoachId       int        `json:"coach_id"`
+	Coach         Coach      `json:"coach"`
+}
+
+type CoachContentList struct {
+	List       []CoachContent `json:"list"`
+	PageSize   int            `json:"page_size"`
+	HasMore    bool           `json:"has_more"`
+	NextMarker string         `json:"next_marker"`
+}
+
+type CoachDeletionRequest struct {
+	Id          int        `json:"id"`
+	Status      int        `json:"status"`
+	Reason      string     `json:"reason"`
+	ScheduledAt time.Time  `json:"scheduled_at"`
+	CancelledAt *time.Time `json:"cancelled_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CoachId     int        `json:"coach_id"`
+}
+
+type CoachImpersonationLog struct {
+	Id        int       `json:"id"`
+	ActorId   int       `json:"actor_id"`
+	SessionId string    `json:"session_id"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	IP        string    `json:"ip"`
+	CoachId   int       `json:"coach_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CoachImpersonationLogList struct {
+	List       []CoachImpersonationLog `json:"list"`
+	PageSize   int                     `json:"page_size"`
+	HasMore    bool                    `json:"has_more"`
+	NextMarker string                  `json:"next_marker"`
+}
+
+type CoachItem struct {
+	Id        int       `json:"id"`
+	Nickname  string    `json:"nickname"`
+	AvatarURL string    `json:"avatar_url"`
+	Age       int       `json:"age"`
+	Gender    int       `json:"gender"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CoachItemList struct {
+	List       []CoachItem `json:"list"`
+	PageSize   int         `json:"page_size"`
+	HasMore    bool        `json:"has_more"`
+	NextMarker string      `json:"next_marker"`
+}
+
+type CoachMediaSocialAccount struct {
+	Id             int                 `json:"id"`
+	D              int                 `json:"d"`
+	Status         int                 `json:"status"`
+	Nickname       string              `json:"nickname"`
+	NicknameUsed   string              `json:"nickname_used"`
+	AvatarURL      string              `json:"avatar_url"`
+	Handle         string              `json:"handle"`
+	AccountURL     string              `json:"account_url"`
+	FollowersCount int                 `json:"followers_count"`
+	LogoURL        string              `json:"logo_url"`
+	HomepageURL    string              `json:"homepage_url"`
+	UpdatedAt      *time.Time          `json:"updated_at"`
+	CreatedAt      time.Time           `json:"created_at"`
+	CoachId        int                 `json:"coach_id"`
+	Coach          Coach               `json:"coach"`
+	PlatformId     int                 `json:"platform_id"`
+	Platform       MediaSocialPlatform `json:"platform"`
+}
+
+type CoachProfile1 struct {
+	Id                  int    `json:"id"`
+	CoachId             int    `json:"coach_id"`
+	Nickname            string `json:"nickname"`
+	AvatarURL           string `json:"avatar_url"`
+	Age                 int    `json:"age"`
+	Gender              int    `json:"gender"`
+	BodyType            int    `json:"body_type"`
+	Height              int    `json:"height"`
+	Weight              int    `json:"weight"`
+	BodyFatPercent      int    `json:"body_fat_percent"`
+	RiskScreenings      string `json:"risk_screenings"`
+	TrainingGoals       string `json:"training_goals"`
+	TrainingFrequency   int    `json:"training_frequency"`
+	TrainingPreferences string `json:"training_preferences"`
+	DietPreferences     string `json:"diet_preferences"`
+}
+
+type CoachProfile2 struct {
+	Id              int    `json:"id"`
+	CoachId         int    `json:"coach_id"`
+	Specialties     string `json:"specialties"`
+	Certification   string `json:"certification"`
+	ExperienceYears string `json:"experience_years"`
+}
+
+type CoachRelationship struct {
+	Id        int        `json:"id"`
+	Status    int        `json:"status"`
+	Role      int        `json:"role"`
+	Note      string     `json:"note"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+	CoachId   int        `json:"coach_id"`
+	Coach     Coach      `json:"coach"`
+	StudentId int        `json:"student_id"`
+	Student   Coach      `json:"student"`
+}
+
+type CoachReport struct {
+	Id           int       `json:"id"`
+	Type         int       `json:"type"`
+	Status       int       `json:"status"`
+	D            int       `json:"d"`
+	Content      string    `json:"content"`
+	ReplyContent string    `json:"reply_content"`
+	ReasonType   string    `json:"reason_type"`
+	ReasonId     int       `json:"reason_id"`
+	CoachId      int       `json:"coach_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type CoachReportList struct {
+	List       []CoachReport `json:"list"`
+	PageSize   int           `json:"page_size"`
+	HasMore    bool          `json:"has_more"`
+	NextMarker string        `json:"next_marker"`
+}
+
+type CoachSubscriptionBrief struct {
+	Name      string     `json:"name"`
+	Status    int        `json:"status"`
+	ExpiredAt *time.Time `json:"expired_at"`
+	Count     int        `json:"count"`
+}
+
+type CoachWorkoutStats struct {
+	Version           string    `json:"v"`
+	TotalWorkoutDays  int       `json:"total_workout_days"`
+	TotalWorkoutTimes int64     `json:"total_workout_times"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type CommentItem struct {
+	Id         int             `json:"id"`
+	Content    string          `json:"content"`
+	Status     int             `json:"status"`
+	ReplyCount int             `json:"reply_count"`
+	RootId     int             `json:"root_id"`
+	ParentId   int             `json:"parent_id"`
+	Creator    CoachBrief      `json:"creator"`
+	IsAuthor   bool            `json:"is_author"`
+	CreatedAt  time.Time       `json:"created_at"`
+	ReplyTo    *CommentReplyTo `json:"reply_to,omitempty"`
+}
+
+type CommentItemList struct {
+	List       []CommentItem `json:"list"`
+	PageSize   int           `json:"page_size"`
+	HasMore    bool          `json:"has_more"`
+	NextMarker string        `json:"next_marker"`
+}
+
+type CommentReplyTo struct {
+	Id       int    `json:"id"`
+	Nickname string `json:"nickname"`
+}
+
+type CompleteExamRequest struct {
+	Id int `json:"id"`
+}
+
+type CompleteExamResponse struct {
+	Exam       Exam `json:"exam"`
+	TotalScore int  `json:"total_score"`
+	IsPassed   int  `json:"is_passed"`
+}
+
+type ContinueWorkoutDayRequest struct {
+	Id int `json:"id"`
+}
+
+type CreateAccountRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type CreateArticleRequest struct {
+	Title      string `json:"title"`
+	Overview   string `json:"overview"`
+	Type       int    `json:"type"`
+	Status     int    `json:"status"`
+	VideoURL   string `json:"video_url"`
+	TimePoints []struct {
+		Time            int    `json:"time"`
+		Text            string `json:"text"`
+		WorkoutActionId int    `json:"workout_action_id"`
+	} `json:"time_points"`
+	PublishedAt *time.Time `json:"published_at"`
+	Submit      bool       `json:"submit"`
+}
+
+type CreateCoachContentRequest struct {
+	Title         string `json:"title"`
+	Description   string `json:"description"`
+	ContentType   int    `json:"content_type"`
+	CoverImageURL string `json:"cover_image_url"`
+	ContentURL    string `json:"content_url"`
+	VideoKey      string `json:"video_key"`
+	CoachId       int    `json:"coach_id"`
+}
+
+type CreateCoachRequest struct {
+	Nickname  string `json:"nickname"`
+	AvatarURL string `json:"avatar_url"`
+	Bio       string `json:"bio"`
+}
+
+type CreateCommentRequest struct {
+	ContentId int    `json:"content_id"`
+	Content   string `json:"content"`
+	ParentId  int    `json:"parent_id"`
+}
+
+type CreateCommentResponse struct {
+	Id     int `json:"id"`
+	Status int `json:"status"`
+}
+
+type CreateContentWithWorkoutActionRequest struct {
+	ContentId       int `json:"content_id"`
+	WorkoutActionId int `json:"workout_action_id"`
+	StartPoint      int `json:"start_point"`
+}
+
+type CreateContentWithWorkoutPlanRequest struct {
+	Details       string `json:"details"`
+	ContentId     int    `json:"content_id"`
+	WorkoutPlanId int    `json:"workout_plan_id"`
+}
+
+type CreateEquipmentRequest struct {
+	ZhName   string `json:"zh_name"`
+	Name     string `json:"name"`
+	Alias    string `json:"alias"`
+	Overview string `json:"overview"`
+	Tags     string `json:"tags"`
+	SortIdx  int    `json:"sort_idx"`
+	Medias   string `json:"medias"`
+}
+
+type CreateFavoriteRequest struct {
+	ContentType string `json:"content_type"`
+	ContentId   int    `json:"content_id"`
+	FolderId    int    `json:"folder_id"`
+}
+
+type CreateFreeWorkoutDayRequest struct {
+	Type              string     `json:"type"`
+	Title             string     `json:"title"`
+	PendingSteps      string     `json:"pending_steps"`
+	UpdatedDetails    string     `json:"updated_details"`
+	StartAt           *time.Time `json:"start_at"`
+	FinishedAt        *time.Time `json:"finished_at"`
+	Remark            string     `json:"remark"`
+	Medias            string     `json:"medias"`
+	StartWhenCreate   bool       `json:"start_when_create"`
+	FinishWhenCreated bool       `json:"finish_when_created"`
+}
+
+type CreateGiftCardRequest struct {
+	Num              int `json:"num"`
+	GiftCardRewardId int `json:"gift_card_reward_id"`
+}
+
+type CreateGiftCardRewardRequest struct {
+	Name     string `json:"name"`
+	Overview string `json:"overview"`
+	Details  string `json:"details"`
+}
+
+type CreateInviteRequest struct {
+	StudentId int `json:"student_id"`
+	ExpiresIn int `json:"expires_in"`
+}
+
+type CreateInviteResponse struct {
+	Id        int       `json:"id"`
+	Code      string    `json:"code"`
+	URL       string    `json:"url"`
+	QRCode    string    `json:"qrcode"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+type CreateMediaResourceRequest struct {
+	Type     int    `json:"type"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Duration int    `json:"duration"`
+	Size     int    `json:"size"`
+	Filetype string `json:"filetype"`
+	Filename string `json:"filename"`
+	Hash     string `json:"hash"`
+	Key      string `json:"key"`
+}
+
+type CreateMuscleRequest struct {
+	ZhName   string `json:"zh_name"`
+	Name     string `json:"name"`
+	Overview string `json:"overview"`
+	Tags     string `json:"tags"`
+	SortIdx  int    `json:"sort_idx"`
+	Medias   string `json:"medias"`
+}
+
+type CreatePaperRequest struct {
+	Name      string `json:"name"`
+	Overview  string `json:"overview"`
+	Tags      string `json:"tags"`
+	PassScore int    `json:"pass_score"`
+	Duration  int    `json:"duration"`
+	QuizList  []struct {
+		Id      int `json:"id"`
+		Score   int `json:"score"`
+		SortIdx int `json:"sort_idx"`
+	} `json:"quiz_list"`
+}
+
+type CreateQuizRequest struct {
+	Content    string `json:"content"`
+	Overview   string `json:"overview"`
+	Type       int    `json:"type"`
+	Tags       string `json:"tags"`
+	Difficulty int    `json:"difficulty"`
+	Choices    string `json:"choices"`
+	Answer     string `json:"answer"`
+	Analysis   string `json:"analysis"`
+	Medias     string `json:"medias"`
+}
+
+type CreateReportRequest struct {
+	Content    string  `json:"content"`
+	ReasonType *string `json:"reason_type"`
+	ReasonId   *int    `json:"reason_id"`
+}
+
+type CreateStudentRequest struct {
+	Name   string `json:"name"`
+	Gender int    `json:"gender"`
+	Age    int    `json:"age"`
+}
+
+type CreateSubscriptionPlanRequest struct {
+	Name             string `json:"name"`
+	Details          string `json:"details"`
+	UnitPrice        int    `json:"unit_price"`
+	DiscountPolicies []struct {
+		Name         string `json:"name"`
+		Rate         int    `json:"rate"`
+		CountRequire int    `json:"count_require"`
+		Enabled      int    `json:"enabled"`
+	} `json:"discount_policies"`
+}
+
+type CreateWorkoutDayRequest struct {
+	StudentIds      []int  `json:"student_ids"`
+	StartWhenCreate bool   `json:"start_when_create"`
+	Title           string `json:"title"`
+	Type            string `json:"type"`
+	Details         string `json:"details"`
+	WorkoutPlanId   int    `json:"workout_plan_id"`
+}
+
+type CreateWorkoutDayResponse struct {
+	Ids []int `json:"ids"`
+}
+
+type CreateWorkoutHistoryRequest struct {
+	WorkoutActionId int     `json:"workout_action_id"`
+	Reps            int     `json:"reps"`
+	RepsUnit        string  `json:"reps_unit"`
+	Weight          float64 `json:"weight"`
+	WeightUnit      string  `json:"weight_unit"`
+}
+
+type CreateWorkoutPlanRequest struct {
+	WorkoutPlanBody
+}
+
+type CreateWorkoutPlanSetRequest struct {
+	Title    string `json:"title"`
+	Overview string `json:"overview"`
+	IconURL  string `json:"icon_url"`
+	Idx      int    `json:"idx"`
+	Details  []struct {
+		Type int `json:"type"`
+		Id   int `json:"id"`
+	} `json:"details"`
+}
+
+type CreateWorkoutScheduleRequest struct {
+	Title    string `json:"title"`
+	Overview string `json:"overview"`
+	Status   int    `json:"status"`
+	Level    int    `json:"level"`
+	Type     int    `json:"type"`
+	Details  string `json:"details"`
+}
+
+type CreatorBrief struct {
+	CoachBrief
+	IsSelf bool `json:"is_self"`
+}
+
+type DeleteCommentRequest struct {
+	Id int `json:"id"`
+}
+
+type DeleteEquipmentRequest struct {
+	Id int `json:"id"`
+}
+
+type DeleteFavoriteRequest struct {
+	ContentType string `json:"content_type"`
+	ContentId   int    `json:"content_id"`
+}
+
+type DeleteMediaResourceRequest struct {
+	Id int `json:"id"`
+}
+
+type DeleteMuscleRequest struct {
+	Id int `json:"id"`
+}
+
+type DeleteStudentRequest struct {
+	Id int `json:"id"`
+}
+
+type DeleteWorkoutActionRequest struct {
+	Id int `json:"id"`
+}
+
+type DeleteWorkoutDayRequest struct {
+	Id int `json:"id"`
+}
+
+type DeleteWorkoutPlanRequest struct {
+	Id int `json:"id"`
+}
+
+type DisableTOTPRequest struct {
+	Code string `json:"code"`
+}
+
+type DiscountText struct {
+	Value int    `json:"value"`
+	Text  string `json:"text"`
+}
+
+type DismissRelationshipRequest struct {
+	Id int `json:"id"`
+}
+
+type EnableTOTPRequest struct {
+	Code string `json:"code"`
+}
+
+type EnrollTOTPResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type Equipment struct {
+	Id       int64  `json:"id"`
+	D        int    `json:"d"`
+	Name     string `json:"name"`
+	ZhName   string `json:"zh_name"`
+	Alias    string `json:"alias"`
+	Overview string `json:"overview"`
+	Tags     string `json:"tags"`
+	SortIdx  int    `json:"sort_idx"`
+	Medias   string `json:"medias"`
+}
+
+type EquipmentList struct {
+	List       []Equipment `json:"list"`
+	PageSize   int         `json:"page_size"`
+	HasMore    bool        `json:"has_more"`
+	NextMarker string      `json:"next_marker"`
+}
+
+type Exam struct {
+	Id          int        `json:"id"`
+	Status      int        `json:"status"`
+	CurQuizId   int        `json:"cur_quiz_id"`
+	Score       int        `json:"score"`
+	CorrectRate int        `json:"correct_rate"`
+	Pass        int        `json:"pass"`
+	StudentId   int        `json:"student_id"`
+	StartedAt   *time.Time `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	GiveUpAt    *time.Time `json:"give_up_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	PaperId     int        `json:"paper_id"`
+	Paper       Paper      `json:"paper"`
+}
+
+type ExamDetailResponse struct {
+	Exam        Exam         `json:"exam"`
+	Paper       Paper        `json:"paper"`
+	QuizAnswers []QuizAnswer `json:"quiz_answers"`
+}
+
+type ExamList struct {
+	List       []Exam `json:"list"`
+	PageSize   int    `json:"page_size"`
+	HasMore    bool   `json:"has_more"`
+	NextMarker string `json:"next_marker"`
+}
+
+type ExchangeMagicLinkRequest struct {
+	Code string `json:"code"`
+}
+
+type FavoriteItem struct {
+	Id          int            `json:"id"`
+	ContentType string         `json:"content_type"`
+	FolderId    int            `json:"folder_id"`
+	Content     FavoriteTarget `json:"content"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+type FavoriteItemList struct {
+	List       []FavoriteItem `json:"list"`
+	PageSize   int            `json:"page_size"`
+	HasMore    bool           `json:"has_more"`
+	NextMarker string         `json:"next_marker"`
+}
+
+type FavoriteResponse struct {
+	IsFavorited bool `json:"is_favorited"`
+}
+
+type FavoriteTarget struct {
+	Id                int         `json:"id"`
+	Title             string      `json:"title"`
+	Overview          string      `json:"overview"`
+	Type              int         `json:"type"`
+	VideoURL          string      `json:"video_url"`
+	LikeCount         int         `json:"like_count"`
+	CommentCount      int         `json:"comment_count"`
+	Level             int         `json:"level"`
+	Tags              string      `json:"tags"`
+	EstimatedDuration int         `json:"estimated_duration"`
+	Creator           *CoachBrief `json:"creator,omitempty"`
+	Invalid           bool        `json:"invalid"`
+}
+
+type FeedCoachContent struct {
+	Id           int    `json:"id"`
+	Title        string `json:"title"`
+	Overview     string `json:"overview"`
+	Type         int    `json:"type"`
+	VideoURL     string `json:"video_url"`
+	LikeCount    int    `json:"like_count"`
+	CommentCount int    `json:"comment_count"`
+}
+
+type FeedItem struct {
+	Type         string            `json:"type"`
+	Time         time.Time         `json:"time"`
+	Creator      CoachBrief        `json:"creator"`
+	CoachContent *FeedCoachContent `json:"coach_content,omitempty"`
+	WorkoutPlan  *FeedWorkoutPlan  `json:"workout_plan,omitempty"`
+	WorkoutDay   *FeedWorkoutDay   `json:"workout_day,omitempty"`
+}
+
+type FeedItemList struct {
+	List       []FeedItem `json:"list"`
+	PageSize   int        `json:"page_size"`
+	HasMore    bool       `json:"has_more"`
+	NextMarker string     `json:"next_marker"`
+}
+
+type FeedWorkoutDay struct {
+	Id          int        `json:"id"`
+	Title       string     `json:"title"`
+	Type        string     `json:"type"`
+	Duration    int        `json:"duration"`
+	TotalVolume float64    `json:"total_volume"`
+	FinishedAt  *time.Time `json:"finished_at"`
+}
+
+type FeedWorkoutPlan struct {
+	Id                int    `json:"id"`
+	Title             string `json:"title"`
+	Overview          string `json:"overview"`
+	Level             int    `json:"level"`
+	Tags              string `json:"tags"`
+	EstimatedDuration int    `json:"estimated_duration"`
+}
+
+type FetchAccountListResponse struct {
+	List []AccountItem `json:"list"`
+}
+
+type FetchAppliedWorkoutScheduleListResponse struct {
+	List []AppliedWorkoutSchedule `json:"list"`
+}
+
+type FetchArticleListRequest struct {
+	Pagination
+}
+
+type FetchArticleProfileRequest struct {
+	Id int `json:"id"`
+}
+
+type FetchArticleReviewListRequest struct {
+	Id int `json:"id"`
+}
+
+type FetchArticleReviewListResponse struct {
+	Status       int                 `json:"status"`
+	RejectReason string              `json:"reject_reason"`
+	SubmittedAt  *time.Time          `json:"submitted_at"`
+	ReviewedAt   *time.Time          `json:"reviewed_at"`
+	List         []ArticleReviewItem `json:"list"`
+}
+
+type FetchCardioWorkoutActionListRequest struct {
+	Pagination
+	Keyword string `json:"keyword"`
+	Tag     string `json:"tag"`
+}
+
+type FetchCoachContentListRequest struct {
+	Pagination
+}
+
+type FetchCoachListRequest struct {
+	Pagination
+	Keyword string `json:"keyword"`
+}
+
+type FetchCoachProfileInAdminRequest struct {
+	Id int `json:"id"`
+}
+
+type FetchCoachProfileInWechatRequest struct {
+	UID string `json:"uid"`
+}
+
+type FetchCoachProfileInWechatResponse struct {
+	Id             int                       `json:"id"`
+	UID            string                    `json:"uid"`
+	Nickname       string                    `json:"nickname"`
+	AvatarURL      string                    `json:"avatar_url"`
+	Accounts       []CoachMediaSocialAccount `json:"accounts"`
+	FollowerCount  int64                     `json:"follower_count"`
+	FollowingCount int64                     `json:"following_count"`
+	IsFollowing    bool                      `json:"is_following"`
+	IsFollowed     bool                      `json:"is_followed"`
+	IsMutual       bool                      `json:"is_mutual"`
+	IsBlocked      bool                      `json:"is_blocked"`
+}
+
+type FetchCoachProfileResponse struct {
+	Id             int                    `json:"id"`
+	UID            string                 `json:"uid"`
+	Nickname       string                 `json:"nickname"`
+	AvatarURL      string                 `json:"avatar_url"`
+	Timezone       string                 `json:"timezone"`
+	Subscription   CoachSubscriptionBrief `json:"subscription"`
+	NoAccount      bool                   `json:"no_account"`
+	EmailVerified  bool                   `json:"email_verified"`
+	FollowerCount  int64                  `json:"follower_count"`
+	FollowingCount int64                  `json:"following_count"`
+}
+
+type FetchCommentListRequest struct {
+	Pagination
+	ContentId int `json:"content_id"`
+	RootId    int `json:"root_id"`
+}
+
+type FetchContentListOfWorkoutActionRequest struct {
+	Pagination
+	WorkoutActionId int `json:"workout_action_id"`
+}
+
+type FetchContentListOfWorkoutPlanRequest struct {
+	Pagination
+	WorkoutPlanId int    `json:"workout_plan_id"`
+	Level         int    `json:"level"`
+	Tag           string `json:"tag"`
+}
+
+type FetchContentProfileOfWorkoutPlanRequest struct {
+	Id int `json:"id"`
+}
+
+type FetchEquipmentListRequest struct {
+	Pagination
+	Ids []int `json:"ids"`
+}
+
+type FetchEquipmentRequest struct {
+	Id int `json:"id"`
+}
+
+type FetchExamListRequest struct {
+	Pagination
+}
+
+type FetchExamProfileRequest struct {
+	Id int `json:"id"`
+}
+
+type FetchExamResultRequest struct {
+	Id int `json:"id"`
+}
+
+type FetchFavoriteListRequest struct {
+	Pagination
+	ContentType string `json:"content_type"`
+	FolderId    int    `json:"folder_id"`
+}
+
+type FetchFeedListRequest struct {
+	PageSize   int    `json:"page_size"`
+	NextMarker string `json:"next_marker"`
+}
+
+type FetchFinishedWorkoutDayListRequest struct {
+	Id              int        `json:"id"`
+	FinishedAtStart *time.Time `json:"finished_at_start"`
+	FinishedAtEnd   *time.Time `json:"finished_at_end"`
+}
+
+type FetchFinishedWorkoutDayListResponse struct {
+	List []WorkoutDayItem `json:"list"`
+}
+
+type FetchFollowSuggestionListRequest struct {
+	PageSize int `json:"page_size"`
+}
+
+type FetchFollowSuggestionListResponse struct {
+	List []FollowSuggestion `json:"list"`
+}
+
+type FetchGiftCardListRequest struct {
+	Pagination
+}
+
+type FetchGiftCardProfileRequest struct {
+	Code string `json:"code"`
+}
+
+type FetchGiftCardProfileResponse struct {
+	Name   string `json:"name"`
+	Status int    `json:"status"`
+}
+
+type FetchGiftCardRewardListRequest struct {
+	Pagination
+}
+
+type FetchImpersonationLogListRequest struct {
+	Pagination
+	ActorId int `json:"actor_id"`
+	CoachId int `json:"coach_id"`
+}
+
+type FetchInviteListRequest struct {
+	Pagination
+	StudentId int `json:"student_id"`
+}
+
+type FetchInviteProfileRequest struct {
+	Code string `json:"code"`
+}
+
+type FetchInviteProfileResponse struct {
+	Code      string     `json:"code"`
+	Status    int        `json:"status"`
+	Available bool       `json:"available"`
+	Coach     CoachBrief `json:"coach"`
+	ExpiredAt time.Time  `json:"expired_at"`
+}
+
+type FetchMFAStatusResponse struct {
+	Enabled            bool       `json:"enabled"`
+	EnabledAt          *time.Time `json:"enabled_at"`
+	RecoveryCodesLeft  int64      `json:"recovery_codes_left"`
+	SessionMFAVerified bool       `json:"session_mfa_verified"`
+}
+
+type FetchMediaResourceListRequest struct {
+	Pagination
+}
+
+type FetchMineReportListRequest struct {
+	Pagination
+}
+
+type FetchMuscleListRequest struct {
+	Pagination
+	Ids []int `json:"ids"`
+}
+
+type FetchMuscleProfileRequest struct {
+	Id int `json:"id"`
+}
+
+type FetchMyBlockListRequest struct {
+	Pagination
+}
+
+type FetchMyFollowerListRequest struct {
+	Pagination
+}
+
+type FetchMyFollowingListRequest struct {
+	Pagination
+}
+
+type FetchMyStudentWorkoutDayListRequest struct {
+	Pagination
+	Id             int        `json:"id"`
+	StartedAtStart *time.Time `json:"started_at_start"`
+	StartedAtEnd   *time.Time `json:"started_at_end"`
+	Status         *int       `json:"status"`
+}
+
+type FetchPaperListRequest struct {
+	Pagination
+}
+
+type FetchPaperProfileRequest struct {
+	Id int `json:"id"`
+}
+
+type FetchPaperProfileResponse struct {
+	Paper   Paper       `json:"paper"`
+	Quizzes []PaperQuiz `json:"quizzes"`
+}
+
+type FetchPendingArticleListRequest struct {
+	Pagination
+}
+
+type FetchPendingCommentListRequest struct {
+	Pagination
+}
+
+type FetchQuizListRequest struct {
+	Pagination
+}
+
+type FetchRelatedWorkoutActionsRequest struct {
+	Id int `json:"id"`
+}
+
+type FetchRelatedWorkoutActionsResponse struct {
+	Advanced  []WorkoutAction `json:"advanced"`
+	Regressed []WorkoutAction `json:"regressed"`
+}
+
+type FetchReportListRequest struct {
+	Pagination
+}
+
+type FetchReportProfileRequest struct {
+	Id int `json:"id"`
+}
+
+type FetchRunningExamResponse struct {
+	List []Exam `json:"list"`
+}
+
+type FetchSessionListResponse struct {
+	List []SessionItem `json:"list"`
+}
+
+type FetchStartedWorkoutDayResponse struct {
+	List []StartedWorkoutDayItem `json:"list"`
+}
+
+type FetchStudentListRequest struct {
+	Pagination
+	Keyword string `json:"keyword"`
+}
+
+type FetchStudentProfileRequest struct {
+	Id int `json:"id"`
+}
+
+type FetchStudentWorkoutActionHistoryListOfWorkoutDayRequest struct {
+	Pagination
+	WorkoutDayId int    `json:"workout_day_id"`
+	StudentId    int    `json:"student_id"`
+	OrderBy      string `json:"order_by"`
+}
+
+type FetchStudentWorkoutDayProfileRequest struct {
+	Id        int `json:"id"`
+	StudentId int `json:"student_id"`
+}
+
+type FetchStudentWorkoutDayResultRequest struct {
+	Id int `json:"id"`
+}
+
+type FetchSubscriptionListRequest struct {
+	Pagination
+}
+
+type FetchSubscriptionPlanListRequest struct {
+	Pagination
+}
+
+type FetchSubscriptionPlanListResponse struct {
+	List []SubscriptionPlan `json:"list"`
+}
+
+type FetchVersionResponse struct {
+	Version string `json:"version"`
+}
+
+type FetchWorkoutActionHistoryListOfWorkoutActionRequest struct {
+	Pagination
+	WorkoutActionId int    `json:"workout_action_id"`
+	StudentId       int    `json:"student_id"`
+	OrderBy         string `json:"order_by"`
+}
+
+type FetchWorkoutActionHistoryListOfWorkoutDayRequest struct {
+	Pagination
+	WorkoutDayId int    `json:"workout_day_id"`
+	OrderBy      string `json:"order_by"`
+}
+
+type FetchWorkoutActionListByIdsRequest struct {
+	Ids []int `json:"ids"`
+}
+
+type FetchWorkoutActionListByIdsResponse struct {
+	List []WorkoutActionItem `json:"list"`
+}
+
+type FetchWorkoutActionListRequest struct {
+	Pagination
+	Type    string `json:"type"`
+	Keyword string `json:"keyword"`
+	Tag     string `json:"tag"`
+	Level   string `json:"level"`
+	Muscle  string `json:"muscle"`
+}
+
+type FetchWorkoutDayListRequest struct {
+	Pagination
+	Status          int        `json:"status"`
+	FinishedAtStart *time.Time `json:"finished_at_start"`
+	FinishedAtEnd   *time.Time `json:"finished_at_end"`
+}
+
+type FetchWorkoutDayProfileRequest struct {
+	Id int `json:"id"`
+}
+
+type FetchWorkoutDayResultRequest struct {
+	Id int `json:"id"`
+}
+
+type FetchWorkoutPlanListRequest struct {
+	Pagination
+	Ids     []int  `json:"ids"`
+	Keyword string `json:"keyword"`
+	Level   int    `json:"level"`
+	Tag     string `json:"tag"`
+	Muscle  string `json:"muscle"`
+}
+
+type FetchWorkoutPlanProfileRequest struct {
+	Id int `json:"id"`
+}
+
+type FetchWorkoutPlanSetListRequest struct {
+	Pagination
+}
+
+type FetchWorkoutScheduleListRequest struct {
+	Pagination
+	Keyword string `json:"keyword"`
+	Level   int    `json:"level"`
+	Tag     string `json:"tag"`
+}
+
+type FetchWorkoutScheduleProfileRequest struct {
+	Id int `json:"id"`
+}
+
+type FinishWorkoutDayRequest struct {
+	Id             int    `json:"id"`
+	PendingSteps   string `json:"pending_steps"`
+	UpdatedDetails string `json:"updated_details"`
+}
+
+type FollowCoachRequest struct {
+	FollowingId int `json:"following_id"`
+}
+
+type FollowItem struct {
+	CoachBrief
+	IsMutual bool `json:"is_mutual"`
+}
+
+type FollowItemList struct {
+	List       []FollowItem `json:"list"`
+	PageSize   int          `json:"page_size"`
+	HasMore    bool         `json:"has_more"`
+	NextMarker string       `json:"next_marker"`
+}
+
+type FollowSuggestion struct {
+	CoachBrief
+	Reason string `json:"reason"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type GetWorkoutActionRequest struct {
+	Id int `json:"id"`
+}
+
+type GiftCard struct {
+	Id               int            `json:"id"`
+	D                int            `json:"d"`
+	Code             string         `json:"code"`
+	Status           int            `json:"status"`
+	CreatorId        int            `json:"coach_id"`
+	ConsumerId       int            `json:"consumer_id"`
+	UsedAt           *time.Time     `json:"used_at"`
+	ExpiredAt        *time.Time     `json:"expired_at"`
+	CreatedAt        time.Time      `json:"created_at"`
+	GiftCardRewardId int            `json:"gift_card_reward_id"`
+	GiftCardReward   GiftCardReward `json:"gift_card_reward"`
+}
+
+type GiftCardList struct {
+	List       []GiftCard `json:"list"`
+	PageSize   int        `json:"page_size"`
+	HasMore    bool       `json:"has_more"`
+	NextMarker string     `json:"next_marker"`
+}
+
+type GiftCardReward struct {
+	Id        int        `json:"id"`
+	D         int        `json:"d"`
+	Name      string     `json:"name"`
+	Overview  string     `json:"overview"`
+	Status    int        `json:"status"`
+	Details   string     `json:"details"`
+	CreatorId int        `json:"coach_id"`
+	ExpiredAt *time.Time `json:"expired_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type GiftCardRewardList struct {
+	List       []GiftCardReward `json:"list"`
+	PageSize   int              `json:"page_size"`
+	HasMore    bool             `json:"has_more"`
+	NextMarker string           `json:"next_marker"`
+}
+
+type GiveUpExamRequest struct {
+	Id int `json:"id"`
+}
+
+type GiveUpWorkoutDayRequest struct {
+	Id int `json:"id"`
+}
+
+type IdResponse struct {
+	Id int `json:"id"`
+}
+
+type InviteItem struct {
+	Id        int         `json:"id"`
+	Code      string      `json:"code"`
+	URL       string      `json:"url"`
+	Status    int         `json:"status"`
+	Expired   bool        `json:"expired"`
+	InviteeId int         `json:"invitee_id"`
+	ExpiredAt time.Time   `json:"expired_at"`
+	HandledAt *time.Time  `json:"handled_at"`
+	CreatedAt time.Time   `json:"created_at"`
+	Student   *CoachBrief `json:"student,omitempty"`
+}
+
+type InviteItemList struct {
+	List       []InviteItem `json:"list"`
+	PageSize   int          `json:"page_size"`
+	HasMore    bool         `json:"has_more"`
+	NextMarker string       `json:"next_marker"`
+}
+
+type LikeContentRequest struct {
+	Id int `json:"id"`
+}
+
+type LikeContentResponse struct {
+	LikeCount int  `json:"like_count"`
+	IsLiked   bool `json:"is_liked"`
+}
+
+type LoginCoachRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password,omitempty"`
+}
+
+type LoginWithSMSRequest struct {
+	Phone string `json:"phone"`
+	Code  string `json:"code"`
+}
+
+type LogoutAllResponse struct {
+	Count int64 `json:"count"`
+}
+
+type LogoutRequest struct {
+	Id int `json:"id"`
+}
+
+type MediaResource struct {
+	Id        int       `json:"id"`
+	MediaType int       `json:"media_type"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	Size      int       `json:"size"`
+	Duration  int       `json:"duration"`
+	CoverKey  string    `json:"cover_key"`
+	Title     string    `json:"title"`
+	Filename  string    `json:"filename"`
+	Filetype  string    `json:"filetype"`
+	Hash      string    `json:"hash"`
+	Key       string    `json:"key"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+	CreatorId int       `json:"creator_id"`
+}
+
+type MediaResourceList struct {
+	List       []MediaResource `json:"list"`
+	PageSize   int             `json:"page_size"`
+	HasMore    bool            `json:"has_more"`
+	NextMarker string          `json:"next_marker"`
+}
+
+type MediaSocialPlatform struct {
+	Id          int       `json:"id"`
+	Name        string    `json:"image_keys"`
+	LogoURL     string    `json:"logo_url"`
+	HomepageURL string    `json:"homepage_url"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Muscle struct {
+	Id       int64  `json:"id"`
+	D        int    `json:"d"`
+	Name     string `json:"name"`
+	ZhName   string `json:"zh_name"`
+	Tags     string `json:"tags"`
+	Overview string `json:"overview"`
+	SortIdx  int    `json:"sort_idx"`
+	Features string `json:"features"`
+	Medias   string `json:"medias"`
+}
+
+type MuscleList struct {
+	List       []Muscle `json:"list"`
+	PageSize   int      `json:"page_size"`
+	HasMore    bool     `json:"has_more"`
+	NextMarker string   `json:"next_marker"`
+}
+
+type OAuthCodeRequest struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
+
+type OAuthURLResponse struct {
+	Provider string `json:"provider"`
+	URL      string `json:"url"`
+	State    string `json:"state"`
+}
+
+type Pagination struct {
+	Page       int    `json:"page"`
+	PageSize   int    `json:"page_size"`
+	NextMarker string `json:"next_marker"`
+}
+
+type Paper struct {
+	Id        int       `json:"id"`
+	Name      string    `json:"name"`
+	Overview  string    `json:"overview"`
+	Tags      string    `json:"tags"`
+	Duration  int       `json:"duration"`
+	PassScore int       `json:"pass_score"`
+	QuizCount int       `json:"quiz_count"`
+	CreatorId int       `json:"creator_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PaperList struct {
+	List       []Paper `json:"list"`
+	PageSize   int     `json:"page_size"`
+	HasMore    bool    `json:"has_more"`
+	NextMarker string  `json:"next_marker"`
+}
+
+type PaperQuiz struct {
+	Id      int   `json:"id"`
+	Score   int   `json:"score"`
+	SortIdx int   `json:"sort_idx"`
+	Visible int   `json:"visible"`
+	PaperId int   `json:"quiz_paper_id"`
+	Paper   Paper `json:"paper"`
+	QuizId  int   `json:"quiz_id"`
+	Quiz    Quiz  `json:"quiz"`
+}
+
+type PendingArticleItem struct {
+	Id          int        `json:"id"`
+	Title       string     `json:"title"`
+	Overview    string     `json:"overview"`
+	Type        int        `json:"type"`
+	VideoURL    string     `json:"video_url"`
+	Publish     int        `json:"publish"`
+	PublishedAt *time.Time `json:"published_at"`
+	SubmittedAt *time.Time `json:"submitted_at"`
+	Creator     CoachBrief `json:"creator"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type PendingArticleItemList struct {
+	List       []PendingArticleItem `json:"list"`
+	PageSize   int                  `json:"page_size"`
+	HasMore    bool                 `json:"has_more"`
+	NextMarker string               `json:"next_marker"`
+}
+
+type PendingCommentItem struct {
+	Id        int        `json:"id"`
+	Content   string     `json:"content"`
+	ContentId int        `json:"content_id"`
+	Reason    string     `json:"reason"`
+	Creator   CoachBrief `json:"creator"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type PendingCommentItemList struct {
+	List       []PendingCommentItem `json:"list"`
+	PageSize   int                  `json:"page_size"`
+	HasMore    bool                 `json:"has_more"`
+	NextMarker string               `json:"next_marker"`
+}
+
+type QiniuTokenResponse struct {
+	Token string `json:"token"`
+}
+
+type Quiz struct {
+	Id         int       `json:"id"`
+	Content    string    `json:"content"`
+	Overview   string    `json:"overview"`
+	Medias     string    `json:"medias"`
+	Type       int       `json:"type"`
+	Difficulty int       `json:"difficulty"`
+	Tags       string    `json:"tags"`
+	Analysis   string    `json:"analysis"`
+	Choices    string    `json:"choices"`
+	Answer     string    `json:"answer"`
+	CreatorId  int       `json:"creator_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type QuizAnswer struct {
+	Id        int        `json:"id"`
+	Status    int        `json:"status"`
+	Answer    string     `json:"answer"`
+	Score     int        `json:"score"`
+	StudentId int        `json:"student_id"`
+	UpdatedAt *time.Time `json:"updated_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	QuizId    int        `json:"quiz_id"`
+	Quiz      Quiz       `json:"quiz"`
+	ExamId    int        `json:"exam_id"`
+	Exam      Exam       `json:"exam"`
+	PaperId   int        `json:"paper_id"`
+	Paper     Paper      `json:"paper"`
+}
+
+type QuizList struct {
+	List       []Quiz `json:"list"`
+	PageSize   int    `json:"page_size"`
+	HasMore    bool   `json:"has_more"`
+	NextMarker string `json:"next_marker"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type RefreshCoachStatsRequest struct {
+	RangeOfStart *string `json:"range_of_start"`
+	RangeOfEnd   *string `json:"range_of_end"`
+}
+
+type RefreshCoachStatsResponse struct {
+	Stats            CoachWorkoutStats                  `json:"stats"`
+	MaxStreak        int                                `json:"max_streak"`
+	MaxStreakRange   StatsDateRange                     `json:"max_streak_range"`
+	MaxDurationDay   StatsWorkoutDay                    `json:"max_duration_day"`
+	EarliestStartDay StatsWorkoutDay                    `json:"earliest_start_day"`
+	LatestFinishDay  StatsWorkoutDay                    `json:"latest_finish_day"`
+	TypePlanMap      map[string][]StatsWorkoutDayOfType `json:"type_plan_map"`
+	MaxVolumeDay     StatsWorkoutDay                    `json:"max_volume_day"`
+	ActionStats      []ActionStat                       `json:"action_stats"`
+}
+
+type RefreshTodayWorkoutStatsRequest struct {
+	RangeOfStart *time.Time `json:"range_of_start"`
+	RangeOfEnd   *time.Time `json:"range_of_end"`
+}
+
+type RefreshTodayWorkoutStatsResponse struct {
+	WorkoutSteps  []TodayWorkoutActionGroup `json:"workout_steps"`
+	Times         int                       `json:"times"`
+	SetCount      int                       `json:"set_count"`
+	DurationCount int                       `json:"duration_count"`
+	VolumeCount   float64                   `json:"volume_count"`
+	Tags          []string                  `json:"tags"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RefreshWorkoutDayRecordsResponse struct {
+	Updated int `json:"updated"`
+	Total   int `json:"total"`
+}
+
+type RegenerateRecoveryCodesRequest struct {
+	Code string `json:"code"`
+}
+
+type RegisterCoachRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+type RejectInviteRequest struct {
+	Code string `json:"code"`
+}
+
+type RejectRelationshipRequest struct {
+	Id int `json:"id"`
+}
+
+type RequestDeletionRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+	Reason   string `json:"reason"`
+}
+
+type ResetPasswordRequest struct {
+	Email    string `json:"email"`
+	Code     string `json:"code"`
+	Password string `json:"password"`
+}
+
+type ReviewArticleRequest struct {
+	Id       int    `json:"id"`
+	Approved bool   `json:"approved"`
+	Reason   string `json:"reason"`
+}
+
+type ReviewArticleResponse struct {
+	Id          int        `json:"id"`
+	Status      int        `json:"status"`
+	PublishedAt *time.Time `json:"published_at"`
+}
+
+type ReviewCommentRequest struct {
+	Id       int  `json:"id"`
+	Approved bool `json:"approved"`
+}
+
+type RevokeInviteRequest struct {
+	Id int `json:"id"`
+}
+
+type SendGiftCardRequest struct {
+	Code      string `json:"code"`
+	ToCoachId int    `json:"to_coach_id"`
+}
+
+type SendSMSCodeRequest struct {
+	Phone string `json:"phone"`
+}
+
+type SendVerificationCodeRequest struct {
+	Email string `json:"email"`
+}
+
+type SessionItem struct {
+	Id         int        `json:"id"`
+	Device     string     `json:"device"`
+	IP         string     `json:"ip"`
+	IsCurrent  bool       `json:"is_current"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiredAt  time.Time  `json:"expired_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type ShareWorkoutDayRequest struct {
+	Id     int  `json:"id"`
+	Shared bool `json:"shared"`
+}
+
+type ShareWorkoutDayResponse struct {
+	Shared int `json:"shared"`
+}
+
+type StartExamWithPaperRequest struct {
+	PaperId int `json:"paper_id"`
+}
+
+type StartWorkoutDayRequest struct {
+	Id int `json:"id"`
+}
+
+type StartedWorkoutDayItem struct {
+	Id          int                  `json:"id"`
+	Status      int                  `json:"status"`
+	Title       string               `json:"title"`
+	Type        string               `json:"type"`
+	CreatedAt   time.Time            `json:"created_at"`
+	StartedAt   *time.Time           `json:"started_at"`
+	CoachId     int                  `json:"coach_id"`
+	GroupNo     string               `json:"group_no"`
+	Student     CoachBrief           `json:"student"`
+	WorkoutPlan *WorkoutDayPlanBrief `json:"workout_plan"`
+}
+
+type StartedWorkoutDayStatus struct {
+	Id        int        `json:"id"`
+	Status    int        `json:"status"`
+	CreatedAt time.Time  `json:"created_at"`
+	StartedAt *time.Time `json:"started_at"`
+	StudentId int        `json:"student_id"`
+	CoachId   int        `json:"coach_id"`
+}
+
+type StatsDateRange struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+type StatsWorkoutDay struct {
+	Id          int              `json:"id"`
+	StartedAt   *time.Time       `json:"started_at"`
+	FinishedAt  *time.Time       `json:"finished_at"`
+	Duration    int              `json:"duration"`
+	TotalVolume float64          `json:"total_volume"`
+	WorkoutPlan StatsWorkoutPlan `json:"workout_plan"`
+}
+
+type StatsWorkoutDayOfType struct {
+	WorkoutDayId int                   `json:"workout_day_id"`
+	WorkoutPlan  StatsWorkoutPlanTitle `json:"workout_plan"`
+}
+
+type StatsWorkoutPlan struct {
+	Id       int    `json:"id"`
+	Title    string `json:"title"`
+	Overview string `json:"overview"`
+	Type     string `json:"type"`
+}
+
+type StatsWorkoutPlanTitle struct {
+	Id    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+type StudentItem struct {
+	Id        int       `json:"id"`
+	Nickname  string    `json:"nickname"`
+	AvatarURL string    `json:"avatar_url"`
+	Age       int       `json:"age"`
+	Gender    int       `json:"gender"`
+	Role      int       `json:"role"`
+	RoleText  string    `json:"role_text"`
+	Status    int       `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type StudentItemList struct {
+	List       []StudentItem `json:"list"`
+	PageSize   int           `json:"page_size"`
+	HasMore    bool          `json:"has_more"`
+	NextMarker string        `json:"next_marker"`
+}
+
+type StudentProfile struct {
+	Id        int    `json:"id"`
+	Nickname  string `json:"nickname"`
+	AvatarURL string `json:"avatar_url"`
+	Gender    int    `json:"gender"`
+	Age       int    `json:"age"`
+	Status    int    `json:"status"`
+	Role      int    `json:"role"`
+}
+
+type StudentToFriendRequest struct {
+	Id int `json:"id"`
+}
+
+type SubmitArticleRequest struct {
+	Id          int        `json:"id"`
+	PublishedAt *time.Time `json:"published_at"`
+}
+
+type Subscription struct {
+	Id                 int              `json:"id"`
+	Step               int              `json:"step"`
+	Count              int              `json:"count"`
+	Reason             string           `json:"reason"`
+	ExpectExpiredAt    *time.Time       `json:"expect_expired_at"`
+	CreatedAt          time.Time        `json:"created_at"`
+	ActiveAt           *time.Time       `json:"active_at"`
+	ExpiredAt          *time.Time       `json:"expired_at"`
+	PausedAt           *time.Time       `json:"paused_at"`
+	PauseCount         int              `json:"pause_count"`
+	InvalidAt          *time.Time       `json:"invalid_at"`
+	CoachId            int              `json:"coach_id"`
+	Coach              Coach            `json:"coach"`
+	SubscriptionPlanId int              `json:"subscription_plan_id"`
+	SubscriptionPlan   SubscriptionPlan `json:"subscription_plan"`
+}
+
+type SubscriptionList struct {
+	List       []Subscription `json:"list"`
+	PageSize   int            `json:"page_size"`
+	HasMore    bool           `json:"has_more"`
+	NextMarker string         `json:"next_marker"`
+}
+
+type SubscriptionPlan struct {
+	Id        int       `json:"id"`
+	Name      string    `json:"name"`
+	Details   string    `json:"details"`
+	UnitPrice int       `json:"unit_price"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TodayWorkoutAction struct {
+	ActionId   int     `json:"action_id"`
+	ActionName string  `json:"action_name"`
+	Reps       int     `json:"reps"`
+	RepsUnit   string  `json:"reps_unit"`
+	Weight     float64 `json:"weight"`
+	WeightUnit string  `json:"weight_unit"`
+}
+
+type TodayWorkoutActionGroup struct {
+	Title       string                       `json:"title"`
+	Type        string                       `json:"type"`
+	TotalVolume float64                      `json:"total_volume"`
+	Duration    int                          `json:"duration"`
+	Sets        []TodayWorkoutActionGroupSet `json:"sets"`
+}
+
+type TodayWorkoutActionGroupSet struct {
+	Idx     int                  `json:"idx"`
+	Texts   []string             `json:"texts"`
+	Actions []TodayWorkoutAction `json:"actions"`
+}
+
+type UnFollowCoachRequest struct {
+	FollowingId int `json:"following_id"`
+}
+
+type UnblockCoachRequest struct {
+	BlockedId int `json:"blocked_id"`
+}
+
+type UnlikeContentRequest struct {
+	Id int `json:"id"`
+}
+
+type UnlinkAccountRequest struct {
+	ProviderType int `json:"provider_type"`
+}
+
+type UnlockLoginInAdminRequest struct {
+	Email string `json:"email"`
+	IP    string `json:"ip"`
+}
+
+type UpdateArticleRequest struct {
+	Id         int    `json:"id"`
+	Title      string `json:"title"`
+	Overview   string `json:"overview"`
+	Type       int    `json:"type"`
+	VideoURL   string `json:"video_url"`
+	TimePoints []struct {
+		Id              int    `json:"id"`
+		Time            int    `json:"time"`
+		Text            string `json:"text"`
+		WorkoutActionId int    `json:"workout_action_id"`
+	} `json:"time_points"`
+	PublishedAt *time.Time `json:"published_at"`
+}
+
+type UpdateCoachProfileRequest struct {
+	Nickname  string `json:"nickname,omitempty"`
+	AvatarURL string `json:"avatar_url"`
+	Config    string `json:"config"`
+	Timezone  string `json:"timezone"`
+}
+
+type UpdateEquipmentRequest struct {
+	Id       int    `json:"id"`
+	ZhName   string `json:"zh_name"`
+	Name     string `json:"name"`
+	Alias    string `json:"alias"`
+	Overview string `json:"overview"`
+	Tags     string `json:"tags"`
+	SortIdx  int    `json:"sort_idx"`
+	Medias   string `json:"medias"`
+}
+
+type UpdateMuscleRequest struct {
+	Id       int    `json:"id"`
+	ZhName   string `json:"zh_name"`
+	Name     string `json:"name"`
+	Overview string `json:"overview"`
+	Tags     string `json:"tags"`
+	SortIdx  int    `json:"sort_idx"`
+	Medias   string `json:"medias"`
+}
+
+type UpdatePaperRequest struct {
+	Id        int    `json:"id"`
+	Name      string `json:"name"`
+	Tags      string `json:"tags"`
+	PassScore int    `json:"pass_score"`
+	Duration  int    `json:"duration"`
+	QuizList  []struct {
+		RelationId int `json:"relation_id"`
+		Id         int `json:"id"`
+		Score      int `json:"score"`
+		SortIdx    int `json:"sort_idx"`
+	} `json:"quiz_list"`
+}
+
+type UpdateQuizAnswerRequest struct {
+	Id      int    `json:"id"`
+	ExamId  int    `json:"exam_id"`
+	QuizId  int    `json:"quiz_id"`
+	Content string `json:"content"`
+}
+
+type UpdateStudentProfileRequest struct {
+	Id        int    `json:"id"`
+	Nickname  string `json:"nickname"`
+	AvatarURL string `json:"avatar_url"`
+	Age       int    `json:"age"`
+	Gender    int    `json:"gender"`
+}
+
+type UpdateWorkoutActionIdxRequest struct {
+	Id  int `json:"id"`
+	Idx int `json:"idx"`
+}
+
+type UpdateWorkoutActionProfileRequest struct {
+	WorkoutActionBody
+	Id int `json:"id"`
+}
+
+type UpdateWorkoutDayPlanDetailsRequest struct {
+	Id   int    `json:"id"`
+	Data string `json:"data"`
+}
+
+type UpdateWorkoutDayRequest struct {
+	Id             int        `json:"id"`
+	Type           string     `json:"type"`
+	Title          string     `json:"title"`
+	PendingSteps   string     `json:"pending_steps"`
+	UpdatedDetails string     `json:"updated_details"`
+	StartAt        *time.Time `json:"start_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
+}
+
+type UpdateWorkoutDayStepProgressRequest struct {
+	Id   int    `json:"id"`
+	Data string `json:"data"`
+}
+
+type UpdateWorkoutPlanRequest struct {
+	WorkoutPlanBody
+	Id int `json:"id"`
+}
+
+type UpdateWorkoutScheduleRequest struct {
+	Id           int    `json:"id"`
+	Title        string `json:"title"`
+	Overview     string `json:"overview"`
+	Level        int    `json:"level"`
+	Type         int    `json:"type"`
+	WorkoutPlans []struct {
+		Weekday        int   `json:"weekday"`
+		Day            int   `json:"day"`
+		WorkoutPlanIds []int `json:"workout_plan_ids"`
+	} `json:"workout_plans"`
+}
+
+type UsingGiftCardRequest struct {
+	Code string `json:"code"`
+}
+
+type VerifyEmailRequest struct {
+	Code string `json:"code"`
+}
+
+type VerifyMFARequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+type WorkoutAction struct {
+	Id                   int        `json:"id"`
+	D                    int        `json:"d"`
+	Status               int        `json:"status"`
+	Name                 string     `json:"name"`
+	ZhName               string     `json:"zh_name"`
+	Alias                string     `json:"alias"`
+	Overview             string     `json:"overview"`
+	CoverURL             string     `json:"cover_url"`
+	SortIdx              int        `json:"sort_idx"`
+	Type                 string     `json:"type"`
+	Level                int        `json:"level"`
+	Score                int        `json:"score"`
+	Tags1                string     `json:"tags1"`
+	Tags2                string     `json:"tags2"`
+	Pattern              string     `json:"pattern"`
+	Details              string     `json:"details"`
+	Points               string     `json:"points"`
+	Problems             string     `json:"problems"`
+	ExtraConfig          string     `json:"extra_config"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            *time.Time `json:"updated_at"`
+	MuscleIds            string     `json:"muscle_ids"`
+	PrimaryMuscleIds     string     `json:"primary_muscle_ids"`
+	SecondaryMuscleIds   string     `json:"secondary_muscle_ids"`
+	EquipmentIds         string     `json:"equipment_ids"`
+	AlternativeActionIds string     `json:"alternative_action_ids"`
+	AdvancedActionIds    string     `json:"advanced_action_ids"`
+	RegressedActionIds   string     `json:"regressed_action_ids"`
+	OwnerId              int        `json:"owner_id"`
+}
+
+type WorkoutActionBody struct {
+	Name                 string `json:"name"`
+	ZhName               string `json:"zh_name"`
+	Alias                string `json:"alias"`
+	Overview             string `json:"overview"`
+	CoverURL             string `json:"cover_url"`
+	Type                 string `json:"type"`
+	Level                int    `json:"level"`
+	SortIdx              int    `json:"sort_idx"`
+	Pattern              string `json:"pattern"`
+	Score                int    `json:"score"`
+	Tags1                string `json:"tags1"`
+	Tags2                string `json:"tags2"`
+	Details              string `json:"details"`
+	Points               string `json:"points"`
+	Problems             string `json:"problems"`
+	ExtraConfig          string `json:"extra_config"`
+	EquipmentIds         string `json:"equipment_ids"`
+	MuscleIds            string `json:"muscle_ids"`
+	PrimaryMuscleIds     string `json:"primary_muscle_ids"`
+	SecondaryMuscleIds   string `json:"secondary_muscle_ids"`
+	AlternativeActionIds string `json:"alternative_action_ids"`
+	AdvancedActionIds    string `json:"advanced_action_ids"`
+	RegressedActionIds   string `json:"regressed_action_ids"`
+}
+
+type WorkoutActionContentItem struct {
+	Id       int        `json:"id"`
+	Title    string     `json:"title"`
+	Overview string     `json:"overview"`
+	VideoURL string     `json:"video_url"`
+	Time     int        `json:"time"`
+	Creator  CoachBrief `json:"creator"`
+}
+
+type WorkoutActionContentItemList struct {
+	List       []WorkoutActionContentItem `json:"list"`
+	PageSize   int                        `json:"page_size"`
+	HasMore    bool                       `json:"has_more"`
+	NextMarker string                     `json:"next_marker"`
+}
+
+type WorkoutActionHistory struct {
+	Id              int           `json:"id"`
+	D               int           `json:"d"`
+	StepUid         int           `json:"step_uid"`
+	SetUid          int           `json:"set_uid"`
+	ActUid          int           `json:"act_uid"`
+	Reps            int           `json:"reps"`
+	RepsUnit        string        `json:"reps_unit"`
+	Weight          float64       `json:"weight"`
+	WeightUnit      string        `json:"weight_unit"`
+	Remark          string        `json:"remark"`
+	ExtraMedias     string        `json:"extra_medias"`
+	CreatedAt       time.Time     `json:"created_at"`
+	WorkoutDayId    int           `json:"workout_day_id"`
+	StudentId       int           `json:"student_id"`
+	WorkoutActionId int           `json:"action_id"`
+	WorkoutAction   WorkoutAction `json:"action"`
+}
+
+type WorkoutActionHistoryList struct {
+	List       []WorkoutActionHistory `json:"list"`
+	PageSize   int                    `json:"page_size"`
+	HasMore    bool                   `json:"has_more"`
+	NextMarker string                 `json:"next_marker"`
+}
+
+type WorkoutActionItem struct {
+	Id           int       `json:"id"`
+	Name         string    `json:"name"`
+	ZhName       string    `json:"zh_name"`
+	MuscleIds    string    `json:"muscle_ids"`
+	EquipmentIds string    `json:"equipment_ids"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type WorkoutActionListItem struct {
+	WorkoutActionItem
+	Idx  int    `json:"idx"`
+	Tags string `json:"tags"`
+}
+
+type WorkoutActionListItemList struct {
+	List       []WorkoutActionListItem `json:"list"`
+	PageSize   int                     `json:"page_size"`
+	HasMore    bool                    `json:"has_more"`
+	NextMarker string                  `json:"next_marker"`
+}
+
+type WorkoutDayItem struct {
+	Id          int                  `json:"id"`
+	Status      int                  `json:"status"`
+	Title       string               `json:"title"`
+	Type        string               `json:"type"`
+	GroupNo     string               `json:"group_no"`
+	WorkoutPlan *WorkoutDayPlanBrief `json:"workout_plan"`
+	StartedAt   *time.Time           `json:"started_at"`
+	FinishedAt  *time.Time           `json:"finished_at"`
+}
+
+type WorkoutDayItemList struct {
+	List       []WorkoutDayItem `json:"list"`
+	PageSize   int              `json:"page_size"`
+	HasMore    bool             `json:"has_more"`
+	NextMarker string           `json:"next_marker"`
+}
+
+type WorkoutDayPlanBrief struct {
+	Id       int         `json:"id"`
+	Title    string      `json:"title"`
+	Overview string      `json:"overview"`
+	Tags     string      `json:"tags"`
+	Details  string      `json:"details,omitempty"`
+	Creator  *CoachBrief `json:"creator,omitempty"`
+}
+
+type WorkoutDayProfile struct {
+	Id             int                  `json:"id"`
+	Title          string               `json:"title"`
+	Type           string               `json:"type"`
+	Status         int                  `json:"status"`
+	Duration       int                  `json:"duration"`
+	TotalVolume    float64              `json:"total_volume"`
+	Remark         string               `json:"remark"`
+	Medias         string               `json:"medias"`
+	PendingSteps   string               `json:"pending_steps"`
+	UpdatedDetails string               `json:"updated_details"`
+	StudentId      int                  `json:"student_id"`
+	Student        CoachBrief           `json:"student"`
+	IsSelf         bool                 `json:"is_self"`
+	StartedAt      *time.Time           `json:"started_at"`
+	FinishedAt     *time.Time           `json:"finished_at"`
+	WorkoutPlan    *WorkoutDayPlanBrief `json:"workout_plan"`
+}
+
+type WorkoutDayResult struct {
+	Id          int                       `json:"id"`
+	Status      int                       `json:"status"`
+	Steps       []TodayWorkoutActionGroup `json:"steps"`
+	SetCount    int                       `json:"set_count"`
+	Duration    int                       `json:"duration"`
+	TotalVolume float64                   `json:"total_volume"`
+	Tags        []string                  `json:"tags"`
+	StudentId   int                       `json:"student_id"`
+	IsSelf      bool                      `json:"is_self"`
+	WorkoutPlan *WorkoutDayPlanBrief      `json:"workout_plan"`
+	StartedAt   *time.Time                `json:"started_at"`
+	FinishedAt  *time.Time                `json:"finished_at"`
+}
+
+type WorkoutPlan struct {
+	Id                int        `json:"id"`
+	D                 int        `json:"d"`
+	Status            int        `json:"status"`
+	Title             string     `json:"title"`
+	Overview          string     `json:"overview"`
+	CoverURL          string     `json:"cover_url"`
+	Type              string     `json:"type"`
+	Level             int        `json:"level"`
+	Tags              string     `json:"tags"`
+	EstimatedDuration int        `json:"estimated_duration"`
+	EquipmentIds      string     `json:"equipment_ids"`
+	MuscleIds         string     `json:"muscle_ids"`
+	Details           string     `json:"details"`
+	Points            string     `json:"points"`
+	Suggestions       string     `json:"suggestions"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         *time.Time `json:"updated_at"`
+	OwnerId           int        `json:"owner_id"`
+	Creator           Coach      `json:"creator"`
+}
+
+type WorkoutPlanBody struct {
+	Title             string `json:"title"`
+	Type              string `json:"type"`
+	Overview          string `json:"overview"`
+	Level             int    `json:"level"`
+	Status            int    `json:"status"`
+	Details           string `json:"details"`
+	Points            string `json:"points"`
+	Suggestions       string `json:"suggestions"`
+	EstimatedDuration int    `json:"estimated_duration"`
+	Tags              string `json:"tags"`
+	MuscleIds         string `json:"muscle_ids"`
+	EquipmentIds      string `json:"equipment_ids"`
+}
+
+type WorkoutPlanContentItem struct {
+	Id       int        `json:"id"`
+	Title    string     `json:"title"`
+	Overview string     `json:"overview"`
+	VideoKey string     `json:"video_key"`
+	Details  string     `json:"details"`
+	Creator  CoachBrief `json:"creator"`
+}
+
+type WorkoutPlanContentItemList struct {
+	List       []WorkoutPlanContentItem `json:"list"`
+	PageSize   int                      `json:"page_size"`
+	HasMore    bool                     `json:"has_more"`
+	NextMarker string                   `json:"next_marker"`
+}
+
+type WorkoutPlanContentProfile struct {
+	Id          int        `json:"id"`
+	ContentType int        `json:"content_type"`
+	Title       string     `json:"title"`
+	Overview    string     `json:"overview"`
+	Details     string     `json:"details"`
+	VideoURL    string     `json:"video_url"`
+	Creator     CoachBrief `json:"creator"`
+}
+
+type WorkoutPlanItem struct {
+	Id                int        `json:"id"`
+	Title             string     `json:"title"`
+	Overview          string     `json:"overview"`
+	Level             int        `json:"level"`
+	Tags              string     `json:"tags"`
+	EstimatedDuration int        `json:"estimated_duration"`
+	Creator           CoachBrief `json:"creator"`
+	IsFavorited       bool       `json:"is_favorited"`
+}
+
+type WorkoutPlanItemList struct {
+	List       []WorkoutPlanItem `json:"list"`
+	PageSize   int               `json:"page_size"`
+	HasMore    bool              `json:"has_more"`
+	NextMarker string            `json:"next_marker"`
+}
+
+type WorkoutPlanList struct {
+	List       []WorkoutPlan `json:"list"`
+	PageSize   int           `json:"page_size"`
+	HasMore    bool          `json:"has_more"`
+	NextMarker string        `json:"next_marker"`
+}
+
+type WorkoutPlanProfile struct {
+	Id                int          `json:"id"`
+	Title             string       `json:"title"`
+	Overview          string       `json:"overview"`
+	Level             int          `json:"level"`
+	EstimatedDuration int          `json:"estimated_duration"`
+	Suggestions       string       `json:"suggestions"`
+	Tags              string       `json:"tags"`
+	CoverURL          string       `json:"cover_url"`
+	EquipmentIds      string       `json:"equipment_ids"`
+	MuscleIds         string       `json:"muscle_ids"`
+	Details           string       `json:"details"`
+	Creator           CreatorBrief `json:"creator"`
+	IsFavorited       bool         `json:"is_favorited"`
+	CreatedAt         time.Time    `json:"created_at"`
+}
+
+type WorkoutPlanSet struct {
+	Id        int       `json:"id"`
+	Title     string    `json:"title"`
+	Overview  string    `json:"overview"`
+	IconURL   string    `json:"icon_url"`
+	Idx       int       `json:"idx"`
+	Details   string    `json:"details"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WorkoutPlanSetCreator struct {
+	Nickname  string `json:"nickname"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+type WorkoutPlanSetDetail struct {
+	Type     int                   `json:"type"`
+	Id       int                   `json:"id"`
+	Title    string                `json:"title"`
+	Overview string                `json:"overview"`
+	Tags     string                `json:"tags"`
+	Creator  WorkoutPlanSetCreator `json:"creator"`
+}
+
+type WorkoutPlanSetItem struct {
+	Id      int                    `json:"id"`
+	Title   string                 `json:"title"`
+	Idx     int                    `json:"idx"`
+	Details []WorkoutPlanSetDetail `json:"details"`
+}
+
+type WorkoutPlanSetItemList struct {
+	List       []WorkoutPlanSetItem `json:"list"`
+	PageSize   int                  `json:"page_size"`
+	HasMore    bool                 `json:"has_more"`
+	NextMarker string               `json:"next_marker"`
+}
+
+type WorkoutScheduleDay struct {
+	Idx         int                    `json:"idx"`
+	Day         int                    `json:"day"`
+	Weekday     int                    `json:"weekday"`
+	WorkoutPlan WorkoutScheduleDayPlan `json:"workout_plan"`
+}
+
+type WorkoutScheduleDayPlan struct {
+	Id                int    `json:"id"`
+	Title             string `json:"title"`
+	Overview          string `json:"overview"`
+	Tags              string `json:"tags"`
+	EstimatedDuration int    `json:"estimated_duration"`
+}
+
+type WorkoutScheduleItem struct {
+	Id       int        `json:"id"`
+	Type     int        `json:"type"`
+	Title    string     `json:"title"`
+	Overview string     `json:"overview"`
+	Level    int        `json:"level"`
+	Creator  CoachBrief `json:"creator"`
+}
+
+type WorkoutScheduleItemList struct {
+	List       []WorkoutScheduleItem `json:"list"`
+	PageSize   int                   `json:"page_size"`
+	HasMore    bool                  `json:"has_more"`
+	NextMarker string                `json:"next_marker"`
+}
+
+type WorkoutScheduleProfile struct {
+	Id                int                  `json:"id"`
+	Title             string               `json:"title"`
+	Overview          string               `json:"overview"`
+	Level             int                  `json:"level"`
+	Type              int                  `json:"type"`
+	Details           string               `json:"details"`
+	Creator           CreatorBrief         `json:"creator"`
+	CreatedAt         time.Time            `json:"created_at"`
+	Schedules         []WorkoutScheduleDay `json:"schedules"`
+	Applied           *int                 `json:"applied,omitempty"`
+	AppliedInInterval *int                 `json:"applied_in_interval,omitempty"`
+}
@@ -1,8 +1,13 @@
 package logger
 
 import (
+	"context"
+	"os"
+	"time"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 // Logger 封装zap日志库
@@ -10,11 +15,33 @@ type Logger struct {
 	*zap.SugaredLogger
 }
 
+// Options 日志配置
+type Options struct {
+	Level string
+	// json 或 console，console 方便开发时看
+	Format string
+	// 为空时输出到标准输出，否则写到文件并按大小切分
+	File string
+	// 单个文件的大小，单位 MB
+	MaxSize int
+	// 保留的旧文件个数，0 表示都保留
+	MaxBackups int
+	// 旧文件保留的天数，0 表示不按时间删除
+	MaxAge int
+	// 每秒同样的日志超过 100 条后，每 100 条只记录 1 条
+	Sampling bool
+}
+
 // NewLogger 创建新的日志实例
 func NewLogger(level string) *Logger {
+	return New(Options{Level: level, Format: "json"})
+}
+
+// New 按配置创建日志实例
+func New(opts Options) *Logger {
 	// 解析日志级别
 	var zapLevel zapcore.Level
-	switch level {
+	switch opts.Level {
 	case "debug":
 		zapLevel = zapcore.DebugLevel
 	case "info":
@@ -27,39 +54,106 @@ func NewLogger(level string) *Logger {
 		zapLevel = zapcore.InfoLevel
 	}
 
-	// 配置日志
-	config := zap.Config{
-		Level:       zap.NewAtomicLevelAt(zapLevel),
-		Development: false,
-		Encoding:    "json",
-		EncoderConfig: zapcore.EncoderConfig{
-			TimeKey:        "time",
-			LevelKey:       "level",
-			NameKey:        "logger",
-			CallerKey:      "caller",
-			MessageKey:     "msg",
-			StacktraceKey:  "stacktrace",
-			LineEnding:     zapcore.DefaultLineEnding,
-			EncodeLevel:    zapcore.LowercaseLevelEncoder,
-			EncodeTime:     zapcore.ISO8601TimeEncoder,
-			EncodeDuration: zapcore.SecondsDurationEncoder,
-			EncodeCaller:   zapcore.ShortCallerEncoder,
-		},
-		OutputPaths:      []string{"stdout"},
-		ErrorOutputPaths: []string{"stderr"},
+	encoder_config := zapcore.EncoderConfig{
+		TimeKey:        "time",
+		LevelKey:       "level",
+		NameKey:        "logger",
+		CallerKey:      "caller",
+		MessageKey:     "msg",
+		StacktraceKey:  "stacktrace",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeTime:     zapcore.ISO8601TimeEncoder,
+		EncodeDuration: zapcore.SecondsDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	}
+	var encoder zapcore.Encoder
+	if opts.Format == "console" {
+		encoder_config.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		encoder_config.EncodeDuration = zapcore.StringDurationEncoder
+		encoder = zapcore.NewConsoleEncoder(encoder_config)
+	} else {
+		encoder = zapcore.NewJSONEncoder(encoder_config)
+	}
+
+	var output zapcore.WriteSyncer = zapcore.Lock(os.Stdout)
+	if opts.File != "" {
+		output = zapcore.AddSync(&lumberjack.Logger{
+			Filename:   opts.File,
+			MaxSize:    opts.MaxSize,
+			MaxBackups: opts.MaxBackups,
+			MaxAge:     opts.MaxAge,
+		})
+	}
+
+	core := zapcore.NewCore(encoder, output, zap.NewAtomicLevelAt(zapLevel))
+	if opts.Sampling {
+		core = zapcore.NewSamplerWithOptions(core, time.Second, 100, 100)
 	}
 
 	// 创建日志
-	logger, _ := config.Build()
+	logger := zap.New(core, zap.AddCaller(), zap.ErrorOutput(zapcore.Lock(os.Stderr)))
 	return &Logger{logger.Sugar()}
 }
 
-// Error 记录错误日志
+// Error 记录错误日志，err 为 nil 时也能记录
 func (l *Logger) Error(msg string, err error) {
-	l.SugaredLogger.Errorw(msg, "error", err.Error())
+	// 多了一层封装，跳过它，caller 才是调用的地方
+	l.SugaredLogger.WithOptions(zap.AddCallerSkip(1)).Errorw(msg, zap.Error(err))
 }
 
 // Fatal 记录致命错误并退出
 func (l *Logger) Fatal(msg string, err error) {
-	l.SugaredLogger.Fatalw(msg, "error", err.Error())
+	l.SugaredLogger.WithOptions(zap.AddCallerSkip(1)).Fatalw(msg, zap.Error(err))
+}
+
+// 请求相关的字段在 context 里的 key，gin.Context 用 c.Set 设置后，用 c 作为 context 也能取到
+const (
+	RequestIdKey = "request_id"
+	// 和 AuthMiddleware 保存当前用户用的 key 一致
+	UserIdKey = "id"
+	RouteKey  = "route"
+)
+
+type requestIdKey struct{}
+
+// WithRequestId 把请求 ID 放到 context 里，用于没有 gin.Context 的地方
+func WithRequestId(ctx context.Context, request_id string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, request_id)
+}
+
+// RequestId 取出 context 里的请求 ID，没有时返回空字符串
+func RequestId(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if v, ok := ctx.Value(requestIdKey{}).(string); ok {
+		return v
+	}
+	if v, ok := ctx.Value(RequestIdKey).(string); ok {
+		return v
+	}
+	return ""
+}
+
+// WithContext 返回带上请求 ID、当前用户和路由的日志
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	if ctx == nil {
+		return l
+	}
+	var fields []interface{}
+	if request_id := RequestId(ctx); request_id != "" {
+		fields = append(fields, RequestIdKey, request_id)
+	}
+	// token 里解析出来的用户 ID 是 float64
+	if uid, ok := ctx.Value(UserIdKey).(float64); ok {
+		fields = append(fields, "user_id", int(uid))
+	}
+	if route, ok := ctx.Value(RouteKey).(string); ok && route != "" {
+		fields = append(fields, RouteKey, route)
+	}
+	if len(fields) == 0 {
+		return l
+	}
+	return &Logger{l.SugaredLogger.With(fields...)}
 }
@@ -0,0 +1,91 @@
+package mailer
+
+import (
+	"fmt"
+	"mime"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"myapi/pkg/logger"
+)
+
+// Mailer 发送邮件
+type Mailer interface {
+	Send(to string, subject string, body string) error
+}
+
+// SMTPMailer 通过 SMTP 发送邮件
+type SMTPMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPMailer(host string, port string, username string, password string, from string) *SMTPMailer {
+	if from == "" {
+		from = username
+	}
+	return &SMTPMailer{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (m *SMTPMailer) Send(to string, subject string, body string) error {
+	// 收件人会写进邮件头，不能带换行
+	if strings.ContainsAny(to, "\r\n") {
+		return fmt.Errorf("mailer: invalid recipient %q", to)
+	}
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+	msg := strings.Join([]string{
+		"From: " + m.from,
+		"To: " + to,
+		"Subject: " + mime.QEncoding.Encode("utf-8", subject),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+	return smtp.SendMail(m.host+":"+m.port, auth, m.from, []string{to}, []byte(msg))
+}
+
+// LogMailer 本地开发用，不真正发送，邮件内容写到日志，指定了文件时追加到文件里
+type LogMailer struct {
+	logger *logger.Logger
+	path   string
+	mu     sync.Mutex
+}
+
+func NewLogMailer(logger *logger.Logger, path string) *LogMailer {
+	return &LogMailer{
+		logger: logger,
+		path:   path,
+	}
+}
+
+func (m *LogMailer) Send(to string, subject string, body string) error {
+	if m.path == "" {
+		m.logger.Infow("Mail sent", "to", to, "subject", subject, "body", body)
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n", time.Now().Format(time.RFC3339), to, subject, body)
+	return err
+}
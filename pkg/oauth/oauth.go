@@ -0,0 +1,196 @@
+package oauth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UserInfo 第三方帐号的用户信息
+type UserInfo struct {
+	Id        string
+	Nickname  string
+	AvatarURL string
+}
+
+// Provider 第三方登录
+type Provider interface {
+	Name() string
+	// AuthCodeURL 跳转到第三方授权页的地址
+	AuthCodeURL(state string) string
+	// Exchange 用授权码换取用户信息
+	Exchange(ctx context.Context, code string) (*UserInfo, error)
+}
+
+// Config 标准 OAuth2 授权码模式需要的配置
+type Config struct {
+	Name         string
+	ClientId     string
+	ClientSecret string
+	AuthorizeURL string
+	TokenURL     string
+	UserInfoURL  string
+	RedirectURL  string
+	Scope        string
+}
+
+// OAuth2Provider 标准 OAuth2 授权码模式，用户信息接口返回 JSON
+type OAuth2Provider struct {
+	config Config
+	client *http.Client
+}
+
+func NewOAuth2Provider(config Config) *OAuth2Provider {
+	return &OAuth2Provider{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *OAuth2Provider) Name() string {
+	return p.config.Name
+}
+
+func (p *OAuth2Provider) AuthCodeURL(state string) string {
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientId)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("state", state)
+	if p.config.Scope != "" {
+		query.Set("scope", p.config.Scope)
+	}
+	sep := "?"
+	if strings.Contains(p.config.AuthorizeURL, "?") {
+		sep = "&"
+	}
+	return p.config.AuthorizeURL + sep + query.Encode()
+}
+
+func (p *OAuth2Provider) Exchange(ctx context.Context, code string) (*UserInfo, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("client_id", p.config.ClientId)
+	form.Set("client_secret", p.config.ClientSecret)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	var token struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}
+	if err := p.do(req, &token); err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("oauth token exchange failed: %s", token.Error)
+	}
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, p.config.UserInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	req.Header.Set("Accept", "application/json")
+	var data map[string]interface{}
+	if err := p.do(req, &data); err != nil {
+		return nil, err
+	}
+	// 不同平台字段名不一样，取常见的几个
+	info := &UserInfo{
+		Id:        pick(data, "sub", "id", "openid", "unionid"),
+		Nickname:  pick(data, "nickname", "name", "login"),
+		AvatarURL: pick(data, "avatar_url", "picture", "headimgurl"),
+	}
+	if info.Id == "" {
+		return nil, errors.New("oauth user info missing id")
+	}
+	return info, nil
+}
+
+func (p *OAuth2Provider) do(req *http.Request, v interface{}) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("oauth request %s failed: %d", req.URL.Path, resp.StatusCode)
+	}
+	return json.Unmarshal(body, v)
+}
+
+func pick(data map[string]interface{}, keys ...string) string {
+	for _, k := range keys {
+		switch v := data[k].(type) {
+		case string:
+			if v != "" {
+				return v
+			}
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	return ""
+}
+
+var ErrInvalidState = errors.New("invalid oauth state")
+
+// NewState 生成带签名的 state，绑定帐号时带上当前用户 id，回调时校验是同一个人发起的
+func NewState(secret_key string, uid int, ttl time.Duration) (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	payload := fmt.Sprintf("%d:%d:%s", uid, time.Now().Add(ttl).Unix(), hex.EncodeToString(buf))
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return encoded + "." + sign(secret_key, encoded), nil
+}
+
+// ParseState 校验 state 并返回发起时的用户 id
+func ParseState(secret_key string, state string) (int, error) {
+	parts := strings.Split(state, ".")
+	if len(parts) != 2 || !hmac.Equal([]byte(parts[1]), []byte(sign(secret_key, parts[0]))) {
+		return 0, ErrInvalidState
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return 0, ErrInvalidState
+	}
+	fields := strings.Split(string(payload), ":")
+	if len(fields) != 3 {
+		return 0, ErrInvalidState
+	}
+	uid, err1 := strconv.Atoi(fields[0])
+	expires_at, err2 := strconv.ParseInt(fields[1], 10, 64)
+	if err1 != nil || err2 != nil || time.Now().Unix() > expires_at {
+		return 0, ErrInvalidState
+	}
+	return uid, nil
+}
+
+func sign(secret_key string, value string) string {
+	mac := hmac.New(sha256.New, []byte("oauth_state:"+secret_key))
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
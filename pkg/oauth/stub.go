@@ -0,0 +1,59 @@
+package oauth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// NewStubServer 本地开发用的假 OAuth 服务，不校验 client，授权页直接跳回
+// 授权地址可以带 login 参数指定登录的用户，默认是 stub-user
+func NewStubServer() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		redirect_uri := r.URL.Query().Get("redirect_uri")
+		if redirect_uri == "" {
+			http.Error(w, "missing redirect_uri", http.StatusBadRequest)
+			return
+		}
+		login := r.URL.Query().Get("login")
+		if login == "" {
+			login = "stub-user"
+		}
+		query := url.Values{}
+		query.Set("code", login)
+		query.Set("state", r.URL.Query().Get("state"))
+		sep := "?"
+		if strings.Contains(redirect_uri, "?") {
+			sep = "&"
+		}
+		http.Redirect(w, r, redirect_uri+sep+query.Encode(), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		code := r.FormValue("code")
+		w.Header().Set("Content-Type", "application/json")
+		if code == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "stub." + code,
+			"token_type":   "bearer",
+		})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		login := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer stub.")
+		if login == "" || login == r.Header.Get("Authorization") {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"sub":  login,
+			"name": login,
+		})
+	})
+	return mux
+}
@@ -0,0 +1,26 @@
+package sms
+
+import (
+	"myapi/pkg/logger"
+)
+
+// Sender 发送短信，接入短信服务商时实现这个接口
+type Sender interface {
+	Send(phone string, content string) error
+}
+
+// LogSender 本地开发用，不真正发送，短信内容写到日志
+type LogSender struct {
+	logger *logger.Logger
+}
+
+func NewLogSender(logger *logger.Logger) *LogSender {
+	return &LogSender{
+		logger: logger,
+	}
+}
+
+func (s *LogSender) Send(phone string, content string) error {
+	s.logger.Infow("SMS sent", "phone", phone, "content", content)
+	return nil
+}